@@ -5,6 +5,7 @@
 package main
 
 import (
+	"errors"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -13,10 +14,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/internal/metrics"
 	"github.com/btcsuite/btcwallet/rpc/legacyrpc"
 	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
 	"github.com/btcsuite/btcwallet/walletdb"
 	"github.com/lightninglabs/neutrino"
 )
@@ -69,12 +73,27 @@ func walletMain() error {
 	}
 
 	dbDir := networkDir(cfg.AppDataDir.Value, activeNet.Params)
-	loader := wallet.NewLoader(activeNet.Params, dbDir, 250)
+	loader := newLoader(cfg, dbDir)
+	if len(cfg.MetricsListeners) != 0 {
+		loader.SetDatabaseObserver(metrics.ObserveDBTx)
+		if err := startMetricsServer(); err != nil {
+			log.Errorf("Unable to start metrics server: %v", err)
+			return err
+		}
+	}
+
+	// Named wallets are hosted in addition to the default wallet, except
+	// with SPV, which shares a single chain service between the wallets.
+	var multiLoader *wallet.MultiLoader
+	if !cfg.UseSPV {
+		multiLoader = wallet.NewMultiLoader(loader,
+			filepath.Join(dbDir, namedWalletsDirName))
+	}
 
 	// Create and start HTTP server to serve wallet client connections.
 	// This will be updated with the wallet and chain server RPC client
 	// created below after each is created.
-	rpcs, legacyRPCServer, err := startRPCServers(loader)
+	rpcs, legacyRPCServer, err := startRPCServers(loader, multiLoader)
 	if err != nil {
 		log.Errorf("Unable to create RPC servers: %v", err)
 		return err
@@ -86,11 +105,47 @@ func walletMain() error {
 		go rpcClientConnectLoop(legacyRPCServer, loader)
 	}
 
+	if cfg.RemoteSigner != "" {
+		signer, err := dialRemoteSigner()
+		if err != nil {
+			log.Errorf("Unable to connect to remote signer: %v", err)
+			return err
+		}
+		loader.RunAfterLoad(func(w *wallet.Wallet) {
+			w.SetSigner(signer)
+		})
+	}
+
 	loader.RunAfterLoad(func(w *wallet.Wallet) {
+		configureWallet(w, cfg.BackupDir)
 		startWalletRPCServices(w, rpcs, legacyRPCServer)
+		if len(cfg.MetricsListeners) != 0 {
+			metrics.AddWallet("", w)
+		}
 	})
+	if multiLoader != nil {
+		multiLoader.RunAfterLoad(func(name string, w *wallet.Wallet) {
+			backupDir := cfg.BackupDir
+			if backupDir != "" {
+				backupDir = filepath.Join(backupDir,
+					namedWalletsDirName, name)
+			}
+			configureWallet(w, backupDir)
+			if len(cfg.MetricsListeners) != 0 {
+				metrics.AddWallet(name, w)
+			}
+			go syncNamedWallet(name, w)
+		})
+	}
 
 	if !cfg.NoInitialLoad {
+		if cfg.CompactDB {
+			if err := compactDatabase(dbDir); err != nil {
+				log.Error(err)
+				return err
+			}
+		}
+
 		// Load the wallet database.  It must have been created already
 		// or this will return an appropriate error.
 		_, err = loader.OpenExistingWallet([]byte(cfg.WalletPass), true)
@@ -109,6 +164,14 @@ func walletMain() error {
 			log.Errorf("Failed to close wallet: %v", err)
 		}
 	})
+	if multiLoader != nil {
+		addInterruptHandler(func() {
+			err := multiLoader.UnloadWallets()
+			if err != nil {
+				log.Errorf("Failed to close named wallets: %v", err)
+			}
+		})
+	}
 	if rpcs != nil {
 		addInterruptHandler(func() {
 			// TODO: Does this need to wait for the grpc server to
@@ -135,6 +198,86 @@ func walletMain() error {
 	return nil
 }
 
+// startMetricsServer serves the Prometheus metrics at /metrics on the metrics
+// listeners.
+func startMetricsServer() error {
+	listeners := makeListeners(cfg.MetricsListeners, net.Listen)
+	if len(listeners) == 0 {
+		return errors.New("failed to create listeners for metrics server")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	for _, lis := range listeners {
+		lis := lis
+		go func() {
+			log.Infof("Metrics server listening on %s", lis.Addr())
+			err := http.Serve(lis, mux)
+			log.Tracef("Finished serving metrics: %v", err)
+		}()
+	}
+	return nil
+}
+
+// namedWalletsDirName is the name of the directory in the network directory
+// holding the database directories of named wallets.
+const namedWalletsDirName = "wallets"
+
+// configureWallet applies the wallet options of the application config to a
+// loaded wallet, writing database backups to backupDir unless it is empty.
+func configureWallet(w *wallet.Wallet, backupDir string) {
+	w.SetDefaultCoinSelectionStrategy(cfg.coinSelectionStrategy)
+	w.SetTestMempoolAccept(cfg.TestMempoolAccept)
+	if cfg.BatchInterval > 0 {
+		w.StartPaymentBatcher(cfg.BatchInterval, 1,
+			txrules.DefaultRelayFeePerKb)
+	}
+	if cfg.RebroadcastInterval > 0 {
+		w.StartRebroadcaster(cfg.RebroadcastInterval)
+	}
+	if backupDir != "" {
+		err := w.StartBackups(wallet.BackupConfig{
+			Dir:        backupDir,
+			Interval:   cfg.BackupInterval,
+			Keep:       cfg.BackupKeep,
+			Passphrase: []byte(cfg.BackupPass),
+		})
+		if err != nil {
+			log.Errorf("Unable to start database backups: %v", err)
+		}
+	}
+}
+
+// syncNamedWallet connects a chain client to the chain server of the
+// application config and synchronizes the named wallet w with it.  Chain
+// clients deliver their notifications to a single wallet, so each named wallet
+// has its own client, which is stopped with the wallet.  Unlike the client of
+// the default wallet, the client does not switch to backup chain servers.
+func syncNamedWallet(name string, w *wallet.Wallet) {
+	var (
+		chainClient  chain.Interface
+		bitcoindConn *chain.BitcoindConn
+		err          error
+	)
+	if cfg.UseBitcoind {
+		bitcoindConn, chainClient, err = startChainBitcoind(cfg.RPCConnect)
+	} else {
+		chainClient, err = startChainRPC(readCAFile(), cfg.RPCConnect, 0)
+	}
+	if err != nil {
+		log.Errorf("Unable to connect wallet %q to the chain server: %v",
+			name, err)
+		return
+	}
+
+	w.SynchronizeRPC(chainClient)
+	w.WaitForShutdown()
+	chainClient.Stop()
+	chainClient.WaitForShutdown()
+	if bitcoindConn != nil {
+		bitcoindConn.Stop()
+	}
+}
+
 // rpcClientConnectLoop continuously attempts a connection to the consensus RPC
 // server.  When a connection is established, the client is used to sync the
 // loaded wallet, either immediately or when loaded at a later time.
@@ -142,19 +285,56 @@ func walletMain() error {
 // The legacy RPC is optional.  If set, the connected RPC client will be
 // associated with the server for RPC passthrough and to enable additional
 // methods.
+//
+// When backup chain servers are configured, the servers are tried in order,
+// and the client is switched to the next server when the current one becomes
+// unreachable or stalls.  The wallet is restarted with the new client, which
+// replays the blocks it missed since it was last synced.
 func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Loader) {
 	var certs []byte
-	if !cfg.UseSPV {
+	if !cfg.UseSPV && !cfg.UseBitcoind {
 		certs = readCAFile()
 	}
 
+	chainServers := append([]string{cfg.RPCConnect}, cfg.BackupRPCConnect...)
+	chainServer := 0
+	nextChainServer := func() {
+		chainServer = (chainServer + 1) % len(chainServers)
+	}
+
+	// Connections to the only chain server are retried indefinitely,
+	// while a limited number of attempts is made before trying the next
+	// server.
+	reconnectAttempts := 0
+	if len(chainServers) > 1 {
+		reconnectAttempts = chainServerConnectAttempts
+	}
+
+	// Once every chain server failed to connect, reconnecting backs off
+	// exponentially until a connection succeeds.
+	backoff := minReconnectBackoff
+	connectFailed := func() {
+		nextChainServer()
+		if chainServer != 0 {
+			return
+		}
+		log.Infof("Retrying chain server connection in %v", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+
 	for {
 		var (
-			chainClient chain.Interface
-			err         error
+			chainClient  chain.Interface
+			bitcoindConn *chain.BitcoindConn
+			err          error
 		)
 
-		if cfg.UseSPV {
+		switch {
+		case cfg.UseSPV:
 			var (
 				chainService *neutrino.ChainService
 				spvdb        walletdb.DB
@@ -165,32 +345,63 @@ func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Load
 			defer spvdb.Close()
 			if err != nil {
 				log.Errorf("Unable to create Neutrino DB: %s", err)
+				connectFailed()
 				continue
 			}
-			chainService, err = neutrino.NewChainService(
-				neutrino.Config{
-					DataDir:      netDir,
-					Database:     spvdb,
-					ChainParams:  *activeNet.Params,
-					ConnectPeers: cfg.ConnectPeers,
-					AddPeers:     cfg.AddPeers,
-				})
+			neutrinoCfg := neutrino.Config{
+				DataDir:      netDir,
+				Database:     spvdb,
+				ChainParams:  *activeNet.Params,
+				ConnectPeers: cfg.ConnectPeers,
+				AddPeers:     cfg.AddPeers,
+			}
+			if proxy := chainProxy(); proxy != nil {
+				neutrinoCfg.Dialer = func(addr net.Addr) (net.Conn, error) {
+					return proxy.Dial(addr.Network(), addr.String())
+				}
+				neutrinoCfg.NameResolver = proxy.LookupIP
+			}
+			chainService, err = neutrino.NewChainService(neutrinoCfg)
 			if err != nil {
 				log.Errorf("Couldn't create Neutrino ChainService: %s", err)
+				connectFailed()
 				continue
 			}
-			chainClient = chain.NewNeutrinoClient(activeNet.Params, chainService)
+			neutrinoClient := chain.NewNeutrinoClient(activeNet.Params,
+				chainService)
+			neutrinoClient.SetFilterFetching(cfg.FilterFetchWorkers,
+				cfg.FilterFetchBatchSize)
+			chainClient = neutrinoClient
 			err = chainClient.Start()
 			if err != nil {
 				log.Errorf("Couldn't start Neutrino client: %s", err)
 			}
-		} else {
-			chainClient, err = startChainRPC(certs)
+
+		case cfg.UseBitcoind:
+			bitcoindConn, chainClient, err = startChainBitcoind(
+				chainServers[chainServer])
+			if err != nil {
+				log.Errorf("Unable to open connection to bitcoind: %v", err)
+				connectFailed()
+				continue
+			}
+
+		default:
+			chainClient, err = startChainRPC(certs,
+				chainServers[chainServer], reconnectAttempts)
 			if err != nil {
 				log.Errorf("Unable to open connection to consensus RPC server: %v", err)
+				connectFailed()
 				continue
 			}
 		}
+		backoff = minReconnectBackoff
+
+		monitor, err := monitorChainServer(chainClient, loader,
+			len(chainServers) > 1)
+		if err != nil {
+			log.Errorf("Unable to monitor chain server: %v", err)
+		}
 
 		// Rather than inlining this logic directly into the loader
 		// callback, a function variable is used to avoid running any of
@@ -215,6 +426,13 @@ func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Load
 		})
 
 		chainClient.WaitForShutdown()
+		if monitor != nil {
+			monitor.Stop()
+		}
+		if bitcoindConn != nil {
+			bitcoindConn.Stop()
+		}
+		nextChainServer()
 
 		mu.Lock()
 		associateRPCClient = nil
@@ -258,17 +476,127 @@ func readCAFile() []byte {
 	return certs
 }
 
-// startChainRPC opens a RPC client connection to a btcd server for blockchain
-// services.  This function uses the RPC options from the global config and
-// there is no recovery in case the server is not available or if there is an
-// authentication error.  Instead, all requests to the client will simply error.
-func startChainRPC(certs []byte) (*chain.RPCClient, error) {
-	log.Infof("Attempting RPC client connection to %v", cfg.RPCConnect)
-	rpcc, err := chain.NewRPCClient(activeNet.Params, cfg.RPCConnect,
-		cfg.BtcdUsername, cfg.BtcdPassword, certs, cfg.DisableClientTLS, 0)
+const (
+	// chainServerConnectAttempts is the number of connection attempts made
+	// to a btcd chain server before trying the next one.
+	chainServerConnectAttempts = 3
+
+	// chainHealthProbeInterval is the interval at which the chain server
+	// is probed.
+	chainHealthProbeInterval = 30 * time.Second
+
+	// chainHealthMaxFailures is the number of consecutive failed probes
+	// after which the chain server is considered unreachable.
+	chainHealthMaxFailures = 3
+
+	// minReconnectBackoff and maxReconnectBackoff bound the time waited
+	// before reconnecting after failing to connect to every chain server.
+	// The time doubles with each consecutive failure.
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// monitorChainServer starts monitoring the health of the chain server of
+// chainClient.  Health changes are recorded by the loaded wallet, which
+// notifies its clients, and chainClient is stopped when switchServers is set
+// and the server becomes degraded, so that the next chain server is connected
+// to.  The returned monitor must be stopped once chainClient shuts down.
+func monitorChainServer(chainClient chain.Interface, loader *wallet.Loader,
+	switchServers bool) (*chain.HealthMonitor, error) {
+
+	monitor, err := chain.NewHealthMonitor(chainClient,
+		&chain.HealthMonitorConfig{
+			ProbeInterval: chainHealthProbeInterval,
+			MaxFailures:   chainHealthMaxFailures,
+			StallTimeout:  cfg.ChainStallTimeout,
+			OnChange: func(status chain.HealthStatus) {
+				if w, ok := loader.LoadedWallet(); ok {
+					w.SetChainHealth(status)
+				}
+				if status.Degraded() && switchServers {
+					log.Infof("Switching to the next chain " +
+						"server")
+					chainClient.Stop()
+				}
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	monitor.Start()
+	return monitor, nil
+}
+
+// chainProxy returns the proxy through which connections to chain servers and
+// peers are made, or nil when no proxy is configured.
+func chainProxy() *chain.Proxy {
+	if cfg.Proxy == "" {
+		return nil
+	}
+	return &chain.Proxy{
+		Addr:           cfg.Proxy,
+		User:           cfg.ProxyUser,
+		Pass:           cfg.ProxyPass,
+		IsolateStreams: cfg.TorIsolation,
+	}
+}
+
+// startChainRPC opens a RPC client connection to the btcd server at connect
+// for blockchain services, making at most reconnectAttempts connection
+// attempts, or retrying indefinitely when zero.  This function uses the RPC
+// options from the global config and there is no recovery in case the server
+// is not available or if there is an authentication error.  Instead, all
+// requests to the client will simply error.
+func startChainRPC(certs []byte, connect string,
+	reconnectAttempts int) (*chain.RPCClient, error) {
+
+	log.Infof("Attempting RPC client connection to %v", connect)
+	rpcc, err := chain.NewRPCClient(activeNet.Params, connect,
+		cfg.BtcdUsername, cfg.BtcdPassword, certs, cfg.DisableClientTLS,
+		reconnectAttempts, chainProxy())
 	if err != nil {
 		return nil, err
 	}
 	err = rpcc.Start()
 	return rpcc, err
 }
+
+// bitcoindZMQPollInterval is the interval at which events are read from the
+// bitcoind ZMQ connections.
+const bitcoindZMQPollInterval = 100 * time.Millisecond
+
+// startChainBitcoind opens RPC and ZMQ connections to the bitcoind node at
+// connect for blockchain services, using the RPC options from the global
+// config, and returns the connection and a client synchronizing with it.  When
+// a bitcoind poll interval is configured, the node is polled over RPC instead
+// of using ZMQ.  The connection must be stopped once the client shuts down.
+func startChainBitcoind(connect string) (*chain.BitcoindConn,
+	*chain.BitcoindClient, error) {
+
+	log.Infof("Attempting bitcoind connection to %v", connect)
+	var conn *chain.BitcoindConn
+	var err error
+	if cfg.BitcoindPoll != 0 {
+		conn, err = chain.NewBitcoindPollingConn(activeNet.Params,
+			connect, cfg.BtcdUsername, cfg.BtcdPassword,
+			cfg.BitcoindPoll, chainProxy())
+	} else {
+		conn, err = chain.NewBitcoindConn(activeNet.Params,
+			connect, cfg.BtcdUsername, cfg.BtcdPassword,
+			cfg.ZMQPubRawBlock, cfg.ZMQPubRawTx,
+			bitcoindZMQPollInterval, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conn.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	client := conn.NewBitcoindClient()
+	if err := client.Start(); err != nil {
+		conn.Stop()
+		return nil, nil, err
+	}
+	return conn, client, nil
+}
@@ -1,10 +1,13 @@
 package chain
 
 import (
+	"bytes"
 	"container/list"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -61,6 +64,11 @@ type BitcoindClient struct {
 	// they are relevant to the client.
 	rescanUpdate chan interface{}
 
+	// rescanQuit is closed to cancel the running rescan, and is nil while
+	// no rescan is running.
+	rescanQuitMtx sync.Mutex
+	rescanQuit    chan struct{}
+
 	// watchedAddresses, watchedOutPoints, and watchedTxs are the set of
 	// items we should match transactions against while processing a chain
 	// rescan to determine if they are relevant to the client.
@@ -143,9 +151,57 @@ func (c *BitcoindClient) GetBlockHeight(hash *chainhash.Hash) (int32, error) {
 	return header.Height, nil
 }
 
-// GetBlock returns a block from the hash.
+// EstimateFeePerKb returns the fee rate, in satoshis per kilobyte, bitcoind
+// estimates is required for a transaction to confirm within confTarget blocks.
+// Nodes without estimatesmartfee are asked with estimatefee instead.
+func (c *BitcoindClient) EstimateFeePerKb(confTarget uint32) (btcutil.Amount, error) {
+	target, err := json.Marshal(confTarget)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.chainConn.client.RawRequest(
+		"estimatesmartfee", []json.RawMessage{target},
+	)
+	if rpcErr, ok := err.(*btcjson.RPCError); ok &&
+		rpcErr.Code == btcjson.ErrRPCMethodNotFound.Code {
+
+		feeRate, err := c.chainConn.client.EstimateFee(int64(confTarget))
+		if err != nil {
+			return 0, err
+		}
+		if feeRate <= 0 {
+			return 0, errors.New("not enough data to estimate fee")
+		}
+		return btcutil.NewAmount(feeRate)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		FeeRate *float64 `json:"feerate"`
+		Errors  []string `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return 0, err
+	}
+	if result.FeeRate == nil || *result.FeeRate <= 0 {
+		return 0, fmt.Errorf("unable to estimate fee: %v",
+			strings.Join(result.Errors, ", "))
+	}
+	return btcutil.NewAmount(*result.FeeRate)
+}
+
+// GetBlock returns a block from the hash.  Blocks pruned by the bitcoind node
+// are retrieved from its peers instead.
 func (c *BitcoindClient) GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
-	return c.chainConn.client.GetBlock(hash)
+	block, err := c.chainConn.client.GetBlock(hash)
+	if err != nil && isPrunedBlockErr(err) {
+		log.Debugf("Block %v is pruned by bitcoind, fetching it from "+
+			"peers", hash)
+		return c.chainConn.prunedBlocks.FetchBlock(hash)
+	}
+	return block, err
 }
 
 // GetBlockVerbose returns a verbose block from the hash.
@@ -188,11 +244,62 @@ func (c *BitcoindClient) GetTxOut(txHash *chainhash.Hash, index uint32,
 	return c.chainConn.client.GetTxOut(txHash, index, mempool)
 }
 
-// SendRawTransaction sends a raw transaction via bitcoind.
+// SendRawTransaction sends a raw transaction via bitcoind.  Rejections by the
+// bitcoind mempool are returned as the rejection errors of this package, such
+// as ErrDoubleSpend, while transactions already in the mempool are returned as
+// published.
 func (c *BitcoindClient) SendRawTransaction(tx *wire.MsgTx,
 	allowHighFees bool) (*chainhash.Hash, error) {
 
-	return c.chainConn.client.SendRawTransaction(tx, allowHighFees)
+	txid, err := c.chainConn.client.SendRawTransaction(tx, allowHighFees)
+	if err != nil {
+		return publishResult(tx, err, bitcoindPublishErrs)
+	}
+	return txid, nil
+}
+
+// MempoolAcceptResult describes whether a transaction would be accepted to the
+// mempool of a chain backend, and the reason it would be rejected otherwise.
+type MempoolAcceptResult struct {
+	Allowed      bool
+	RejectReason string
+}
+
+// TestMempoolAccept tests whether tx would be accepted to the mempool of
+// bitcoind, using the testmempoolaccept RPC, without broadcasting it.
+func (c *BitcoindClient) TestMempoolAccept(
+	tx *wire.MsgTx) (*MempoolAcceptResult, error) {
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	rawTxs, err := json.Marshal([]string{hex.EncodeToString(buf.Bytes())})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.chainConn.client.RawRequest(
+		"testmempoolaccept", []json.RawMessage{rawTxs},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Allowed      bool   `json:"allowed"`
+		RejectReason string `json:"reject-reason"`
+	}
+	if err := json.Unmarshal(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("expected one testmempoolaccept result, "+
+			"got %d", len(results))
+	}
+	return &MempoolAcceptResult{
+		Allowed:      results[0].Allowed,
+		RejectReason: results[0].RejectReason,
+	}, nil
 }
 
 // Notifications returns a channel to retrieve notifications from.
@@ -388,6 +495,20 @@ func (c *BitcoindClient) Rescan(blockHash *chainhash.Hash,
 	return nil
 }
 
+// CancelRescan cancels the running rescan, which stops before processing its
+// next block without sending a RescanFinished notification.  ErrNoRescan is
+// returned when no rescan is running.
+func (c *BitcoindClient) CancelRescan() error {
+	c.rescanQuitMtx.Lock()
+	defer c.rescanQuitMtx.Unlock()
+	if c.rescanQuit == nil {
+		return ErrNoRescan
+	}
+	close(c.rescanQuit)
+	c.rescanQuit = nil
+	return nil
+}
+
 // Start initializes the bitcoind rescan client using the backing bitcoind
 // connection and starts all goroutines necessary in order to process rescans
 // and ZMQ notifications.
@@ -528,7 +649,8 @@ func (c *BitcoindClient) rescanHandler() {
 
 			// We're starting a rescan from the hash.
 			case chainhash.Hash:
-				if err := c.rescan(update); err != nil {
+				err := c.rescan(update)
+				if err != nil && err != ErrRescanCanceled {
 					log.Errorf("Unable to complete chain "+
 						"rescan: %v", err)
 				}
@@ -620,17 +742,20 @@ func (c *BitcoindClient) BlockStamp() (*waddrmgr.BlockStamp, error) {
 
 // onBlockConnected is a callback that's executed whenever a new block has been
 // detected. This will queue a BlockConnected notification to the caller.
-func (c *BitcoindClient) onBlockConnected(hash *chainhash.Hash, height int32,
-	timestamp time.Time) {
+func (c *BitcoindClient) onBlockConnected(height int32,
+	header *wire.BlockHeader) {
 
 	if c.shouldNotifyBlocks() {
 		select {
 		case c.notificationQueue.ChanIn() <- BlockConnected{
-			Block: wtxmgr.Block{
-				Hash:   *hash,
-				Height: height,
+			BlockMeta: wtxmgr.BlockMeta{
+				Block: wtxmgr.Block{
+					Hash:   header.BlockHash(),
+					Height: height,
+				},
+				Time: header.Timestamp,
 			},
-			Time: timestamp,
+			Header: header,
 		}:
 		case <-c.quit:
 		}
@@ -664,18 +789,25 @@ func (c *BitcoindClient) onFilteredBlockConnected(height int32,
 
 // onBlockDisconnected is a callback that's executed whenever a block has been
 // disconnected. This will queue a BlockDisconnected notification to the caller
-// with the details of the block being disconnected.
+// with the details of the block being disconnected.  The header is only
+// included when it is the header of the block.
 func (c *BitcoindClient) onBlockDisconnected(hash *chainhash.Hash, height int32,
-	timestamp time.Time) {
+	timestamp time.Time, header *wire.BlockHeader) {
 
+	if header != nil && header.BlockHash() != *hash {
+		header = nil
+	}
 	if c.shouldNotifyBlocks() {
 		select {
 		case c.notificationQueue.ChanIn() <- BlockDisconnected{
-			Block: wtxmgr.Block{
-				Hash:   *hash,
-				Height: height,
+			BlockMeta: wtxmgr.BlockMeta{
+				Block: wtxmgr.Block{
+					Hash:   *hash,
+					Height: height,
+				},
+				Time: timestamp,
 			},
-			Time: timestamp,
+			Header: header,
 		}:
 		case <-c.quit:
 		}
@@ -797,7 +929,7 @@ func (c *BitcoindClient) reorg(currentBlock waddrmgr.BlockStamp,
 
 		c.onBlockDisconnected(
 			&currentBlock.Hash, currentBlock.Height,
-			currentBlock.Timestamp,
+			currentBlock.Timestamp, currentHeader,
 		)
 
 		// Our current block should now reflect the previous one to
@@ -829,6 +961,7 @@ func (c *BitcoindClient) reorg(currentBlock waddrmgr.BlockStamp,
 
 	c.onBlockDisconnected(
 		&currentBlock.Hash, currentBlock.Height, currentHeader.Timestamp,
+		currentHeader,
 	)
 
 	currentBlock.Height--
@@ -918,6 +1051,16 @@ func (c *BitcoindClient) FilterBlocks(
 func (c *BitcoindClient) rescan(start chainhash.Hash) error {
 	log.Infof("Starting rescan from block %s", start)
 
+	quit := make(chan struct{})
+	c.rescanQuitMtx.Lock()
+	c.rescanQuit = quit
+	c.rescanQuitMtx.Unlock()
+	defer func() {
+		c.rescanQuitMtx.Lock()
+		c.rescanQuit = nil
+		c.rescanQuitMtx.Unlock()
+	}()
+
 	// We start by getting the best already processed block. We only use
 	// the height, as the hash can change during a reorganization, which we
 	// catch by testing connectivity from known blocks to the previous
@@ -950,15 +1093,30 @@ func (c *BitcoindClient) rescan(start chainhash.Hash) error {
 	headers.PushBack(previousHeader)
 
 	// Queue a RescanFinished notification to the caller with the last block
-	// processed throughout the rescan once done.
-	defer c.onRescanFinished(
-		previousHash, previousHeader.Height,
-		time.Unix(previousHeader.Time, 0),
-	)
+	// processed throughout the rescan once done, unless the rescan is
+	// canceled.
+	var canceled bool
+	finishedHash, finishedHeight := previousHash, previousHeader.Height
+	finishedTime := time.Unix(previousHeader.Time, 0)
+	defer func() {
+		if !canceled {
+			c.onRescanFinished(
+				finishedHash, finishedHeight, finishedTime,
+			)
+		}
+	}()
 
 	// Cycle through all of the blocks known to bitcoind, being mindful of
 	// reorgs.
 	for i := previousHeader.Height + 1; i <= bestBlock.Height; i++ {
+		select {
+		case <-quit:
+			log.Infof("Canceled rescan at height %d", i-1)
+			canceled = true
+			return ErrRescanCanceled
+		default:
+		}
+
 		hash, err := c.GetBlockHash(int64(i))
 		if err != nil {
 			return err
@@ -1005,7 +1163,7 @@ func (c *BitcoindClient) rescan(start chainhash.Hash) error {
 			// rescan state.
 			c.onBlockDisconnected(
 				previousHash, previousHeader.Height,
-				time.Unix(previousHeader.Time, 0),
+				time.Unix(previousHeader.Time, 0), nil,
 			)
 
 			// Get the previous block of the best chain.
@@ -1159,7 +1317,7 @@ func (c *BitcoindClient) filterBlock(block *wire.MsgBlock, height int32,
 
 	if notify {
 		c.onFilteredBlockConnected(height, &block.Header, relevantTxs)
-		c.onBlockConnected(&blockHash, height, block.Header.Timestamp)
+		c.onBlockConnected(height, &block.Header)
 	}
 
 	return relevantTxs, nil
@@ -16,7 +16,8 @@ import (
 )
 
 // BitcoindConn represents a persistent client connection to a bitcoind node
-// that listens for events read from a ZMQ connection.
+// that listens for events read from a ZMQ connection, or polled from its RPC
+// server when ZMQ is not available.
 type BitcoindConn struct {
 	started int32 // To be used atomically.
 	stopped int32 // To be used atomically.
@@ -45,6 +46,15 @@ type BitcoindConn struct {
 	// event from the ZMQ connection.
 	zmqPollInterval time.Duration
 
+	// rpcPollInterval is the interval at which the best block and mempool
+	// of the bitcoind node are polled over RPC.  It is zero when events
+	// are read from ZMQ connections instead.
+	rpcPollInterval time.Duration
+
+	// prunedBlocks retrieves the blocks pruned by the bitcoind node from
+	// the node's peers.
+	prunedBlocks *PrunedBlockFetcher
+
 	// rescanClients is the set of active bitcoind rescan clients to which
 	// ZMQ event notfications will be sent to.
 	rescanClientsMtx sync.Mutex
@@ -58,10 +68,11 @@ type BitcoindConn struct {
 // string. The connection is not established immediately, but must be done using
 // the Start method. If the remote node does not operate on the same bitcoin
 // network as described by the passed chain parameters, the connection will be
-// disconnected.
+// disconnected. When proxy is not nil, the RPC connection and the connections
+// to the node's peers are made through it, while the ZMQ connections are not.
 func NewBitcoindConn(chainParams *chaincfg.Params,
 	host, user, pass, zmqBlockHost, zmqTxHost string,
-	zmqPollInterval time.Duration) (*BitcoindConn, error) {
+	zmqPollInterval time.Duration, proxy *Proxy) (*BitcoindConn, error) {
 
 	clientCfg := &rpcclient.ConnConfig{
 		Host:                 host,
@@ -72,6 +83,7 @@ func NewBitcoindConn(chainParams *chaincfg.Params,
 		DisableTLS:           true,
 		HTTPPostMode:         true,
 	}
+	setRPCProxy(clientCfg, proxy)
 
 	client, err := rpcclient.New(clientCfg, nil)
 	if err != nil {
@@ -87,6 +99,39 @@ func NewBitcoindConn(chainParams *chaincfg.Params,
 		rescanClients:   make(map[uint64]*BitcoindClient),
 		quit:            make(chan struct{}),
 	}
+	conn.prunedBlocks = NewPrunedBlockFetcher(
+		chainParams, conn.fullBlockPeers,
+	)
+	if proxy != nil {
+		conn.prunedBlocks.dial = func(addr string) (net.Conn, error) {
+			return proxy.Dial("tcp", addr)
+		}
+	}
+
+	return conn, nil
+}
+
+// NewBitcoindPollingConn creates a client connection to the node described by
+// the host string, like NewBitcoindConn, which derives block and transaction
+// events from changes of the node's best block and mempool, polled over RPC
+// every pollInterval. This allows using bitcoind nodes without ZMQ enabled, and
+// connecting to bitcoind entirely through a proxy.
+func NewBitcoindPollingConn(chainParams *chaincfg.Params,
+	host, user, pass string, pollInterval time.Duration,
+	proxy *Proxy) (*BitcoindConn, error) {
+
+	if pollInterval <= 0 {
+		return nil, fmt.Errorf("invalid bitcoind poll interval %v",
+			pollInterval)
+	}
+
+	conn, err := NewBitcoindConn(
+		chainParams, host, user, pass, "", "", 0, proxy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.rpcPollInterval = pollInterval
 
 	return conn, nil
 }
@@ -113,6 +158,15 @@ func (c *BitcoindConn) Start() error {
 			c.chainParams.Net, net)
 	}
 
+	// Without ZMQ, the events are derived by polling the node instead.
+	if c.rpcPollInterval != 0 {
+		c.wg.Add(2)
+		go c.blockPollHandler()
+		go c.txPollHandler()
+
+		return nil
+	}
+
 	// Establish two different ZMQ connections to bitcoind to retrieve block
 	// and transaction event notifications. We'll use two as a separation of
 	// concern to ensure one type of event isn't dropped from the connection
@@ -210,17 +264,9 @@ func (c *BitcoindConn) blockEventHandler(conn *gozmq.Conn) {
 				continue
 			}
 
-			c.rescanClientsMtx.Lock()
-			for _, client := range c.rescanClients {
-				select {
-				case client.zmqBlockNtfns <- block:
-				case <-client.quit:
-				case <-c.quit:
-					c.rescanClientsMtx.Unlock()
-					return
-				}
+			if !c.notifyBlock(block) {
+				return
 			}
-			c.rescanClientsMtx.Unlock()
 		default:
 			// It's possible that the message wasn't fully read if
 			// bitcoind shuts down, which will produce an unreadable
@@ -286,17 +332,9 @@ func (c *BitcoindConn) txEventHandler(conn *gozmq.Conn) {
 				continue
 			}
 
-			c.rescanClientsMtx.Lock()
-			for _, client := range c.rescanClients {
-				select {
-				case client.zmqTxNtfns <- tx:
-				case <-client.quit:
-				case <-c.quit:
-					c.rescanClientsMtx.Unlock()
-					return
-				}
+			if !c.notifyTx(tx) {
+				return
 			}
-			c.rescanClientsMtx.Unlock()
 		default:
 			// It's possible that the message wasn't fully read if
 			// bitcoind shuts down, which will produce an unreadable
@@ -312,6 +350,163 @@ func (c *BitcoindConn) txEventHandler(conn *gozmq.Conn) {
 	}
 }
 
+// blockPollHandler polls the best block of the bitcoind node and forwards each
+// new best block along to the current rescan clients. Blocks connected between
+// two polls are not forwarded, as the clients retrieve the blocks leading to a
+// best block they don't connect to themselves.
+//
+// NOTE: This must be run as a goroutine.
+func (c *BitcoindConn) blockPollHandler() {
+	defer c.wg.Done()
+
+	log.Infof("Started polling bitcoind for block notifications every %v",
+		c.rpcPollInterval)
+
+	ticker := time.NewTicker(c.rpcPollInterval)
+	defer ticker.Stop()
+
+	// The best block at startup is known to the clients, which retrieve it
+	// when started.
+	bestHash, err := c.client.GetBestBlockHash()
+	if err != nil {
+		log.Errorf("Unable to retrieve best block: %v", err)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.quit:
+			return
+		}
+
+		hash, err := c.client.GetBestBlockHash()
+		if err != nil {
+			log.Errorf("Unable to retrieve best block: %v", err)
+			continue
+		}
+		if bestHash != nil && *hash == *bestHash {
+			continue
+		}
+
+		block, err := c.client.GetBlock(hash)
+		if err != nil {
+			log.Errorf("Unable to retrieve block %v: %v", hash, err)
+			continue
+		}
+		bestHash = hash
+
+		if !c.notifyBlock(block) {
+			return
+		}
+	}
+}
+
+// txPollHandler polls the mempool of the bitcoind node and forwards the
+// transactions which entered it since the previous poll along to the current
+// rescan clients.
+//
+// NOTE: This must be run as a goroutine.
+func (c *BitcoindConn) txPollHandler() {
+	defer c.wg.Done()
+
+	log.Infof("Started polling bitcoind for transaction notifications "+
+		"every %v", c.rpcPollInterval)
+
+	ticker := time.NewTicker(c.rpcPollInterval)
+	defer ticker.Stop()
+
+	// The transactions already in the mempool at startup are not
+	// forwarded, just as they would not be received over ZMQ.
+	var mempool map[chainhash.Hash]struct{}
+	if hashes, err := c.client.GetRawMempool(); err != nil {
+		log.Errorf("Unable to retrieve mempool: %v", err)
+	} else {
+		mempool = make(map[chainhash.Hash]struct{}, len(hashes))
+		for _, hash := range hashes {
+			mempool[*hash] = struct{}{}
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.quit:
+			return
+		}
+
+		hashes, err := c.client.GetRawMempool()
+		if err != nil {
+			log.Errorf("Unable to retrieve mempool: %v", err)
+			continue
+		}
+
+		newMempool := make(map[chainhash.Hash]struct{}, len(hashes))
+		for _, hash := range hashes {
+			newMempool[*hash] = struct{}{}
+			if _, ok := mempool[*hash]; ok || mempool == nil {
+				continue
+			}
+
+			// The transaction may have left the mempool since it
+			// was listed, in which case it is skipped.
+			tx, err := c.client.GetRawTransaction(hash)
+			if err != nil {
+				log.Debugf("Unable to retrieve mempool "+
+					"transaction %v: %v", hash, err)
+				continue
+			}
+			if !c.notifyTx(tx.MsgTx()) {
+				return
+			}
+		}
+		mempool = newMempool
+	}
+}
+
+// notifyBlock forwards a block event along to the current rescan clients. It
+// returns false if the connection was stopped in the meantime.
+func (c *BitcoindConn) notifyBlock(block *wire.MsgBlock) bool {
+	c.rescanClientsMtx.Lock()
+	defer c.rescanClientsMtx.Unlock()
+
+	for _, client := range c.rescanClients {
+		select {
+		case client.zmqBlockNtfns <- block:
+		case <-client.quit:
+		case <-c.quit:
+			return false
+		}
+	}
+	return true
+}
+
+// notifyTx forwards a transaction event along to the current rescan clients.
+// It returns false if the connection was stopped in the meantime.
+func (c *BitcoindConn) notifyTx(tx *wire.MsgTx) bool {
+	c.rescanClientsMtx.Lock()
+	defer c.rescanClientsMtx.Unlock()
+
+	for _, client := range c.rescanClients {
+		select {
+		case client.zmqTxNtfns <- tx:
+		case <-client.quit:
+		case <-c.quit:
+			return false
+		}
+	}
+	return true
+}
+
+// fullBlockPeers returns the addresses of the peers of the bitcoind node which
+// serve full blocks, from which blocks pruned by the node can be retrieved.
+func (c *BitcoindConn) fullBlockPeers() ([]string, error) {
+	peers, err := c.client.GetPeerInfo()
+	if err != nil {
+		return nil, err
+	}
+	return bitcoindFullBlockPeers(peers), nil
+}
+
 // getCurrentNet returns the network on which the bitcoind node is running.
 func (c *BitcoindConn) getCurrentNet() (wire.BitcoinNet, error) {
 	hash, err := c.client.GetBlockHash(0)
@@ -0,0 +1,241 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// pollingNode is a bitcoind JSON-RPC server serving the best block and
+// mempool polled by a BitcoindConn without ZMQ.
+type pollingNode struct {
+	mu      sync.Mutex
+	best    *wire.MsgBlock
+	blocks  map[chainhash.Hash]*wire.MsgBlock
+	mempool []*wire.MsgTx
+	calls   map[string]int
+}
+
+func newPollingNode() *pollingNode {
+	genesis := chaincfg.RegressionNetParams.GenesisBlock
+	return &pollingNode{
+		best:   genesis,
+		blocks: map[chainhash.Hash]*wire.MsgBlock{genesis.BlockHash(): genesis},
+		calls:  make(map[string]int),
+	}
+}
+
+// setBest connects block as the best block of the node.
+func (n *pollingNode) setBest(block *wire.MsgBlock) {
+	n.mu.Lock()
+	n.best = block
+	n.blocks[block.BlockHash()] = block
+	n.mu.Unlock()
+}
+
+// setMempool replaces the transactions in the mempool of the node.
+func (n *pollingNode) setMempool(txs ...*wire.MsgTx) {
+	n.mu.Lock()
+	n.mempool = txs
+	n.mu.Unlock()
+}
+
+// callCount returns how many times method was called.
+func (n *pollingNode) callCount(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+func (n *pollingNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	serialize := func(s interface {
+		Serialize(w io.Writer) error
+	}) string {
+		var buf bytes.Buffer
+		s.Serialize(&buf)
+		return hex.EncodeToString(buf.Bytes())
+	}
+	param := func() string {
+		var s string
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &s)
+		}
+		return s
+	}
+
+	n.mu.Lock()
+	n.calls[req.Method]++
+	var result interface{}
+	switch req.Method {
+	case "getblockhash":
+		result = chaincfg.RegressionNetParams.GenesisHash.String()
+	case "getbestblockhash":
+		result = n.best.BlockHash().String()
+	case "getblock":
+		hash, _ := chainhash.NewHashFromStr(param())
+		if block, ok := n.blocks[*hash]; ok {
+			result = serialize(block)
+		}
+	case "getrawmempool":
+		hashes := make([]string, 0, len(n.mempool))
+		for _, tx := range n.mempool {
+			hashes = append(hashes, tx.TxHash().String())
+		}
+		result = hashes
+	case "getrawtransaction":
+		for _, tx := range n.mempool {
+			if tx.TxHash().String() == param() {
+				result = serialize(tx)
+			}
+		}
+	}
+	n.mu.Unlock()
+
+	resp := map[string]interface{}{"id": req.ID, "result": result}
+	if result == nil {
+		resp["error"] = map[string]interface{}{
+			"code":    -5,
+			"message": "not found",
+		}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// pollTestTx returns a transaction spending the output index of the null
+// transaction hash, which makes it unique for each index.
+func pollTestTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: index}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1e6, []byte{0x51}))
+	return tx
+}
+
+// waitForCalls waits until method was called at least count times.
+func waitForCalls(t *testing.T, node *pollingNode, method string, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for node.callCount(method) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s calls", method)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestBitcoindPollingConn ensures that a BitcoindConn without ZMQ forwards new
+// best blocks and the transactions entering the mempool of the node to its
+// rescan clients.
+func TestBitcoindPollingConn(t *testing.T) {
+	node := newPollingNode()
+	node.setMempool(pollTestTx(0))
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	if _, err := NewBitcoindPollingConn(&chaincfg.RegressionNetParams,
+		"localhost", "user", "pass", 0, nil); err == nil {
+
+		t.Fatal("expected error creating a conn without poll interval")
+	}
+	conn, err := NewBitcoindPollingConn(&chaincfg.RegressionNetParams,
+		strings.TrimPrefix(server.URL, "http://"), "user", "pass",
+		5*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := conn.NewBitcoindClient()
+	conn.AddClient(client)
+	if err := conn.Start(); err != nil {
+		t.Fatalf("unable to start conn: %v", err)
+	}
+	defer conn.Stop()
+
+	// Wait until the state of the node at startup was polled, which is
+	// not forwarded.
+	waitForCalls(t, node, "getbestblockhash", 1)
+	waitForCalls(t, node, "getrawmempool", 1)
+
+	tx := pollTestTx(1)
+	node.setMempool(pollTestTx(0), tx)
+	select {
+	case got := <-client.zmqTxNtfns:
+		if got.TxHash() != tx.TxHash() {
+			t.Fatalf("expected transaction %v, got %v",
+				tx.TxHash(), got.TxHash())
+		}
+	case block := <-client.zmqBlockNtfns:
+		t.Fatalf("unexpected block %v", block.BlockHash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification of the mempool transaction")
+	}
+
+	genesis := chaincfg.RegressionNetParams.GenesisBlock
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   1,
+			PrevBlock: genesis.BlockHash(),
+			Timestamp: time.Unix(genesis.Header.Timestamp.Unix()+1, 0),
+		},
+		Transactions: []*wire.MsgTx{tx},
+	}
+	node.setBest(block)
+	select {
+	case got := <-client.zmqBlockNtfns:
+		if got.BlockHash() != block.BlockHash() {
+			t.Fatalf("expected block %v, got %v",
+				block.BlockHash(), got.BlockHash())
+		}
+	case tx := <-client.zmqTxNtfns:
+		t.Fatalf("unexpected transaction %v", tx.TxHash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification of the new best block")
+	}
+
+	// Neither the unchanged best block nor the transactions remaining in
+	// the mempool are forwarded again.
+	blockPolls := node.callCount("getbestblockhash")
+	waitForCalls(t, node, "getbestblockhash", blockPolls+2)
+	select {
+	case block := <-client.zmqBlockNtfns:
+		t.Fatalf("unexpected block %v", block.BlockHash())
+	case tx := <-client.zmqTxNtfns:
+		t.Fatalf("unexpected transaction %v", tx.TxHash())
+	default:
+	}
+}
+
+// TestBitcoindFullBlockPeers ensures that only outbound peers serving full
+// blocks are used to retrieve pruned blocks.
+func TestBitcoindFullBlockPeers(t *testing.T) {
+	peers := []btcjson.GetPeerInfoResult{
+		{Addr: "10.0.0.1:8333", Services: "0000000000000409"},
+		{Addr: "10.0.0.2:51234", Services: "0000000000000409",
+			Inbound: true},
+		{Addr: "10.0.0.3:8333", Services: "0000000000000408"},
+		{Addr: "10.0.0.4:8333", Services: "invalid"},
+	}
+	addrs := bitcoindFullBlockPeers(peers)
+	if len(addrs) != 1 || addrs[0] != "10.0.0.1:8333" {
+		t.Fatalf("expected only the outbound full node, got %v", addrs)
+	}
+}
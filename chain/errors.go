@@ -0,0 +1,161 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// These errors are returned by the SendRawTransaction method of all chain
+// backends when the backend rejects a transaction, so that callers can decide
+// whether to rebroadcast, replace or abandon the transaction without matching
+// the messages of each backend.  They are returned wrapped in a PublishError
+// holding the rejection message of the backend, and can be tested for with
+// IsPublishError.  Rejections which are not described by any of these errors
+// are returned unchanged.
+var (
+	// ErrDoubleSpend is returned when the transaction spends an output
+	// already spent by another transaction in the mempool.
+	ErrDoubleSpend = errors.New("transaction double spends an output " +
+		"spent by a mempool transaction")
+
+	// ErrMempoolMinFee is returned when the fee of the transaction is below
+	// the minimum relay or mempool fee, or too low to replace the
+	// transactions it conflicts with.
+	ErrMempoolMinFee = errors.New("transaction fee is below the minimum " +
+		"mempool fee")
+
+	// ErrAlreadyInMempool describes the rejection of a transaction which
+	// is already in the mempool.  It is never returned by
+	// SendRawTransaction, which returns the hash of the transaction
+	// instead, as the transaction was published before.
+	ErrAlreadyInMempool = errors.New("transaction already in mempool")
+
+	// ErrMissingInputs is returned when the transaction spends outputs
+	// which are unknown or already spent in the block chain.
+	ErrMissingInputs = errors.New("transaction spends missing or spent " +
+		"outputs")
+
+	// ErrNonStandard is returned when the transaction, or one of its
+	// scripts, is rejected by the standardness policy of the mempool.
+	ErrNonStandard = errors.New("transaction is not standard")
+)
+
+// PublishError describes the rejection of a transaction by the mempool of a
+// chain backend.
+type PublishError struct {
+	// Err is the rejection error of this package describing the rejection,
+	// such as ErrDoubleSpend.
+	Err error
+
+	// Reason is the rejection message of the backend.
+	Reason string
+}
+
+// Error satisfies the error interface and prints the rejection error along
+// with the rejection message of the backend.
+func (e *PublishError) Error() string {
+	return e.Err.Error() + ": " + e.Reason
+}
+
+// IsPublishError returns whether err is the rejection described by target,
+// one of the rejection errors of this package such as ErrDoubleSpend.
+func IsPublishError(err, target error) bool {
+	if err == target {
+		return true
+	}
+	e, ok := err.(*PublishError)
+	return ok && e.Err == target
+}
+
+// publishErrMatch describes a rejection message of a backend, identified by
+// the substring reason, and the error it is returned as.
+type publishErrMatch struct {
+	reason string
+	err    error
+}
+
+// btcdPublishErrs are the rejection messages of the btcd mempool.  The double
+// spend message must be matched before the messages of spent outputs.
+var btcdPublishErrs = []publishErrMatch{
+	{"already have transaction", ErrAlreadyInMempool},
+	{"already spent by transaction", ErrDoubleSpend},
+	{"orphan transaction", ErrMissingInputs},
+	{"unknown or fully-spent", ErrMissingInputs},
+	{"under the required amount", ErrMempoolMinFee},
+	{"insufficient priority", ErrMempoolMinFee},
+	{"is not standard", ErrNonStandard},
+}
+
+// bitcoindPublishErrs are the rejection reasons of the bitcoind mempool.
+var bitcoindPublishErrs = []publishErrMatch{
+	{"txn-already-in-mempool", ErrAlreadyInMempool},
+	{"txn-already-known", ErrAlreadyInMempool},
+	{"txn-mempool-conflict", ErrDoubleSpend},
+	{"missing-inputs", ErrMissingInputs},
+	{"Missing inputs", ErrMissingInputs},
+	{"inputs-missingorspent", ErrMissingInputs},
+	{"min relay fee not met", ErrMempoolMinFee},
+	{"mempool min fee not met", ErrMempoolMinFee},
+	{"insufficient fee", ErrMempoolMinFee},
+	{"non-mandatory-script-verify-flag", ErrNonStandard},
+	{"scriptpubkey", ErrNonStandard},
+	{"scriptsig-size", ErrNonStandard},
+	{"scriptsig-not-pushonly", ErrNonStandard},
+	{"bare-multisig", ErrNonStandard},
+	{"dust", ErrNonStandard},
+	{"multi-op-return", ErrNonStandard},
+	{"tx-size", ErrNonStandard},
+	{"bad-txns-nonstandard-inputs", ErrNonStandard},
+}
+
+// mapPublishErr returns the PublishError describing the rejection err of a
+// backend whose rejection messages are described by matches, or err itself when
+// no message matches.
+func mapPublishErr(err error, matches ...[]publishErrMatch) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, m := range matches {
+		for _, match := range m {
+			if strings.Contains(msg, match.reason) {
+				log.Debugf("Transaction rejected: %v", msg)
+				return &PublishError{Err: match.err, Reason: msg}
+			}
+		}
+	}
+	return err
+}
+
+// publishResult returns the result of publishing tx to a backend whose
+// rejection messages are described by matches, given the rejection err of the
+// backend.  A transaction already in the mempool was published before, so its
+// hash is returned without error.
+func publishResult(tx *wire.MsgTx, err error,
+	matches ...[]publishErrMatch) (*chainhash.Hash, error) {
+
+	err = mapPublishErr(err, matches...)
+	if err != nil && !IsPublishError(err, ErrAlreadyInMempool) {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// These errors are returned by the CancelRescan method of chain backends
+// supporting the cancellation of rescans, and of the wallet.
+var (
+	// ErrRescanCanceled is returned by a rescan which was canceled before
+	// reaching the best block, and to the callers waiting for it.
+	ErrRescanCanceled = errors.New("rescan canceled")
+
+	// ErrNoRescan is returned when there is no running rescan to cancel.
+	ErrNoRescan = errors.New("no rescan is running")
+)
@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestPublishResult ensures that rejections of the backends are returned as
+// the rejection errors of this package along with their rejection message, and
+// that transactions already in the mempool are returned as published.
+func TestPublishResult(t *testing.T) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1e6, []byte{0x51}))
+	unknown := errors.New("-26: bad-txns-inputs-duplicate")
+
+	tests := []struct {
+		name      string
+		err       error
+		matches   []publishErrMatch
+		published bool
+		target    error
+	}{
+		{
+			name:      "published",
+			matches:   bitcoindPublishErrs,
+			published: true,
+		},
+		{
+			name:      "already in mempool",
+			err:       errors.New("-27: txn-already-in-mempool"),
+			matches:   bitcoindPublishErrs,
+			published: true,
+		},
+		{
+			name:    "double spend",
+			err:     errors.New("-26: txn-mempool-conflict"),
+			matches: bitcoindPublishErrs,
+			target:  ErrDoubleSpend,
+		},
+		{
+			name: "min fee",
+			err: errors.New("-22: TX rejected: transaction 00 has " +
+				"insufficient priority (1 <= 2)"),
+			matches: btcdPublishErrs,
+			target:  ErrMempoolMinFee,
+		},
+		{
+			name:    "unknown",
+			err:     unknown,
+			matches: bitcoindPublishErrs,
+			target:  unknown,
+		},
+	}
+	for _, test := range tests {
+		txid, err := publishResult(tx, test.err, test.matches)
+		if test.published {
+			if err != nil || txid == nil || *txid != tx.TxHash() {
+				t.Errorf("%s: unexpected publish result %v, %v",
+					test.name, txid, err)
+			}
+			continue
+		}
+		if txid != nil || !IsPublishError(err, test.target) {
+			t.Errorf("%s: expected %v, got %v, %v", test.name,
+				test.target, txid, err)
+			continue
+		}
+		if test.target == unknown {
+			if err != unknown {
+				t.Errorf("%s: expected unchanged error, got %v",
+					test.name, err)
+			}
+			continue
+		}
+		e, ok := err.(*PublishError)
+		if !ok || e.Reason != test.err.Error() {
+			t.Errorf("%s: expected rejection message %q, got %v",
+				test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// BackendHealth describes the health of the connection to a chain backend.
+type BackendHealth uint8
+
+// These constants describe the health of a chain backend.
+const (
+	// BackendHealthy indicates the backend answers probes and connects
+	// new blocks.
+	BackendHealthy BackendHealth = iota
+
+	// BackendUnreachable indicates the backend failed to answer several
+	// consecutive probes.
+	BackendUnreachable
+
+	// BackendStalled indicates the best block of the backend has not
+	// changed for longer than the stall timeout.
+	BackendStalled
+)
+
+var backendHealthStrings = [...]string{
+	BackendHealthy:     "healthy",
+	BackendUnreachable: "unreachable",
+	BackendStalled:     "stalled",
+}
+
+// String returns the BackendHealth as a human-readable string.
+func (h BackendHealth) String() string {
+	if int(h) < len(backendHealthStrings) {
+		return backendHealthStrings[h]
+	}
+	return "unknown"
+}
+
+// HealthStatus describes the health of a chain backend as determined by the
+// most recent probes of a HealthMonitor.
+type HealthStatus struct {
+	Health BackendHealth
+
+	// BestHash and BestHeight are the best block last reported by the
+	// backend, and LastBlockChange the time it was first reported.
+	BestHash        chainhash.Hash
+	BestHeight      int32
+	LastBlockChange time.Time
+
+	// Err is the error of the last failed probe of an unreachable
+	// backend.
+	Err error
+}
+
+// Degraded returns whether the backend is not healthy.
+func (s *HealthStatus) Degraded() bool {
+	return s.Health != BackendHealthy
+}
+
+// HealthMonitorConfig describes how a HealthMonitor probes a backend.
+type HealthMonitorConfig struct {
+	// ProbeInterval is the interval between two probes of the backend.
+	ProbeInterval time.Duration
+
+	// MaxFailures is the number of consecutive failed probes after which
+	// the backend is considered unreachable.
+	MaxFailures int
+
+	// StallTimeout is the time after which a backend whose best block has
+	// not changed is considered stalled.  Zero disables stall detection.
+	StallTimeout time.Duration
+
+	// OnChange is called with the status of the backend when its health
+	// changes.
+	OnChange func(HealthStatus)
+}
+
+// pinger is implemented by backends which can be pinged, such as btcd RPC
+// clients.
+type pinger interface {
+	Ping() error
+}
+
+// HealthMonitor periodically probes a chain backend by pinging it, when
+// supported, and requesting its best block, so that connections which fail or
+// stall are detected rather than leaving the wallet silently out of sync.
+type HealthMonitor struct {
+	client Interface
+	cfg    HealthMonitorConfig
+
+	mu     sync.Mutex
+	status HealthStatus
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHealthMonitor creates a monitor of the backend of client.  Probing does
+// not begin until the Start method is called.
+func NewHealthMonitor(client Interface, cfg *HealthMonitorConfig) (*HealthMonitor, error) {
+	if cfg.ProbeInterval <= 0 {
+		return nil, errors.New("health probe interval must be positive")
+	}
+	if cfg.MaxFailures <= 0 {
+		return nil, errors.New("health probe failures must be positive")
+	}
+	return &HealthMonitor{
+		client: client,
+		cfg:    *cfg,
+		status: HealthStatus{LastBlockChange: time.Now()},
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// Start begins probing the backend.
+func (m *HealthMonitor) Start() {
+	m.wg.Add(1)
+	go m.probeHandler()
+}
+
+// Stop ends probing the backend and waits for the last probe to finish.
+func (m *HealthMonitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// Status returns the status of the backend as of the most recent probe.
+func (m *HealthMonitor) Status() HealthStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// probeHandler probes the backend every probe interval until the monitor is
+// stopped.
+//
+// NOTE: This must be run as a goroutine.
+func (m *HealthMonitor) probeHandler() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.cfg.ProbeInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.quit:
+			return
+		}
+
+		err := m.probe()
+		if err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+
+		m.mu.Lock()
+		status := m.status
+		switch {
+		case err != nil && failures < m.cfg.MaxFailures:
+			// A single failed probe does not affect the health
+			// of a healthy backend.
+
+		case err != nil:
+			status.Health = BackendUnreachable
+			status.Err = err
+
+		case m.cfg.StallTimeout != 0 &&
+			time.Since(status.LastBlockChange) >= m.cfg.StallTimeout:
+
+			status.Health = BackendStalled
+			status.Err = nil
+
+		default:
+			status.Health = BackendHealthy
+			status.Err = nil
+		}
+		changed := status.Health != m.status.Health
+		m.status = status
+		m.mu.Unlock()
+
+		if !changed {
+			continue
+		}
+		switch status.Health {
+		case BackendHealthy:
+			log.Infof("Chain backend is healthy again at height %d",
+				status.BestHeight)
+		case BackendUnreachable:
+			log.Warnf("Chain backend is unreachable: %v", err)
+		case BackendStalled:
+			log.Warnf("Chain backend has not connected a block "+
+				"since %v", status.LastBlockChange.Format(
+				time.RFC3339))
+		}
+		if m.cfg.OnChange != nil {
+			m.cfg.OnChange(status)
+		}
+	}
+}
+
+// probe pings the backend and records its best block.
+func (m *HealthMonitor) probe() error {
+	if p, ok := m.client.(pinger); ok {
+		if err := p.Ping(); err != nil {
+			return err
+		}
+	}
+	hash, height, err := m.client.GetBestBlock()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if *hash != m.status.BestHash {
+		m.status.BestHash = *hash
+		m.status.BestHeight = height
+		m.status.LastBlockChange = time.Now()
+	}
+	m.mu.Unlock()
+	return nil
+}
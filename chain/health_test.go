@@ -0,0 +1,99 @@
+package chain_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/chain"
+)
+
+// mockBackend is a chain backend whose best block and reachability are set by
+// the test.  Only GetBestBlock is implemented.
+type mockBackend struct {
+	chain.Interface
+
+	mu     sync.Mutex
+	hash   chainhash.Hash
+	height int32
+	err    error
+}
+
+func (b *mockBackend) GetBestBlock() (*chainhash.Hash, int32, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, 0, b.err
+	}
+	hash := b.hash
+	return &hash, b.height, nil
+}
+
+func (b *mockBackend) set(height int32, err error) {
+	b.mu.Lock()
+	b.hash = chainhash.Hash{byte(height)}
+	b.height = height
+	b.err = err
+	b.mu.Unlock()
+}
+
+// TestHealthMonitor ensures that backends failing probes are reported as
+// unreachable, backends without new blocks as stalled, and that both are
+// reported healthy again once they recover.
+func TestHealthMonitor(t *testing.T) {
+	backend := &mockBackend{}
+	backend.set(1, nil)
+
+	changes := make(chan chain.HealthStatus, 10)
+	monitor, err := chain.NewHealthMonitor(backend, &chain.HealthMonitorConfig{
+		ProbeInterval: 10 * time.Millisecond,
+		MaxFailures:   2,
+		StallTimeout:  200 * time.Millisecond,
+		OnChange: func(status chain.HealthStatus) {
+			changes <- status
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor.Start()
+	defer monitor.Stop()
+
+	expect := func(health chain.BackendHealth) chain.HealthStatus {
+		select {
+		case status := <-changes:
+			if status.Health != health {
+				t.Fatalf("expected health %v, got %v", health,
+					status.Health)
+			}
+			return status
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for health %v", health)
+		}
+		panic("unreachable")
+	}
+
+	backendErr := errors.New("connection refused")
+	backend.set(1, backendErr)
+	status := expect(chain.BackendUnreachable)
+	if status.Err != backendErr {
+		t.Fatalf("expected error %v, got %v", backendErr, status.Err)
+	}
+
+	backend.set(2, nil)
+	status = expect(chain.BackendHealthy)
+	if status.BestHeight != 2 {
+		t.Fatalf("expected best height 2, got %d", status.BestHeight)
+	}
+	if status := monitor.Status(); status.Degraded() {
+		t.Fatalf("expected healthy monitor status, got %v",
+			status.Health)
+	}
+
+	// Without new blocks, the backend stalls until the next block.
+	expect(chain.BackendStalled)
+	backend.set(3, nil)
+	expect(chain.BackendHealthy)
+}
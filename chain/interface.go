@@ -1,6 +1,7 @@
 package chain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -20,6 +21,11 @@ func BackEnds() []string {
 	}
 }
 
+// ErrFeeEstimationUnsupported is returned by EstimateFeePerKb when the chain
+// backend is unable to estimate fees.
+var ErrFeeEstimationUnsupported = errors.New("fee estimation is not " +
+	"supported by the chain backend")
+
 // Interface allows more than one backing blockchain source, such as a
 // btcd RPC chain server, or an SPV library, as long as we write a driver for
 // it.
@@ -34,6 +40,7 @@ type Interface interface {
 	FilterBlocks(*FilterBlocksRequest) (*FilterBlocksResponse, error)
 	BlockStamp() (*waddrmgr.BlockStamp, error)
 	SendRawTransaction(*wire.MsgTx, bool) (*chainhash.Hash, error)
+	EstimateFeePerKb(confTarget uint32) (btcutil.Amount, error)
 	Rescan(*chainhash.Hash, []btcutil.Address, map[wire.OutPoint]btcutil.Address) error
 	NotifyReceived([]btcutil.Address) error
 	NotifyBlocks() error
@@ -51,8 +58,12 @@ type (
 	ClientConnected struct{}
 
 	// BlockConnected is a notification for a newly-attached block to the
-	// best chain.
-	BlockConnected wtxmgr.BlockMeta
+	// best chain.  Header is nil when the backend does not know the
+	// header of the block.
+	BlockConnected struct {
+		wtxmgr.BlockMeta
+		Header *wire.BlockHeader
+	}
 
 	// FilteredBlockConnected is an alternate notification that contains
 	// both block and relevant transaction information in one struct, which
@@ -90,8 +101,12 @@ type (
 	}
 
 	// BlockDisconnected is a notifcation that the block described by the
-	// BlockStamp was reorganized out of the best chain.
-	BlockDisconnected wtxmgr.BlockMeta
+	// BlockStamp was reorganized out of the best chain.  Header is nil
+	// when the backend no longer knows the header of the block.
+	BlockDisconnected struct {
+		wtxmgr.BlockMeta
+		Header *wire.BlockHeader
+	}
 
 	// RelevantTx is a notification for a transaction which spends wallet
 	// inputs or pays to a watched address.
@@ -34,6 +34,12 @@ type NeutrinoClient struct {
 	lastProgressSent    bool
 	currentBlock        chan *waddrmgr.BlockStamp
 
+	// filterFetchWorkers is the number of compact filters requested in
+	// parallel by FilterBlocks, and filterFetchBatchSize the number of
+	// filters fetched at once before they are matched.
+	filterFetchWorkers   int
+	filterFetchBatchSize int
+
 	quit       chan struct{}
 	rescanQuit chan struct{}
 	rescanErr  <-chan error
@@ -43,6 +49,12 @@ type NeutrinoClient struct {
 	finished   bool
 	isRescan   bool
 
+	// watchedAddrs and watchedInputs are the addresses and inputs watched
+	// by the running rescan, which continue to be watched for new blocks
+	// when the rescan is canceled.
+	watchedAddrs  []btcutil.Address
+	watchedInputs []neutrino.InputWithScript
+
 	clientMtx sync.Mutex
 }
 
@@ -52,8 +64,27 @@ func NewNeutrinoClient(chainParams *chaincfg.Params,
 	chainService *neutrino.ChainService) *NeutrinoClient {
 
 	return &NeutrinoClient{
-		CS:          chainService,
-		chainParams: chainParams,
+		CS:                   chainService,
+		chainParams:          chainParams,
+		filterFetchWorkers:   1,
+		filterFetchBatchSize: 1,
+	}
+}
+
+// SetFilterFetching sets the number of compact filters requested in parallel
+// when filtering blocks, and the number of filters fetched at once before they
+// are matched against the watched addresses and outpoints.  Fetching filters
+// in parallel speeds up initial syncs and recoveries on good connections, at
+// the cost of fetching filters past the first matching block.  Both default to
+// one, and values below one are ignored.
+//
+// NOTE: This should be done before the client has been started.
+func (s *NeutrinoClient) SetFilterFetching(workers, batchSize int) {
+	if workers > 0 {
+		s.filterFetchWorkers = workers
+	}
+	if batchSize > 0 {
+		s.filterFetchBatchSize = batchSize
 	}
 }
 
@@ -101,6 +132,12 @@ func (s *NeutrinoClient) WaitForShutdown() {
 	s.wg.Wait()
 }
 
+// EstimateFeePerKb returns ErrFeeEstimationUnsupported, as fee rates can not be
+// estimated from compact filters and headers.
+func (s *NeutrinoClient) EstimateFeePerKb(uint32) (btcutil.Amount, error) {
+	return 0, ErrFeeEstimationUnsupported
+}
+
 // GetBlock replicates the RPC client's GetBlock command.
 func (s *NeutrinoClient) GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
 	// TODO(roasbeef): add a block cache?
@@ -157,14 +194,13 @@ func (s *NeutrinoClient) GetBlockHeader(
 }
 
 // SendRawTransaction replicates the RPC client's SendRawTransaction command.
+// As peers may run either btcd or bitcoind, the rejections of both are
+// returned as the rejection errors of this package, such as ErrDoubleSpend,
+// while transactions already in the mempool are returned as published.
 func (s *NeutrinoClient) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (
 	*chainhash.Hash, error) {
 	err := s.CS.SendTransaction(tx)
-	if err != nil {
-		return nil, err
-	}
-	hash := tx.TxHash()
-	return &hash, nil
+	return publishResult(tx, err, btcdPublishErrs, bitcoindPublishErrs)
 }
 
 // FilterBlocks scans the blocks contained in the FilterBlocksRequest for any
@@ -190,11 +226,22 @@ func (s *NeutrinoClient) FilterBlocks(
 	// each one, and matching it against the watchlist generated above. If
 	// the filter returns a positive match, the full block is then requested
 	// and scanned for addresses using the block filterer.
+	var filters []*gcs.Filter
 	for i, blk := range req.Blocks {
-		filter, err := s.pollCFilter(&blk.Hash)
-		if err != nil {
-			return nil, err
+		// Fetch the filters of the next batch of blocks once the
+		// filters of the previous batch have been matched.
+		batchIndex := i % s.filterFetchBatchSize
+		if batchIndex == 0 {
+			end := i + s.filterFetchBatchSize
+			if end > len(req.Blocks) {
+				end = len(req.Blocks)
+			}
+			filters, err = s.fetchCFilters(req.Blocks[i:end])
+			if err != nil {
+				return nil, err
+			}
 		}
+		filter := filters[batchIndex]
 
 		// Skip any empty filters.
 		if filter == nil || filter.N() == 0 {
@@ -316,6 +363,61 @@ func (s *NeutrinoClient) pollCFilter(hash *chainhash.Hash) (*gcs.Filter, error)
 	return nil, err
 }
 
+// fetchCFilters fetches the compact filters of blocks, requesting up to the
+// configured number of filters in parallel.  The filters are returned in the
+// order of the blocks.
+func (s *NeutrinoClient) fetchCFilters(
+	blocks []wtxmgr.BlockMeta) ([]*gcs.Filter, error) {
+
+	filters := make([]*gcs.Filter, len(blocks))
+	if s.filterFetchWorkers == 1 || len(blocks) == 1 {
+		for i := range blocks {
+			filter, err := s.pollCFilter(&blocks[i].Hash)
+			if err != nil {
+				return nil, err
+			}
+			filters[i] = filter
+		}
+		return filters, nil
+	}
+
+	workers := s.filterFetchWorkers
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	indexes := make(chan int, len(blocks))
+	for i := range blocks {
+		indexes <- i
+	}
+	close(indexes)
+
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range indexes {
+				filter, err := s.pollCFilter(&blocks[i].Hash)
+				if err != nil {
+					errs <- err
+					return
+				}
+				filters[i] = filter
+			}
+			errs <- nil
+		}()
+	}
+
+	var err error
+	for w := 0; w < workers; w++ {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
 // Rescan replicates the RPC client's Rescan command.
 func (s *NeutrinoClient) Rescan(startHash *chainhash.Hash, addrs []btcutil.Address,
 	outPoints map[wire.OutPoint]btcutil.Address) error {
@@ -380,6 +482,8 @@ func (s *NeutrinoClient) Rescan(startHash *chainhash.Hash, addrs []btcutil.Addre
 			PkScript: addrScript,
 		})
 	}
+	s.watchedAddrs = append([]btcutil.Address(nil), addrs...)
+	s.watchedInputs = inputsToWatch
 
 	newRescan := s.CS.NewRescan(
 		neutrino.NotificationHandlers(rpcclient.NotificationHandlers{
@@ -419,10 +523,24 @@ func (s *NeutrinoClient) NotifyReceived(addrs []btcutil.Address) error {
 	// If we have a rescan running, we just need to add the appropriate
 	// addresses to the watch list.
 	if s.scanning {
+		s.watchedAddrs = append(s.watchedAddrs, addrs...)
 		s.clientMtx.Unlock()
 		return s.rescan.Update(neutrino.AddAddrs(addrs...))
 	}
 
+	s.watchedAddrs = append([]btcutil.Address(nil), addrs...)
+	s.watchedInputs = nil
+	s.startNotifications()
+	s.clientMtx.Unlock()
+	return nil
+}
+
+// startNotifications starts a rescan from the best block which only notifies
+// new blocks and the transactions relevant to the watched addresses and
+// inputs.
+//
+// NOTE: This must be called with the clientMtx held.
+func (s *NeutrinoClient) startNotifications() {
 	s.rescanQuit = make(chan struct{})
 	s.scanning = true
 
@@ -439,11 +557,31 @@ func (s *NeutrinoClient) NotifyReceived(addrs []btcutil.Address) error {
 		}),
 		neutrino.StartTime(s.startTime),
 		neutrino.QuitChan(s.rescanQuit),
-		neutrino.WatchAddrs(addrs...),
+		neutrino.WatchAddrs(s.watchedAddrs...),
+		neutrino.WatchInputs(s.watchedInputs...),
 	)
 	s.rescan = newRescan
 	s.rescanErr = s.rescan.Start()
+}
+
+// CancelRescan cancels the running rescan without sending a RescanFinished
+// notification.  The addresses and inputs of the rescan continue to be watched
+// from the best block on.  ErrNoRescan is returned when no rescan is running.
+func (s *NeutrinoClient) CancelRescan() error {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	if !s.scanning || !s.isRescan || s.finished {
+		return ErrNoRescan
+	}
+
+	close(s.rescanQuit)
 	s.clientMtx.Unlock()
+	s.rescan.WaitForShutdown()
+	s.clientMtx.Lock()
+	log.Infof("Canceled rescan")
+
+	s.isRescan = false
+	s.startNotifications()
 	return nil
 }
 
@@ -538,13 +676,17 @@ func (s *NeutrinoClient) onFilteredBlockConnected(height int32,
 // channel.
 func (s *NeutrinoClient) onBlockDisconnected(hash *chainhash.Hash, height int32,
 	t time.Time) {
+	// The header of the disconnected block is no longer known by the
+	// chain service.
 	select {
 	case s.enqueueNotification <- BlockDisconnected{
-		Block: wtxmgr.Block{
-			Hash:   *hash,
-			Height: height,
+		BlockMeta: wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   *hash,
+				Height: height,
+			},
+			Time: t,
 		},
-		Time: t,
 	}:
 	case <-s.quit:
 	case <-s.rescanQuit:
@@ -594,13 +736,25 @@ func (s *NeutrinoClient) onBlockConnected(hash *chainhash.Hash, height int32,
 			}
 		}
 		s.clientMtx.Unlock()
+
+		// The header is read from the headers of the chain service,
+		// which has just connected the block.
+		header, err := s.CS.GetBlockHeader(hash)
+		if err != nil {
+			log.Warnf("Unable to fetch header of block %v: %v",
+				hash, err)
+			header = nil
+		}
 		select {
 		case s.enqueueNotification <- BlockConnected{
-			Block: wtxmgr.Block{
-				Hash:   *hash,
-				Height: height,
+			BlockMeta: wtxmgr.BlockMeta{
+				Block: wtxmgr.Block{
+					Hash:   *hash,
+					Height: height,
+				},
+				Time: time,
 			},
-			Time: time,
+			Header: header,
 		}:
 		case <-s.quit:
 		case <-s.rescanQuit:
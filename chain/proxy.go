@@ -0,0 +1,71 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+
+	"github.com/btcsuite/btcd/connmgr"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/go-socks/socks"
+)
+
+// Proxy describes a SOCKS5 proxy, such as Tor, through which the connections
+// to chain servers and peers are made.  Connecting through the proxy allows
+// reaching .onion endpoints when the proxy is Tor.
+type Proxy struct {
+	Addr string
+	User string
+	Pass string
+
+	// IsolateStreams authenticates each connection with random
+	// credentials, which Tor uses to send the connections over separate
+	// circuits.  The User and Pass credentials are not used.
+	IsolateStreams bool
+}
+
+// credentials returns the credentials to authenticate a new connection with.
+func (p *Proxy) credentials() (user, pass string) {
+	if !p.IsolateStreams {
+		return p.User, p.Pass
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Without randomness, the connection is not isolated but is
+		// still proxied.
+		log.Warnf("Unable to generate proxy credentials: %v", err)
+		return p.User, p.Pass
+	}
+	return hex.EncodeToString(b[:8]), hex.EncodeToString(b[8:])
+}
+
+// Dial connects to addr through the proxy.
+func (p *Proxy) Dial(network, addr string) (net.Conn, error) {
+	user, pass := p.credentials()
+	proxy := &socks.Proxy{
+		Addr:     p.Addr,
+		Username: user,
+		Password: pass,
+	}
+	return proxy.Dial(network, addr)
+}
+
+// LookupIP resolves host through the proxy, so that names are not leaked to
+// the local resolver.  This requires the proxy to be Tor.
+func (p *Proxy) LookupIP(host string) ([]net.IP, error) {
+	return connmgr.TorLookupIP(host, p.Addr)
+}
+
+// setRPCProxy configures an RPC connection to be made through proxy, unless
+// proxy is nil.
+func setRPCProxy(connConfig *rpcclient.ConnConfig, proxy *Proxy) {
+	if proxy == nil {
+		return
+	}
+	connConfig.Proxy = proxy.Addr
+	connConfig.ProxyUser, connConfig.ProxyPass = proxy.credentials()
+}
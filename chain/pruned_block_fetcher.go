@@ -0,0 +1,220 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	// prunedBlockFetchTimeout is the time allowed for connecting to a peer
+	// and retrieving a block from it.
+	prunedBlockFetchTimeout = time.Minute
+
+	// prunedBlockPeerServices are the services peers must provide for
+	// pruned blocks to be retrieved from them: serving the full chain,
+	// including the witness data of blocks.
+	prunedBlockPeerServices = wire.SFNodeNetwork | wire.SFNodeWitness
+)
+
+// errNoPrunedBlockPeers is returned when no peer can serve pruned blocks.
+var errNoPrunedBlockPeers = errors.New("no peers serving full blocks")
+
+// PrunedBlockFetcher retrieves blocks which have been pruned by the backend
+// node from peers of the bitcoin network, so that rescans against pruned nodes
+// do not fail.  Blocks are only accepted when their transactions match the
+// merkle root and witness commitment of the requested block.
+type PrunedBlockFetcher struct {
+	chainParams *chaincfg.Params
+
+	// peers returns the addresses of the peers to retrieve blocks from,
+	// in the order they are tried.
+	peers func() ([]string, error)
+
+	// dial opens a connection to a peer.
+	dial func(addr string) (net.Conn, error)
+
+	timeout time.Duration
+}
+
+// NewPrunedBlockFetcher creates a fetcher retrieving blocks from the peers
+// returned by peers.
+func NewPrunedBlockFetcher(chainParams *chaincfg.Params,
+	peers func() ([]string, error)) *PrunedBlockFetcher {
+
+	return &PrunedBlockFetcher{
+		chainParams: chainParams,
+		peers:       peers,
+		dial: func(addr string) (net.Conn, error) {
+			return net.DialTimeout("tcp", addr,
+				prunedBlockFetchTimeout)
+		},
+		timeout: prunedBlockFetchTimeout,
+	}
+}
+
+// FetchBlock retrieves the block with the given hash, trying each peer until
+// one serves it.
+func (f *PrunedBlockFetcher) FetchBlock(
+	hash *chainhash.Hash) (*wire.MsgBlock, error) {
+
+	addrs, err := f.peers()
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errNoPrunedBlockPeers
+	}
+
+	for _, addr := range addrs {
+		block, err := f.fetchFromPeer(addr, hash)
+		if err != nil {
+			log.Debugf("Unable to fetch pruned block %v from peer "+
+				"%v: %v", hash, addr, err)
+			continue
+		}
+
+		log.Infof("Fetched pruned block %v from peer %v", hash, addr)
+		return block, nil
+	}
+	return nil, fmt.Errorf("unable to fetch pruned block %v from any of "+
+		"%d peers", hash, len(addrs))
+}
+
+// fetchFromPeer connects to the peer at addr and requests the block with the
+// given hash from it.
+func (f *PrunedBlockFetcher) fetchFromPeer(addr string,
+	hash *chainhash.Hash) (*wire.MsgBlock, error) {
+
+	verAck := make(chan struct{}, 1)
+	blocks := make(chan *wire.MsgBlock, 1)
+	notFound := make(chan struct{}, 1)
+	cfg := &peer.Config{
+		UserAgentName:    "btcwallet",
+		UserAgentVersion: "0.1",
+		ChainParams:      f.chainParams,
+		DisableRelayTx:   true,
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(*peer.Peer, *wire.MsgVerAck) {
+				select {
+				case verAck <- struct{}{}:
+				default:
+				}
+			},
+			OnBlock: func(_ *peer.Peer, msg *wire.MsgBlock, _ []byte) {
+				if msg.BlockHash() != *hash {
+					return
+				}
+				select {
+				case blocks <- msg:
+				default:
+				}
+			},
+			OnNotFound: func(*peer.Peer, *wire.MsgNotFound) {
+				select {
+				case notFound <- struct{}{}:
+				default:
+				}
+			},
+		},
+	}
+	p, err := peer.NewOutboundPeer(cfg, addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := f.dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	p.AssociateConnection(conn)
+	defer func() {
+		p.Disconnect()
+		p.WaitForDisconnect()
+	}()
+
+	timeout := time.After(f.timeout)
+	select {
+	case <-verAck:
+	case <-timeout:
+		return nil, errors.New("timeout waiting for handshake")
+	}
+	if p.Services()&prunedBlockPeerServices != prunedBlockPeerServices {
+		return nil, errors.New("peer does not serve full blocks")
+	}
+
+	getData := wire.NewMsgGetData()
+	err = getData.AddInvVect(wire.NewInvVect(wire.InvTypeWitnessBlock, hash))
+	if err != nil {
+		return nil, err
+	}
+	p.QueueMessage(getData, nil)
+
+	select {
+	case block := <-blocks:
+		if err := checkBlockTransactions(block); err != nil {
+			return nil, err
+		}
+		return block, nil
+	case <-notFound:
+		return nil, errors.New("block not found")
+	case <-timeout:
+		return nil, errors.New("timeout waiting for block")
+	}
+}
+
+// checkBlockTransactions verifies that the transactions of a block received
+// from a peer are the ones committed to by its header, as the header itself is
+// checked against the requested block hash.
+func checkBlockTransactions(block *wire.MsgBlock) error {
+	blk := btcutil.NewBlock(block)
+	merkles := blockchain.BuildMerkleTreeStore(blk.Transactions(), false)
+	if *merkles[len(merkles)-1] != block.Header.MerkleRoot {
+		return errors.New("block transactions do not match the " +
+			"merkle root")
+	}
+	return blockchain.ValidateWitnessCommitment(blk)
+}
+
+// bitcoindFullBlockPeers returns the addresses of the peers of a bitcoind node
+// which serve full blocks.  Inbound peers are skipped, as the address of their
+// connection is not one they accept connections on.
+func bitcoindFullBlockPeers(peers []btcjson.GetPeerInfoResult) []string {
+	var addrs []string
+	for _, p := range peers {
+		if p.Inbound {
+			continue
+		}
+		services, err := strconv.ParseUint(p.Services, 16, 64)
+		if err != nil {
+			continue
+		}
+		flags := wire.ServiceFlag(services)
+		if flags&prunedBlockPeerServices != prunedBlockPeerServices {
+			continue
+		}
+		addrs = append(addrs, p.Addr)
+	}
+	return addrs
+}
+
+// isPrunedBlockErr returns whether err is the error returned by bitcoind when
+// a requested block has been pruned.
+func isPrunedBlockErr(err error) bool {
+	rpcErr, ok := err.(*btcjson.RPCError)
+	return ok && strings.Contains(rpcErr.Message, "pruned")
+}
@@ -0,0 +1,144 @@
+package chain
+
+import (
+	"math"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// prunedTestBlock returns a block with a single coinbase transaction paying
+// value.
+func prunedTestBlock(value int64) *wire.MsgBlock {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: math.MaxUint32},
+		SignatureScript:  []byte{0x51, 0x51},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(value, []byte{0x51}))
+	return &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: coinbase.TxHash(),
+			Timestamp:  time.Unix(1500000000, 0),
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+}
+
+// servePrunedTestPeer performs the handshake of a peer providing services on
+// conn, and serves block to the peers requesting it.  The peer package is not
+// used, so that the nonces of this peer are not mistaken for the nonces of
+// the fetcher in the same process.
+func servePrunedTestPeer(conn net.Conn, services wire.ServiceFlag,
+	block *wire.MsgBlock) {
+
+	defer conn.Close()
+	pver := wire.ProtocolVersion
+	btcnet := chaincfg.MainNetParams.Net
+	for {
+		msg, _, err := wire.ReadMessage(conn, pver, btcnet)
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *wire.MsgVersion:
+			na := wire.NewNetAddressIPPort(net.IPv4(127, 0, 0, 1),
+				8333, services)
+			version := wire.NewMsgVersion(na, na, 1, 0)
+			version.Services = services
+			if wire.WriteMessage(conn, version, pver, btcnet) != nil {
+				return
+			}
+			verAck := wire.NewMsgVerAck()
+			if wire.WriteMessage(conn, verAck, pver, btcnet) != nil {
+				return
+			}
+
+		case *wire.MsgGetData:
+			for _, iv := range msg.InvList {
+				var reply wire.Message = block
+				if iv.Hash != block.BlockHash() {
+					notFound := wire.NewMsgNotFound()
+					notFound.AddInvVect(iv)
+					reply = notFound
+				}
+				if wire.WriteMessage(conn, reply, pver, btcnet) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// TestPrunedBlockFetcher ensures that blocks are retrieved from the first peer
+// serving full blocks with the transactions committed to by the requested
+// block, and that unknown blocks are reported as not found.
+func TestPrunedBlockFetcher(t *testing.T) {
+	block := prunedTestBlock(5e9)
+
+	// The tampered block has the header of the requested block, but other
+	// transactions.
+	tampered := *block
+	tampered.Transactions = prunedTestBlock(1e9).Transactions
+
+	const fullServices = prunedBlockPeerServices
+	peers := []struct {
+		addr     string
+		services wire.ServiceFlag
+		block    *wire.MsgBlock
+	}{
+		{"10.0.0.1:8333", wire.SFNodeNetwork, block},
+		{"10.0.0.2:8333", fullServices, &tampered},
+		{"10.0.0.3:8333", fullServices, block},
+	}
+	var addrs []string
+	for _, p := range peers {
+		addrs = append(addrs, p.addr)
+	}
+
+	fetcher := NewPrunedBlockFetcher(&chaincfg.MainNetParams,
+		func() ([]string, error) {
+			return addrs, nil
+		})
+	fetcher.timeout = 5 * time.Second
+	var dialed []string
+	fetcher.dial = func(addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		local, remote := net.Pipe()
+		for _, p := range peers {
+			if p.addr == addr {
+				go servePrunedTestPeer(remote, p.services, p.block)
+			}
+		}
+		return local, nil
+	}
+
+	hash := block.BlockHash()
+	fetched, err := fetcher.FetchBlock(&hash)
+	if err != nil {
+		t.Fatalf("unable to fetch block: %v", err)
+	}
+	if !reflect.DeepEqual(fetched, block) {
+		t.Fatalf("fetched block %v, expected %v", fetched.BlockHash(),
+			hash)
+	}
+	if !reflect.DeepEqual(dialed, addrs) {
+		t.Fatalf("dialed peers %v, expected %v", dialed, addrs)
+	}
+
+	// Each peer is tried for an unknown block, which none of them serve.
+	dialed = nil
+	if _, err := fetcher.FetchBlock(&chainhash.Hash{1}); err == nil {
+		t.Fatal("expected error fetching unknown block")
+	}
+	if !reflect.DeepEqual(dialed, addrs) {
+		t.Fatalf("dialed peers %v, expected %v", dialed, addrs)
+	}
+}
@@ -44,9 +44,10 @@ type RPCClient struct {
 // provided in the certs slice.  The connection is not established immediately,
 // but must be done using the Start method.  If the remote server does not
 // operate on the same bitcoin network as described by the passed chain
-// parameters, the connection will be disconnected.
+// parameters, the connection will be disconnected.  When proxy is not nil, the
+// connection is made through it.
 func NewRPCClient(chainParams *chaincfg.Params, connect, user, pass string, certs []byte,
-	disableTLS bool, reconnectAttempts int) (*RPCClient, error) {
+	disableTLS bool, reconnectAttempts int, proxy *Proxy) (*RPCClient, error) {
 
 	if reconnectAttempts < 0 {
 		return nil, errors.New("reconnectAttempts must be positive")
@@ -70,14 +71,15 @@ func NewRPCClient(chainParams *chaincfg.Params, connect, user, pass string, cert
 		currentBlock:        make(chan *waddrmgr.BlockStamp),
 		quit:                make(chan struct{}),
 	}
+	setRPCProxy(client.connConfig, proxy)
 	ntfnCallbacks := &rpcclient.NotificationHandlers{
-		OnClientConnected:   client.onClientConnect,
-		OnBlockConnected:    client.onBlockConnected,
-		OnBlockDisconnected: client.onBlockDisconnected,
-		OnRecvTx:            client.onRecvTx,
-		OnRedeemingTx:       client.onRedeemingTx,
-		OnRescanFinished:    client.onRescanFinished,
-		OnRescanProgress:    client.onRescanProgress,
+		OnClientConnected:           client.onClientConnect,
+		OnFilteredBlockConnected:    client.onFilteredBlockConnected,
+		OnFilteredBlockDisconnected: client.onFilteredBlockDisconnected,
+		OnRecvTx:                    client.onRecvTx,
+		OnRedeemingTx:               client.onRedeemingTx,
+		OnRescanFinished:            client.onRescanFinished,
+		OnRescanProgress:            client.onRescanProgress,
 	}
 	rpcClient, err := rpcclient.New(client.connConfig, ntfnCallbacks)
 	if err != nil {
@@ -92,6 +94,31 @@ func (c *RPCClient) BackEnd() string {
 	return "btcd"
 }
 
+// SendRawTransaction submits the encoded transaction to the server.
+// Rejections by the btcd mempool are returned as the rejection errors of this
+// package, such as ErrDoubleSpend, while transactions already in the mempool
+// are returned as published.
+func (c *RPCClient) SendRawTransaction(tx *wire.MsgTx,
+	allowHighFees bool) (*chainhash.Hash, error) {
+
+	txid, err := c.Client.SendRawTransaction(tx, allowHighFees)
+	if err != nil {
+		return publishResult(tx, err, btcdPublishErrs)
+	}
+	return txid, nil
+}
+
+// GetBlockHeight returns the height for the hash, if known, or returns an
+// error.
+func (c *RPCClient) GetBlockHeight(hash *chainhash.Hash) (int32, error) {
+	header, err := c.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Height, nil
+}
+
 // Start attempts to establish a client connection with the remote server.
 // If successful, handler goroutines are started to process notifications
 // sent by the server.  After a limited number of connection attempts, this
@@ -181,6 +208,20 @@ func (c *RPCClient) BlockStamp() (*waddrmgr.BlockStamp, error) {
 	}
 }
 
+// EstimateFeePerKb returns the fee rate, in satoshis per kilobyte, the server
+// estimates is required for a transaction to confirm within confTarget
+// blocks.
+func (c *RPCClient) EstimateFeePerKb(confTarget uint32) (btcutil.Amount, error) {
+	feeRate, err := c.EstimateFee(int64(confTarget))
+	if err != nil {
+		return 0, err
+	}
+	if feeRate <= 0 {
+		return 0, errors.New("not enough data to estimate fee")
+	}
+	return btcutil.NewAmount(feeRate)
+}
+
 // FilterBlocks scans the blocks contained in the FilterBlocksRequest for any
 // addresses of interest. For each requested block, the corresponding compact
 // filter will first be checked for matches, skipping those that do not report
@@ -296,27 +337,41 @@ func (c *RPCClient) onClientConnect() {
 	}
 }
 
-func (c *RPCClient) onBlockConnected(hash *chainhash.Hash, height int32, time time.Time) {
+// onFilteredBlockConnected queues a BlockConnected notification with the
+// header of the block.  The transactions are those matching the transaction
+// filter of the client, which is not used, as relevant transactions are
+// notified by recvtx and redeemingtx notifications.
+func (c *RPCClient) onFilteredBlockConnected(height int32,
+	header *wire.BlockHeader, _ []*btcutil.Tx) {
+
 	select {
 	case c.enqueueNotification <- BlockConnected{
-		Block: wtxmgr.Block{
-			Hash:   *hash,
-			Height: height,
+		BlockMeta: wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   header.BlockHash(),
+				Height: height,
+			},
+			Time: header.Timestamp,
 		},
-		Time: time,
+		Header: header,
 	}:
 	case <-c.quit:
 	}
 }
 
-func (c *RPCClient) onBlockDisconnected(hash *chainhash.Hash, height int32, time time.Time) {
+func (c *RPCClient) onFilteredBlockDisconnected(height int32,
+	header *wire.BlockHeader) {
+
 	select {
 	case c.enqueueNotification <- BlockDisconnected{
-		Block: wtxmgr.Block{
-			Hash:   *hash,
-			Height: height,
+		BlockMeta: wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   header.BlockHash(),
+				Height: height,
+			},
+			Time: header.Timestamp,
 		},
-		Time: time,
+		Header: header,
 	}:
 	case <-c.quit:
 	}
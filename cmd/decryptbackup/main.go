@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/golangcrypto/ssh/terminal"
+	"github.com/jessevdk/go-flags"
+)
+
+// Flags.
+var opts = struct {
+	Backup string `long:"backup" description:"Path of the encrypted backup" required:"true"`
+	Out    string `long:"out" description:"Path of the decrypted wallet database" required:"true"`
+}{}
+
+func init() {
+	_, err := flags.Parse(&opts)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func main() {
+	os.Exit(mainInt())
+}
+
+func mainInt() int {
+	in, err := os.Open(opts.Backup)
+	if err != nil {
+		fmt.Println("Failed to open backup:", err)
+		return 1
+	}
+	defer in.Close()
+
+	fmt.Print("Backup passphrase: ")
+	pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Print("\n")
+	if err != nil {
+		fmt.Println("Failed to read passphrase:", err)
+		return 1
+	}
+	pass = bytes.TrimSpace(pass)
+
+	out, err := os.OpenFile(opts.Out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		fmt.Println("Failed to create wallet database:", err)
+		return 1
+	}
+	err = wallet.DecryptBackup(out, in, pass)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(opts.Out)
+		fmt.Println("Failed to decrypt backup:", err)
+		return 1
+	}
+
+	fmt.Println("Decrypted wallet database written to", opts.Out)
+	return 0
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/postgres"
+	_ "github.com/btcsuite/btcwallet/walletdb/sqlite"
+	"github.com/jessevdk/go-flags"
+)
+
+const defaultNet = "mainnet"
+
+var datadir = btcutil.AppDataDir("btcwallet", false)
+
+// Flags.
+var opts = struct {
+	From    string `long:"from" description:"Database backend of the source database {bdb, sqlite, postgres}"`
+	FromDB  string `long:"fromdb" description:"Path or connection string of the source database"`
+	To      string `long:"to" description:"Database backend of the destination database {bdb, sqlite, postgres}"`
+	ToDB    string `long:"todb" description:"Path or connection string of the destination database"`
+	Dump    string `long:"dump" description:"Dump the source database to this file instead of copying it to the destination database"`
+	Restore string `long:"restore" description:"Restore the destination database from this dump file instead of the source database"`
+}{
+	From:   "bdb",
+	FromDB: filepath.Join(datadir, defaultNet, "wallet.db"),
+	To:     "sqlite",
+}
+
+func init() {
+	_, err := flags.Parse(&opts)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func main() {
+	os.Exit(mainInt())
+}
+
+func mainInt() int {
+	if opts.Dump != "" && opts.Restore != "" {
+		fmt.Println("The --dump and --restore options can not be combined")
+		return 1
+	}
+
+	// Open the source database unless restoring a dump.
+	var src walletdb.DB
+	if opts.Restore == "" {
+		fmt.Printf("Source database: %s %s\n", opts.From, opts.FromDB)
+		var err error
+		src, err = walletdb.Open(opts.From, opts.FromDB)
+		if err != nil {
+			fmt.Println("Failed to open source database:", err)
+			return 1
+		}
+		defer src.Close()
+	}
+
+	if opts.Dump != "" {
+		fmt.Println("Dumping database to", opts.Dump)
+		if err := dump(src, opts.Dump); err != nil {
+			fmt.Println("Failed to dump database:", err)
+			return 1
+		}
+		return printChecksums(src)
+	}
+
+	if opts.ToDB == "" {
+		fmt.Println("The destination database must be set with --todb")
+		return 1
+	}
+	fmt.Printf("Destination database: %s %s\n", opts.To, opts.ToDB)
+	dst, err := walletdb.Create(opts.To, opts.ToDB)
+	if err != nil {
+		fmt.Println("Failed to create destination database:", err)
+		return 1
+	}
+	defer dst.Close()
+
+	if opts.Restore != "" {
+		fmt.Println("Restoring database from", opts.Restore)
+		err = restore(dst, opts.Restore)
+	} else {
+		fmt.Println("Copying database")
+		err = walletdb.Migrate(dst, src)
+	}
+	if err != nil {
+		fmt.Println("Failed to copy database:", err)
+		return 1
+	}
+	return printChecksums(dst)
+}
+
+// dump writes the dump of db to the file at path.
+func dump(db walletdb.DB, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := walletdb.Dump(db, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// restore restores db from the dump in the file at path.
+func restore(db walletdb.DB, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return walletdb.Restore(db, f)
+}
+
+// printChecksums prints the checksums of the top-level buckets of db, which
+// can be compared with the checksums of other copies of the database.
+func printChecksums(db walletdb.DB) int {
+	sums, err := walletdb.Checksums(db)
+	if err != nil {
+		fmt.Println("Failed to verify checksums:", err)
+		return 1
+	}
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Bucket checksums:")
+	for _, name := range names {
+		fmt.Printf("  %x %q\n", sums[name], name)
+	}
+	return 0
+}
@@ -19,21 +19,29 @@ import (
 	"github.com/btcsuite/btcwallet/internal/cfgutil"
 	"github.com/btcsuite/btcwallet/internal/legacy/keystore"
 	"github.com/btcsuite/btcwallet/netparams"
+	"github.com/btcsuite/btcwallet/rpc/macaroons"
+	"github.com/btcsuite/btcwallet/rpc/rpcpolicy"
 	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcwallet/walletdb"
 	flags "github.com/jessevdk/go-flags"
 	"github.com/lightninglabs/neutrino"
 )
 
 const (
-	defaultCAFilename       = "btcd.cert"
-	defaultConfigFilename   = "btcwallet.conf"
-	defaultLogLevel         = "info"
-	defaultLogDirname       = "logs"
-	defaultLogFilename      = "btcwallet.log"
-	defaultRPCMaxClients    = 10
-	defaultRPCMaxWebsockets = 25
-
-	walletDbName = "wallet.db"
+	defaultCAFilename          = "btcd.cert"
+	defaultConfigFilename      = "btcwallet.conf"
+	defaultLogLevel            = "info"
+	defaultLogDirname          = "logs"
+	defaultLogFilename         = "btcwallet.log"
+	defaultRPCMaxClients       = 10
+	defaultRPCMaxWebsockets    = 25
+	defaultGapLimit            = 250
+	defaultBackupInterval      = 24 * time.Hour
+	defaultBackupKeep          = 7
+	defaultRebroadcastInterval = 30 * time.Minute
+	defaultChainStallTimeout   = time.Hour
+	defaultZMQPubRawBlock      = "tcp://localhost:28332"
+	defaultZMQPubRawTx         = "tcp://localhost:28333"
 )
 
 var (
@@ -60,7 +68,28 @@ type config struct {
 	Profile       string                  `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 
 	// Wallet options
-	WalletPass string `long:"walletpass" default-mask:"-" description:"The public wallet password -- Only required if the wallet was created with one"`
+	WalletPass          string        `long:"walletpass" default-mask:"-" description:"The public wallet password -- Only required if the wallet was created with one"`
+	CoinSelection       string        `long:"coinselection" description:"Coin selection strategy for transactions created over RPC {largest, bnb, knapsack, oldest, all}"`
+	BatchInterval       time.Duration `long:"batchinterval" description:"Interval at which queued payments are batched into transactions and sent -- 0 disables periodic sending.  Valid time units are {s, m, h}"`
+	RebroadcastInterval time.Duration `long:"rebroadcastinterval" description:"Interval at which unconfirmed wallet transactions are resent to the network -- 0 disables rebroadcasting.  Valid time units are {s, m, h}"`
+	GapLimit            uint32        `long:"gaplimit" description:"Number of unused addresses past the last used address of each branch searched for transactions during wallet recovery -- 0 disables recovery"`
+	DBBackend           string        `long:"dbbackend" description:"Database backend of the wallet {bdb, sqlite, postgres, etcd}"`
+	PostgresDSN         string        `long:"pgdsn" description:"Connection string of the PostgreSQL database of the postgres backend"`
+	EtcdEndpoints       string        `long:"etcdendpoints" description:"Comma separated endpoints of the etcd cluster of the etcd backend"`
+	EtcdUser            string        `long:"etcduser" description:"Username for etcd authentication"`
+	EtcdPass            string        `long:"etcdpass" default-mask:"-" description:"Password for etcd authentication"`
+	EtcdPrefix          string        `long:"etcdprefix" description:"Key prefix of the wallet database in etcd (default: btcwallet/<network>/)"`
+	EncryptDB           bool          `long:"encryptdb" description:"Encrypt the entire database of a wallet created with --create, including its transaction history and addresses, with the public wallet password"`
+	CompactDB           bool          `long:"compactdb" description:"Compact the database of the bdb backend on startup, returning the space of deleted data to the file system"`
+
+	// Backup options
+	BackupDir      string        `long:"backupdir" description:"Directory to which backups of the wallet database are written while the wallet is running -- Backups are disabled if unset"`
+	BackupInterval time.Duration `long:"backupinterval" description:"Interval at which the wallet database is backed up.  Valid time units are {s, m, h}"`
+	BackupKeep     int           `long:"backupkeep" description:"Number of most recent backups kept in the backup directory -- 0 keeps all backups"`
+	BackupPass     string        `long:"backuppass" default-mask:"-" description:"Passphrase encrypting backups of the wallet database -- Backups are not encrypted if unset"`
+
+	// coinSelectionStrategy is the parsed CoinSelection option.
+	coinSelectionStrategy wallet.CoinSelectionStrategy
 
 	// RPC client options
 	RPCConnect       string                  `short:"c" long:"rpcconnect" description:"Hostname/IP and port of btcd RPC server to connect to (default localhost:8334, testnet: localhost:18334, simnet: localhost:18556)"`
@@ -71,6 +100,20 @@ type config struct {
 	Proxy            string                  `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser        string                  `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass        string                  `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
+	TorIsolation     bool                    `long:"torisolation" description:"Enable Tor stream isolation by randomizing proxy credentials for each connection"`
+
+	// Chain server health and failover options
+	BackupRPCConnect  []string      `long:"backuprpcconnect" description:"Hostname/IP and port of a backup chain server to switch to when the current one is unreachable or stalls, tried in the order given after rpcconnect and authenticated like it (may be used multiple times)"`
+	ChainStallTimeout time.Duration `long:"chainstalltimeout" description:"Consider the chain server stalled, and switch to a backup chain server if any, when its best block has not changed for this long (0 to disable)"`
+
+	// bitcoind client options
+	UseBitcoind    bool          `long:"bitcoind" description:"Synchronize with a bitcoind node using its RPC server at rpcconnect and its ZMQ notifications rather than btcd"`
+	ZMQPubRawBlock string        `long:"zmqpubrawblock" description:"Address of the bitcoind ZMQ rawblock notifications"`
+	ZMQPubRawTx    string        `long:"zmqpubrawtx" description:"Address of the bitcoind ZMQ rawtx notifications"`
+	BitcoindPoll   time.Duration `long:"bitcoindpollinterval" description:"Poll the best block and mempool of bitcoind over RPC at this interval rather than using ZMQ notifications (0 to use ZMQ)"`
+
+	// Transaction publishing options
+	TestMempoolAccept bool `long:"testmempoolaccept" description:"Test transactions against the bitcoind mempool policy with testmempoolaccept before publishing them"`
 
 	// SPV client options
 	UseSPV       bool          `long:"usespv" description:"Enables the experimental use of SPV rather than RPC for chain synchronization"`
@@ -80,6 +123,12 @@ type config struct {
 	BanDuration  time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
 
+	// SPV client tuning options
+	FilterFetchWorkers   int           `long:"filterfetchworkers" description:"Number of compact filters requested in parallel when scanning blocks for addresses"`
+	FilterFetchBatchSize int           `long:"filterfetchbatchsize" description:"Number of compact filters fetched at once before they are matched when scanning blocks for addresses"`
+	PeerQueryTimeout     time.Duration `long:"peerquerytimeout" description:"How long to wait for a peer to answer a query before trying the next peer.  Valid time units are {s, m, h}"`
+	PeerQueryRetries     int           `long:"peerqueryretries" description:"Number of times a query is retried with each peer before trying the next peer"`
+
 	// RPC server options
 	//
 	// The legacy server is still enabled by default (and eventually will be
@@ -97,12 +146,26 @@ type config struct {
 	LegacyRPCMaxWebsockets int64                   `long:"rpcmaxwebsockets" description:"Max number of legacy RPC websocket connections"`
 	Username               string                  `short:"u" long:"username" description:"Username for legacy RPC and btcd authentication (if btcdusername is unset)"`
 	Password               string                  `short:"P" long:"password" default-mask:"-" description:"Password for legacy RPC and btcd authentication (if btcdpassword is unset)"`
+	LegacyRPCUsers         []string                `long:"rpcauth" default-mask:"-" description:"Additional username:password credential accepted by the legacy RPC server (may be used multiple times)"`
+	LegacyRPCPolicy        []string                `long:"rpcpolicy" description:"Disable or rate limit a legacy RPC method for a username, or * for every user, as username:method:disable or username:method:calls/interval, such as *:listtransactions:10/1m (may be used multiple times)"`
 
 	// EXPERIMENTAL RPC server options
 	//
 	// These options will change (and require changes to config files, etc.)
 	// when the new gRPC server is enabled.
-	ExperimentalRPCListeners []string `long:"experimentalrpclisten" description:"Listen for RPC connections on this interface/port"`
+	ExperimentalRPCListeners   []string `long:"experimentalrpclisten" description:"Listen for RPC connections on this interface/port"`
+	ExperimentalRPCSigner      bool     `long:"experimentalrpcsigner" description:"Serve the SignerService, which signs for remote watching-only wallets with the keys of the unlocked wallet"`
+	ExperimentalRESTListeners  []string `long:"experimentalrestlisten" description:"Serve a REST gateway to the experimental RPC server on this interface/port"`
+	NoExperimentalRPCMacaroons bool     `long:"noexperimentalrpcmacaroons" description:"Serve experimental RPC clients without authenticating them with the admin, readonly and invoice macaroons written to the network data directory"`
+	ExperimentalRPCPolicy      []string `long:"experimentalrpcpolicy" description:"Disable or rate limit an experimental RPC method for clients with macaroons minted with a permission {full, invoice, readonly}, or * for every client, as permission:method:disable or permission:method:calls/interval (may be used multiple times)"`
+
+	// Remote signer options
+	RemoteSigner         string `long:"remotesigner" description:"Hostname/IP and port of the RPC server of a wallet signing transactions for this wallet with its SignerService"`
+	RemoteSignerCert     string `long:"remotesignercert" description:"File containing the certificate of the remote signer's RPC server"`
+	RemoteSignerMacaroon string `long:"remotesignermacaroon" description:"File containing the macaroon with full permission authenticating to the remote signer's RPC server"`
+
+	// Metrics server options
+	MetricsListeners []string `long:"metricslisten" description:"Serve Prometheus metrics at /metrics over HTTP on this interface/port (default port: 8338, testnet: 18338, simnet: 18559)"`
 
 	// Deprecated options
 	DataDir *cfgutil.ExplicitString `short:"b" long:"datadir" default-mask:"-" description:"DEPRECATED -- use appdata instead"`
@@ -192,30 +255,46 @@ func supportedSubsystems() []string {
 
 // parseAndSetDebugLevels attempts to parse the specified debug level and set
 // the levels accordingly.  An appropriate error is returned if anything is
-// invalid.
+// invalid, in which case no levels are changed.
 func parseAndSetDebugLevels(debugLevel string) error {
+	levels, err := parseDebugLevels(debugLevel)
+	if err != nil {
+		return err
+	}
+	for subsysID, logLevel := range levels {
+		setLogLevel(subsysID, logLevel)
+	}
+	return nil
+}
+
+// parseDebugLevels parses the specified debug level into the log levels of
+// the subsystems it changes, keyed by subsystem.  An appropriate error is
+// returned if anything is invalid.
+func parseDebugLevels(debugLevel string) (map[string]string, error) {
 	// When the specified string doesn't have any delimters, treat it as
 	// the log level for all subsystems.
 	if !strings.Contains(debugLevel, ",") && !strings.Contains(debugLevel, "=") {
 		// Validate debug log level.
 		if !validLogLevel(debugLevel) {
 			str := "The specified debug level [%v] is invalid"
-			return fmt.Errorf(str, debugLevel)
+			return nil, fmt.Errorf(str, debugLevel)
 		}
 
-		// Change the logging level for all subsystems.
-		setLogLevels(debugLevel)
-
-		return nil
+		levels := make(map[string]string, len(subsystemLoggers))
+		for subsysID := range subsystemLoggers {
+			levels[subsysID] = debugLevel
+		}
+		return levels, nil
 	}
 
 	// Split the specified string into subsystem/level pairs while detecting
-	// issues and update the log levels accordingly.
+	// issues.
+	levels := make(map[string]string)
 	for _, logLevelPair := range strings.Split(debugLevel, ",") {
 		if !strings.Contains(logLevelPair, "=") {
 			str := "The specified debug level contains an invalid " +
 				"subsystem/level pair [%v]"
-			return fmt.Errorf(str, logLevelPair)
+			return nil, fmt.Errorf(str, logLevelPair)
 		}
 
 		// Extract the specified subsystem and log level.
@@ -226,29 +305,106 @@ func parseAndSetDebugLevels(debugLevel string) error {
 		if _, exists := subsystemLoggers[subsysID]; !exists {
 			str := "The specified subsystem [%v] is invalid -- " +
 				"supported subsytems %v"
-			return fmt.Errorf(str, subsysID, supportedSubsystems())
+			return nil, fmt.Errorf(str, subsysID, supportedSubsystems())
 		}
 
 		// Validate log level.
 		if !validLogLevel(logLevel) {
 			str := "The specified debug level [%v] is invalid"
-			return fmt.Errorf(str, logLevel)
+			return nil, fmt.Errorf(str, logLevel)
 		}
 
-		setLogLevel(subsysID, logLevel)
+		levels[subsysID] = logLevel
 	}
 
+	return levels, nil
+}
+
+// parseLegacyRPCUsers returns the passwords of the additional legacy RPC
+// users keyed by username, parsed from username:password credentials.
+func parseLegacyRPCUsers(auths []string) (map[string]string, error) {
+	users := make(map[string]string, len(auths))
+	for _, auth := range auths {
+		i := strings.IndexByte(auth, ':')
+		if i <= 0 || i == len(auth)-1 {
+			return nil, fmt.Errorf("legacy RPC credential of user %q is "+
+				"not in the form username:password",
+				strings.SplitN(auth, ":", 2)[0])
+		}
+		username := auth[:i]
+		if _, ok := users[username]; ok {
+			return nil, fmt.Errorf("duplicate legacy RPC user %q",
+				username)
+		}
+		users[username] = auth[i+1:]
+	}
+	return users, nil
+}
+
+// checkRPCPolicy returns an error unless the RPC policy rules are valid and
+// apply to one of the credentials of the server enforcing them.
+func checkRPCPolicy(rules []string, credentials []string) error {
+	for _, s := range rules {
+		rule, err := rpcpolicy.ParseRule(s)
+		if err != nil {
+			return err
+		}
+		known := rule.Credential == rpcpolicy.AnyCredential
+		for _, credential := range credentials {
+			known = known || rule.Credential == credential
+		}
+		if !known {
+			return fmt.Errorf("RPC policy rule %q names unknown "+
+				"credential %q", s, rule.Credential)
+		}
+	}
 	return nil
 }
 
+// checkRPCPolicies returns an error unless the additional legacy RPC users
+// and the policies of both RPC servers are valid.
+func checkRPCPolicies(cfg *config) error {
+	legacyUsers, err := parseLegacyRPCUsers(cfg.LegacyRPCUsers)
+	if err != nil {
+		return err
+	}
+	if _, ok := legacyUsers[cfg.Username]; ok {
+		return fmt.Errorf("duplicate legacy RPC user %q", cfg.Username)
+	}
+	credentials := []string{cfg.Username}
+	for username := range legacyUsers {
+		credentials = append(credentials, username)
+	}
+	err = checkRPCPolicy(cfg.LegacyRPCPolicy, credentials)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.ExperimentalRPCPolicy) != 0 &&
+		len(cfg.ExperimentalRPCListeners) == 0 {
+
+		return fmt.Errorf("the --experimentalrpcpolicy option requires " +
+			"--experimentalrpclisten")
+	}
+	// Without macaroons, clients are not distinguished and only the rules
+	// of every credential apply.
+	var permissions []string
+	if !cfg.NoExperimentalRPCMacaroons {
+		for _, perm := range macaroons.Permissions {
+			permissions = append(permissions, string(perm))
+		}
+	}
+	return checkRPCPolicy(cfg.ExperimentalRPCPolicy, permissions)
+}
+
 // loadConfig initializes and parses the config using a config file and command
 // line options.
 //
 // The configuration proceeds as follows:
-//      1) Start with a default config with sane settings
-//      2) Pre-parse the command line to check for an alternative config file
-//      3) Load configuration file overwriting defaults with any specified options
-//      4) Parse CLI options and overwrite/add any specified options
+//  1. Start with a default config with sane settings
+//  2. Pre-parse the command line to check for an alternative config file
+//  3. Load configuration file overwriting defaults with any specified options
+//  4. Parse CLI options and overwrite/add any specified options
 //
 // The above results in btcwallet functioning properly without any config
 // settings while still allowing the user to override settings with config files
@@ -261,18 +417,31 @@ func loadConfig() (*config, []string, error) {
 		AppDataDir:             cfgutil.NewExplicitString(defaultAppDataDir),
 		LogDir:                 defaultLogDir,
 		WalletPass:             wallet.InsecurePubPassphrase,
+		CoinSelection:          "largest",
+		GapLimit:               defaultGapLimit,
+		DBBackend:              wallet.DefaultDatabaseDriver,
+		BackupInterval:         defaultBackupInterval,
+		BackupKeep:             defaultBackupKeep,
+		RebroadcastInterval:    defaultRebroadcastInterval,
 		CAFile:                 cfgutil.NewExplicitString(""),
 		RPCKey:                 cfgutil.NewExplicitString(defaultRPCKeyFile),
 		RPCCert:                cfgutil.NewExplicitString(defaultRPCCertFile),
 		LegacyRPCMaxClients:    defaultRPCMaxClients,
 		LegacyRPCMaxWebsockets: defaultRPCMaxWebsockets,
 		DataDir:                cfgutil.NewExplicitString(defaultAppDataDir),
+		ChainStallTimeout:      defaultChainStallTimeout,
+		ZMQPubRawBlock:         defaultZMQPubRawBlock,
+		ZMQPubRawTx:            defaultZMQPubRawTx,
 		UseSPV:                 false,
 		AddPeers:               []string{},
 		ConnectPeers:           []string{},
 		MaxPeers:               neutrino.MaxPeers,
 		BanDuration:            neutrino.BanDuration,
 		BanThreshold:           neutrino.BanThreshold,
+		FilterFetchWorkers:     1,
+		FilterFetchBatchSize:   1,
+		PeerQueryTimeout:       neutrino.QueryTimeout,
+		PeerQueryRetries:       neutrino.QueryNumRetries,
 	}
 
 	// Pre-parse the command line options to see if an alternative config
@@ -397,6 +566,69 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the database backend.
+	validBackend := false
+	for _, dbType := range walletdb.SupportedDrivers() {
+		if cfg.DBBackend == dbType {
+			validBackend = true
+		}
+	}
+	if !validBackend {
+		err := fmt.Errorf("%s: unsupported database backend %q",
+			"loadConfig", cfg.DBBackend)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+	if cfg.DBBackend == "postgres" && cfg.PostgresDSN == "" {
+		err := fmt.Errorf("%s: the postgres database backend requires "+
+			"--pgdsn", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.CompactDB && cfg.DBBackend != "bdb" {
+		err := fmt.Errorf("%s: --compactdb is only supported by the bdb "+
+			"database backend", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.BackupDir != "" {
+		var err error
+		switch {
+		case cfg.DBBackend == "postgres" || cfg.DBBackend == "etcd":
+			err = fmt.Errorf("%s: --backupdir is not supported by the "+
+				"%s database backend", "loadConfig", cfg.DBBackend)
+		case cfg.BackupInterval <= 0:
+			err = fmt.Errorf("%s: --backupinterval must be positive",
+				"loadConfig")
+		case cfg.BackupKeep < 0:
+			err = fmt.Errorf("%s: --backupkeep may not be negative",
+				"loadConfig")
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+		cfg.BackupDir = cleanAndExpandPath(cfg.BackupDir)
+	}
+	if cfg.DBBackend == "etcd" && cfg.EtcdEndpoints == "" {
+		err := fmt.Errorf("%s: the etcd database backend requires "+
+			"--etcdendpoints", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Parse and validate the coin selection strategy.
+	cfg.coinSelectionStrategy, err = wallet.ParseCoinSelectionStrategy(
+		cfg.CoinSelection,
+	)
+	if err != nil {
+		err := fmt.Errorf("%s: %v", "loadConfig", err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
 	// Exit if you try to use a simulation wallet with a standard
 	// data directory.
 	if !(cfg.AppDataDir.ExplicitlySet() || cfg.DataDir.ExplicitlySet()) && cfg.CreateTemp {
@@ -415,7 +647,11 @@ func loadConfig() (*config, []string, error) {
 
 	// Ensure the wallet exists or create it when the create flag is set.
 	netDir := networkDir(cfg.AppDataDir.Value, activeNet.Params)
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbName := fmt.Sprintf("file `%v`", filepath.Join(netDir,
+		wallet.DatabaseFileName(cfg.DBBackend)))
+	if databaseArgs(&cfg) != nil {
+		dbName = "of the " + cfg.DBBackend + " backend"
+	}
 
 	if cfg.CreateTemp && cfg.Create {
 		err := fmt.Errorf("The flags --create and --createtemp can not " +
@@ -424,7 +660,7 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	dbFileExists, err := cfgutil.FileExists(dbPath)
+	dbFileExists, err := newLoader(&cfg, netDir).WalletExists()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return nil, nil, err
@@ -457,8 +693,8 @@ func loadConfig() (*config, []string, error) {
 		// Error if the create flag is set and the wallet already
 		// exists.
 		if dbFileExists {
-			err := fmt.Errorf("The wallet database file `%v` "+
-				"already exists.", dbPath)
+			err := fmt.Errorf("The wallet database %v already "+
+				"exists.", dbName)
 			fmt.Fprintln(os.Stderr, err)
 			return nil, nil, err
 		}
@@ -501,11 +737,97 @@ func loadConfig() (*config, []string, error) {
 		"::1":       {},
 	}
 
-	if cfg.UseSPV {
+	// Connections through a proxy are made for every backend, but the
+	// bitcoind ZMQ notifications can only be received directly.
+	if cfg.Proxy != "" {
+		if _, _, err := net.SplitHostPort(cfg.Proxy); err != nil {
+			str := "%s: proxy address '%s' is invalid: %v"
+			err := fmt.Errorf(str, funcName, cfg.Proxy, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.UseBitcoind && cfg.BitcoindPoll == 0 {
+			str := "%s: bitcoind ZMQ notifications can not be " +
+				"received through a proxy -- use the " +
+				"--bitcoindpollinterval option"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	if cfg.TorIsolation {
+		if cfg.Proxy == "" {
+			str := "%s: Tor stream isolation requires the --proxy " +
+				"option to be set"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.ProxyUser != "" || cfg.ProxyPass != "" {
+			str := "%s: the --torisolation option may not be used " +
+				"with the --proxyuser and --proxypass options"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	if cfg.UseSPV && cfg.UseBitcoind {
+		err := fmt.Errorf("%s: the --usespv and --bitcoind options "+
+			"may not be used together", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	switch {
+	case cfg.UseSPV:
 		neutrino.MaxPeers = cfg.MaxPeers
 		neutrino.BanDuration = cfg.BanDuration
 		neutrino.BanThreshold = cfg.BanThreshold
-	} else {
+		neutrino.QueryTimeout = cfg.PeerQueryTimeout
+		neutrino.QueryNumRetries = cfg.PeerQueryRetries
+
+		if cfg.FilterFetchWorkers < 1 || cfg.FilterFetchBatchSize < 1 {
+			err := fmt.Errorf("%s: the filterfetchworkers and "+
+				"filterfetchbatchsize options must be positive",
+				funcName)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+
+	case cfg.UseBitcoind:
+		// bitcoind serves RPC without TLS, so none of the btcd
+		// certificate options apply.
+		if cfg.BitcoindPoll < 0 {
+			err := fmt.Errorf("%s: the bitcoindpollinterval option "+
+				"may not be negative", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+		rpcPort := activeNet.BitcoindRPCPort
+		if rpcPort == "" {
+			err := fmt.Errorf("%s: the --bitcoind option may not be "+
+				"used on %s", funcName, activeNet.Params.Name)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+		if cfg.RPCConnect == "" {
+			cfg.RPCConnect = net.JoinHostPort("localhost", rpcPort)
+		}
+		cfg.RPCConnect, err = cfgutil.NormalizeAddress(cfg.RPCConnect,
+			rpcPort)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"Invalid rpcconnect network address: %v\n", err)
+			return nil, nil, err
+		}
+
+	default:
 		if cfg.RPCConnect == "" {
 			cfg.RPCConnect = net.JoinHostPort("localhost", activeNet.RPCClientPort)
 		}
@@ -562,6 +884,55 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	if cfg.ChainStallTimeout < 0 {
+		err := fmt.Errorf("%s: the chainstalltimeout option may not be "+
+			"negative", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Backup chain servers use the same backend and default port as
+	// rpcconnect.
+	if len(cfg.BackupRPCConnect) != 0 {
+		if cfg.UseSPV {
+			err := fmt.Errorf("%s: the --backuprpcconnect option may "+
+				"not be used with --usespv", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		rpcPort := activeNet.RPCClientPort
+		if cfg.UseBitcoind {
+			rpcPort = activeNet.BitcoindRPCPort
+		}
+		for i, addr := range cfg.BackupRPCConnect {
+			addr, err := cfgutil.NormalizeAddress(addr, rpcPort)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid backuprpcconnect "+
+					"network address: %v\n", err)
+				return nil, nil, err
+			}
+			cfg.BackupRPCConnect[i] = addr
+
+			if cfg.UseBitcoind || !cfg.DisableClientTLS {
+				continue
+			}
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := localhostListeners[host]; !ok {
+				str := "%s: the --noclienttls option may not be used " +
+					"when connecting RPC to non localhost " +
+					"addresses: %s"
+				err := fmt.Errorf(str, funcName, addr)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
+		}
+	}
+
 	// Only set default RPC listeners when there are no listeners set for
 	// the experimental RPC server.  This is required to prevent the old RPC
 	// server from sharing listen addresses, since it is impossible to
@@ -595,6 +966,20 @@ func loadConfig() (*config, []string, error) {
 			"Invalid network address in RPC listeners: %v\n", err)
 		return nil, nil, err
 	}
+	cfg.ExperimentalRESTListeners, err = cfgutil.NormalizeAddresses(
+		cfg.ExperimentalRESTListeners, activeNet.RESTServerPort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Invalid network address in REST listeners: %v\n", err)
+		return nil, nil, err
+	}
+	cfg.MetricsListeners, err = cfgutil.NormalizeAddresses(
+		cfg.MetricsListeners, activeNet.MetricsServerPort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Invalid network address in metrics listeners: %v\n", err)
+		return nil, nil, err
+	}
 
 	// Both RPC servers may not listen on the same interface/port.
 	if len(cfg.LegacyRPCListeners) > 0 && len(cfg.ExperimentalRPCListeners) > 0 {
@@ -641,10 +1026,79 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// The signer service is only served by the experimental RPC server.
+	// It signs whatever its clients request, so it is only served to
+	// clients authenticated with a macaroon of full permission.
+	if cfg.ExperimentalRPCSigner && (len(cfg.ExperimentalRPCListeners) == 0 ||
+		cfg.DisableServerTLS || cfg.NoExperimentalRPCMacaroons) {
+
+		str := "%s: the --experimentalrpcsigner option requires " +
+			"--experimentalrpclisten, macaroons and server TLS"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The REST gateway proxies requests to the experimental RPC server.
+	if len(cfg.ExperimentalRESTListeners) != 0 &&
+		(len(cfg.ExperimentalRPCListeners) == 0 || cfg.DisableServerTLS) {
+
+		str := "%s: the --experimentalrestlisten option requires " +
+			"--experimentalrpclisten and server TLS"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Macaroons are required by the experimental RPC server unless
+	// disabled, and are bearer credentials which must be sent over TLS.
+	// Serving clients without TLS therefore requires explicitly serving
+	// them unauthenticated.
+	if len(cfg.ExperimentalRPCListeners) != 0 && cfg.DisableServerTLS &&
+		!cfg.NoExperimentalRPCMacaroons {
+
+		str := "%s: macaroons of the experimental RPC server require " +
+			"server TLS; serving clients without authentication " +
+			"requires --noexperimentalrpcmacaroons"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// RPC policies restrict the users of the legacy RPC server and the
+	// macaroon permissions of the experimental RPC server.
+	if err := checkRPCPolicies(&cfg); err != nil {
+		err := fmt.Errorf("%s: %v", funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The certificate of a remote signer must be provided to authenticate
+	// its server, and a macaroon to authenticate to it, since signers only
+	// serve clients with macaroons.
+	if cfg.RemoteSigner != "" && (cfg.RemoteSignerCert == "" ||
+		cfg.RemoteSignerMacaroon == "") {
+
+		str := "%s: the --remotesigner option requires " +
+			"--remotesignercert and --remotesignermacaroon"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Expand environment variable and leading ~ for filepaths.
 	cfg.CAFile.Value = cleanAndExpandPath(cfg.CAFile.Value)
 	cfg.RPCCert.Value = cleanAndExpandPath(cfg.RPCCert.Value)
 	cfg.RPCKey.Value = cleanAndExpandPath(cfg.RPCKey.Value)
+	cfg.RemoteSignerCert = cleanAndExpandPath(cfg.RemoteSignerCert)
+	if cfg.RemoteSignerMacaroon != "" {
+		cfg.RemoteSignerMacaroon = cleanAndExpandPath(cfg.RemoteSignerMacaroon)
+	}
 
 	// If the btcd username or password are unset, use the same auth as for
 	// the client.  The two settings were previously shared for btcd and
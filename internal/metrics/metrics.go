@@ -0,0 +1,325 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package metrics collects operational metrics of the wallet process, and
+// serves them in the Prometheus exposition format.
+//
+// Wallets added with AddWallet report their sync state, rescan progress,
+// transaction broadcasts and unspent outputs, labeled with the wallet name,
+// which is empty for the default wallet.  Database transactions and RPC calls
+// are reported by installing the observers and interceptors of this package.
+package metrics
+
+import (
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// namespace prefixes the name of every metric.
+const namespace = "btcwallet"
+
+var (
+	dbTxDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transaction_duration_seconds",
+		Help:      "Duration of wallet database transactions.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 8),
+	}, []string{"type"})
+
+	rpcCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of RPC calls handled by the RPC servers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"server", "method"})
+
+	rpcCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "call_errors_total",
+		Help:      "RPC calls handled by the RPC servers which failed.",
+	}, []string{"server", "method"})
+
+	wallets = &walletCollector{wallets: make(map[string]*walletMetrics)}
+
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(prometheus.NewGoCollector(), dbTxDuration,
+		rpcCallDuration, rpcCallErrors, wallets)
+}
+
+// Handler returns an HTTP handler serving the metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveDBTx records the duration of a wallet database transaction.  It is
+// a walletdb.TxObserver.
+func ObserveDBTx(write bool, d time.Duration) {
+	txType := "read"
+	if write {
+		txType = "write"
+	}
+	dbTxDuration.WithLabelValues(txType).Observe(d.Seconds())
+}
+
+// observeRPCCall records the duration of an RPC call, and whether it failed.
+func observeRPCCall(server, method string, d time.Duration, failed bool) {
+	rpcCallDuration.WithLabelValues(server, method).Observe(d.Seconds())
+	if failed {
+		rpcCallErrors.WithLabelValues(server, method).Inc()
+	}
+}
+
+// ObserveLegacyRPCCall records a request handled by the legacy RPC server.  It
+// is the call observer of the server options.
+func ObserveLegacyRPCCall(method string, d time.Duration, failed bool) {
+	observeRPCCall("legacy", method, d, failed)
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor recording the duration of
+// unary calls of the gRPC server.  Streaming calls are not recorded, as they
+// last until the client stops listening for notifications.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPCCall("grpc", path.Base(info.FullMethod),
+			time.Since(start), err != nil)
+		return resp, err
+	}
+}
+
+// utxoCountInterval is the minimum duration between two counts of the
+// unspent outputs of a wallet, which scan every unspent output.
+var utxoCountInterval = time.Minute
+
+// walletMetrics holds the wallet of metrics and the values last notified by
+// the wallet, so that serving the metrics neither queries the chain server nor
+// scans the database.
+type walletMetrics struct {
+	w      *wallet.Wallet
+	state  wallet.SyncStateNotification
+	rescan float64 // from 0 to 1, or 1 when no rescan was started
+	utxos  int     // -1 until first counted
+
+	// utxosChanged is set when transactions were notified since the
+	// unspent outputs were last counted.
+	utxosChanged bool
+}
+
+// AddWallet reports the metrics of the loaded wallet with the name, which is
+// empty for the default wallet, until the wallet shuts down.
+func AddWallet(name string, w *wallet.Wallet) {
+	m := &walletMetrics{
+		w:            w,
+		state:        *w.SyncState(),
+		rescan:       1,
+		utxos:        -1,
+		utxosChanged: true,
+	}
+	wallets.mu.Lock()
+	wallets.wallets[name] = m
+	wallets.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.WaitForShutdown()
+		close(done)
+		wallets.mu.Lock()
+		if wallets.wallets[name] == m {
+			delete(wallets.wallets, name)
+		}
+		wallets.mu.Unlock()
+	}()
+
+	go m.notificationHandler(done)
+	go m.utxoCounter(name, done)
+}
+
+// notificationHandler updates the metrics of the wallet as notifications are
+// received, until the done channel is closed.  As the notification channels
+// are unbuffered, the handler must not perform any work which blocks the
+// wallet.
+func (m *walletMetrics) notificationHandler(done <-chan struct{}) {
+	syncState := m.w.NtfnServer.SyncStateNotifications()
+	defer syncState.Done()
+	blocks := m.w.NtfnServer.BlockEpochNotifications()
+	defer blocks.Done()
+	rescans := m.w.NtfnServer.RescanProgressNotifications()
+	defer rescans.Done()
+	txs := m.w.NtfnServer.TransactionNotifications()
+	defer txs.Done()
+
+	for {
+		select {
+		case n := <-syncState.C:
+			wallets.mu.Lock()
+			m.state = *n
+			wallets.mu.Unlock()
+
+		case n := <-blocks.C:
+			height := n.Height
+			if !n.Connected {
+				height--
+			}
+			wallets.mu.Lock()
+			m.state.Height = height
+			if m.state.Synced && m.state.BackendHeight < height {
+				m.state.BackendHeight = height
+			}
+			wallets.mu.Unlock()
+
+		case p := <-rescans.C:
+			wallets.mu.Lock()
+			m.rescan = p.Percent / 100
+			wallets.mu.Unlock()
+
+		case <-txs.C:
+			wallets.mu.Lock()
+			m.utxosChanged = true
+			wallets.mu.Unlock()
+
+		case <-done:
+			return
+		}
+	}
+}
+
+// utxoCounter counts the unspent outputs of the wallet once added, and again
+// at most once per utxoCountInterval after transactions were notified, until
+// the done channel is closed.
+func (m *walletMetrics) utxoCounter(name string, done <-chan struct{}) {
+	ticker := time.NewTicker(utxoCountInterval)
+	defer ticker.Stop()
+
+	for {
+		wallets.mu.Lock()
+		changed := m.utxosChanged
+		m.utxosChanged = false
+		wallets.mu.Unlock()
+
+		if changed {
+			utxos, err := m.w.UnspentOutputCount()
+			if err != nil {
+				log.Errorf("Cannot count unspent outputs of wallet "+
+					"%q: %v", name, err)
+			} else {
+				wallets.mu.Lock()
+				m.utxos = utxos
+				wallets.mu.Unlock()
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+var (
+	syncHeightDesc = prometheus.NewDesc(namespace+"_sync_height",
+		"Height of the block the wallet is synced to.",
+		[]string{"wallet"}, nil)
+
+	backendHeightDesc = prometheus.NewDesc(namespace+"_backend_height",
+		"Best block height of the chain backend last known to the "+
+			"wallet, or zero when unknown.",
+		[]string{"wallet"}, nil)
+
+	syncedDesc = prometheus.NewDesc(namespace+"_synced",
+		"Whether the wallet is synced to the chain backend.",
+		[]string{"wallet"}, nil)
+
+	rescanProgressDesc = prometheus.NewDesc(namespace+"_rescan_progress_ratio",
+		"Progress of the last rescan of the wallet from 0 to 1.",
+		[]string{"wallet"}, nil)
+
+	txBroadcastsDesc = prometheus.NewDesc(namespace+"_tx_broadcasts_total",
+		"Transactions accepted by the chain backend when published.",
+		[]string{"wallet"}, nil)
+
+	txBroadcastFailuresDesc = prometheus.NewDesc(
+		namespace+"_tx_broadcast_failures_total",
+		"Transactions rejected by the chain backend or which failed to "+
+			"be sent.",
+		[]string{"wallet"}, nil)
+
+	utxosDesc = prometheus.NewDesc(namespace+"_utxos",
+		"Unspent outputs of the wallet, including unconfirmed outputs.",
+		[]string{"wallet"}, nil)
+)
+
+// walletCollector collects the metrics of the added wallets when the metrics
+// are served, from the values last notified by each wallet.
+type walletCollector struct {
+	wallets map[string]*walletMetrics
+	mu      sync.Mutex
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *walletCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- syncHeightDesc
+	ch <- backendHeightDesc
+	ch <- syncedDesc
+	ch <- rescanProgressDesc
+	ch <- txBroadcastsDesc
+	ch <- txBroadcastFailuresDesc
+	ch <- utxosDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *walletCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	snapshot := make(map[string]walletMetrics, len(c.wallets))
+	for name, m := range c.wallets {
+		snapshot[name] = *m
+	}
+	c.mu.Unlock()
+
+	for name, m := range snapshot {
+		metric := func(desc *prometheus.Desc, valueType prometheus.ValueType,
+			value float64) {
+
+			ch <- prometheus.MustNewConstMetric(desc, valueType, value,
+				name)
+		}
+
+		synced := 0.0
+		if m.state.Synced {
+			synced = 1
+		}
+		metric(syncHeightDesc, prometheus.GaugeValue,
+			float64(m.state.Height))
+		metric(backendHeightDesc, prometheus.GaugeValue,
+			float64(m.state.BackendHeight))
+		metric(syncedDesc, prometheus.GaugeValue, synced)
+		metric(rescanProgressDesc, prometheus.GaugeValue, m.rescan)
+
+		published, rejected := m.w.PublishStats()
+		metric(txBroadcastsDesc, prometheus.CounterValue, float64(published))
+		metric(txBroadcastFailuresDesc, prometheus.CounterValue,
+			float64(rejected))
+
+		if m.utxos >= 0 {
+			metric(utxosDesc, prometheus.GaugeValue, float64(m.utxos))
+		}
+	}
+}
@@ -14,34 +14,14 @@ import (
 
 	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/btcsuite/btcwallet/internal/legacy/keystore"
+	"github.com/btcsuite/btcwallet/wallet/bip39"
 	"github.com/btcsuite/golangcrypto/ssh/terminal"
 )
 
 // ProvideSeed is used to prompt for the wallet seed which maybe required during
 // upgrades.
 func ProvideSeed() ([]byte, error) {
-	reader := bufio.NewReader(os.Stdin)
-	for {
-		fmt.Print("Enter existing wallet seed: ")
-		seedStr, err := reader.ReadString('\n')
-		if err != nil {
-			return nil, err
-		}
-		seedStr = strings.TrimSpace(strings.ToLower(seedStr))
-
-		seed, err := hex.DecodeString(seedStr)
-		if err != nil || len(seed) < hdkeychain.MinSeedBytes ||
-			len(seed) > hdkeychain.MaxSeedBytes {
-
-			fmt.Printf("Invalid seed specified.  Must be a "+
-				"hexadecimal value that is at least %d bits and "+
-				"at most %d bits\n", hdkeychain.MinSeedBytes*8,
-				hdkeychain.MaxSeedBytes*8)
-			continue
-		}
-
-		return seed, nil
-	}
+	return existingSeed(bufio.NewReader(os.Stdin))
 }
 
 // ProvidePrivPassphrase is used to prompt for the private passphrase which
@@ -257,67 +237,143 @@ func PublicPass(reader *bufio.Reader, privPass []byte,
 
 // Seed prompts the user whether they want to use an existing wallet generation
 // seed.  When the user answers no, a seed will be generated and displayed to
-// the user along with prompting them for confirmation.  When the user answers
-// yes, a the user is prompted for it.  All prompts are repeated until the user
-// enters a valid response.
+// the user along with prompting them for confirmation.  The generated seed is
+// displayed as a BIP0039 mnemonic if the user chooses so.  When the user
+// answers yes, the user is prompted for it, either as a hexadecimal seed or
+// as a BIP0039 mnemonic and optional passphrase.  All prompts are repeated
+// until the user enters a valid response.
 func Seed(reader *bufio.Reader) ([]byte, error) {
 	// Ascertain the wallet generation seed.
 	useUserSeed, err := promptListBool(reader, "Do you have an "+
-		"existing wallet seed you want to use?", "no")
+		"existing wallet seed or BIP0039 mnemonic you want to use?",
+		"no")
 	if err != nil {
 		return nil, err
 	}
-	if !useUserSeed {
-		seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	if useUserSeed {
+		return existingSeed(reader)
+	}
+
+	useMnemonic, err := promptListBool(reader, "Do you want the seed "+
+		"to be a BIP0039 mnemonic?", "no")
+	if err != nil {
+		return nil, err
+	}
+
+	var seed []byte
+	if useMnemonic {
+		entropy, err := bip39.NewEntropy(bip39.RecommendedEntropyBits)
+		if err != nil {
+			return nil, err
+		}
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			return nil, err
+		}
+		passphrase, err := mnemonicPassphrase(reader, "Do you want to "+
+			"protect the mnemonic with a passphrase?", true)
+		if err != nil {
+			return nil, err
+		}
+		seed, err = bip39.NewSeed(mnemonic, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Println("Your wallet generation mnemonic is:")
+		fmt.Println(mnemonic)
+		if passphrase != "" {
+			fmt.Println("IMPORTANT: The mnemonic passphrase is also\n" +
+				"required to restore your wallet.")
+		}
+	} else {
+		seed, err = hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
 		if err != nil {
 			return nil, err
 		}
 
 		fmt.Println("Your wallet generation seed is:")
 		fmt.Printf("%x\n", seed)
-		fmt.Println("IMPORTANT: Keep the seed in a safe place as you\n" +
-			"will NOT be able to restore your wallet without it.")
-		fmt.Println("Please keep in mind that anyone who has access\n" +
-			"to the seed can also restore your wallet thereby\n" +
-			"giving them access to all your funds, so it is\n" +
-			"imperative that you keep it in a secure location.")
-
-		for {
-			fmt.Print(`Once you have stored the seed in a safe ` +
-				`and secure location, enter "OK" to continue: `)
-			confirmSeed, err := reader.ReadString('\n')
-			if err != nil {
-				return nil, err
-			}
-			confirmSeed = strings.TrimSpace(confirmSeed)
-			confirmSeed = strings.Trim(confirmSeed, `"`)
-			if confirmSeed == "OK" {
-				break
-			}
-		}
+	}
+	fmt.Println("IMPORTANT: Keep the seed in a safe place as you\n" +
+		"will NOT be able to restore your wallet without it.")
+	fmt.Println("Please keep in mind that anyone who has access\n" +
+		"to the seed can also restore your wallet thereby\n" +
+		"giving them access to all your funds, so it is\n" +
+		"imperative that you keep it in a secure location.")
 
-		return seed, nil
+	for {
+		fmt.Print(`Once you have stored the seed in a safe ` +
+			`and secure location, enter "OK" to continue: `)
+		confirmSeed, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		confirmSeed = strings.TrimSpace(confirmSeed)
+		confirmSeed = strings.Trim(confirmSeed, `"`)
+		if confirmSeed == "OK" {
+			break
+		}
 	}
 
+	return seed, nil
+}
+
+// existingSeed prompts the user for an existing wallet seed, which is either
+// entered as a hexadecimal value or as the words of a BIP0039 mnemonic.  The
+// user is also prompted for the passphrase of a mnemonic.  The prompt is
+// repeated until the user enters a valid seed.
+func existingSeed(reader *bufio.Reader) ([]byte, error) {
 	for {
-		fmt.Print("Enter existing wallet seed: ")
+		fmt.Print("Enter existing wallet seed or mnemonic: ")
 		seedStr, err := reader.ReadString('\n')
 		if err != nil {
 			return nil, err
 		}
 		seedStr = strings.TrimSpace(strings.ToLower(seedStr))
 
+		if len(strings.Fields(seedStr)) > 1 {
+			if _, err := bip39.EntropyFromMnemonic(seedStr); err != nil {
+				fmt.Printf("Invalid mnemonic specified: %v\n", err)
+				continue
+			}
+			passphrase, err := mnemonicPassphrase(reader, "Was the "+
+				"mnemonic created with a passphrase?", false)
+			if err != nil {
+				return nil, err
+			}
+			return bip39.NewSeed(seedStr, passphrase)
+		}
+
 		seed, err := hex.DecodeString(seedStr)
 		if err != nil || len(seed) < hdkeychain.MinSeedBytes ||
 			len(seed) > hdkeychain.MaxSeedBytes {
 
 			fmt.Printf("Invalid seed specified.  Must be a "+
 				"hexadecimal value that is at least %d bits and "+
-				"at most %d bits\n", hdkeychain.MinSeedBytes*8,
-				hdkeychain.MaxSeedBytes*8)
+				"at most %d bits, or a BIP0039 mnemonic\n",
+				hdkeychain.MinSeedBytes*8, hdkeychain.MaxSeedBytes*8)
 			continue
 		}
 
 		return seed, nil
 	}
 }
+
+// mnemonicPassphrase prompts the user whether a BIP0039 mnemonic has a
+// passphrase with the given prefix, and if so for the passphrase itself.  An
+// empty passphrase is returned when the user answers no.
+func mnemonicPassphrase(reader *bufio.Reader, prefix string,
+	confirm bool) (string, error) {
+
+	usePassphrase, err := promptListBool(reader, prefix, "no")
+	if err != nil || !usePassphrase {
+		return "", err
+	}
+	passphrase, err := promptPass(reader, "Enter the mnemonic passphrase",
+		confirm)
+	if err != nil {
+		return "", err
+	}
+	return string(passphrase), nil
+}
@@ -7,6 +7,16 @@
 package rpchelp
 
 var helpDescsEnUS = map[string]string{
+	// AbandonTransactionCmd help.
+	"abandontransaction--synopsis": "Removes an unmined wallet transaction, and every unmined wallet transaction spending its outputs, from the wallet.\n" +
+		"The outputs spent by the removed transactions become spendable again.",
+	"abandontransaction-txid": "The hash of the unmined transaction to abandon",
+
+	// AbortRescanCmd help.
+	"abortrescan--synopsis": "Stops the running rescan and the rescans waiting for it.\n" +
+		"The rescan resumes from its last checkpoint when the wallet is restarted.",
+	"abortrescan--result0": "Whether a running rescan was stopped",
+
 	// AddMultisigAddressCmd help.
 	"addmultisigaddress--synopsis": "Generates and imports a multisig address and redeeming script to the 'imported' account.",
 	"addmultisigaddress-account":   "DEPRECATED -- Unused (all imported addresses belong to the imported account)",
@@ -23,6 +33,17 @@ var helpDescsEnUS = map[string]string{
 	"createmultisigresult-address":      "The generated pay-to-script-hash address",
 	"createmultisigresult-redeemScript": "The script required to redeem outputs paid to the multisig address",
 
+	// CreateWalletCmd help.
+	"createwallet--synopsis": "Creates and loads a new named wallet encrypted with the passphrase.\n" +
+		"Requests are sent to the wallet at the '/wallet/<walletname>' path of the server.",
+	"createwallet-walletname":         "The name of the new wallet",
+	"createwallet-disableprivatekeys": "Unsupported; wallets always hold private keys",
+	"createwallet-blank":              "Unsupported; wallets are always created from a seed",
+	"createwallet-passphrase":         "The passphrase encrypting the private keys of the wallet, which is required",
+
+	// LoadWalletResult help.
+	"loadwalletresult-name": "The name of the loaded wallet",
+
 	// DumpPrivKeyCmd help.
 	"dumpprivkey--synopsis": "Returns the private key in WIF encoding that controls some wallet address.",
 	"dumpprivkey-address":   "The address to return a private key for",
@@ -220,7 +241,7 @@ var helpDescsEnUS = map[string]string{
 	"listtransactionsresult-time":               "The earliest Unix time this transaction was known to exist",
 	"listtransactionsresult-timereceived":       "The earliest Unix time this transaction was known to exist",
 	"listtransactionsresult-involveswatchonly":  "Unset",
-	"listtransactionsresult-comment":            "Unset",
+	"listtransactionsresult-comment":            "The label of the transaction, if any",
 	"listtransactionsresult-otheraccount":       "Unset",
 	"listtransactionsresult-trusted":            "Unset",
 	"listtransactionsresult-bip125-replaceable": "Unset",
@@ -250,6 +271,15 @@ var helpDescsEnUS = map[string]string{
 	"listunspentresult-confirmations": "The number of block confirmations of the transaction",
 	"listunspentresult-spendable":     "Whether the output is entirely controlled by wallet keys/scripts (false for partially controlled multisig outputs or outputs to watch-only addresses)",
 
+	// ListWalletsCmd help.
+	"listwallets--synopsis": "Returns the names of the loaded wallets.\n" +
+		"The default wallet is named by the empty string.",
+	"listwallets--result0": "The names of the loaded wallets",
+
+	// LoadWalletCmd help.
+	"loadwallet--synopsis": "Loads a named wallet created by 'createwallet'.",
+	"loadwallet-filename":  "The name of the wallet",
+
 	// LockUnspentCmd help.
 	"lockunspent--synopsis": "Locks or unlocks an unspent output.\n" +
 		"Locked outputs are not chosen for transaction inputs of authored transactions and are not included in 'listunspent' results.\n" +
@@ -323,6 +353,10 @@ var helpDescsEnUS = map[string]string{
 	"signrawtransactionerror-txid":      "The transaction hash of the referenced previous output",
 	"signrawtransactionerror-vout":      "The output index of the referenced previous output",
 
+	// UnloadWalletCmd help.
+	"unloadwallet--synopsis":  "Unloads a named wallet.\nThe default wallet can not be unloaded.",
+	"unloadwallet-walletname": "The name of the wallet to unload, defaulting to the wallet of the request path",
+
 	// ValidateAddressCmd help.
 	"validateaddress--synopsis": "Verify that an address is valid.\n" +
 		"Extra details are returned if the address is controlled by this wallet.\n" +
@@ -371,6 +405,19 @@ var helpDescsEnUS = map[string]string{
 		"The wallet must be unlocked for this request to succeed.",
 	"createnewaccount-account": "Name of the new account",
 
+	// DebugLevelCmd help.
+	"debuglevel--synopsis": "Sets the logging levels of the wallet process, or shows the current levels with 'show'.\n" +
+		"Changed levels are not saved and only apply until the wallet is restarted.\n" +
+		"The request is not passed through to the chain server, whose levels must be changed with its own RPC server.",
+	"debuglevel-levelspec": "A single level for all subsystems, or comma-separated subsystem=level pairs, in the format of the debuglevel option",
+	"debuglevel--result0":  "The current levels of all subsystems as comma-separated subsystem=level pairs",
+
+	// ExportRootKeyCmd help.
+	"exportrootkey--synopsis": "Returns the HD root extended private key of the wallet encrypted with a key derived from the passphrase.\n" +
+		"The wallet must be unlocked for this request to succeed.",
+	"exportrootkey-passphrase": "The passphrase to encrypt the root key with, which is independent of the wallet passphrase",
+	"exportrootkey--result0":   "The encrypted root key as a hexadecimal string",
+
 	// ExportWatchingWalletCmd help.
 	"exportwatchingwallet--synopsis": "Creates and returns a duplicate of the wallet database without any private keys to be used as a watching-only wallet.",
 	"exportwatchingwallet-account":   "Unused (must be unset or \"*\")",
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package walletjson defines the JSON-RPC commands of btcwallet extensions
+// which are not provided by btcjson.  The commands are registered with btcjson
+// when the package is imported, so they can be unmarshaled and have help
+// generated like the btcjson commands.
+package walletjson
+
+import "github.com/btcsuite/btcd/btcjson"
+
+// AbandonTransactionCmd defines the abandontransaction JSON-RPC command.
+type AbandonTransactionCmd struct {
+	Txid string
+}
+
+// NewAbandonTransactionCmd returns a new instance which can be used to issue
+// an abandontransaction JSON-RPC command.
+func NewAbandonTransactionCmd(txHash string) *AbandonTransactionCmd {
+	return &AbandonTransactionCmd{
+		Txid: txHash,
+	}
+}
+
+// AbortRescanCmd defines the abortrescan JSON-RPC command.
+type AbortRescanCmd struct{}
+
+// NewAbortRescanCmd returns a new instance which can be used to issue an
+// abortrescan JSON-RPC command.
+func NewAbortRescanCmd() *AbortRescanCmd {
+	return &AbortRescanCmd{}
+}
+
+// CreateWalletCmd defines the createwallet JSON-RPC command.  The arguments
+// are ordered as those of the createwallet command of Bitcoin Core.
+type CreateWalletCmd struct {
+	WalletName         string
+	DisablePrivateKeys *bool `jsonrpcdefault:"false"`
+	Blank              *bool `jsonrpcdefault:"false"`
+	Passphrase         *string
+}
+
+// NewCreateWalletCmd returns a new instance which can be used to issue a
+// createwallet JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateWalletCmd(walletName string, disablePrivateKeys, blank *bool,
+	passphrase *string) *CreateWalletCmd {
+
+	return &CreateWalletCmd{
+		WalletName:         walletName,
+		DisablePrivateKeys: disablePrivateKeys,
+		Blank:              blank,
+		Passphrase:         passphrase,
+	}
+}
+
+// ExportRootKeyCmd defines the exportrootkey JSON-RPC command.
+type ExportRootKeyCmd struct {
+	Passphrase string
+}
+
+// NewExportRootKeyCmd returns a new instance which can be used to issue an
+// exportrootkey JSON-RPC command.
+func NewExportRootKeyCmd(passphrase string) *ExportRootKeyCmd {
+	return &ExportRootKeyCmd{
+		Passphrase: passphrase,
+	}
+}
+
+// ListWalletsCmd defines the listwallets JSON-RPC command.
+type ListWalletsCmd struct{}
+
+// NewListWalletsCmd returns a new instance which can be used to issue a
+// listwallets JSON-RPC command.
+func NewListWalletsCmd() *ListWalletsCmd {
+	return &ListWalletsCmd{}
+}
+
+// LoadWalletCmd defines the loadwallet JSON-RPC command.
+type LoadWalletCmd struct {
+	Filename string
+}
+
+// NewLoadWalletCmd returns a new instance which can be used to issue a
+// loadwallet JSON-RPC command.
+func NewLoadWalletCmd(filename string) *LoadWalletCmd {
+	return &LoadWalletCmd{
+		Filename: filename,
+	}
+}
+
+// UnloadWalletCmd defines the unloadwallet JSON-RPC command.
+type UnloadWalletCmd struct {
+	WalletName *string
+}
+
+// NewUnloadWalletCmd returns a new instance which can be used to issue an
+// unloadwallet JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewUnloadWalletCmd(walletName *string) *UnloadWalletCmd {
+	return &UnloadWalletCmd{
+		WalletName: walletName,
+	}
+}
+
+func init() {
+	// The commands in this file are only usable with a wallet server.
+	flags := btcjson.UFWalletOnly
+
+	btcjson.MustRegisterCmd("abandontransaction",
+		(*AbandonTransactionCmd)(nil), flags)
+	btcjson.MustRegisterCmd("abortrescan", (*AbortRescanCmd)(nil), flags)
+	btcjson.MustRegisterCmd("createwallet", (*CreateWalletCmd)(nil), flags)
+	btcjson.MustRegisterCmd("exportrootkey", (*ExportRootKeyCmd)(nil), flags)
+	btcjson.MustRegisterCmd("listwallets", (*ListWalletsCmd)(nil), flags)
+	btcjson.MustRegisterCmd("loadwallet", (*LoadWalletCmd)(nil), flags)
+	btcjson.MustRegisterCmd("unloadwallet", (*UnloadWalletCmd)(nil), flags)
+}
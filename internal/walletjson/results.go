@@ -0,0 +1,11 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletjson
+
+// LoadWalletResult models the data returned by the createwallet and
+// loadwallet commands.
+type LoadWalletResult struct {
+	Name string `json:"name"`
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btclog"
 	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/internal/metrics"
 	"github.com/btcsuite/btcwallet/rpc/legacyrpc"
+	"github.com/btcsuite/btcwallet/rpc/restgateway"
 	"github.com/btcsuite/btcwallet/rpc/rpcserver"
 	"github.com/btcsuite/btcwallet/wallet"
 	"github.com/btcsuite/btcwallet/wtxmgr"
@@ -59,6 +61,8 @@ var (
 	chainLog     = backendLog.Logger("CHNS")
 	grpcLog      = backendLog.Logger("GRPC")
 	legacyRPCLog = backendLog.Logger("RPCS")
+	restLog      = backendLog.Logger("REST")
+	metricsLog   = backendLog.Logger("MTRC")
 	btcnLog      = backendLog.Logger("BTCN")
 )
 
@@ -70,6 +74,8 @@ func init() {
 	rpcclient.UseLogger(chainLog)
 	rpcserver.UseLogger(grpcLog)
 	legacyrpc.UseLogger(legacyRPCLog)
+	restgateway.UseLogger(restLog)
+	metrics.UseLogger(metricsLog)
 	neutrino.UseLogger(btcnLog)
 }
 
@@ -81,6 +87,8 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"CHNS": chainLog,
 	"GRPC": grpcLog,
 	"RPCS": legacyRPCLog,
+	"REST": restLog,
+	"MTRC": metricsLog,
 	"BTCN": btcnLog,
 }
 
@@ -122,15 +130,35 @@ func setLogLevel(subsystemID string, logLevel string) {
 	logger.SetLevel(level)
 }
 
-// setLogLevels sets the log level for all subsystem loggers to the passed
-// level.  It also dynamically creates the subsystem loggers as needed, so it
-// can be used to initialize the logging system.
-func setLogLevels(logLevel string) {
-	// Configure all sub-systems with the new logging level.  Dynamically
-	// create loggers as needed.
-	for subsystemID := range subsystemLoggers {
-		setLogLevel(subsystemID, logLevel)
+// logLevelNames are the names of the logging levels accepted by the debuglevel
+// option.
+var logLevelNames = map[btclog.Level]string{
+	btclog.LevelTrace:    "trace",
+	btclog.LevelDebug:    "debug",
+	btclog.LevelInfo:     "info",
+	btclog.LevelWarn:     "warn",
+	btclog.LevelError:    "error",
+	btclog.LevelCritical: "critical",
+	btclog.LevelOff:      "off",
+}
+
+// debugLevels implements the debug level methods of the RPC servers.  It sets
+// the log levels of a level spec in the format of the debuglevel option, unless
+// the spec is empty or "show", and returns the current log level of every
+// subsystem.
+func debugLevels(levelSpec string) (map[string]string, error) {
+	if levelSpec != "" && levelSpec != "show" {
+		if err := parseAndSetDebugLevels(levelSpec); err != nil {
+			return nil, err
+		}
+		log.Infof("Debug levels changed to %s", levelSpec)
+	}
+
+	levels := make(map[string]string, len(subsystemLoggers))
+	for subsystemID, logger := range subsystemLoggers {
+		levels[subsystemID] = logLevelNames[logger.Level()]
 	}
+	return levels, nil
 }
 
 // pickNoun returns the singular or plural form of a noun depending
@@ -12,28 +12,47 @@ type Params struct {
 	*chaincfg.Params
 	RPCClientPort string
 	RPCServerPort string
+
+	// RESTServerPort is the default port of the REST gateway of the RPC
+	// server.
+	RESTServerPort string
+
+	// MetricsServerPort is the default port of the metrics server.
+	MetricsServerPort string
+
+	// BitcoindRPCPort is the default RPC port of bitcoind, and is empty for
+	// networks bitcoind does not support.
+	BitcoindRPCPort string
 }
 
 // MainNetParams contains parameters specific running btcwallet and
 // btcd on the main network (wire.MainNet).
 var MainNetParams = Params{
-	Params:        &chaincfg.MainNetParams,
-	RPCClientPort: "8334",
-	RPCServerPort: "8332",
+	Params:            &chaincfg.MainNetParams,
+	RPCClientPort:     "8334",
+	RPCServerPort:     "8332",
+	RESTServerPort:    "8336",
+	MetricsServerPort: "8338",
+	BitcoindRPCPort:   "8332",
 }
 
 // TestNet3Params contains parameters specific running btcwallet and
 // btcd on the test network (version 3) (wire.TestNet3).
 var TestNet3Params = Params{
-	Params:        &chaincfg.TestNet3Params,
-	RPCClientPort: "18334",
-	RPCServerPort: "18332",
+	Params:            &chaincfg.TestNet3Params,
+	RPCClientPort:     "18334",
+	RPCServerPort:     "18332",
+	RESTServerPort:    "18336",
+	MetricsServerPort: "18338",
+	BitcoindRPCPort:   "18332",
 }
 
 // SimNetParams contains parameters specific to the simulation test network
 // (wire.SimNet).
 var SimNetParams = Params{
-	Params:        &chaincfg.SimNetParams,
-	RPCClientPort: "18556",
-	RPCServerPort: "18554",
+	Params:            &chaincfg.SimNetParams,
+	RPCClientPort:     "18556",
+	RPCServerPort:     "18554",
+	RESTServerPort:    "18558",
+	MetricsServerPort: "18559",
 }
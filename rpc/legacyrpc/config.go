@@ -4,11 +4,40 @@
 
 package legacyrpc
 
+import (
+	"time"
+
+	"github.com/btcsuite/btcwallet/rpc/rpcpolicy"
+)
+
 // Options contains the required options for running the legacy RPC server.
 type Options struct {
 	Username string
 	Password string
 
+	// Users maps additional usernames accepted by the server to their
+	// passwords.
+	Users map[string]string
+
+	// Policy restricts the methods each username may call.  It may be nil
+	// to allow every method.
+	Policy *rpcpolicy.Policy
+
+	// CallObserver, if set, is called with the method and duration of
+	// every handled request, and whether it failed.  Requests passed
+	// through to the chain server are reported with the method
+	// "passthrough".
+	CallObserver func(method string, d time.Duration, failed bool)
+
+	// DebugLevel, if set, queries and changes the logging levels of the
+	// process for debuglevel requests.
+	DebugLevel DebugLevelFunc
+
 	MaxPOSTClients      int64
 	MaxWebsocketClients int64
 }
+
+// DebugLevelFunc applies a level spec of a debuglevel request, other than
+// "show", and returns the resulting level of each subsystem keyed by subsystem.
+// Invalid specs are rejected without changing any level.
+type DebugLevelFunc func(levelSpec string) (map[string]string, error)
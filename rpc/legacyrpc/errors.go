@@ -37,6 +37,10 @@ type (
 	}
 )
 
+// ErrRPCWalletNotFound is the error code of requests for a wallet which does
+// not exist or is not loaded, as used by the reference implementation.
+const ErrRPCWalletNotFound btcjson.RPCErrorCode = -18
+
 // Errors variables that are defined once here to avoid duplication below.
 var (
 	ErrNeedPositiveAmount = InvalidParameterError{
@@ -81,4 +85,29 @@ var (
 		Code:    btcjson.ErrRPCInvalidParameter,
 		Message: "Account name is reserved by RPC server",
 	}
+
+	ErrWalletNotFound = btcjson.RPCError{
+		Code:    ErrRPCWalletNotFound,
+		Message: "Requested wallet does not exist or is not loaded",
+	}
+
+	ErrNamedWalletsDisabled = btcjson.RPCError{
+		Code:    btcjson.ErrRPCWallet,
+		Message: "Named wallets are not supported by this server",
+	}
+
+	ErrDebugLevelDisabled = btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "Logging levels can not be changed by this server",
+	}
+
+	ErrMethodDisabled = btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "Method is disabled for this user by the RPC policy",
+	}
+
+	ErrRateLimited = btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "Method call rate exceeds the RPC policy limit for this user",
+	}
 )
@@ -12,10 +12,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -24,10 +25,11 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/internal/walletjson"
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/wallet"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
-	"github.com/btcsuite/btcwallet/wtxmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
 )
 
 // confirmed checks whether a transaction at height txHeight has met minconf
@@ -58,9 +60,21 @@ type requestHandler func(interface{}, *wallet.Wallet) (interface{}, error)
 // requestHandlerChain is a requestHandler that also takes a parameter for
 type requestHandlerChainRequired func(interface{}, *wallet.Wallet, *chain.RPCClient) (interface{}, error)
 
+// requestHandlerLoader is a handler function for requests managing the wallets
+// hosted by the server.  It is passed the server's multiloader and the name of
+// the wallet the request was sent to, which is empty for the default wallet.
+type requestHandlerLoader func(interface{}, *wallet.MultiLoader, string) (interface{}, error)
+
+// requestHandlerDebugLevel is a handler function for requests querying and
+// changing the logging levels of the process.  It is passed the server's debug
+// level function, which is nil if the server does not support it.
+type requestHandlerDebugLevel func(interface{}, DebugLevelFunc) (interface{}, error)
+
 var rpcHandlers = map[string]struct {
-	handler          requestHandler
-	handlerWithChain requestHandlerChainRequired
+	handler               requestHandler
+	handlerWithChain      requestHandlerChainRequired
+	handlerWithLoader     requestHandlerLoader
+	handlerWithDebugLevel requestHandlerDebugLevel
 
 	// Function variables cannot be compared against anything but nil, so
 	// use a boolean to record whether help generation is necessary.  This
@@ -73,8 +87,11 @@ var rpcHandlers = map[string]struct {
 	noHelp bool
 }{
 	// Reference implementation wallet methods (implemented)
+	"abandontransaction":     {handler: abandonTransaction},
+	"abortrescan":            {handler: abortRescan},
 	"addmultisigaddress":     {handler: addMultiSigAddress},
 	"createmultisig":         {handler: createMultiSig},
+	"createwallet":           {handlerWithLoader: createWallet},
 	"dumpprivkey":            {handler: dumpPrivKey},
 	"getaccount":             {handler: getAccount},
 	"getaccountaddress":      {handler: getAccountAddress},
@@ -95,9 +112,11 @@ var rpcHandlers = map[string]struct {
 	"listlockunspent":        {handler: listLockUnspent},
 	"listreceivedbyaccount":  {handler: listReceivedByAccount},
 	"listreceivedbyaddress":  {handler: listReceivedByAddress},
-	"listsinceblock":         {handlerWithChain: listSinceBlock},
+	"listsinceblock":         {handler: listSinceBlock},
 	"listtransactions":       {handler: listTransactions},
 	"listunspent":            {handler: listUnspent},
+	"listwallets":            {handlerWithLoader: listWallets},
+	"loadwallet":             {handlerWithLoader: loadWallet},
 	"lockunspent":            {handler: lockUnspent},
 	"sendfrom":               {handlerWithChain: sendFrom},
 	"sendmany":               {handler: sendMany},
@@ -105,6 +124,7 @@ var rpcHandlers = map[string]struct {
 	"settxfee":               {handler: setTxFee},
 	"signmessage":            {handler: signMessage},
 	"signrawtransaction":     {handlerWithChain: signRawTransaction},
+	"unloadwallet":           {handlerWithLoader: unloadWallet},
 	"validateaddress":        {handler: validateAddress},
 	"verifymessage":          {handler: verifyMessage},
 	"walletlock":             {handler: walletLock},
@@ -126,6 +146,8 @@ var rpcHandlers = map[string]struct {
 
 	// Extensions to the reference client JSON-RPC API
 	"createnewaccount": {handler: createNewAccount},
+	"debuglevel":       {handlerWithDebugLevel: debugLevel},
+	"exportrootkey":    {handler: exportRootKey},
 	"getbestblock":     {handler: getBestBlock},
 	// This was an extension but the reference implementation added it as
 	// well, but with a different API (no account parameter).  It's listed
@@ -228,6 +250,46 @@ func lazyApplyHandler(request *btcjson.Request, w *wallet.Wallet, chainClient ch
 	}
 }
 
+// lazyApplyLoaderHandler returns a closure executing the handler of a request
+// managing the wallets of the multiloader, or responding with an error if the
+// server does not host named wallets.
+func lazyApplyLoaderHandler(request *btcjson.Request, handler requestHandlerLoader,
+	multiLoader *wallet.MultiLoader, walletName string) lazyHandler {
+
+	return func() (interface{}, *btcjson.RPCError) {
+		if multiLoader == nil {
+			return nil, &ErrNamedWalletsDisabled
+		}
+		cmd, err := btcjson.UnmarshalCmd(request)
+		if err != nil {
+			return nil, btcjson.ErrRPCInvalidRequest
+		}
+		resp, err := handler(cmd, multiLoader, walletName)
+		if err != nil {
+			return nil, jsonError(err)
+		}
+		return resp, nil
+	}
+}
+
+// lazyApplyDebugLevelHandler returns a closure executing the handler of a
+// request querying or changing the logging levels with debugLevel.
+func lazyApplyDebugLevelHandler(request *btcjson.Request,
+	handler requestHandlerDebugLevel, debugLevel DebugLevelFunc) lazyHandler {
+
+	return func() (interface{}, *btcjson.RPCError) {
+		cmd, err := btcjson.UnmarshalCmd(request)
+		if err != nil {
+			return nil, btcjson.ErrRPCInvalidRequest
+		}
+		resp, err := handler(cmd, debugLevel)
+		if err != nil {
+			return nil, jsonError(err)
+		}
+		return resp, nil
+	}
+}
+
 // makeResponse makes the JSON-RPC response struct for the result and error
 // returned by a requestHandler.  The returned response is not ready for
 // marshaling and sending off to a client, but must be
@@ -374,6 +436,31 @@ func createMultiSig(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	}, nil
 }
 
+// debugLevel handles a debuglevel request by setting the logging levels of the
+// level spec, unless it is "show", and returning the current levels of all
+// subsystems in the format of a level spec, such as "BTCW=info,WLLT=debug".
+func debugLevel(icmd interface{}, setLevels DebugLevelFunc) (interface{}, error) {
+	cmd := icmd.(*btcjson.DebugLevelCmd)
+
+	if setLevels == nil {
+		return nil, &ErrDebugLevelDisabled
+	}
+	levels, err := setLevels(cmd.LevelSpec)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	pairs := make([]string, 0, len(levels))
+	for subsystem, level := range levels {
+		pairs = append(pairs, subsystem+"="+level)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), nil
+}
+
 // dumpPrivKey handles a dumpprivkey request with the private key
 // for a single address, or an appropiate error if the wallet
 // is locked.
@@ -394,6 +481,156 @@ func dumpPrivKey(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	return key, err
 }
 
+// abandonTransaction handles an abandontransaction request by removing an
+// unmined transaction and the unmined transactions spending its outputs from
+// the wallet.
+func abandonTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*walletjson.AbandonTransactionCmd)
+
+	txHash, err := chainhash.NewHashFromStr(cmd.Txid)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDecodeHexString,
+			Message: "Transaction hash string decode failed: " + err.Error(),
+		}
+	}
+	if err := w.AbandonTransaction(txHash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// abortRescan handles an abortrescan request by canceling the running rescan.
+// False is returned when no rescan is running.
+func abortRescan(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	err := w.CancelRescan()
+	switch err {
+	case nil:
+		return true, nil
+	case chain.ErrNoRescan:
+		return false, nil
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: err.Error(),
+		}
+	}
+}
+
+// exportRootKey handles an exportrootkey request by returning the HD root key
+// of the wallet encrypted with the passphrase of the request, or an appropriate
+// error if the wallet is locked.
+func exportRootKey(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*walletjson.ExportRootKeyCmd)
+
+	key, err := w.ExportEncryptedRootKey([]byte(cmd.Passphrase))
+	if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+		return nil, &ErrWalletUnlockNeeded
+	}
+	return key, err
+}
+
+// createWallet handles a createwallet request by creating and loading a new
+// named wallet encrypted with the passphrase of the request.  The public
+// passphrase of named wallets is always the insecure default.
+func createWallet(icmd interface{}, m *wallet.MultiLoader, walletName string) (interface{}, error) {
+	cmd := icmd.(*walletjson.CreateWalletCmd)
+
+	// Wallets always hold encrypted private keys derived from a seed.
+	if *cmd.DisablePrivateKeys {
+		return nil, InvalidParameterError{
+			errors.New("wallets without private keys are unsupported"),
+		}
+	}
+	if *cmd.Blank {
+		return nil, InvalidParameterError{
+			errors.New("blank wallets are unsupported"),
+		}
+	}
+	if cmd.Passphrase == nil || *cmd.Passphrase == "" {
+		return nil, InvalidParameterError{
+			errors.New("passphrase must not be empty"),
+		}
+	}
+	_, err := m.CreateWallet(cmd.WalletName,
+		[]byte(wallet.InsecurePubPassphrase), []byte(*cmd.Passphrase),
+		nil, time.Now())
+	if err != nil {
+		return nil, namedWalletError(cmd.WalletName, err)
+	}
+	return &walletjson.LoadWalletResult{Name: cmd.WalletName}, nil
+}
+
+// listWallets handles a listwallets request by returning the names of the
+// loaded wallets, where the default wallet is named by the empty string.
+func listWallets(icmd interface{}, m *wallet.MultiLoader, walletName string) (interface{}, error) {
+	return m.LoadedWallets(), nil
+}
+
+// loadWallet handles a loadwallet request by opening the named wallet.
+func loadWallet(icmd interface{}, m *wallet.MultiLoader, walletName string) (interface{}, error) {
+	cmd := icmd.(*walletjson.LoadWalletCmd)
+
+	_, err := m.OpenWallet(cmd.Filename, []byte(wallet.InsecurePubPassphrase))
+	if err != nil {
+		return nil, namedWalletError(cmd.Filename, err)
+	}
+	return &walletjson.LoadWalletResult{Name: cmd.Filename}, nil
+}
+
+// unloadWallet handles an unloadwallet request by unloading the wallet named
+// by the request, or the wallet the request was sent to when no name is given.
+// The default wallet can not be unloaded.
+func unloadWallet(icmd interface{}, m *wallet.MultiLoader, walletName string) (interface{}, error) {
+	cmd := icmd.(*walletjson.UnloadWalletCmd)
+
+	if cmd.WalletName != nil {
+		if walletName != "" && *cmd.WalletName != walletName {
+			return nil, InvalidParameterError{
+				errors.New("wallet name differs from the wallet " +
+					"of the request path"),
+			}
+		}
+		walletName = *cmd.WalletName
+	}
+	if walletName == "" {
+		return nil, InvalidParameterError{
+			errors.New("the default wallet can not be unloaded"),
+		}
+	}
+	err := m.UnloadWallet(walletName)
+	if err != nil {
+		return nil, namedWalletError(walletName, err)
+	}
+	return nil, nil
+}
+
+// namedWalletError returns the JSON-RPC error of an error creating, opening or
+// unloading the named wallet.
+func namedWalletError(name string, err error) error {
+	switch err {
+	case wallet.ErrInvalidWalletName:
+		return InvalidParameterError{err}
+	case wallet.ErrNotLoaded, walletdb.ErrDbDoesNotExist:
+		return &ErrWalletNotFound
+	case wallet.ErrLoaded:
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: fmt.Sprintf("Wallet %q is already loaded", name),
+		}
+	case wallet.ErrExists:
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: fmt.Sprintf("Wallet %q already exists", name),
+		}
+	default:
+		return err
+	}
+}
+
 // dumpWallet handles a dumpwallet request by returning  all private
 // keys in a wallet, or an appropiate error if the wallet is locked.
 // TODO: finish this to match bitcoind by writing the dump to a file.
@@ -507,10 +744,12 @@ func getInfo(icmd interface{}, w *wallet.Wallet, chainClient *chain.RPCClient) (
 	info.WalletVersion = int32(waddrmgr.LatestMgrVersion)
 	info.Balance = bal.ToBTC()
 	info.PaytxFee = float64(txrules.DefaultRelayFeePerKb)
+	if health := w.ChainHealth(); health.Degraded() {
+		info.Errors = fmt.Sprintf("chain server is %v", health.Health)
+	}
 	// We don't set the following since they don't make much sense in the
 	// wallet architecture:
 	//  - unlocked_until
-	//  - errors
 
 	return info, nil
 }
@@ -832,8 +1071,6 @@ func getTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	var (
 		debitTotal  btcutil.Amount
 		creditTotal btcutil.Amount // Excludes change
-		fee         btcutil.Amount
-		feeF64      float64
 	)
 	for _, deb := range details.Debits {
 		debitTotal += deb.Amount
@@ -843,15 +1080,8 @@ func getTransaction(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 			creditTotal += cred.Amount
 		}
 	}
-	// Fee can only be determined if every input is a debit.
-	if len(details.Debits) == len(details.MsgTx.TxIn) {
-		var outputTotal btcutil.Amount
-		for _, output := range details.MsgTx.TxOut {
-			outputTotal += btcutil.Amount(output.Value)
-		}
-		fee = debitTotal - outputTotal
-		feeF64 = fee.ToBTC()
-	}
+	// Unknown fees are reported as zero.
+	feeF64 := details.Fee.ToBTC()
 
 	if len(details.Debits) == 0 {
 		// Credits must be set later, but since we know the full length
@@ -1118,8 +1348,6 @@ func listReceivedByAddress(icmd interface{}, w *wallet.Wallet) (interface{}, err
 		account string
 	}
 
-	syncBlock := w.Manager.SyncedTo()
-
 	// Intermediate data for all addresses.
 	allAddrData := make(map[string]AddrData)
 	// Create an AddrData entry for each active address in the account.
@@ -1133,104 +1361,75 @@ func listReceivedByAddress(icmd interface{}, w *wallet.Wallet) (interface{}, err
 		allAddrData[address] = AddrData{}
 	}
 
-	minConf := *cmd.MinConf
-	var endHeight int32
-	if minConf == 0 {
-		endHeight = -1
-	} else {
-		endHeight = syncBlock.Height - int32(minConf) + 1
-	}
-	err = wallet.UnstableAPI(w).RangeTransactions(0, endHeight, func(details []wtxmgr.TxDetails) (bool, error) {
-		confirmations := confirms(details[0].Block.Height, syncBlock.Height)
-		for _, tx := range details {
-			for _, cred := range tx.Credits {
-				pkScript := tx.MsgTx.TxOut[cred.Index].PkScript
-				_, addrs, _, err := txscript.ExtractPkScriptAddrs(
-					pkScript, w.ChainParams())
-				if err != nil {
-					// Non standard script, skip.
-					continue
-				}
-				for _, addr := range addrs {
-					addrStr := addr.EncodeAddress()
-					addrData, ok := allAddrData[addrStr]
-					if ok {
-						addrData.amount += cred.Amount
-						// Always overwrite confirmations with newer ones.
-						addrData.confirmations = confirmations
-					} else {
-						addrData = AddrData{
-							amount:        cred.Amount,
-							confirmations: confirmations,
-						}
-					}
-					addrData.tx = append(addrData.tx, tx.Hash.String())
-					allAddrData[addrStr] = addrData
-				}
-			}
+	received, err := w.ReceivedByAddresses(int32(*cmd.MinConf))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range received {
+		addrData := AddrData{
+			amount:        r.Amount,
+			confirmations: r.Confirmations,
 		}
-		return false, nil
-	})
+		for i := range r.TxHashes {
+			addrData.tx = append(addrData.tx, r.TxHashes[i].String())
+		}
+		allAddrData[r.Address] = addrData
+	}
+
+	labels, err := w.AddressLabels()
 	if err != nil {
 		return nil, err
 	}
 
 	// Massage address data into output format.
 	numAddresses := len(allAddrData)
-	ret := make([]btcjson.ListReceivedByAddressResult, numAddresses, numAddresses)
+	ret := make([]listReceivedByAddressResult, numAddresses, numAddresses)
 	idx := 0
 	for address, addrData := range allAddrData {
-		ret[idx] = btcjson.ListReceivedByAddressResult{
-			Address:       address,
-			Amount:        addrData.amount.ToBTC(),
-			Confirmations: uint64(addrData.confirmations),
-			TxIDs:         addrData.tx,
+		ret[idx] = listReceivedByAddressResult{
+			ListReceivedByAddressResult: btcjson.ListReceivedByAddressResult{
+				Address:       address,
+				Amount:        addrData.amount.ToBTC(),
+				Confirmations: uint64(addrData.confirmations),
+				TxIDs:         addrData.tx,
+			},
+			Label: labels[address],
 		}
 		idx++
 	}
 	return ret, nil
 }
 
+// listReceivedByAddressResult is a listreceivedbyaddress result with the label
+// of the address.
+type listReceivedByAddressResult struct {
+	btcjson.ListReceivedByAddressResult
+	Label string `json:"label,omitempty"`
+}
+
 // listSinceBlock handles a listsinceblock request by returning an array of maps
 // with details of sent and received wallet transactions since the given block.
-func listSinceBlock(icmd interface{}, w *wallet.Wallet, chainClient *chain.RPCClient) (interface{}, error) {
+func listSinceBlock(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	cmd := icmd.(*btcjson.ListSinceBlockCmd)
 
-	syncBlock := w.Manager.SyncedTo()
-	targetConf := int64(*cmd.TargetConfirmations)
-
-	// For the result we need the block hash for the last block counted
-	// in the blockchain due to confirmations. We send this off now so that
-	// it can arrive asynchronously while we figure out the rest.
-	gbh := chainClient.GetBlockHashAsync(int64(syncBlock.Height) + 1 - targetConf)
-
-	var start int32
+	var since *chainhash.Hash
 	if cmd.BlockHash != nil {
-		hash, err := chainhash.NewHashFromStr(*cmd.BlockHash)
+		var err error
+		since, err = chainhash.NewHashFromStr(*cmd.BlockHash)
 		if err != nil {
 			return nil, DeserializationError{err}
 		}
-		block, err := chainClient.GetBlockVerboseTx(hash)
-		if err != nil {
-			return nil, err
-		}
-		start = int32(block.Height) + 1
 	}
 
-	txInfoList, err := w.ListSinceBlock(start, -1, syncBlock.Height)
-	if err != nil {
-		return nil, err
-	}
-
-	// Done with work, get the response.
-	blockHash, err := gbh.Receive()
+	txInfoList, lastBlock, err := w.ListSinceBlockHash(since,
+		int32(*cmd.TargetConfirmations))
 	if err != nil {
 		return nil, err
 	}
 
 	res := btcjson.ListSinceBlockResult{
 		Transactions: txInfoList,
-		LastBlock:    blockHash.String(),
+		LastBlock:    lastBlock.String(),
 	}
 	return res, nil
 }
@@ -1319,7 +1518,30 @@ func listUnspent(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 		}
 	}
 
-	return w.ListUnspent(int32(*cmd.MinConf), int32(*cmd.MaxConf), addresses)
+	unspent, err := w.ListUnspent(int32(*cmd.MinConf), int32(*cmd.MaxConf), addresses)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := w.AddressLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]listUnspentResult, 0, len(unspent))
+	for _, result := range unspent {
+		results = append(results, listUnspentResult{
+			ListUnspentResult: *result,
+			Label:             labels[result.Address],
+		})
+	}
+	return results, nil
+}
+
+// listUnspentResult is a listunspent result with the label of the address of
+// the output.
+type listUnspentResult struct {
+	btcjson.ListUnspentResult
+	Label string `json:"label,omitempty"`
 }
 
 // lockUnspent handles the lockunspent command.
@@ -1371,6 +1593,7 @@ func makeOutputs(pairs map[string]btcutil.Amount, chainParams *chaincfg.Params)
 // sendPairs creates and sends payment transactions.
 // It returns the transaction hash in string format upon success
 // All errors are returned in btcjson.RPCError format
+// A zero feeSatPerKb pays the fee rate estimated by the chain backend
 func sendPairs(w *wallet.Wallet, amounts map[string]btcutil.Amount,
 	account uint32, minconf int32, feeSatPerKb btcutil.Amount) (string, error) {
 
@@ -1378,7 +1601,10 @@ func sendPairs(w *wallet.Wallet, amounts map[string]btcutil.Amount,
 	if err != nil {
 		return "", err
 	}
-	txHash, err := w.SendOutputs(outputs, account, minconf, feeSatPerKb)
+	txHash, err := w.SendOutputs(
+		outputs, account, minconf, feeSatPerKb,
+		w.DefaultCoinSelectionStrategy(),
+	)
 	if err != nil {
 		if err == txrules.ErrAmountNegative {
 			return "", ErrNeedPositiveAmount
@@ -1447,8 +1673,7 @@ func sendFrom(icmd interface{}, w *wallet.Wallet, chainClient *chain.RPCClient)
 		cmd.ToAddress: amt,
 	}
 
-	return sendPairs(w, pairs, account, minConf,
-		txrules.DefaultRelayFeePerKb)
+	return sendPairs(w, pairs, account, minConf, 0)
 }
 
 // sendMany handles a sendmany RPC request by creating a new transaction
@@ -1489,7 +1714,7 @@ func sendMany(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 		pairs[k] = amt
 	}
 
-	return sendPairs(w, pairs, account, minConf, txrules.DefaultRelayFeePerKb)
+	return sendPairs(w, pairs, account, minConf, 0)
 }
 
 // sendToAddress handles a sendtoaddress RPC request by creating a new
@@ -1525,8 +1750,7 @@ func sendToAddress(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	}
 
 	// sendtoaddress always spends from the default account, this matches bitcoind
-	return sendPairs(w, pairs, waddrmgr.DefaultAccountNum, 1,
-		txrules.DefaultRelayFeePerKb)
+	return sendPairs(w, pairs, waddrmgr.DefaultAccountNum, 1, 0)
 }
 
 // setTxFee sets the transaction fee per kilobyte added to transactions.
@@ -1552,17 +1776,7 @@ func signMessage(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 		return nil, err
 	}
 
-	privKey, err := w.PrivKeyForAddress(addr)
-	if err != nil {
-		return nil, err
-	}
-
-	var buf bytes.Buffer
-	wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n")
-	wire.WriteVarString(&buf, 0, cmd.Message)
-	messageHash := chainhash.DoubleHashB(buf.Bytes())
-	sigbytes, err := btcec.SignCompact(btcec.S256(), privKey,
-		messageHash, true)
+	sigbytes, err := w.SignMessage(addr, cmd.Message)
 	if err != nil {
 		return nil, err
 	}
@@ -1840,33 +2054,11 @@ func verifyMessage(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 		return nil, err
 	}
 
-	// Validate the signature - this just shows that it was valid at all.
-	// we will compare it with the key next.
-	var buf bytes.Buffer
-	wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n")
-	wire.WriteVarString(&buf, 0, cmd.Message)
-	expectedMessageHash := chainhash.DoubleHashB(buf.Bytes())
-	pk, wasCompressed, err := btcec.RecoverCompact(btcec.S256(), sig,
-		expectedMessageHash)
+	valid, err := wallet.VerifyMessage(addr, cmd.Message, sig)
 	if err != nil {
 		return nil, err
 	}
-
-	var serializedPubKey []byte
-	if wasCompressed {
-		serializedPubKey = pk.SerializeCompressed()
-	} else {
-		serializedPubKey = pk.SerializeUncompressed()
-	}
-	// Verify that the signed-by address matches the given address
-	switch checkAddr := addr.(type) {
-	case *btcutil.AddressPubKeyHash: // ok
-		return bytes.Equal(btcutil.Hash160(serializedPubKey), checkAddr.Hash160()[:]), nil
-	case *btcutil.AddressPubKey: // ok
-		return string(serializedPubKey) == checkAddr.String(), nil
-	default:
-		return nil, errors.New("address type not supported")
-	}
+	return valid, nil
 }
 
 // walletIsLocked handles the walletislocked extension request by
@@ -5,10 +5,14 @@
 package legacyrpc
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcwallet/rpc/rpcpolicy"
 )
 
 func TestThrottle(t *testing.T) {
@@ -46,3 +50,80 @@ func TestThrottle(t *testing.T) {
 		t.Fatalf("status codes: want: %v, got: %v", want, got)
 	}
 }
+
+func TestUserPolicy(t *testing.T) {
+	policy, err := rpcpolicy.Parse([]string{"bob:dumpprivkey:disable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		Username: "alice",
+		Password: "alicepass",
+		Users:    map[string]string{"bob": "bobpass"},
+		Policy:   policy,
+	}
+	s := NewServer(&opts, nil, nil)
+
+	tests := []struct {
+		username, password string
+		valid              bool
+	}{
+		{"alice", "alicepass", true},
+		{"bob", "bobpass", true},
+		{"bob", "alicepass", false},
+		{"carol", "bobpass", false},
+	}
+	for _, test := range tests {
+		auth := httpBasicAuth(test.username, test.password)
+		username, ok := s.checkAuth(string(auth))
+		if ok != test.valid {
+			t.Errorf("%v:%v: expected valid=%v", test.username,
+				test.password, test.valid)
+		}
+		if ok && username != test.username {
+			t.Errorf("%v:%v: authenticated as %v", test.username,
+				test.password, username)
+		}
+	}
+
+	req := &btcjson.Request{Method: "dumpprivkey"}
+	if jsonErr := s.checkPolicy("alice", req); jsonErr != nil {
+		t.Errorf("policy rejected request of alice: %v", jsonErr)
+	}
+	if jsonErr := s.checkPolicy("bob", req); jsonErr != &ErrMethodDisabled {
+		t.Errorf("expected ErrMethodDisabled, got %v", jsonErr)
+	}
+}
+
+func TestDebugLevel(t *testing.T) {
+	var spec string
+	setLevels := func(levelSpec string) (map[string]string, error) {
+		spec = levelSpec
+		if levelSpec == "WLLT=verbose" {
+			return nil, errors.New("invalid level")
+		}
+		return map[string]string{"WLLT": "debug", "BTCW": "info"}, nil
+	}
+
+	resp, err := debugLevel(btcjson.NewDebugLevelCmd("WLLT=debug"), setLevels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec != "WLLT=debug" {
+		t.Errorf("levels set with spec %q", spec)
+	}
+	if resp != "BTCW=info,WLLT=debug" {
+		t.Errorf("unexpected response %v", resp)
+	}
+
+	_, err = debugLevel(btcjson.NewDebugLevelCmd("WLLT=verbose"), setLevels)
+	if jsonErr, ok := err.(*btcjson.RPCError); !ok ||
+		jsonErr.Code != btcjson.ErrRPCInvalidParameter {
+		t.Errorf("expected invalid parameter error, got %v", err)
+	}
+
+	_, err = debugLevel(btcjson.NewDebugLevelCmd("show"), nil)
+	if err != &ErrDebugLevelDisabled {
+		t.Errorf("expected ErrDebugLevelDisabled, got %v", err)
+	}
+}
@@ -15,12 +15,15 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/rpc/rpcpolicy"
 	"github.com/btcsuite/btcwallet/wallet"
 	"github.com/btcsuite/websocket"
 )
@@ -28,6 +31,7 @@ import (
 type websocketClient struct {
 	conn          *websocket.Conn
 	authenticated bool
+	username      string
 	remoteAddr    string
 	allRequests   chan []byte
 	responses     chan []byte
@@ -35,10 +39,13 @@ type websocketClient struct {
 	wg            sync.WaitGroup
 }
 
-func newWebsocketClient(c *websocket.Conn, authenticated bool, remoteAddr string) *websocketClient {
+func newWebsocketClient(c *websocket.Conn, authenticated bool, username,
+	remoteAddr string) *websocketClient {
+
 	return &websocketClient{
 		conn:          c,
 		authenticated: authenticated,
+		username:      username,
 		remoteAddr:    remoteAddr,
 		allRequests:   make(chan []byte),
 		responses:     make(chan []byte),
@@ -61,14 +68,19 @@ type Server struct {
 	httpServer    http.Server
 	wallet        *wallet.Wallet
 	walletLoader  *wallet.Loader
+	multiLoader   *wallet.MultiLoader
 	chainClient   chain.Interface
 	handlerLookup func(string) (requestHandler, bool)
 	handlerMu     sync.Mutex
 
 	listeners []net.Listener
-	authsha   [sha256.Size]byte
+	auths     []userAuth
+	policy    *rpcpolicy.Policy
 	upgrader  websocket.Upgrader
 
+	callObserver func(method string, d time.Duration, failed bool)
+	debugLevel   DebugLevelFunc
+
 	maxPostClients      int64 // Max concurrent HTTP POST clients.
 	maxWebsocketClients int64 // Max concurrent websocket clients.
 
@@ -79,6 +91,13 @@ type Server struct {
 	requestShutdownChan chan struct{}
 }
 
+// userAuth is the hash of the HTTP Basic authentication string of a user.  A
+// hash is used for a constant time comparison.
+type userAuth struct {
+	sha      [sha256.Size]byte
+	username string
+}
+
 // jsonAuthFail sends a message back to the client if the http auth is rejected.
 func jsonAuthFail(w http.ResponseWriter) {
 	w.Header().Add("WWW-Authenticate", `Basic realm="btcwallet RPC"`)
@@ -103,9 +122,13 @@ func NewServer(opts *Options, walletLoader *wallet.Loader, listeners []net.Liste
 		maxPostClients:      opts.MaxPOSTClients,
 		maxWebsocketClients: opts.MaxWebsocketClients,
 		listeners:           listeners,
-		// A hash of the HTTP basic auth string is used for a constant
-		// time comparison.
-		authsha: sha256.Sum256(httpBasicAuth(opts.Username, opts.Password)),
+		auths: []userAuth{{
+			sha:      sha256.Sum256(httpBasicAuth(opts.Username, opts.Password)),
+			username: opts.Username,
+		}},
+		policy:       opts.Policy,
+		callObserver: opts.CallObserver,
+		debugLevel:   opts.DebugLevel,
 		upgrader: websocket.Upgrader{
 			// Allow all origins.
 			CheckOrigin: func(r *http.Request) bool { return true },
@@ -113,6 +136,17 @@ func NewServer(opts *Options, walletLoader *wallet.Loader, listeners []net.Liste
 		quit:                make(chan struct{}),
 		requestShutdownChan: make(chan struct{}, 1),
 	}
+	usernames := make([]string, 0, len(opts.Users))
+	for username := range opts.Users {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	for _, username := range usernames {
+		server.auths = append(server.auths, userAuth{
+			sha:      sha256.Sum256(httpBasicAuth(username, opts.Users[username])),
+			username: username,
+		})
+	}
 
 	serveMux.Handle("/", throttledFn(opts.MaxPOSTClients,
 		func(w http.ResponseWriter, r *http.Request) {
@@ -120,20 +154,22 @@ func NewServer(opts *Options, walletLoader *wallet.Loader, listeners []net.Liste
 			w.Header().Set("Content-Type", "application/json")
 			r.Close = true
 
-			if err := server.checkAuthHeader(r); err != nil {
+			username, err := server.checkAuthHeader(r)
+			if err != nil {
 				log.Warnf("Unauthorized client connection attempt")
 				jsonAuthFail(w)
 				return
 			}
 			server.wg.Add(1)
-			server.postClientRPC(w, r)
+			server.postClientRPC(w, r, username)
 			server.wg.Done()
 		}))
 
 	serveMux.Handle("/ws", throttledFn(opts.MaxWebsocketClients,
 		func(w http.ResponseWriter, r *http.Request) {
 			authenticated := false
-			switch server.checkAuthHeader(r) {
+			username, err := server.checkAuthHeader(r)
+			switch err {
 			case nil:
 				authenticated = true
 			case ErrNoAuth:
@@ -153,7 +189,8 @@ func NewServer(opts *Options, walletLoader *wallet.Loader, listeners []net.Liste
 					r.RemoteAddr, err)
 				return
 			}
-			wsc := newWebsocketClient(conn, authenticated, r.RemoteAddr)
+			wsc := newWebsocketClient(conn, authenticated, username,
+				r.RemoteAddr)
 			server.websocketClientRPC(wsc)
 		}))
 
@@ -264,14 +301,25 @@ func (s *Server) SetChainServer(chainClient chain.Interface) {
 	s.handlerMu.Unlock()
 }
 
+// SetMultiLoader sets the multiloader hosting named wallets, which enables the
+// methods managing named wallets and the handling of requests sent to the
+// /wallet/<name> path of a named wallet.
+func (s *Server) SetMultiLoader(multiLoader *wallet.MultiLoader) {
+	s.handlerMu.Lock()
+	s.multiLoader = multiLoader
+	s.handlerMu.Unlock()
+}
+
 // handlerClosure creates a closure function for handling requests of the given
 // method.  This may be a request that is handled directly by btcwallet, or
 // a chain server request that is handled by passing the request down to btcd.
+// Requests are handled by the default wallet, unless walletName names a
+// wallet of the multiloader.
 //
 // NOTE: These handlers do not handle special cases, such as the authenticate
 // method.  Each of these must be checked beforehand (the method is already
 // known) and handled accordingly.
-func (s *Server) handlerClosure(request *btcjson.Request) lazyHandler {
+func (s *Server) handlerClosure(request *btcjson.Request, walletName string) lazyHandler {
 	s.handlerMu.Lock()
 	// With the lock held, make copies of these pointers for the closure.
 	wallet := s.wallet
@@ -280,33 +328,116 @@ func (s *Server) handlerClosure(request *btcjson.Request) lazyHandler {
 		chainClient = wallet.ChainClient()
 		s.chainClient = chainClient
 	}
+	multiLoader := s.multiLoader
 	s.handlerMu.Unlock()
 
+	handlerData := rpcHandlers[request.Method]
+	if handlerData.handlerWithDebugLevel != nil {
+		return lazyApplyDebugLevelHandler(request,
+			handlerData.handlerWithDebugLevel, s.debugLevel)
+	}
+	if handlerData.handlerWithLoader != nil {
+		return lazyApplyLoaderHandler(request,
+			handlerData.handlerWithLoader, multiLoader, walletName)
+	}
+
+	// Named wallets are synchronized by their own chain client, which is
+	// also used for passthrough.
+	if walletName != "" {
+		loaded := false
+		if multiLoader != nil {
+			wallet, loaded = multiLoader.LoadedWallet(walletName)
+		}
+		if !loaded {
+			return func() (interface{}, *btcjson.RPCError) {
+				return nil, &ErrWalletNotFound
+			}
+		}
+		chainClient = wallet.ChainClient()
+	}
+
 	return lazyApplyHandler(request, wallet, chainClient)
 }
 
+// walletPathPrefix prefixes the path of HTTP POST requests to a named wallet.
+const walletPathPrefix = "/wallet/"
+
+// requestWalletName returns the name of the wallet an HTTP POST request is sent
+// to, which is empty for requests to the default wallet.
+func requestWalletName(r *http.Request) string {
+	if !strings.HasPrefix(r.URL.Path, walletPathPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(r.URL.Path, walletPathPrefix)
+}
+
 // ErrNoAuth represents an error where authentication could not succeed
 // due to a missing Authorization HTTP header.
 var ErrNoAuth = errors.New("no auth")
 
 // checkAuthHeader checks the HTTP Basic authentication supplied by a client
-// in the HTTP request r.  It errors with ErrNoAuth if the request does not
-// contain the Authorization header, or another non-nil error if the
-// authentication was provided but incorrect.
+// in the HTTP request r, and returns the authenticated username.  It errors
+// with ErrNoAuth if the request does not contain the Authorization header, or
+// another non-nil error if the authentication was provided but incorrect.
 //
 // This check is time-constant.
-func (s *Server) checkAuthHeader(r *http.Request) error {
+func (s *Server) checkAuthHeader(r *http.Request) (string, error) {
 	authhdr := r.Header["Authorization"]
 	if len(authhdr) == 0 {
-		return ErrNoAuth
+		return "", ErrNoAuth
+	}
+
+	username, ok := s.checkAuth(authhdr[0])
+	if !ok {
+		return "", errors.New("bad auth")
+	}
+	return username, nil
+}
+
+// checkAuth returns the username authenticated by the HTTP Basic
+// authentication string auth, and whether the authentication is valid.  Every
+// user is compared in constant time to not reveal which one matched.
+func (s *Server) checkAuth(auth string) (string, bool) {
+	authsha := sha256.Sum256([]byte(auth))
+	username, ok := "", false
+	for i := range s.auths {
+		cmp := subtle.ConstantTimeCompare(authsha[:], s.auths[i].sha[:])
+		if cmp == 1 {
+			username, ok = s.auths[i].username, true
+		}
 	}
+	return username, ok
+}
 
-	authsha := sha256.Sum256([]byte(authhdr[0]))
-	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
-	if cmp != 1 {
-		return errors.New("bad auth")
+// observeCall reports the duration since start of a handled request, and
+// whether it failed, to the call observer of the server.
+func (s *Server) observeCall(req *btcjson.Request, start time.Time, jsonErr *btcjson.RPCError) {
+	if s.callObserver == nil {
+		return
+	}
+	method := req.Method
+	if _, ok := rpcHandlers[method]; !ok {
+		// Limit the reported methods to those known to the wallet.
+		method = "passthrough"
+	}
+	s.callObserver(method, time.Since(start), jsonErr != nil)
+}
+
+// checkPolicy returns the error of a request which the RPC policy does not
+// allow the user to send, or nil if the request is allowed.
+func (s *Server) checkPolicy(username string, req *btcjson.Request) *btcjson.RPCError {
+	switch s.policy.Check(username, req.Method) {
+	case nil:
+		return nil
+	case rpcpolicy.ErrRateLimited:
+		log.Debugf("Rate limited %v request of user %v", req.Method,
+			username)
+		return &ErrRateLimited
+	default:
+		log.Warnf("Rejected %v request of user %v disabled by the RPC "+
+			"policy", req.Method, username)
+		return &ErrMethodDisabled
 	}
-	return nil
 }
 
 // throttledFn wraps an http.HandlerFunc with throttling of concurrent active
@@ -363,23 +494,22 @@ func idPointer(id interface{}) (p *interface{}) {
 	return
 }
 
-// invalidAuth checks whether a websocket request is a valid (parsable)
+// checkAuthCmd checks whether a websocket request is a valid (parsable)
 // authenticate request and checks the supplied username and passphrase
-// against the server auth.
-func (s *Server) invalidAuth(req *btcjson.Request) bool {
+// against the server auth, returning the authenticated username and whether
+// the request authenticates the client.
+func (s *Server) checkAuthCmd(req *btcjson.Request) (string, bool) {
 	cmd, err := btcjson.UnmarshalCmd(req)
 	if err != nil {
-		return false
+		return "", false
 	}
 	authCmd, ok := cmd.(*btcjson.AuthenticateCmd)
 	if !ok {
-		return false
+		return "", false
 	}
 	// Check credentials.
 	login := authCmd.Username + ":" + authCmd.Passphrase
-	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-	authSha := sha256.Sum256([]byte(auth))
-	return subtle.ConstantTimeCompare(authSha[:], s.authsha[:]) != 1
+	return s.checkAuth("Basic " + base64.StdEncoding.EncodeToString([]byte(login)))
 }
 
 func (s *Server) websocketClientRead(wsc *websocketClient) {
@@ -436,11 +566,17 @@ out:
 			}
 
 			if req.Method == "authenticate" {
-				if wsc.authenticated || s.invalidAuth(&req) {
+				if wsc.authenticated {
+					// Disconnect immediately.
+					break out
+				}
+				username, ok := s.checkAuthCmd(&req)
+				if !ok {
 					// Disconnect immediately.
 					break out
 				}
 				wsc.authenticated = true
+				wsc.username = username
 				resp := makeResponse(req.ID, nil, nil)
 				// Expected to never fail.
 				mresp, err := json.Marshal(resp)
@@ -459,6 +595,19 @@ out:
 				break out
 			}
 
+			if jsonErr := s.checkPolicy(wsc.username, &req); jsonErr != nil {
+				mresp, err := btcjson.MarshalResponse(req.ID, nil, jsonErr)
+				// Expected to never fail.
+				if err != nil {
+					panic(err)
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+				continue
+			}
+
 			switch req.Method {
 			case "stop":
 				resp := makeResponse(req.ID,
@@ -477,10 +626,12 @@ out:
 
 			default:
 				req := req // Copy for the closure
-				f := s.handlerClosure(&req)
+				f := s.handlerClosure(&req, "")
 				wsc.wg.Add(1)
 				go func() {
+					start := time.Now()
 					resp, jsonErr := f()
+					s.observeCall(&req, start, jsonErr)
 					mresp, err := btcjson.MarshalResponse(req.ID, resp, jsonErr)
 					if err != nil {
 						log.Errorf("Unable to marshal response: %v", err)
@@ -562,8 +713,9 @@ func (s *Server) websocketClientRPC(wsc *websocketClient) {
 // that may be read from a client.  This is currently limited to 4MB.
 const maxRequestSize = 1024 * 1024 * 4
 
-// postClientRPC processes and replies to a JSON-RPC client request.
-func (s *Server) postClientRPC(w http.ResponseWriter, r *http.Request) {
+// postClientRPC processes and replies to a JSON-RPC client request of the
+// authenticated user.
+func (s *Server) postClientRPC(w http.ResponseWriter, r *http.Request, username string) {
 	body := http.MaxBytesReader(w, r.Body, maxRequestSize)
 	rpcRequest, err := ioutil.ReadAll(body)
 	if err != nil {
@@ -596,19 +748,25 @@ func (s *Server) postClientRPC(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the response and error from the request.  Two special cases
-	// are handled for the authenticate and stop request methods.
-	var res interface{}
-	var jsonErr *btcjson.RPCError
-	var stop bool
-	switch req.Method {
-	case "authenticate":
+	// are handled for the authenticate and stop request methods, once the
+	// request is allowed by the RPC policy.
+	if req.Method == "authenticate" {
 		// Drop it.
 		return
-	case "stop":
+	}
+	var res interface{}
+	var stop bool
+	jsonErr := s.checkPolicy(username, &req)
+	switch {
+	case jsonErr != nil:
+		// Rejected by the RPC policy.
+	case req.Method == "stop":
 		stop = true
 		res = "btcwallet stopping"
 	default:
-		res, jsonErr = s.handlerClosure(&req)()
+		start := time.Now()
+		res, jsonErr = s.handlerClosure(&req, requestWalletName(r))()
+		s.observeCall(&req, start, jsonErr)
 	}
 
 	// Marshal and send.
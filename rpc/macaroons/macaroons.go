@@ -0,0 +1,302 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package macaroons implements macaroon authentication for the gRPC server.
+//
+// Macaroons are bearer credentials with caveats restricting their use.  Every
+// macaroon minted by a Service carries a permission caveat naming the methods
+// it may call.  Holders of a macaroon may attenuate it by adding further
+// permission caveats without contacting the wallet, and since every caveat must
+// be satisfied, the attenuated macaroon only permits the methods allowed by all
+// of them.  Clients send a macaroon with each call as hex in the "macaroon"
+// metadata.
+package macaroons
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/macaroon.v2"
+)
+
+const (
+	// rootKeyLen is the length of the key verifying all macaroons.
+	rootKeyLen = 32
+
+	// location is the location hint of minted macaroons.
+	location = "btcwallet"
+
+	// permissionCaveat prefixes the first party caveats restricting the
+	// permission of a macaroon.
+	permissionCaveat = "permission "
+
+	// MetadataKey is the key of the gRPC metadata holding the hex encoded
+	// macaroon of a call.
+	MetadataKey = "macaroon"
+)
+
+// errNoPermission is returned when verifying a macaroon without any permission
+// caveat, which would otherwise permit every method.
+var errNoPermission = errors.New("macaroon has no permission caveat")
+
+// Files holds the names of the macaroon files written by WriteFiles, keyed by
+// their permission.
+var Files = map[Permission]string{
+	ReadOnly: "readonly.macaroon",
+	Invoice:  "invoice.macaroon",
+	Full:     "admin.macaroon",
+}
+
+// Service mints and verifies macaroons with a root key.
+type Service struct {
+	rootKey []byte
+}
+
+// NewService returns a Service using the root key stored in the file
+// keyFile.  If the file does not exist, a new random root key is generated and
+// written to it.  Removing the file revokes every macaroon minted with the
+// key.
+func NewService(keyFile string) (*Service, error) {
+	rootKey, err := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		rootKey = make([]byte, rootKeyLen)
+		if _, err := rand.Read(rootKey); err != nil {
+			return nil, err
+		}
+		err = os.MkdirAll(filepath.Dir(keyFile), 0700)
+		if err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(keyFile, rootKey, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rootKey) != rootKeyLen {
+		return nil, fmt.Errorf("macaroon root key file %v has invalid "+
+			"length %d", keyFile, len(rootKey))
+	}
+	return &Service{rootKey: rootKey}, nil
+}
+
+// Mint returns a new macaroon with the permission perm.
+func (s *Service) Mint(perm Permission) (*macaroon.Macaroon, error) {
+	if !perm.valid() {
+		return nil, fmt.Errorf("unknown permission %q", perm)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	m, err := macaroon.New(s.rootKey, id, location, macaroon.LatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	return Attenuate(m, perm)
+}
+
+// Attenuate returns a copy of m which is further restricted to the methods
+// allowed by perm.  It does not require the root key.
+func Attenuate(m *macaroon.Macaroon, perm Permission) (*macaroon.Macaroon, error) {
+	if !perm.valid() {
+		return nil, fmt.Errorf("unknown permission %q", perm)
+	}
+	m = m.Clone()
+	err := m.AddFirstPartyCaveat([]byte(permissionCaveat + string(perm)))
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Verify checks that m was minted by the service and that each of its caveats
+// allows calling the gRPC method with the full method name method.
+func (s *Service) Verify(m *macaroon.Macaroon, method string) error {
+	_, err := s.verify(m, method)
+	return err
+}
+
+// verify checks m like Verify, and returns the permission of its first
+// permission caveat, which is the permission it was minted with.
+func (s *Service) verify(m *macaroon.Macaroon, method string) (Permission, error) {
+	var minted Permission
+	perms := 0
+	check := func(caveat string) error {
+		if !strings.HasPrefix(caveat, permissionCaveat) {
+			return fmt.Errorf("unknown caveat %q", caveat)
+		}
+		perm := Permission(strings.TrimPrefix(caveat, permissionCaveat))
+		if !perm.valid() {
+			return fmt.Errorf("unknown permission %q", perm)
+		}
+		if !perm.Allows(method) {
+			return fmt.Errorf("permission %q does not allow %v", perm,
+				method)
+		}
+		if perms == 0 {
+			minted = perm
+		}
+		perms++
+		return nil
+	}
+	if err := m.Verify(s.rootKey, check, nil); err != nil {
+		return "", err
+	}
+	if perms == 0 {
+		return "", errNoPermission
+	}
+	return minted, nil
+}
+
+// WriteFiles mints a macaroon of every permission and writes it to the file
+// named by Files in the directory dir.  Existing files are not replaced.
+func (s *Service) WriteFiles(dir string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	for _, perm := range Permissions {
+		path := filepath.Join(dir, Files[perm])
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			continue
+		}
+		m, err := s.Mint(perm)
+		if err != nil {
+			return err
+		}
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, b, 0600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permissionKey is the context key of the permission a macaroon of a call was
+// minted with.
+type permissionKey struct{}
+
+// PermissionFromContext returns the permission the macaroon of an authorized
+// call was minted with, which identifies the macaroon file it was read from or
+// attenuated from.
+func PermissionFromContext(ctx context.Context) (Permission, bool) {
+	perm, ok := ctx.Value(permissionKey{}).(Permission)
+	return perm, ok
+}
+
+// authorize returns a gRPC error unless the context of a call carries a
+// macaroon allowing the call of method, and otherwise returns the context
+// holding the permission the macaroon was minted with.
+func (s *Service) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[MetadataKey]) != 1 {
+		return nil, grpc.Errorf(codes.Unauthenticated,
+			"expected one macaroon in metadata")
+	}
+	b, err := hex.DecodeString(md[MetadataKey][0])
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "macaroon: %v", err)
+	}
+	m := new(macaroon.Macaroon)
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "macaroon: %v", err)
+	}
+	perm, err := s.verify(m, method)
+	if err != nil {
+		return nil, grpc.Errorf(codes.PermissionDenied, "macaroon: %v", err)
+	}
+	return context.WithValue(ctx, permissionKey{}, perm), nil
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor rejecting unary calls
+// without a macaroon allowing the call.
+func (s *Service) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := s.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// serverStream is a server stream with the context returned by authorize.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// StreamServerInterceptor returns a gRPC interceptor rejecting streaming calls
+// without a macaroon allowing the call.
+func (s *Service) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := s.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Credential is a gRPC credential sending a macaroon with every call.
+type Credential struct {
+	m *macaroon.Macaroon
+}
+
+// NewCredential returns a Credential sending m.
+func NewCredential(m *macaroon.Macaroon) Credential {
+	return Credential{m: m}
+}
+
+// ReadCredential returns a Credential sending the macaroon read from the file
+// path, as written by WriteFiles.
+func ReadCredential(path string) (Credential, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Credential{}, err
+	}
+	m := new(macaroon.Macaroon)
+	if err := m.UnmarshalBinary(b); err != nil {
+		return Credential{}, err
+	}
+	return NewCredential(m), nil
+}
+
+// GetRequestMetadata implements the credentials.PerRPCCredentials interface.
+func (c Credential) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	b, err := c.m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{MetadataKey: hex.EncodeToString(b)}, nil
+}
+
+// RequireTransportSecurity implements the credentials.PerRPCCredentials
+// interface.  Macaroons are bearer credentials and are only sent over TLS.
+func (c Credential) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package macaroons
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testService(t *testing.T) (*Service, string) {
+	dir, err := ioutil.TempDir("", "macaroons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(filepath.Join(dir, "macaroons.key"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+func TestVerify(t *testing.T) {
+	s, dir := testService(t)
+	defer os.RemoveAll(dir)
+
+	const (
+		balance     = "/walletrpc.WalletService/Balance"
+		nextAddress = "/walletrpc.WalletService/NextAddress"
+		sendOutputs = "/walletrpc.WalletService/SendOutputs"
+	)
+	tests := []struct {
+		perm    Permission
+		method  string
+		allowed bool
+	}{
+		{ReadOnly, balance, true},
+		{ReadOnly, nextAddress, false},
+		{ReadOnly, sendOutputs, false},
+		{Invoice, balance, false},
+		{Invoice, nextAddress, true},
+		{Invoice, sendOutputs, false},
+		{Full, balance, true},
+		{Full, nextAddress, true},
+		{Full, sendOutputs, true},
+	}
+	for _, test := range tests {
+		m, err := s.Mint(test.perm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.Verify(m, test.method)
+		if test.allowed && err != nil {
+			t.Errorf("%v macaroon denied %v: %v", test.perm, test.method, err)
+		}
+		if !test.allowed && err == nil {
+			t.Errorf("%v macaroon allowed %v", test.perm, test.method)
+		}
+	}
+}
+
+func TestAttenuate(t *testing.T) {
+	s, dir := testService(t)
+	defer os.RemoveAll(dir)
+
+	full, err := s.Mint(Full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly, err := Attenuate(full, ReadOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Verify(readOnly, "/walletrpc.WalletService/Balance"); err != nil {
+		t.Errorf("attenuated macaroon denied read: %v", err)
+	}
+	if err := s.Verify(readOnly, "/walletrpc.WalletService/DumpPrivateKey"); err == nil {
+		t.Error("attenuated macaroon allowed key export")
+	}
+	if err := s.Verify(full, "/walletrpc.WalletService/DumpPrivateKey"); err != nil {
+		t.Errorf("attenuation modified the original macaroon: %v", err)
+	}
+
+	// Permissions only ever narrow: no method is allowed by both the read
+	// only and invoice permissions that is not allowed by each.
+	both, err := Attenuate(readOnly, Invoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Verify(both, "/walletrpc.WalletService/NextAddress"); err == nil {
+		t.Error("attenuated macaroon allowed a method denied by a caveat")
+	}
+	if err := s.Verify(both, "/walletrpc.WalletService/Network"); err != nil {
+		t.Errorf("attenuated macaroon denied a method allowed by every caveat: %v", err)
+	}
+
+	// Attenuated macaroons keep the permission they were minted with.
+	perm, err := s.verify(both, "/walletrpc.WalletService/Network")
+	if err != nil || perm != Full {
+		t.Errorf("expected minted permission %v, got %v (err=%v)", Full,
+			perm, err)
+	}
+}
+
+func TestRootKey(t *testing.T) {
+	s, dir := testService(t)
+	defer os.RemoveAll(dir)
+
+	m, err := s.Mint(Full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The persisted root key verifies previously minted macaroons.
+	reopened, err := NewService(filepath.Join(dir, "macaroons.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Verify(m, "/walletrpc.WalletService/Balance"); err != nil {
+		t.Errorf("reopened service denied macaroon: %v", err)
+	}
+
+	// Other root keys do not.
+	other, otherDir := testService(t)
+	defer os.RemoveAll(otherDir)
+	if err := other.Verify(m, "/walletrpc.WalletService/Balance"); err == nil {
+		t.Error("macaroon verified with another root key")
+	}
+}
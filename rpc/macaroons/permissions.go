@@ -0,0 +1,95 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package macaroons
+
+// Permission describes the RPC methods a macaroon is authorized to call.
+type Permission string
+
+// Permissions granted by the macaroons minted by a Service.
+const (
+	// ReadOnly permits methods which query the wallet or stream
+	// notifications.  These do not modify the wallet or reveal secrets.
+	ReadOnly Permission = "readonly"
+
+	// Invoice permits methods required to request and watch for payments
+	// to the wallet, which includes creating new addresses.
+	Invoice Permission = "invoice"
+
+	// Full permits every method, including spending and key export.
+	Full Permission = "full"
+)
+
+// Permissions lists every permission in order of increasing capability.
+var Permissions = []Permission{ReadOnly, Invoice, Full}
+
+// Full gRPC method names of the services served by the RPC server.
+const (
+	versionService = "/walletrpc.VersionService/"
+	walletService  = "/walletrpc.WalletService/"
+	loaderService  = "/walletrpc.WalletLoaderService/"
+)
+
+var readOnlyMethods = map[string]struct{}{
+	versionService + "Version": {},
+
+	walletService + "Ping":                        {},
+	walletService + "Network":                     {},
+	walletService + "AccountNumber":               {},
+	walletService + "Accounts":                    {},
+	walletService + "Balance":                     {},
+	walletService + "GetTransactions":             {},
+	walletService + "GetRecoveryInfo":             {},
+	walletService + "GetTransaction":              {},
+	walletService + "ListLockedOutputs":           {},
+	walletService + "ValidateAddress":             {},
+	walletService + "VerifyMessage":               {},
+	walletService + "TransactionNotifications":    {},
+	walletService + "SpentnessNotifications":      {},
+	walletService + "AccountNotifications":        {},
+	walletService + "RescanNotifications":         {},
+	walletService + "SubscribeSyncState":          {},
+	walletService + "AccountBalanceNotifications": {},
+
+	loaderService + "WalletExists": {},
+	loaderService + "ListWallets":  {},
+}
+
+var invoiceMethods = map[string]struct{}{
+	versionService + "Version": {},
+
+	walletService + "Ping":                     {},
+	walletService + "Network":                  {},
+	walletService + "NextAddress":              {},
+	walletService + "ValidateAddress":          {},
+	walletService + "GetTransaction":           {},
+	walletService + "TransactionNotifications": {},
+}
+
+// Allows returns whether the permission authorizes calling the gRPC method
+// with the full method name method, such as
+// "/walletrpc.WalletService/Balance".  Methods unknown to the permission,
+// including all methods added after it was defined, are only permitted by Full.
+func (p Permission) Allows(method string) bool {
+	var ok bool
+	switch p {
+	case ReadOnly:
+		_, ok = readOnlyMethods[method]
+	case Invoice:
+		_, ok = invoiceMethods[method]
+	case Full:
+		ok = true
+	}
+	return ok
+}
+
+// valid returns whether p is a known permission.
+func (p Permission) valid() bool {
+	for _, perm := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
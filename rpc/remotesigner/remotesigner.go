@@ -0,0 +1,64 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package remotesigner implements a wallet.Signer which delegates signing to
+// the SignerService of a remote wallet, so that a watching-only wallet can sign
+// transactions with the keys of a wallet running on another machine.
+package remotesigner
+
+import (
+	"bytes"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/btcsuite/btcd/wire"
+	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
+	"github.com/btcsuite/btcwallet/wallet"
+)
+
+// callTimeout is the time a remote signer has to respond to a call, so that an
+// unresponsive signer does not block the wallet indefinitely while it holds
+// locks for transaction creation.
+const callTimeout = 30 * time.Second
+
+// Signer is a wallet.Signer calling the SignerService of a remote wallet.
+type Signer struct {
+	client pb.SignerServiceClient
+}
+
+// New returns a Signer calling the SignerService of the RPC server of conn.
+func New(conn *grpc.ClientConn) *Signer {
+	return &Signer{client: pb.NewSignerServiceClient(conn)}
+}
+
+// SignOutputRaw implements the wallet.Signer interface.
+func (s *Signer) SignOutputRaw(tx *wire.MsgTx,
+	desc *wallet.SignDescriptor) ([]byte, error) {
+
+	var buf bytes.Buffer
+	buf.Grow(tx.SerializeSize())
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := s.client.SignOutputRaw(ctx,
+		&pb.SignOutputRawRequest{
+			Transaction: buf.Bytes(),
+			KeyPath:     desc.KeyPath,
+			PublicKey:   desc.PubKey,
+			SubScript:   desc.SubScript,
+			Amount:      desc.Amount,
+			HashType:    uint32(desc.HashType),
+			InputIndex:  uint32(desc.InputIndex),
+			Witness:     desc.Witness,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
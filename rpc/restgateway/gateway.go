@@ -0,0 +1,257 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package restgateway implements a REST reverse proxy for the WalletService of
+// the gRPC server, for clients which can not use gRPC.
+//
+// Each WalletService method is served at /v1/<method>, such as /v1/Balance.
+// Methods are called with POST requests.  Only the methods permitted by
+// read-only macaroons, which do not modify the wallet, may also be called with
+// GET requests, so that links can not trigger sends or other changes.
+// Requests are the JSON encoding of the method's request message, using the
+// field names of the .proto file, and may be empty to send the default request.
+// Unary methods respond with the JSON encoding of the response message.
+// Streaming methods respond with newline-delimited JSON objects, each holding a
+// response message in the "result" field, until the stream ends or an error
+// object is sent.  Errors are JSON objects with the gRPC error code and message
+// in the "code" and "error" fields.
+//
+// Request headers prefixed with Grpc-Metadata- are forwarded as gRPC metadata,
+// such as Grpc-Metadata-Macaroon for servers requiring macaroons, and
+// Grpc-Metadata-Wallet to call a named wallet.
+package restgateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/btcsuite/btcwallet/rpc/macaroons"
+	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
+)
+
+const (
+	// pathPrefix prefixes the path of every method.
+	pathPrefix = "/v1/"
+
+	// metadataHeaderPrefix prefixes the headers forwarded as gRPC
+	// metadata.
+	metadataHeaderPrefix = "Grpc-Metadata-"
+
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 1 << 20
+
+	// walletService prefixes the full gRPC method names of the methods.
+	walletService = "/walletrpc.WalletService/"
+)
+
+// method describes a method of the WalletService client.
+type method struct {
+	call    reflect.Value // bound client method
+	reqType reflect.Type  // request message type, not a pointer
+	stream  bool          // whether the method is server streaming
+	get     bool          // whether the method may be called with GET
+}
+
+// Gateway is an http.Handler proxying REST requests to a WalletService
+// client.
+type Gateway struct {
+	methods   map[string]method
+	marshaler jsonpb.Marshaler
+}
+
+// New returns a Gateway proxying requests to client, which is usually created
+// with walletrpc.NewWalletServiceClient for a connection to the gRPC server.
+func New(client pb.WalletServiceClient) *Gateway {
+	v := reflect.ValueOf(client)
+	t := reflect.TypeOf((*pb.WalletServiceClient)(nil)).Elem()
+	methods := make(map[string]method, t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		// Every method has the signature
+		// func(context.Context, *Request, ...grpc.CallOption) (R, error)
+		// where R is the response message for unary methods, and a
+		// stream client with a Recv method for streaming methods.
+		m := t.Method(i)
+		if m.Type.NumIn() != 3 || m.Type.NumOut() != 2 ||
+			m.Type.In(1).Kind() != reflect.Ptr {
+			continue
+		}
+		methods[m.Name] = method{
+			call:    v.MethodByName(m.Name),
+			reqType: m.Type.In(1).Elem(),
+			stream:  m.Type.Out(0).Kind() == reflect.Interface,
+			get:     macaroons.ReadOnly.Allows(walletService + m.Name),
+		}
+	}
+	return &Gateway{
+		methods:   methods,
+		marshaler: jsonpb.Marshaler{OrigName: true, EmitDefaults: true},
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, codes.Unimplemented,
+			"method must be GET or POST")
+		return
+	}
+	m, ok := g.methods[strings.TrimPrefix(r.URL.Path, pathPrefix)]
+	if !ok || !strings.HasPrefix(r.URL.Path, pathPrefix) {
+		writeError(w, http.StatusNotFound, codes.Unimplemented,
+			"unknown method "+r.URL.Path)
+		return
+	}
+	if r.Method == http.MethodGet && !m.get {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, codes.Unimplemented,
+			"method "+r.URL.Path+" must be POST")
+		return
+	}
+
+	req := reflect.New(m.reqType)
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codes.InvalidArgument,
+			err.Error())
+		return
+	}
+	if len(bytes.TrimSpace(body)) != 0 {
+		err = jsonpb.Unmarshal(bytes.NewReader(body), req.Interface().(proto.Message))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codes.InvalidArgument,
+				err.Error())
+			return
+		}
+	}
+
+	// The request context is canceled when the client disconnects, which
+	// also ends streams.
+	ctx := outgoingContext(r)
+	out := m.call.Call([]reflect.Value{reflect.ValueOf(ctx), req})
+	if err, _ := out[1].Interface().(error); err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !m.stream {
+		err = g.marshaler.Marshal(w, out[0].Interface().(proto.Message))
+		if err != nil {
+			log.Errorf("Cannot marshal %v response: %v", r.URL.Path, err)
+		}
+		return
+	}
+	g.serveStream(w, out[0].MethodByName("Recv"))
+}
+
+// serveStream writes each message received by the Recv method of a stream
+// client until the stream ends.
+func (g *Gateway) serveStream(w http.ResponseWriter, recv reflect.Value) {
+	flusher, _ := w.(http.Flusher)
+	for {
+		out := recv.Call(nil)
+		if err, _ := out[1].Interface().(error); err != nil {
+			if err != io.EOF {
+				writeStreamError(w, err)
+			}
+			return
+		}
+		var buf bytes.Buffer
+		buf.WriteString(`{"result":`)
+		err := g.marshaler.Marshal(&buf, out[0].Interface().(proto.Message))
+		if err != nil {
+			log.Errorf("Cannot marshal stream response: %v", err)
+			return
+		}
+		buf.WriteString("}\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// outgoingContext returns the context of calls proxying r, holding the gRPC
+// metadata of its Grpc-Metadata- headers.
+func outgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for name, values := range r.Header {
+		if !strings.HasPrefix(name, metadataHeaderPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, metadataHeaderPrefix))
+		md[key] = append(md[key], values...)
+	}
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// errorResponse is the JSON encoding of errors.
+type errorResponse struct {
+	Error string     `json:"error"`
+	Code  codes.Code `json:"code"`
+}
+
+func writeError(w http.ResponseWriter, status int, code codes.Code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&errorResponse{Error: msg, Code: code})
+}
+
+func writeGRPCError(w http.ResponseWriter, err error) {
+	code := grpc.Code(err)
+	writeError(w, httpStatus(code), code, grpc.ErrorDesc(err))
+}
+
+// writeStreamError writes an error ending a stream after its response status
+// was sent.
+func writeStreamError(w http.ResponseWriter, err error) {
+	json.NewEncoder(w).Encode(&errorResponse{
+		Error: grpc.ErrorDesc(err),
+		Code:  grpc.Code(err),
+	})
+}
+
+// httpStatus returns the HTTP status of responses with gRPC errors of a code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return http.StatusRequestTimeout
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
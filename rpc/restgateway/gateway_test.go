@@ -0,0 +1,136 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package restgateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
+)
+
+// mockClient implements the Balance method of a WalletService client.
+type mockClient struct {
+	pb.WalletServiceClient
+
+	macaroon []string
+}
+
+func (c *mockClient) Balance(ctx context.Context, req *pb.BalanceRequest,
+	opts ...grpc.CallOption) (*pb.BalanceResponse, error) {
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	c.macaroon = md["macaroon"]
+	if req.AccountNumber != 0 {
+		return nil, grpc.Errorf(codes.NotFound, "account not found")
+	}
+	return &pb.BalanceResponse{
+		Total:     5000,
+		Spendable: int64(req.RequiredConfirmations),
+	}, nil
+}
+
+func TestGateway(t *testing.T) {
+	client := &mockClient{}
+	server := httptest.NewServer(New(client))
+	defer server.Close()
+
+	post := func(path, body string, header http.Header) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest("POST", server.URL+path,
+			strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var v map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		return resp, v
+	}
+
+	header := http.Header{"Grpc-Metadata-Macaroon": {"abcd"}}
+	resp, v := post("/v1/Balance", `{"required_confirmations": 6}`, header)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if v["total"] != "5000" || v["spendable"] != "6" {
+		t.Errorf("unexpected response %v", v)
+	}
+	if len(client.macaroon) != 1 || client.macaroon[0] != "abcd" {
+		t.Errorf("expected macaroon metadata abcd, got %v", client.macaroon)
+	}
+
+	// Errors of the gRPC method are mapped to HTTP statuses.
+	resp, v = post("/v1/Balance", `{"account_number": 1}`, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound,
+			resp.StatusCode)
+	}
+	if v["code"] != float64(codes.NotFound) {
+		t.Errorf("expected code %d, got %v", codes.NotFound, v["code"])
+	}
+
+	resp, _ = post("/v1/Balance", `{"account_number": "x"}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest,
+			resp.StatusCode)
+	}
+
+	resp, _ = post("/v1/Unknown", ``, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound,
+			resp.StatusCode)
+	}
+}
+
+// TestGatewayGet ensures that only read-only methods may be called with GET
+// requests.
+func TestGatewayGet(t *testing.T) {
+	server := httptest.NewServer(New(&mockClient{}))
+	defer server.Close()
+
+	tests := []struct {
+		method string
+		status int
+	}{
+		{"Balance", http.StatusOK},
+		{"SendOutputs", http.StatusMethodNotAllowed},
+		{"LockOutputs", http.StatusMethodNotAllowed},
+		{"SignMessage", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		resp, err := http.Get(server.URL + "/v1/" + test.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("GET %s: expected status %d, got %d",
+				test.method, test.status, resp.StatusCode)
+		}
+		if test.status == http.StatusMethodNotAllowed &&
+			resp.Header.Get("Allow") != "POST" {
+
+			t.Errorf("GET %s: expected POST to be allowed, got %q",
+				test.method, resp.Header.Get("Allow"))
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package rpcpolicy implements policies restricting the RPC methods each
+// credential of an RPC server may call.
+//
+// A policy is made of rules which either disable a method for a credential or
+// limit the rate at which the credential may call it.  Rules of the credential
+// "*" apply to every credential, and rate limits of such rules are tracked
+// separately for each credential.  Method names are compared case
+// insensitively.
+package rpcpolicy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// AnyCredential is the credential of rules applying to every credential.
+const AnyCredential = "*"
+
+var (
+	// ErrDisabled describes the error condition of calling a method which
+	// is disabled for the credential.
+	ErrDisabled = errors.New("method is disabled by the RPC policy")
+
+	// ErrRateLimited describes the error condition of calling a method
+	// more often than the rate limit of the credential allows.
+	ErrRateLimited = errors.New("method call rate exceeds the RPC policy " +
+		"limit")
+)
+
+// Rule restricts calls of a method with a credential.  The method is disabled
+// when Disable is set, and may otherwise be called at most Calls times during
+// each Interval.
+type Rule struct {
+	Credential string
+	Method     string
+	Disable    bool
+	Calls      int
+	Interval   time.Duration
+}
+
+// ParseRule parses a rule in the form credential:method:disable, which
+// disables the method, or credential:method:calls/interval, which limits the
+// rate of calls, such as "*:listtransactions:10/1m".  The credential may not
+// contain colons, and the interval is parsed with time.ParseDuration.
+func ParseRule(s string) (Rule, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return Rule{}, fmt.Errorf("RPC policy rule %q is not in the form "+
+			"credential:method:disable or credential:method:calls/interval", s)
+	}
+	r := Rule{Credential: parts[0], Method: parts[1]}
+	if parts[2] == "disable" {
+		r.Disable = true
+		return r, nil
+	}
+	i := strings.IndexByte(parts[2], '/')
+	if i == -1 {
+		return Rule{}, fmt.Errorf("RPC policy rule %q has unknown "+
+			"restriction %q", s, parts[2])
+	}
+	calls, err := strconv.Atoi(parts[2][:i])
+	if err != nil || calls <= 0 {
+		return Rule{}, fmt.Errorf("RPC policy rule %q has invalid call "+
+			"count %q", s, parts[2][:i])
+	}
+	interval, err := time.ParseDuration(parts[2][i+1:])
+	if err != nil || interval <= 0 {
+		return Rule{}, fmt.Errorf("RPC policy rule %q has invalid "+
+			"interval %q", s, parts[2][i+1:])
+	}
+	r.Calls = calls
+	r.Interval = interval
+	return r, nil
+}
+
+// String returns the rule in the form parsed by ParseRule.
+func (r Rule) String() string {
+	if r.Disable {
+		return r.Credential + ":" + r.Method + ":disable"
+	}
+	return fmt.Sprintf("%s:%s:%d/%v", r.Credential, r.Method, r.Calls,
+		r.Interval)
+}
+
+// bucket is a token bucket tracking the rate of calls of a credential limited
+// by a rule.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketKey identifies the bucket of a rule and a credential.
+type bucketKey struct {
+	rule       int
+	credential string
+}
+
+// Policy checks calls of RPC methods against a set of rules.  A nil Policy
+// allows every call.
+//
+// Policy is safe for concurrent access.
+type Policy struct {
+	rules   []Rule
+	byName  map[string][]int // rule indexes keyed by lowercase method
+	buckets map[bucketKey]*bucket
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// New returns a Policy checking calls against rules.
+func New(rules []Rule) *Policy {
+	p := &Policy{
+		rules:   rules,
+		byName:  make(map[string][]int),
+		buckets: make(map[bucketKey]*bucket),
+		now:     time.Now,
+	}
+	for i, r := range rules {
+		method := strings.ToLower(r.Method)
+		p.byName[method] = append(p.byName[method], i)
+	}
+	return p
+}
+
+// Parse returns a Policy of the rules parsed by ParseRule, or nil when there
+// are no rules.
+func Parse(rules []string) (*Policy, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	parsed := make([]Rule, 0, len(rules))
+	for _, s := range rules {
+		r, err := ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return New(parsed), nil
+}
+
+// Check returns ErrDisabled if a rule disables the method for the credential,
+// or ErrRateLimited if calling it now would exceed the rate limit of a rule.
+// Calls which are allowed count towards the rate limits of the credential.
+func (p *Policy) Check(credential, method string) error {
+	if p == nil {
+		return nil
+	}
+	indexes := p.byName[strings.ToLower(method)]
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	buckets := make([]*bucket, 0, len(indexes))
+	for _, i := range indexes {
+		r := &p.rules[i]
+		if r.Credential != credential && r.Credential != AnyCredential {
+			continue
+		}
+		if r.Disable {
+			return ErrDisabled
+		}
+
+		// Refill the bucket at the rate of the rule, holding at most
+		// the calls allowed during one interval.
+		key := bucketKey{rule: i, credential: credential}
+		b := p.buckets[key]
+		if b == nil {
+			b = &bucket{tokens: float64(r.Calls), last: now}
+			p.buckets[key] = b
+		}
+		elapsed := now.Sub(b.last)
+		if elapsed > 0 {
+			b.tokens += float64(r.Calls) * float64(elapsed) /
+				float64(r.Interval)
+			if b.tokens > float64(r.Calls) {
+				b.tokens = float64(r.Calls)
+			}
+			b.last = now
+		}
+		if b.tokens < 1 {
+			return ErrRateLimited
+		}
+		buckets = append(buckets, b)
+	}
+
+	// The call is only counted once it is allowed by every rule.
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return nil
+}
+
+// grpcError returns the gRPC error of a call rejected by Check.
+func grpcError(err error) error {
+	code := codes.PermissionDenied
+	if err == ErrRateLimited {
+		code = codes.ResourceExhausted
+	}
+	return grpc.Errorf(code, "%v", err)
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor rejecting unary calls not
+// allowed by the policy for the credential returned by credential for the call
+// context.  Rules name gRPC methods without their service, such as
+// "DumpPrivateKey".
+func (p *Policy) UnaryServerInterceptor(credential func(context.Context) string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		err := p.Check(credential(ctx), path.Base(info.FullMethod))
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor rejecting streaming calls
+// not allowed by the policy for the credential returned by credential for the
+// stream context.
+func (p *Policy) StreamServerInterceptor(credential func(context.Context) string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		err := p.Check(credential(ss.Context()), path.Base(info.FullMethod))
+		if err != nil {
+			return grpcError(err)
+		}
+		return handler(srv, ss)
+	}
+}
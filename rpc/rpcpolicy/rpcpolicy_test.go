@@ -0,0 +1,99 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcpolicy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		valid bool
+		want  Rule
+	}{
+		{"alice:dumpprivkey:disable", true,
+			Rule{Credential: "alice", Method: "dumpprivkey", Disable: true}},
+		{"*:listtransactions:10/1m0s", true,
+			Rule{Credential: "*", Method: "listtransactions", Calls: 10,
+				Interval: time.Minute}},
+		{"alice:dumpprivkey", false, Rule{}},
+		{":dumpprivkey:disable", false, Rule{}},
+		{"alice::disable", false, Rule{}},
+		{"alice:dumpprivkey:deny", false, Rule{}},
+		{"alice:listtransactions:0/1m", false, Rule{}},
+		{"alice:listtransactions:10/0s", false, Rule{}},
+		{"alice:listtransactions:10/minute", false, Rule{}},
+	}
+	for _, test := range tests {
+		r, err := ParseRule(test.rule)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("rule %q: expected error", test.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("rule %q: %v", test.rule, err)
+			continue
+		}
+		if r != test.want {
+			t.Errorf("rule %q: parsed %+v, want %+v", test.rule, r,
+				test.want)
+		}
+		if r.String() != test.rule {
+			t.Errorf("rule %q: formatted as %q", test.rule, r.String())
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := Parse([]string{
+		"alice:dumpprivkey:disable",
+		"*:ListTransactions:2/1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	if err := p.Check("alice", "DumpPrivKey"); err != ErrDisabled {
+		t.Errorf("expected ErrDisabled, got %v", err)
+	}
+	if err := p.Check("bob", "dumpprivkey"); err != nil {
+		t.Errorf("method disabled for another credential: %v", err)
+	}
+
+	// Each credential is limited separately.
+	for _, credential := range []string{"alice", "bob"} {
+		for i := 0; i < 2; i++ {
+			err := p.Check(credential, "listtransactions")
+			if err != nil {
+				t.Fatalf("%v call %d: %v", credential, i, err)
+			}
+		}
+		err := p.Check(credential, "listtransactions")
+		if err != ErrRateLimited {
+			t.Errorf("%v: expected ErrRateLimited, got %v", credential,
+				err)
+		}
+	}
+
+	// Calls are allowed again at the rate of the rule.
+	now = now.Add(30 * time.Second)
+	if err := p.Check("alice", "listtransactions"); err != nil {
+		t.Errorf("call after refill: %v", err)
+	}
+	if err := p.Check("alice", "listtransactions"); err != ErrRateLimited {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+
+	var nilPolicy *Policy
+	if err := nilPolicy.Check("alice", "dumpprivkey"); err != nil {
+		t.Errorf("nil policy rejected call: %v", err)
+	}
+}
@@ -16,15 +16,19 @@
 package rpcserver
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/txscript"
@@ -35,20 +39,27 @@ import (
 	"github.com/btcsuite/btcwallet/internal/cfgutil"
 	"github.com/btcsuite/btcwallet/internal/zero"
 	"github.com/btcsuite/btcwallet/netparams"
+	"github.com/btcsuite/btcwallet/rpc/macaroons"
 	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
 	"github.com/btcsuite/btcwallet/walletdb"
 )
 
 // Public API version constants
 const (
-	semverString = "2.0.1"
+	semverString = "2.13.0"
 	semverMajor  = 2
-	semverMinor  = 0
-	semverPatch  = 1
+	semverMinor  = 13
+	semverPatch  = 0
 )
 
+// WalletMetadataKey is the key of the gRPC metadata naming the wallet a
+// WalletService call is served by.  Calls without the metadata are served by
+// the default wallet.
+const WalletMetadataKey = "wallet"
+
 // translateError creates a new gRPC error with an appropiate error code for
 // recognized errors.
 //
@@ -76,14 +87,31 @@ func errorCode(err error) codes.Code {
 			return codes.InvalidArgument
 		case waddrmgr.ErrDuplicateAccount:
 			return codes.AlreadyExists
+		case waddrmgr.ErrLocked:
+			return codes.FailedPrecondition
+		case waddrmgr.ErrAddressNotFound:
+			return codes.NotFound
 		}
 
 		err = e.Err
 	}
 
+	if _, ok := err.(*wallet.TxRejectedError); ok {
+		return codes.FailedPrecondition
+	}
+	if e, ok := err.(*chain.PublishError); ok {
+		err = e.Err
+	}
+
 	switch err {
 	case wallet.ErrLoaded:
 		return codes.FailedPrecondition
+	case wallet.ErrExists:
+		return codes.AlreadyExists
+	case wallet.ErrInvalidWalletName:
+		return codes.InvalidArgument
+	case wallet.ErrNamedWalletsUnsupported:
+		return codes.Unimplemented
 	case walletdb.ErrDbNotOpen:
 		return codes.Aborted
 	case walletdb.ErrDbExists:
@@ -92,6 +120,16 @@ func errorCode(err error) codes.Code {
 		return codes.NotFound
 	case hdkeychain.ErrInvalidSeedLen:
 		return codes.InvalidArgument
+	case wallet.ErrSignerKeyMismatch:
+		return codes.InvalidArgument
+	case wallet.ErrTransactionNotFound:
+		return codes.NotFound
+	case txrules.ErrAmountNegative, txrules.ErrAmountExceedsMax,
+		txrules.ErrOutputIsDust:
+		return codes.InvalidArgument
+	case chain.ErrDoubleSpend, chain.ErrMempoolMinFee,
+		chain.ErrMissingInputs, chain.ErrNonStandard:
+		return codes.FailedPrecondition
 	default:
 		return codes.Unknown
 	}
@@ -104,18 +142,33 @@ type versionServer struct {
 
 // walletServer provides wallet services for RPC clients.
 type walletServer struct {
-	wallet *wallet.Wallet
+	loader      *wallet.Loader
+	multiLoader *wallet.MultiLoader
+}
+
+// signerServer signs transaction inputs for remote watching-only wallets.
+type signerServer struct {
+	signer wallet.Signer
 }
 
 // loaderServer provides RPC clients with the ability to load and close wallets,
 // as well as establishing a RPC connection to a btcd consensus server.
 type loaderServer struct {
-	loader    *wallet.Loader
-	activeNet *netparams.Params
-	rpcClient *chain.RPCClient
-	mu        sync.Mutex
+	loader      *wallet.Loader
+	multiLoader *wallet.MultiLoader
+	activeNet   *netparams.Params
+	proxy       *chain.Proxy
+	debugLevel  DebugLevelFunc
+	rpcClient   *chain.RPCClient
+	mu          sync.Mutex
 }
 
+// DebugLevelFunc sets the logging levels of a level spec in the format of the
+// debuglevel option, unless the spec is empty or "show", and returns the
+// current logging level of every subsystem keyed by subsystem.  No levels are
+// changed when it errors.
+type DebugLevelFunc func(levelSpec string) (map[string]string, error)
+
 // StartVersionService creates an implementation of the VersionService and
 // registers it with the gRPC server.
 func StartVersionService(server *grpc.Server) {
@@ -132,12 +185,45 @@ func (*versionServer) Version(ctx context.Context, req *pb.VersionRequest) (*pb.
 }
 
 // StartWalletService creates an implementation of the WalletService and
-// registers it with the gRPC server.
-func StartWalletService(server *grpc.Server, wallet *wallet.Wallet) {
-	service := &walletServer{wallet}
+// registers it with the gRPC server.  Calls are served by the default wallet of
+// the loader unless the WalletMetadataKey metadata of the call names a wallet
+// of the multiloader, which may be nil if named wallets are not hosted.  The
+// service is registered before any wallet is loaded, and calls fail while the
+// wallet serving them is not loaded.
+func StartWalletService(server *grpc.Server, loader *wallet.Loader,
+	multiLoader *wallet.MultiLoader) {
+
+	service := &walletServer{loader: loader, multiLoader: multiLoader}
 	pb.RegisterWalletServiceServer(server, service)
 }
 
+// walletFor returns the wallet serving a call with the context ctx.
+func (s *walletServer) walletFor(ctx context.Context) (*wallet.Wallet, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	names := md[WalletMetadataKey]
+	switch {
+	case len(names) == 0 || len(names) == 1 && names[0] == "":
+		w, ok := s.loader.LoadedWallet()
+		if !ok {
+			return nil, grpc.Errorf(codes.FailedPrecondition,
+				"wallet is not loaded")
+		}
+		return w, nil
+	case len(names) != 1:
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"expected one wallet name in metadata")
+	case s.multiLoader == nil:
+		return nil, grpc.Errorf(codes.Unimplemented,
+			"named wallets are not hosted by this server")
+	}
+	w, ok := s.multiLoader.LoadedWallet(names[0])
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound,
+			"wallet %q is not loaded", names[0])
+	}
+	return w, nil
+}
+
 func (s *walletServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
 	return &pb.PingResponse{}, nil
 }
@@ -145,13 +231,23 @@ func (s *walletServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingR
 func (s *walletServer) Network(ctx context.Context, req *pb.NetworkRequest) (
 	*pb.NetworkResponse, error) {
 
-	return &pb.NetworkResponse{ActiveNetwork: uint32(s.wallet.ChainParams().Net)}, nil
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.NetworkResponse{ActiveNetwork: uint32(w.ChainParams().Net)}, nil
 }
 
 func (s *walletServer) AccountNumber(ctx context.Context, req *pb.AccountNumberRequest) (
 	*pb.AccountNumberResponse, error) {
 
-	accountNum, err := s.wallet.AccountNumber(waddrmgr.KeyScopeBIP0044, req.AccountName)
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountNum, err := w.AccountNumber(waddrmgr.KeyScopeBIP0044, req.AccountName)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -162,7 +258,12 @@ func (s *walletServer) AccountNumber(ctx context.Context, req *pb.AccountNumberR
 func (s *walletServer) Accounts(ctx context.Context, req *pb.AccountsRequest) (
 	*pb.AccountsResponse, error) {
 
-	resp, err := s.wallet.Accounts(waddrmgr.KeyScopeBIP0044)
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := w.Accounts(waddrmgr.KeyScopeBIP0044)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -188,7 +289,12 @@ func (s *walletServer) Accounts(ctx context.Context, req *pb.AccountsRequest) (
 func (s *walletServer) RenameAccount(ctx context.Context, req *pb.RenameAccountRequest) (
 	*pb.RenameAccountResponse, error) {
 
-	err := s.wallet.RenameAccount(waddrmgr.KeyScopeBIP0044, req.AccountNumber, req.NewName)
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.RenameAccount(waddrmgr.KeyScopeBIP0044, req.AccountNumber, req.NewName)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -201,20 +307,23 @@ func (s *walletServer) NextAccount(ctx context.Context, req *pb.NextAccountReque
 
 	defer zero.Bytes(req.Passphrase)
 
-	if req.AccountName == "" {
-		return nil, grpc.Errorf(codes.InvalidArgument, "account name may not be empty")
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	lock := make(chan time.Time, 1)
-	defer func() {
-		lock <- time.Time{} // send matters, not the value
-	}()
-	err := s.wallet.Unlock(req.Passphrase, lock)
-	if err != nil {
-		return nil, translateError(err)
+	if req.AccountName == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "account name may not be empty")
 	}
 
-	account, err := s.wallet.NextAccount(waddrmgr.KeyScopeBIP0044, req.AccountName)
+	var account uint32
+	err = w.WithUnlock(req.Passphrase, func() error {
+		var err error
+		account, err = w.NextAccount(
+			waddrmgr.KeyScopeBIP0044, req.AccountName,
+		)
+		return err
+	})
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -225,15 +334,17 @@ func (s *walletServer) NextAccount(ctx context.Context, req *pb.NextAccountReque
 func (s *walletServer) NextAddress(ctx context.Context, req *pb.NextAddressRequest) (
 	*pb.NextAddressResponse, error) {
 
-	var (
-		addr btcutil.Address
-		err  error
-	)
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var addr btcutil.Address
 	switch req.Kind {
 	case pb.NextAddressRequest_BIP0044_EXTERNAL:
-		addr, err = s.wallet.NewAddress(req.Account, waddrmgr.KeyScopeBIP0044)
+		addr, err = w.NewAddress(req.Account, waddrmgr.KeyScopeBIP0044)
 	case pb.NextAddressRequest_BIP0044_INTERNAL:
-		addr, err = s.wallet.NewChangeAddress(req.Account, waddrmgr.KeyScopeBIP0044)
+		addr, err = w.NewChangeAddress(req.Account, waddrmgr.KeyScopeBIP0044)
 	default:
 		return nil, grpc.Errorf(codes.InvalidArgument, "kind=%v", req.Kind)
 	}
@@ -249,19 +360,15 @@ func (s *walletServer) ImportPrivateKey(ctx context.Context, req *pb.ImportPriva
 
 	defer zero.Bytes(req.Passphrase)
 
-	wif, err := btcutil.DecodeWIF(req.PrivateKeyWif)
+	w, err := s.walletFor(ctx)
 	if err != nil {
-		return nil, grpc.Errorf(codes.InvalidArgument,
-			"Invalid WIF-encoded private key: %v", err)
+		return nil, err
 	}
 
-	lock := make(chan time.Time, 1)
-	defer func() {
-		lock <- time.Time{} // send matters, not the value
-	}()
-	err = s.wallet.Unlock(req.Passphrase, lock)
+	wif, err := btcutil.DecodeWIF(req.PrivateKeyWif)
 	if err != nil {
-		return nil, translateError(err)
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Invalid WIF-encoded private key: %v", err)
 	}
 
 	// At the moment, only the special-cased import account can be used to
@@ -271,7 +378,12 @@ func (s *walletServer) ImportPrivateKey(ctx context.Context, req *pb.ImportPriva
 			"Only the imported account accepts private key imports")
 	}
 
-	_, err = s.wallet.ImportPrivateKey(waddrmgr.KeyScopeBIP0044, wif, nil, req.Rescan)
+	err = w.WithUnlock(req.Passphrase, func() error {
+		_, err := w.ImportPrivateKey(
+			waddrmgr.KeyScopeBIP0044, wif, nil, req.Rescan,
+		)
+		return err
+	})
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -282,9 +394,14 @@ func (s *walletServer) ImportPrivateKey(ctx context.Context, req *pb.ImportPriva
 func (s *walletServer) Balance(ctx context.Context, req *pb.BalanceRequest) (
 	*pb.BalanceResponse, error) {
 
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	account := req.AccountNumber
 	reqConfs := req.RequiredConfirmations
-	bals, err := s.wallet.CalculateAccountBalances(account, reqConfs)
+	bals, err := w.CalculateAccountBalances(account, reqConfs)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -317,17 +434,42 @@ func confirms(txHeight, curHeight int32) int32 {
 	}
 }
 
+// sortOldestFirst sorts outputs from the most to the least confirmed.  Unmined
+// outputs are sorted last.
+func sortOldestFirst(outputs []*wallet.TransactionOutput) {
+	sort.SliceStable(outputs, func(i, j int) bool {
+		hi := outputs[i].ContainingBlock.Height
+		hj := outputs[j].ContainingBlock.Height
+		switch {
+		case hi == -1:
+			return false
+		case hj == -1:
+			return true
+		default:
+			return hi < hj
+		}
+	})
+}
+
 func (s *walletServer) FundTransaction(ctx context.Context, req *pb.FundTransactionRequest) (
 	*pb.FundTransactionResponse, error) {
 
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	policy := wallet.OutputSelectionPolicy{
 		Account:               req.Account,
 		RequiredConfirmations: req.RequiredConfirmations,
 	}
-	unspentOutputs, err := s.wallet.UnspentOutputs(policy)
+	unspentOutputs, err := w.UnspentOutputs(policy)
 	if err != nil {
 		return nil, translateError(err)
 	}
+	if req.OldestFirst {
+		sortOldestFirst(unspentOutputs)
+	}
 
 	selectedOutputs := make([]*pb.FundTransactionResponse_PreviousOutput, 0, len(unspentOutputs))
 	var totalAmount btcutil.Amount
@@ -349,7 +491,7 @@ func (s *walletServer) FundTransaction(ctx context.Context, req *pb.FundTransact
 
 	var changeScript []byte
 	if req.IncludeChangeScript && totalAmount > btcutil.Amount(req.TargetAmount) {
-		changeAddr, err := s.wallet.NewChangeAddress(req.Account, waddrmgr.KeyScopeBIP0044)
+		changeAddr, err := w.NewChangeAddress(req.Account, waddrmgr.KeyScopeBIP0044)
 		if err != nil {
 			return nil, translateError(err)
 		}
@@ -382,6 +524,11 @@ func marshalGetTransactionsResult(wresp *wallet.GetTransactionsResult) (
 func (s *walletServer) GetTransactions(ctx context.Context, req *pb.GetTransactionsRequest) (
 	resp *pb.GetTransactionsResponse, err error) {
 
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var startBlock, endBlock *wallet.BlockIdentifier
 	if req.StartingBlockHash != nil && req.StartingBlockHeight != 0 {
 		return nil, errors.New(
@@ -425,13 +572,206 @@ func (s *walletServer) GetTransactions(ctx context.Context, req *pb.GetTransacti
 
 	_ = minRecentTxs
 
-	gtr, err := s.wallet.GetTransactions(startBlock, endBlock, ctx.Done())
+	gtr, err := w.GetTransactions(startBlock, endBlock, ctx.Done())
 	if err != nil {
 		return nil, translateError(err)
 	}
 	return marshalGetTransactionsResult(gtr)
 }
 
+func (s *walletServer) GetRecoveryInfo(ctx context.Context, req *pb.GetRecoveryInfoRequest) (
+	*pb.GetRecoveryInfoResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := w.RecoveryInfo()
+	return &pb.GetRecoveryInfoResponse{
+		RecoveryMode:     info.Recovering,
+		RecoveryFinished: info.Finished,
+		RecoveryWindow:   info.Window,
+		Progress:         info.Progress,
+	}, nil
+}
+
+// decodeAddress decodes an address of the network of the wallet, returning an
+// InvalidArgument error when the address is invalid.
+func decodeAddress(a string, params *chaincfg.Params) (btcutil.Address, error) {
+	addr, err := btcutil.DecodeAddress(a, params)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Invalid address %q: %v", a, err)
+	}
+	if !addr.IsForNet(params) {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Address %q is not intended for use on %s", a, params.Name)
+	}
+	return addr, nil
+}
+
+func marshalOutPoint(op *wire.OutPoint) *pb.OutPoint {
+	return &pb.OutPoint{
+		TransactionHash: op.Hash[:],
+		OutputIndex:     op.Index,
+	}
+}
+
+func marshalGetTransactionResult(v *wallet.TransactionDetails) *pb.GetTransactionResponse {
+	inputs := make([]*pb.GetTransactionResponse_Input, len(v.Inputs))
+	for i := range v.Inputs {
+		input := &v.Inputs[i]
+		inputs[i] = &pb.GetTransactionResponse_Input{
+			PreviousOutput: marshalOutPoint(&input.PreviousOutPoint),
+			PreviousAmount: int64(input.PreviousAmount),
+			AmountKnown:    input.AmountKnown,
+			Mine:           input.Mine,
+			Account:        input.Account,
+		}
+	}
+	outputs := make([]*pb.GetTransactionResponse_Output, len(v.Outputs))
+	for i := range v.Outputs {
+		output := &v.Outputs[i]
+		addrs := make([]string, len(output.Addresses))
+		for j, addr := range output.Addresses {
+			addrs[j] = addr.EncodeAddress()
+		}
+		outputs[i] = &pb.GetTransactionResponse_Output{
+			Index:     output.Index,
+			Amount:    int64(output.Amount),
+			PkScript:  output.PkScript,
+			Addresses: addrs,
+			Mine:      output.Mine,
+			Account:   output.Account,
+			Internal:  output.Internal,
+		}
+	}
+	resp := &pb.GetTransactionResponse{
+		Hash:          v.Hash[:],
+		Transaction:   v.SerializedTx,
+		Label:         v.Label,
+		ReceivedTime:  v.Received.Unix(),
+		BlockHeight:   v.BlockHeight,
+		Confirmations: v.Confirmations,
+		Inputs:        inputs,
+		Outputs:       outputs,
+		Fee:           int64(v.Fee),
+		FeeKnown:      v.FeeKnown,
+	}
+	if v.BlockHash != nil {
+		resp.BlockHash = v.BlockHash[:]
+		resp.BlockTime = v.BlockTime.Unix()
+	}
+	return resp
+}
+
+func (s *walletServer) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (
+	*pb.GetTransactionResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := chainhash.NewHash(req.TransactionHash)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+
+	details, err := w.GetTransaction(txHash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return marshalGetTransactionResult(details), nil
+}
+
+func (s *walletServer) ListLockedOutputs(ctx context.Context, req *pb.ListLockedOutputsRequest) (
+	*pb.ListLockedOutputsResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := w.LockedOutpoints()
+	outputs := make([]*pb.OutPoint, 0, len(locked))
+	for _, input := range locked {
+		txHash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		op := wire.OutPoint{Hash: *txHash, Index: input.Vout}
+		outputs = append(outputs, marshalOutPoint(&op))
+	}
+	return &pb.ListLockedOutputsResponse{Outputs: outputs}, nil
+}
+
+func (s *walletServer) ValidateAddress(ctx context.Context, req *pb.ValidateAddressRequest) (
+	*pb.ValidateAddressResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ValidateAddressResponse{}
+	addr, err := decodeAddress(req.Address, w.ChainParams())
+	if err != nil {
+		return resp, nil
+	}
+	resp.IsValid = true
+
+	ainfo, err := w.AddressInfo(addr)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return resp, nil
+		}
+		return nil, translateError(err)
+	}
+	resp.IsMine = true
+	resp.Account = ainfo.Account()
+	resp.IsInternal = ainfo.Internal()
+
+	switch ma := ainfo.(type) {
+	case waddrmgr.ManagedPubKeyAddress:
+		if ma.Compressed() {
+			resp.PubKey = ma.PubKey().SerializeCompressed()
+		} else {
+			resp.PubKey = ma.PubKey().SerializeUncompressed()
+		}
+	case waddrmgr.ManagedScriptAddress:
+		resp.IsScript = true
+
+		// The script is only available if the manager is unlocked.
+		script, err := ma.Script()
+		if err == nil {
+			resp.Script = script
+		}
+	}
+	return resp, nil
+}
+
+func (s *walletServer) VerifyMessage(ctx context.Context, req *pb.VerifyMessageRequest) (
+	*pb.VerifyMessageResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := decodeAddress(req.Address, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := wallet.VerifyMessage(addr, req.Message, req.Signature)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	return &pb.VerifyMessageResponse{Valid: valid}, nil
+}
+
 func (s *walletServer) ChangePassphrase(ctx context.Context, req *pb.ChangePassphraseRequest) (
 	*pb.ChangePassphraseResponse, error) {
 
@@ -440,12 +780,16 @@ func (s *walletServer) ChangePassphrase(ctx context.Context, req *pb.ChangePassp
 		zero.Bytes(req.NewPassphrase)
 	}()
 
-	var err error
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	switch req.Key {
 	case pb.ChangePassphraseRequest_PRIVATE:
-		err = s.wallet.ChangePrivatePassphrase(req.OldPassphrase, req.NewPassphrase)
+		err = w.ChangePrivatePassphrase(req.OldPassphrase, req.NewPassphrase)
 	case pb.ChangePassphraseRequest_PUBLIC:
-		err = s.wallet.ChangePublicPassphrase(req.OldPassphrase, req.NewPassphrase)
+		err = w.ChangePublicPassphrase(req.OldPassphrase, req.NewPassphrase)
 	default:
 		return nil, grpc.Errorf(codes.InvalidArgument, "Unknown key type (%d)", req.Key)
 	}
@@ -462,23 +806,27 @@ func (s *walletServer) SignTransaction(ctx context.Context, req *pb.SignTransact
 
 	defer zero.Bytes(req.Passphrase)
 
-	var tx wire.MsgTx
-	err := tx.Deserialize(bytes.NewReader(req.SerializedTransaction))
+	w, err := s.walletFor(ctx)
 	if err != nil {
-		return nil, grpc.Errorf(codes.InvalidArgument,
-			"Bytes do not represent a valid raw transaction: %v", err)
+		return nil, err
 	}
 
-	lock := make(chan time.Time, 1)
-	defer func() {
-		lock <- time.Time{} // send matters, not the value
-	}()
-	err = s.wallet.Unlock(req.Passphrase, lock)
+	var tx wire.MsgTx
+	err = tx.Deserialize(bytes.NewReader(req.SerializedTransaction))
 	if err != nil {
-		return nil, translateError(err)
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Bytes do not represent a valid raw transaction: %v", err)
 	}
 
-	invalidSigs, err := s.wallet.SignTransaction(&tx, txscript.SigHashAll, nil, nil, nil)
+	// The wallet is only unlocked while the transaction is signed.
+	var invalidSigs []wallet.SignatureError
+	err = w.WithUnlock(req.Passphrase, func() error {
+		var err error
+		invalidSigs, err = w.SignTransaction(
+			&tx, txscript.SigHashAll, nil, nil, nil,
+		)
+		return err
+	})
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -512,14 +860,19 @@ func (s *walletServer) SignTransaction(ctx context.Context, req *pb.SignTransact
 func (s *walletServer) PublishTransaction(ctx context.Context, req *pb.PublishTransactionRequest) (
 	*pb.PublishTransactionResponse, error) {
 
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var msgTx wire.MsgTx
-	err := msgTx.Deserialize(bytes.NewReader(req.SignedTransaction))
+	err = msgTx.Deserialize(bytes.NewReader(req.SignedTransaction))
 	if err != nil {
 		return nil, grpc.Errorf(codes.InvalidArgument,
 			"Bytes do not represent a valid raw transaction: %v", err)
 	}
 
-	err = s.wallet.PublishTransaction(&msgTx)
+	err = w.PublishTransaction(&msgTx)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -527,6 +880,137 @@ func (s *walletServer) PublishTransaction(ctx context.Context, req *pb.PublishTr
 	return &pb.PublishTransactionResponse{}, nil
 }
 
+func (s *walletServer) SendOutputs(ctx context.Context, req *pb.SendOutputsRequest) (
+	*pb.SendOutputsResponse, error) {
+
+	defer zero.Bytes(req.Passphrase)
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Outputs) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "No outputs")
+	}
+	if req.RequiredConfirmations < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Required confirmations may not be negative")
+	}
+	if req.FeeRate < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Fee rate may not be negative")
+	}
+	outputs := make([]*wire.TxOut, len(req.Outputs))
+	for i, output := range req.Outputs {
+		outputs[i] = wire.NewTxOut(output.Amount, output.PkScript)
+	}
+
+	// The wallet is only unlocked while the transaction is created.
+	var txHash *chainhash.Hash
+	err = w.WithUnlock(req.Passphrase, func() error {
+		var err error
+		txHash, err = w.SendOutputs(
+			outputs, req.Account, req.RequiredConfirmations,
+			btcutil.Amount(req.FeeRate),
+			w.DefaultCoinSelectionStrategy(),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.SendOutputsResponse{TransactionHash: txHash[:]}, nil
+}
+
+func (s *walletServer) LockOutputs(ctx context.Context, req *pb.LockOutputsRequest) (
+	*pb.LockOutputsResponse, error) {
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Unlock && len(req.Outputs) == 0 {
+		w.ResetLockedOutpoints()
+		return &pb.LockOutputsResponse{}, nil
+	}
+
+	ops := make([]wire.OutPoint, len(req.Outputs))
+	for i, output := range req.Outputs {
+		txHash, err := chainhash.NewHash(output.TransactionHash)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+		}
+		ops[i] = wire.OutPoint{Hash: *txHash, Index: output.OutputIndex}
+	}
+	for _, op := range ops {
+		if req.Unlock {
+			w.UnlockOutpoint(op)
+		} else {
+			w.LockOutpoint(op)
+		}
+	}
+	return &pb.LockOutputsResponse{}, nil
+}
+
+func (s *walletServer) SignMessage(ctx context.Context, req *pb.SignMessageRequest) (
+	*pb.SignMessageResponse, error) {
+
+	defer zero.Bytes(req.Passphrase)
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := decodeAddress(req.Address, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var sig []byte
+	err = w.WithUnlock(req.Passphrase, func() error {
+		var err error
+		sig, err = w.SignMessage(addr, req.Message)
+		return err
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.SignMessageResponse{Signature: sig}, nil
+}
+
+func (s *walletServer) DumpPrivateKey(ctx context.Context, req *pb.DumpPrivateKeyRequest) (
+	*pb.DumpPrivateKeyResponse, error) {
+
+	defer zero.Bytes(req.Passphrase)
+
+	w, err := s.walletFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := decodeAddress(req.Address, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var wif string
+	err = w.WithUnlock(req.Passphrase, func() error {
+		var err error
+		wif, err = w.DumpWIFPrivateKey(addr)
+		return err
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.DumpPrivateKeyResponse{PrivateKeyWif: wif}, nil
+}
+
 func marshalTransactionInputs(v []wallet.TransactionSummaryInput) []*pb.TransactionDetails_Input {
 	inputs := make([]*pb.TransactionDetails_Input, len(v))
 	for i := range v {
@@ -548,6 +1032,7 @@ func marshalTransactionOutputs(v []wallet.TransactionSummaryOutput) []*pb.Transa
 			Index:    output.Index,
 			Account:  output.Account,
 			Internal: output.Internal,
+			Amount:   int64(output.Amount),
 		}
 	}
 	return outputs
@@ -564,6 +1049,41 @@ func marshalTransactionDetails(v []wallet.TransactionSummary) []*pb.TransactionD
 			Credits:     marshalTransactionOutputs(tx.MyOutputs),
 			Fee:         int64(tx.Fee),
 			Timestamp:   tx.Timestamp,
+			Label:       tx.Label,
+		}
+	}
+	return txs
+}
+
+func marshalConflicts(v []wallet.ConflictedTransaction) []*pb.TransactionNotificationsResponse_ConflictedTransaction {
+	conflicts := make([]*pb.TransactionNotificationsResponse_ConflictedTransaction, len(v))
+	for i := range v {
+		conflict := &v[i]
+		conflicts[i] = &pb.TransactionNotificationsResponse_ConflictedTransaction{
+			Hash:            conflict.Hash[:],
+			ConflictingHash: conflict.ConflictingHash[:],
+			Mined:           conflict.Mined,
+		}
+	}
+	return conflicts
+}
+
+func marshalReorgedTransactions(v []wallet.ReorgedTransaction) []*pb.TransactionNotificationsResponse_ReorgedTransaction {
+	txs := make([]*pb.TransactionNotificationsResponse_ReorgedTransaction, len(v))
+	for i := range v {
+		tx := &v[i]
+		var status pb.TransactionNotificationsResponse_ReorgedTransaction_Status
+		switch tx.Status {
+		case wallet.ReorgUnmined:
+			status = pb.TransactionNotificationsResponse_ReorgedTransaction_UNMINED
+		case wallet.ReorgConflicted:
+			status = pb.TransactionNotificationsResponse_ReorgedTransaction_CONFLICTED
+		case wallet.ReorgRemoved:
+			status = pb.TransactionNotificationsResponse_ReorgedTransaction_REMOVED
+		}
+		txs[i] = &pb.TransactionNotificationsResponse_ReorgedTransaction{
+			Hash:   tx.Hash[:],
+			Status: status,
 		}
 	}
 	return txs
@@ -606,7 +1126,12 @@ func marshalAccountBalances(v []wallet.AccountBalance) []*pb.AccountBalance {
 func (s *walletServer) TransactionNotifications(req *pb.TransactionNotificationsRequest,
 	svr pb.WalletService_TransactionNotificationsServer) error {
 
-	n := s.wallet.NtfnServer.TransactionNotifications()
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
+	n := w.NtfnServer.TransactionNotifications()
 	defer n.Done()
 
 	ctxDone := svr.Context().Done()
@@ -618,6 +1143,8 @@ func (s *walletServer) TransactionNotifications(req *pb.TransactionNotifications
 				DetachedBlocks:           marshalHashes(v.DetachedBlocks),
 				UnminedTransactions:      marshalTransactionDetails(v.UnminedTransactions),
 				UnminedTransactionHashes: marshalHashes(v.UnminedTransactionHashes),
+				ConflictedTransactions:   marshalConflicts(v.ConflictedTransactions),
+				ReorgedTransactions:      marshalReorgedTransactions(v.ReorgedTransactions),
 			}
 			err := svr.Send(&resp)
 			if err != nil {
@@ -633,12 +1160,17 @@ func (s *walletServer) TransactionNotifications(req *pb.TransactionNotifications
 func (s *walletServer) SpentnessNotifications(req *pb.SpentnessNotificationsRequest,
 	svr pb.WalletService_SpentnessNotificationsServer) error {
 
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
 	if req.NoNotifyUnspent && req.NoNotifySpent {
 		return grpc.Errorf(codes.InvalidArgument,
 			"no_notify_unspent and no_notify_spent may not both be true")
 	}
 
-	n := s.wallet.NtfnServer.AccountSpentnessNotifications(req.Account)
+	n := w.NtfnServer.AccountSpentnessNotifications(req.Account)
 	defer n.Done()
 
 	ctxDone := svr.Context().Done()
@@ -674,7 +1206,12 @@ func (s *walletServer) SpentnessNotifications(req *pb.SpentnessNotificationsRequ
 func (s *walletServer) AccountNotifications(req *pb.AccountNotificationsRequest,
 	svr pb.WalletService_AccountNotificationsServer) error {
 
-	n := s.wallet.NtfnServer.AccountNotifications()
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
+	n := w.NtfnServer.AccountNotifications()
 	defer n.Done()
 
 	ctxDone := svr.Context().Done()
@@ -699,15 +1236,234 @@ func (s *walletServer) AccountNotifications(req *pb.AccountNotificationsRequest,
 	}
 }
 
+func (s *walletServer) RescanNotifications(req *pb.RescanNotificationsRequest,
+	svr pb.WalletService_RescanNotificationsServer) error {
+
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
+	n := w.NtfnServer.RescanProgressNotifications()
+	defer n.Done()
+
+	ctxDone := svr.Context().Done()
+	for {
+		select {
+		case v := <-n.C:
+			resp := pb.RescanNotificationsResponse{
+				BlockHash:        v.Hash[:],
+				Height:           v.Height,
+				TargetHeight:     v.TargetHeight,
+				Percent:          v.Percent,
+				SecondsRemaining: int64(v.Remaining / time.Second),
+			}
+			err := svr.Send(&resp)
+			if err != nil {
+				return translateError(err)
+			}
+
+		case <-ctxDone:
+			return nil
+		}
+	}
+}
+
+func (s *walletServer) SubscribeSyncState(req *pb.SubscribeSyncStateRequest,
+	svr pb.WalletService_SubscribeSyncStateServer) error {
+
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
+	n := w.NtfnServer.SyncStateNotifications()
+	defer n.Done()
+
+	state := w.SyncState()
+	ctxDone := svr.Context().Done()
+	for {
+		resp := pb.SubscribeSyncStateResponse{
+			Synced:        state.Synced,
+			Height:        state.Height,
+			BackendHeight: state.BackendHeight,
+		}
+		err := svr.Send(&resp)
+		if err != nil {
+			return translateError(err)
+		}
+
+		select {
+		case state = <-n.C:
+		case <-ctxDone:
+			return nil
+		}
+	}
+}
+
+func (s *walletServer) AccountBalanceNotifications(req *pb.AccountBalanceNotificationsRequest,
+	svr pb.WalletService_AccountBalanceNotificationsServer) error {
+
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+
+	n := w.NtfnServer.AccountBalanceNotifications()
+	defer n.Done()
+
+	ctxDone := svr.Context().Done()
+	for {
+		select {
+		case v := <-n.C:
+			resp := pb.AccountBalanceNotificationsResponse{
+				Account:            v.Account,
+				ConfirmedBalance:   int64(v.Confirmed),
+				UnconfirmedBalance: int64(v.Unconfirmed),
+				ConfirmedDelta:     int64(v.ConfirmedDelta),
+				UnconfirmedDelta:   int64(v.UnconfirmedDelta),
+			}
+			err := svr.Send(&resp)
+			if err != nil {
+				return translateError(err)
+			}
+
+		case <-ctxDone:
+			return nil
+		}
+	}
+}
+
+// exportChunkSize is the maximum size of the chunks of the wallet database
+// streamed by ExportDatabase.
+const exportChunkSize = 64 * 1024
+
+// exportWriter sends the bytes written to it as chunks of an ExportDatabase
+// stream.
+type exportWriter struct {
+	svr pb.WalletService_ExportDatabaseServer
+}
+
+func (w exportWriter) Write(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > exportChunkSize {
+			chunk = chunk[:exportChunkSize]
+		}
+		err := w.svr.Send(&pb.ExportDatabaseResponse{Chunk: chunk})
+		if err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// ExportDatabase streams an export of the wallet database.  The export holds
+// every key and value of the wallet, so it is only served to calls
+// authenticated with a full macaroon, and never when macaroons are disabled.
+// Encrypted databases are only exported as copies of the encrypted database,
+// since a dump would hold their decrypted contents.
+func (s *walletServer) ExportDatabase(req *pb.ExportDatabaseRequest,
+	svr pb.WalletService_ExportDatabaseServer) error {
+
+	perm, ok := macaroons.PermissionFromContext(svr.Context())
+	if !ok || perm != macaroons.Full {
+		return grpc.Errorf(codes.PermissionDenied, "exporting the "+
+			"database requires a full macaroon")
+	}
+
+	w, err := s.walletFor(svr.Context())
+	if err != nil {
+		return err
+	}
+	if req.Dump && w.DatabaseEncrypted() {
+		return grpc.Errorf(codes.FailedPrecondition, "encrypted "+
+			"databases can not be dumped")
+	}
+
+	// The export is read in a single database transaction and streamed
+	// while it is written, so it is consistent without being held in
+	// memory.
+	db := w.Database()
+	buf := bufio.NewWriterSize(exportWriter{svr}, exportChunkSize)
+	if req.Dump {
+		err = walletdb.Dump(db, buf)
+	} else {
+		err = db.Copy(buf)
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// StartSignerService creates an implementation of the SignerService signing
+// with signer and registers it with the gRPC server.
+func StartSignerService(server *grpc.Server, signer wallet.Signer) {
+	pb.RegisterSignerServiceServer(server, &signerServer{signer})
+}
+
+func (s *signerServer) SignOutputRaw(ctx context.Context, req *pb.SignOutputRawRequest) (
+	*pb.SignOutputRawResponse, error) {
+
+	var tx wire.MsgTx
+	err := tx.Deserialize(bytes.NewReader(req.Transaction))
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"Bytes do not represent a valid raw transaction: %v", err)
+	}
+
+	sig, err := s.signer.SignOutputRaw(&tx, &wallet.SignDescriptor{
+		KeyPath:    req.KeyPath,
+		PubKey:     req.PublicKey,
+		SubScript:  req.SubScript,
+		Amount:     req.Amount,
+		HashType:   txscript.SigHashType(req.HashType),
+		InputIndex: int(req.InputIndex),
+		Witness:    req.Witness,
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &pb.SignOutputRawResponse{Signature: sig}, nil
+}
+
 // StartWalletLoaderService creates an implementation of the WalletLoaderService
-// and registers it with the gRPC server.
+// and registers it with the gRPC server.  Requests naming a wallet are served
+// by multiLoader, which may be nil if named wallets are not hosted.  Consensus
+// RPC connections started by the service are made through proxy, unless it is
+// nil, and only synchronize the default wallet.  Logging levels are queried and
+// changed with debugLevel, which may be nil to not support the DebugLevel
+// method.
 func StartWalletLoaderService(server *grpc.Server, loader *wallet.Loader,
-	activeNet *netparams.Params) {
-
-	service := &loaderServer{loader: loader, activeNet: activeNet}
+	multiLoader *wallet.MultiLoader, activeNet *netparams.Params,
+	proxy *chain.Proxy, debugLevel DebugLevelFunc) {
+
+	service := &loaderServer{
+		loader:      loader,
+		multiLoader: multiLoader,
+		activeNet:   activeNet,
+		proxy:       proxy,
+		debugLevel:  debugLevel,
+	}
 	pb.RegisterWalletLoaderServiceServer(server, service)
 }
 
+// namedWallets returns the multiloader serving requests naming a wallet, or an
+// error if named wallets are not hosted.
+func (s *loaderServer) namedWallets() (*wallet.MultiLoader, error) {
+	if s.multiLoader == nil {
+		return nil, grpc.Errorf(codes.Unimplemented,
+			"named wallets are not hosted by this server")
+	}
+	return s.multiLoader, nil
+}
+
 func (s *loaderServer) CreateWallet(ctx context.Context, req *pb.CreateWalletRequest) (
 	*pb.CreateWalletResponse, error) {
 
@@ -722,6 +1478,19 @@ func (s *loaderServer) CreateWallet(ctx context.Context, req *pb.CreateWalletReq
 		pubPassphrase = []byte(wallet.InsecurePubPassphrase)
 	}
 
+	if req.WalletName != "" {
+		multiLoader, err := s.namedWallets()
+		if err != nil {
+			return nil, err
+		}
+		_, err = multiLoader.CreateWallet(req.WalletName, pubPassphrase,
+			req.PrivatePassphrase, req.Seed, time.Now())
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return &pb.CreateWalletResponse{}, nil
+	}
+
 	wallet, err := s.loader.CreateNewWallet(
 		pubPassphrase, req.PrivatePassphrase, req.Seed, time.Now(),
 	)
@@ -747,6 +1516,18 @@ func (s *loaderServer) OpenWallet(ctx context.Context, req *pb.OpenWalletRequest
 		pubPassphrase = []byte(wallet.InsecurePubPassphrase)
 	}
 
+	if req.WalletName != "" {
+		multiLoader, err := s.namedWallets()
+		if err != nil {
+			return nil, err
+		}
+		_, err = multiLoader.OpenWallet(req.WalletName, pubPassphrase)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return &pb.OpenWalletResponse{}, nil
+	}
+
 	wallet, err := s.loader.OpenExistingWallet(pubPassphrase, false)
 	if err != nil {
 		return nil, translateError(err)
@@ -764,7 +1545,18 @@ func (s *loaderServer) OpenWallet(ctx context.Context, req *pb.OpenWalletRequest
 func (s *loaderServer) WalletExists(ctx context.Context, req *pb.WalletExistsRequest) (
 	*pb.WalletExistsResponse, error) {
 
-	exists, err := s.loader.WalletExists()
+	var exists bool
+	var err error
+	if req.WalletName != "" {
+		var multiLoader *wallet.MultiLoader
+		multiLoader, err = s.namedWallets()
+		if err != nil {
+			return nil, err
+		}
+		exists, err = multiLoader.WalletExists(req.WalletName)
+	} else {
+		exists, err = s.loader.WalletExists()
+	}
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -774,7 +1566,17 @@ func (s *loaderServer) WalletExists(ctx context.Context, req *pb.WalletExistsReq
 func (s *loaderServer) CloseWallet(ctx context.Context, req *pb.CloseWalletRequest) (
 	*pb.CloseWalletResponse, error) {
 
-	err := s.loader.UnloadWallet()
+	var err error
+	if req.WalletName != "" {
+		var multiLoader *wallet.MultiLoader
+		multiLoader, err = s.namedWallets()
+		if err != nil {
+			return nil, err
+		}
+		err = multiLoader.UnloadWallet(req.WalletName)
+	} else {
+		err = s.loader.UnloadWallet()
+	}
 	if err == wallet.ErrNotLoaded {
 		return nil, grpc.Errorf(codes.FailedPrecondition, "wallet is not loaded")
 	}
@@ -785,6 +1587,21 @@ func (s *loaderServer) CloseWallet(ctx context.Context, req *pb.CloseWalletReque
 	return &pb.CloseWalletResponse{}, nil
 }
 
+func (s *loaderServer) ListWallets(ctx context.Context, req *pb.ListWalletsRequest) (
+	*pb.ListWalletsResponse, error) {
+
+	if s.multiLoader == nil {
+		var names []string
+		if _, ok := s.loader.LoadedWallet(); ok {
+			names = append(names, "")
+		}
+		return &pb.ListWalletsResponse{WalletNames: names}, nil
+	}
+	return &pb.ListWalletsResponse{
+		WalletNames: s.multiLoader.LoadedWallets(),
+	}, nil
+}
+
 func (s *loaderServer) StartConsensusRpc(ctx context.Context, req *pb.StartConsensusRpcRequest) (
 	*pb.StartConsensusRpcResponse, error) {
 
@@ -812,7 +1629,8 @@ func (s *loaderServer) StartConsensusRpc(ctx context.Context, req *pb.StartConse
 	}
 
 	rpcClient, err := chain.NewRPCClient(s.activeNet.Params, networkAddress, req.Username,
-		string(req.Password), req.Certificate, len(req.Certificate) == 0, 1)
+		string(req.Password), req.Certificate, len(req.Certificate) == 0, 1,
+		s.proxy)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -835,3 +1653,30 @@ func (s *loaderServer) StartConsensusRpc(ctx context.Context, req *pb.StartConse
 
 	return &pb.StartConsensusRpcResponse{}, nil
 }
+
+func (s *loaderServer) DebugLevel(ctx context.Context, req *pb.DebugLevelRequest) (
+	*pb.DebugLevelResponse, error) {
+
+	if s.debugLevel == nil {
+		return nil, grpc.Errorf(codes.Unimplemented,
+			"logging levels can not be changed by this server")
+	}
+	levels, err := s.debugLevel(req.LevelSpec)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	subsystems := make([]*pb.DebugLevelResponse_Subsystem, 0, len(names))
+	for _, name := range names {
+		subsystems = append(subsystems, &pb.DebugLevelResponse_Subsystem{
+			Name:  name,
+			Level: levels[name],
+		})
+	}
+	return &pb.DebugLevelResponse{Subsystems: subsystems}, nil
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+)
+
+var (
+	testPubPass  = []byte("public")
+	testPrivPass = []byte("private")
+)
+
+// testWalletServer returns a walletServer of a loader which created a new
+// wallet, the wallet, and a function removing it.
+func testWalletServer(t *testing.T) (*walletServer, *wallet.Wallet, func()) {
+	dir, err := ioutil.TempDir("", "rpcserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := wallet.NewLoader(&chaincfg.TestNet3Params, dir, 0)
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	w, err := loader.CreateNewWallet(
+		testPubPass, testPrivPass, seed, time.Now(),
+	)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+
+	return &walletServer{loader: loader}, w, func() {
+		loader.UnloadWallet()
+		os.RemoveAll(dir)
+	}
+}
+
+// testAddress derives the next external address of the default account of the
+// key scope.  The chain server is not notified of it, as the wallet has none.
+func testAddress(t *testing.T, w *wallet.Wallet,
+	scope waddrmgr.KeyScope) btcutil.Address {
+
+	var addr btcutil.Address
+	err := walletdb.Update(w.Database(), func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket([]byte("waddrmgr"))
+		manager, err := w.Manager.FetchScopedKeyManager(scope)
+		if err != nil {
+			return err
+		}
+		addrs, err := manager.NextExternalAddresses(
+			addrmgrNs, waddrmgr.DefaultAccountNum, 1,
+		)
+		if err != nil {
+			return err
+		}
+		addr = addrs[0].Address()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to derive address: %v", err)
+	}
+	return addr
+}
+
+// assertCode ensures that err is an RPC error of the code.
+func assertCode(t *testing.T, desc string, err error, code codes.Code) {
+	if err == nil {
+		t.Fatalf("%s: expected error with code %v", desc, code)
+	}
+	if grpc.Code(err) != code {
+		t.Fatalf("%s: expected error with code %v, got %v", desc, code,
+			err)
+	}
+}
+
+// TestValidateAddress ensures that addresses are validated against the network
+// of the wallet, and that only addresses of the wallet are reported as its
+// own, along with their public keys.
+func TestValidateAddress(t *testing.T) {
+	s, w, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	addr := testAddress(t, w, waddrmgr.KeyScopeBIP0084)
+	resp, err := s.ValidateAddress(ctx, &pb.ValidateAddressRequest{
+		Address: addr.EncodeAddress(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsValid || !resp.IsMine || resp.IsInternal || resp.IsScript ||
+		resp.Account != waddrmgr.DefaultAccountNum || len(resp.PubKey) != 33 {
+
+		t.Fatalf("unexpected wallet address validation %+v", resp)
+	}
+
+	other, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), &chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = s.ValidateAddress(ctx, &pb.ValidateAddressRequest{
+		Address: other.EncodeAddress(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsValid || resp.IsMine {
+		t.Fatalf("unexpected foreign address validation %+v", resp)
+	}
+
+	mainnet, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), &chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []string{"invalid", mainnet.EncodeAddress()} {
+		resp, err = s.ValidateAddress(ctx, &pb.ValidateAddressRequest{
+			Address: a,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.IsValid {
+			t.Fatalf("address %q reported as valid", a)
+		}
+	}
+}
+
+// TestSignVerifyMessage ensures that messages signed with the passphrase of
+// the wallet verify, and that the wallet is locked again after signing.
+func TestSignVerifyMessage(t *testing.T) {
+	s, w, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, scope := range []waddrmgr.KeyScope{
+		waddrmgr.KeyScopeBIP0044, waddrmgr.KeyScopeBIP0084,
+	} {
+		addr := testAddress(t, w, scope).EncodeAddress()
+
+		_, err := s.SignMessage(ctx, &pb.SignMessageRequest{
+			Address:    addr,
+			Message:    "message",
+			Passphrase: []byte("wrong"),
+		})
+		assertCode(t, "wrong passphrase", err, codes.InvalidArgument)
+
+		signed, err := s.SignMessage(ctx, &pb.SignMessageRequest{
+			Address:    addr,
+			Message:    "message",
+			Passphrase: append([]byte(nil), testPrivPass...),
+		})
+		if err != nil {
+			t.Fatalf("%v: unable to sign message: %v", scope, err)
+		}
+		if !w.Locked() {
+			t.Fatalf("%v: wallet unlocked after signing", scope)
+		}
+
+		for _, test := range []struct {
+			message string
+			valid   bool
+		}{
+			{"message", true},
+			{"other message", false},
+		} {
+			resp, err := s.VerifyMessage(ctx, &pb.VerifyMessageRequest{
+				Address:   addr,
+				Message:   test.message,
+				Signature: signed.Signature,
+			})
+			if err != nil {
+				t.Fatalf("%v: unable to verify message: %v", scope,
+					err)
+			}
+			if resp.Valid != test.valid {
+				t.Fatalf("%v: message %q: expected valid %v",
+					scope, test.message, test.valid)
+			}
+		}
+	}
+
+	_, err := s.VerifyMessage(ctx, &pb.VerifyMessageRequest{
+		Address: "invalid",
+		Message: "message",
+	})
+	assertCode(t, "invalid address", err, codes.InvalidArgument)
+}
+
+// TestDumpPrivateKey ensures that the private key of an address of the wallet
+// is only returned with the passphrase of the wallet.
+func TestDumpPrivateKey(t *testing.T) {
+	s, w, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	addr := testAddress(t, w, waddrmgr.KeyScopeBIP0084)
+	_, err := s.DumpPrivateKey(ctx, &pb.DumpPrivateKeyRequest{
+		Address:    addr.EncodeAddress(),
+		Passphrase: []byte("wrong"),
+	})
+	assertCode(t, "wrong passphrase", err, codes.InvalidArgument)
+
+	resp, err := s.DumpPrivateKey(ctx, &pb.DumpPrivateKeyRequest{
+		Address:    addr.EncodeAddress(),
+		Passphrase: append([]byte(nil), testPrivPass...),
+	})
+	if err != nil {
+		t.Fatalf("unable to dump private key: %v", err)
+	}
+	wif, err := btcutil.DecodeWIF(resp.PrivateKeyWif)
+	if err != nil {
+		t.Fatalf("unable to decode private key: %v", err)
+	}
+	pkHash := btcutil.Hash160(wif.SerializePubKey())
+	if !bytes.Equal(pkHash, addr.ScriptAddress()) {
+		t.Fatal("dumped private key does not match the address")
+	}
+	if !w.Locked() {
+		t.Fatal("wallet unlocked after dumping the private key")
+	}
+}
+
+// TestLockOutputs ensures that outputs are locked and unlocked, and that
+// unlocking without outputs unlocks all of them.
+func TestLockOutputs(t *testing.T) {
+	s, _, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	ops := []*pb.OutPoint{
+		marshalOutPoint(&wire.OutPoint{Hash: chainhash.Hash{1}, Index: 0}),
+		marshalOutPoint(&wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1}),
+	}
+	locked := func() map[wire.OutPoint]bool {
+		resp, err := s.ListLockedOutputs(ctx, &pb.ListLockedOutputsRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[wire.OutPoint]bool)
+		for _, op := range resp.Outputs {
+			var hash chainhash.Hash
+			copy(hash[:], op.TransactionHash)
+			m[wire.OutPoint{Hash: hash, Index: op.OutputIndex}] = true
+		}
+		return m
+	}
+
+	_, err := s.LockOutputs(ctx, &pb.LockOutputsRequest{Outputs: ops})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := locked(); len(l) != 2 {
+		t.Fatalf("expected 2 locked outputs, got %v", l)
+	}
+
+	_, err = s.LockOutputs(ctx, &pb.LockOutputsRequest{
+		Outputs: ops[:1],
+		Unlock:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := locked()
+	if len(l) != 1 || !l[wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1}] {
+		t.Fatalf("expected only the second output locked, got %v", l)
+	}
+
+	_, err = s.LockOutputs(ctx, &pb.LockOutputsRequest{Unlock: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := locked(); len(l) != 0 {
+		t.Fatalf("expected no locked outputs, got %v", l)
+	}
+
+	_, err = s.LockOutputs(ctx, &pb.LockOutputsRequest{
+		Outputs: []*pb.OutPoint{{TransactionHash: []byte{1}}},
+	})
+	assertCode(t, "invalid hash", err, codes.InvalidArgument)
+}
+
+// TestGetTransaction ensures that requests for invalid or unrecorded
+// transactions fail with the appropriate codes.
+func TestGetTransaction(t *testing.T) {
+	s, _, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := s.GetTransaction(ctx, &pb.GetTransactionRequest{
+		TransactionHash: []byte{1},
+	})
+	assertCode(t, "invalid hash", err, codes.InvalidArgument)
+
+	hash := chainhash.Hash{1}
+	_, err = s.GetTransaction(ctx, &pb.GetTransactionRequest{
+		TransactionHash: hash[:],
+	})
+	assertCode(t, "unrecorded transaction", err, codes.NotFound)
+}
+
+// TestSendOutputsInvalid ensures that invalid requests to send outputs fail
+// before the wallet is unlocked.
+func TestSendOutputsInvalid(t *testing.T) {
+	s, _, cleanup := testWalletServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	outputs := []*pb.SendOutputsRequest_Output{
+		{Amount: 1e6, PkScript: []byte{0x51}},
+	}
+	tests := []struct {
+		name string
+		req  *pb.SendOutputsRequest
+	}{
+		{
+			name: "no outputs",
+			req:  &pb.SendOutputsRequest{},
+		},
+		{
+			name: "negative confirmations",
+			req: &pb.SendOutputsRequest{
+				Outputs:               outputs,
+				RequiredConfirmations: -1,
+			},
+		},
+		{
+			name: "negative fee rate",
+			req: &pb.SendOutputsRequest{
+				Outputs: outputs,
+				FeeRate: -1,
+			},
+		},
+	}
+	for _, test := range tests {
+		test.req.Passphrase = append([]byte(nil), testPrivPass...)
+		_, err := s.SendOutputs(ctx, test.req)
+		assertCode(t, test.name, err, codes.InvalidArgument)
+	}
+}
+
+// TestWalletFor ensures that calls are served by the default wallet of the
+// loader only while it is loaded, and that calls naming wallets fail when
+// named wallets are not hosted.
+func TestWalletFor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	loader := wallet.NewLoader(&chaincfg.TestNet3Params, dir, 0)
+	s := &walletServer{loader: loader}
+	ctx := context.Background()
+
+	_, err = s.Network(ctx, &pb.NetworkRequest{})
+	assertCode(t, "unloaded wallet", err, codes.FailedPrecondition)
+
+	_, err = loader.CreateNewWallet(testPubPass, testPrivPass, nil, time.Now())
+	if err != nil {
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	defer loader.UnloadWallet()
+	resp, err := s.Network(ctx, &pb.NetworkRequest{})
+	if err != nil {
+		t.Fatalf("unable to call loaded wallet: %v", err)
+	}
+	if resp.ActiveNetwork != uint32(chaincfg.TestNet3Params.Net) {
+		t.Fatalf("unexpected network %v", resp.ActiveNetwork)
+	}
+
+	named := metadata.NewIncomingContext(ctx, metadata.Pairs(
+		WalletMetadataKey, "named",
+	))
+	_, err = s.Network(named, &pb.NetworkRequest{})
+	assertCode(t, "named wallet", err, codes.Unimplemented)
+}
@@ -13,6 +13,7 @@ It has these top-level messages:
 	TransactionDetails
 	BlockDetails
 	AccountBalance
+	OutPoint
 	PingRequest
 	PingResponse
 	NetworkRequest
@@ -33,6 +34,16 @@ It has these top-level messages:
 	BalanceResponse
 	GetTransactionsRequest
 	GetTransactionsResponse
+	GetRecoveryInfoRequest
+	GetRecoveryInfoResponse
+	GetTransactionRequest
+	GetTransactionResponse
+	ListLockedOutputsRequest
+	ListLockedOutputsResponse
+	ValidateAddressRequest
+	ValidateAddressResponse
+	VerifyMessageRequest
+	VerifyMessageResponse
 	ChangePassphraseRequest
 	ChangePassphraseResponse
 	FundTransactionRequest
@@ -41,12 +52,28 @@ It has these top-level messages:
 	SignTransactionResponse
 	PublishTransactionRequest
 	PublishTransactionResponse
+	SendOutputsRequest
+	SendOutputsResponse
+	LockOutputsRequest
+	LockOutputsResponse
+	SignMessageRequest
+	SignMessageResponse
+	DumpPrivateKeyRequest
+	DumpPrivateKeyResponse
 	TransactionNotificationsRequest
 	TransactionNotificationsResponse
 	SpentnessNotificationsRequest
 	SpentnessNotificationsResponse
 	AccountNotificationsRequest
 	AccountNotificationsResponse
+	RescanNotificationsRequest
+	RescanNotificationsResponse
+	SubscribeSyncStateRequest
+	SubscribeSyncStateResponse
+	AccountBalanceNotificationsRequest
+	AccountBalanceNotificationsResponse
+	ExportDatabaseRequest
+	ExportDatabaseResponse
 	CreateWalletRequest
 	CreateWalletResponse
 	OpenWalletRequest
@@ -55,8 +82,14 @@ It has these top-level messages:
 	CloseWalletResponse
 	WalletExistsRequest
 	WalletExistsResponse
+	ListWalletsRequest
+	ListWalletsResponse
 	StartConsensusRpcRequest
 	StartConsensusRpcResponse
+	DebugLevelRequest
+	DebugLevelResponse
+	SignOutputRawRequest
+	SignOutputRawResponse
 */
 package walletrpc
 
@@ -99,7 +132,7 @@ var NextAddressRequest_Kind_value = map[string]int32{
 func (x NextAddressRequest_Kind) String() string {
 	return proto.EnumName(NextAddressRequest_Kind_name, int32(x))
 }
-func (NextAddressRequest_Kind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{17, 0} }
+func (NextAddressRequest_Kind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{18, 0} }
 
 type ChangePassphraseRequest_Key int32
 
@@ -121,7 +154,33 @@ func (x ChangePassphraseRequest_Key) String() string {
 	return proto.EnumName(ChangePassphraseRequest_Key_name, int32(x))
 }
 func (ChangePassphraseRequest_Key) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{25, 0}
+	return fileDescriptor0, []int{36, 0}
+}
+
+type TransactionNotificationsResponse_ReorgedTransaction_Status int32
+
+const (
+	TransactionNotificationsResponse_ReorgedTransaction_UNMINED    TransactionNotificationsResponse_ReorgedTransaction_Status = 0
+	TransactionNotificationsResponse_ReorgedTransaction_CONFLICTED TransactionNotificationsResponse_ReorgedTransaction_Status = 1
+	TransactionNotificationsResponse_ReorgedTransaction_REMOVED    TransactionNotificationsResponse_ReorgedTransaction_Status = 2
+)
+
+var TransactionNotificationsResponse_ReorgedTransaction_Status_name = map[int32]string{
+	0: "UNMINED",
+	1: "CONFLICTED",
+	2: "REMOVED",
+}
+var TransactionNotificationsResponse_ReorgedTransaction_Status_value = map[string]int32{
+	"UNMINED":    0,
+	"CONFLICTED": 1,
+	"REMOVED":    2,
+}
+
+func (x TransactionNotificationsResponse_ReorgedTransaction_Status) String() string {
+	return proto.EnumName(TransactionNotificationsResponse_ReorgedTransaction_Status_name, int32(x))
+}
+func (TransactionNotificationsResponse_ReorgedTransaction_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{53, 1, 0}
 }
 
 type VersionRequest struct {
@@ -195,6 +254,7 @@ type TransactionDetails struct {
 	Credits     []*TransactionDetails_Output `protobuf:"bytes,4,rep,name=credits" json:"credits,omitempty"`
 	Fee         int64                        `protobuf:"varint,5,opt,name=fee" json:"fee,omitempty"`
 	Timestamp   int64                        `protobuf:"varint,6,opt,name=timestamp" json:"timestamp,omitempty"`
+	Label       string                       `protobuf:"bytes,7,opt,name=label" json:"label,omitempty"`
 }
 
 func (m *TransactionDetails) Reset()                    { *m = TransactionDetails{} }
@@ -244,6 +304,13 @@ func (m *TransactionDetails) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *TransactionDetails) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
 type TransactionDetails_Input struct {
 	Index           uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
 	PreviousAccount uint32 `protobuf:"varint,2,opt,name=previous_account,json=previousAccount" json:"previous_account,omitempty"`
@@ -280,6 +347,7 @@ type TransactionDetails_Output struct {
 	Index    uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
 	Account  uint32 `protobuf:"varint,2,opt,name=account" json:"account,omitempty"`
 	Internal bool   `protobuf:"varint,3,opt,name=internal" json:"internal,omitempty"`
+	Amount   int64  `protobuf:"varint,4,opt,name=amount" json:"amount,omitempty"`
 }
 
 func (m *TransactionDetails_Output) Reset()                    { *m = TransactionDetails_Output{} }
@@ -308,6 +376,13 @@ func (m *TransactionDetails_Output) GetInternal() bool {
 	return false
 }
 
+func (m *TransactionDetails_Output) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
 type BlockDetails struct {
 	Hash         []byte                `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	Height       int32                 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
@@ -372,13 +447,37 @@ func (m *AccountBalance) GetTotalBalance() int64 {
 	return 0
 }
 
+type OutPoint struct {
+	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	OutputIndex     uint32 `protobuf:"varint,2,opt,name=output_index,json=outputIndex" json:"output_index,omitempty"`
+}
+
+func (m *OutPoint) Reset()                    { *m = OutPoint{} }
+func (m *OutPoint) String() string            { return proto.CompactTextString(m) }
+func (*OutPoint) ProtoMessage()               {}
+func (*OutPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *OutPoint) GetTransactionHash() []byte {
+	if m != nil {
+		return m.TransactionHash
+	}
+	return nil
+}
+
+func (m *OutPoint) GetOutputIndex() uint32 {
+	if m != nil {
+		return m.OutputIndex
+	}
+	return 0
+}
+
 type PingRequest struct {
 }
 
 func (m *PingRequest) Reset()                    { *m = PingRequest{} }
 func (m *PingRequest) String() string            { return proto.CompactTextString(m) }
 func (*PingRequest) ProtoMessage()               {}
-func (*PingRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*PingRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 type PingResponse struct {
 }
@@ -386,7 +485,7 @@ type PingResponse struct {
 func (m *PingResponse) Reset()                    { *m = PingResponse{} }
 func (m *PingResponse) String() string            { return proto.CompactTextString(m) }
 func (*PingResponse) ProtoMessage()               {}
-func (*PingResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*PingResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 type NetworkRequest struct {
 }
@@ -394,7 +493,7 @@ type NetworkRequest struct {
 func (m *NetworkRequest) Reset()                    { *m = NetworkRequest{} }
 func (m *NetworkRequest) String() string            { return proto.CompactTextString(m) }
 func (*NetworkRequest) ProtoMessage()               {}
-func (*NetworkRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*NetworkRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 type NetworkResponse struct {
 	ActiveNetwork uint32 `protobuf:"varint,1,opt,name=active_network,json=activeNetwork" json:"active_network,omitempty"`
@@ -403,7 +502,7 @@ type NetworkResponse struct {
 func (m *NetworkResponse) Reset()                    { *m = NetworkResponse{} }
 func (m *NetworkResponse) String() string            { return proto.CompactTextString(m) }
 func (*NetworkResponse) ProtoMessage()               {}
-func (*NetworkResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*NetworkResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *NetworkResponse) GetActiveNetwork() uint32 {
 	if m != nil {
@@ -419,7 +518,7 @@ type AccountNumberRequest struct {
 func (m *AccountNumberRequest) Reset()                    { *m = AccountNumberRequest{} }
 func (m *AccountNumberRequest) String() string            { return proto.CompactTextString(m) }
 func (*AccountNumberRequest) ProtoMessage()               {}
-func (*AccountNumberRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*AccountNumberRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *AccountNumberRequest) GetAccountName() string {
 	if m != nil {
@@ -435,7 +534,7 @@ type AccountNumberResponse struct {
 func (m *AccountNumberResponse) Reset()                    { *m = AccountNumberResponse{} }
 func (m *AccountNumberResponse) String() string            { return proto.CompactTextString(m) }
 func (*AccountNumberResponse) ProtoMessage()               {}
-func (*AccountNumberResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*AccountNumberResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *AccountNumberResponse) GetAccountNumber() uint32 {
 	if m != nil {
@@ -450,7 +549,7 @@ type AccountsRequest struct {
 func (m *AccountsRequest) Reset()                    { *m = AccountsRequest{} }
 func (m *AccountsRequest) String() string            { return proto.CompactTextString(m) }
 func (*AccountsRequest) ProtoMessage()               {}
-func (*AccountsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*AccountsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 type AccountsResponse struct {
 	Accounts           []*AccountsResponse_Account `protobuf:"bytes,1,rep,name=accounts" json:"accounts,omitempty"`
@@ -461,7 +560,7 @@ type AccountsResponse struct {
 func (m *AccountsResponse) Reset()                    { *m = AccountsResponse{} }
 func (m *AccountsResponse) String() string            { return proto.CompactTextString(m) }
 func (*AccountsResponse) ProtoMessage()               {}
-func (*AccountsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*AccountsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *AccountsResponse) GetAccounts() []*AccountsResponse_Account {
 	if m != nil {
@@ -496,7 +595,7 @@ type AccountsResponse_Account struct {
 func (m *AccountsResponse_Account) Reset()                    { *m = AccountsResponse_Account{} }
 func (m *AccountsResponse_Account) String() string            { return proto.CompactTextString(m) }
 func (*AccountsResponse_Account) ProtoMessage()               {}
-func (*AccountsResponse_Account) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12, 0} }
+func (*AccountsResponse_Account) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13, 0} }
 
 func (m *AccountsResponse_Account) GetAccountNumber() uint32 {
 	if m != nil {
@@ -548,7 +647,7 @@ type RenameAccountRequest struct {
 func (m *RenameAccountRequest) Reset()                    { *m = RenameAccountRequest{} }
 func (m *RenameAccountRequest) String() string            { return proto.CompactTextString(m) }
 func (*RenameAccountRequest) ProtoMessage()               {}
-func (*RenameAccountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*RenameAccountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *RenameAccountRequest) GetAccountNumber() uint32 {
 	if m != nil {
@@ -570,7 +669,7 @@ type RenameAccountResponse struct {
 func (m *RenameAccountResponse) Reset()                    { *m = RenameAccountResponse{} }
 func (m *RenameAccountResponse) String() string            { return proto.CompactTextString(m) }
 func (*RenameAccountResponse) ProtoMessage()               {}
-func (*RenameAccountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*RenameAccountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 type NextAccountRequest struct {
 	Passphrase  []byte `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
@@ -580,7 +679,7 @@ type NextAccountRequest struct {
 func (m *NextAccountRequest) Reset()                    { *m = NextAccountRequest{} }
 func (m *NextAccountRequest) String() string            { return proto.CompactTextString(m) }
 func (*NextAccountRequest) ProtoMessage()               {}
-func (*NextAccountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*NextAccountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 func (m *NextAccountRequest) GetPassphrase() []byte {
 	if m != nil {
@@ -603,7 +702,7 @@ type NextAccountResponse struct {
 func (m *NextAccountResponse) Reset()                    { *m = NextAccountResponse{} }
 func (m *NextAccountResponse) String() string            { return proto.CompactTextString(m) }
 func (*NextAccountResponse) ProtoMessage()               {}
-func (*NextAccountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*NextAccountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *NextAccountResponse) GetAccountNumber() uint32 {
 	if m != nil {
@@ -620,7 +719,7 @@ type NextAddressRequest struct {
 func (m *NextAddressRequest) Reset()                    { *m = NextAddressRequest{} }
 func (m *NextAddressRequest) String() string            { return proto.CompactTextString(m) }
 func (*NextAddressRequest) ProtoMessage()               {}
-func (*NextAddressRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*NextAddressRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *NextAddressRequest) GetAccount() uint32 {
 	if m != nil {
@@ -643,7 +742,7 @@ type NextAddressResponse struct {
 func (m *NextAddressResponse) Reset()                    { *m = NextAddressResponse{} }
 func (m *NextAddressResponse) String() string            { return proto.CompactTextString(m) }
 func (*NextAddressResponse) ProtoMessage()               {}
-func (*NextAddressResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*NextAddressResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *NextAddressResponse) GetAddress() string {
 	if m != nil {
@@ -662,7 +761,7 @@ type ImportPrivateKeyRequest struct {
 func (m *ImportPrivateKeyRequest) Reset()                    { *m = ImportPrivateKeyRequest{} }
 func (m *ImportPrivateKeyRequest) String() string            { return proto.CompactTextString(m) }
 func (*ImportPrivateKeyRequest) ProtoMessage()               {}
-func (*ImportPrivateKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*ImportPrivateKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *ImportPrivateKeyRequest) GetPassphrase() []byte {
 	if m != nil {
@@ -698,7 +797,7 @@ type ImportPrivateKeyResponse struct {
 func (m *ImportPrivateKeyResponse) Reset()                    { *m = ImportPrivateKeyResponse{} }
 func (m *ImportPrivateKeyResponse) String() string            { return proto.CompactTextString(m) }
 func (*ImportPrivateKeyResponse) ProtoMessage()               {}
-func (*ImportPrivateKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*ImportPrivateKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 type BalanceRequest struct {
 	AccountNumber         uint32 `protobuf:"varint,1,opt,name=account_number,json=accountNumber" json:"account_number,omitempty"`
@@ -708,7 +807,7 @@ type BalanceRequest struct {
 func (m *BalanceRequest) Reset()                    { *m = BalanceRequest{} }
 func (m *BalanceRequest) String() string            { return proto.CompactTextString(m) }
 func (*BalanceRequest) ProtoMessage()               {}
-func (*BalanceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*BalanceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *BalanceRequest) GetAccountNumber() uint32 {
 	if m != nil {
@@ -733,7 +832,7 @@ type BalanceResponse struct {
 func (m *BalanceResponse) Reset()                    { *m = BalanceResponse{} }
 func (m *BalanceResponse) String() string            { return proto.CompactTextString(m) }
 func (*BalanceResponse) ProtoMessage()               {}
-func (*BalanceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*BalanceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *BalanceResponse) GetTotal() int64 {
 	if m != nil {
@@ -782,7 +881,7 @@ type GetTransactionsRequest struct {
 func (m *GetTransactionsRequest) Reset()                    { *m = GetTransactionsRequest{} }
 func (m *GetTransactionsRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetTransactionsRequest) ProtoMessage()               {}
-func (*GetTransactionsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*GetTransactionsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *GetTransactionsRequest) GetStartingBlockHash() []byte {
 	if m != nil {
@@ -827,7 +926,7 @@ type GetTransactionsResponse struct {
 func (m *GetTransactionsResponse) Reset()                    { *m = GetTransactionsResponse{} }
 func (m *GetTransactionsResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetTransactionsResponse) ProtoMessage()               {}
-func (*GetTransactionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*GetTransactionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *GetTransactionsResponse) GetMinedTransactions() []*BlockDetails {
 	if m != nil {
@@ -843,270 +942,937 @@ func (m *GetTransactionsResponse) GetUnminedTransactions() []*TransactionDetails
 	return nil
 }
 
-type ChangePassphraseRequest struct {
-	Key           ChangePassphraseRequest_Key `protobuf:"varint,1,opt,name=key,enum=walletrpc.ChangePassphraseRequest_Key" json:"key,omitempty"`
-	OldPassphrase []byte                      `protobuf:"bytes,2,opt,name=old_passphrase,json=oldPassphrase,proto3" json:"old_passphrase,omitempty"`
-	NewPassphrase []byte                      `protobuf:"bytes,3,opt,name=new_passphrase,json=newPassphrase,proto3" json:"new_passphrase,omitempty"`
+type GetRecoveryInfoRequest struct {
 }
 
-func (m *ChangePassphraseRequest) Reset()                    { *m = ChangePassphraseRequest{} }
-func (m *ChangePassphraseRequest) String() string            { return proto.CompactTextString(m) }
-func (*ChangePassphraseRequest) ProtoMessage()               {}
-func (*ChangePassphraseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (m *GetRecoveryInfoRequest) Reset()                    { *m = GetRecoveryInfoRequest{} }
+func (m *GetRecoveryInfoRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRecoveryInfoRequest) ProtoMessage()               {}
+func (*GetRecoveryInfoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
-func (m *ChangePassphraseRequest) GetKey() ChangePassphraseRequest_Key {
+type GetRecoveryInfoResponse struct {
+	// Whether the wallet is recovering the addresses of its seed.
+	RecoveryMode bool `protobuf:"varint,1,opt,name=recovery_mode,json=recoveryMode" json:"recovery_mode,omitempty"`
+	// Whether the recovery scanned through the best block of the chain server.
+	RecoveryFinished bool `protobuf:"varint,2,opt,name=recovery_finished,json=recoveryFinished" json:"recovery_finished,omitempty"`
+	// The address lookahead used by the recovery.
+	RecoveryWindow uint32 `protobuf:"varint,3,opt,name=recovery_window,json=recoveryWindow" json:"recovery_window,omitempty"`
+	// The fraction, between zero and one, of the blocks scanned.
+	Progress float64 `protobuf:"fixed64,4,opt,name=progress" json:"progress,omitempty"`
+}
+
+func (m *GetRecoveryInfoResponse) Reset()                    { *m = GetRecoveryInfoResponse{} }
+func (m *GetRecoveryInfoResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetRecoveryInfoResponse) ProtoMessage()               {}
+func (*GetRecoveryInfoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *GetRecoveryInfoResponse) GetRecoveryMode() bool {
 	if m != nil {
-		return m.Key
+		return m.RecoveryMode
 	}
-	return ChangePassphraseRequest_PRIVATE
+	return false
 }
 
-func (m *ChangePassphraseRequest) GetOldPassphrase() []byte {
+func (m *GetRecoveryInfoResponse) GetRecoveryFinished() bool {
 	if m != nil {
-		return m.OldPassphrase
+		return m.RecoveryFinished
+	}
+	return false
+}
+
+func (m *GetRecoveryInfoResponse) GetRecoveryWindow() uint32 {
+	if m != nil {
+		return m.RecoveryWindow
+	}
+	return 0
+}
+
+func (m *GetRecoveryInfoResponse) GetProgress() float64 {
+	if m != nil {
+		return m.Progress
+	}
+	return 0
+}
+
+type GetTransactionRequest struct {
+	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+}
+
+func (m *GetTransactionRequest) Reset()                    { *m = GetTransactionRequest{} }
+func (m *GetTransactionRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetTransactionRequest) ProtoMessage()               {}
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *GetTransactionRequest) GetTransactionHash() []byte {
+	if m != nil {
+		return m.TransactionHash
 	}
 	return nil
 }
 
-func (m *ChangePassphraseRequest) GetNewPassphrase() []byte {
+type GetTransactionResponse struct {
+	Hash         []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Transaction  []byte `protobuf:"bytes,2,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Label        string `protobuf:"bytes,3,opt,name=label" json:"label,omitempty"`
+	ReceivedTime int64  `protobuf:"varint,4,opt,name=received_time,json=receivedTime" json:"received_time,omitempty"`
+	// The block the transaction is mined in.  The block hash is empty and the
+	// height is -1 for unmined transactions.
+	BlockHash     []byte                           `protobuf:"bytes,5,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockHeight   int32                            `protobuf:"varint,6,opt,name=block_height,json=blockHeight" json:"block_height,omitempty"`
+	BlockTime     int64                            `protobuf:"varint,7,opt,name=block_time,json=blockTime" json:"block_time,omitempty"`
+	Confirmations int32                            `protobuf:"varint,8,opt,name=confirmations" json:"confirmations,omitempty"`
+	Inputs        []*GetTransactionResponse_Input  `protobuf:"bytes,9,rep,name=inputs" json:"inputs,omitempty"`
+	Outputs       []*GetTransactionResponse_Output `protobuf:"bytes,10,rep,name=outputs" json:"outputs,omitempty"`
+	// The fee is only set when fee_known is true.
+	Fee      int64 `protobuf:"varint,11,opt,name=fee" json:"fee,omitempty"`
+	FeeKnown bool  `protobuf:"varint,12,opt,name=fee_known,json=feeKnown" json:"fee_known,omitempty"`
+}
+
+func (m *GetTransactionResponse) Reset()                    { *m = GetTransactionResponse{} }
+func (m *GetTransactionResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetTransactionResponse) ProtoMessage()               {}
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *GetTransactionResponse) GetHash() []byte {
 	if m != nil {
-		return m.NewPassphrase
+		return m.Hash
 	}
 	return nil
 }
 
-type ChangePassphraseResponse struct {
+func (m *GetTransactionResponse) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
 }
 
-func (m *ChangePassphraseResponse) Reset()                    { *m = ChangePassphraseResponse{} }
-func (m *ChangePassphraseResponse) String() string            { return proto.CompactTextString(m) }
-func (*ChangePassphraseResponse) ProtoMessage()               {}
-func (*ChangePassphraseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (m *GetTransactionResponse) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
 
-type FundTransactionRequest struct {
-	Account                  uint32 `protobuf:"varint,1,opt,name=account" json:"account,omitempty"`
-	TargetAmount             int64  `protobuf:"varint,2,opt,name=target_amount,json=targetAmount" json:"target_amount,omitempty"`
-	RequiredConfirmations    int32  `protobuf:"varint,3,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
-	IncludeImmatureCoinbases bool   `protobuf:"varint,4,opt,name=include_immature_coinbases,json=includeImmatureCoinbases" json:"include_immature_coinbases,omitempty"`
-	IncludeChangeScript      bool   `protobuf:"varint,5,opt,name=include_change_script,json=includeChangeScript" json:"include_change_script,omitempty"`
+func (m *GetTransactionResponse) GetReceivedTime() int64 {
+	if m != nil {
+		return m.ReceivedTime
+	}
+	return 0
 }
 
-func (m *FundTransactionRequest) Reset()                    { *m = FundTransactionRequest{} }
-func (m *FundTransactionRequest) String() string            { return proto.CompactTextString(m) }
-func (*FundTransactionRequest) ProtoMessage()               {}
-func (*FundTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (m *GetTransactionResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
 
-func (m *FundTransactionRequest) GetAccount() uint32 {
+func (m *GetTransactionResponse) GetBlockHeight() int32 {
 	if m != nil {
-		return m.Account
+		return m.BlockHeight
 	}
 	return 0
 }
 
-func (m *FundTransactionRequest) GetTargetAmount() int64 {
+func (m *GetTransactionResponse) GetBlockTime() int64 {
 	if m != nil {
-		return m.TargetAmount
+		return m.BlockTime
 	}
 	return 0
 }
 
-func (m *FundTransactionRequest) GetRequiredConfirmations() int32 {
+func (m *GetTransactionResponse) GetConfirmations() int32 {
 	if m != nil {
-		return m.RequiredConfirmations
+		return m.Confirmations
 	}
 	return 0
 }
 
-func (m *FundTransactionRequest) GetIncludeImmatureCoinbases() bool {
+func (m *GetTransactionResponse) GetInputs() []*GetTransactionResponse_Input {
 	if m != nil {
-		return m.IncludeImmatureCoinbases
+		return m.Inputs
 	}
-	return false
+	return nil
 }
 
-func (m *FundTransactionRequest) GetIncludeChangeScript() bool {
+func (m *GetTransactionResponse) GetOutputs() []*GetTransactionResponse_Output {
 	if m != nil {
-		return m.IncludeChangeScript
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *GetTransactionResponse) GetFee() int64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *GetTransactionResponse) GetFeeKnown() bool {
+	if m != nil {
+		return m.FeeKnown
 	}
 	return false
 }
 
-type FundTransactionResponse struct {
-	SelectedOutputs []*FundTransactionResponse_PreviousOutput `protobuf:"bytes,1,rep,name=selected_outputs,json=selectedOutputs" json:"selected_outputs,omitempty"`
-	TotalAmount     int64                                     `protobuf:"varint,2,opt,name=total_amount,json=totalAmount" json:"total_amount,omitempty"`
-	ChangePkScript  []byte                                    `protobuf:"bytes,3,opt,name=change_pk_script,json=changePkScript,proto3" json:"change_pk_script,omitempty"`
+type GetTransactionResponse_Input struct {
+	PreviousOutput *OutPoint `protobuf:"bytes,1,opt,name=previous_output,json=previousOutput" json:"previous_output,omitempty"`
+	// The previous amount is only set when amount_known is true, and the
+	// account only when mine is true.
+	PreviousAmount int64  `protobuf:"varint,2,opt,name=previous_amount,json=previousAmount" json:"previous_amount,omitempty"`
+	AmountKnown    bool   `protobuf:"varint,3,opt,name=amount_known,json=amountKnown" json:"amount_known,omitempty"`
+	Mine           bool   `protobuf:"varint,4,opt,name=mine" json:"mine,omitempty"`
+	Account        uint32 `protobuf:"varint,5,opt,name=account" json:"account,omitempty"`
 }
 
-func (m *FundTransactionResponse) Reset()                    { *m = FundTransactionResponse{} }
-func (m *FundTransactionResponse) String() string            { return proto.CompactTextString(m) }
-func (*FundTransactionResponse) ProtoMessage()               {}
-func (*FundTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (m *GetTransactionResponse_Input) Reset()         { *m = GetTransactionResponse_Input{} }
+func (m *GetTransactionResponse_Input) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionResponse_Input) ProtoMessage()    {}
+func (*GetTransactionResponse_Input) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{29, 0}
+}
 
-func (m *FundTransactionResponse) GetSelectedOutputs() []*FundTransactionResponse_PreviousOutput {
+func (m *GetTransactionResponse_Input) GetPreviousOutput() *OutPoint {
 	if m != nil {
-		return m.SelectedOutputs
+		return m.PreviousOutput
 	}
 	return nil
 }
 
-func (m *FundTransactionResponse) GetTotalAmount() int64 {
+func (m *GetTransactionResponse_Input) GetPreviousAmount() int64 {
 	if m != nil {
-		return m.TotalAmount
+		return m.PreviousAmount
 	}
 	return 0
 }
 
-func (m *FundTransactionResponse) GetChangePkScript() []byte {
+func (m *GetTransactionResponse_Input) GetAmountKnown() bool {
 	if m != nil {
-		return m.ChangePkScript
+		return m.AmountKnown
 	}
-	return nil
+	return false
 }
 
-type FundTransactionResponse_PreviousOutput struct {
-	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
-	OutputIndex     uint32 `protobuf:"varint,2,opt,name=output_index,json=outputIndex" json:"output_index,omitempty"`
-	Amount          int64  `protobuf:"varint,3,opt,name=amount" json:"amount,omitempty"`
-	PkScript        []byte `protobuf:"bytes,4,opt,name=pk_script,json=pkScript,proto3" json:"pk_script,omitempty"`
-	ReceiveTime     int64  `protobuf:"varint,5,opt,name=receive_time,json=receiveTime" json:"receive_time,omitempty"`
-	FromCoinbase    bool   `protobuf:"varint,6,opt,name=from_coinbase,json=fromCoinbase" json:"from_coinbase,omitempty"`
+func (m *GetTransactionResponse_Input) GetMine() bool {
+	if m != nil {
+		return m.Mine
+	}
+	return false
 }
 
-func (m *FundTransactionResponse_PreviousOutput) Reset() {
-	*m = FundTransactionResponse_PreviousOutput{}
+func (m *GetTransactionResponse_Input) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
 }
-func (m *FundTransactionResponse_PreviousOutput) String() string { return proto.CompactTextString(m) }
-func (*FundTransactionResponse_PreviousOutput) ProtoMessage()    {}
-func (*FundTransactionResponse_PreviousOutput) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{28, 0}
+
+type GetTransactionResponse_Output struct {
+	Index     uint32   `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Amount    int64    `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+	PkScript  []byte   `protobuf:"bytes,3,opt,name=pk_script,json=pkScript,proto3" json:"pk_script,omitempty"`
+	Addresses []string `protobuf:"bytes,4,rep,name=addresses" json:"addresses,omitempty"`
+	// The account and branch are only set for wallet outputs.
+	Mine     bool   `protobuf:"varint,5,opt,name=mine" json:"mine,omitempty"`
+	Account  uint32 `protobuf:"varint,6,opt,name=account" json:"account,omitempty"`
+	Internal bool   `protobuf:"varint,7,opt,name=internal" json:"internal,omitempty"`
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetTransactionHash() []byte {
-	if m != nil {
-		return m.TransactionHash
-	}
-	return nil
+func (m *GetTransactionResponse_Output) Reset()         { *m = GetTransactionResponse_Output{} }
+func (m *GetTransactionResponse_Output) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionResponse_Output) ProtoMessage()    {}
+func (*GetTransactionResponse_Output) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{29, 1}
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetOutputIndex() uint32 {
+func (m *GetTransactionResponse_Output) GetIndex() uint32 {
 	if m != nil {
-		return m.OutputIndex
+		return m.Index
 	}
 	return 0
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetAmount() int64 {
+func (m *GetTransactionResponse_Output) GetAmount() int64 {
 	if m != nil {
 		return m.Amount
 	}
 	return 0
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetPkScript() []byte {
+func (m *GetTransactionResponse_Output) GetPkScript() []byte {
 	if m != nil {
 		return m.PkScript
 	}
 	return nil
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetReceiveTime() int64 {
+func (m *GetTransactionResponse_Output) GetAddresses() []string {
 	if m != nil {
-		return m.ReceiveTime
+		return m.Addresses
 	}
-	return 0
+	return nil
 }
 
-func (m *FundTransactionResponse_PreviousOutput) GetFromCoinbase() bool {
+func (m *GetTransactionResponse_Output) GetMine() bool {
 	if m != nil {
-		return m.FromCoinbase
+		return m.Mine
 	}
 	return false
 }
 
-type SignTransactionRequest struct {
-	Passphrase            []byte `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
-	SerializedTransaction []byte `protobuf:"bytes,2,opt,name=serialized_transaction,json=serializedTransaction,proto3" json:"serialized_transaction,omitempty"`
-	// If no indexes are specified, signatures scripts will be added for
-	// every input. If any input indexes are specified, only those inputs
-	// will be signed.  Rather than returning an incompletely signed
-	// transaction if any of the inputs to be signed can not be, the RPC
-	// immediately errors.
-	InputIndexes []uint32 `protobuf:"varint,3,rep,packed,name=input_indexes,json=inputIndexes" json:"input_indexes,omitempty"`
+func (m *GetTransactionResponse_Output) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
 }
 
-func (m *SignTransactionRequest) Reset()                    { *m = SignTransactionRequest{} }
-func (m *SignTransactionRequest) String() string            { return proto.CompactTextString(m) }
-func (*SignTransactionRequest) ProtoMessage()               {}
-func (*SignTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
-
-func (m *SignTransactionRequest) GetPassphrase() []byte {
+func (m *GetTransactionResponse_Output) GetInternal() bool {
 	if m != nil {
-		return m.Passphrase
+		return m.Internal
 	}
-	return nil
+	return false
 }
 
-func (m *SignTransactionRequest) GetSerializedTransaction() []byte {
+type ListLockedOutputsRequest struct {
+}
+
+func (m *ListLockedOutputsRequest) Reset()                    { *m = ListLockedOutputsRequest{} }
+func (m *ListLockedOutputsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListLockedOutputsRequest) ProtoMessage()               {}
+func (*ListLockedOutputsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+type ListLockedOutputsResponse struct {
+	Outputs []*OutPoint `protobuf:"bytes,1,rep,name=outputs" json:"outputs,omitempty"`
+}
+
+func (m *ListLockedOutputsResponse) Reset()                    { *m = ListLockedOutputsResponse{} }
+func (m *ListLockedOutputsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListLockedOutputsResponse) ProtoMessage()               {}
+func (*ListLockedOutputsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *ListLockedOutputsResponse) GetOutputs() []*OutPoint {
 	if m != nil {
-		return m.SerializedTransaction
+		return m.Outputs
 	}
 	return nil
 }
 
-func (m *SignTransactionRequest) GetInputIndexes() []uint32 {
+type ValidateAddressRequest struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+}
+
+func (m *ValidateAddressRequest) Reset()                    { *m = ValidateAddressRequest{} }
+func (m *ValidateAddressRequest) String() string            { return proto.CompactTextString(m) }
+func (*ValidateAddressRequest) ProtoMessage()               {}
+func (*ValidateAddressRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *ValidateAddressRequest) GetAddress() string {
 	if m != nil {
-		return m.InputIndexes
+		return m.Address
 	}
-	return nil
+	return ""
 }
 
-type SignTransactionResponse struct {
-	Transaction          []byte   `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
-	UnsignedInputIndexes []uint32 `protobuf:"varint,2,rep,packed,name=unsigned_input_indexes,json=unsignedInputIndexes" json:"unsigned_input_indexes,omitempty"`
+type ValidateAddressResponse struct {
+	IsValid bool `protobuf:"varint,1,opt,name=is_valid,json=isValid" json:"is_valid,omitempty"`
+	IsMine  bool `protobuf:"varint,2,opt,name=is_mine,json=isMine" json:"is_mine,omitempty"`
+	// The following fields are only set for wallet addresses.
+	Account    uint32 `protobuf:"varint,3,opt,name=account" json:"account,omitempty"`
+	IsInternal bool   `protobuf:"varint,4,opt,name=is_internal,json=isInternal" json:"is_internal,omitempty"`
+	IsScript   bool   `protobuf:"varint,5,opt,name=is_script,json=isScript" json:"is_script,omitempty"`
+	PubKey     []byte `protobuf:"bytes,6,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// The script of a script address is only known when the wallet is unlocked.
+	Script []byte `protobuf:"bytes,7,opt,name=script,proto3" json:"script,omitempty"`
 }
 
-func (m *SignTransactionResponse) Reset()                    { *m = SignTransactionResponse{} }
-func (m *SignTransactionResponse) String() string            { return proto.CompactTextString(m) }
-func (*SignTransactionResponse) ProtoMessage()               {}
-func (*SignTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (m *ValidateAddressResponse) Reset()                    { *m = ValidateAddressResponse{} }
+func (m *ValidateAddressResponse) String() string            { return proto.CompactTextString(m) }
+func (*ValidateAddressResponse) ProtoMessage()               {}
+func (*ValidateAddressResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
-func (m *SignTransactionResponse) GetTransaction() []byte {
+func (m *ValidateAddressResponse) GetIsValid() bool {
 	if m != nil {
-		return m.Transaction
+		return m.IsValid
 	}
-	return nil
+	return false
 }
 
-func (m *SignTransactionResponse) GetUnsignedInputIndexes() []uint32 {
+func (m *ValidateAddressResponse) GetIsMine() bool {
 	if m != nil {
-		return m.UnsignedInputIndexes
+		return m.IsMine
 	}
-	return nil
+	return false
 }
 
-type PublishTransactionRequest struct {
-	SignedTransaction []byte `protobuf:"bytes,1,opt,name=signed_transaction,json=signedTransaction,proto3" json:"signed_transaction,omitempty"`
+func (m *ValidateAddressResponse) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
 }
 
-func (m *PublishTransactionRequest) Reset()                    { *m = PublishTransactionRequest{} }
-func (m *PublishTransactionRequest) String() string            { return proto.CompactTextString(m) }
-func (*PublishTransactionRequest) ProtoMessage()               {}
-func (*PublishTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+func (m *ValidateAddressResponse) GetIsInternal() bool {
+	if m != nil {
+		return m.IsInternal
+	}
+	return false
+}
 
-func (m *PublishTransactionRequest) GetSignedTransaction() []byte {
+func (m *ValidateAddressResponse) GetIsScript() bool {
 	if m != nil {
-		return m.SignedTransaction
+		return m.IsScript
 	}
-	return nil
+	return false
 }
 
-type PublishTransactionResponse struct {
+func (m *ValidateAddressResponse) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *ValidateAddressResponse) GetScript() []byte {
+	if m != nil {
+		return m.Script
+	}
+	return nil
+}
+
+type VerifyMessageRequest struct {
+	Address   string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Message   string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *VerifyMessageRequest) Reset()                    { *m = VerifyMessageRequest{} }
+func (m *VerifyMessageRequest) String() string            { return proto.CompactTextString(m) }
+func (*VerifyMessageRequest) ProtoMessage()               {}
+func (*VerifyMessageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *VerifyMessageRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *VerifyMessageRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *VerifyMessageRequest) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type VerifyMessageResponse struct {
+	Valid bool `protobuf:"varint,1,opt,name=valid" json:"valid,omitempty"`
+}
+
+func (m *VerifyMessageResponse) Reset()                    { *m = VerifyMessageResponse{} }
+func (m *VerifyMessageResponse) String() string            { return proto.CompactTextString(m) }
+func (*VerifyMessageResponse) ProtoMessage()               {}
+func (*VerifyMessageResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *VerifyMessageResponse) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+type ChangePassphraseRequest struct {
+	Key           ChangePassphraseRequest_Key `protobuf:"varint,1,opt,name=key,enum=walletrpc.ChangePassphraseRequest_Key" json:"key,omitempty"`
+	OldPassphrase []byte                      `protobuf:"bytes,2,opt,name=old_passphrase,json=oldPassphrase,proto3" json:"old_passphrase,omitempty"`
+	NewPassphrase []byte                      `protobuf:"bytes,3,opt,name=new_passphrase,json=newPassphrase,proto3" json:"new_passphrase,omitempty"`
+}
+
+func (m *ChangePassphraseRequest) Reset()                    { *m = ChangePassphraseRequest{} }
+func (m *ChangePassphraseRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChangePassphraseRequest) ProtoMessage()               {}
+func (*ChangePassphraseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *ChangePassphraseRequest) GetKey() ChangePassphraseRequest_Key {
+	if m != nil {
+		return m.Key
+	}
+	return ChangePassphraseRequest_PRIVATE
+}
+
+func (m *ChangePassphraseRequest) GetOldPassphrase() []byte {
+	if m != nil {
+		return m.OldPassphrase
+	}
+	return nil
+}
+
+func (m *ChangePassphraseRequest) GetNewPassphrase() []byte {
+	if m != nil {
+		return m.NewPassphrase
+	}
+	return nil
+}
+
+type ChangePassphraseResponse struct {
+}
+
+func (m *ChangePassphraseResponse) Reset()                    { *m = ChangePassphraseResponse{} }
+func (m *ChangePassphraseResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChangePassphraseResponse) ProtoMessage()               {}
+func (*ChangePassphraseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+type FundTransactionRequest struct {
+	Account                  uint32 `protobuf:"varint,1,opt,name=account" json:"account,omitempty"`
+	TargetAmount             int64  `protobuf:"varint,2,opt,name=target_amount,json=targetAmount" json:"target_amount,omitempty"`
+	RequiredConfirmations    int32  `protobuf:"varint,3,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+	IncludeImmatureCoinbases bool   `protobuf:"varint,4,opt,name=include_immature_coinbases,json=includeImmatureCoinbases" json:"include_immature_coinbases,omitempty"`
+	IncludeChangeScript      bool   `protobuf:"varint,5,opt,name=include_change_script,json=includeChangeScript" json:"include_change_script,omitempty"`
+	OldestFirst              bool   `protobuf:"varint,6,opt,name=oldest_first,json=oldestFirst" json:"oldest_first,omitempty"`
+}
+
+func (m *FundTransactionRequest) Reset()                    { *m = FundTransactionRequest{} }
+func (m *FundTransactionRequest) String() string            { return proto.CompactTextString(m) }
+func (*FundTransactionRequest) ProtoMessage()               {}
+func (*FundTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *FundTransactionRequest) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
+}
+
+func (m *FundTransactionRequest) GetTargetAmount() int64 {
+	if m != nil {
+		return m.TargetAmount
+	}
+	return 0
+}
+
+func (m *FundTransactionRequest) GetRequiredConfirmations() int32 {
+	if m != nil {
+		return m.RequiredConfirmations
+	}
+	return 0
+}
+
+func (m *FundTransactionRequest) GetIncludeImmatureCoinbases() bool {
+	if m != nil {
+		return m.IncludeImmatureCoinbases
+	}
+	return false
+}
+
+func (m *FundTransactionRequest) GetIncludeChangeScript() bool {
+	if m != nil {
+		return m.IncludeChangeScript
+	}
+	return false
+}
+
+func (m *FundTransactionRequest) GetOldestFirst() bool {
+	if m != nil {
+		return m.OldestFirst
+	}
+	return false
+}
+
+type FundTransactionResponse struct {
+	SelectedOutputs []*FundTransactionResponse_PreviousOutput `protobuf:"bytes,1,rep,name=selected_outputs,json=selectedOutputs" json:"selected_outputs,omitempty"`
+	TotalAmount     int64                                     `protobuf:"varint,2,opt,name=total_amount,json=totalAmount" json:"total_amount,omitempty"`
+	ChangePkScript  []byte                                    `protobuf:"bytes,3,opt,name=change_pk_script,json=changePkScript,proto3" json:"change_pk_script,omitempty"`
+}
+
+func (m *FundTransactionResponse) Reset()                    { *m = FundTransactionResponse{} }
+func (m *FundTransactionResponse) String() string            { return proto.CompactTextString(m) }
+func (*FundTransactionResponse) ProtoMessage()               {}
+func (*FundTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *FundTransactionResponse) GetSelectedOutputs() []*FundTransactionResponse_PreviousOutput {
+	if m != nil {
+		return m.SelectedOutputs
+	}
+	return nil
+}
+
+func (m *FundTransactionResponse) GetTotalAmount() int64 {
+	if m != nil {
+		return m.TotalAmount
+	}
+	return 0
+}
+
+func (m *FundTransactionResponse) GetChangePkScript() []byte {
+	if m != nil {
+		return m.ChangePkScript
+	}
+	return nil
+}
+
+type FundTransactionResponse_PreviousOutput struct {
+	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	OutputIndex     uint32 `protobuf:"varint,2,opt,name=output_index,json=outputIndex" json:"output_index,omitempty"`
+	Amount          int64  `protobuf:"varint,3,opt,name=amount" json:"amount,omitempty"`
+	PkScript        []byte `protobuf:"bytes,4,opt,name=pk_script,json=pkScript,proto3" json:"pk_script,omitempty"`
+	ReceiveTime     int64  `protobuf:"varint,5,opt,name=receive_time,json=receiveTime" json:"receive_time,omitempty"`
+	FromCoinbase    bool   `protobuf:"varint,6,opt,name=from_coinbase,json=fromCoinbase" json:"from_coinbase,omitempty"`
+}
+
+func (m *FundTransactionResponse_PreviousOutput) Reset() {
+	*m = FundTransactionResponse_PreviousOutput{}
+}
+func (m *FundTransactionResponse_PreviousOutput) String() string { return proto.CompactTextString(m) }
+func (*FundTransactionResponse_PreviousOutput) ProtoMessage()    {}
+func (*FundTransactionResponse_PreviousOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{39, 0}
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetTransactionHash() []byte {
+	if m != nil {
+		return m.TransactionHash
+	}
+	return nil
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetOutputIndex() uint32 {
+	if m != nil {
+		return m.OutputIndex
+	}
+	return 0
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetPkScript() []byte {
+	if m != nil {
+		return m.PkScript
+	}
+	return nil
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetReceiveTime() int64 {
+	if m != nil {
+		return m.ReceiveTime
+	}
+	return 0
+}
+
+func (m *FundTransactionResponse_PreviousOutput) GetFromCoinbase() bool {
+	if m != nil {
+		return m.FromCoinbase
+	}
+	return false
+}
+
+type SignTransactionRequest struct {
+	Passphrase            []byte `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	SerializedTransaction []byte `protobuf:"bytes,2,opt,name=serialized_transaction,json=serializedTransaction,proto3" json:"serialized_transaction,omitempty"`
+	// If no indexes are specified, signatures scripts will be added for
+	// every input. If any input indexes are specified, only those inputs
+	// will be signed.  Rather than returning an incompletely signed
+	// transaction if any of the inputs to be signed can not be, the RPC
+	// immediately errors.
+	InputIndexes []uint32 `protobuf:"varint,3,rep,packed,name=input_indexes,json=inputIndexes" json:"input_indexes,omitempty"`
+}
+
+func (m *SignTransactionRequest) Reset()                    { *m = SignTransactionRequest{} }
+func (m *SignTransactionRequest) String() string            { return proto.CompactTextString(m) }
+func (*SignTransactionRequest) ProtoMessage()               {}
+func (*SignTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *SignTransactionRequest) GetPassphrase() []byte {
+	if m != nil {
+		return m.Passphrase
+	}
+	return nil
+}
+
+func (m *SignTransactionRequest) GetSerializedTransaction() []byte {
+	if m != nil {
+		return m.SerializedTransaction
+	}
+	return nil
+}
+
+func (m *SignTransactionRequest) GetInputIndexes() []uint32 {
+	if m != nil {
+		return m.InputIndexes
+	}
+	return nil
+}
+
+type SignTransactionResponse struct {
+	Transaction          []byte   `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	UnsignedInputIndexes []uint32 `protobuf:"varint,2,rep,packed,name=unsigned_input_indexes,json=unsignedInputIndexes" json:"unsigned_input_indexes,omitempty"`
+}
+
+func (m *SignTransactionResponse) Reset()                    { *m = SignTransactionResponse{} }
+func (m *SignTransactionResponse) String() string            { return proto.CompactTextString(m) }
+func (*SignTransactionResponse) ProtoMessage()               {}
+func (*SignTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *SignTransactionResponse) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *SignTransactionResponse) GetUnsignedInputIndexes() []uint32 {
+	if m != nil {
+		return m.UnsignedInputIndexes
+	}
+	return nil
+}
+
+type PublishTransactionRequest struct {
+	SignedTransaction []byte `protobuf:"bytes,1,opt,name=signed_transaction,json=signedTransaction,proto3" json:"signed_transaction,omitempty"`
+}
+
+func (m *PublishTransactionRequest) Reset()                    { *m = PublishTransactionRequest{} }
+func (m *PublishTransactionRequest) String() string            { return proto.CompactTextString(m) }
+func (*PublishTransactionRequest) ProtoMessage()               {}
+func (*PublishTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *PublishTransactionRequest) GetSignedTransaction() []byte {
+	if m != nil {
+		return m.SignedTransaction
+	}
+	return nil
+}
+
+type PublishTransactionResponse struct {
 }
 
 func (m *PublishTransactionResponse) Reset()                    { *m = PublishTransactionResponse{} }
 func (m *PublishTransactionResponse) String() string            { return proto.CompactTextString(m) }
 func (*PublishTransactionResponse) ProtoMessage()               {}
-func (*PublishTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+func (*PublishTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+type SendOutputsRequest struct {
+	Passphrase            []byte                       `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Account               uint32                       `protobuf:"varint,2,opt,name=account" json:"account,omitempty"`
+	Outputs               []*SendOutputsRequest_Output `protobuf:"bytes,3,rep,name=outputs" json:"outputs,omitempty"`
+	RequiredConfirmations int32                        `protobuf:"varint,4,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+	// The fee rate in satoshis per kilobyte.  The fee rate estimated by the
+	// chain server is used when zero.
+	FeeRate int64 `protobuf:"varint,5,opt,name=fee_rate,json=feeRate" json:"fee_rate,omitempty"`
+}
+
+func (m *SendOutputsRequest) Reset()                    { *m = SendOutputsRequest{} }
+func (m *SendOutputsRequest) String() string            { return proto.CompactTextString(m) }
+func (*SendOutputsRequest) ProtoMessage()               {}
+func (*SendOutputsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *SendOutputsRequest) GetPassphrase() []byte {
+	if m != nil {
+		return m.Passphrase
+	}
+	return nil
+}
+
+func (m *SendOutputsRequest) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
+}
+
+func (m *SendOutputsRequest) GetOutputs() []*SendOutputsRequest_Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *SendOutputsRequest) GetRequiredConfirmations() int32 {
+	if m != nil {
+		return m.RequiredConfirmations
+	}
+	return 0
+}
+
+func (m *SendOutputsRequest) GetFeeRate() int64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+type SendOutputsRequest_Output struct {
+	PkScript []byte `protobuf:"bytes,1,opt,name=pk_script,json=pkScript,proto3" json:"pk_script,omitempty"`
+	Amount   int64  `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+}
+
+func (m *SendOutputsRequest_Output) Reset()                    { *m = SendOutputsRequest_Output{} }
+func (m *SendOutputsRequest_Output) String() string            { return proto.CompactTextString(m) }
+func (*SendOutputsRequest_Output) ProtoMessage()               {}
+func (*SendOutputsRequest_Output) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44, 0} }
+
+func (m *SendOutputsRequest_Output) GetPkScript() []byte {
+	if m != nil {
+		return m.PkScript
+	}
+	return nil
+}
+
+func (m *SendOutputsRequest_Output) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+type SendOutputsResponse struct {
+	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+}
+
+func (m *SendOutputsResponse) Reset()                    { *m = SendOutputsResponse{} }
+func (m *SendOutputsResponse) String() string            { return proto.CompactTextString(m) }
+func (*SendOutputsResponse) ProtoMessage()               {}
+func (*SendOutputsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *SendOutputsResponse) GetTransactionHash() []byte {
+	if m != nil {
+		return m.TransactionHash
+	}
+	return nil
+}
+
+type LockOutputsRequest struct {
+	Outputs []*OutPoint `protobuf:"bytes,1,rep,name=outputs" json:"outputs,omitempty"`
+	// Unlocking without specifying outputs unlocks all locked outputs.
+	Unlock bool `protobuf:"varint,2,opt,name=unlock" json:"unlock,omitempty"`
+}
+
+func (m *LockOutputsRequest) Reset()                    { *m = LockOutputsRequest{} }
+func (m *LockOutputsRequest) String() string            { return proto.CompactTextString(m) }
+func (*LockOutputsRequest) ProtoMessage()               {}
+func (*LockOutputsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *LockOutputsRequest) GetOutputs() []*OutPoint {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *LockOutputsRequest) GetUnlock() bool {
+	if m != nil {
+		return m.Unlock
+	}
+	return false
+}
+
+type LockOutputsResponse struct {
+}
+
+func (m *LockOutputsResponse) Reset()                    { *m = LockOutputsResponse{} }
+func (m *LockOutputsResponse) String() string            { return proto.CompactTextString(m) }
+func (*LockOutputsResponse) ProtoMessage()               {}
+func (*LockOutputsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+type SignMessageRequest struct {
+	Passphrase []byte `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Address    string `protobuf:"bytes,2,opt,name=address" json:"address,omitempty"`
+	Message    string `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *SignMessageRequest) Reset()                    { *m = SignMessageRequest{} }
+func (m *SignMessageRequest) String() string            { return proto.CompactTextString(m) }
+func (*SignMessageRequest) ProtoMessage()               {}
+func (*SignMessageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *SignMessageRequest) GetPassphrase() []byte {
+	if m != nil {
+		return m.Passphrase
+	}
+	return nil
+}
+
+func (m *SignMessageRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *SignMessageRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type SignMessageResponse struct {
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *SignMessageResponse) Reset()                    { *m = SignMessageResponse{} }
+func (m *SignMessageResponse) String() string            { return proto.CompactTextString(m) }
+func (*SignMessageResponse) ProtoMessage()               {}
+func (*SignMessageResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *SignMessageResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type DumpPrivateKeyRequest struct {
+	Passphrase []byte `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Address    string `protobuf:"bytes,2,opt,name=address" json:"address,omitempty"`
+}
+
+func (m *DumpPrivateKeyRequest) Reset()                    { *m = DumpPrivateKeyRequest{} }
+func (m *DumpPrivateKeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*DumpPrivateKeyRequest) ProtoMessage()               {}
+func (*DumpPrivateKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *DumpPrivateKeyRequest) GetPassphrase() []byte {
+	if m != nil {
+		return m.Passphrase
+	}
+	return nil
+}
+
+func (m *DumpPrivateKeyRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type DumpPrivateKeyResponse struct {
+	PrivateKeyWif string `protobuf:"bytes,1,opt,name=private_key_wif,json=privateKeyWif" json:"private_key_wif,omitempty"`
+}
+
+func (m *DumpPrivateKeyResponse) Reset()                    { *m = DumpPrivateKeyResponse{} }
+func (m *DumpPrivateKeyResponse) String() string            { return proto.CompactTextString(m) }
+func (*DumpPrivateKeyResponse) ProtoMessage()               {}
+func (*DumpPrivateKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *DumpPrivateKeyResponse) GetPrivateKeyWif() string {
+	if m != nil {
+		return m.PrivateKeyWif
+	}
+	return ""
+}
 
 type TransactionNotificationsRequest struct {
 }
@@ -1115,7 +1881,7 @@ func (m *TransactionNotificationsRequest) Reset()         { *m = TransactionNoti
 func (m *TransactionNotificationsRequest) String() string { return proto.CompactTextString(m) }
 func (*TransactionNotificationsRequest) ProtoMessage()    {}
 func (*TransactionNotificationsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{33}
+	return fileDescriptor0, []int{52}
 }
 
 type TransactionNotificationsResponse struct {
@@ -1134,44 +1900,132 @@ type TransactionNotificationsResponse struct {
 	UnminedTransactions []*TransactionDetails `protobuf:"bytes,3,rep,name=unmined_transactions,json=unminedTransactions" json:"unmined_transactions,omitempty"`
 	// Instead of notifying all of the removed unmined transactions,
 	// just send all of the current hashes.
-	UnminedTransactionHashes [][]byte `protobuf:"bytes,4,rep,name=unmined_transaction_hashes,json=unminedTransactionHashes,proto3" json:"unmined_transaction_hashes,omitempty"`
+	UnminedTransactionHashes [][]byte                                                  `protobuf:"bytes,4,rep,name=unmined_transaction_hashes,json=unminedTransactionHashes,proto3" json:"unmined_transaction_hashes,omitempty"`
+	ConflictedTransactions   []*TransactionNotificationsResponse_ConflictedTransaction `protobuf:"bytes,5,rep,name=conflicted_transactions,json=conflictedTransactions" json:"conflicted_transactions,omitempty"`
+	ReorgedTransactions      []*TransactionNotificationsResponse_ReorgedTransaction    `protobuf:"bytes,6,rep,name=reorged_transactions,json=reorgedTransactions" json:"reorged_transactions,omitempty"`
+}
+
+func (m *TransactionNotificationsResponse) Reset()         { *m = TransactionNotificationsResponse{} }
+func (m *TransactionNotificationsResponse) String() string { return proto.CompactTextString(m) }
+func (*TransactionNotificationsResponse) ProtoMessage()    {}
+func (*TransactionNotificationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{53}
+}
+
+func (m *TransactionNotificationsResponse) GetAttachedBlocks() []*BlockDetails {
+	if m != nil {
+		return m.AttachedBlocks
+	}
+	return nil
+}
+
+func (m *TransactionNotificationsResponse) GetDetachedBlocks() [][]byte {
+	if m != nil {
+		return m.DetachedBlocks
+	}
+	return nil
+}
+
+func (m *TransactionNotificationsResponse) GetUnminedTransactions() []*TransactionDetails {
+	if m != nil {
+		return m.UnminedTransactions
+	}
+	return nil
+}
+
+func (m *TransactionNotificationsResponse) GetUnminedTransactionHashes() [][]byte {
+	if m != nil {
+		return m.UnminedTransactionHashes
+	}
+	return nil
+}
+
+func (m *TransactionNotificationsResponse) GetConflictedTransactions() []*TransactionNotificationsResponse_ConflictedTransaction {
+	if m != nil {
+		return m.ConflictedTransactions
+	}
+	return nil
+}
+
+func (m *TransactionNotificationsResponse) GetReorgedTransactions() []*TransactionNotificationsResponse_ReorgedTransaction {
+	if m != nil {
+		return m.ReorgedTransactions
+	}
+	return nil
 }
 
-func (m *TransactionNotificationsResponse) Reset()         { *m = TransactionNotificationsResponse{} }
-func (m *TransactionNotificationsResponse) String() string { return proto.CompactTextString(m) }
-func (*TransactionNotificationsResponse) ProtoMessage()    {}
-func (*TransactionNotificationsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{34}
+// Unmined transactions that were double spent by a transaction in an
+// attached block or by a newly seen unmined transaction.
+type TransactionNotificationsResponse_ConflictedTransaction struct {
+	Hash            []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	ConflictingHash []byte `protobuf:"bytes,2,opt,name=conflicting_hash,json=conflictingHash,proto3" json:"conflicting_hash,omitempty"`
+	Mined           bool   `protobuf:"varint,3,opt,name=mined" json:"mined,omitempty"`
 }
 
-func (m *TransactionNotificationsResponse) GetAttachedBlocks() []*BlockDetails {
+func (m *TransactionNotificationsResponse_ConflictedTransaction) Reset() {
+	*m = TransactionNotificationsResponse_ConflictedTransaction{}
+}
+func (m *TransactionNotificationsResponse_ConflictedTransaction) String() string {
+	return proto.CompactTextString(m)
+}
+func (*TransactionNotificationsResponse_ConflictedTransaction) ProtoMessage() {}
+func (*TransactionNotificationsResponse_ConflictedTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{53, 0}
+}
+
+func (m *TransactionNotificationsResponse_ConflictedTransaction) GetHash() []byte {
 	if m != nil {
-		return m.AttachedBlocks
+		return m.Hash
 	}
 	return nil
 }
 
-func (m *TransactionNotificationsResponse) GetDetachedBlocks() [][]byte {
+func (m *TransactionNotificationsResponse_ConflictedTransaction) GetConflictingHash() []byte {
 	if m != nil {
-		return m.DetachedBlocks
+		return m.ConflictingHash
 	}
 	return nil
 }
 
-func (m *TransactionNotificationsResponse) GetUnminedTransactions() []*TransactionDetails {
+func (m *TransactionNotificationsResponse_ConflictedTransaction) GetMined() bool {
 	if m != nil {
-		return m.UnminedTransactions
+		return m.Mined
 	}
-	return nil
+	return false
 }
 
-func (m *TransactionNotificationsResponse) GetUnminedTransactionHashes() [][]byte {
+// Wallet transactions that were mined in a detached block and not mined
+// again in an attached block, with their status in the new best chain.
+type TransactionNotificationsResponse_ReorgedTransaction struct {
+	Hash   []byte                                                     `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Status TransactionNotificationsResponse_ReorgedTransaction_Status `protobuf:"varint,2,opt,name=status,enum=walletrpc.TransactionNotificationsResponse_ReorgedTransaction_Status" json:"status,omitempty"`
+}
+
+func (m *TransactionNotificationsResponse_ReorgedTransaction) Reset() {
+	*m = TransactionNotificationsResponse_ReorgedTransaction{}
+}
+func (m *TransactionNotificationsResponse_ReorgedTransaction) String() string {
+	return proto.CompactTextString(m)
+}
+func (*TransactionNotificationsResponse_ReorgedTransaction) ProtoMessage() {}
+func (*TransactionNotificationsResponse_ReorgedTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{53, 1}
+}
+
+func (m *TransactionNotificationsResponse_ReorgedTransaction) GetHash() []byte {
 	if m != nil {
-		return m.UnminedTransactionHashes
+		return m.Hash
 	}
 	return nil
 }
 
+func (m *TransactionNotificationsResponse_ReorgedTransaction) GetStatus() TransactionNotificationsResponse_ReorgedTransaction_Status {
+	if m != nil {
+		return m.Status
+	}
+	return TransactionNotificationsResponse_ReorgedTransaction_UNMINED
+}
+
 type SpentnessNotificationsRequest struct {
 	Account         uint32 `protobuf:"varint,1,opt,name=account" json:"account,omitempty"`
 	NoNotifyUnspent bool   `protobuf:"varint,2,opt,name=no_notify_unspent,json=noNotifyUnspent" json:"no_notify_unspent,omitempty"`
@@ -1181,7 +2035,7 @@ type SpentnessNotificationsRequest struct {
 func (m *SpentnessNotificationsRequest) Reset()                    { *m = SpentnessNotificationsRequest{} }
 func (m *SpentnessNotificationsRequest) String() string            { return proto.CompactTextString(m) }
 func (*SpentnessNotificationsRequest) ProtoMessage()               {}
-func (*SpentnessNotificationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+func (*SpentnessNotificationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
 
 func (m *SpentnessNotificationsRequest) GetAccount() uint32 {
 	if m != nil {
@@ -1210,10 +2064,12 @@ type SpentnessNotificationsResponse struct {
 	Spender         *SpentnessNotificationsResponse_Spender `protobuf:"bytes,3,opt,name=spender" json:"spender,omitempty"`
 }
 
-func (m *SpentnessNotificationsResponse) Reset()                    { *m = SpentnessNotificationsResponse{} }
-func (m *SpentnessNotificationsResponse) String() string            { return proto.CompactTextString(m) }
-func (*SpentnessNotificationsResponse) ProtoMessage()               {}
-func (*SpentnessNotificationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+func (m *SpentnessNotificationsResponse) Reset()         { *m = SpentnessNotificationsResponse{} }
+func (m *SpentnessNotificationsResponse) String() string { return proto.CompactTextString(m) }
+func (*SpentnessNotificationsResponse) ProtoMessage()    {}
+func (*SpentnessNotificationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{55}
+}
 
 func (m *SpentnessNotificationsResponse) GetTransactionHash() []byte {
 	if m != nil {
@@ -1247,7 +2103,7 @@ func (m *SpentnessNotificationsResponse_Spender) Reset() {
 func (m *SpentnessNotificationsResponse_Spender) String() string { return proto.CompactTextString(m) }
 func (*SpentnessNotificationsResponse_Spender) ProtoMessage()    {}
 func (*SpentnessNotificationsResponse_Spender) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{36, 0}
+	return fileDescriptor0, []int{55, 0}
 }
 
 func (m *SpentnessNotificationsResponse_Spender) GetTransactionHash() []byte {
@@ -1270,7 +2126,7 @@ type AccountNotificationsRequest struct {
 func (m *AccountNotificationsRequest) Reset()                    { *m = AccountNotificationsRequest{} }
 func (m *AccountNotificationsRequest) String() string            { return proto.CompactTextString(m) }
 func (*AccountNotificationsRequest) ProtoMessage()               {}
-func (*AccountNotificationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+func (*AccountNotificationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
 
 type AccountNotificationsResponse struct {
 	AccountNumber    uint32 `protobuf:"varint,1,opt,name=account_number,json=accountNumber" json:"account_number,omitempty"`
@@ -1283,7 +2139,7 @@ type AccountNotificationsResponse struct {
 func (m *AccountNotificationsResponse) Reset()                    { *m = AccountNotificationsResponse{} }
 func (m *AccountNotificationsResponse) String() string            { return proto.CompactTextString(m) }
 func (*AccountNotificationsResponse) ProtoMessage()               {}
-func (*AccountNotificationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+func (*AccountNotificationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
 
 func (m *AccountNotificationsResponse) GetAccountNumber() uint32 {
 	if m != nil {
@@ -1320,16 +2176,221 @@ func (m *AccountNotificationsResponse) GetImportedKeyCount() uint32 {
 	return 0
 }
 
+type RescanNotificationsRequest struct {
+}
+
+func (m *RescanNotificationsRequest) Reset()                    { *m = RescanNotificationsRequest{} }
+func (m *RescanNotificationsRequest) String() string            { return proto.CompactTextString(m) }
+func (*RescanNotificationsRequest) ProtoMessage()               {}
+func (*RescanNotificationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+type RescanNotificationsResponse struct {
+	// The block the rescan has processed through.
+	BlockHash []byte `protobuf:"bytes,1,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Height    int32  `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	// The best block height when the rescan started, which the rescan
+	// completes at.
+	TargetHeight int32 `protobuf:"varint,3,opt,name=target_height,json=targetHeight" json:"target_height,omitempty"`
+	// The percentage of the rescanned blocks processed, and the estimated
+	// number of seconds until the rescan completes.  The estimate is zero
+	// until enough blocks have been processed.
+	Percent          float64 `protobuf:"fixed64,4,opt,name=percent" json:"percent,omitempty"`
+	SecondsRemaining int64   `protobuf:"varint,5,opt,name=seconds_remaining,json=secondsRemaining" json:"seconds_remaining,omitempty"`
+}
+
+func (m *RescanNotificationsResponse) Reset()                    { *m = RescanNotificationsResponse{} }
+func (m *RescanNotificationsResponse) String() string            { return proto.CompactTextString(m) }
+func (*RescanNotificationsResponse) ProtoMessage()               {}
+func (*RescanNotificationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *RescanNotificationsResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *RescanNotificationsResponse) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *RescanNotificationsResponse) GetTargetHeight() int32 {
+	if m != nil {
+		return m.TargetHeight
+	}
+	return 0
+}
+
+func (m *RescanNotificationsResponse) GetPercent() float64 {
+	if m != nil {
+		return m.Percent
+	}
+	return 0
+}
+
+func (m *RescanNotificationsResponse) GetSecondsRemaining() int64 {
+	if m != nil {
+		return m.SecondsRemaining
+	}
+	return 0
+}
+
+type SubscribeSyncStateRequest struct {
+}
+
+func (m *SubscribeSyncStateRequest) Reset()                    { *m = SubscribeSyncStateRequest{} }
+func (m *SubscribeSyncStateRequest) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeSyncStateRequest) ProtoMessage()               {}
+func (*SubscribeSyncStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+type SubscribeSyncStateResponse struct {
+	// Whether the wallet is synced to the chain server.
+	Synced bool `protobuf:"varint,1,opt,name=synced" json:"synced,omitempty"`
+	// The height of the block the wallet is synced to, and the height of
+	// the best block of the chain server, which is zero when unknown.
+	Height        int32 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	BackendHeight int32 `protobuf:"varint,3,opt,name=backend_height,json=backendHeight" json:"backend_height,omitempty"`
+}
+
+func (m *SubscribeSyncStateResponse) Reset()                    { *m = SubscribeSyncStateResponse{} }
+func (m *SubscribeSyncStateResponse) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeSyncStateResponse) ProtoMessage()               {}
+func (*SubscribeSyncStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *SubscribeSyncStateResponse) GetSynced() bool {
+	if m != nil {
+		return m.Synced
+	}
+	return false
+}
+
+func (m *SubscribeSyncStateResponse) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *SubscribeSyncStateResponse) GetBackendHeight() int32 {
+	if m != nil {
+		return m.BackendHeight
+	}
+	return 0
+}
+
+type AccountBalanceNotificationsRequest struct {
+}
+
+func (m *AccountBalanceNotificationsRequest) Reset()         { *m = AccountBalanceNotificationsRequest{} }
+func (m *AccountBalanceNotificationsRequest) String() string { return proto.CompactTextString(m) }
+func (*AccountBalanceNotificationsRequest) ProtoMessage()    {}
+func (*AccountBalanceNotificationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{62}
+}
+
+type AccountBalanceNotificationsResponse struct {
+	Account            uint32 `protobuf:"varint,1,opt,name=account" json:"account,omitempty"`
+	ConfirmedBalance   int64  `protobuf:"varint,2,opt,name=confirmed_balance,json=confirmedBalance" json:"confirmed_balance,omitempty"`
+	UnconfirmedBalance int64  `protobuf:"varint,3,opt,name=unconfirmed_balance,json=unconfirmedBalance" json:"unconfirmed_balance,omitempty"`
+	// The changes of the balances since the previous notification of the
+	// account, or since the stream was started.
+	ConfirmedDelta   int64 `protobuf:"varint,4,opt,name=confirmed_delta,json=confirmedDelta" json:"confirmed_delta,omitempty"`
+	UnconfirmedDelta int64 `protobuf:"varint,5,opt,name=unconfirmed_delta,json=unconfirmedDelta" json:"unconfirmed_delta,omitempty"`
+}
+
+func (m *AccountBalanceNotificationsResponse) Reset()         { *m = AccountBalanceNotificationsResponse{} }
+func (m *AccountBalanceNotificationsResponse) String() string { return proto.CompactTextString(m) }
+func (*AccountBalanceNotificationsResponse) ProtoMessage()    {}
+func (*AccountBalanceNotificationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{63}
+}
+
+func (m *AccountBalanceNotificationsResponse) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
+}
+
+func (m *AccountBalanceNotificationsResponse) GetConfirmedBalance() int64 {
+	if m != nil {
+		return m.ConfirmedBalance
+	}
+	return 0
+}
+
+func (m *AccountBalanceNotificationsResponse) GetUnconfirmedBalance() int64 {
+	if m != nil {
+		return m.UnconfirmedBalance
+	}
+	return 0
+}
+
+func (m *AccountBalanceNotificationsResponse) GetConfirmedDelta() int64 {
+	if m != nil {
+		return m.ConfirmedDelta
+	}
+	return 0
+}
+
+func (m *AccountBalanceNotificationsResponse) GetUnconfirmedDelta() int64 {
+	if m != nil {
+		return m.UnconfirmedDelta
+	}
+	return 0
+}
+
+type ExportDatabaseRequest struct {
+	// If set, the database is exported in the backend-independent dump
+	// format read by walletdb.Restore instead of a copy of the database
+	// file.  Databases of backends without file copies must be dumped.
+	Dump bool `protobuf:"varint,1,opt,name=dump" json:"dump,omitempty"`
+}
+
+func (m *ExportDatabaseRequest) Reset()                    { *m = ExportDatabaseRequest{} }
+func (m *ExportDatabaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportDatabaseRequest) ProtoMessage()               {}
+func (*ExportDatabaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *ExportDatabaseRequest) GetDump() bool {
+	if m != nil {
+		return m.Dump
+	}
+	return false
+}
+
+type ExportDatabaseResponse struct {
+	// The next part of the exported database.  The parts of all responses
+	// are concatenated to the complete export.
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+}
+
+func (m *ExportDatabaseResponse) Reset()                    { *m = ExportDatabaseResponse{} }
+func (m *ExportDatabaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExportDatabaseResponse) ProtoMessage()               {}
+func (*ExportDatabaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *ExportDatabaseResponse) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
 type CreateWalletRequest struct {
 	PublicPassphrase  []byte `protobuf:"bytes,1,opt,name=public_passphrase,json=publicPassphrase,proto3" json:"public_passphrase,omitempty"`
 	PrivatePassphrase []byte `protobuf:"bytes,2,opt,name=private_passphrase,json=privatePassphrase,proto3" json:"private_passphrase,omitempty"`
 	Seed              []byte `protobuf:"bytes,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	WalletName        string `protobuf:"bytes,4,opt,name=wallet_name,json=walletName" json:"wallet_name,omitempty"`
 }
 
 func (m *CreateWalletRequest) Reset()                    { *m = CreateWalletRequest{} }
 func (m *CreateWalletRequest) String() string            { return proto.CompactTextString(m) }
 func (*CreateWalletRequest) ProtoMessage()               {}
-func (*CreateWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+func (*CreateWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
 
 func (m *CreateWalletRequest) GetPublicPassphrase() []byte {
 	if m != nil {
@@ -1352,22 +2413,30 @@ func (m *CreateWalletRequest) GetSeed() []byte {
 	return nil
 }
 
+func (m *CreateWalletRequest) GetWalletName() string {
+	if m != nil {
+		return m.WalletName
+	}
+	return ""
+}
+
 type CreateWalletResponse struct {
 }
 
 func (m *CreateWalletResponse) Reset()                    { *m = CreateWalletResponse{} }
 func (m *CreateWalletResponse) String() string            { return proto.CompactTextString(m) }
 func (*CreateWalletResponse) ProtoMessage()               {}
-func (*CreateWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+func (*CreateWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
 
 type OpenWalletRequest struct {
 	PublicPassphrase []byte `protobuf:"bytes,1,opt,name=public_passphrase,json=publicPassphrase,proto3" json:"public_passphrase,omitempty"`
+	WalletName       string `protobuf:"bytes,2,opt,name=wallet_name,json=walletName" json:"wallet_name,omitempty"`
 }
 
 func (m *OpenWalletRequest) Reset()                    { *m = OpenWalletRequest{} }
 func (m *OpenWalletRequest) String() string            { return proto.CompactTextString(m) }
 func (*OpenWalletRequest) ProtoMessage()               {}
-func (*OpenWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+func (*OpenWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
 
 func (m *OpenWalletRequest) GetPublicPassphrase() []byte {
 	if m != nil {
@@ -1376,21 +2445,36 @@ func (m *OpenWalletRequest) GetPublicPassphrase() []byte {
 	return nil
 }
 
+func (m *OpenWalletRequest) GetWalletName() string {
+	if m != nil {
+		return m.WalletName
+	}
+	return ""
+}
+
 type OpenWalletResponse struct {
 }
 
 func (m *OpenWalletResponse) Reset()                    { *m = OpenWalletResponse{} }
 func (m *OpenWalletResponse) String() string            { return proto.CompactTextString(m) }
 func (*OpenWalletResponse) ProtoMessage()               {}
-func (*OpenWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+func (*OpenWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
 
 type CloseWalletRequest struct {
+	WalletName string `protobuf:"bytes,1,opt,name=wallet_name,json=walletName" json:"wallet_name,omitempty"`
 }
 
 func (m *CloseWalletRequest) Reset()                    { *m = CloseWalletRequest{} }
 func (m *CloseWalletRequest) String() string            { return proto.CompactTextString(m) }
 func (*CloseWalletRequest) ProtoMessage()               {}
-func (*CloseWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+func (*CloseWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+func (m *CloseWalletRequest) GetWalletName() string {
+	if m != nil {
+		return m.WalletName
+	}
+	return ""
+}
 
 type CloseWalletResponse struct {
 }
@@ -1398,15 +2482,23 @@ type CloseWalletResponse struct {
 func (m *CloseWalletResponse) Reset()                    { *m = CloseWalletResponse{} }
 func (m *CloseWalletResponse) String() string            { return proto.CompactTextString(m) }
 func (*CloseWalletResponse) ProtoMessage()               {}
-func (*CloseWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+func (*CloseWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{71} }
 
 type WalletExistsRequest struct {
+	WalletName string `protobuf:"bytes,1,opt,name=wallet_name,json=walletName" json:"wallet_name,omitempty"`
 }
 
 func (m *WalletExistsRequest) Reset()                    { *m = WalletExistsRequest{} }
 func (m *WalletExistsRequest) String() string            { return proto.CompactTextString(m) }
 func (*WalletExistsRequest) ProtoMessage()               {}
-func (*WalletExistsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+func (*WalletExistsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{72} }
+
+func (m *WalletExistsRequest) GetWalletName() string {
+	if m != nil {
+		return m.WalletName
+	}
+	return ""
+}
 
 type WalletExistsResponse struct {
 	Exists bool `protobuf:"varint,1,opt,name=exists" json:"exists,omitempty"`
@@ -1415,7 +2507,7 @@ type WalletExistsResponse struct {
 func (m *WalletExistsResponse) Reset()                    { *m = WalletExistsResponse{} }
 func (m *WalletExistsResponse) String() string            { return proto.CompactTextString(m) }
 func (*WalletExistsResponse) ProtoMessage()               {}
-func (*WalletExistsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+func (*WalletExistsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{73} }
 
 func (m *WalletExistsResponse) GetExists() bool {
 	if m != nil {
@@ -1424,6 +2516,30 @@ func (m *WalletExistsResponse) GetExists() bool {
 	return false
 }
 
+type ListWalletsRequest struct {
+}
+
+func (m *ListWalletsRequest) Reset()                    { *m = ListWalletsRequest{} }
+func (m *ListWalletsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListWalletsRequest) ProtoMessage()               {}
+func (*ListWalletsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{74} }
+
+type ListWalletsResponse struct {
+	WalletNames []string `protobuf:"bytes,1,rep,name=wallet_names,json=walletNames" json:"wallet_names,omitempty"`
+}
+
+func (m *ListWalletsResponse) Reset()                    { *m = ListWalletsResponse{} }
+func (m *ListWalletsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListWalletsResponse) ProtoMessage()               {}
+func (*ListWalletsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{75} }
+
+func (m *ListWalletsResponse) GetWalletNames() []string {
+	if m != nil {
+		return m.WalletNames
+	}
+	return nil
+}
+
 type StartConsensusRpcRequest struct {
 	NetworkAddress string `protobuf:"bytes,1,opt,name=network_address,json=networkAddress" json:"network_address,omitempty"`
 	Username       string `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
@@ -1434,7 +2550,7 @@ type StartConsensusRpcRequest struct {
 func (m *StartConsensusRpcRequest) Reset()                    { *m = StartConsensusRpcRequest{} }
 func (m *StartConsensusRpcRequest) String() string            { return proto.CompactTextString(m) }
 func (*StartConsensusRpcRequest) ProtoMessage()               {}
-func (*StartConsensusRpcRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+func (*StartConsensusRpcRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{76} }
 
 func (m *StartConsensusRpcRequest) GetNetworkAddress() string {
 	if m != nil {
@@ -1452,25 +2568,177 @@ func (m *StartConsensusRpcRequest) GetUsername() string {
 
 func (m *StartConsensusRpcRequest) GetPassword() []byte {
 	if m != nil {
-		return m.Password
+		return m.Password
+	}
+	return nil
+}
+
+func (m *StartConsensusRpcRequest) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
+type StartConsensusRpcResponse struct {
+}
+
+func (m *StartConsensusRpcResponse) Reset()                    { *m = StartConsensusRpcResponse{} }
+func (m *StartConsensusRpcResponse) String() string            { return proto.CompactTextString(m) }
+func (*StartConsensusRpcResponse) ProtoMessage()               {}
+func (*StartConsensusRpcResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{77} }
+
+type DebugLevelRequest struct {
+	// The logging levels to set, in the format of the debuglevel option, such
+	// as "debug" or "WLLT=debug,CHNS=trace".  The levels are not changed when
+	// the level spec is empty or "show".
+	LevelSpec string `protobuf:"bytes,1,opt,name=level_spec,json=levelSpec" json:"level_spec,omitempty"`
+}
+
+func (m *DebugLevelRequest) Reset()                    { *m = DebugLevelRequest{} }
+func (m *DebugLevelRequest) String() string            { return proto.CompactTextString(m) }
+func (*DebugLevelRequest) ProtoMessage()               {}
+func (*DebugLevelRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{78} }
+
+func (m *DebugLevelRequest) GetLevelSpec() string {
+	if m != nil {
+		return m.LevelSpec
+	}
+	return ""
+}
+
+type DebugLevelResponse struct {
+	Subsystems []*DebugLevelResponse_Subsystem `protobuf:"bytes,1,rep,name=subsystems" json:"subsystems,omitempty"`
+}
+
+func (m *DebugLevelResponse) Reset()                    { *m = DebugLevelResponse{} }
+func (m *DebugLevelResponse) String() string            { return proto.CompactTextString(m) }
+func (*DebugLevelResponse) ProtoMessage()               {}
+func (*DebugLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{79} }
+
+func (m *DebugLevelResponse) GetSubsystems() []*DebugLevelResponse_Subsystem {
+	if m != nil {
+		return m.Subsystems
+	}
+	return nil
+}
+
+type DebugLevelResponse_Subsystem struct {
+	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Level string `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+}
+
+func (m *DebugLevelResponse_Subsystem) Reset()         { *m = DebugLevelResponse_Subsystem{} }
+func (m *DebugLevelResponse_Subsystem) String() string { return proto.CompactTextString(m) }
+func (*DebugLevelResponse_Subsystem) ProtoMessage()    {}
+func (*DebugLevelResponse_Subsystem) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{79, 0}
+}
+
+func (m *DebugLevelResponse_Subsystem) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DebugLevelResponse_Subsystem) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type SignOutputRawRequest struct {
+	Transaction []byte `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// BIP0032 derivation path of the signing key from the root key of the
+	// wallet, including the hardened offset of hardened indexes.
+	KeyPath []uint32 `protobuf:"varint,2,rep,packed,name=key_path,json=keyPath" json:"key_path,omitempty"`
+	// Checked against the public key derived from key_path.
+	PublicKey  []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	SubScript  []byte `protobuf:"bytes,4,opt,name=sub_script,json=subScript,proto3" json:"sub_script,omitempty"`
+	Amount     int64  `protobuf:"varint,5,opt,name=amount" json:"amount,omitempty"`
+	HashType   uint32 `protobuf:"varint,6,opt,name=hash_type,json=hashType" json:"hash_type,omitempty"`
+	InputIndex uint32 `protobuf:"varint,7,opt,name=input_index,json=inputIndex" json:"input_index,omitempty"`
+	Witness    bool   `protobuf:"varint,8,opt,name=witness" json:"witness,omitempty"`
+}
+
+func (m *SignOutputRawRequest) Reset()                    { *m = SignOutputRawRequest{} }
+func (m *SignOutputRawRequest) String() string            { return proto.CompactTextString(m) }
+func (*SignOutputRawRequest) ProtoMessage()               {}
+func (*SignOutputRawRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{80} }
+
+func (m *SignOutputRawRequest) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *SignOutputRawRequest) GetKeyPath() []uint32 {
+	if m != nil {
+		return m.KeyPath
+	}
+	return nil
+}
+
+func (m *SignOutputRawRequest) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *SignOutputRawRequest) GetSubScript() []byte {
+	if m != nil {
+		return m.SubScript
+	}
+	return nil
+}
+
+func (m *SignOutputRawRequest) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *SignOutputRawRequest) GetHashType() uint32 {
+	if m != nil {
+		return m.HashType
+	}
+	return 0
+}
+
+func (m *SignOutputRawRequest) GetInputIndex() uint32 {
+	if m != nil {
+		return m.InputIndex
 	}
-	return nil
+	return 0
 }
 
-func (m *StartConsensusRpcRequest) GetCertificate() []byte {
+func (m *SignOutputRawRequest) GetWitness() bool {
 	if m != nil {
-		return m.Certificate
+		return m.Witness
 	}
-	return nil
+	return false
 }
 
-type StartConsensusRpcResponse struct {
+type SignOutputRawResponse struct {
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
-func (m *StartConsensusRpcResponse) Reset()                    { *m = StartConsensusRpcResponse{} }
-func (m *StartConsensusRpcResponse) String() string            { return proto.CompactTextString(m) }
-func (*StartConsensusRpcResponse) ProtoMessage()               {}
-func (*StartConsensusRpcResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+func (m *SignOutputRawResponse) Reset()                    { *m = SignOutputRawResponse{} }
+func (m *SignOutputRawResponse) String() string            { return proto.CompactTextString(m) }
+func (*SignOutputRawResponse) ProtoMessage()               {}
+func (*SignOutputRawResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{81} }
+
+func (m *SignOutputRawResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
 
 func init() {
 	proto.RegisterType((*VersionRequest)(nil), "walletrpc.VersionRequest")
@@ -1480,6 +2748,7 @@ func init() {
 	proto.RegisterType((*TransactionDetails_Output)(nil), "walletrpc.TransactionDetails.Output")
 	proto.RegisterType((*BlockDetails)(nil), "walletrpc.BlockDetails")
 	proto.RegisterType((*AccountBalance)(nil), "walletrpc.AccountBalance")
+	proto.RegisterType((*OutPoint)(nil), "walletrpc.OutPoint")
 	proto.RegisterType((*PingRequest)(nil), "walletrpc.PingRequest")
 	proto.RegisterType((*PingResponse)(nil), "walletrpc.PingResponse")
 	proto.RegisterType((*NetworkRequest)(nil), "walletrpc.NetworkRequest")
@@ -1501,6 +2770,18 @@ func init() {
 	proto.RegisterType((*BalanceResponse)(nil), "walletrpc.BalanceResponse")
 	proto.RegisterType((*GetTransactionsRequest)(nil), "walletrpc.GetTransactionsRequest")
 	proto.RegisterType((*GetTransactionsResponse)(nil), "walletrpc.GetTransactionsResponse")
+	proto.RegisterType((*GetRecoveryInfoRequest)(nil), "walletrpc.GetRecoveryInfoRequest")
+	proto.RegisterType((*GetRecoveryInfoResponse)(nil), "walletrpc.GetRecoveryInfoResponse")
+	proto.RegisterType((*GetTransactionRequest)(nil), "walletrpc.GetTransactionRequest")
+	proto.RegisterType((*GetTransactionResponse)(nil), "walletrpc.GetTransactionResponse")
+	proto.RegisterType((*GetTransactionResponse_Input)(nil), "walletrpc.GetTransactionResponse.Input")
+	proto.RegisterType((*GetTransactionResponse_Output)(nil), "walletrpc.GetTransactionResponse.Output")
+	proto.RegisterType((*ListLockedOutputsRequest)(nil), "walletrpc.ListLockedOutputsRequest")
+	proto.RegisterType((*ListLockedOutputsResponse)(nil), "walletrpc.ListLockedOutputsResponse")
+	proto.RegisterType((*ValidateAddressRequest)(nil), "walletrpc.ValidateAddressRequest")
+	proto.RegisterType((*ValidateAddressResponse)(nil), "walletrpc.ValidateAddressResponse")
+	proto.RegisterType((*VerifyMessageRequest)(nil), "walletrpc.VerifyMessageRequest")
+	proto.RegisterType((*VerifyMessageResponse)(nil), "walletrpc.VerifyMessageResponse")
 	proto.RegisterType((*ChangePassphraseRequest)(nil), "walletrpc.ChangePassphraseRequest")
 	proto.RegisterType((*ChangePassphraseResponse)(nil), "walletrpc.ChangePassphraseResponse")
 	proto.RegisterType((*FundTransactionRequest)(nil), "walletrpc.FundTransactionRequest")
@@ -1510,13 +2791,32 @@ func init() {
 	proto.RegisterType((*SignTransactionResponse)(nil), "walletrpc.SignTransactionResponse")
 	proto.RegisterType((*PublishTransactionRequest)(nil), "walletrpc.PublishTransactionRequest")
 	proto.RegisterType((*PublishTransactionResponse)(nil), "walletrpc.PublishTransactionResponse")
+	proto.RegisterType((*SendOutputsRequest)(nil), "walletrpc.SendOutputsRequest")
+	proto.RegisterType((*SendOutputsRequest_Output)(nil), "walletrpc.SendOutputsRequest.Output")
+	proto.RegisterType((*SendOutputsResponse)(nil), "walletrpc.SendOutputsResponse")
+	proto.RegisterType((*LockOutputsRequest)(nil), "walletrpc.LockOutputsRequest")
+	proto.RegisterType((*LockOutputsResponse)(nil), "walletrpc.LockOutputsResponse")
+	proto.RegisterType((*SignMessageRequest)(nil), "walletrpc.SignMessageRequest")
+	proto.RegisterType((*SignMessageResponse)(nil), "walletrpc.SignMessageResponse")
+	proto.RegisterType((*DumpPrivateKeyRequest)(nil), "walletrpc.DumpPrivateKeyRequest")
+	proto.RegisterType((*DumpPrivateKeyResponse)(nil), "walletrpc.DumpPrivateKeyResponse")
 	proto.RegisterType((*TransactionNotificationsRequest)(nil), "walletrpc.TransactionNotificationsRequest")
 	proto.RegisterType((*TransactionNotificationsResponse)(nil), "walletrpc.TransactionNotificationsResponse")
+	proto.RegisterType((*TransactionNotificationsResponse_ConflictedTransaction)(nil), "walletrpc.TransactionNotificationsResponse.ConflictedTransaction")
+	proto.RegisterType((*TransactionNotificationsResponse_ReorgedTransaction)(nil), "walletrpc.TransactionNotificationsResponse.ReorgedTransaction")
 	proto.RegisterType((*SpentnessNotificationsRequest)(nil), "walletrpc.SpentnessNotificationsRequest")
 	proto.RegisterType((*SpentnessNotificationsResponse)(nil), "walletrpc.SpentnessNotificationsResponse")
 	proto.RegisterType((*SpentnessNotificationsResponse_Spender)(nil), "walletrpc.SpentnessNotificationsResponse.Spender")
 	proto.RegisterType((*AccountNotificationsRequest)(nil), "walletrpc.AccountNotificationsRequest")
 	proto.RegisterType((*AccountNotificationsResponse)(nil), "walletrpc.AccountNotificationsResponse")
+	proto.RegisterType((*RescanNotificationsRequest)(nil), "walletrpc.RescanNotificationsRequest")
+	proto.RegisterType((*RescanNotificationsResponse)(nil), "walletrpc.RescanNotificationsResponse")
+	proto.RegisterType((*SubscribeSyncStateRequest)(nil), "walletrpc.SubscribeSyncStateRequest")
+	proto.RegisterType((*SubscribeSyncStateResponse)(nil), "walletrpc.SubscribeSyncStateResponse")
+	proto.RegisterType((*AccountBalanceNotificationsRequest)(nil), "walletrpc.AccountBalanceNotificationsRequest")
+	proto.RegisterType((*AccountBalanceNotificationsResponse)(nil), "walletrpc.AccountBalanceNotificationsResponse")
+	proto.RegisterType((*ExportDatabaseRequest)(nil), "walletrpc.ExportDatabaseRequest")
+	proto.RegisterType((*ExportDatabaseResponse)(nil), "walletrpc.ExportDatabaseResponse")
 	proto.RegisterType((*CreateWalletRequest)(nil), "walletrpc.CreateWalletRequest")
 	proto.RegisterType((*CreateWalletResponse)(nil), "walletrpc.CreateWalletResponse")
 	proto.RegisterType((*OpenWalletRequest)(nil), "walletrpc.OpenWalletRequest")
@@ -1525,10 +2825,18 @@ func init() {
 	proto.RegisterType((*CloseWalletResponse)(nil), "walletrpc.CloseWalletResponse")
 	proto.RegisterType((*WalletExistsRequest)(nil), "walletrpc.WalletExistsRequest")
 	proto.RegisterType((*WalletExistsResponse)(nil), "walletrpc.WalletExistsResponse")
+	proto.RegisterType((*ListWalletsRequest)(nil), "walletrpc.ListWalletsRequest")
+	proto.RegisterType((*ListWalletsResponse)(nil), "walletrpc.ListWalletsResponse")
 	proto.RegisterType((*StartConsensusRpcRequest)(nil), "walletrpc.StartConsensusRpcRequest")
 	proto.RegisterType((*StartConsensusRpcResponse)(nil), "walletrpc.StartConsensusRpcResponse")
+	proto.RegisterType((*DebugLevelRequest)(nil), "walletrpc.DebugLevelRequest")
+	proto.RegisterType((*DebugLevelResponse)(nil), "walletrpc.DebugLevelResponse")
+	proto.RegisterType((*DebugLevelResponse_Subsystem)(nil), "walletrpc.DebugLevelResponse.Subsystem")
+	proto.RegisterType((*SignOutputRawRequest)(nil), "walletrpc.SignOutputRawRequest")
+	proto.RegisterType((*SignOutputRawResponse)(nil), "walletrpc.SignOutputRawResponse")
 	proto.RegisterEnum("walletrpc.NextAddressRequest_Kind", NextAddressRequest_Kind_name, NextAddressRequest_Kind_value)
 	proto.RegisterEnum("walletrpc.ChangePassphraseRequest_Key", ChangePassphraseRequest_Key_name, ChangePassphraseRequest_Key_value)
+	proto.RegisterEnum("walletrpc.TransactionNotificationsResponse_ReorgedTransaction_Status", TransactionNotificationsResponse_ReorgedTransaction_Status_name, TransactionNotificationsResponse_ReorgedTransaction_Status_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1613,10 +2921,18 @@ type WalletServiceClient interface {
 	Accounts(ctx context.Context, in *AccountsRequest, opts ...grpc.CallOption) (*AccountsResponse, error)
 	Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
 	GetTransactions(ctx context.Context, in *GetTransactionsRequest, opts ...grpc.CallOption) (*GetTransactionsResponse, error)
+	GetRecoveryInfo(ctx context.Context, in *GetRecoveryInfoRequest, opts ...grpc.CallOption) (*GetRecoveryInfoResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	ListLockedOutputs(ctx context.Context, in *ListLockedOutputsRequest, opts ...grpc.CallOption) (*ListLockedOutputsResponse, error)
+	ValidateAddress(ctx context.Context, in *ValidateAddressRequest, opts ...grpc.CallOption) (*ValidateAddressResponse, error)
+	VerifyMessage(ctx context.Context, in *VerifyMessageRequest, opts ...grpc.CallOption) (*VerifyMessageResponse, error)
 	// Notifications
 	TransactionNotifications(ctx context.Context, in *TransactionNotificationsRequest, opts ...grpc.CallOption) (WalletService_TransactionNotificationsClient, error)
 	SpentnessNotifications(ctx context.Context, in *SpentnessNotificationsRequest, opts ...grpc.CallOption) (WalletService_SpentnessNotificationsClient, error)
 	AccountNotifications(ctx context.Context, in *AccountNotificationsRequest, opts ...grpc.CallOption) (WalletService_AccountNotificationsClient, error)
+	RescanNotifications(ctx context.Context, in *RescanNotificationsRequest, opts ...grpc.CallOption) (WalletService_RescanNotificationsClient, error)
+	SubscribeSyncState(ctx context.Context, in *SubscribeSyncStateRequest, opts ...grpc.CallOption) (WalletService_SubscribeSyncStateClient, error)
+	AccountBalanceNotifications(ctx context.Context, in *AccountBalanceNotificationsRequest, opts ...grpc.CallOption) (WalletService_AccountBalanceNotificationsClient, error)
 	// Control
 	ChangePassphrase(ctx context.Context, in *ChangePassphraseRequest, opts ...grpc.CallOption) (*ChangePassphraseResponse, error)
 	RenameAccount(ctx context.Context, in *RenameAccountRequest, opts ...grpc.CallOption) (*RenameAccountResponse, error)
@@ -1626,6 +2942,12 @@ type WalletServiceClient interface {
 	FundTransaction(ctx context.Context, in *FundTransactionRequest, opts ...grpc.CallOption) (*FundTransactionResponse, error)
 	SignTransaction(ctx context.Context, in *SignTransactionRequest, opts ...grpc.CallOption) (*SignTransactionResponse, error)
 	PublishTransaction(ctx context.Context, in *PublishTransactionRequest, opts ...grpc.CallOption) (*PublishTransactionResponse, error)
+	SendOutputs(ctx context.Context, in *SendOutputsRequest, opts ...grpc.CallOption) (*SendOutputsResponse, error)
+	LockOutputs(ctx context.Context, in *LockOutputsRequest, opts ...grpc.CallOption) (*LockOutputsResponse, error)
+	SignMessage(ctx context.Context, in *SignMessageRequest, opts ...grpc.CallOption) (*SignMessageResponse, error)
+	DumpPrivateKey(ctx context.Context, in *DumpPrivateKeyRequest, opts ...grpc.CallOption) (*DumpPrivateKeyResponse, error)
+	// Backups
+	ExportDatabase(ctx context.Context, in *ExportDatabaseRequest, opts ...grpc.CallOption) (WalletService_ExportDatabaseClient, error)
 }
 
 type walletServiceClient struct {
@@ -1690,6 +3012,51 @@ func (c *walletServiceClient) GetTransactions(ctx context.Context, in *GetTransa
 	return out, nil
 }
 
+func (c *walletServiceClient) GetRecoveryInfo(ctx context.Context, in *GetRecoveryInfoRequest, opts ...grpc.CallOption) (*GetRecoveryInfoResponse, error) {
+	out := new(GetRecoveryInfoResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/GetRecoveryInfo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/GetTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ListLockedOutputs(ctx context.Context, in *ListLockedOutputsRequest, opts ...grpc.CallOption) (*ListLockedOutputsResponse, error) {
+	out := new(ListLockedOutputsResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/ListLockedOutputs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ValidateAddress(ctx context.Context, in *ValidateAddressRequest, opts ...grpc.CallOption) (*ValidateAddressResponse, error) {
+	out := new(ValidateAddressResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/ValidateAddress", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) VerifyMessage(ctx context.Context, in *VerifyMessageRequest, opts ...grpc.CallOption) (*VerifyMessageResponse, error) {
+	out := new(VerifyMessageResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/VerifyMessage", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *walletServiceClient) TransactionNotifications(ctx context.Context, in *TransactionNotificationsRequest, opts ...grpc.CallOption) (WalletService_TransactionNotificationsClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[0], c.cc, "/walletrpc.WalletService/TransactionNotifications", opts...)
 	if err != nil {
@@ -1786,6 +3153,102 @@ func (x *walletServiceAccountNotificationsClient) Recv() (*AccountNotificationsR
 	return m, nil
 }
 
+func (c *walletServiceClient) RescanNotifications(ctx context.Context, in *RescanNotificationsRequest, opts ...grpc.CallOption) (WalletService_RescanNotificationsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[3], c.cc, "/walletrpc.WalletService/RescanNotifications", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletServiceRescanNotificationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletService_RescanNotificationsClient interface {
+	Recv() (*RescanNotificationsResponse, error)
+	grpc.ClientStream
+}
+
+type walletServiceRescanNotificationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletServiceRescanNotificationsClient) Recv() (*RescanNotificationsResponse, error) {
+	m := new(RescanNotificationsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletServiceClient) SubscribeSyncState(ctx context.Context, in *SubscribeSyncStateRequest, opts ...grpc.CallOption) (WalletService_SubscribeSyncStateClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[4], c.cc, "/walletrpc.WalletService/SubscribeSyncState", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletServiceSubscribeSyncStateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletService_SubscribeSyncStateClient interface {
+	Recv() (*SubscribeSyncStateResponse, error)
+	grpc.ClientStream
+}
+
+type walletServiceSubscribeSyncStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletServiceSubscribeSyncStateClient) Recv() (*SubscribeSyncStateResponse, error) {
+	m := new(SubscribeSyncStateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletServiceClient) AccountBalanceNotifications(ctx context.Context, in *AccountBalanceNotificationsRequest, opts ...grpc.CallOption) (WalletService_AccountBalanceNotificationsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[5], c.cc, "/walletrpc.WalletService/AccountBalanceNotifications", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletServiceAccountBalanceNotificationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletService_AccountBalanceNotificationsClient interface {
+	Recv() (*AccountBalanceNotificationsResponse, error)
+	grpc.ClientStream
+}
+
+type walletServiceAccountBalanceNotificationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletServiceAccountBalanceNotificationsClient) Recv() (*AccountBalanceNotificationsResponse, error) {
+	m := new(AccountBalanceNotificationsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *walletServiceClient) ChangePassphrase(ctx context.Context, in *ChangePassphraseRequest, opts ...grpc.CallOption) (*ChangePassphraseResponse, error) {
 	out := new(ChangePassphraseResponse)
 	err := grpc.Invoke(ctx, "/walletrpc.WalletService/ChangePassphrase", in, out, c.cc, opts...)
@@ -1858,6 +3321,74 @@ func (c *walletServiceClient) PublishTransaction(ctx context.Context, in *Publis
 	return out, nil
 }
 
+func (c *walletServiceClient) SendOutputs(ctx context.Context, in *SendOutputsRequest, opts ...grpc.CallOption) (*SendOutputsResponse, error) {
+	out := new(SendOutputsResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/SendOutputs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) LockOutputs(ctx context.Context, in *LockOutputsRequest, opts ...grpc.CallOption) (*LockOutputsResponse, error) {
+	out := new(LockOutputsResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/LockOutputs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) SignMessage(ctx context.Context, in *SignMessageRequest, opts ...grpc.CallOption) (*SignMessageResponse, error) {
+	out := new(SignMessageResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/SignMessage", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) DumpPrivateKey(ctx context.Context, in *DumpPrivateKeyRequest, opts ...grpc.CallOption) (*DumpPrivateKeyResponse, error) {
+	out := new(DumpPrivateKeyResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/DumpPrivateKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ExportDatabase(ctx context.Context, in *ExportDatabaseRequest, opts ...grpc.CallOption) (WalletService_ExportDatabaseClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[6], c.cc, "/walletrpc.WalletService/ExportDatabase", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletServiceExportDatabaseClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletService_ExportDatabaseClient interface {
+	Recv() (*ExportDatabaseResponse, error)
+	grpc.ClientStream
+}
+
+type walletServiceExportDatabaseClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletServiceExportDatabaseClient) Recv() (*ExportDatabaseResponse, error) {
+	m := new(ExportDatabaseResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for WalletService service
 
 type WalletServiceServer interface {
@@ -1868,10 +3399,18 @@ type WalletServiceServer interface {
 	Accounts(context.Context, *AccountsRequest) (*AccountsResponse, error)
 	Balance(context.Context, *BalanceRequest) (*BalanceResponse, error)
 	GetTransactions(context.Context, *GetTransactionsRequest) (*GetTransactionsResponse, error)
+	GetRecoveryInfo(context.Context, *GetRecoveryInfoRequest) (*GetRecoveryInfoResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	ListLockedOutputs(context.Context, *ListLockedOutputsRequest) (*ListLockedOutputsResponse, error)
+	ValidateAddress(context.Context, *ValidateAddressRequest) (*ValidateAddressResponse, error)
+	VerifyMessage(context.Context, *VerifyMessageRequest) (*VerifyMessageResponse, error)
 	// Notifications
 	TransactionNotifications(*TransactionNotificationsRequest, WalletService_TransactionNotificationsServer) error
 	SpentnessNotifications(*SpentnessNotificationsRequest, WalletService_SpentnessNotificationsServer) error
 	AccountNotifications(*AccountNotificationsRequest, WalletService_AccountNotificationsServer) error
+	RescanNotifications(*RescanNotificationsRequest, WalletService_RescanNotificationsServer) error
+	SubscribeSyncState(*SubscribeSyncStateRequest, WalletService_SubscribeSyncStateServer) error
+	AccountBalanceNotifications(*AccountBalanceNotificationsRequest, WalletService_AccountBalanceNotificationsServer) error
 	// Control
 	ChangePassphrase(context.Context, *ChangePassphraseRequest) (*ChangePassphraseResponse, error)
 	RenameAccount(context.Context, *RenameAccountRequest) (*RenameAccountResponse, error)
@@ -1881,116 +3420,212 @@ type WalletServiceServer interface {
 	FundTransaction(context.Context, *FundTransactionRequest) (*FundTransactionResponse, error)
 	SignTransaction(context.Context, *SignTransactionRequest) (*SignTransactionResponse, error)
 	PublishTransaction(context.Context, *PublishTransactionRequest) (*PublishTransactionResponse, error)
+	SendOutputs(context.Context, *SendOutputsRequest) (*SendOutputsResponse, error)
+	LockOutputs(context.Context, *LockOutputsRequest) (*LockOutputsResponse, error)
+	SignMessage(context.Context, *SignMessageRequest) (*SignMessageResponse, error)
+	DumpPrivateKey(context.Context, *DumpPrivateKeyRequest) (*DumpPrivateKeyResponse, error)
+	// Backups
+	ExportDatabase(*ExportDatabaseRequest, WalletService_ExportDatabaseServer) error
 }
 
 func RegisterWalletServiceServer(s *grpc.Server, srv WalletServiceServer) {
 	s.RegisterService(&_WalletService_serviceDesc, srv)
 }
 
-func _WalletService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PingRequest)
+func _WalletService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Network(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/Network",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Network(ctx, req.(*NetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_AccountNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).AccountNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/AccountNumber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).AccountNumber(ctx, req.(*AccountNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_Accounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Accounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/Accounts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Accounts(ctx, req.(*AccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_Balance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Balance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/Balance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Balance(ctx, req.(*BalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).Ping(ctx, in)
+		return srv.(WalletServiceServer).GetTransactions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/Ping",
+		FullMethod: "/walletrpc.WalletService/GetTransactions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).Ping(ctx, req.(*PingRequest))
+		return srv.(WalletServiceServer).GetTransactions(ctx, req.(*GetTransactionsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletService_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NetworkRequest)
+func _WalletService_GetRecoveryInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecoveryInfoRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).Network(ctx, in)
+		return srv.(WalletServiceServer).GetRecoveryInfo(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/Network",
+		FullMethod: "/walletrpc.WalletService/GetRecoveryInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).Network(ctx, req.(*NetworkRequest))
+		return srv.(WalletServiceServer).GetRecoveryInfo(ctx, req.(*GetRecoveryInfoRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletService_AccountNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AccountNumberRequest)
+func _WalletService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).AccountNumber(ctx, in)
+		return srv.(WalletServiceServer).GetTransaction(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/AccountNumber",
+		FullMethod: "/walletrpc.WalletService/GetTransaction",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).AccountNumber(ctx, req.(*AccountNumberRequest))
+		return srv.(WalletServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletService_Accounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AccountsRequest)
+func _WalletService_ListLockedOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLockedOutputsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).Accounts(ctx, in)
+		return srv.(WalletServiceServer).ListLockedOutputs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/Accounts",
+		FullMethod: "/walletrpc.WalletService/ListLockedOutputs",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).Accounts(ctx, req.(*AccountsRequest))
+		return srv.(WalletServiceServer).ListLockedOutputs(ctx, req.(*ListLockedOutputsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletService_Balance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BalanceRequest)
+func _WalletService_ValidateAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateAddressRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).Balance(ctx, in)
+		return srv.(WalletServiceServer).ValidateAddress(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/Balance",
+		FullMethod: "/walletrpc.WalletService/ValidateAddress",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).Balance(ctx, req.(*BalanceRequest))
+		return srv.(WalletServiceServer).ValidateAddress(ctx, req.(*ValidateAddressRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletService_GetTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTransactionsRequest)
+func _WalletService_VerifyMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMessageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServiceServer).GetTransactions(ctx, in)
+		return srv.(WalletServiceServer).VerifyMessage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletrpc.WalletService/GetTransactions",
+		FullMethod: "/walletrpc.WalletService/VerifyMessage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServiceServer).GetTransactions(ctx, req.(*GetTransactionsRequest))
+		return srv.(WalletServiceServer).VerifyMessage(ctx, req.(*VerifyMessageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -2058,6 +3693,69 @@ func (x *walletServiceAccountNotificationsServer) Send(m *AccountNotificationsRe
 	return x.ServerStream.SendMsg(m)
 }
 
+func _WalletService_RescanNotifications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RescanNotificationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).RescanNotifications(m, &walletServiceRescanNotificationsServer{stream})
+}
+
+type WalletService_RescanNotificationsServer interface {
+	Send(*RescanNotificationsResponse) error
+	grpc.ServerStream
+}
+
+type walletServiceRescanNotificationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletServiceRescanNotificationsServer) Send(m *RescanNotificationsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _WalletService_SubscribeSyncState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeSyncStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).SubscribeSyncState(m, &walletServiceSubscribeSyncStateServer{stream})
+}
+
+type WalletService_SubscribeSyncStateServer interface {
+	Send(*SubscribeSyncStateResponse) error
+	grpc.ServerStream
+}
+
+type walletServiceSubscribeSyncStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletServiceSubscribeSyncStateServer) Send(m *SubscribeSyncStateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _WalletService_AccountBalanceNotifications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AccountBalanceNotificationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).AccountBalanceNotifications(m, &walletServiceAccountBalanceNotificationsServer{stream})
+}
+
+type WalletService_AccountBalanceNotificationsServer interface {
+	Send(*AccountBalanceNotificationsResponse) error
+	grpc.ServerStream
+}
+
+type walletServiceAccountBalanceNotificationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletServiceAccountBalanceNotificationsServer) Send(m *AccountBalanceNotificationsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _WalletService_ChangePassphrase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ChangePassphraseRequest)
 	if err := dec(in); err != nil {
@@ -2202,6 +3900,99 @@ func _WalletService_PublishTransaction_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletService_SendOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SendOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/SendOutputs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SendOutputs(ctx, req.(*SendOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_LockOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).LockOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/LockOutputs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).LockOutputs(ctx, req.(*LockOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_SignMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SignMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/SignMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SignMessage(ctx, req.(*SignMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_DumpPrivateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpPrivateKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).DumpPrivateKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletService/DumpPrivateKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).DumpPrivateKey(ctx, req.(*DumpPrivateKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_ExportDatabase_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportDatabaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).ExportDatabase(m, &walletServiceExportDatabaseServer{stream})
+}
+
+type WalletService_ExportDatabaseServer interface {
+	Send(*ExportDatabaseResponse) error
+	grpc.ServerStream
+}
+
+type walletServiceExportDatabaseServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletServiceExportDatabaseServer) Send(m *ExportDatabaseResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _WalletService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "walletrpc.WalletService",
 	HandlerType: (*WalletServiceServer)(nil),
@@ -2230,6 +4021,26 @@ var _WalletService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetTransactions",
 			Handler:    _WalletService_GetTransactions_Handler,
 		},
+		{
+			MethodName: "GetRecoveryInfo",
+			Handler:    _WalletService_GetRecoveryInfo_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _WalletService_GetTransaction_Handler,
+		},
+		{
+			MethodName: "ListLockedOutputs",
+			Handler:    _WalletService_ListLockedOutputs_Handler,
+		},
+		{
+			MethodName: "ValidateAddress",
+			Handler:    _WalletService_ValidateAddress_Handler,
+		},
+		{
+			MethodName: "VerifyMessage",
+			Handler:    _WalletService_VerifyMessage_Handler,
+		},
 		{
 			MethodName: "ChangePassphrase",
 			Handler:    _WalletService_ChangePassphrase_Handler,
@@ -2262,6 +4073,22 @@ var _WalletService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "PublishTransaction",
 			Handler:    _WalletService_PublishTransaction_Handler,
 		},
+		{
+			MethodName: "SendOutputs",
+			Handler:    _WalletService_SendOutputs_Handler,
+		},
+		{
+			MethodName: "LockOutputs",
+			Handler:    _WalletService_LockOutputs_Handler,
+		},
+		{
+			MethodName: "SignMessage",
+			Handler:    _WalletService_SignMessage_Handler,
+		},
+		{
+			MethodName: "DumpPrivateKey",
+			Handler:    _WalletService_DumpPrivateKey_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2279,6 +4106,26 @@ var _WalletService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _WalletService_AccountNotifications_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "RescanNotifications",
+			Handler:       _WalletService_RescanNotifications_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeSyncState",
+			Handler:       _WalletService_SubscribeSyncState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "AccountBalanceNotifications",
+			Handler:       _WalletService_AccountBalanceNotifications_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportDatabase",
+			Handler:       _WalletService_ExportDatabase_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api.proto",
 }
@@ -2290,7 +4137,9 @@ type WalletLoaderServiceClient interface {
 	CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error)
 	OpenWallet(ctx context.Context, in *OpenWalletRequest, opts ...grpc.CallOption) (*OpenWalletResponse, error)
 	CloseWallet(ctx context.Context, in *CloseWalletRequest, opts ...grpc.CallOption) (*CloseWalletResponse, error)
+	ListWallets(ctx context.Context, in *ListWalletsRequest, opts ...grpc.CallOption) (*ListWalletsResponse, error)
 	StartConsensusRpc(ctx context.Context, in *StartConsensusRpcRequest, opts ...grpc.CallOption) (*StartConsensusRpcResponse, error)
+	DebugLevel(ctx context.Context, in *DebugLevelRequest, opts ...grpc.CallOption) (*DebugLevelResponse, error)
 }
 
 type walletLoaderServiceClient struct {
@@ -2337,6 +4186,15 @@ func (c *walletLoaderServiceClient) CloseWallet(ctx context.Context, in *CloseWa
 	return out, nil
 }
 
+func (c *walletLoaderServiceClient) ListWallets(ctx context.Context, in *ListWalletsRequest, opts ...grpc.CallOption) (*ListWalletsResponse, error) {
+	out := new(ListWalletsResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletLoaderService/ListWallets", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *walletLoaderServiceClient) StartConsensusRpc(ctx context.Context, in *StartConsensusRpcRequest, opts ...grpc.CallOption) (*StartConsensusRpcResponse, error) {
 	out := new(StartConsensusRpcResponse)
 	err := grpc.Invoke(ctx, "/walletrpc.WalletLoaderService/StartConsensusRpc", in, out, c.cc, opts...)
@@ -2346,6 +4204,15 @@ func (c *walletLoaderServiceClient) StartConsensusRpc(ctx context.Context, in *S
 	return out, nil
 }
 
+func (c *walletLoaderServiceClient) DebugLevel(ctx context.Context, in *DebugLevelRequest, opts ...grpc.CallOption) (*DebugLevelResponse, error) {
+	out := new(DebugLevelResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletLoaderService/DebugLevel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for WalletLoaderService service
 
 type WalletLoaderServiceServer interface {
@@ -2353,7 +4220,9 @@ type WalletLoaderServiceServer interface {
 	CreateWallet(context.Context, *CreateWalletRequest) (*CreateWalletResponse, error)
 	OpenWallet(context.Context, *OpenWalletRequest) (*OpenWalletResponse, error)
 	CloseWallet(context.Context, *CloseWalletRequest) (*CloseWalletResponse, error)
+	ListWallets(context.Context, *ListWalletsRequest) (*ListWalletsResponse, error)
 	StartConsensusRpc(context.Context, *StartConsensusRpcRequest) (*StartConsensusRpcResponse, error)
+	DebugLevel(context.Context, *DebugLevelRequest) (*DebugLevelResponse, error)
 }
 
 func RegisterWalletLoaderServiceServer(s *grpc.Server, srv WalletLoaderServiceServer) {
@@ -2432,6 +4301,24 @@ func _WalletLoaderService_CloseWallet_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletLoaderService_ListWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletLoaderServiceServer).ListWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletLoaderService/ListWallets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletLoaderServiceServer).ListWallets(ctx, req.(*ListWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WalletLoaderService_StartConsensusRpc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartConsensusRpcRequest)
 	if err := dec(in); err != nil {
@@ -2450,6 +4337,24 @@ func _WalletLoaderService_StartConsensusRpc_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletLoaderService_DebugLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletLoaderServiceServer).DebugLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletLoaderService/DebugLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletLoaderServiceServer).DebugLevel(ctx, req.(*DebugLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _WalletLoaderService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "walletrpc.WalletLoaderService",
 	HandlerType: (*WalletLoaderServiceServer)(nil),
@@ -2470,10 +4375,82 @@ var _WalletLoaderService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CloseWallet",
 			Handler:    _WalletLoaderService_CloseWallet_Handler,
 		},
+		{
+			MethodName: "ListWallets",
+			Handler:    _WalletLoaderService_ListWallets_Handler,
+		},
 		{
 			MethodName: "StartConsensusRpc",
 			Handler:    _WalletLoaderService_StartConsensusRpc_Handler,
 		},
+		{
+			MethodName: "DebugLevel",
+			Handler:    _WalletLoaderService_DebugLevel_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
+
+// Client API for SignerService service
+
+type SignerServiceClient interface {
+	SignOutputRaw(ctx context.Context, in *SignOutputRawRequest, opts ...grpc.CallOption) (*SignOutputRawResponse, error)
+}
+
+type signerServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSignerServiceClient(cc *grpc.ClientConn) SignerServiceClient {
+	return &signerServiceClient{cc}
+}
+
+func (c *signerServiceClient) SignOutputRaw(ctx context.Context, in *SignOutputRawRequest, opts ...grpc.CallOption) (*SignOutputRawResponse, error) {
+	out := new(SignOutputRawResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.SignerService/SignOutputRaw", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for SignerService service
+
+type SignerServiceServer interface {
+	SignOutputRaw(context.Context, *SignOutputRawRequest) (*SignOutputRawResponse, error)
+}
+
+func RegisterSignerServiceServer(s *grpc.Server, srv SignerServiceServer) {
+	s.RegisterService(&_SignerService_serviceDesc, srv)
+}
+
+func _SignerService_SignOutputRaw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignOutputRawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServiceServer).SignOutputRaw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.SignerService/SignOutputRaw",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServiceServer).SignOutputRaw(ctx, req.(*SignOutputRawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SignerService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletrpc.SignerService",
+	HandlerType: (*SignerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SignOutputRaw",
+			Handler:    _SignerService_SignOutputRaw_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
@@ -2482,155 +4459,253 @@ var _WalletLoaderService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("api.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2391 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x59, 0x5b, 0x73, 0xdc, 0x48,
-	0x15, 0x66, 0x2c, 0xdf, 0x72, 0xe6, 0xde, 0xbe, 0x4d, 0x94, 0x38, 0xc9, 0x2a, 0xbb, 0xd9, 0xec,
-	0x2e, 0x98, 0x60, 0x02, 0x2c, 0xc5, 0x56, 0xd8, 0xc4, 0x64, 0xd9, 0x21, 0xc1, 0x99, 0x92, 0x93,
-	0x4d, 0xaa, 0xa0, 0x98, 0x92, 0x35, 0xed, 0x58, 0x78, 0x46, 0x9a, 0x48, 0x9a, 0x38, 0xe1, 0x89,
-	0xa2, 0x8a, 0x47, 0x5e, 0x80, 0x07, 0x0a, 0x6a, 0x5f, 0xf8, 0x05, 0x54, 0xf1, 0xc2, 0x23, 0xfc,
-	0x06, 0x1e, 0xf9, 0x17, 0xfc, 0x02, 0xfa, 0x72, 0x7a, 0xd4, 0x2d, 0x69, 0xc6, 0xf6, 0x16, 0x6f,
-	0xa3, 0xaf, 0xcf, 0x39, 0x7d, 0xfa, 0xf4, 0xb9, 0xf6, 0xc0, 0x25, 0x6f, 0x1c, 0xec, 0x8c, 0xe3,
-	0x28, 0x8d, 0xc8, 0xa5, 0x53, 0x6f, 0x38, 0xa4, 0x69, 0x3c, 0xf6, 0x9d, 0x16, 0x34, 0xbe, 0xa0,
-	0x71, 0x12, 0x44, 0xa1, 0x4b, 0x5f, 0x4d, 0x68, 0x92, 0x3a, 0xff, 0xaa, 0x40, 0x73, 0x0a, 0x25,
-	0xe3, 0x28, 0x4c, 0x28, 0x79, 0x0f, 0x1a, 0xaf, 0x25, 0xd4, 0x4f, 0xd2, 0x38, 0x08, 0x5f, 0x76,
-	0x2a, 0x37, 0x2a, 0xb7, 0x2f, 0xb9, 0x75, 0x44, 0x0f, 0x04, 0x48, 0xd6, 0x61, 0x69, 0xe4, 0xfd,
-	0x32, 0x8a, 0x3b, 0x0b, 0x6c, 0xb5, 0xee, 0xca, 0x0f, 0x81, 0x06, 0x21, 0x43, 0x2d, 0x44, 0xf9,
-	0x07, 0x47, 0xc7, 0x5e, 0xea, 0x1f, 0x77, 0x16, 0x25, 0x2a, 0x3e, 0xc8, 0x35, 0x80, 0x71, 0x4c,
-	0x63, 0x3a, 0xa4, 0x5e, 0x42, 0x3b, 0x4b, 0x62, 0x13, 0x0d, 0xe1, 0x8a, 0x1c, 0x4e, 0x82, 0xe1,
-	0xa0, 0x3f, 0xa2, 0xa9, 0x37, 0xf0, 0x52, 0xaf, 0xb3, 0x2c, 0x15, 0x11, 0xe8, 0x4f, 0x11, 0x74,
-	0xfe, 0x69, 0x01, 0x79, 0x1a, 0x7b, 0x61, 0xe2, 0xf9, 0x29, 0x53, 0xef, 0x47, 0x0c, 0x0f, 0x86,
-	0x09, 0x21, 0xb0, 0x78, 0xec, 0x25, 0xc7, 0x42, 0xf9, 0x9a, 0x2b, 0x7e, 0x93, 0x1b, 0x50, 0x4d,
-	0x33, 0x4a, 0xa1, 0x79, 0xcd, 0xd5, 0x21, 0xf2, 0x03, 0x58, 0x1e, 0xd0, 0xc3, 0x20, 0x4d, 0xd8,
-	0x01, 0xac, 0xdb, 0xd5, 0xdd, 0x9b, 0x3b, 0x53, 0xf3, 0xed, 0x14, 0x37, 0xd9, 0xe9, 0x86, 0xe3,
-	0x49, 0xea, 0x22, 0x0b, 0xb9, 0x07, 0x2b, 0x7e, 0x4c, 0x07, 0x9c, 0x7b, 0x51, 0x70, 0xbf, 0x3b,
-	0x9f, 0xfb, 0xc9, 0x24, 0xe5, 0xec, 0x8a, 0x89, 0xb4, 0xc0, 0x3a, 0xa2, 0xd2, 0x12, 0x96, 0xcb,
-	0x7f, 0x92, 0xab, 0x70, 0x29, 0x0d, 0x46, 0xec, 0xa6, 0xbc, 0xd1, 0x58, 0x9c, 0xde, 0x72, 0x33,
-	0xc0, 0x7e, 0x05, 0x4b, 0x42, 0x01, 0x6e, 0xdf, 0x20, 0x1c, 0xd0, 0x37, 0xe2, 0xb0, 0xcc, 0xbe,
-	0xe2, 0x83, 0x7c, 0x00, 0x2d, 0x66, 0xcd, 0xd7, 0x41, 0x34, 0x49, 0xfa, 0x9e, 0xef, 0x47, 0x93,
-	0x30, 0xc5, 0xcb, 0x6a, 0x2a, 0xfc, 0xbe, 0x84, 0xc9, 0xfb, 0xd0, 0xcc, 0x48, 0x47, 0x82, 0xd2,
-	0x12, 0xbb, 0x35, 0xa6, 0x94, 0x02, 0xb5, 0x9f, 0xc2, 0xb2, 0xd4, 0x7a, 0xc6, 0x9e, 0x1d, 0x58,
-	0x31, 0xb7, 0x52, 0x9f, 0xc4, 0x86, 0xd5, 0x20, 0x4c, 0x69, 0x1c, 0x7a, 0x43, 0x21, 0x7b, 0xd5,
-	0x9d, 0x7e, 0x3b, 0x7f, 0xa9, 0x40, 0xed, 0xc1, 0x30, 0xf2, 0x4f, 0xe6, 0x5d, 0xde, 0x26, 0x2c,
-	0x1f, 0xd3, 0xe0, 0xe5, 0xb1, 0x94, 0xbc, 0xe4, 0xe2, 0x97, 0x69, 0x23, 0x2b, 0x67, 0x23, 0x72,
-	0x1f, 0x6a, 0xda, 0xfd, 0xaa, 0x8b, 0xd9, 0x9e, 0x7b, 0x31, 0xae, 0xc1, 0xe2, 0x3c, 0x81, 0x06,
-	0xda, 0xe9, 0x81, 0x37, 0xf4, 0x42, 0x9f, 0xea, 0xa7, 0xac, 0x98, 0xa7, 0xbc, 0x09, 0xf5, 0x34,
-	0x4a, 0xbd, 0x61, 0xff, 0x50, 0x92, 0x0a, 0x5d, 0x2d, 0x26, 0x90, 0x83, 0xc8, 0xee, 0xd4, 0xa1,
-	0xda, 0x63, 0x21, 0xa4, 0x82, 0xb0, 0x01, 0x35, 0xf9, 0x29, 0x03, 0x90, 0x87, 0xe9, 0x3e, 0x4d,
-	0x4f, 0xa3, 0xf8, 0x44, 0x51, 0x7c, 0x0c, 0xcd, 0x29, 0x92, 0x45, 0x29, 0xd7, 0xef, 0x35, 0xed,
-	0x87, 0x72, 0x05, 0x35, 0xa9, 0x4b, 0x14, 0xc9, 0x9d, 0xef, 0xc3, 0x3a, 0xea, 0xbe, 0x3f, 0x19,
-	0x1d, 0xd2, 0x18, 0x25, 0x92, 0x77, 0xa0, 0x86, 0x2a, 0xf7, 0x43, 0x6f, 0x44, 0x31, 0xc4, 0xab,
-	0x88, 0xed, 0x33, 0xc8, 0xb9, 0x07, 0x1b, 0x39, 0x56, 0x7d, 0x6b, 0xe4, 0x15, 0x2b, 0xd9, 0xd6,
-	0x1a, 0xb9, 0xd3, 0x86, 0x26, 0xf2, 0x27, 0xea, 0x1c, 0xff, 0xb0, 0xa0, 0x95, 0x61, 0x28, 0xee,
-	0x87, 0xb0, 0x8a, 0x8c, 0x09, 0x13, 0x94, 0x0f, 0xba, 0x3c, 0xb9, 0x02, 0xdc, 0x29, 0x13, 0xf9,
-	0x3a, 0x10, 0x7f, 0x12, 0xc7, 0x94, 0xe9, 0x73, 0xc8, 0x9d, 0xa8, 0x2f, 0x5c, 0x47, 0x06, 0x77,
-	0x0b, 0x57, 0x84, 0x77, 0x7d, 0xce, 0xdd, 0xe8, 0x0e, 0xac, 0xe7, 0xa8, 0xa5, 0x53, 0x59, 0xc2,
-	0xa9, 0x88, 0x41, 0x2f, 0x56, 0xec, 0xdf, 0x2c, 0xc0, 0x8a, 0x0a, 0x94, 0xf3, 0x9d, 0xbd, 0x60,
-	0xde, 0x85, 0x82, 0x79, 0x8b, 0x9e, 0x62, 0x15, 0x3d, 0x85, 0x1f, 0x8d, 0xbe, 0x91, 0x41, 0xd2,
-	0x3f, 0xa1, 0x6f, 0xfb, 0xd2, 0xe7, 0x64, 0x16, 0x6d, 0xa9, 0x95, 0x47, 0xf4, 0xed, 0x9e, 0x50,
-	0x8e, 0x51, 0xab, 0x90, 0xd2, 0xa8, 0x97, 0x24, 0xb5, 0x5a, 0x31, 0xa8, 0x47, 0xe3, 0x28, 0x4e,
-	0xe9, 0x40, 0xa3, 0x5e, 0x46, 0x6a, 0x5c, 0x51, 0xd4, 0xce, 0x0b, 0x58, 0x77, 0x29, 0x3f, 0x8b,
-	0xb2, 0x3f, 0x3a, 0xd2, 0x39, 0x0d, 0x72, 0x19, 0x56, 0x43, 0x7a, 0xaa, 0x1b, 0x63, 0x85, 0x7d,
-	0x0b, 0x3f, 0xdb, 0x82, 0x8d, 0x9c, 0x64, 0x8c, 0x83, 0xe7, 0x40, 0xf6, 0xd9, 0x19, 0x73, 0x1b,
-	0xf2, 0xaa, 0xe1, 0x25, 0xc9, 0xf8, 0x38, 0xe6, 0x55, 0x43, 0x26, 0x08, 0x0d, 0x39, 0x87, 0xe9,
-	0x9d, 0x4f, 0x60, 0xcd, 0x10, 0x7c, 0x31, 0xbf, 0xfe, 0x73, 0x05, 0xf5, 0x1a, 0x0c, 0x62, 0x9a,
-	0x28, 0xdf, 0x9e, 0x93, 0x13, 0xbe, 0x0b, 0x8b, 0x27, 0x2c, 0x3b, 0x0a, 0x4d, 0x1a, 0xbb, 0x8e,
-	0xe6, 0xdc, 0x45, 0x31, 0x3b, 0x8f, 0x18, 0xa5, 0x2b, 0xe8, 0x9d, 0x5d, 0x58, 0xe4, 0x5f, 0x2c,
-	0xd3, 0xb6, 0x1e, 0x74, 0x7b, 0x77, 0xee, 0xdc, 0xbd, 0xdb, 0x7f, 0xf8, 0xe2, 0xe9, 0x43, 0x77,
-	0xff, 0xfe, 0xe3, 0xd6, 0xd7, 0x74, 0xb4, 0xbb, 0x8f, 0x68, 0xc5, 0xf9, 0x26, 0x1e, 0x4d, 0x09,
-	0xc5, 0xa3, 0x71, 0xe5, 0x24, 0x84, 0x91, 0xae, 0x3e, 0x9d, 0x3f, 0x54, 0x60, 0xab, 0x2b, 0x2e,
-	0xbb, 0x17, 0x07, 0xaf, 0xbd, 0x94, 0xb2, 0x1b, 0x3f, 0xaf, 0xa9, 0x67, 0x27, 0xfb, 0x5b, 0xbc,
-	0x9e, 0x08, 0x71, 0xc2, 0xb5, 0x4e, 0x83, 0x23, 0xe1, 0xde, 0xac, 0x76, 0x8f, 0xa7, 0xbb, 0x3c,
-	0x0f, 0x8e, 0x78, 0x4e, 0x67, 0x5a, 0xf8, 0x5e, 0x28, 0x7c, 0x7a, 0xd5, 0xc5, 0x2f, 0xc7, 0x86,
-	0x4e, 0x51, 0x29, 0x74, 0x8b, 0x10, 0x1a, 0x18, 0x1e, 0x17, 0xf4, 0xc1, 0xef, 0xc0, 0x66, 0xcc,
-	0x38, 0x02, 0x56, 0x6d, 0x99, 0xb3, 0x87, 0x47, 0x41, 0x3c, 0xf2, 0x64, 0x51, 0x90, 0x05, 0x65,
-	0x43, 0xad, 0xee, 0xe9, 0x8b, 0x6c, 0xbf, 0xe6, 0x74, 0x3f, 0x34, 0x27, 0xab, 0x7d, 0x22, 0x4c,
-	0xc5, 0x3e, 0x96, 0x2b, 0x3f, 0x78, 0x21, 0x4a, 0xc6, 0x34, 0x1c, 0x78, 0x87, 0x43, 0x95, 0xf7,
-	0x33, 0x80, 0x97, 0xd8, 0x60, 0xc4, 0x64, 0x4e, 0x62, 0xda, 0x8f, 0xe9, 0xa9, 0x17, 0x0f, 0x54,
-	0x89, 0x55, 0xb0, 0x2b, 0x50, 0xe7, 0x4f, 0x0b, 0xb0, 0xf9, 0x63, 0x9a, 0x6a, 0x65, 0x69, 0xea,
-	0x63, 0x3b, 0xb0, 0xc6, 0xaa, 0x5a, 0x9c, 0xb2, 0x6a, 0xa1, 0xa7, 0x3a, 0x79, 0x33, 0x6d, 0xb5,
-	0x94, 0xe5, 0xba, 0x5d, 0xd8, 0xc8, 0xd3, 0x67, 0x15, 0xb4, 0xed, 0xae, 0x99, 0x1c, 0xb2, 0x9c,
-	0x7e, 0x08, 0x6d, 0xa6, 0x72, 0x6e, 0x07, 0x4b, 0xec, 0xd0, 0x94, 0x0b, 0x99, 0x7c, 0xa6, 0x8f,
-	0x49, 0x2b, 0xa5, 0x2f, 0x0a, 0x73, 0xb6, 0x75, 0x6a, 0x29, 0xfb, 0x1e, 0x5c, 0x61, 0x0d, 0x61,
-	0x30, 0x9a, 0x8c, 0x98, 0x09, 0x7c, 0x9e, 0x82, 0x8d, 0xda, 0xbc, 0x24, 0xf8, 0x2e, 0x23, 0x89,
-	0x2b, 0x28, 0x74, 0x33, 0x38, 0x7f, 0x67, 0xce, 0x5a, 0x30, 0x0d, 0xde, 0xc9, 0x67, 0x40, 0x18,
-	0x23, 0xbb, 0x5a, 0x43, 0xa4, 0x2c, 0x28, 0x5b, 0x5a, 0xcc, 0xe9, 0x7d, 0x86, 0xdb, 0x16, 0x2c,
-	0xba, 0x3c, 0xd2, 0x83, 0xf5, 0x49, 0x58, 0x22, 0x69, 0xe1, 0x3c, 0x8d, 0xc3, 0x1a, 0xb2, 0x1a,
-	0x5a, 0xb3, 0x26, 0x7b, 0x6b, 0xef, 0xd8, 0x0b, 0x5f, 0xd2, 0xde, 0x34, 0x76, 0xd4, 0x8d, 0x7e,
-	0x0c, 0x16, 0x0b, 0x10, 0x71, 0x83, 0x8d, 0xdd, 0x5b, 0x9a, 0xf0, 0x19, 0x0c, 0x3b, 0x3c, 0x12,
-	0x38, 0x0b, 0x77, 0xfa, 0x88, 0xf5, 0xc6, 0x5a, 0x80, 0xca, 0x8a, 0x57, 0x67, 0x68, 0xc6, 0xc6,
-	0xc9, 0x78, 0xe2, 0xd5, 0xc8, 0xe4, 0x5d, 0xd6, 0x19, 0x9a, 0x91, 0x39, 0xd7, 0xc0, 0x62, 0x92,
-	0x49, 0x15, 0x56, 0x7a, 0x6e, 0xf7, 0x8b, 0xfb, 0x4f, 0x1f, 0xb2, 0x0c, 0x03, 0xb0, 0xdc, 0x7b,
-	0xf6, 0xe0, 0x71, 0x77, 0x8f, 0xe5, 0x15, 0x16, 0x90, 0x45, 0x8d, 0x30, 0x20, 0x7f, 0xcd, 0x1c,
-	0xf6, 0xb3, 0x49, 0xa8, 0x1f, 0xfa, 0xec, 0xa4, 0xc8, 0xcb, 0x9f, 0x17, 0xbf, 0xa4, 0xa9, 0xea,
-	0x37, 0x55, 0xa3, 0x24, 0x40, 0xd9, 0x6d, 0xce, 0x89, 0x58, 0x6b, 0x4e, 0xc4, 0x92, 0x4f, 0xc0,
-	0x0e, 0x42, 0x7f, 0x38, 0x19, 0xd0, 0xfe, 0x34, 0xe4, 0xfc, 0x28, 0x08, 0x0f, 0x99, 0xd6, 0x09,
-	0x66, 0x9a, 0x0e, 0x52, 0x74, 0x91, 0x60, 0x4f, 0xad, 0xf3, 0xa0, 0x51, 0xdc, 0xbe, 0x38, 0x72,
-	0x3f, 0xf1, 0xe3, 0x60, 0x2c, 0x0b, 0xe9, 0xaa, 0xbb, 0x86, 0x8b, 0xd2, 0x1c, 0x07, 0x62, 0xc9,
-	0xf9, 0xab, 0x05, 0x5b, 0x05, 0x13, 0xa0, 0x63, 0xfe, 0x1c, 0x5a, 0x09, 0x9b, 0x68, 0x7c, 0x5e,
-	0x67, 0x23, 0xd1, 0x3b, 0x2b, 0xb7, 0xfc, 0x96, 0x76, 0xdf, 0x33, 0xb8, 0x77, 0x7a, 0xd8, 0x7f,
-	0xe3, 0xac, 0xd0, 0x54, 0xa2, 0xe4, 0x77, 0xc2, 0xcb, 0x9d, 0x6c, 0x23, 0x0c, 0x33, 0x56, 0x05,
-	0x86, 0x56, 0xbc, 0x0d, 0x2d, 0x3c, 0xc8, 0xf8, 0x44, 0x9d, 0x45, 0x3a, 0x41, 0x43, 0xe2, 0xbd,
-	0x13, 0x79, 0x0c, 0xfb, 0x3f, 0x15, 0x68, 0x98, 0x1b, 0xf2, 0x21, 0x42, 0x0b, 0x03, 0x3d, 0xdf,
-	0x34, 0x35, 0x5c, 0x64, 0x03, 0xa6, 0x8a, 0x3c, 0x5f, 0x5f, 0x0e, 0x06, 0xb2, 0x26, 0x54, 0x25,
-	0xd6, 0x15, 0xe3, 0x01, 0xcb, 0xf7, 0xc6, 0x78, 0x81, 0x5f, 0xe4, 0x0a, 0x5c, 0xca, 0x74, 0x5b,
-	0x14, 0xe2, 0x57, 0xc7, 0xa8, 0x15, 0x97, 0xcb, 0xb3, 0x05, 0xef, 0x75, 0x79, 0x5f, 0x8f, 0xf3,
-	0x51, 0x15, 0xb1, 0xa7, 0x81, 0x6c, 0xa6, 0x8e, 0xe2, 0x68, 0x34, 0xbd, 0x65, 0xd1, 0xc6, 0xac,
-	0xba, 0x35, 0x0e, 0xaa, 0x9b, 0x75, 0xfe, 0x58, 0x81, 0xcd, 0x83, 0xe0, 0x65, 0x58, 0xe2, 0xa7,
-	0x67, 0x55, 0x3a, 0xe6, 0x88, 0x09, 0x8d, 0x03, 0x6f, 0x18, 0xfc, 0xca, 0xcc, 0x0b, 0x18, 0x74,
-	0x1b, 0xd9, 0xaa, 0x26, 0x9d, 0xab, 0x15, 0x84, 0x53, 0x83, 0x50, 0x39, 0x54, 0xd6, 0xdd, 0x9a,
-	0x00, 0xbb, 0x12, 0x73, 0x5e, 0xc1, 0x56, 0x41, 0x2b, 0x74, 0x9d, 0xdc, 0xbc, 0x5a, 0x29, 0xce,
-	0xab, 0x77, 0x61, 0x73, 0x12, 0x26, 0x8c, 0x9d, 0xa9, 0x65, 0x6e, 0xb5, 0x20, 0xb6, 0x5a, 0x57,
-	0xab, 0x5d, 0x7d, 0xcb, 0x9f, 0xc0, 0xe5, 0xde, 0xe4, 0x70, 0x18, 0x24, 0xc7, 0x25, 0xb6, 0xf8,
-	0x06, 0x10, 0x14, 0x58, 0xdc, 0xbb, 0x2d, 0x57, 0x34, 0x2e, 0xe7, 0x2a, 0xd8, 0x65, 0xb2, 0x30,
-	0x37, 0xbc, 0x03, 0xd7, 0x35, 0x78, 0x3f, 0x4a, 0x83, 0xa3, 0xc0, 0xf7, 0xf4, 0xa2, 0xe6, 0x7c,
-	0xb9, 0x00, 0x37, 0x66, 0xd3, 0xa0, 0x25, 0x3e, 0x85, 0xa6, 0x97, 0xa6, 0x9e, 0x7f, 0xcc, 0xd4,
-	0x12, 0xb5, 0xe6, 0xcc, 0xd4, 0xde, 0x50, 0xf4, 0x02, 0x4d, 0x78, 0xfd, 0x1d, 0x50, 0x53, 0x02,
-	0x37, 0x11, 0x0b, 0x02, 0x05, 0x23, 0xe1, 0xac, 0x02, 0x60, 0x7d, 0xd5, 0x02, 0xc0, 0xf3, 0x51,
-	0x89, 0x44, 0x11, 0x4b, 0x54, 0x4e, 0xa4, 0x35, 0xb7, 0x53, 0x64, 0xfc, 0x5c, 0xac, 0x3b, 0xbf,
-	0xab, 0xc0, 0xf6, 0x01, 0x6b, 0x23, 0xd2, 0x90, 0xf5, 0x6b, 0x65, 0x16, 0x9c, 0x93, 0x65, 0x59,
-	0x31, 0x0f, 0xa3, 0x7e, 0xc8, 0x99, 0xde, 0xf6, 0x99, 0x2b, 0x70, 0x31, 0xc2, 0x65, 0x57, 0xdd,
-	0x66, 0x18, 0x09, 0x61, 0x6f, 0x9f, 0x49, 0x98, 0xf7, 0x6c, 0x19, 0xad, 0xa4, 0x94, 0x73, 0x7a,
-	0x5d, 0x51, 0x0a, 0x2d, 0x9c, 0xdf, 0x2f, 0xc0, 0xb5, 0x59, 0xfa, 0xe0, 0x6d, 0xfd, 0x7f, 0x93,
-	0xc6, 0x23, 0x58, 0x11, 0x6d, 0x14, 0x95, 0xaf, 0x4a, 0x66, 0xde, 0x9c, 0xaf, 0x89, 0x58, 0x66,
-	0x8c, 0xae, 0x92, 0x60, 0x3f, 0x83, 0x15, 0xc4, 0x2e, 0xa2, 0xe5, 0x75, 0xa8, 0x6a, 0xd1, 0x85,
-	0x4a, 0x42, 0x16, 0xc6, 0xce, 0x36, 0x5c, 0x51, 0xc3, 0x72, 0x99, 0x8f, 0xff, 0xb7, 0x02, 0x57,
-	0xcb, 0xd7, 0x2f, 0x34, 0x7b, 0x9c, 0x67, 0xae, 0x2c, 0x1f, 0x19, 0xad, 0x0b, 0x8d, 0x8c, 0x8b,
-	0x17, 0x1a, 0x19, 0x97, 0x66, 0x8c, 0x8c, 0xbf, 0xad, 0xc0, 0xda, 0x5e, 0x4c, 0x59, 0xfb, 0xfe,
-	0x5c, 0x5c, 0x97, 0x72, 0xd7, 0x8f, 0xa0, 0x3d, 0xe6, 0x19, 0xc3, 0xef, 0x17, 0x72, 0x6e, 0x4b,
-	0x2e, 0x68, 0xfd, 0x0b, 0xcb, 0x46, 0x6a, 0x92, 0x28, 0xb4, 0x3a, 0x6d, 0x5c, 0xd1, 0xc8, 0x09,
-	0x2c, 0x26, 0x94, 0x0e, 0xb0, 0xbe, 0x89, 0xdf, 0xce, 0x26, 0xac, 0x9b, 0x6a, 0x60, 0x6e, 0xfa,
-	0x14, 0xda, 0x4f, 0x98, 0x2b, 0x7c, 0x75, 0xe5, 0x9c, 0x75, 0x20, 0xba, 0x04, 0x94, 0xcb, 0xd0,
-	0xbd, 0x61, 0x94, 0x98, 0xa7, 0x76, 0x36, 0x98, 0x31, 0x74, 0x14, 0x89, 0x19, 0x2c, 0x91, 0x87,
-	0x6f, 0x82, 0x24, 0x7b, 0x29, 0xd9, 0x81, 0x75, 0x13, 0x46, 0x3f, 0x61, 0x05, 0x94, 0x0a, 0x44,
-	0xe8, 0xc4, 0x06, 0x26, 0xf9, 0xe5, 0x7c, 0x59, 0x81, 0xce, 0x01, 0xef, 0xe6, 0xf7, 0x38, 0x59,
-	0x98, 0x4c, 0x12, 0x77, 0xec, 0xab, 0x33, 0xb1, 0xd4, 0x87, 0x8f, 0x44, 0x7d, 0x73, 0x0a, 0x6c,
-	0x20, 0x8c, 0xe3, 0x22, 0x7f, 0xa3, 0x9b, 0x24, 0xfc, 0xca, 0xa7, 0xae, 0x35, 0xfd, 0xe6, 0x6b,
-	0xdc, 0x22, 0x8c, 0x5c, 0x59, 0x77, 0xfa, 0xcd, 0xeb, 0x94, 0x4f, 0x63, 0xf4, 0x6b, 0x8a, 0x05,
-	0x5c, 0x87, 0x9c, 0x2b, 0x70, 0xb9, 0x44, 0x3d, 0x79, 0xa8, 0x5d, 0x77, 0xfa, 0x2e, 0x7d, 0x40,
-	0xe3, 0xd7, 0x81, 0xcf, 0xd3, 0xfd, 0x0a, 0x22, 0xe4, 0xb2, 0x16, 0xec, 0xe6, 0xeb, 0xb5, 0x6d,
-	0x97, 0x2d, 0xa1, 0xcc, 0x7f, 0x57, 0xa1, 0x2e, 0x2d, 0xa8, 0x64, 0x7e, 0x0f, 0x16, 0xf9, 0x33,
-	0x1b, 0xd9, 0xd4, 0xb8, 0xb4, 0x67, 0x38, 0x7b, 0xab, 0x80, 0x4f, 0x6b, 0xcf, 0x0a, 0x3e, 0xa7,
-	0x19, 0xca, 0x98, 0x6f, 0x74, 0x86, 0x32, 0xf9, 0xc7, 0x3a, 0x17, 0xea, 0xc6, 0x53, 0x1a, 0xb9,
-	0x5e, 0x7c, 0xe1, 0x32, 0xde, 0xe7, 0xec, 0x1b, 0xb3, 0x09, 0x50, 0xe6, 0x1e, 0xac, 0xaa, 0xb7,
-	0x31, 0x62, 0x97, 0x3e, 0x98, 0x49, 0x49, 0x57, 0xe6, 0x3c, 0xa6, 0xf1, 0xa3, 0xa9, 0xa7, 0x26,
-	0xfd, 0x68, 0xe6, 0x7c, 0x6d, 0x1c, 0x2d, 0x3f, 0x0a, 0xbf, 0x80, 0x66, 0x6e, 0x22, 0x23, 0xef,
-	0x68, 0xe4, 0xe5, 0x83, 0xac, 0xed, 0xcc, 0x23, 0x41, 0xc9, 0x13, 0xe8, 0xcc, 0x6a, 0x0b, 0xc8,
-	0x87, 0xe5, 0x55, 0xb8, 0x2c, 0xf7, 0xda, 0x1f, 0x9d, 0x8b, 0x56, 0x6e, 0x7a, 0xa7, 0x42, 0x22,
-	0xd6, 0x24, 0x96, 0xd6, 0x14, 0x72, 0xfb, 0x1c, 0x65, 0x47, 0x6e, 0xf9, 0xc1, 0xb9, 0x0b, 0x14,
-	0xdb, 0x30, 0xc8, 0x9e, 0x68, 0x8d, 0xed, 0x6e, 0x95, 0xb8, 0x40, 0xd9, 0x66, 0xef, 0x9f, 0x49,
-	0x37, 0xdd, 0xea, 0x67, 0xd0, 0xca, 0x4f, 0x71, 0xc4, 0x39, 0x7b, 0xe8, 0xb4, 0x6f, 0xce, 0xa5,
-	0xc9, 0x9c, 0xdc, 0x78, 0xc7, 0x33, 0x9c, 0xbc, 0xec, 0xed, 0xd0, 0x70, 0xf2, 0xd2, 0x27, 0x40,
-	0xf2, 0x18, 0xaa, 0xda, 0x4b, 0x1d, 0xd9, 0xce, 0xbf, 0x9d, 0x99, 0xf2, 0xae, 0xcd, 0x5a, 0xce,
-	0x49, 0xc3, 0x6c, 0xb7, 0x3d, 0xf7, 0x25, 0xae, 0x28, 0x2d, 0xf7, 0xa6, 0xc6, 0x8c, 0x99, 0x7f,
-	0xa3, 0x32, 0x8c, 0x39, 0xe3, 0x55, 0xcd, 0x30, 0xe6, 0xac, 0x47, 0x2e, 0x1e, 0x56, 0xb9, 0x89,
-	0xd0, 0x08, 0xab, 0xf2, 0x71, 0xdb, 0x08, 0xab, 0x59, 0xe3, 0x28, 0x93, 0x9c, 0x1b, 0x37, 0x0c,
-	0xc9, 0xe5, 0x03, 0x92, 0x21, 0x79, 0xd6, 0xb4, 0xe2, 0x01, 0x29, 0x4e, 0x02, 0x44, 0xff, 0x0f,
-	0x6c, 0xe6, 0xd0, 0x61, 0xbf, 0x77, 0x06, 0x15, 0x66, 0xf5, 0xbf, 0x59, 0xaa, 0x5c, 0x3e, 0x8e,
-	0x3c, 0xd6, 0xc3, 0xa9, 0xdc, 0xfe, 0x04, 0x6a, 0x7a, 0xb9, 0x24, 0xfa, 0xdd, 0x95, 0x94, 0x57,
-	0xfb, 0xfa, 0xcc, 0x75, 0x3c, 0x0b, 0x13, 0xa8, 0xf7, 0x0c, 0x86, 0xc0, 0x92, 0x9e, 0xc6, 0x10,
-	0x58, 0xd6, 0x6c, 0x90, 0x2e, 0x40, 0xd6, 0x2a, 0x90, 0xab, 0x1a, 0x79, 0xa1, 0x07, 0xb1, 0xb7,
-	0x67, 0xac, 0x66, 0x6e, 0xac, 0x75, 0x12, 0x86, 0x1b, 0x17, 0xfb, 0x0e, 0xc3, 0x8d, 0x4b, 0x1a,
-	0x10, 0xf2, 0x0b, 0x68, 0x17, 0x2a, 0x33, 0xd1, 0x7d, 0x74, 0x56, 0x5b, 0x61, 0xbf, 0x3b, 0x9f,
-	0x48, 0xca, 0x3f, 0x5c, 0x16, 0x7f, 0x43, 0x7f, 0xfb, 0x7f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x22,
-	0xfc, 0x70, 0xb3, 0x93, 0x1e, 0x00, 0x00,
+	// 3962 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xad, 0x1b, 0xcb, 0x72, 0x1c, 0x49,
+	0x91, 0xd1, 0x48, 0x1a, 0x29, 0x25, 0x8d, 0xa4, 0xd6, 0x6b, 0x3c, 0xb6, 0xd6, 0x76, 0xdb, 0xeb,
+	0xf5, 0xee, 0x62, 0xad, 0xd1, 0x3e, 0x58, 0x02, 0x30, 0x6b, 0xcb, 0xf2, 0xae, 0xb0, 0x2d, 0x8b,
+	0x96, 0x1f, 0x1b, 0xb1, 0xc0, 0x44, 0xcf, 0x4c, 0x49, 0x6a, 0x34, 0xd3, 0x33, 0xee, 0xee, 0xb1,
+	0xac, 0x3d, 0x10, 0xc0, 0x9d, 0x0b, 0x70, 0x20, 0x20, 0x08, 0x22, 0x38, 0x70, 0x26, 0x82, 0xcb,
+	0x46, 0x70, 0x00, 0x22, 0xb8, 0xf0, 0x03, 0x5c, 0xf8, 0x07, 0x0e, 0x70, 0xe2, 0x46, 0x56, 0x55,
+	0x56, 0x77, 0x55, 0x3f, 0x46, 0xd2, 0x2e, 0x27, 0x4d, 0x65, 0x65, 0x65, 0x66, 0x65, 0x65, 0xe5,
+	0xa3, 0xb2, 0x05, 0x93, 0x6e, 0xdf, 0x5b, 0xeb, 0x07, 0xbd, 0xa8, 0x67, 0x4d, 0x1e, 0xb9, 0x9d,
+	0x0e, 0x8b, 0x82, 0x7e, 0xcb, 0x9e, 0x83, 0xea, 0x53, 0x16, 0x84, 0x5e, 0xcf, 0x77, 0xd8, 0xf3,
+	0x01, 0x0b, 0x23, 0xfb, 0xaf, 0x25, 0x98, 0x8d, 0x41, 0x61, 0xbf, 0xe7, 0x87, 0xcc, 0x7a, 0x15,
+	0xaa, 0x2f, 0x24, 0xa8, 0x11, 0x46, 0x81, 0xe7, 0xef, 0xd7, 0x4a, 0x97, 0x4a, 0xd7, 0x27, 0x9d,
+	0x19, 0x82, 0xee, 0x0a, 0xa0, 0xb5, 0x08, 0x63, 0x5d, 0xf7, 0x07, 0xbd, 0xa0, 0x36, 0x82, 0xb3,
+	0x33, 0x8e, 0x1c, 0x08, 0xa8, 0xe7, 0x23, 0xb4, 0x4c, 0x50, 0x3e, 0xe0, 0xd0, 0xbe, 0x1b, 0xb5,
+	0x0e, 0x6a, 0xa3, 0x12, 0x2a, 0x06, 0xd6, 0x2b, 0x00, 0xfd, 0x80, 0x05, 0xac, 0xc3, 0xdc, 0x90,
+	0xd5, 0xc6, 0x04, 0x13, 0x0d, 0xc2, 0x05, 0x69, 0x0e, 0xbc, 0x4e, 0xbb, 0xd1, 0x65, 0x91, 0xdb,
+	0x76, 0x23, 0xb7, 0x36, 0x2e, 0x05, 0x11, 0xd0, 0x87, 0x04, 0xb4, 0xff, 0x55, 0x06, 0xeb, 0x71,
+	0xe0, 0xfa, 0xa1, 0xdb, 0x8a, 0x50, 0xbc, 0xbb, 0x08, 0xf7, 0x3a, 0xa1, 0x65, 0xc1, 0xe8, 0x81,
+	0x1b, 0x1e, 0x08, 0xe1, 0xa7, 0x1d, 0xf1, 0xdb, 0xba, 0x04, 0x53, 0x51, 0x82, 0x29, 0x24, 0x9f,
+	0x76, 0x74, 0x90, 0xf5, 0x75, 0x18, 0x6f, 0xb3, 0xa6, 0x17, 0x85, 0xb8, 0x81, 0xf2, 0xf5, 0xa9,
+	0xf5, 0x2b, 0x6b, 0xb1, 0xfa, 0xd6, 0xb2, 0x4c, 0xd6, 0xb6, 0xfc, 0xfe, 0x20, 0x72, 0x68, 0x89,
+	0x75, 0x0b, 0x2a, 0xad, 0x80, 0xb5, 0xf9, 0xea, 0x51, 0xb1, 0xfa, 0xea, 0xf0, 0xd5, 0x8f, 0x06,
+	0x11, 0x5f, 0xae, 0x16, 0x59, 0x73, 0x50, 0xde, 0x63, 0x52, 0x13, 0x65, 0x87, 0xff, 0xb4, 0x2e,
+	0xc0, 0x64, 0xe4, 0x75, 0xf1, 0xa4, 0xdc, 0x6e, 0x5f, 0xec, 0xbe, 0xec, 0x24, 0x00, 0xae, 0xd6,
+	0x8e, 0xdb, 0x64, 0x9d, 0x5a, 0x45, 0xe8, 0x45, 0x0e, 0xea, 0xcf, 0x61, 0x4c, 0x88, 0xc5, 0xa7,
+	0x3d, 0xbf, 0xcd, 0x5e, 0x0a, 0x15, 0xa0, 0xd6, 0xc5, 0xc0, 0x7a, 0x1d, 0xe6, 0x50, 0xc7, 0x2f,
+	0xbc, 0xde, 0x20, 0x6c, 0xb8, 0xad, 0x56, 0x6f, 0xe0, 0x47, 0x74, 0x84, 0xb3, 0x0a, 0x7e, 0x5b,
+	0x82, 0xad, 0xd7, 0x60, 0x36, 0x41, 0xed, 0x0a, 0xcc, 0xb2, 0x90, 0xa1, 0x1a, 0x63, 0x0a, 0x68,
+	0xbd, 0x03, 0xe3, 0x72, 0x2f, 0x05, 0x3c, 0x6b, 0x50, 0x31, 0x59, 0xa9, 0xa1, 0x55, 0x87, 0x09,
+	0xcf, 0x8f, 0x58, 0xe0, 0xbb, 0x1d, 0x41, 0x7b, 0xc2, 0x89, 0xc7, 0xd6, 0x32, 0x8c, 0x13, 0xd7,
+	0x51, 0xc1, 0x95, 0x46, 0xf6, 0xaf, 0x4b, 0x30, 0x7d, 0xa7, 0xd3, 0x6b, 0x1d, 0x0e, 0x3b, 0x6a,
+	0x5c, 0x7c, 0xc0, 0xbc, 0xfd, 0x03, 0xc9, 0x71, 0xcc, 0xa1, 0x91, 0xa9, 0xd1, 0x72, 0x5a, 0xa3,
+	0xb7, 0x61, 0x5a, 0xb3, 0x06, 0x75, 0x8c, 0xab, 0x43, 0x8f, 0xd1, 0x31, 0x96, 0xd8, 0x8f, 0xa0,
+	0x4a, 0xfa, 0xbb, 0xe3, 0x76, 0x5c, 0xbf, 0xc5, 0xf4, 0xdd, 0x97, 0xcc, 0xdd, 0x5f, 0x81, 0x99,
+	0xa8, 0x17, 0xb9, 0x9d, 0x46, 0x53, 0xa2, 0x0a, 0x59, 0xcb, 0x48, 0x90, 0x03, 0x69, 0xb9, 0xfd,
+	0x31, 0x4c, 0xa0, 0x72, 0x77, 0x7a, 0xa8, 0x17, 0x7e, 0x78, 0x1a, 0xb3, 0x86, 0xb6, 0xeb, 0x59,
+	0x0d, 0xfe, 0x11, 0x57, 0xc0, 0x65, 0x98, 0xee, 0x89, 0x33, 0x69, 0xc8, 0x03, 0x91, 0x8a, 0x9f,
+	0x92, 0xb0, 0x2d, 0x0e, 0xb2, 0x67, 0x60, 0x6a, 0x07, 0xaf, 0xb2, 0x72, 0x06, 0x55, 0x98, 0x96,
+	0x43, 0xe9, 0x08, 0xb8, 0xbb, 0xd8, 0x66, 0xd1, 0x51, 0x2f, 0x38, 0x54, 0x18, 0xef, 0xc3, 0x6c,
+	0x0c, 0x49, 0xbc, 0x05, 0x67, 0xfa, 0x82, 0x35, 0x7c, 0x39, 0x43, 0x7b, 0x9c, 0x91, 0x50, 0x42,
+	0xb7, 0xbf, 0x06, 0x8b, 0xa4, 0x95, 0xed, 0x41, 0xb7, 0xc9, 0x02, 0xa2, 0xc8, 0xa5, 0x24, 0x65,
+	0x34, 0x7c, 0xb7, 0xcb, 0xc8, 0xd5, 0x4c, 0x11, 0x6c, 0x1b, 0x41, 0xf6, 0x2d, 0x58, 0x4a, 0x2d,
+	0xd5, 0x59, 0xd3, 0x5a, 0x31, 0x93, 0xb0, 0xd6, 0xd0, 0xed, 0x79, 0x98, 0xa5, 0xf5, 0xa1, 0xda,
+	0xc7, 0x67, 0x65, 0x98, 0x4b, 0x60, 0x44, 0xee, 0x5b, 0x30, 0x41, 0x0b, 0x43, 0x24, 0x94, 0xbe,
+	0xfc, 0x69, 0x74, 0x05, 0x70, 0xe2, 0x45, 0xd6, 0x97, 0xc1, 0x6a, 0x0d, 0x82, 0x80, 0xa1, 0x3c,
+	0x4d, 0x6e, 0x9e, 0xf2, 0x78, 0xa4, 0x93, 0x99, 0xa3, 0x19, 0x61, 0xb7, 0xe2, 0x7c, 0x6e, 0xc2,
+	0x62, 0x0a, 0x5b, 0x9a, 0x6b, 0x59, 0x98, 0xab, 0x65, 0xe0, 0x8b, 0x99, 0xfa, 0x4f, 0x46, 0xa0,
+	0xa2, 0xae, 0xe6, 0xe9, 0xf6, 0x9e, 0x51, 0xef, 0x48, 0x46, 0xbd, 0x59, 0x1b, 0x2c, 0x67, 0x6d,
+	0x90, 0x6f, 0x8d, 0xbd, 0x94, 0xd7, 0xb2, 0x71, 0xc8, 0x8e, 0x1b, 0xad, 0xf8, 0x5a, 0xce, 0x38,
+	0x73, 0x6a, 0xe6, 0x3e, 0x3b, 0xde, 0x10, 0xc2, 0x21, 0xb6, 0xba, 0xc4, 0x1a, 0xf6, 0x98, 0xc4,
+	0x56, 0x33, 0x06, 0x76, 0xb7, 0xdf, 0x0b, 0x22, 0xd6, 0xd6, 0xb0, 0xc7, 0x09, 0x9b, 0x66, 0x14,
+	0x36, 0xde, 0x86, 0x45, 0x87, 0xf1, 0xbd, 0x28, 0xfd, 0x93, 0x21, 0x9d, 0x52, 0x21, 0xe7, 0x60,
+	0xc2, 0x67, 0x47, 0xba, 0x32, 0x2a, 0x38, 0x16, 0x76, 0xb6, 0x02, 0x4b, 0x29, 0xca, 0x74, 0x0f,
+	0x9e, 0x81, 0xb5, 0x8d, 0x7b, 0x4c, 0x31, 0xe4, 0xd1, 0xcb, 0x0d, 0xc3, 0xfe, 0x41, 0xc0, 0xa3,
+	0x97, 0xbc, 0x84, 0x1a, 0xe4, 0x14, 0xaa, 0xb7, 0xbf, 0x01, 0x0b, 0x06, 0xe1, 0xb3, 0xd9, 0xf5,
+	0xaf, 0x4a, 0x24, 0x57, 0xbb, 0x1d, 0xb0, 0x50, 0xd9, 0xf6, 0x10, 0x6f, 0xf3, 0x1e, 0x8c, 0x1e,
+	0xa2, 0x2f, 0x10, 0x92, 0x54, 0xd7, 0x6d, 0xcd, 0xb8, 0xb3, 0x64, 0xd6, 0xee, 0x23, 0xa6, 0x23,
+	0xf0, 0xed, 0x75, 0x18, 0xe5, 0x23, 0xf4, 0xed, 0x73, 0x77, 0xb6, 0x76, 0x6e, 0xde, 0x7c, 0xe7,
+	0x9d, 0xc6, 0xe6, 0xc7, 0x8f, 0x37, 0x9d, 0xed, 0xdb, 0x0f, 0xe6, 0xbe, 0xa4, 0x43, 0xb7, 0xb6,
+	0x09, 0x5a, 0xb2, 0xdf, 0xa2, 0xad, 0x29, 0xa2, 0xb4, 0x35, 0x2e, 0x9c, 0x04, 0xd1, 0x4d, 0x57,
+	0x43, 0xfb, 0xe7, 0x25, 0x58, 0xd9, 0x12, 0x87, 0xbd, 0x13, 0x78, 0x2f, 0xdc, 0x88, 0xe1, 0x89,
+	0x9f, 0x56, 0xd5, 0xc5, 0xe1, 0xe5, 0x1a, 0x8f, 0x60, 0x82, 0x9c, 0x30, 0xad, 0x23, 0x6f, 0x4f,
+	0x98, 0x37, 0xe6, 0x10, 0xfd, 0x98, 0xcb, 0x33, 0x6f, 0x8f, 0x47, 0x0b, 0x94, 0xa2, 0xe5, 0xfa,
+	0xc2, 0xa6, 0x27, 0x1c, 0x1a, 0xd9, 0x75, 0xa8, 0x65, 0x85, 0x22, 0xb3, 0xf0, 0xa1, 0x4a, 0xd7,
+	0xe3, 0x8c, 0x36, 0xf8, 0x2e, 0x2c, 0x07, 0xb8, 0xc2, 0xc3, 0xa8, 0x8f, 0xc6, 0xee, 0xef, 0x79,
+	0x41, 0xd7, 0x95, 0xe1, 0x46, 0x86, 0xaa, 0x25, 0x35, 0xbb, 0xa1, 0x4f, 0x22, 0xbf, 0xd9, 0x98,
+	0x1f, 0xa9, 0x13, 0xa3, 0xad, 0xb8, 0xa6, 0x82, 0x4f, 0xd9, 0x91, 0x03, 0x1e, 0xe2, 0xc2, 0x3e,
+	0xf3, 0xdb, 0x6e, 0xb3, 0xa3, 0x22, 0x4a, 0x02, 0xe0, 0x41, 0xdd, 0xeb, 0x22, 0xcd, 0x41, 0xc0,
+	0x1a, 0x01, 0x3b, 0x72, 0x83, 0xb6, 0x0a, 0xea, 0x0a, 0xec, 0x08, 0xa8, 0xfd, 0xcb, 0x11, 0x58,
+	0xfe, 0x90, 0x45, 0x5a, 0xc0, 0x8b, 0x6d, 0x6c, 0x0d, 0x16, 0x30, 0x5e, 0x06, 0x11, 0x46, 0x0b,
+	0xdd, 0xd5, 0xc9, 0x93, 0x99, 0x57, 0x53, 0x89, 0xaf, 0x5b, 0x87, 0xa5, 0x34, 0x7e, 0x12, 0x9b,
+	0xe7, 0x9d, 0x05, 0x73, 0x85, 0x0c, 0xd4, 0x6f, 0xc0, 0x3c, 0x8a, 0x9c, 0xe2, 0x50, 0x96, 0xb1,
+	0x4e, 0x4e, 0x24, 0xf4, 0x51, 0x1e, 0x13, 0x57, 0x52, 0x1f, 0x15, 0xea, 0x9c, 0xd7, 0xb1, 0x25,
+	0xed, 0x5b, 0x70, 0x1e, 0x13, 0x53, 0xaf, 0x3b, 0xe8, 0xa2, 0x0a, 0x5a, 0xdc, 0x05, 0x1b, 0x51,
+	0x7f, 0x4c, 0xac, 0x3b, 0x47, 0x28, 0x8e, 0xc0, 0xd0, 0xd5, 0x60, 0xff, 0x11, 0x8d, 0x35, 0xa3,
+	0x1a, 0x3a, 0x93, 0x7b, 0x60, 0xe1, 0x42, 0x3c, 0x5a, 0x83, 0xa4, 0x0c, 0x28, 0x2b, 0xda, 0x9d,
+	0xd3, 0x33, 0x18, 0x67, 0x5e, 0x2c, 0xd1, 0xe9, 0x59, 0x3b, 0xb0, 0x38, 0xf0, 0x73, 0x28, 0x8d,
+	0x9c, 0x26, 0x25, 0x59, 0xa0, 0xa5, 0x86, 0xd4, 0x35, 0x71, 0x9e, 0xb8, 0x9d, 0x1e, 0x66, 0xf2,
+	0xc7, 0x5b, 0xfe, 0x5e, 0x4f, 0xc5, 0xc3, 0x3f, 0xc8, 0xfd, 0x98, 0x53, 0xb4, 0x1f, 0x8c, 0x0f,
+	0x01, 0xc1, 0x1b, 0xdd, 0x5e, 0x5b, 0xde, 0xbf, 0x09, 0x67, 0x5a, 0x01, 0x1f, 0x22, 0xcc, 0x7a,
+	0x13, 0xe6, 0x63, 0xa4, 0x3d, 0x54, 0x5b, 0x78, 0xc0, 0xa4, 0x9f, 0x99, 0x70, 0xe6, 0xd4, 0xc4,
+	0x3d, 0x82, 0x73, 0x0b, 0x8c, 0x91, 0x8f, 0xd0, 0xb1, 0xf4, 0x8e, 0xa8, 0x5a, 0xa8, 0x2a, 0xf0,
+	0x33, 0x01, 0xe5, 0xc9, 0x21, 0xd6, 0x30, 0xfb, 0xc2, 0x5d, 0xf0, 0xb3, 0x2c, 0x39, 0xf1, 0xd8,
+	0xbe, 0x03, 0x4b, 0xe6, 0x09, 0x28, 0xdb, 0x3c, 0x7d, 0x8a, 0x64, 0xff, 0x6d, 0x3c, 0x6d, 0xe1,
+	0xf1, 0xae, 0x3f, 0x5f, 0xf5, 0x10, 0x27, 0xe4, 0x65, 0x2d, 0x21, 0x27, 0x0d, 0x32, 0xcc, 0x86,
+	0xf0, 0x28, 0x31, 0xd5, 0xa4, 0x74, 0x76, 0x5a, 0x01, 0x1f, 0x23, 0xcc, 0x5a, 0x05, 0xd0, 0xec,
+	0x7c, 0x4c, 0xd0, 0x9e, 0x6c, 0xc6, 0x16, 0x8e, 0xd1, 0xc4, 0x30, 0xed, 0x71, 0x61, 0xa2, 0x53,
+	0x4d, 0xcd, 0xa8, 0x63, 0x0a, 0x82, 0x47, 0x45, 0xde, 0x7b, 0x01, 0x11, 0x0c, 0xae, 0xc2, 0x8c,
+	0xe9, 0x6c, 0x26, 0x04, 0x09, 0x13, 0x88, 0x49, 0xd0, 0xb8, 0xc7, 0x8b, 0x87, 0xb0, 0x36, 0x29,
+	0xec, 0xec, 0x35, 0xcd, 0xce, 0xf2, 0x55, 0xa5, 0x6a, 0x20, 0xb9, 0xcc, 0xba, 0x03, 0x15, 0x99,
+	0x62, 0x86, 0x35, 0x10, 0x14, 0xae, 0x9f, 0x4c, 0x41, 0xd5, 0x41, 0xb4, 0x50, 0xd5, 0x41, 0x53,
+	0x49, 0x1d, 0x74, 0x1e, 0x26, 0xf1, 0x4f, 0xe3, 0xd0, 0xef, 0x1d, 0xf9, 0xb5, 0x69, 0x59, 0x27,
+	0x20, 0xe0, 0x3e, 0x1f, 0xd7, 0xff, 0x5c, 0x52, 0x15, 0xcf, 0x37, 0xb4, 0x82, 0x45, 0x12, 0x13,
+	0x07, 0x38, 0xb5, 0xbe, 0xa0, 0x09, 0xa1, 0x92, 0xe9, 0xa4, 0x8a, 0xa1, 0xda, 0x25, 0xa7, 0xdc,
+	0x19, 0xc9, 0x2b, 0x77, 0x44, 0x68, 0x17, 0xbf, 0x48, 0x20, 0x59, 0xb8, 0x4c, 0x49, 0x98, 0x90,
+	0x89, 0xdb, 0x0f, 0xbf, 0x80, 0x14, 0x4e, 0xc4, 0x6f, 0x3d, 0x4c, 0x8d, 0x19, 0x61, 0xaa, 0xfe,
+	0xa7, 0xd2, 0x09, 0x05, 0x54, 0x52, 0x0a, 0x8d, 0xe8, 0xa5, 0x10, 0xd7, 0x4b, 0xff, 0xb0, 0x11,
+	0xb6, 0x02, 0xaf, 0x1f, 0x91, 0x73, 0x9c, 0xe8, 0x1f, 0xee, 0x8a, 0x31, 0x8f, 0x03, 0x14, 0x5d,
+	0x99, 0xac, 0x64, 0x26, 0x9d, 0x04, 0x10, 0x4b, 0x38, 0x96, 0x2f, 0xe1, 0x78, 0x71, 0x9d, 0x56,
+	0x31, 0xeb, 0x34, 0x1e, 0x24, 0x1f, 0x78, 0x61, 0xf4, 0x00, 0x2d, 0x8d, 0xb5, 0xe5, 0x36, 0xe2,
+	0x4c, 0xfb, 0xdb, 0x70, 0x2e, 0x67, 0x8e, 0x2e, 0xd9, 0x8d, 0xc4, 0x56, 0xa4, 0x7f, 0xcc, 0x3d,
+	0x26, 0x85, 0x83, 0x79, 0xc8, 0xf2, 0x53, 0xb7, 0xe3, 0x61, 0xd1, 0xcf, 0x72, 0x72, 0x9e, 0xfc,
+	0xb4, 0xe2, 0x1f, 0xe8, 0xd9, 0x32, 0x8b, 0x88, 0x3d, 0xe6, 0x82, 0x5e, 0xd8, 0x78, 0xc1, 0x67,
+	0xc9, 0xa9, 0x55, 0xbc, 0x50, 0x20, 0x5b, 0x2b, 0x80, 0x3f, 0x1b, 0x42, 0x3f, 0xd2, 0x8b, 0x8d,
+	0x7b, 0xe1, 0xc3, 0x94, 0x86, 0xca, 0xa6, 0x86, 0x2e, 0xc2, 0x14, 0x2e, 0x89, 0x95, 0x24, 0x0f,
+	0x1e, 0xbc, 0x70, 0x4b, 0x95, 0xb3, 0x78, 0x56, 0x88, 0x40, 0x67, 0x35, 0x46, 0x3a, 0x0c, 0xe9,
+	0xac, 0x90, 0x61, 0x7f, 0xd0, 0xe4, 0x49, 0x8a, 0xd0, 0xfc, 0xb4, 0x33, 0x8e, 0x43, 0xcc, 0x36,
+	0xf8, 0xc9, 0xd3, 0x92, 0x8a, 0x84, 0xcb, 0x91, 0x7d, 0x00, 0x8b, 0x4f, 0x59, 0xe0, 0xed, 0x1d,
+	0x3f, 0xc4, 0x2d, 0xb9, 0xfb, 0xec, 0x44, 0x55, 0xf0, 0x99, 0xae, 0xc4, 0x55, 0x99, 0x2f, 0x0d,
+	0x45, 0xc2, 0xe0, 0xed, 0xfb, 0x22, 0xf8, 0x93, 0x15, 0x25, 0x00, 0xfb, 0x06, 0x2c, 0xa5, 0x38,
+	0x25, 0xd9, 0x87, 0xae, 0x3c, 0x39, 0xe0, 0x4f, 0x4a, 0x2b, 0x1b, 0x07, 0xae, 0xbf, 0xcf, 0x76,
+	0xe2, 0x0c, 0x4d, 0x09, 0xf7, 0x3e, 0x94, 0xf9, 0x0e, 0x4b, 0x22, 0x01, 0xbd, 0xa6, 0x1d, 0x76,
+	0xc1, 0x82, 0x35, 0x9e, 0x6f, 0xf1, 0x25, 0x3c, 0xb5, 0xea, 0x75, 0xda, 0x0d, 0x2d, 0x0d, 0x94,
+	0xee, 0x77, 0x06, 0xa1, 0xc9, 0x32, 0x8e, 0xc6, 0xd3, 0x7b, 0x0d, 0x4d, 0x6e, 0x67, 0x06, 0xa1,
+	0x09, 0x9a, 0xfd, 0x0a, 0x94, 0xb9, 0x6e, 0xa7, 0xa0, 0xb2, 0xe3, 0x6c, 0x3d, 0xbd, 0xfd, 0x78,
+	0x13, 0xf3, 0x58, 0x80, 0xf1, 0x9d, 0x27, 0x77, 0x1e, 0x6c, 0x6d, 0x60, 0xf6, 0x8a, 0x16, 0x9d,
+	0x95, 0x88, 0xd2, 0xbe, 0x1f, 0x61, 0x5a, 0x74, 0x6f, 0xe0, 0xb7, 0x73, 0x42, 0xcf, 0xf0, 0x42,
+	0xdf, 0x0d, 0xf6, 0x59, 0x64, 0x3a, 0x96, 0x69, 0x09, 0x24, 0xb7, 0x52, 0x9c, 0x17, 0x96, 0x87,
+	0xe4, 0x85, 0xe8, 0xf4, 0xea, 0x9e, 0xdf, 0xea, 0x0c, 0xda, 0xac, 0x11, 0x27, 0x76, 0x2d, 0xbc,
+	0x39, 0x4d, 0x57, 0xde, 0x7b, 0x7e, 0x36, 0x35, 0xc2, 0xd8, 0x22, 0x84, 0x0d, 0x35, 0xcf, 0x53,
+	0x33, 0xb5, 0xba, 0x25, 0xb6, 0x6c, 0x5a, 0xe8, 0x02, 0x4d, 0x4a, 0x75, 0x48, 0x63, 0xb5, 0x7f,
+	0x57, 0x86, 0x95, 0x8c, 0x0a, 0xc8, 0x28, 0xbe, 0x0b, 0x73, 0x21, 0xeb, 0xb0, 0x16, 0xaf, 0xe6,
+	0xcc, 0xcb, 0xfd, 0x15, 0xed, 0xbc, 0x0b, 0x56, 0xaf, 0xed, 0x18, 0x1e, 0xd9, 0x99, 0x55, 0xa4,
+	0xc8, 0x73, 0x70, 0xcf, 0x2b, 0x8b, 0x55, 0x43, 0x8d, 0x53, 0x02, 0x46, 0x5a, 0xbc, 0x0e, 0x73,
+	0xb4, 0x91, 0xb4, 0x67, 0xac, 0x4a, 0xf8, 0x0e, 0xf9, 0xc7, 0xfa, 0x3f, 0x4b, 0x50, 0x35, 0x19,
+	0xfe, 0x7f, 0xdf, 0x57, 0x34, 0xaf, 0x5d, 0x2e, 0xf6, 0xda, 0xa3, 0x29, 0xaf, 0x8d, 0x74, 0x29,
+	0x31, 0x90, 0x81, 0x5c, 0xbe, 0x06, 0x4e, 0x11, 0x4c, 0x84, 0x72, 0xb4, 0xa6, 0xbd, 0xa0, 0xd7,
+	0x8d, 0x4f, 0x59, 0xb8, 0x0c, 0x4c, 0xc9, 0x38, 0x50, 0x9d, 0xac, 0xfd, 0x8b, 0x12, 0x2c, 0xef,
+	0xe2, 0x25, 0xce, 0xb1, 0xd3, 0x93, 0xea, 0x29, 0x34, 0xc4, 0x10, 0x6f, 0x3c, 0x5e, 0xe7, 0x4f,
+	0xcd, 0xec, 0x93, 0x2e, 0xdd, 0x52, 0x32, 0xab, 0x51, 0xe7, 0x62, 0x89, 0x24, 0x40, 0x2a, 0x84,
+	0xc9, 0x27, 0xd4, 0x19, 0x67, 0x5a, 0x00, 0xb7, 0x24, 0xcc, 0x7e, 0x0e, 0x2b, 0x19, 0xa9, 0xc8,
+	0x74, 0x52, 0xf9, 0x55, 0x29, 0x9b, 0x5f, 0xbd, 0x03, 0xcb, 0x03, 0x9f, 0x7b, 0x26, 0x14, 0xcb,
+	0x64, 0x35, 0x22, 0x58, 0x2d, 0xaa, 0xd9, 0x2d, 0x9d, 0x25, 0xc6, 0xa0, 0x9d, 0x41, 0xb3, 0x83,
+	0xc9, 0x67, 0x8e, 0x2e, 0x6e, 0x80, 0x45, 0x04, 0xb3, 0xbc, 0xe7, 0xe5, 0x8c, 0xb6, 0xca, 0xbe,
+	0x00, 0xf5, 0x3c, 0x5a, 0xe4, 0x1b, 0x7e, 0x3b, 0x02, 0xd6, 0x2e, 0x96, 0x1b, 0x66, 0x10, 0xfc,
+	0x02, 0xf5, 0xeb, 0xad, 0x24, 0x42, 0x96, 0x33, 0x2f, 0xca, 0x59, 0x4e, 0x99, 0x4c, 0xaa, 0xd8,
+	0xa5, 0x8c, 0x0e, 0x73, 0x29, 0x18, 0x19, 0x79, 0xba, 0x15, 0x60, 0xd0, 0x24, 0xfb, 0xab, 0xe0,
+	0xd8, 0xc1, 0x61, 0xfd, 0x9b, 0x71, 0xa6, 0x62, 0x58, 0x71, 0x29, 0x65, 0xc5, 0x05, 0x09, 0x8b,
+	0xfd, 0x01, 0x2c, 0x18, 0x62, 0xd3, 0xd1, 0x9f, 0x21, 0x69, 0xff, 0x04, 0x2c, 0x9e, 0x4d, 0xa4,
+	0x54, 0x7c, 0xb6, 0x54, 0x82, 0x8b, 0x37, 0xf0, 0x79, 0x6a, 0xac, 0xc2, 0xbb, 0x1c, 0xd9, 0x4b,
+	0xb0, 0x60, 0x10, 0xa7, 0x73, 0x3d, 0xc0, 0x63, 0x45, 0x53, 0x48, 0x85, 0xda, 0xd3, 0x1c, 0x2b,
+	0x85, 0xe2, 0x91, 0xc2, 0x50, 0x5c, 0x36, 0x42, 0xb1, 0xfd, 0x36, 0xea, 0x47, 0xe7, 0x44, 0xfa,
+	0x31, 0x22, 0x74, 0x29, 0x1d, 0xa1, 0xbf, 0x03, 0x4b, 0x77, 0x07, 0xdd, 0xfe, 0xe7, 0x7b, 0x38,
+	0xc9, 0x95, 0x10, 0xcf, 0x69, 0x39, 0x4d, 0x92, 0x44, 0xc9, 0x79, 0x52, 0x29, 0xe5, 0x3c, 0xa9,
+	0xd8, 0x97, 0xe1, 0xa2, 0x76, 0x45, 0xb6, 0x7b, 0x91, 0xb7, 0xe7, 0xb5, 0x5c, 0xfd, 0x19, 0xc1,
+	0xfe, 0x6c, 0x1c, 0x2e, 0x15, 0xe3, 0x10, 0xbf, 0x0f, 0x60, 0xd6, 0x8d, 0x22, 0xb7, 0x85, 0x95,
+	0xa3, 0xac, 0xee, 0x4f, 0x2c, 0xa6, 0xab, 0x0a, 0x5f, 0x40, 0x43, 0x9e, 0xd7, 0xb7, 0x99, 0x49,
+	0x81, 0xbb, 0x0b, 0x0c, 0x08, 0x0a, 0x4c, 0x88, 0x45, 0x25, 0x77, 0xf9, 0xf3, 0x96, 0xdc, 0x3c,
+	0x36, 0xe7, 0x50, 0x14, 0xf6, 0x4d, 0x39, 0xf9, 0xb4, 0x53, 0xcb, 0x2e, 0xfc, 0x48, 0xcc, 0x5b,
+	0x9f, 0xc2, 0x0a, 0xbf, 0xb4, 0x1d, 0x4f, 0x44, 0xd3, 0xd4, 0x13, 0x05, 0x17, 0xe9, 0x76, 0xbe,
+	0x48, 0xb9, 0x8a, 0x5c, 0xdb, 0x88, 0x49, 0xe9, 0xae, 0x6b, 0xb9, 0x95, 0x07, 0x0e, 0xad, 0xe7,
+	0xb0, 0x18, 0xb0, 0x1e, 0x66, 0x27, 0x29, 0xc6, 0xe3, 0x82, 0xf1, 0xad, 0xb3, 0x30, 0x76, 0x24,
+	0x1d, 0x9d, 0xeb, 0x42, 0x90, 0x81, 0x85, 0xf5, 0x0e, 0x2c, 0xe5, 0xca, 0x98, 0x5b, 0x8c, 0xa3,
+	0xc7, 0x50, 0x92, 0xf3, 0x77, 0x1f, 0xed, 0xa9, 0x7d, 0x56, 0x83, 0x8b, 0x48, 0x2d, 0x7b, 0x92,
+	0xac, 0x4d, 0x75, 0x9a, 0x1c, 0xf0, 0x9a, 0xcb, 0xca, 0x4a, 0x96, 0xcb, 0xeb, 0x7b, 0x98, 0x83,
+	0x47, 0x78, 0xd5, 0x42, 0x7a, 0x3a, 0xdd, 0xfc, 0x62, 0xbb, 0x5f, 0xdb, 0x15, 0xc4, 0x1c, 0x22,
+	0x8a, 0x75, 0xcd, 0xb8, 0x84, 0xf0, 0x84, 0xf4, 0xc9, 0xf6, 0xc3, 0xad, 0xed, 0xcd, 0xbb, 0x98,
+	0x90, 0x56, 0x01, 0x36, 0x1e, 0x6d, 0xdf, 0xc3, 0x8c, 0xf4, 0x31, 0x8e, 0x4b, 0x7c, 0xd2, 0xd9,
+	0x7c, 0xf8, 0xe8, 0x29, 0x0e, 0x46, 0xec, 0x9f, 0x96, 0x60, 0x75, 0xb7, 0xcf, 0xfc, 0xc8, 0xc7,
+	0xdb, 0x9a, 0x77, 0xb9, 0x86, 0x24, 0xa3, 0x6f, 0xc0, 0xbc, 0xdf, 0x6b, 0xf8, 0x7c, 0xd1, 0x71,
+	0x03, 0x23, 0x26, 0x27, 0x43, 0x7e, 0x70, 0xd6, 0xef, 0x09, 0x62, 0xc7, 0x4f, 0x24, 0x98, 0xdf,
+	0xf6, 0x04, 0x57, 0x62, 0x4a, 0x2d, 0xce, 0x28, 0x4c, 0x21, 0x85, 0xfd, 0xb3, 0x11, 0x78, 0xa5,
+	0x48, 0x9e, 0x33, 0xfb, 0xf8, 0xd3, 0xe4, 0x56, 0xf7, 0xa1, 0x22, 0xde, 0x34, 0x99, 0x6c, 0x35,
+	0x9b, 0xe9, 0xe5, 0x70, 0x49, 0xc4, 0x34, 0x2e, 0x74, 0x14, 0x85, 0xfa, 0x13, 0xa8, 0x10, 0xec,
+	0x2c, 0x52, 0xf2, 0x8a, 0xcf, 0x4f, 0x0b, 0x09, 0x49, 0xb6, 0x63, 0xaf, 0xc2, 0x79, 0xd5, 0xb9,
+	0xca, 0x73, 0x7f, 0xff, 0x2e, 0xc1, 0x85, 0xfc, 0xf9, 0x33, 0x35, 0x02, 0x4e, 0xd3, 0xe4, 0xc9,
+	0xef, 0xdf, 0x94, 0xcf, 0xd4, 0xbf, 0x19, 0x3d, 0x53, 0xff, 0x66, 0xac, 0xa0, 0x7f, 0x83, 0x09,
+	0x94, 0x23, 0xde, 0xd6, 0x73, 0x55, 0xf2, 0x97, 0x12, 0x9c, 0xcf, 0x9d, 0x26, 0x8d, 0x98, 0xaf,
+	0x64, 0xa5, 0xf4, 0x2b, 0x59, 0x51, 0xd3, 0x37, 0x29, 0xbf, 0x8c, 0x26, 0x1b, 0x95, 0x5f, 0xf4,
+	0x7e, 0x86, 0x17, 0xa6, 0xcf, 0x02, 0xfe, 0xd6, 0x4b, 0x8f, 0x8d, 0x6a, 0xc8, 0x5f, 0x37, 0x43,
+	0x86, 0x5e, 0xa5, 0x1d, 0x36, 0x02, 0xd6, 0x75, 0x3d, 0x9f, 0x7f, 0x14, 0x21, 0xf3, 0xa2, 0x39,
+	0x9a, 0x70, 0x14, 0xdc, 0x3e, 0x0f, 0xe7, 0x76, 0x07, 0x4d, 0x9e, 0x16, 0x35, 0xd9, 0xee, 0xb1,
+	0xdf, 0xe2, 0x57, 0x5b, 0xa5, 0x0c, 0x76, 0x08, 0xf5, 0xbc, 0x49, 0xda, 0x1d, 0xaf, 0xf5, 0x11,
+	0xc8, 0x54, 0x45, 0x4d, 0xa3, 0xc2, 0x6d, 0xf1, 0x0f, 0x24, 0xdc, 0xd6, 0x21, 0x1a, 0xae, 0xb9,
+	0xaf, 0x19, 0x82, 0xca, 0x8d, 0xd9, 0x57, 0xc1, 0x36, 0x3b, 0xd2, 0xb9, 0xaa, 0xff, 0x4f, 0x09,
+	0xae, 0x0c, 0x45, 0xd3, 0x5a, 0x38, 0xf9, 0x7e, 0x05, 0xd5, 0x44, 0x39, 0x26, 0x0f, 0xb4, 0x46,
+	0x47, 0x7b, 0x2e, 0x9e, 0x50, 0x1d, 0xc5, 0xb7, 0x00, 0x03, 0x66, 0x16, 0x5d, 0x16, 0x4a, 0x96,
+	0x36, 0xa5, 0x16, 0x60, 0x14, 0x4f, 0xd0, 0xdb, 0xac, 0x13, 0xb9, 0xf4, 0x8e, 0x5a, 0x8d, 0xc1,
+	0x77, 0x39, 0x94, 0x8b, 0xa1, 0x53, 0x96, 0xa8, 0x74, 0x5a, 0xda, 0x84, 0x40, 0xb6, 0xdf, 0x84,
+	0xa5, 0xcd, 0x97, 0xdc, 0x44, 0xef, 0xba, 0x91, 0xdb, 0xd4, 0x9e, 0x2a, 0x30, 0x0e, 0xb4, 0x31,
+	0x01, 0xa2, 0x93, 0x10, 0xbf, 0xed, 0x35, 0x58, 0x4e, 0x23, 0x27, 0x4f, 0x21, 0xad, 0x83, 0x81,
+	0x7f, 0x48, 0x26, 0x29, 0x07, 0xf6, 0xef, 0x4b, 0xb0, 0xb0, 0x11, 0x30, 0x3c, 0xe2, 0x67, 0xc2,
+	0x35, 0x29, 0xda, 0x28, 0x61, 0x9f, 0x17, 0x11, 0xad, 0x46, 0x26, 0x3b, 0x9b, 0x93, 0x13, 0xda,
+	0x93, 0x06, 0x16, 0x28, 0x2a, 0xdf, 0xca, 0xbc, 0x7e, 0xcc, 0xd3, 0x8c, 0x86, 0x8e, 0x72, 0x87,
+	0x8c, 0x62, 0x1d, 0xc6, 0x2f, 0xfe, 0x9b, 0x3b, 0x2a, 0xe9, 0x1b, 0xa5, 0x7f, 0x18, 0x95, 0x5f,
+	0xda, 0x48, 0x90, 0x68, 0x44, 0x2e, 0xc3, 0xa2, 0x29, 0x27, 0xe5, 0xbd, 0x2e, 0xcc, 0x3f, 0x42,
+	0xbf, 0xf8, 0x05, 0xa4, 0x4f, 0xb1, 0x1e, 0xc9, 0xb0, 0x5e, 0x04, 0x4b, 0x67, 0x41, 0x8c, 0xdf,
+	0x05, 0x6b, 0xa3, 0xd3, 0x0b, 0x53, 0x7a, 0x4b, 0x11, 0x2b, 0x65, 0x88, 0x61, 0xfa, 0x6e, 0x2c,
+	0x23, 0x6a, 0xef, 0xc1, 0x82, 0x84, 0x6c, 0xbe, 0xf4, 0xc2, 0xa4, 0x66, 0x38, 0x91, 0xdc, 0x1a,
+	0x2c, 0x9a, 0xeb, 0x92, 0x7b, 0xca, 0x04, 0x44, 0xdd, 0x53, 0x39, 0xe2, 0x7b, 0xe1, 0x8f, 0x9d,
+	0x72, 0x4d, 0x98, 0x7c, 0x34, 0xb1, 0x60, 0x40, 0x89, 0x08, 0x7a, 0x6d, 0x8d, 0xbb, 0x4c, 0x6a,
+	0xd1, 0x6b, 0x27, 0xec, 0x43, 0xfb, 0x37, 0x25, 0xa8, 0xed, 0xf2, 0xd6, 0xd8, 0x06, 0x5f, 0xe1,
+	0x87, 0x98, 0x33, 0xf4, 0x5b, 0x4a, 0x7a, 0xbc, 0x0f, 0xf4, 0xc5, 0x45, 0xc3, 0x7c, 0xf0, 0xab,
+	0x12, 0x98, 0x9e, 0x3b, 0xf9, 0xd3, 0xed, 0x20, 0xe4, 0x2e, 0x3b, 0xd6, 0x7f, 0x3c, 0x16, 0x1d,
+	0x16, 0x3c, 0x2c, 0x44, 0x6f, 0xc7, 0xcf, 0xc7, 0x34, 0xe6, 0xe5, 0x78, 0x8b, 0x05, 0xe4, 0x02,
+	0x18, 0xbd, 0x53, 0xe8, 0x20, 0xe1, 0xea, 0xb2, 0xe2, 0x91, 0xd2, 0xd7, 0x61, 0xfe, 0x2e, 0x6b,
+	0x0e, 0xf6, 0x1f, 0xb0, 0x17, 0xac, 0xa3, 0x84, 0x46, 0xff, 0xdd, 0xe1, 0x63, 0x9e, 0x4a, 0xb4,
+	0x48, 0xde, 0x49, 0x01, 0xc1, 0xf8, 0xdb, 0xe2, 0x6f, 0x16, 0x96, 0xbe, 0x88, 0x54, 0xf5, 0x21,
+	0x40, 0x88, 0x5e, 0xf3, 0x38, 0x8c, 0x58, 0x57, 0x65, 0xff, 0x7a, 0x63, 0x22, 0xbb, 0x64, 0x6d,
+	0x57, 0xe1, 0x3b, 0xda, 0xd2, 0xfa, 0xbb, 0x30, 0x19, 0x4f, 0xf0, 0x9b, 0xa2, 0x9d, 0xbb, 0xf8,
+	0x2d, 0x1a, 0x38, 0x9c, 0x0e, 0x29, 0x4a, 0x0e, 0xec, 0x1f, 0x8f, 0xc0, 0x22, 0xaf, 0xca, 0xa8,
+	0xba, 0x76, 0x8f, 0xd4, 0x76, 0x4e, 0x7e, 0xb1, 0xc0, 0x4a, 0x9a, 0xc7, 0xc4, 0xbe, 0x1b, 0x1d,
+	0xd0, 0x1b, 0x45, 0x05, 0xc7, 0x3b, 0x38, 0xe4, 0xba, 0xa0, 0x7b, 0xc4, 0xdf, 0x44, 0xe9, 0xd9,
+	0x55, 0x42, 0xf8, 0xe3, 0xe4, 0xaa, 0xd8, 0xb4, 0xf9, 0x4a, 0x34, 0x89, 0x90, 0x4c, 0x81, 0x3d,
+	0x96, 0x7e, 0x5b, 0xe2, 0xb1, 0xb1, 0x11, 0x1d, 0xf7, 0x19, 0x3d, 0xe2, 0x4f, 0x70, 0xc0, 0x63,
+	0x1c, 0xa7, 0x33, 0x96, 0x4a, 0x3a, 0x63, 0xe1, 0xce, 0xfd, 0xc8, 0x13, 0x99, 0x93, 0x68, 0x0f,
+	0x4d, 0x38, 0x6a, 0x88, 0x37, 0x72, 0x29, 0xa5, 0x82, 0xd3, 0x94, 0xa6, 0xeb, 0x4e, 0xfc, 0xc9,
+	0xe1, 0x2e, 0x0b, 0x5e, 0x78, 0x2d, 0x5e, 0xcf, 0x55, 0x08, 0x62, 0x9d, 0xd3, 0xce, 0xd0, 0xfc,
+	0x30, 0xb1, 0x5e, 0xcf, 0x9b, 0x92, 0x1c, 0xd7, 0xff, 0xbb, 0x08, 0x33, 0xf2, 0x36, 0x29, 0x9a,
+	0x5f, 0x85, 0x51, 0xfe, 0xe5, 0x92, 0xb5, 0xac, 0xad, 0xd2, 0xbe, 0x6c, 0xaa, 0xaf, 0x64, 0xe0,
+	0x71, 0x71, 0x59, 0xa1, 0x2f, 0x94, 0x0c, 0x61, 0xcc, 0xcf, 0x9e, 0x0c, 0x61, 0xd2, 0xdf, 0x3f,
+	0x39, 0x30, 0x63, 0x7c, 0x9d, 0x64, 0x5d, 0xcc, 0x7e, 0x34, 0x64, 0x7c, 0xf2, 0x54, 0xbf, 0x54,
+	0x8c, 0x40, 0x34, 0x37, 0x60, 0x42, 0x7d, 0x6e, 0x64, 0xd5, 0x73, 0xbf, 0x41, 0x92, 0x94, 0xce,
+	0x0f, 0xf9, 0x3e, 0x89, 0x6f, 0x4d, 0x85, 0x4e, 0x7d, 0x6b, 0xe6, 0x27, 0x0b, 0xc6, 0xd6, 0xd2,
+	0x5f, 0x17, 0x7c, 0x0c, 0xb3, 0xa9, 0x26, 0xb7, 0x75, 0xb9, 0xb0, 0x99, 0x17, 0x0b, 0x65, 0x0f,
+	0x43, 0x31, 0x28, 0xeb, 0xed, 0xe6, 0x34, 0xe5, 0x9c, 0x2e, 0x75, 0x9a, 0x72, 0x6e, 0xb7, 0xfa,
+	0x09, 0x54, 0x4d, 0xa6, 0xd6, 0xa5, 0x21, 0xfd, 0x47, 0x49, 0xf7, 0xf2, 0x89, 0x1d, 0x4a, 0xeb,
+	0xfb, 0x30, 0x9f, 0x69, 0x63, 0x59, 0xfa, 0xe7, 0x61, 0x45, 0x0d, 0xb0, 0xfa, 0xd5, 0xe1, 0x48,
+	0x89, 0x42, 0x52, 0x5d, 0x2a, 0x43, 0x21, 0xf9, 0x6d, 0x2f, 0x43, 0x21, 0x45, 0x4d, 0x2e, 0xb4,
+	0x4f, 0xa3, 0x7b, 0x63, 0xd8, 0x67, 0x5e, 0x07, 0xc9, 0xb0, 0xcf, 0xfc, 0xc6, 0xcf, 0x00, 0x6a,
+	0x45, 0x65, 0xaf, 0xf5, 0xc6, 0xa9, 0x6a, 0x63, 0xc9, 0xe9, 0xcd, 0x33, 0xd4, 0xd1, 0x37, 0x4b,
+	0x56, 0x0f, 0x96, 0xf3, 0x0b, 0x3b, 0xeb, 0xfa, 0x29, 0x6a, 0x3f, 0xc9, 0xf2, 0xf5, 0x53, 0x57,
+	0x89, 0xc8, 0xd0, 0x4b, 0x3e, 0x5a, 0x34, 0xd8, 0x5d, 0xcb, 0xb9, 0xc1, 0x79, 0xcc, 0x5e, 0x3b,
+	0x11, 0x2f, 0x66, 0xb5, 0x07, 0x0b, 0x39, 0x75, 0x8f, 0xf5, 0xaa, 0x46, 0xa1, 0xb8, 0x6c, 0xaa,
+	0x5f, 0x3b, 0x09, 0x2d, 0xe6, 0xd3, 0x02, 0x2b, 0x5b, 0x80, 0x58, 0xc6, 0xab, 0x72, 0x51, 0xf1,
+	0x52, 0x7f, 0xf5, 0x04, 0xac, 0x98, 0xc9, 0x0f, 0xe3, 0xba, 0x37, 0xaf, 0x92, 0xb0, 0x6e, 0x64,
+	0xd5, 0x32, 0xa4, 0x30, 0xa9, 0xaf, 0x9d, 0x16, 0x3d, 0xe6, 0xff, 0x09, 0xcc, 0xa5, 0xdb, 0x77,
+	0x96, 0x7d, 0x72, 0xb7, 0xb1, 0x7e, 0x65, 0x28, 0x4e, 0x72, 0xa1, 0x8c, 0xcf, 0x04, 0x8d, 0x0b,
+	0x95, 0xf7, 0x69, 0xa2, 0x71, 0xa1, 0x72, 0xbf, 0x30, 0xb4, 0x1e, 0xc0, 0x94, 0xf6, 0x21, 0xa0,
+	0xb5, 0x9a, 0xfe, 0x34, 0xcf, 0xa4, 0xf7, 0x4a, 0xd1, 0x74, 0x8a, 0x1a, 0x39, 0x92, 0xd5, 0xa1,
+	0x1f, 0xfa, 0x65, 0xa9, 0xa5, 0x1c, 0x08, 0x2a, 0x33, 0xfd, 0x09, 0x9c, 0xa1, 0xcc, 0x82, 0x8f,
+	0xf6, 0x0c, 0x65, 0x16, 0x7d, 0x43, 0xc7, 0xfd, 0x5e, 0xaa, 0x15, 0x68, 0xf8, 0xbd, 0xfc, 0x3e,
+	0xab, 0xe1, 0xf7, 0x8a, 0xfa, 0x90, 0x48, 0x39, 0xd5, 0x67, 0x32, 0x28, 0xe7, 0x77, 0xc6, 0x0c,
+	0xca, 0x45, 0x6d, 0x2a, 0x17, 0xac, 0x6c, 0x0b, 0xc8, 0xb8, 0x42, 0x85, 0xdd, 0x26, 0xe3, 0x0a,
+	0x15, 0xf7, 0x91, 0xf8, 0x09, 0x6a, 0x5d, 0x12, 0xe3, 0x04, 0xb3, 0x4d, 0x1f, 0xe3, 0x04, 0xf3,
+	0x9a, 0x2b, 0x48, 0x4d, 0x6b, 0x6a, 0x18, 0xd4, 0xb2, 0x9d, 0x14, 0x83, 0x5a, 0x4e, 0x2f, 0x44,
+	0xc8, 0x96, 0x74, 0x28, 0x4c, 0xd9, 0x32, 0x3d, 0x12, 0x53, 0xb6, 0x9c, 0xc6, 0x06, 0xc6, 0x6b,
+	0xb3, 0xcf, 0x60, 0xc4, 0xeb, 0xdc, 0xae, 0x86, 0x11, 0xaf, 0x0b, 0x9a, 0x14, 0xcf, 0xa0, 0x6a,
+	0x56, 0xea, 0x06, 0xd9, 0xdc, 0x8a, 0xdf, 0x20, 0x9b, 0x5f, 0xe6, 0xdf, 0x2c, 0xad, 0xff, 0x7d,
+	0x54, 0xd5, 0x92, 0x0f, 0x7a, 0x6e, 0x9b, 0x05, 0x2a, 0x03, 0x7d, 0x04, 0xd3, 0x7a, 0xa9, 0x68,
+	0xe9, 0xfb, 0xce, 0xa9, 0x3d, 0xeb, 0x17, 0x0b, 0xe7, 0x69, 0x07, 0x48, 0x50, 0x2f, 0xc9, 0x0d,
+	0x82, 0x39, 0x6f, 0x0a, 0x06, 0xc1, 0xbc, 0x5a, 0xde, 0xda, 0x02, 0x48, 0x0a, 0x6d, 0xeb, 0x82,
+	0xde, 0x1e, 0x4b, 0x97, 0xf8, 0xf5, 0xd5, 0x82, 0xd9, 0xc4, 0x04, 0xb4, 0x32, 0xdb, 0x30, 0x81,
+	0x6c, 0xd5, 0x6e, 0x98, 0x40, 0x4e, 0x75, 0x2e, 0xcc, 0x33, 0xa9, 0x8f, 0x4d, 0xf3, 0xcc, 0x54,
+	0xd3, 0xa6, 0x79, 0xe6, 0x94, 0xd5, 0x98, 0xa9, 0x65, 0x6a, 0x52, 0x23, 0x53, 0x2b, 0x2a, 0xa8,
+	0x8d, 0x4c, 0xad, 0xb0, 0xac, 0xe5, 0x6a, 0x4c, 0xca, 0x4d, 0x43, 0x8d, 0x99, 0x6a, 0xd7, 0x50,
+	0x63, 0xb6, 0x46, 0x5d, 0x6f, 0xc1, 0x0c, 0xbf, 0x12, 0x89, 0x11, 0x39, 0x12, 0x10, 0xd7, 0x58,
+	0x46, 0x68, 0xc9, 0x2b, 0x40, 0x8d, 0xd0, 0x92, 0x5b, 0x9e, 0x35, 0xc7, 0xc5, 0x7f, 0x81, 0xbd,
+	0xfd, 0x3f, 0xeb, 0x9e, 0xfb, 0x69, 0x12, 0x36, 0x00, 0x00,
 }
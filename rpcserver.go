@@ -5,27 +5,54 @@
 package main
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/internal/metrics"
 	"github.com/btcsuite/btcwallet/rpc/legacyrpc"
+	"github.com/btcsuite/btcwallet/rpc/macaroons"
+	"github.com/btcsuite/btcwallet/rpc/remotesigner"
+	"github.com/btcsuite/btcwallet/rpc/restgateway"
+	"github.com/btcsuite/btcwallet/rpc/rpcpolicy"
 	"github.com/btcsuite/btcwallet/rpc/rpcserver"
+	pb "github.com/btcsuite/btcwallet/rpc/walletrpc"
 	"github.com/btcsuite/btcwallet/wallet"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
 
+const (
+	// autogenCertOrg is the organization of the certificates generated by
+	// the wallet, which are rotated before they expire.
+	autogenCertOrg = "btcwallet autogenerated cert"
+
+	// rpcCertRotateBefore is how long before expiring generated
+	// certificates are replaced.
+	rpcCertRotateBefore = 30 * 24 * time.Hour
+
+	// rpcCertCheckInterval is how often the RPC cert and key files are
+	// checked for modifications and the certificate for expiry.
+	rpcCertCheckInterval = time.Minute
+)
+
 // openRPCKeyPair creates or loads the RPC TLS keypair specified by the
 // application config.  This function respects the cfg.OneTimeTLSKey setting.
+// Loaded keypairs generated by the wallet are regenerated when they are about
+// to expire.
 func openRPCKeyPair() (tls.Certificate, error) {
 	// Check for existence of the TLS key file.  If one time TLS keys are
 	// enabled but a key already exists, this function should error since
@@ -47,8 +74,42 @@ func openRPCKeyPair() (tls.Certificate, error) {
 	case !keyExists:
 		return generateRPCKeyPair(true)
 	default:
-		return tls.LoadX509KeyPair(cfg.RPCCert.Value, cfg.RPCKey.Value)
+		keyPair, err := tls.LoadX509KeyPair(cfg.RPCCert.Value, cfg.RPCKey.Value)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+		notAfter, autogenerated, err := rpcCertExpiry(keyPair)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+		switch {
+		case autogenerated && time.Until(notAfter) < rpcCertRotateBefore:
+			log.Infof("TLS certificate expires %v, rotating", notAfter)
+			return generateRPCKeyPair(true)
+		case time.Now().After(notAfter):
+			log.Warnf("TLS certificate `%s` expired %v",
+				cfg.RPCCert.Value, notAfter)
+		}
+		return keyPair, nil
+	}
+}
+
+// rpcCertExpiry returns the expiry time of the certificate of keyPair, and
+// whether it was generated by the wallet.
+func rpcCertExpiry(keyPair tls.Certificate) (notAfter time.Time, autogenerated bool, err error) {
+	if len(keyPair.Certificate) == 0 {
+		return time.Time{}, false, errors.New("TLS keypair has no certificate")
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, org := range cert.Subject.Organization {
+		if org == autogenCertOrg {
+			autogenerated = true
+		}
 	}
+	return cert.NotAfter, autogenerated, nil
 }
 
 // generateRPCKeyPair generates a new RPC TLS keypair and writes the cert and
@@ -70,9 +131,8 @@ func generateRPCKeyPair(writeKey bool) (tls.Certificate, error) {
 	}
 
 	// Generate cert pair.
-	org := "btcwallet autogenerated cert"
 	validUntil := time.Now().Add(time.Hour * 24 * 365 * 10)
-	cert, key, err := btcutil.NewTLSCertPair(org, validUntil, nil)
+	cert, key, err := btcutil.NewTLSCertPair(autogenCertOrg, validUntil, nil)
 	if err != nil {
 		return tls.Certificate{}, err
 	}
@@ -102,27 +162,256 @@ func generateRPCKeyPair(writeKey bool) (tls.Certificate, error) {
 	return keyPair, nil
 }
 
-func startRPCServers(walletLoader *wallet.Loader) (*grpc.Server, *legacyrpc.Server, error) {
+// rpcCertReloader provides the RPC TLS keypair to the TLS handshakes of the RPC
+// servers.  The keypair is reloaded when the cert or key file is modified, and
+// generated keypairs are rotated before they expire, so certificates may be
+// replaced without restarting the wallet.
+type rpcCertReloader struct {
+	mu      sync.RWMutex
+	keyPair *tls.Certificate
+
+	// modTime is the latest modification time of the cert and key files
+	// when they were last loaded.  It is only accessed by run.
+	modTime time.Time
+
+	interval time.Duration
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+func newRPCCertReloader(keyPair tls.Certificate) *rpcCertReloader {
+	return &rpcCertReloader{
+		keyPair:  &keyPair,
+		modTime:  rpcKeyPairModTime(),
+		interval: rpcCertCheckInterval,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// rpcKeyPairModTime returns the latest modification time of the RPC cert and
+// key files.  Files which can not be read are ignored.
+func rpcKeyPairModTime() time.Time {
+	var modTime time.Time
+	for _, path := range []string{cfg.RPCCert.Value, cfg.RPCKey.Value} {
+		fi, err := os.Stat(path)
+		if err == nil && fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	return modTime
+}
+
+// GetCertificate returns the current keypair.  It is used as the
+// GetCertificate function of the TLS configuration of the RPC servers.
+func (r *rpcCertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	keyPair := r.keyPair
+	r.mu.RUnlock()
+	return keyPair, nil
+}
+
+// verifyPeerCertificate authenticates connections to the wallet's own RPC
+// server by requiring the server to present the current certificate.  It is
+// used as the VerifyPeerCertificate function of the TLS configuration of such
+// client connections.
+func (r *rpcCertReloader) verifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	keyPair, _ := r.GetCertificate(nil)
+	if len(rawCerts) == 0 || len(keyPair.Certificate) == 0 ||
+		!bytes.Equal(rawCerts[0], keyPair.Certificate[0]) {
+
+		return errors.New("RPC server presented an unexpected certificate")
+	}
+	return nil
+}
+
+// run periodically checks for modified cert and key files and expiring
+// certificates until stop is called.  It must be run as a goroutine.
+func (r *rpcCertReloader) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.check()
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// stop signals run to return and waits for it.  It must only be called once,
+// after run was started.
+func (r *rpcCertReloader) stop() {
+	close(r.quit)
+	<-r.done
+}
+
+func (r *rpcCertReloader) check() {
+	r.mu.RLock()
+	keyPair := *r.keyPair
+	r.mu.RUnlock()
+
+	notAfter, autogenerated, err := rpcCertExpiry(keyPair)
+	if err != nil {
+		log.Errorf("Cannot parse TLS certificate: %v", err)
+		return
+	}
+	modTime := rpcKeyPairModTime()
+	switch {
+	case autogenerated && time.Until(notAfter) < rpcCertRotateBefore:
+		log.Infof("TLS certificate expires %v, rotating", notAfter)
+		keyPair, err = generateRPCKeyPair(!cfg.OneTimeTLSKey)
+		if err == nil {
+			log.Warnf("Clients must be updated with the new TLS "+
+				"certificate `%s`", cfg.RPCCert.Value)
+		}
+
+	// One time keys are never written, so the keypair can not be
+	// reloaded from the files.
+	case !cfg.OneTimeTLSKey && !modTime.Equal(r.modTime):
+		log.Infof("Reloading modified TLS certificate `%s`",
+			cfg.RPCCert.Value)
+		keyPair, err = tls.LoadX509KeyPair(cfg.RPCCert.Value, cfg.RPCKey.Value)
+
+	default:
+		return
+	}
+	// The modification time is recorded even when the files can not be
+	// loaded, such as when only one of them was replaced yet, so that
+	// loading is only retried after they are modified again.
+	r.modTime = rpcKeyPairModTime()
+	if err != nil {
+		log.Errorf("Cannot load TLS keypair: %v", err)
+		return
+	}
+	r.mu.Lock()
+	r.keyPair = &keyPair
+	r.mu.Unlock()
+}
+
+// openMacaroonService opens the service verifying the macaroons of
+// experimental RPC clients, with the root key stored in the network data
+// directory, and writes macaroons of each permission to the directory if they
+// do not exist yet.
+func openMacaroonService() (*macaroons.Service, error) {
+	netDir := networkDir(cfg.AppDataDir.Value, activeNet.Params)
+	svc, err := macaroons.NewService(filepath.Join(netDir, "macaroons.key"))
+	if err != nil {
+		return nil, err
+	}
+	err = svc.WriteFiles(netDir)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Experimental RPC server requires macaroons, which are "+
+		"stored in %s", netDir)
+	return svc, nil
+}
+
+// rpcInterceptors returns the server options intercepting calls of the
+// experimental RPC server to authenticate macaroons and enforce the RPC policy,
+// in that order.
+func rpcInterceptors() ([]grpc.ServerOption, error) {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+	if len(cfg.MetricsListeners) != 0 {
+		unary = append(unary, metrics.UnaryServerInterceptor())
+	}
+	credential := func(context.Context) string { return "" }
+	if !cfg.NoExperimentalRPCMacaroons {
+		svc, err := openMacaroonService()
+		if err != nil {
+			return nil, err
+		}
+		unary = append(unary, svc.UnaryServerInterceptor())
+		stream = append(stream, svc.StreamServerInterceptor())
+		credential = func(ctx context.Context) string {
+			perm, _ := macaroons.PermissionFromContext(ctx)
+			return string(perm)
+		}
+	}
+	policy, err := rpcpolicy.Parse(cfg.ExperimentalRPCPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		unary = append(unary, policy.UnaryServerInterceptor(credential))
+		stream = append(stream, policy.StreamServerInterceptor(credential))
+	}
+
+	var opts []grpc.ServerOption
+	if len(unary) != 0 {
+		opts = append(opts,
+			grpc.UnaryInterceptor(chainUnaryInterceptors(unary)))
+	}
+	if len(stream) != 0 {
+		opts = append(opts,
+			grpc.StreamInterceptor(chainStreamInterceptors(stream)))
+	}
+	return opts, nil
+}
+
+// chainUnaryInterceptors returns a unary interceptor calling each of the
+// interceptors in order, since a server only accepts a single one.
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// chainStreamInterceptors returns a stream interceptor calling each of the
+// interceptors in order.
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+func startRPCServers(walletLoader *wallet.Loader,
+	multiLoader *wallet.MultiLoader) (*grpc.Server, *legacyrpc.Server, error) {
+
 	var (
 		server       *grpc.Server
 		legacyServer *legacyrpc.Server
 		legacyListen = net.Listen
-		keyPair      tls.Certificate
-		err          error
 	)
 	if cfg.DisableServerTLS {
 		log.Info("Server TLS is disabled.  Only legacy RPC may be used")
 	} else {
-		keyPair, err = openRPCKeyPair()
+		keyPair, err := openRPCKeyPair()
 		if err != nil {
 			return nil, nil, err
 		}
+		reloader := newRPCCertReloader(keyPair)
+		go reloader.run()
+		addInterruptHandler(reloader.stop)
 
 		// Change the standard net.Listen function to the tls one.
 		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{keyPair},
-			MinVersion:   tls.VersionTLS12,
-			NextProtos:   []string{"h2"}, // HTTP/2 over TLS
+			GetCertificate: reloader.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+			NextProtos:     []string{"h2"}, // HTTP/2 over TLS
 		}
 		legacyListen = func(net string, laddr string) (net.Listener, error) {
 			return tls.Listen(net, laddr, tlsConfig)
@@ -134,10 +423,19 @@ func startRPCServers(walletLoader *wallet.Loader) (*grpc.Server, *legacyrpc.Serv
 				err := errors.New("failed to create listeners for RPC server")
 				return nil, nil, err
 			}
-			creds := credentials.NewServerTLSFromCert(&keyPair)
-			server = grpc.NewServer(grpc.Creds(creds))
+			creds := credentials.NewTLS(tlsConfig)
+			opts := []grpc.ServerOption{grpc.Creds(creds)}
+			interceptors, err := rpcInterceptors()
+			if err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, interceptors...)
+			server = grpc.NewServer(opts...)
 			rpcserver.StartVersionService(server)
-			rpcserver.StartWalletLoaderService(server, walletLoader, activeNet)
+			rpcserver.StartWalletLoaderService(server, walletLoader,
+				multiLoader, activeNet, chainProxy(), debugLevels)
+			rpcserver.StartWalletService(server, walletLoader,
+				multiLoader)
 			for _, lis := range listeners {
 				lis := lis
 				go func() {
@@ -148,6 +446,13 @@ func startRPCServers(walletLoader *wallet.Loader) (*grpc.Server, *legacyrpc.Serv
 						err)
 				}()
 			}
+
+			if len(cfg.ExperimentalRESTListeners) != 0 {
+				err := startRESTGateway(reloader, tlsConfig)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
 		}
 	}
 
@@ -159,13 +464,30 @@ func startRPCServers(walletLoader *wallet.Loader) (*grpc.Server, *legacyrpc.Serv
 			err := errors.New("failed to create listeners for legacy RPC server")
 			return nil, nil, err
 		}
+		users, err := parseLegacyRPCUsers(cfg.LegacyRPCUsers)
+		if err != nil {
+			return nil, nil, err
+		}
+		policy, err := rpcpolicy.Parse(cfg.LegacyRPCPolicy)
+		if err != nil {
+			return nil, nil, err
+		}
 		opts := legacyrpc.Options{
 			Username:            cfg.Username,
 			Password:            cfg.Password,
+			Users:               users,
+			Policy:              policy,
+			DebugLevel:          debugLevels,
 			MaxPOSTClients:      cfg.LegacyRPCMaxClients,
 			MaxWebsocketClients: cfg.LegacyRPCMaxWebsockets,
 		}
+		if len(cfg.MetricsListeners) != 0 {
+			opts.CallObserver = metrics.ObserveLegacyRPCCall
+		}
 		legacyServer = legacyrpc.NewServer(&opts, walletLoader, listeners)
+		if multiLoader != nil {
+			legacyServer.SetMultiLoader(multiLoader)
+		}
 	}
 
 	// Error when neither the GRPC nor legacy RPC servers can be started.
@@ -176,6 +498,61 @@ func startRPCServers(walletLoader *wallet.Loader) (*grpc.Server, *legacyrpc.Serv
 	return server, legacyServer, nil
 }
 
+// startRESTGateway serves the REST gateway on the REST listeners, proxying
+// requests to the experimental RPC server.
+func startRESTGateway(reloader *rpcCertReloader, tlsConfig *tls.Config) error {
+	// The gateway connects to the wallet's own RPC server, which is
+	// authenticated by its current certificate rather than the host name,
+	// so that certificates may be rotated.
+	clientTLS := &tls.Config{
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: reloader.verifyPeerCertificate,
+	}
+	conn, err := grpc.Dial(localRPCAddr(cfg.ExperimentalRPCListeners[0]),
+		grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+	if err != nil {
+		return err
+	}
+
+	// The HTTP server is not configured for HTTP/2 on these listeners.
+	restTLS := tlsConfig.Clone()
+	restTLS.NextProtos = []string{"http/1.1"}
+	listeners := makeListeners(cfg.ExperimentalRESTListeners,
+		func(net string, laddr string) (net.Listener, error) {
+			return tls.Listen(net, laddr, restTLS)
+		})
+	if len(listeners) == 0 {
+		conn.Close()
+		return errors.New("failed to create listeners for REST gateway")
+	}
+	gateway := restgateway.New(pb.NewWalletServiceClient(conn))
+	for _, lis := range listeners {
+		lis := lis
+		go func() {
+			log.Infof("Experimental REST gateway listening on %s",
+				lis.Addr())
+			err := http.Serve(lis, gateway)
+			log.Tracef("Finished serving REST gateway: %v", err)
+		}()
+	}
+	return nil
+}
+
+// localRPCAddr returns the address connecting to the RPC server listening on
+// the normalized address addr, replacing unspecified hosts listening on all
+// interfaces with localhost.
+func localRPCAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	ip := net.ParseIP(host)
+	if host == "" || host == "*" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
 type listenFunc func(net string, laddr string) (net.Listener, error)
 
 // makeListeners splits the normalized listen addresses into IPv4 and IPv6
@@ -242,13 +619,36 @@ func makeListeners(normalizedListenAddrs []string, listen listenFunc) []net.List
 
 // startWalletRPCServices associates each of the (optionally-nil) RPC servers
 // with a wallet to enable remote wallet access.  For the GRPC server, this
-// registers the WalletService service, and for the legacy JSON-RPC server it
-// enables methods that require a loaded wallet.
-func startWalletRPCServices(wallet *wallet.Wallet, server *grpc.Server, legacyServer *legacyrpc.Server) {
-	if server != nil {
-		rpcserver.StartWalletService(server, wallet)
+// registers the SignerService when enabled, as the WalletService is registered
+// when the server is started, and for the legacy JSON-RPC server it enables
+// methods that require a loaded wallet.
+func startWalletRPCServices(w *wallet.Wallet, server *grpc.Server,
+	legacyServer *legacyrpc.Server) {
+
+	if server != nil && cfg.ExperimentalRPCSigner {
+		rpcserver.StartSignerService(server, wallet.NewLocalSigner(w))
 	}
 	if legacyServer != nil {
-		legacyServer.RegisterWallet(wallet)
+		legacyServer.RegisterWallet(w)
+	}
+}
+
+// dialRemoteSigner connects to the RPC server of the remote signer specified
+// by the application config.
+func dialRemoteSigner() (*remotesigner.Signer, error) {
+	creds, err := credentials.NewClientTLSFromFile(cfg.RemoteSignerCert, "")
+	if err != nil {
+		return nil, err
+	}
+	macaroon, err := macaroons.ReadCredential(cfg.RemoteSignerMacaroon)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(cfg.RemoteSigner,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(macaroon))
+	if err != nil {
+		return nil, err
 	}
+	return remotesigner.New(conn), nil
 }
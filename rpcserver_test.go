@@ -0,0 +1,93 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/btcsuite/btcwallet/internal/cfgutil"
+)
+
+// TestRPCCertReloader ensures that the RPC keypair is reloaded once its files
+// are modified, and that the reloader stops checking the files once stopped.
+func TestRPCCertReloader(t *testing.T) {
+	// Loggers can not be used without the log rotator.
+	log.SetLevel(btclog.LevelOff)
+
+	dir, err := ioutil.TempDir("", "btcwallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg = &config{
+		RPCCert: cfgutil.NewExplicitString(filepath.Join(dir, "rpc.cert")),
+		RPCKey:  cfgutil.NewExplicitString(filepath.Join(dir, "rpc.key")),
+	}
+
+	keyPair, err := generateRPCKeyPair(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := newRPCCertReloader(keyPair)
+	reloader.interval = time.Millisecond
+	go reloader.run()
+
+	// The modification time is set explicitly, as the files may be
+	// rewritten within the resolution of their modification times.
+	newKeyPair, err := generateRPCKeyPair(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Hour)
+	for _, path := range []string{cfg.RPCCert.Value, cfg.RPCKey.Value} {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, _ := reloader.GetCertificate(nil)
+		if bytes.Equal(current.Certificate[0], newKeyPair.Certificate[0]) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("modified keypair was not reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		reloader.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reloader did not stop")
+	}
+
+	// Modified files are no longer reloaded.
+	if _, err := generateRPCKeyPair(true); err != nil {
+		t.Fatal(err)
+	}
+	modTime = modTime.Add(time.Hour)
+	for _, path := range []string{cfg.RPCCert.Value, cfg.RPCKey.Value} {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * reloader.interval)
+	current, _ := reloader.GetCertificate(nil)
+	if !bytes.Equal(current.Certificate[0], newKeyPair.Certificate[0]) {
+		t.Fatal("keypair reloaded after the reloader was stopped")
+	}
+}
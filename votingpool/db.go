@@ -196,6 +196,128 @@ func putPool(ns walletdb.ReadWriteBucket, poolID []byte) error {
 	return nil
 }
 
+// reencryptPools replaces the values of all voting pools stored in ns which
+// are encrypted with the crypto public key of the address manager, the public
+// keys of the series and the script hashes of used addresses, with their
+// re-encryption by reencrypt.
+func reencryptPools(ns walletdb.ReadWriteBucket,
+	reencrypt func([]byte) ([]byte, error)) error {
+
+	var poolIDs [][]byte
+	err := ns.ForEach(func(k, v []byte) error {
+		if v == nil {
+			poolIDs = append(poolIDs, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return newError(ErrDatabase, "failed to read voting pools", err)
+	}
+	for _, poolID := range poolIDs {
+		poolBucket := ns.NestedReadWriteBucket(poolID)
+		err := reencryptSeries(poolBucket, reencrypt)
+		if err != nil {
+			return err
+		}
+		usedAddrs := poolBucket.NestedReadWriteBucket(usedAddrsBucketName)
+		if usedAddrs == nil {
+			continue
+		}
+		var bucketIDs [][]byte
+		err = usedAddrs.ForEach(func(k, v []byte) error {
+			if v == nil {
+				bucketIDs = append(bucketIDs, k)
+			}
+			return nil
+		})
+		if err != nil {
+			str := fmt.Sprintf("failed to read used addrs of pool %v",
+				poolID)
+			return newError(ErrDatabase, str, err)
+		}
+		for _, bucketID := range bucketIDs {
+			err := reencryptValues(
+				usedAddrs.NestedReadWriteBucket(bucketID), reencrypt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reencryptSeries replaces the encrypted public keys of all series of the pool
+// bucket with their re-encryption by reencrypt.  The private keys are
+// encrypted with the crypto private key and are kept.
+func reencryptSeries(poolBucket walletdb.ReadWriteBucket,
+	reencrypt func([]byte) ([]byte, error)) error {
+
+	bucket := poolBucket.NestedReadWriteBucket(seriesBucketName)
+	if bucket == nil {
+		return nil
+	}
+	rows := make(map[string]*dbSeriesRow)
+	err := bucket.ForEach(func(k, v []byte) error {
+		row, err := deserializeSeriesRow(v)
+		if err != nil {
+			return err
+		}
+		rows[string(k)] = row
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, row := range rows {
+		for i, pubKeyEncrypted := range row.pubKeysEncrypted {
+			reencrypted, err := reencrypt(pubKeyEncrypted)
+			if err != nil {
+				return newError(ErrCrypto, "failed to re-encrypt "+
+					"series public key", err)
+			}
+			row.pubKeysEncrypted[i] = reencrypted
+		}
+		serialized, err := serializeSeriesRow(row)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(k), serialized); err != nil {
+			return newError(ErrDatabase, "cannot put series", err)
+		}
+	}
+	return nil
+}
+
+// reencryptValues replaces every value of bucket with its re-encryption by
+// reencrypt.
+func reencryptValues(bucket walletdb.ReadWriteBucket,
+	reencrypt func([]byte) ([]byte, error)) error {
+
+	values := make(map[string][]byte)
+	err := bucket.ForEach(func(k, v []byte) error {
+		if v != nil {
+			values[string(k)] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return newError(ErrDatabase, "failed to read used addrs", err)
+	}
+	for k, v := range values {
+		reencrypted, err := reencrypt(v)
+		if err != nil {
+			return newError(ErrCrypto, "failed to re-encrypt used "+
+				"address hash", err)
+		}
+		if err := bucket.Put([]byte(k), reencrypted); err != nil {
+			return newError(ErrDatabase, "failed to store used "+
+				"address hash", err)
+		}
+	}
+	return nil
+}
+
 // loadAllSeries returns a map of all the series stored inside a voting pool
 // bucket, keyed by id.
 func loadAllSeries(ns walletdb.ReadBucket, poolID []byte) (map[uint32]*dbSeriesRow, error) {
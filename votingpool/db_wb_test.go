@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/walletdb"
 )
 
@@ -153,3 +154,76 @@ func TestPutAndGetWithdrawal(t *testing.T) {
 		t.Fatalf("Wrong value retrieved from DB; got %x, want %x", retrieved, serialized)
 	}
 }
+
+func TestReencryptPublicData(t *testing.T) {
+	tearDown, db, pool := TstCreatePool(t)
+	defer tearDown()
+
+	def := TstCreateSeriesDef(t, pool, 2, createMasterKeys(t, 3))
+	hash := bytes.Repeat([]byte{0x09}, 20)
+	err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		TstCreateSeries(t, tx, pool, []TstSeriesDef{def})
+		ns, _ := TstRWNamespaces(tx)
+		encryptedHash, err := pool.Manager().Encrypt(waddrmgr.CKTPublic, hash)
+		if err != nil {
+			return err
+		}
+		return putUsedAddrHash(ns, pool.ID, def.SeriesID, 0, 0, encryptedHash)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns, addrmgrNs := TstRWNamespaces(tx)
+		return pool.Manager().RotatePublicPassphrase(addrmgrNs,
+			pubPassphrase, []byte("new public passphrase"), nil,
+			func(reencrypt waddrmgr.ReencryptFunc) error {
+				return ReencryptPublicData(ns, reencrypt)
+			})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pool loads with the rotated crypto key and has the same series,
+	// and the used address hash decrypts with it.  The series has private
+	// keys, which are only loaded by an unlocked manager.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns, addrmgrNs := TstRNamespaces(tx)
+		var loaded *Pool
+		var err error
+		TstRunWithManagerUnlocked(t, pool.Manager(), addrmgrNs, func() {
+			loaded, err = Load(ns, pool.Manager(), pool.ID)
+		})
+		if err != nil {
+			return err
+		}
+		want := pool.Series(def.SeriesID).publicKeys
+		got := loaded.Series(def.SeriesID).publicKeys
+		if len(got) != len(want) {
+			t.Fatalf("Wrong number of public keys; got %d, want %d",
+				len(got), len(want))
+		}
+		for i := range want {
+			if got[i].String() != want[i].String() {
+				t.Fatalf("Wrong public key %d; got %v, want %v", i,
+					got[i], want[i])
+			}
+		}
+
+		storedHash, err := pool.Manager().Decrypt(waddrmgr.CKTPublic,
+			getUsedAddrHash(ns, pool.ID, def.SeriesID, 0, 0))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(storedHash, hash) {
+			t.Fatalf("Wrong stored hash; got %x, want %x", storedHash,
+				hash)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -174,6 +174,20 @@ func LoadAndEmpowerSeries(ns walletdb.ReadWriteBucket, m *waddrmgr.Manager,
 	return pool.EmpowerSeries(ns, seriesID, rawPrivKey)
 }
 
+// ReencryptPublicData re-encrypts the data of all voting pools stored in ns
+// which is encrypted with the crypto public key of the address manager.  Since
+// waddrmgr.Manager.RotatePublicPassphrase replaces that key, it must be called
+// with the waddrmgr.ReencryptFunc passed to the reencrypt callback of the
+// rotation when voting pools are stored, or the pools can no longer be
+// loaded.
+//
+// Pools loaded before the rotation remain usable.
+func ReencryptPublicData(ns walletdb.ReadWriteBucket,
+	reencrypt waddrmgr.ReencryptFunc) error {
+
+	return reencryptPools(ns, reencrypt)
+}
+
 // Series returns the series with the given ID, or nil if it doesn't
 // exist.
 func (p *Pool) Series(seriesID uint32) *SeriesData {
@@ -285,6 +285,17 @@ func (a *managedAddress) PrivKey() (*btcec.PrivateKey, error) {
 	a.manager.mtx.Lock()
 	defer a.manager.mtx.Unlock()
 
+	// Neither are they for addresses of watching-only accounts or imported
+	// public keys.
+	if !a.imported {
+		acctInfo, ok := a.manager.acctInfo[a.derivationPath.Account]
+		if ok && len(acctInfo.acctKeyEncrypted) == 0 {
+			return nil, managerError(ErrWatchingOnly, errWatchingOnly, nil)
+		}
+	} else if len(a.privKeyEncrypted) == 0 {
+		return nil, managerError(ErrWatchingOnly, errWatchingOnly, nil)
+	}
+
 	// Account manager must be unlocked to decrypt the private key.
 	if a.manager.rootManager.IsLocked() {
 		return nil, managerError(ErrLocked, errLocked, nil)
@@ -151,6 +151,7 @@ var (
 	// scopeBucket -> scope -> acctBucket
 	// scopeBucket -> scope -> addrBucket
 	// scopeBucket -> scope -> usedAddrBucket
+	// scopeBucket -> scope -> addrLabelBucket
 	// scopeBucket -> scope -> addrAcctIdxBucket
 	// scopeBucket -> scope -> acctNameIdxBucket
 	// scopeBucket -> scope -> acctIDIdxBucketName
@@ -214,6 +215,12 @@ var (
 	// addresses hash if the address has been used or not.
 	usedAddrBucketName = []byte("usedaddrs")
 
+	// addrLabelBucketName is the name of the bucket that maps an address
+	// hash to the label of the address.  The bucket is created when the
+	// first label of a scope is set, so databases created before labels
+	// were added don't need to be upgraded.
+	addrLabelBucketName = []byte("addrlabels")
+
 	// meta is used to store meta-data about the address manager
 	// e.g. last account number
 	metaBucketName = []byte("meta")
@@ -255,9 +262,10 @@ var (
 	watchingOnlyName    = []byte("watchonly")
 
 	// Sync related key names (sync bucket).
-	syncedToName   = []byte("syncedto")
-	startBlockName = []byte("startblock")
-	birthdayName   = []byte("birthday")
+	syncedToName      = []byte("syncedto")
+	startBlockName    = []byte("startblock")
+	birthdayName      = []byte("birthday")
+	birthdayBlockName = []byte("birthdayblock")
 )
 
 // uint32ToBytes converts a 32 bit unsigned integer into a 4-byte slice in
@@ -1397,6 +1405,86 @@ func markAddressUsed(ns walletdb.ReadWriteBucket, scope *KeyScope,
 	return nil
 }
 
+// fetchAddressLabel returns the label of the provided address id, or an empty
+// string if the address has no label.
+func fetchAddressLabel(ns walletdb.ReadBucket, scope *KeyScope,
+	addressID []byte) (string, error) {
+
+	scopedBucket, err := fetchReadScopeBucket(ns, scope)
+	if err != nil {
+		return "", err
+	}
+
+	bucket := scopedBucket.NestedReadBucket(addrLabelBucketName)
+	if bucket == nil {
+		return "", nil
+	}
+
+	addrHash := sha256.Sum256(addressID)
+	return string(bucket.Get(addrHash[:])), nil
+}
+
+// putAddressLabel stores the label of the provided address id to the
+// database.  An empty label removes the label of the address.
+func putAddressLabel(ns walletdb.ReadWriteBucket, scope *KeyScope,
+	addressID []byte, label string) error {
+
+	scopedBucket, err := fetchWriteScopeBucket(ns, scope)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := scopedBucket.CreateBucketIfNotExists(addrLabelBucketName)
+	if err != nil {
+		str := "failed to create address label bucket"
+		return managerError(ErrDatabase, str, err)
+	}
+
+	addrHash := sha256.Sum256(addressID)
+	if label == "" {
+		err = bucket.Delete(addrHash[:])
+	} else {
+		err = bucket.Put(addrHash[:], []byte(label))
+	}
+	if err != nil {
+		str := fmt.Sprintf("failed to store label of address %x",
+			addressID)
+		return managerError(ErrDatabase, str, err)
+	}
+
+	return nil
+}
+
+// forEachAddressLabel calls the given function with the address row and label
+// of each labeled address of the scope stored in the manager, breaking early
+// on error.
+func forEachAddressLabel(ns walletdb.ReadBucket, scope *KeyScope,
+	fn func(rowInterface interface{}, label string) error) error {
+
+	scopedBucket, err := fetchReadScopeBucket(ns, scope)
+	if err != nil {
+		return err
+	}
+
+	bucket := scopedBucket.NestedReadBucket(addrLabelBucketName)
+	if bucket == nil {
+		return nil
+	}
+
+	err = bucket.ForEach(func(k, v []byte) error {
+		addrRow, err := fetchAddressByHash(ns, scope, k)
+		if err != nil {
+			return err
+		}
+
+		return fn(addrRow, string(v))
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+	return nil
+}
+
 // fetchAddress loads address information for the provided address id from the
 // database.  The returned value is one of the address rows for the specific
 // address type.  The caller should use type assertions to ascertain the type.
@@ -1802,6 +1890,165 @@ func deletePrivateKeys(ns walletdb.ReadWriteBucket) error {
 	return nil
 }
 
+// reencryptPublicData replaces all values encrypted with the crypto public key
+// with their re-encryption by reencrypt.  These are the master HD public key,
+// and for each scope the cointype and account public keys, the public keys of
+// imported addresses and the script hashes of script addresses.
+func reencryptPublicData(ns walletdb.ReadWriteBucket,
+	reencrypt func([]byte) ([]byte, error)) error {
+
+	// Watching-only managers created from account keys do not store the
+	// master HD public key.
+	bucket := ns.NestedReadWriteBucket(mainBucketName)
+	if masterHDPubEnc := bucket.Get(masterHDPubName); masterHDPubEnc != nil {
+		masterHDPubEnc, err := reencrypt(masterHDPubEnc)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(masterHDPubName, masterHDPubEnc); err != nil {
+			str := "failed to store encrypted master HD public key"
+			return managerError(ErrDatabase, str, err)
+		}
+	}
+
+	scopeBucket := ns.NestedReadWriteBucket(scopeBucketName)
+	return scopeBucket.ForEach(func(scopeKey, _ []byte) error {
+		if len(scopeKey) != 8 {
+			return nil
+		}
+
+		managerScopeBucket := scopeBucket.NestedReadWriteBucket(scopeKey)
+
+		coinTypePubKeyEnc := managerScopeBucket.Get(coinTypePubKeyName)
+		if coinTypePubKeyEnc != nil {
+			coinTypePubKeyEnc, err := reencrypt(coinTypePubKeyEnc)
+			if err != nil {
+				return err
+			}
+			err = managerScopeBucket.Put(
+				coinTypePubKeyName, coinTypePubKeyEnc,
+			)
+			if err != nil {
+				str := "failed to store encrypted cointype public key"
+				return managerError(ErrDatabase, str, err)
+			}
+		}
+
+		// Re-encrypt the account extended public key of all accounts.
+		// Buckets may not be modified while iterating over them, so the
+		// rewritten rows are stored after each iteration.
+		var rows [][2][]byte
+		bucket := managerScopeBucket.NestedReadWriteBucket(acctBucketName)
+		err := bucket.ForEach(func(k, v []byte) error {
+			// Skip buckets.
+			if v == nil {
+				return nil
+			}
+
+			row, err := deserializeAccountRow(k, v)
+			if err != nil {
+				return err
+			}
+
+			switch row.acctType {
+			case accountDefault:
+				arow, err := deserializeDefaultAccountRow(k, row)
+				if err != nil {
+					return err
+				}
+
+				// The imported account has no keys.
+				if len(arow.pubKeyEncrypted) == 0 {
+					return nil
+				}
+				pubKeyEncrypted, err := reencrypt(arow.pubKeyEncrypted)
+				if err != nil {
+					return err
+				}
+				row.rawData = serializeDefaultAccountRow(
+					pubKeyEncrypted, arow.privKeyEncrypted,
+					arow.nextExternalIndex, arow.nextInternalIndex,
+					arow.name,
+				)
+				rows = append(rows, [2][]byte{
+					append([]byte(nil), k...), serializeAccountRow(row),
+				})
+			}
+
+			return nil
+		})
+		if err != nil {
+			return maybeConvertDbError(err)
+		}
+		for _, row := range rows {
+			if err := bucket.Put(row[0], row[1]); err != nil {
+				str := "failed to store account public key"
+				return managerError(ErrDatabase, str, err)
+			}
+		}
+
+		// Re-encrypt the public key of all imported addresses and the
+		// hash of all script addresses.
+		rows = rows[:0]
+		bucket = managerScopeBucket.NestedReadWriteBucket(addrBucketName)
+		err = bucket.ForEach(func(k, v []byte) error {
+			// Skip buckets.
+			if v == nil {
+				return nil
+			}
+
+			row, err := deserializeAddressRow(v)
+			if err != nil {
+				return err
+			}
+
+			switch row.addrType {
+			case adtImport:
+				irow, err := deserializeImportedAddress(row)
+				if err != nil {
+					return err
+				}
+				encryptedPubKey, err := reencrypt(irow.encryptedPubKey)
+				if err != nil {
+					return err
+				}
+				row.rawData = serializeImportedAddress(
+					encryptedPubKey, irow.encryptedPrivKey)
+
+			case adtScript:
+				srow, err := deserializeScriptAddress(row)
+				if err != nil {
+					return err
+				}
+				encryptedHash, err := reencrypt(srow.encryptedHash)
+				if err != nil {
+					return err
+				}
+				row.rawData = serializeScriptAddress(encryptedHash,
+					srow.encryptedScript)
+
+			default:
+				return nil
+			}
+
+			rows = append(rows, [2][]byte{
+				append([]byte(nil), k...), serializeAddressRow(row),
+			})
+			return nil
+		})
+		if err != nil {
+			return maybeConvertDbError(err)
+		}
+		for _, row := range rows {
+			if err := bucket.Put(row[0], row[1]); err != nil {
+				str := "failed to store imported address"
+				return managerError(ErrDatabase, str, err)
+			}
+		}
+		return nil
+	})
+}
+
 // fetchSyncedTo loads the block stamp the manager is synced to from the
 // database.
 func fetchSyncedTo(ns walletdb.ReadBucket) (*BlockStamp, error) {
@@ -1832,7 +2079,6 @@ func fetchSyncedTo(ns walletdb.ReadBucket) (*BlockStamp, error) {
 
 // putSyncedTo stores the provided synced to blockstamp to the database.
 func putSyncedTo(ns walletdb.ReadWriteBucket, bs *BlockStamp) error {
-	bucket := ns.NestedReadWriteBucket(syncBucketName)
 	errStr := fmt.Sprintf("failed to store sync information %v", bs.Hash)
 
 	// If the block height is greater than zero, check that the previous
@@ -1844,6 +2090,14 @@ func putSyncedTo(ns walletdb.ReadWriteBucket, bs *BlockStamp) error {
 			return managerError(ErrDatabase, errStr, err)
 		}
 	}
+	return putSyncedToBlock(ns, bs)
+}
+
+// putSyncedToBlock stores the block hash of bs by its height and marks bs as
+// the synced to block, without checking that the previous block is stored.
+func putSyncedToBlock(ns walletdb.ReadWriteBucket, bs *BlockStamp) error {
+	bucket := ns.NestedReadWriteBucket(syncBucketName)
+	errStr := fmt.Sprintf("failed to store sync information %v", bs.Hash)
 
 	// Store the block hash by block height.
 	height := make([]byte, 4)
@@ -1961,6 +2215,62 @@ func putBirthday(ns walletdb.ReadWriteBucket, t time.Time) error {
 	return nil
 }
 
+// fetchBirthdayBlock loads the block stamp of the manager's birthday from the
+// database.  ErrBirthdayBlockNotSet is returned if it has not been stored.
+func fetchBirthdayBlock(ns walletdb.ReadBucket) (BlockStamp, error) {
+	bucket := ns.NestedReadBucket(syncBucketName)
+
+	var bs BlockStamp
+
+	// The serialized birthday block format is:
+	//   <blockheight><blockhash><timestamp>
+	//
+	// 4 bytes block height + 32 bytes hash length + 8 bytes timestamp
+	buf := bucket.Get(birthdayBlockName)
+	if buf == nil {
+		str := "birthday block not set"
+		return bs, managerError(ErrBirthdayBlockNotSet, str, nil)
+	}
+	if len(buf) != 44 {
+		str := "malformed birthday block stored in database"
+		return bs, managerError(ErrDatabase, str, nil)
+	}
+
+	bs.Height = int32(binary.BigEndian.Uint32(buf[0:4]))
+	copy(bs.Hash[:], buf[4:36])
+	bs.Timestamp = time.Unix(int64(binary.BigEndian.Uint64(buf[36:])), 0)
+	return bs, nil
+}
+
+// putBirthdayBlock stores the provided birthday block stamp to the database.
+func putBirthdayBlock(ns walletdb.ReadWriteBucket, bs BlockStamp) error {
+	bucket := ns.NestedReadWriteBucket(syncBucketName)
+
+	buf := make([]byte, 44)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(bs.Height))
+	copy(buf[4:36], bs.Hash[:])
+	binary.BigEndian.PutUint64(buf[36:], uint64(bs.Timestamp.Unix()))
+
+	err := bucket.Put(birthdayBlockName, buf)
+	if err != nil {
+		str := fmt.Sprintf("failed to store birthday block %v", bs.Hash)
+		return managerError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deleteBirthdayBlock removes the birthday block stamp from the database.
+func deleteBirthdayBlock(ns walletdb.ReadWriteBucket) error {
+	bucket := ns.NestedReadWriteBucket(syncBucketName)
+
+	err := bucket.Delete(birthdayBlockName)
+	if err != nil {
+		str := "failed to delete birthday block"
+		return managerError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
 // managerExists returns whether or not the manager has already been created
 // in the given database namespace.
 func managerExists(ns walletdb.ReadBucket) bool {
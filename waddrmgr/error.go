@@ -131,32 +131,42 @@ const (
 	// ErrScopeNotFound is returned when a target scope cannot be found
 	// within the database.
 	ErrScopeNotFound
+
+	// ErrInvalidLabel indicates that an address label was refused due to
+	// being too long or not valid UTF-8.
+	ErrInvalidLabel
+
+	// ErrBirthdayBlockNotSet indicates that the block of the manager's
+	// birthday has not been found and stored yet.
+	ErrBirthdayBlockNotSet
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
 var errorCodeStrings = map[ErrorCode]string{
-	ErrDatabase:          "ErrDatabase",
-	ErrUpgrade:           "ErrUpgrade",
-	ErrKeyChain:          "ErrKeyChain",
-	ErrCrypto:            "ErrCrypto",
-	ErrInvalidKeyType:    "ErrInvalidKeyType",
-	ErrNoExist:           "ErrNoExist",
-	ErrAlreadyExists:     "ErrAlreadyExists",
-	ErrCoinTypeTooHigh:   "ErrCoinTypeTooHigh",
-	ErrAccountNumTooHigh: "ErrAccountNumTooHigh",
-	ErrLocked:            "ErrLocked",
-	ErrWatchingOnly:      "ErrWatchingOnly",
-	ErrInvalidAccount:    "ErrInvalidAccount",
-	ErrAddressNotFound:   "ErrAddressNotFound",
-	ErrAccountNotFound:   "ErrAccountNotFound",
-	ErrDuplicateAddress:  "ErrDuplicateAddress",
-	ErrDuplicateAccount:  "ErrDuplicateAccount",
-	ErrTooManyAddresses:  "ErrTooManyAddresses",
-	ErrWrongPassphrase:   "ErrWrongPassphrase",
-	ErrWrongNet:          "ErrWrongNet",
-	ErrCallBackBreak:     "ErrCallBackBreak",
-	ErrEmptyPassphrase:   "ErrEmptyPassphrase",
-	ErrScopeNotFound:     "ErrScopeNotFound",
+	ErrDatabase:            "ErrDatabase",
+	ErrUpgrade:             "ErrUpgrade",
+	ErrKeyChain:            "ErrKeyChain",
+	ErrCrypto:              "ErrCrypto",
+	ErrInvalidKeyType:      "ErrInvalidKeyType",
+	ErrNoExist:             "ErrNoExist",
+	ErrAlreadyExists:       "ErrAlreadyExists",
+	ErrCoinTypeTooHigh:     "ErrCoinTypeTooHigh",
+	ErrAccountNumTooHigh:   "ErrAccountNumTooHigh",
+	ErrLocked:              "ErrLocked",
+	ErrWatchingOnly:        "ErrWatchingOnly",
+	ErrInvalidAccount:      "ErrInvalidAccount",
+	ErrAddressNotFound:     "ErrAddressNotFound",
+	ErrAccountNotFound:     "ErrAccountNotFound",
+	ErrDuplicateAddress:    "ErrDuplicateAddress",
+	ErrDuplicateAccount:    "ErrDuplicateAccount",
+	ErrTooManyAddresses:    "ErrTooManyAddresses",
+	ErrWrongPassphrase:     "ErrWrongPassphrase",
+	ErrWrongNet:            "ErrWrongNet",
+	ErrCallBackBreak:       "ErrCallBackBreak",
+	ErrEmptyPassphrase:     "ErrEmptyPassphrase",
+	ErrScopeNotFound:       "ErrScopeNotFound",
+	ErrInvalidLabel:        "ErrInvalidLabel",
+	ErrBirthdayBlockNotSet: "ErrBirthdayBlockNotSet",
 }
 
 // String returns the ErrorCode as a human-readable name.
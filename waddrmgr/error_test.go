@@ -39,6 +39,9 @@ func TestErrorCodeStringer(t *testing.T) {
 		{waddrmgr.ErrWrongNet, "ErrWrongNet"},
 		{waddrmgr.ErrCallBackBreak, "ErrCallBackBreak"},
 		{waddrmgr.ErrEmptyPassphrase, "ErrEmptyPassphrase"},
+		{waddrmgr.ErrScopeNotFound, "ErrScopeNotFound"},
+		{waddrmgr.ErrInvalidLabel, "ErrInvalidLabel"},
+		{waddrmgr.ErrBirthdayBlockNotSet, "ErrBirthdayBlockNotSet"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 	t.Logf("Running %d tests", len(tests))
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
@@ -44,6 +45,10 @@ const (
 	// DefaultAccountNum is the number of the default account.
 	DefaultAccountNum = 0
 
+	// MaxAddressLabelLen is the maximum length in bytes of an address
+	// label.
+	MaxAddressLabelLen = 255
+
 	// defaultAccountName is the initial name of the default account.  Note
 	// that the default account may be renamed and is not a reserved name,
 	// so the default account might not be named "default" and non-default
@@ -428,48 +433,17 @@ func (m *Manager) NewScopedKeyManager(ns walletdb.ReadWriteBucket, scope KeyScop
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	// If the manager is locked, then we can't create a new scoped manager.
-	if m.locked {
-		return nil, managerError(ErrLocked, errLocked, nil)
-	}
-
-	// Now that we know the manager is unlocked, we'll need to fetch the
-	// root master HD private key. This is required as we'll be attempting
+	// We'll need the root master HD private key, as we'll be attempting
 	// the following derivation: m/purpose'/cointype'
 	//
 	// Note that the path to the coin type is requires hardened derivation,
 	// therefore this can only be done if the wallet's root key hasn't been
 	// neutered.
-	masterRootPrivEnc, _, err := fetchMasterHDKeys(ns)
+	rootPriv, err := m.rootPrivKey(ns)
 	if err != nil {
 		return nil, err
 	}
-
-	// If the master root private key isn't found within the database, but
-	// we need to bail here as we can't create the cointype key without the
-	// master root private key.
-	if masterRootPrivEnc == nil {
-		return nil, managerError(ErrWatchingOnly, "", nil)
-	}
-
-	// Before we can derive any new scoped managers using this key, we'll
-	// need to fully decrypt it.
-	serializedMasterRootPriv, err := m.cryptoKeyPriv.Decrypt(masterRootPrivEnc)
-	if err != nil {
-		str := fmt.Sprintf("failed to decrypt master root serialized private key")
-		return nil, managerError(ErrLocked, str, err)
-	}
-
-	// Now that we know the root priv is within the database, we'll decode
-	// it into a usable object.
-	rootPriv, err := hdkeychain.NewKeyFromString(
-		string(serializedMasterRootPriv),
-	)
-	zero.Bytes(serializedMasterRootPriv)
-	if err != nil {
-		str := fmt.Sprintf("failed to create master extended private key")
-		return nil, managerError(ErrKeyChain, str, err)
-	}
+	defer rootPriv.Zero()
 
 	// Now that we have the root private key, we'll fetch the scope bucket
 	// so we can create the proper internal name spaces.
@@ -524,6 +498,77 @@ func (m *Manager) NewScopedKeyManager(ns walletdb.ReadWriteBucket, scope KeyScop
 	return m.scopedManagers[scope], nil
 }
 
+// rootPrivKey decrypts and returns the master HD root private key.  The
+// caller should zero the key once done with it.  An error with the code
+// ErrLocked is returned when the manager is locked, and ErrWatchingOnly when
+// the root key has been neutered.
+//
+// This function MUST be called with the manager lock held.
+func (m *Manager) rootPrivKey(ns walletdb.ReadBucket) (*hdkeychain.ExtendedKey, error) {
+	// If the manager is locked, then we can't decrypt the root key.
+	if m.locked {
+		return nil, managerError(ErrLocked, errLocked, nil)
+	}
+
+	masterRootPrivEnc, _, err := fetchMasterHDKeys(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the master root private key isn't found within the database, we
+	// need to bail here as no hardened keys can be derived without it.
+	if masterRootPrivEnc == nil {
+		return nil, managerError(ErrWatchingOnly, "", nil)
+	}
+
+	serializedMasterRootPriv, err := m.cryptoKeyPriv.Decrypt(masterRootPrivEnc)
+	if err != nil {
+		str := fmt.Sprintf("failed to decrypt master root serialized private key")
+		return nil, managerError(ErrLocked, str, err)
+	}
+
+	// Now that we know the root priv is within the database, we'll decode
+	// it into a usable object.
+	rootPriv, err := hdkeychain.NewKeyFromString(
+		string(serializedMasterRootPriv),
+	)
+	zero.Bytes(serializedMasterRootPriv)
+	if err != nil {
+		str := fmt.Sprintf("failed to create master extended private key")
+		return nil, managerError(ErrKeyChain, str, err)
+	}
+	return rootPriv, nil
+}
+
+// DeriveFromRoot derives the extended private key at the path of child
+// indexes from the master HD root key, such as the hardened path
+// m/48'/0'/0'/2' of a BIP0048 account.  Hardened indexes include the
+// hdkeychain.HardenedKeyStart offset.  This allows keys outside of the
+// BIP0044-like hierarchy of the key scopes to be derived.  The manager must be
+// unlocked and the root key must not have been neutered.  The caller should
+// zero the returned key once done with it.
+func (m *Manager) DeriveFromRoot(ns walletdb.ReadBucket,
+	path ...uint32) (*hdkeychain.ExtendedKey, error) {
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	key, err := m.rootPrivKey(ns)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		child, err := key.Child(index)
+		key.Zero()
+		if err != nil {
+			str := fmt.Sprintf("failed to derive child %d", index)
+			return nil, managerError(ErrKeyChain, str, err)
+		}
+		key = child
+	}
+	return key, nil
+}
+
 // FetchScopedKeyManager attempts to fetch an active scoped manager according to
 // its registered scope. If the manger is found, then a nil error is returned
 // along with the active scoped manager. Otherwise, a nil manager and a non-nil
@@ -655,6 +700,47 @@ func (m *Manager) MarkUsed(ns walletdb.ReadWriteBucket, address btcutil.Address)
 	return managerError(ErrAddressNotFound, str, nil)
 }
 
+// SetAddressLabel sets the label of the provided address, which may belong to
+// any of the scoped managers.  An empty label removes the label of the
+// address.
+func (m *Manager) SetAddressLabel(ns walletdb.ReadWriteBucket,
+	address btcutil.Address, label string) error {
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for _, scopedMgr := range m.scopedManagers {
+		if _, err := scopedMgr.Address(ns, address); err != nil {
+			continue
+		}
+
+		return scopedMgr.SetAddressLabel(ns, address, label)
+	}
+
+	str := fmt.Sprintf("unable to find key for addr %v", address)
+	return managerError(ErrAddressNotFound, str, nil)
+}
+
+// AddressLabel returns the label of the provided address, which may belong to
+// any of the scoped managers, or an empty string if the address has no label.
+func (m *Manager) AddressLabel(ns walletdb.ReadBucket,
+	address btcutil.Address) (string, error) {
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for _, scopedMgr := range m.scopedManagers {
+		if _, err := scopedMgr.Address(ns, address); err != nil {
+			continue
+		}
+
+		return scopedMgr.AddressLabel(ns, address)
+	}
+
+	str := fmt.Sprintf("unable to find key for addr %v", address)
+	return "", managerError(ErrAddressNotFound, str, nil)
+}
+
 // AddrAccount returns the account to which the given address belongs. We also
 // return the scoped manager that owns the addr+account combo.
 func (m *Manager) AddrAccount(ns walletdb.ReadBucket,
@@ -753,7 +839,8 @@ func (m *Manager) ChainParams() *chaincfg.Params {
 // private password, the address manager must not be watching-only.  The new
 // passphrase keys are derived using the scrypt parameters in the options, so
 // changing the passphrase may be used to bump the computational difficulty
-// needed to brute force the passphrase.
+// needed to brute force the passphrase.  If the options are nil, the scrypt
+// parameters of the current passphrase are kept.
 func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 	newPassphrase []byte, private bool, config *ScryptOptions) error {
 
@@ -765,12 +852,27 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	// Ensure the provided old passphrase is correct.  This check is done
-	// using a copy of the appropriate master key depending on the private
-	// flag to ensure the current state is not altered.  The temp key is
-	// cleared when done to avoid leaving a copy in memory.
+	apply, err := m.changePassphrase(
+		ns, oldPassphrase, newPassphrase, private, config,
+	)
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// deriveMasterKey returns a copy of the public or private master key derived
+// from passphrase, or ErrWrongPassphrase if the passphrase is incorrect.  The
+// copy ensures the current state is not altered, and must be zeroed by the
+// caller when done to avoid leaving it in memory.
+//
+// This function MUST be called with the manager lock held.
+func (m *Manager) deriveMasterKey(passphrase []byte,
+	private bool) (*snacl.SecretKey, error) {
+
 	var keyName string
-	secretKey := snacl.SecretKey{Key: &snacl.CryptoKey{}}
+	secretKey := &snacl.SecretKey{Key: &snacl.CryptoKey{}}
 	if private {
 		keyName = "private"
 		secretKey.Parameters = m.masterKeyPriv.Parameters
@@ -778,24 +880,50 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 		keyName = "public"
 		secretKey.Parameters = m.masterKeyPub.Parameters
 	}
-	if err := secretKey.DeriveKey(&oldPassphrase); err != nil {
+	if err := secretKey.DeriveKey(&passphrase); err != nil {
 		if err == snacl.ErrInvalidPassword {
 			str := fmt.Sprintf("invalid passphrase for %s master "+
 				"key", keyName)
-			return managerError(ErrWrongPassphrase, str, nil)
+			return nil, managerError(ErrWrongPassphrase, str, nil)
 		}
 
 		str := fmt.Sprintf("failed to derive %s master key", keyName)
-		return managerError(ErrCrypto, str, err)
+		return nil, managerError(ErrCrypto, str, err)
+	}
+	return secretKey, nil
+}
+
+// changePassphrase stores the public or private master key derived from
+// newPassphrase, and the crypto keys encrypted with it, in the database.  The
+// returned function updates the manager to the new keys, and must only be
+// called once the database has been updated successfully, so that the manager
+// is left untouched on error.
+//
+// This function MUST be called with the manager lock held.
+func (m *Manager) changePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
+	newPassphrase []byte, private bool, config *ScryptOptions) (func(), error) {
+
+	// Ensure the provided old passphrase is correct.
+	secretKey, err := m.deriveMasterKey(oldPassphrase, private)
+	if err != nil {
+		return nil, err
 	}
 	defer secretKey.Zero()
 
+	if config == nil {
+		config = &ScryptOptions{
+			N: secretKey.Parameters.N,
+			R: secretKey.Parameters.R,
+			P: secretKey.Parameters.P,
+		}
+	}
+
 	// Generate a new master key from the passphrase which is used to secure
 	// the actual secret keys.
 	newMasterKey, err := newSecretKey(&newPassphrase, config)
 	if err != nil {
 		str := "failed to create new master private key"
-		return managerError(ErrCrypto, str, err)
+		return nil, managerError(ErrCrypto, str, err)
 	}
 	newKeyParams := newMasterKey.Marshal()
 
@@ -813,7 +941,7 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 		_, err := rand.Read(passphraseSalt[:])
 		if err != nil {
 			str := "failed to read random source for passhprase salt"
-			return managerError(ErrCrypto, str, err)
+			return nil, managerError(ErrCrypto, str, err)
 		}
 
 		// Re-encrypt the crypto private key using the new master
@@ -821,13 +949,13 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 		decPriv, err := secretKey.Decrypt(m.cryptoKeyPrivEncrypted)
 		if err != nil {
 			str := "failed to decrypt crypto private key"
-			return managerError(ErrCrypto, str, err)
+			return nil, managerError(ErrCrypto, str, err)
 		}
 		encPriv, err := newMasterKey.Encrypt(decPriv)
 		zero.Bytes(decPriv)
 		if err != nil {
 			str := "failed to encrypt crypto private key"
-			return managerError(ErrCrypto, str, err)
+			return nil, managerError(ErrCrypto, str, err)
 		}
 
 		// Re-encrypt the crypto script key using the new master
@@ -835,13 +963,13 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 		decScript, err := secretKey.Decrypt(m.cryptoKeyScriptEncrypted)
 		if err != nil {
 			str := "failed to decrypt crypto script key"
-			return managerError(ErrCrypto, str, err)
+			return nil, managerError(ErrCrypto, str, err)
 		}
 		encScript, err := newMasterKey.Encrypt(decScript)
 		zero.Bytes(decScript)
 		if err != nil {
 			str := "failed to encrypt crypto script key"
-			return managerError(ErrCrypto, str, err)
+			return nil, managerError(ErrCrypto, str, err)
 		}
 
 		// When the manager is locked, ensure the new clear text master
@@ -862,50 +990,218 @@ func (m *Manager) ChangePassphrase(ns walletdb.ReadWriteBucket, oldPassphrase,
 		// transaction.
 		err = putCryptoKeys(ns, nil, encPriv, encScript)
 		if err != nil {
-			return maybeConvertDbError(err)
+			return nil, maybeConvertDbError(err)
 		}
 
 		err = putMasterKeyParams(ns, nil, newKeyParams)
 		if err != nil {
-			return maybeConvertDbError(err)
+			return nil, maybeConvertDbError(err)
 		}
 
-		// Now that the db has been successfully updated, clear the old
+		// Once the db has been successfully updated, clear the old
 		// key and set the new one.
-		copy(m.cryptoKeyPrivEncrypted[:], encPriv)
-		copy(m.cryptoKeyScriptEncrypted[:], encScript)
-		m.masterKeyPriv.Zero() // Clear the old key.
-		m.masterKeyPriv = newMasterKey
-		m.privPassphraseSalt = passphraseSalt
-		m.hashedPrivPassphrase = hashedPassphrase
-	} else {
-		// Re-encrypt the crypto public key using the new master public
-		// key.
-		encryptedPub, err := newMasterKey.Encrypt(m.cryptoKeyPub.Bytes())
+		return func() {
+			copy(m.cryptoKeyPrivEncrypted[:], encPriv)
+			copy(m.cryptoKeyScriptEncrypted[:], encScript)
+			m.masterKeyPriv.Zero() // Clear the old key.
+			m.masterKeyPriv = newMasterKey
+			m.privPassphraseSalt = passphraseSalt
+			m.hashedPrivPassphrase = hashedPassphrase
+		}, nil
+	}
+	// Re-encrypt the crypto public key using the new master public key.
+	encryptedPub, err := newMasterKey.Encrypt(m.cryptoKeyPub.Bytes())
+	if err != nil {
+		str := "failed to encrypt crypto public key"
+		return nil, managerError(ErrCrypto, str, err)
+	}
+
+	// Save the new keys and params to the the db in a single transaction.
+	err = putCryptoKeys(ns, encryptedPub, nil, nil)
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	err = putMasterKeyParams(ns, newKeyParams, nil)
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	// Once the db has been successfully updated, clear the old key and set
+	// the new one.
+	return func() {
+		m.masterKeyPub.Zero()
+		m.masterKeyPub = newMasterKey
+	}, nil
+}
+
+// ReencryptFunc decrypts data encrypted with a crypto key which is being
+// replaced and returns it encrypted with the new crypto key.
+type ReencryptFunc func(ciphertext []byte) ([]byte, error)
+
+// RotatePublicPassphrase changes the public passphrase of the manager from
+// oldPassphrase to newPassphrase and replaces the crypto public key, unlike
+// ChangePassphrase which only re-encrypts the existing crypto key.  The old
+// passphrase is therefore useless against the public data of the rotated
+// database, even together with a copy of the database from before the
+// rotation.  All data of the manager encrypted with the crypto public key is
+// re-encrypted with the new key.  Callers storing data encrypted with
+// CKTPublic outside of the manager, such as voting pools, must re-encrypt it
+// in the same transaction using the ReencryptFunc passed to reencrypt, which
+// may be nil.  The manager is left unchanged if an error is returned.
+//
+// The private passphrase is not required, so the public passphrase of locked
+// and watching-only managers can be rotated.  A nil config keeps the scrypt
+// parameters of the current public master key.
+func (m *Manager) RotatePublicPassphrase(ns walletdb.ReadWriteBucket,
+	oldPassphrase, newPassphrase []byte, config *ScryptOptions,
+	reencrypt func(ReencryptFunc) error) error {
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	apply, err := m.rotatePublicPassphrase(
+		ns, oldPassphrase, newPassphrase, config, reencrypt,
+	)
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// ChangePassphrases rotates the public passphrase as RotatePublicPassphrase
+// does and changes the private passphrase as ChangePassphrase does, in the
+// same transaction.  Both old passphrases are checked before any data is
+// changed, and the manager is only updated once both passphrases have been
+// changed in the database, so it is left unchanged if an error is returned.
+func (m *Manager) ChangePassphrases(ns walletdb.ReadWriteBucket, publicOld,
+	publicNew, privateOld, privateNew []byte, config *ScryptOptions,
+	reencrypt func(ReencryptFunc) error) error {
+
+	// No private passphrase to change for a watching-only address manager.
+	if m.watchingOnly {
+		return managerError(ErrWatchingOnly, errWatchingOnly, nil)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, private := range []bool{false, true} {
+		passphrase := publicOld
+		if private {
+			passphrase = privateOld
+		}
+		secretKey, err := m.deriveMasterKey(passphrase, private)
 		if err != nil {
-			str := "failed to encrypt crypto public key"
-			return managerError(ErrCrypto, str, err)
+			return err
 		}
+		secretKey.Zero()
+	}
 
-		// Save the new keys and params to the the db in a single
-		// transaction.
-		err = putCryptoKeys(ns, encryptedPub, nil, nil)
+	applyPublic, err := m.rotatePublicPassphrase(
+		ns, publicOld, publicNew, config, reencrypt,
+	)
+	if err != nil {
+		return err
+	}
+	applyPrivate, err := m.changePassphrase(
+		ns, privateOld, privateNew, true, config,
+	)
+	if err != nil {
+		return err
+	}
+	applyPublic()
+	applyPrivate()
+	return nil
+}
+
+// rotatePublicPassphrase stores a new crypto public key, encrypted with the
+// public master key derived from newPassphrase, and all public data
+// re-encrypted with it in the database.  The returned function updates the
+// manager to the new keys, and must only be called once the database has been
+// updated successfully.
+//
+// This function MUST be called with the manager lock held.
+func (m *Manager) rotatePublicPassphrase(ns walletdb.ReadWriteBucket,
+	oldPassphrase, newPassphrase []byte, config *ScryptOptions,
+	reencrypt func(ReencryptFunc) error) (func(), error) {
+
+	// Ensure the provided old passphrase is correct.
+	secretKey, err := m.deriveMasterKey(oldPassphrase, false)
+	if err != nil {
+		return nil, err
+	}
+	defer secretKey.Zero()
+
+	if config == nil {
+		config = &ScryptOptions{
+			N: secretKey.Parameters.N,
+			R: secretKey.Parameters.R,
+			P: secretKey.Parameters.P,
+		}
+	}
+
+	newMasterKey, err := newSecretKey(&newPassphrase, config)
+	if err != nil {
+		str := "failed to create new master public key"
+		return nil, managerError(ErrCrypto, str, err)
+	}
+	newCryptoKeyPub, err := newCryptoKey()
+	if err != nil {
+		str := "failed to generate crypto public key"
+		return nil, managerError(ErrCrypto, str, err)
+	}
+	encryptedPub, err := newMasterKey.Encrypt(newCryptoKeyPub.Bytes())
+	if err != nil {
+		str := "failed to encrypt crypto public key"
+		return nil, managerError(ErrCrypto, str, err)
+	}
+
+	oldCryptoKeyPub := m.cryptoKeyPub
+	reencryptFn := func(ciphertext []byte) ([]byte, error) {
+		plaintext, err := oldCryptoKeyPub.Decrypt(ciphertext)
 		if err != nil {
-			return maybeConvertDbError(err)
+			str := "failed to decrypt public data"
+			return nil, managerError(ErrCrypto, str, err)
 		}
+		defer zero.Bytes(plaintext)
 
-		err = putMasterKeyParams(ns, newKeyParams, nil)
+		reencrypted, err := newCryptoKeyPub.Encrypt(plaintext)
 		if err != nil {
-			return maybeConvertDbError(err)
+			str := "failed to encrypt public data"
+			return nil, managerError(ErrCrypto, str, err)
 		}
+		return reencrypted, nil
+	}
 
-		// Now that the db has been successfully updated, clear the old
-		// key and set the new one.
-		m.masterKeyPub.Zero()
-		m.masterKeyPub = newMasterKey
+	// Re-encrypt the public data of the manager and of the caller, and
+	// save the new keys and params in the same transaction.
+	if err := reencryptPublicData(ns, reencryptFn); err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+	if reencrypt != nil {
+		if err := reencrypt(reencryptFn); err != nil {
+			return nil, err
+		}
+	}
+	err = putCryptoKeys(ns, encryptedPub, nil, nil)
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+	err = putMasterKeyParams(ns, newMasterKey.Marshal(), nil)
+	if err != nil {
+		return nil, maybeConvertDbError(err)
 	}
 
-	return nil
+	// Once the db has been successfully updated, clear the old keys and
+	// set the new ones.
+	return func() {
+		m.masterKeyPub.Zero()
+		m.masterKeyPub = newMasterKey
+		m.cryptoKeyPub = newCryptoKeyPub
+		oldCryptoKeyPub.Zero()
+	}, nil
 }
 
 // ConvertToWatchingOnly converts the current address manager to a locked
@@ -1093,6 +1389,11 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) error {
 	// extended keys.
 	for _, manager := range m.scopedManagers {
 		for account, acctInfo := range manager.acctInfo {
+			// Watching-only accounts have no private key.
+			if len(acctInfo.acctKeyEncrypted) == 0 {
+				continue
+			}
+
 			decrypted, err := m.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
 			if err != nil {
 				m.lock()
@@ -1115,6 +1416,15 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) error {
 		// We'll also derive any private keys that are pending due to
 		// them being created while the address manager was locked.
 		for _, info := range manager.deriveOnUnlock {
+			// Addresses of watching-only accounts have no private
+			// key to derive.
+			acctInfo := manager.acctInfo[info.managedAddr.Account()]
+			if acctInfo != nil && len(acctInfo.acctKeyEncrypted) == 0 {
+				manager.deriveOnUnlock[0] = nil
+				manager.deriveOnUnlock = manager.deriveOnUnlock[1:]
+				continue
+			}
+
 			addressKey, err := manager.deriveKeyFromPath(
 				ns, info.managedAddr.Account(), info.branch,
 				info.index, true,
@@ -1173,6 +1483,17 @@ func ValidateAccountName(name string) error {
 	return nil
 }
 
+// ValidateAddressLabel validates the given address label and returns an error,
+// if any.
+func ValidateAddressLabel(label string) error {
+	if len(label) > MaxAddressLabelLen || !utf8.ValidString(label) {
+		str := fmt.Sprintf("labels must be valid UTF-8 of at most %d "+
+			"bytes", MaxAddressLabelLen)
+		return managerError(ErrInvalidLabel, str, nil)
+	}
+	return nil
+}
+
 // selectCryptoKey selects the appropriate crypto key based on the key type. An
 // error is returned when an invalid key type is specified or the requested key
 // requires the manager to be unlocked when it isn't.
@@ -1820,3 +2141,141 @@ func Create(ns walletdb.ReadWriteBucket, seed, pubPassphrase, privPassphrase []b
 	// Use 48 hours as margin of safety for wallet birthday.
 	return putBirthday(ns, birthday.Add(-48*time.Hour))
 }
+
+// CreateWatchingOnly creates a new watching-only address manager in the given
+// namespace.  Unlike Create, no seed or private key material is stored at all.
+// Instead, a key scope is created for every account extended public key in
+// accountPubKeys, which becomes the default account of that scope.  Addresses
+// of these accounts are derived and tracked as usual, but operations requiring
+// private keys, such as unlocking the manager, return an error with the code
+// ErrWatchingOnly.  Only the default key scopes are supported.
+//
+// The public passphrase is required on subsequent opens of the address
+// manager.  If a config structure is passed to the function, that
+// configuration will override the defaults.
+//
+// A ManagerError with an error code of ErrAlreadyExists will be returned the
+// address manager already exists in the specified namespace.
+func CreateWatchingOnly(ns walletdb.ReadWriteBucket,
+	accountPubKeys map[KeyScope]*hdkeychain.ExtendedKey,
+	pubPassphrase []byte, chainParams *chaincfg.Params,
+	config *ScryptOptions, birthday time.Time) error {
+
+	// Return an error if the manager has already been created in
+	// the given database namespace.
+	exists := managerExists(ns)
+	if exists {
+		return managerError(ErrAlreadyExists, errAlreadyExists, nil)
+	}
+
+	if len(accountPubKeys) == 0 {
+		str := "no account public keys provided"
+		return managerError(ErrKeyChain, str, nil)
+	}
+	scopeSchemas := make(map[KeyScope]ScopeAddrSchema, len(accountPubKeys))
+	for scope, acctKeyPub := range accountPubKeys {
+		schema, ok := ScopeAddrMap[scope]
+		if !ok {
+			str := fmt.Sprintf("unsupported key scope %v", &scope)
+			return managerError(ErrScopeNotFound, str, nil)
+		}
+		if acctKeyPub.IsPrivate() {
+			str := fmt.Sprintf("account key of scope %v is not a "+
+				"public key", &scope)
+			return managerError(ErrKeyChain, str, nil)
+		}
+		if !acctKeyPub.IsForNet(chainParams) {
+			str := fmt.Sprintf("account key of scope %v is not for "+
+				"network %v", &scope, chainParams.Name)
+			return managerError(ErrWrongNet, str, nil)
+		}
+		if err := checkBranchKeys(acctKeyPub); err != nil {
+			str := fmt.Sprintf("account key of scope %v is unusable",
+				&scope)
+			return managerError(ErrKeyChain, str, err)
+		}
+		scopeSchemas[scope] = schema
+	}
+
+	// Perform the initial bucket creation and database namespace setup.
+	if err := createManagerNS(ns, scopeSchemas); err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	if config == nil {
+		config = &DefaultScryptOptions
+	}
+
+	// Only the public master and crypto keys are generated, as there is
+	// no private key material to protect.
+	masterKeyPub, err := newSecretKey(&pubPassphrase, config)
+	if err != nil {
+		str := "failed to master public key"
+		return managerError(ErrCrypto, str, err)
+	}
+	cryptoKeyPub, err := newCryptoKey()
+	if err != nil {
+		str := "failed to generate crypto public key"
+		return managerError(ErrCrypto, str, err)
+	}
+	cryptoKeyPubEnc, err := masterKeyPub.Encrypt(cryptoKeyPub.Bytes())
+	if err != nil {
+		str := "failed to encrypt crypto public key"
+		return managerError(ErrCrypto, str, err)
+	}
+	err = putMasterKeyParams(ns, masterKeyPub.Marshal(), nil)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+	err = putCryptoKeys(ns, cryptoKeyPubEnc, nil, nil)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	// Save the default and imported accounts of each scope.
+	for scope, acctKeyPub := range accountPubKeys {
+		acctPubEnc, err := cryptoKeyPub.Encrypt(
+			[]byte(acctKeyPub.String()),
+		)
+		if err != nil {
+			str := fmt.Sprintf("failed to encrypt public key for "+
+				"account 0 of scope %v", &scope)
+			return managerError(ErrCrypto, str, err)
+		}
+		err = putAccountInfo(
+			ns, &scope, DefaultAccountNum, acctPubEnc, nil, 0, 0,
+			defaultAccountName,
+		)
+		if err != nil {
+			return maybeConvertDbError(err)
+		}
+		err = putAccountInfo(
+			ns, &scope, ImportedAddrAccount, nil, nil, 0, 0,
+			ImportedAddrAccountName,
+		)
+		if err != nil {
+			return maybeConvertDbError(err)
+		}
+	}
+
+	err = putWatchingOnly(ns, true)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	// Use the genesis block for the passed chain as the created at block
+	// and save the initial synced to state.
+	createdAt := &BlockStamp{Hash: *chainParams.GenesisHash, Height: 0}
+	syncInfo := newSyncState(createdAt, createdAt)
+	err = putSyncedTo(ns, &syncInfo.syncedTo)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+	err = putStartBlock(ns, &syncInfo.startBlock)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	// Use 48 hours as margin of safety for wallet birthday.
+	return putBirthday(ns, birthday.Add(-48*time.Hour))
+}
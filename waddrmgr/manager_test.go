@@ -10,12 +10,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/btcsuite/btcwallet/snacl"
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/walletdb"
@@ -1750,6 +1753,43 @@ func testSync(tc *testContext) bool {
 		return false
 	}
 
+	// Fast-forwarding past blocks which were not synced should succeed,
+	// and allow syncing the following blocks.
+	blockStamp = waddrmgr.BlockStamp{
+		Height:    100,
+		Hash:      chainhash.Hash{100},
+		Timestamp: time.Unix(5678, 0),
+	}
+	nextBlockStamp := waddrmgr.BlockStamp{
+		Height:    101,
+		Hash:      chainhash.Hash{101},
+		Timestamp: time.Unix(5679, 0),
+	}
+	err = walletdb.Update(tc.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		err := tc.rootManager.FastForwardSyncedTo(ns, &blockStamp)
+		if err != nil {
+			return err
+		}
+		if got := tc.rootManager.SyncedTo(); got != blockStamp {
+			tc.t.Errorf("SyncedTo unexpected block stamp after "+
+				"fast-forward -- got %v, want %v", got,
+				blockStamp)
+		}
+		return tc.rootManager.SetSyncedTo(ns, &nextBlockStamp)
+	})
+	if err != nil {
+		tc.t.Errorf("FastForwardSyncedTo unexpected err: %v", err)
+		return false
+	}
+	gotBlockStamp = tc.rootManager.SyncedTo()
+	if gotBlockStamp != nextBlockStamp {
+		tc.t.Errorf("SyncedTo unexpected block stamp after "+
+			"fast-forward -- got %v, want %v", gotBlockStamp,
+			nextBlockStamp)
+		return false
+	}
+
 	return true
 }
 
@@ -2435,3 +2475,924 @@ func TestNewRawAccount(t *testing.T) {
 			accountTargetAddr.AddrHash())
 	}
 }
+
+// TestCreateWatchingOnly ensures that a watching-only manager created from an
+// account extended public key derives the same addresses as a manager created
+// from the seed, and that it refuses operations requiring private keys.
+func TestCreateWatchingOnly(t *testing.T) {
+	t.Parallel()
+
+	// Derive the public key of the default BIP0084 account of the seed.
+	scope := waddrmgr.KeyScopeBIP0084
+	acctKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint32{scope.Purpose, scope.Coin, 0} {
+		acctKey, err = acctKey.Child(index + hdkeychain.HardenedKeyStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	acctKeyPub, err := acctKey.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	woTeardown, woDB := emptyDB(t)
+	defer woTeardown()
+
+	var woMgr *waddrmgr.Manager
+	err = walletdb.Update(woDB, func(tx walletdb.ReadWriteTx) error {
+		ns, err := tx.CreateTopLevelBucket(waddrmgrNamespaceKey)
+		if err != nil {
+			return err
+		}
+
+		// Private account keys are rejected.
+		err = waddrmgr.CreateWatchingOnly(
+			ns, map[waddrmgr.KeyScope]*hdkeychain.ExtendedKey{
+				scope: acctKey,
+			}, pubPassphrase, &chaincfg.MainNetParams, fastScrypt,
+			time.Time{},
+		)
+		if !waddrmgr.IsError(err, waddrmgr.ErrKeyChain) {
+			return fmt.Errorf("expected ErrKeyChain, got %v", err)
+		}
+
+		err = waddrmgr.CreateWatchingOnly(
+			ns, map[waddrmgr.KeyScope]*hdkeychain.ExtendedKey{
+				scope: acctKeyPub,
+			}, pubPassphrase, &chaincfg.MainNetParams, fastScrypt,
+			time.Time{},
+		)
+		if err != nil {
+			return err
+		}
+		woMgr, err = waddrmgr.Open(
+			ns, pubPassphrase, &chaincfg.MainNetParams,
+		)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("create/open: unexpected error: %v", err)
+	}
+	defer woMgr.Close()
+
+	if !woMgr.WatchOnly() {
+		t.Fatal("expected watching-only manager")
+	}
+
+	nextAddr := func(db walletdb.DB,
+		mgr *waddrmgr.Manager) waddrmgr.ManagedAddress {
+
+		scopedMgr, err := mgr.FetchScopedKeyManager(scope)
+		if err != nil {
+			t.Fatalf("unable to fetch scope %v: %v", scope, err)
+		}
+		var addr waddrmgr.ManagedAddress
+		err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+			ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+			addrs, err := scopedMgr.NextExternalAddresses(ns, 0, 1)
+			if err != nil {
+				return err
+			}
+			addr = addrs[0]
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unable to derive address: %v", err)
+		}
+		return addr
+	}
+	addr := nextAddr(db, mgr)
+	woAddr := nextAddr(woDB, woMgr)
+	if addr.Address().String() != woAddr.Address().String() {
+		t.Fatalf("expected address %v, got %v", addr.Address(),
+			woAddr.Address())
+	}
+
+	// Only the default scopes with an account key are created.
+	_, err = woMgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0044)
+	if !waddrmgr.IsError(err, waddrmgr.ErrScopeNotFound) {
+		t.Fatalf("expected ErrScopeNotFound, got %v", err)
+	}
+
+	err = walletdb.View(woDB, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		return woMgr.Unlock(ns, privPassphrase)
+	})
+	if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("expected ErrWatchingOnly unlocking, got %v", err)
+	}
+	_, err = woAddr.(waddrmgr.ManagedPubKeyAddress).PrivKey()
+	if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+}
+
+// TestNewAccountWatchingOnly ensures that a watching-only account imported
+// into a manager with private keys derives the addresses of its account key,
+// has no private keys, and does not prevent the manager from being unlocked.
+func TestNewAccountWatchingOnly(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use an account key unrelated to the seed of the manager.
+	otherSeed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	acctKey, err := hdkeychain.NewMaster(otherSeed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acctKeyPub, err := acctKey.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extKey, err := acctKeyPub.Child(waddrmgr.ExternalBranch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extKey, err = extKey.Child(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := extKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		&chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		account uint32
+		addr    waddrmgr.ManagedAddress
+	)
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+
+		_, err := scopedMgr.NewAccountWatchingOnly(ns, "hw", acctKey)
+		if !waddrmgr.IsError(err, waddrmgr.ErrKeyChain) {
+			return fmt.Errorf("expected ErrKeyChain importing "+
+				"private key, got %v", err)
+		}
+
+		account, err = scopedMgr.NewAccountWatchingOnly(
+			ns, "hw", acctKeyPub,
+		)
+		if err != nil {
+			return err
+		}
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		addrs, err := scopedMgr.NextExternalAddresses(ns, account, 1)
+		if err != nil {
+			return err
+		}
+		addr = addrs[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to import account: %v", err)
+	}
+
+	if account != 1 {
+		t.Fatalf("expected account 1, got %v", account)
+	}
+	if addr.Address().String() != wantAddr.String() {
+		t.Fatalf("expected address %v, got %v", wantAddr,
+			addr.Address())
+	}
+	_, err = addr.(waddrmgr.ManagedPubKeyAddress).PrivKey()
+	if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+
+	// The manager can still be locked and unlocked with the account.
+	if err := mgr.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		return mgr.Unlock(ns, privPassphrase)
+	})
+	if err != nil {
+		t.Fatalf("unable to unlock manager: %v", err)
+	}
+
+	// Accounts loaded from the database have an empty rather than a nil
+	// encrypted private key, so the account must also be recognized as
+	// watching-only when the wallet is reopened, whether it is loaded
+	// before or after the manager is unlocked.
+	var reopened *waddrmgr.Manager
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err error
+		reopened, err = waddrmgr.Open(
+			ns, pubPassphrase, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		if err := reopened.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		scopedMgr, err := reopened.FetchScopedKeyManager(
+			waddrmgr.KeyScopeBIP0084,
+		)
+		if err != nil {
+			return err
+		}
+		addr, err = scopedMgr.LastExternalAddress(ns, account)
+		if err != nil {
+			return err
+		}
+		if err := reopened.Lock(); err != nil {
+			return err
+		}
+		return reopened.Unlock(ns, privPassphrase)
+	})
+	if err != nil {
+		t.Fatalf("unable to unlock reopened manager: %v", err)
+	}
+	defer reopened.Close()
+
+	if addr.Address().String() != wantAddr.String() {
+		t.Fatalf("expected address %v, got %v", wantAddr,
+			addr.Address())
+	}
+	_, err = addr.(waddrmgr.ManagedPubKeyAddress).PrivKey()
+	if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+}
+
+// TestImportPublicKey tests that a public key can be imported into a scoped
+// key manager as a watched address of the scope's external address type.
+func TestImportPublicKey(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := privKey.PubKey()
+	wantAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		&chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var addr waddrmgr.ManagedPubKeyAddress
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+
+		var err error
+		addr, err = scopedMgr.ImportPublicKey(
+			ns, pubKey, &waddrmgr.BlockStamp{},
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = scopedMgr.ImportPublicKey(
+			ns, pubKey, &waddrmgr.BlockStamp{},
+		)
+		if !waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress) {
+			return fmt.Errorf("expected ErrDuplicateAddress "+
+				"importing key twice, got %v", err)
+		}
+		return mgr.Unlock(ns, privPassphrase)
+	})
+	if err != nil {
+		t.Fatalf("unable to import public key: %v", err)
+	}
+
+	if addr.Address().String() != wantAddr.String() {
+		t.Fatalf("expected address %v, got %v", wantAddr,
+			addr.Address())
+	}
+	if !addr.Imported() {
+		t.Fatal("expected imported address")
+	}
+	if _, err := addr.PrivKey(); !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+
+	// The address is also known to the manager after being loaded from
+	// the database.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		loaded, err := waddrmgr.Open(
+			ns, pubPassphrase, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		defer loaded.Close()
+
+		maddr, err := loaded.Address(ns, wantAddr)
+		if err != nil {
+			return err
+		}
+		_, err = maddr.(waddrmgr.ManagedPubKeyAddress).PrivKey()
+		if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+			return fmt.Errorf("expected ErrWatchingOnly, got %v",
+				err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDeriveAndStoreFromKeyPath tests that keys derived at an arbitrary path
+// are only known to the manager once stored.
+func TestDeriveAndStoreFromKeyPath(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := waddrmgr.DerivationPath{Account: 0, Branch: 7, Index: 42}
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+
+		derived, err := scopedMgr.DeriveFromKeyPath(ns, path)
+		if err != nil {
+			return err
+		}
+		_, err = scopedMgr.Address(ns, derived.Address())
+		if !waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return fmt.Errorf("expected ErrAddressNotFound before "+
+				"storing, got %v", err)
+		}
+
+		stored, err := scopedMgr.DeriveAndStoreFromKeyPath(ns, path)
+		if err != nil {
+			return err
+		}
+		if stored.Address().String() != derived.Address().String() {
+			return fmt.Errorf("expected address %v, got %v",
+				derived.Address(), stored.Address())
+		}
+
+		// Storing the same path again is not an error.
+		if _, err := scopedMgr.DeriveAndStoreFromKeyPath(ns, path); err != nil {
+			return err
+		}
+
+		maddr, err := scopedMgr.Address(ns, derived.Address())
+		if err != nil {
+			return err
+		}
+		pubKeyAddr := maddr.(waddrmgr.ManagedPubKeyAddress)
+		_, gotPath, _ := pubKeyAddr.DerivationInfo()
+		if gotPath != path {
+			return fmt.Errorf("expected path %v, got %v", path,
+				gotPath)
+		}
+
+		// Storing an address of the external branch must not skip
+		// the addresses before it either.
+		_, err = scopedMgr.DeriveAndStoreFromKeyPath(
+			ns, waddrmgr.DerivationPath{
+				Account: 0,
+				Branch:  waddrmgr.ExternalBranch,
+				Index:   9,
+			},
+		)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The next indexes of the account are persisted unchanged, so a
+	// reopened manager still hands out the first addresses.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		reopened, err := waddrmgr.Open(
+			ns, pubPassphrase, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		defer reopened.Close()
+
+		scopedMgr, err := reopened.FetchScopedKeyManager(
+			waddrmgr.KeyScopeBIP0084,
+		)
+		if err != nil {
+			return err
+		}
+		props, err := scopedMgr.AccountProperties(ns, 0)
+		if err != nil {
+			return err
+		}
+		if props.ExternalKeyCount != 0 || props.InternalKeyCount != 0 {
+			return fmt.Errorf("expected no derived keys, got %d "+
+				"external and %d internal",
+				props.ExternalKeyCount, props.InternalKeyCount)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDeriveFromRoot tests deriving keys from the root key matches the keys of
+// the key scopes.
+func TestDeriveFromRoot(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0044)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := []uint32{
+		44 + hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart,
+		hdkeychain.HardenedKeyStart, 0, 0,
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+
+		_, err := mgr.DeriveFromRoot(ns, path...)
+		if !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return fmt.Errorf("expected ErrLocked, got %v", err)
+		}
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+
+		key, err := mgr.DeriveFromRoot(ns, path...)
+		if err != nil {
+			return err
+		}
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return err
+		}
+
+		maddr, err := scopedMgr.DeriveFromKeyPath(
+			ns, waddrmgr.DerivationPath{},
+		)
+		if err != nil {
+			return err
+		}
+		wantPubKey := maddr.(waddrmgr.ManagedPubKeyAddress).PubKey()
+		if !pubKey.IsEqual(wantPubKey) {
+			return fmt.Errorf("expected key %x, got %x",
+				wantPubKey.SerializeCompressed(),
+				pubKey.SerializeCompressed())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAddressLabels tests setting, fetching and removing address labels.
+func TestAddressLabels(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+
+		addrs, err := scopedMgr.NextExternalAddresses(ns, 0, 2)
+		if err != nil {
+			return err
+		}
+		labeled := addrs[0].Address()
+		unlabeled := addrs[1].Address()
+
+		label, err := mgr.AddressLabel(ns, labeled)
+		if err != nil {
+			return err
+		}
+		if label != "" {
+			return fmt.Errorf("expected no label, got %q", label)
+		}
+
+		if err := mgr.SetAddressLabel(ns, labeled, "invoice 1"); err != nil {
+			return err
+		}
+		label, err = scopedMgr.AddressLabel(ns, labeled)
+		if err != nil {
+			return err
+		}
+		if label != "invoice 1" {
+			return fmt.Errorf("expected label %q, got %q",
+				"invoice 1", label)
+		}
+
+		labels := make(map[string]string)
+		err = scopedMgr.ForEachAddressLabel(ns,
+			func(addr btcutil.Address, label string) error {
+				labels[addr.EncodeAddress()] = label
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		expected := map[string]string{
+			labeled.EncodeAddress(): "invoice 1",
+		}
+		if !reflect.DeepEqual(labels, expected) {
+			return fmt.Errorf("expected labels %v, got %v",
+				expected, labels)
+		}
+
+		longLabel := strings.Repeat("a", waddrmgr.MaxAddressLabelLen+1)
+		err = mgr.SetAddressLabel(ns, unlabeled, longLabel)
+		if !waddrmgr.IsError(err, waddrmgr.ErrInvalidLabel) {
+			return fmt.Errorf("expected ErrInvalidLabel, got %v",
+				err)
+		}
+
+		unknown, err := btcutil.NewAddressWitnessPubKeyHash(
+			make([]byte, 20), &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		err = mgr.SetAddressLabel(ns, unknown, "unknown")
+		if !waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return fmt.Errorf("expected ErrAddressNotFound, got "+
+				"%v", err)
+		}
+
+		// An empty label removes the label.
+		if err := mgr.SetAddressLabel(ns, labeled, ""); err != nil {
+			return err
+		}
+		label, err = mgr.AddressLabel(ns, labeled)
+		if err != nil {
+			return err
+		}
+		if label != "" {
+			return fmt.Errorf("expected removed label, got %q",
+				label)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestBirthdayBlock ensures the birthday block is stored and is removed when
+// the birthday changes.
+func TestBirthdayBlock(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	block := waddrmgr.BlockStamp{
+		Height:    1000,
+		Hash:      chainhash.Hash{0x01},
+		Timestamp: time.Unix(1500000000, 0),
+	}
+	err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		_, err := mgr.BirthdayBlock(ns)
+		if !waddrmgr.IsError(err, waddrmgr.ErrBirthdayBlockNotSet) {
+			return fmt.Errorf("expected ErrBirthdayBlockNotSet, "+
+				"got %v", err)
+		}
+
+		if err := mgr.SetBirthdayBlock(ns, block); err != nil {
+			return err
+		}
+		stored, err := mgr.BirthdayBlock(ns)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(stored, block) {
+			return fmt.Errorf("expected birthday block %v, got %v",
+				spew.Sdump(block), spew.Sdump(stored))
+		}
+
+		// Moving the birthday back invalidates the block.
+		err = mgr.SetBirthday(ns, block.Timestamp.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		_, err = mgr.BirthdayBlock(ns)
+		if !waddrmgr.IsError(err, waddrmgr.ErrBirthdayBlockNotSet) {
+			return fmt.Errorf("expected ErrBirthdayBlockNotSet after "+
+				"setting birthday, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestChangePassphraseKeepsScrypt ensures the scrypt parameters of the current
+// passphrase are kept when changing it without new parameters.
+func TestChangePassphraseKeepsScrypt(t *testing.T) {
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	var configs []waddrmgr.ScryptOptions
+	oldKeyGen := waddrmgr.SetSecretKeyGen(func(passphrase *[]byte,
+		config *waddrmgr.ScryptOptions) (*snacl.SecretKey, error) {
+
+		configs = append(configs, *config)
+		return snacl.NewSecretKey(
+			passphrase, config.N, config.R, config.P,
+		)
+	})
+	defer waddrmgr.SetSecretKeyGen(oldKeyGen)
+
+	err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		err := mgr.ChangePassphrase(
+			ns, pubPassphrase, pubPassphrase2, false, nil,
+		)
+		if err != nil {
+			return err
+		}
+		return mgr.ChangePassphrase(
+			ns, privPassphrase, privPassphrase2, true, nil,
+		)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 new secret keys, got %d", len(configs))
+	}
+	for _, config := range configs {
+		if config != *fastScrypt {
+			t.Fatalf("expected scrypt options %v, got %v",
+				*fastScrypt, config)
+		}
+	}
+}
+
+// TestRotatePublicPassphrase tests that rotating the public passphrase of a
+// locked manager re-encrypts all public data with a new crypto key.
+func TestRotatePublicPassphrase(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0044)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := []byte{0x51} // OP_TRUE
+
+	var addrs []btcutil.Address
+	var oldKey []byte
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		defer mgr.Lock()
+
+		chainAddrs, err := scopedMgr.NextExternalAddresses(ns, 0, 1)
+		if err != nil {
+			return err
+		}
+		importedAddr, err := scopedMgr.ImportPublicKey(
+			ns, privKey.PubKey(), &waddrmgr.BlockStamp{},
+		)
+		if err != nil {
+			return err
+		}
+		scriptAddr, err := scopedMgr.ImportScript(
+			ns, script, &waddrmgr.BlockStamp{},
+		)
+		if err != nil {
+			return err
+		}
+		addrs = []btcutil.Address{
+			chainAddrs[0].Address(), importedAddr.Address(),
+			scriptAddr.Address(),
+		}
+
+		oldKey, err = mgr.Encrypt(waddrmgr.CKTPublic, []byte("data"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reencrypted []byte
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		err := mgr.RotatePublicPassphrase(
+			ns, pubPassphrase2, pubPassphrase, nil, nil,
+		)
+		if !waddrmgr.IsError(err, waddrmgr.ErrWrongPassphrase) {
+			return fmt.Errorf("expected ErrWrongPassphrase, got %v",
+				err)
+		}
+
+		return mgr.RotatePublicPassphrase(
+			ns, pubPassphrase, pubPassphrase2, nil,
+			func(reencrypt waddrmgr.ReencryptFunc) error {
+				var err error
+				reencrypted, err = reencrypt(oldKey)
+				return err
+			},
+		)
+	})
+	if err != nil {
+		t.Fatalf("unable to rotate public passphrase: %v", err)
+	}
+
+	// Data encrypted with the old crypto key is only readable after being
+	// re-encrypted.
+	if _, err := mgr.Decrypt(waddrmgr.CKTPublic, oldKey); err == nil {
+		t.Fatal("expected error decrypting with the rotated key")
+	}
+	plaintext, err := mgr.Decrypt(waddrmgr.CKTPublic, reencrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "data" {
+		t.Fatalf("expected re-encrypted data, got %q", plaintext)
+	}
+
+	// Only the new passphrase opens the manager, and all addresses and
+	// the account keys load with the new crypto key.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		_, err := waddrmgr.Open(ns, pubPassphrase, &chaincfg.MainNetParams)
+		if !waddrmgr.IsError(err, waddrmgr.ErrWrongPassphrase) {
+			return fmt.Errorf("expected ErrWrongPassphrase opening "+
+				"with the old passphrase, got %v", err)
+		}
+
+		loaded, err := waddrmgr.Open(
+			ns, pubPassphrase2, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		defer loaded.Close()
+
+		for _, addr := range addrs {
+			maddr, err := loaded.Address(ns, addr)
+			if err != nil {
+				return err
+			}
+			if maddr.Address().String() != addr.String() {
+				return fmt.Errorf("expected address %v, got %v",
+					addr, maddr.Address())
+			}
+		}
+
+		loadedScope, err := loaded.FetchScopedKeyManager(
+			waddrmgr.KeyScopeBIP0044,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = loadedScope.AccountProperties(ns, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestChangePassphrases tests that changing both passphrases leaves the
+// manager and database unchanged when either old passphrase is wrong.
+func TestChangePassphrases(t *testing.T) {
+	t.Parallel()
+
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	var encrypted []byte
+	err := walletdb.View(db, func(tx walletdb.ReadTx) error {
+		var err error
+		encrypted, err = mgr.Encrypt(waddrmgr.CKTPublic, []byte("data"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                  string
+		publicOld, privateOld []byte
+	}{
+		{"wrong public passphrase", pubPassphrase2, privPassphrase},
+		{"wrong private passphrase", pubPassphrase, privPassphrase2},
+	}
+	for _, test := range tests {
+		err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+			ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+			return mgr.ChangePassphrases(
+				ns, test.publicOld, pubPassphrase2,
+				test.privateOld, privPassphrase2, nil, nil,
+			)
+		})
+		if !waddrmgr.IsError(err, waddrmgr.ErrWrongPassphrase) {
+			t.Fatalf("%s: expected ErrWrongPassphrase, got %v",
+				test.name, err)
+		}
+
+		// The crypto public key and private passphrase are unchanged.
+		if _, err := mgr.Decrypt(waddrmgr.CKTPublic, encrypted); err != nil {
+			t.Fatalf("%s: unable to decrypt public data: %v",
+				test.name, err)
+		}
+		err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+			ns := tx.ReadBucket(waddrmgrNamespaceKey)
+			if err := mgr.Unlock(ns, privPassphrase); err != nil {
+				return err
+			}
+			return mgr.Lock()
+		})
+		if err != nil {
+			t.Fatalf("%s: unable to unlock with the old private "+
+				"passphrase: %v", test.name, err)
+		}
+	}
+
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return mgr.ChangePassphrases(
+			ns, pubPassphrase, pubPassphrase2, privPassphrase,
+			privPassphrase2, nil, nil,
+		)
+	})
+	if err != nil {
+		t.Fatalf("unable to change passphrases: %v", err)
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		loaded, err := waddrmgr.Open(
+			ns, pubPassphrase2, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			return err
+		}
+		defer loaded.Close()
+		return loaded.Unlock(ns, privPassphrase2)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
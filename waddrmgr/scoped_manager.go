@@ -3,6 +3,7 @@ package waddrmgr
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -264,9 +265,10 @@ func (s *ScopedKeyManager) deriveKey(acctInfo *accountInfo, branch,
 
 	// Choose the public or private extended key based on whether or not
 	// the private flag was specified.  This, in turn, allows for public or
-	// private child derivation.
+	// private child derivation.  Watching-only accounts have no private
+	// key, so public keys are always derived for them.
 	acctKey := acctInfo.acctKeyPub
-	if private {
+	if private && acctInfo.acctKeyPriv != nil {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -342,7 +344,7 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 		nextInternalIndex: row.nextInternalIndex,
 	}
 
-	if !s.rootManager.isLocked() {
+	if !s.rootManager.isLocked() && len(acctInfo.acctKeyEncrypted) != 0 {
 		// Use the crypto private key to decrypt the account private
 		// extended keys.
 		decrypted, err := s.rootManager.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
@@ -467,6 +469,54 @@ func (s *ScopedKeyManager) DeriveFromKeyPath(ns walletdb.ReadBucket,
 	return s.keyToManaged(extKey, kp.Account, kp.Branch, kp.Index)
 }
 
+// DeriveAndStoreFromKeyPath derives the child key of the given key path like
+// DeriveFromKeyPath, and also stores its address to the database so it is
+// recognized as belonging to the account.  Any branch of an existing account
+// may be used, so keys outside of the external and internal branches can be
+// derived and watched.  Storing an address which is already known has no
+// effect.
+func (s *ScopedKeyManager) DeriveAndStoreFromKeyPath(ns walletdb.ReadWriteBucket,
+	kp DerivationPath) (ManagedAddress, error) {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	extKey, err := s.deriveKeyFromPath(
+		ns, kp.Account, kp.Branch, kp.Index, !s.rootManager.IsLocked(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ma, err := s.keyToManaged(extKey, kp.Account, kp.Branch, kp.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	addressID := ma.Address().ScriptAddress()
+	if s.existsAddress(ns, addressID) {
+		return ma, nil
+	}
+
+	// The address is stored without updating the next indexes of the
+	// account, as those only track the addresses handed out by
+	// NextExternalAddresses and NextInternalAddresses.
+	addrRow := dbAddressRow{
+		addrType:   adtChain,
+		account:    kp.Account,
+		addTime:    uint64(time.Now().Unix()),
+		syncStatus: ssFull,
+		rawData:    serializeChainedAddress(kp.Branch, kp.Index),
+	}
+	err = putAddress(ns, &s.scope, addressID, &addrRow)
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	s.addrs[addrKey(addressID)] = ma
+	return ma, nil
+}
+
 // deriveKeyFromPath returns either a public or private derived extended key
 // based on the private flag for the given an account, branch, and index.
 //
@@ -685,9 +735,9 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 	}
 
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and the account is watching-only.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && acctInfo.acctKeyPriv != nil {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -867,9 +917,9 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 	}
 
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and the account is watching-only.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && acctInfo.acctKeyPriv != nil {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -1231,6 +1281,76 @@ func (s *ScopedKeyManager) NewAccount(ns walletdb.ReadWriteBucket, name string)
 	return account, nil
 }
 
+// NewAccountWatchingOnly creates and returns a new watching-only account
+// stored in the manager based on the given account name and account extended
+// public key.  Addresses of the account are derived from the public key using
+// the address schema of the scope, and have no private keys.  Unlike
+// NewAccount, the manager does not need to be unlocked, and it may be
+// watching-only itself.  If an account with the same name already exists,
+// ErrDuplicateAccount will be returned.
+func (s *ScopedKeyManager) NewAccountWatchingOnly(ns walletdb.ReadWriteBucket,
+	name string, pubKey *hdkeychain.ExtendedKey) (uint32, error) {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if pubKey.IsPrivate() {
+		str := "account key is not a public key"
+		return 0, managerError(ErrKeyChain, str, nil)
+	}
+	if !pubKey.IsForNet(s.rootManager.chainParams) {
+		str := fmt.Sprintf("account key is not for network %v",
+			s.rootManager.chainParams.Name)
+		return 0, managerError(ErrWrongNet, str, nil)
+	}
+	if err := checkBranchKeys(pubKey); err != nil {
+		str := "account key is unusable"
+		return 0, managerError(ErrKeyChain, str, err)
+	}
+
+	// Validate the account name.
+	if err := ValidateAccountName(name); err != nil {
+		return 0, err
+	}
+
+	// Check that account with the same name does not exist
+	_, err := s.lookupAccount(ns, name)
+	if err == nil {
+		str := fmt.Sprintf("account with the same name already exists")
+		return 0, managerError(ErrDuplicateAccount, str, err)
+	}
+
+	account, err := fetchLastAccount(ns, &s.scope)
+	if err != nil {
+		return 0, err
+	}
+	account++
+	if account > MaxAccountNum {
+		return 0, managerError(ErrAccountNumTooHigh, errAcctTooHigh, nil)
+	}
+
+	acctPubEnc, err := s.rootManager.cryptoKeyPub.Encrypt(
+		[]byte(pubKey.String()),
+	)
+	if err != nil {
+		str := "failed to encrypt public key for account"
+		return 0, managerError(ErrCrypto, str, err)
+	}
+
+	// Without an encrypted private key, the account is loaded as
+	// watching-only.
+	err = putAccountInfo(
+		ns, &s.scope, account, acctPubEnc, nil, 0, 0, name,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := putLastAccount(ns, &s.scope, account); err != nil {
+		return 0, err
+	}
+	return account, nil
+}
+
 // newAccount is a helper function that derives a new precise account number,
 // and creates a mapping from the passed name to the account number in the
 // database.
@@ -1381,6 +1501,12 @@ func (s *ScopedKeyManager) RenameAccount(ns walletdb.ReadWriteBucket,
 	return err
 }
 
+// importedDerivationPath is the derivation path of imported keys.  It is
+// incomplete as we don't know exactly how the keys were derived.
+var importedDerivationPath = DerivationPath{
+	Account: ImportedAddrAccount,
+}
+
 // ImportPrivateKey imports a WIF private key into the address manager.  The
 // imported address is created using either a compressed or uncompressed
 // serialized public key, depending on the CompressPubKey bool of the WIF.
@@ -1418,14 +1544,105 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 		return nil, managerError(ErrLocked, errLocked, nil)
 	}
 
-	// Prevent duplicates.
+	// Encrypt the private key when not a watching-only address manager.
 	serializedPubKey := wif.SerializePubKey()
+	var encryptedPrivKey []byte
+	if !s.rootManager.WatchOnly() {
+		var err error
+		privKeyBytes := wif.PrivKey.Serialize()
+		encryptedPrivKey, err = s.rootManager.cryptoKeyPriv.Encrypt(privKeyBytes)
+		zero.Bytes(privKeyBytes)
+		if err != nil {
+			str := fmt.Sprintf("failed to encrypt private key for %x",
+				serializedPubKey)
+			return nil, managerError(ErrCrypto, str, err)
+		}
+	}
+
+	err := s.putImportedKey(ns, serializedPubKey, encryptedPrivKey, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new managed address based on the imported address.
+	var managedAddr *managedAddress
+	if !s.rootManager.WatchOnly() {
+		managedAddr, err = newManagedAddress(
+			s, importedDerivationPath, wif.PrivKey,
+			wif.CompressPubKey, s.addrSchema.ExternalAddrType,
+		)
+	} else {
+		pubKey := (*btcec.PublicKey)(&wif.PrivKey.PublicKey)
+		managedAddr, err = newManagedAddressWithoutPrivKey(
+			s, importedDerivationPath, pubKey, wif.CompressPubKey,
+			s.addrSchema.ExternalAddrType,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	managedAddr.imported = true
+
+	// Add the new managed address to the cache of recent addresses and
+	// return it.
+	s.addrs[addrKey(managedAddr.Address().ScriptAddress())] = managedAddr
+	return managedAddr, nil
+}
+
+// ImportPublicKey imports a public key into the address manager.  The imported
+// address is of the external address type of the scope, such as P2PKH for the
+// BIP0044 scope and P2WPKH for the BIP0084 scope, and is created using the
+// compressed serialization of the key.  Outputs paying to the address are
+// detected, but as the private key is not known, the address manager can not
+// sign for it and PrivKey of the address returns ErrWatchingOnly.
+//
+// All imported addresses will be part of the account defined by the
+// ImportedAddrAccount constant.
+//
+// This function will return an error if the address already exists.  Any
+// other errors returned are generally unexpected.
+func (s *ScopedKeyManager) ImportPublicKey(ns walletdb.ReadWriteBucket,
+	pubKey *btcec.PublicKey, bs *BlockStamp) (ManagedPubKeyAddress, error) {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	serializedPubKey := pubKey.SerializeCompressed()
+	err := s.putImportedKey(ns, serializedPubKey, nil, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	managedAddr, err := newManagedAddressWithoutPrivKey(
+		s, importedDerivationPath, pubKey, true,
+		s.addrSchema.ExternalAddrType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	managedAddr.imported = true
+
+	// Add the new managed address to the cache of recent addresses and
+	// return it.
+	s.addrs[addrKey(managedAddr.Address().ScriptAddress())] = managedAddr
+	return managedAddr, nil
+}
+
+// putImportedKey stores the imported address of the serialized public key and
+// an optional encrypted private key to the database, updating the start block
+// when bs is before it.  An error is returned if the address already exists.
+//
+// This function MUST be called with the manager lock held for writes.
+func (s *ScopedKeyManager) putImportedKey(ns walletdb.ReadWriteBucket,
+	serializedPubKey, encryptedPrivKey []byte, bs *BlockStamp) error {
+
+	// Prevent duplicates.
 	pubKeyHash := btcutil.Hash160(serializedPubKey)
 	alreadyExists := s.existsAddress(ns, pubKeyHash)
 	if alreadyExists {
 		str := fmt.Sprintf("address for public key %x already exists",
 			serializedPubKey)
-		return nil, managerError(ErrDuplicateAddress, str, nil)
+		return managerError(ErrDuplicateAddress, str, nil)
 	}
 
 	// Encrypt public key.
@@ -1435,20 +1652,7 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 	if err != nil {
 		str := fmt.Sprintf("failed to encrypt public key for %x",
 			serializedPubKey)
-		return nil, managerError(ErrCrypto, str, err)
-	}
-
-	// Encrypt the private key when not a watching-only address manager.
-	var encryptedPrivKey []byte
-	if !s.rootManager.WatchOnly() {
-		privKeyBytes := wif.PrivKey.Serialize()
-		encryptedPrivKey, err = s.rootManager.cryptoKeyPriv.Encrypt(privKeyBytes)
-		zero.Bytes(privKeyBytes)
-		if err != nil {
-			str := fmt.Sprintf("failed to encrypt private key for %x",
-				serializedPubKey)
-			return nil, managerError(ErrCrypto, str, err)
-		}
+		return managerError(ErrCrypto, str, err)
 	}
 
 	// The start block needs to be updated when the newly imported address
@@ -1464,13 +1668,13 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 		encryptedPubKey, encryptedPrivKey,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if updateStartBlock {
 		err := putStartBlock(ns, bs)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -1482,35 +1686,7 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 		s.rootManager.mtx.Unlock()
 	}
 
-	// The full derivation path for an imported key is incomplete as we
-	// don't know exactly how it was derived.
-	importedDerivationPath := DerivationPath{
-		Account: ImportedAddrAccount,
-	}
-
-	// Create a new managed address based on the imported address.
-	var managedAddr *managedAddress
-	if !s.rootManager.WatchOnly() {
-		managedAddr, err = newManagedAddress(
-			s, importedDerivationPath, wif.PrivKey,
-			wif.CompressPubKey, s.addrSchema.ExternalAddrType,
-		)
-	} else {
-		pubKey := (*btcec.PublicKey)(&wif.PrivKey.PublicKey)
-		managedAddr, err = newManagedAddressWithoutPrivKey(
-			s, importedDerivationPath, pubKey, wif.CompressPubKey,
-			s.addrSchema.ExternalAddrType,
-		)
-	}
-	if err != nil {
-		return nil, err
-	}
-	managedAddr.imported = true
-
-	// Add the new managed address to the cache of recent addresses and
-	// return it.
-	s.addrs[addrKey(managedAddr.Address().ScriptAddress())] = managedAddr
-	return managedAddr, nil
+	return nil
 }
 
 // ImportScript imports a user-provided script into the address manager.  The
@@ -1664,6 +1840,71 @@ func (s *ScopedKeyManager) MarkUsed(ns walletdb.ReadWriteBucket,
 	return nil
 }
 
+// SetAddressLabel sets the label of the provided address, replacing any
+// existing label.  An empty label removes the label of the address.  The label
+// must be valid UTF-8 and no longer than MaxAddressLabelLen bytes.
+func (s *ScopedKeyManager) SetAddressLabel(ns walletdb.ReadWriteBucket,
+	address btcutil.Address, label string) error {
+
+	if err := ValidateAddressLabel(label); err != nil {
+		return err
+	}
+
+	maddr, err := s.Address(ns, address)
+	if err != nil {
+		return err
+	}
+
+	err = putAddressLabel(ns, &s.scope, maddr.Address().ScriptAddress(), label)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+	return nil
+}
+
+// AddressLabel returns the label of the provided address, or an empty string
+// if the address has no label.
+func (s *ScopedKeyManager) AddressLabel(ns walletdb.ReadBucket,
+	address btcutil.Address) (string, error) {
+
+	maddr, err := s.Address(ns, address)
+	if err != nil {
+		return "", err
+	}
+
+	label, err := fetchAddressLabel(
+		ns, &s.scope, maddr.Address().ScriptAddress(),
+	)
+	if err != nil {
+		return "", maybeConvertDbError(err)
+	}
+	return label, nil
+}
+
+// ForEachAddressLabel calls the given function with each labeled address
+// stored in the manager and its label, breaking early on error.
+func (s *ScopedKeyManager) ForEachAddressLabel(ns walletdb.ReadBucket,
+	fn func(addr btcutil.Address, label string) error) error {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	labelFn := func(rowInterface interface{}, label string) error {
+		managedAddr, err := s.rowInterfaceToManaged(ns, rowInterface)
+		if err != nil {
+			return err
+		}
+		return fn(managedAddr.Address(), label)
+	}
+
+	err := forEachAddressLabel(ns, &s.scope, labelFn)
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
 // ChainParams returns the chain parameters for this address manager.
 func (s *ScopedKeyManager) ChainParams() *chaincfg.Params {
 	// NOTE: No need for mutex here since the net field does not change
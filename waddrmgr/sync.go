@@ -69,6 +69,46 @@ func (m *Manager) SetSyncedTo(ns walletdb.ReadWriteBucket, bs *BlockStamp) error
 	return nil
 }
 
+// PutSyncedTo stores bs as the block the manager is synced to, as SetSyncedTo
+// does, but leaves the block returned by SyncedTo unchanged until it is set
+// with MarkSyncedTo.  This allows callers to only update it once the database
+// transaction storing bs has been committed.
+func (m *Manager) PutSyncedTo(ns walletdb.ReadWriteBucket, bs *BlockStamp) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return putSyncedTo(ns, bs)
+}
+
+// MarkSyncedTo sets the block returned by SyncedTo to bs, which must have been
+// stored by PutSyncedTo in a committed database transaction.
+func (m *Manager) MarkSyncedTo(bs *BlockStamp) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.syncState.syncedTo = *bs
+}
+
+// FastForwardSyncedTo marks the address manager to be in sync with the block
+// described by the blockstamp without the blocks between the synced to block
+// and bs having been synced, which SetSyncedTo requires.  This allows skipping
+// blocks that can not contain any transactions of the manager, such as the
+// blocks before its birthday.  Only the hash of bs is stored, so reorgs below
+// it can not be detected.  The blockstamp must be past the synced to block.
+func (m *Manager) FastForwardSyncedTo(ns walletdb.ReadWriteBucket,
+	bs *BlockStamp) error {
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	err := putSyncedToBlock(ns, bs)
+	if err != nil {
+		return err
+	}
+	m.syncState.syncedTo = *bs
+	return nil
+}
+
 // SyncedTo returns details about the block height and hash that the address
 // manager is synced through at the very least.  The intention is that callers
 // can use this information for intelligently initiating rescans to sync back to
@@ -101,12 +141,36 @@ func (m *Manager) Birthday() time.Time {
 }
 
 // SetBirthday sets the birthday, or earliest time a key could have been used,
-// for the manager.
+// for the manager.  The stored birthday block no longer matches the new
+// birthday, so it is removed and must be found again.
 func (m *Manager) SetBirthday(ns walletdb.ReadWriteBucket,
 	birthday time.Time) error {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
 	m.birthday = birthday
-	return putBirthday(ns, birthday)
+	if err := putBirthday(ns, birthday); err != nil {
+		return err
+	}
+	return deleteBirthdayBlock(ns)
+}
+
+// BirthdayBlock returns the block stamp of the first block after the
+// manager's birthday, which is where a rescan of a restored wallet starts.
+// ErrBirthdayBlockNotSet is returned if the block has not been found yet.
+func (m *Manager) BirthdayBlock(ns walletdb.ReadBucket) (BlockStamp, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return fetchBirthdayBlock(ns)
+}
+
+// SetBirthdayBlock stores the block stamp of the first block after the
+// manager's birthday.
+func (m *Manager) SetBirthdayBlock(ns walletdb.ReadWriteBucket,
+	block BlockStamp) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return putBirthdayBlock(ns, block)
 }
@@ -0,0 +1,104 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// AbandonTransaction removes the unmined transaction txHash, and every unmined
+// transaction spending its outputs, from the wallet.  The outputs spent by the
+// removed transactions become spendable again, and the transaction is no
+// longer rebroadcast.  Clients of transaction notifications are notified of
+// the removal and the new balances of the affected accounts.
+//
+// Abandoning a transaction does not prevent it from being mined if it is still
+// relayed by the network, in which case it is added back to the wallet once it
+// is mined.
+func (w *Wallet) AbandonTransaction(txHash *chainhash.Hash) error {
+	var removed []TransactionSummary
+	err := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+
+		details, err := w.TxStore.TxDetails(txmgrNs, txHash)
+		if err != nil {
+			return err
+		}
+		if details == nil {
+			return fmt.Errorf("transaction %v not found", txHash)
+		}
+		if details.Block.Height != -1 {
+			return fmt.Errorf("transaction %v is already mined",
+				txHash)
+		}
+
+		// The summaries are made before removing the transactions, as
+		// the accounts of their inputs are looked up through the store.
+		descendants, err := w.unminedDescendants(txmgrNs, txHash)
+		if err != nil {
+			return err
+		}
+		removed = append(removed, makeTxSummary(dbtx, w, details))
+		for i := range descendants {
+			d, err := w.TxStore.TxDetails(txmgrNs, &descendants[i])
+			if err != nil {
+				return err
+			}
+			if d != nil {
+				removed = append(removed, makeTxSummary(dbtx, w, d))
+			}
+		}
+
+		return w.TxStore.RemoveUnminedTx(txmgrNs, &details.TxRecord)
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Abandoned unmined transaction %v", txHash)
+
+	// Clients are only notified once the removal was committed.
+	return walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		w.NtfnServer.notifyRemovedUnminedTransactions(dbtx, removed)
+		return nil
+	})
+}
+
+// unminedDescendants returns the hashes of the unmined transactions which
+// spend the outputs of the unmined transaction txHash, directly or through
+// other unmined transactions.  These are removed along with it.
+func (w *Wallet) unminedDescendants(ns walletdb.ReadBucket,
+	txHash *chainhash.Hash) ([]chainhash.Hash, error) {
+
+	unmined, err := w.TxStore.UnminedTxs(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	spent := map[chainhash.Hash]struct{}{*txHash: {}}
+	var descendants []chainhash.Hash
+	for found := true; found; {
+		found = false
+		for _, tx := range unmined {
+			hash := tx.TxHash()
+			if _, ok := spent[hash]; ok {
+				continue
+			}
+			for _, in := range tx.TxIn {
+				_, ok := spent[in.PreviousOutPoint.Hash]
+				if !ok {
+					continue
+				}
+				spent[hash] = struct{}{}
+				descendants = append(descendants, hash)
+				found = true
+				break
+			}
+		}
+	}
+	return descendants, nil
+}
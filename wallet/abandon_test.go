@@ -0,0 +1,106 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestAbandonTransaction ensures that abandoning an unmined transaction
+// removes the unmined transactions spending it, and that clients are notified
+// of the balances of the accounts of every removed transaction once the
+// removal was committed.
+func TestAbandonTransaction(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	w.Start()
+	if err := w.Unlock(testPrivPass, nil); err != nil {
+		t.Fatal(err)
+	}
+	account, err := w.NextAccount(waddrmgr.KeyScopeBIP0084, "spender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payScript := func(account uint32) []byte {
+		addr, err := w.NewAddress(account, waddrmgr.KeyScopeBIP0084)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkScript
+	}
+
+	// The abandoned transaction pays the default account, and its child
+	// spends that output to the new account.
+	parent := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	parent.TxOut[0].PkScript = payScript(waddrmgr.DefaultAccountNum)
+	child := testTx(wire.OutPoint{Hash: parent.TxHash()}, 9e5)
+	child.TxOut[0].PkScript = payScript(account)
+	for _, tx := range []*wire.MsgTx{parent, child} {
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			return w.insertRelevantTx(dbTx, rec, nil)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := w.NtfnServer.TransactionNotifications()
+	defer client.Done()
+
+	parentHash := parent.TxHash()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- w.AbandonTransaction(&parentHash)
+	}()
+
+	var n *TransactionNotifications
+	select {
+	case n = <-client.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification of the abandoned transaction")
+	}
+
+	// The removal is committed before clients are notified.
+	if hashes := unminedTxHashes(t, w); len(hashes) != 0 {
+		t.Fatalf("notified before the removal of %v was committed",
+			hashes)
+	}
+	if len(n.UnminedTransactionHashes) != 0 {
+		t.Fatalf("expected no unmined transactions, got %v",
+			n.UnminedTransactionHashes)
+	}
+	balances := make(map[uint32]bool)
+	for _, b := range n.NewBalances {
+		balances[b.Account] = true
+	}
+	if !balances[waddrmgr.DefaultAccountNum] || !balances[account] {
+		t.Fatalf("expected balances of accounts %d and %d, got %v",
+			waddrmgr.DefaultAccountNum, account, n.NewBalances)
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("unable to abandon transaction: %v", err)
+	}
+	if err := w.AbandonTransaction(&parentHash); err == nil {
+		t.Fatal("expected error abandoning a removed transaction")
+	}
+}
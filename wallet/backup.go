@@ -0,0 +1,238 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcwallet/internal/legacy/rename"
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+)
+
+const (
+	// backupPrefix begins the file names of the backups of the wallet
+	// database, followed by the UTC time of the backup.
+	backupPrefix = "wallet-"
+
+	// backupTimeFormat is the format of the time in backup file names,
+	// which sorts backups by their time.
+	backupTimeFormat = "20060102-150405"
+
+	// backupExt and encryptedBackupExt end the file names of unencrypted
+	// and encrypted backups.
+	backupExt          = ".db"
+	encryptedBackupExt = ".db.enc"
+)
+
+// ErrMalformedBackup is returned when decrypting a backup which was not
+// encrypted by the wallet.
+var ErrMalformedBackup = errors.New("malformed encrypted backup")
+
+// BackupConfig configures the backups of the wallet database created by
+// StartBackups.
+type BackupConfig struct {
+	// Dir is the directory the backups are written to.
+	Dir string
+
+	// Interval is the time between two backups.
+	Interval time.Duration
+
+	// Keep is the number of most recent backups kept in Dir, where older
+	// backups are removed.  Zero keeps all backups.
+	Keep int
+
+	// Passphrase encrypts the backups when set.  Encrypted backups are
+	// decrypted with DecryptBackup.
+	Passphrase []byte
+}
+
+// StartBackups writes a copy of the wallet database to the backup directory
+// every interval, as configured by cfg, until the wallet is stopped.  Each copy
+// is taken in a single read transaction, so backups are consistent while the
+// wallet keeps running.  It must be called after the wallet is started.
+func (w *Wallet) StartBackups(cfg BackupConfig) error {
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return err
+	}
+
+	// The key encrypting backups is derived once, as deriving it is slow
+	// by design.
+	var key *snacl.SecretKey
+	if len(cfg.Passphrase) != 0 {
+		var err error
+		key, err = snacl.NewSecretKey(&cfg.Passphrase,
+			waddrmgr.DefaultScryptOptions.N,
+			waddrmgr.DefaultScryptOptions.R,
+			waddrmgr.DefaultScryptOptions.P)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.wg.Add(1)
+	go w.backupHandler(cfg, key)
+	return nil
+}
+
+func (w *Wallet) backupHandler(cfg BackupConfig, key *snacl.SecretKey) {
+	defer w.wg.Done()
+	if key != nil {
+		defer key.Zero()
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	quit := w.quitChan()
+	for {
+		select {
+		case <-ticker.C:
+			path, err := w.backup(cfg, key)
+			if err != nil {
+				log.Errorf("Unable to back up wallet database: %v",
+					err)
+				continue
+			}
+			log.Infof("Backed up wallet database to %s", path)
+
+			if err := removeOldBackups(cfg.Dir, cfg.Keep); err != nil {
+				log.Errorf("Unable to remove old backups: %v", err)
+			}
+
+		case <-quit:
+			return
+		}
+	}
+}
+
+// backup writes a backup of the wallet database to the backup directory and
+// returns its path.  The backup is written to a temporary file which is only
+// renamed to the backup once it is complete.
+func (w *Wallet) backup(cfg BackupConfig, key *snacl.SecretKey) (string, error) {
+	name := backupPrefix + time.Now().UTC().Format(backupTimeFormat)
+	if key == nil {
+		name += backupExt
+	} else {
+		name += encryptedBackupExt
+	}
+	path := filepath.Join(cfg.Dir, name)
+
+	f, err := ioutil.TempFile(cfg.Dir, name+".tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := f.Name()
+	err = w.writeBackup(f, key)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = rename.Atomic(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return path, nil
+}
+
+// writeBackup writes a copy of the wallet database to f, encrypted with key if
+// it is not nil.
+func (w *Wallet) writeBackup(f io.Writer, key *snacl.SecretKey) error {
+	if key == nil {
+		return w.db.Copy(f)
+	}
+
+	var buf bytes.Buffer
+	if err := w.db.Copy(&buf); err != nil {
+		return err
+	}
+	sealed, err := key.Encrypt(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(key.Marshal()); err != nil {
+		return err
+	}
+	_, err = f.Write(sealed)
+	return err
+}
+
+// removeOldBackups removes all but the keep most recent backups of dir.
+// Nothing is removed if keep is zero.
+func removeOldBackups(dir string, keep int) error {
+	if keep == 0 {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, fi := range files {
+		name := fi.Name()
+		if !strings.HasPrefix(name, backupPrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, backupExt) ||
+			strings.HasSuffix(name, encryptedBackupExt) {
+
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+
+	sort.Strings(backups)
+	for _, name := range backups[:len(backups)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecryptBackup decrypts the encrypted backup read from r with passphrase and
+// writes the copy of the wallet database to w.  snacl.ErrInvalidPassword is
+// returned if the passphrase is wrong.
+func DecryptBackup(w io.Writer, r io.Reader, passphrase []byte) error {
+	backup, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(backup) < secretKeyParamsSize {
+		return ErrMalformedBackup
+	}
+
+	var key snacl.SecretKey
+	if err := key.Unmarshal(backup[:secretKeyParamsSize]); err != nil {
+		return ErrMalformedBackup
+	}
+	if err := key.DeriveKey(&passphrase); err != nil {
+		return err
+	}
+	defer key.Zero()
+
+	db, err := key.Decrypt(backup[secretKeyParamsSize:])
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(db)
+	return err
+}
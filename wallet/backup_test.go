@@ -0,0 +1,129 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+)
+
+// TestEncryptedBackup ensures that encrypted backups of the wallet database
+// are decrypted into a copy of the database.
+func TestEncryptedBackup(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := walletdb.Create("bdb", filepath.Join(dir, "wallet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns, err := tx.CreateTopLevelBucket([]byte("ns"))
+		if err != nil {
+			return err
+		}
+		return ns.Put([]byte("key"), []byte("value"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use cheap scrypt parameters to keep the test fast.
+	pass := []byte("backup passphrase")
+	key, err := snacl.NewSecretKey(&pass, 16, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Wallet{db: db}
+	backupDir := filepath.Join(dir, "backups")
+	if err := os.Mkdir(backupDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path, err := w.backup(BackupConfig{Dir: backupDir}, key)
+	if err != nil {
+		t.Fatalf("unable to back up database: %v", err)
+	}
+	backup, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = DecryptBackup(&out, bytes.NewReader(backup), []byte("wrong"))
+	if err != snacl.ErrInvalidPassword {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	if err := DecryptBackup(&out, bytes.NewReader(backup), pass); err != nil {
+		t.Fatalf("unable to decrypt backup: %v", err)
+	}
+
+	restoredPath := filepath.Join(dir, "restored.db")
+	if err := ioutil.WriteFile(restoredPath, out.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := walletdb.Open("bdb", restoredPath)
+	if err != nil {
+		t.Fatalf("unable to open decrypted backup: %v", err)
+	}
+	defer restored.Close()
+	err = walletdb.View(restored, func(tx walletdb.ReadTx) error {
+		v := tx.ReadBucket([]byte("ns")).Get([]byte("key"))
+		if !bytes.Equal(v, []byte("value")) {
+			t.Fatalf("unexpected value %q in decrypted backup", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRemoveOldBackups ensures that only the most recent backups are kept.
+func TestRemoveOldBackups(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"wallet-20180101-000000.db",
+		"wallet-20180102-000000.db.enc",
+		"wallet-20180103-000000.db",
+		"wallet-20180104-000000.db.tmp123",
+		"other.db",
+	}
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := removeOldBackups(dir, 2); err != nil {
+		t.Fatalf("unable to remove old backups: %v", err)
+	}
+	for i, name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if removed := os.IsNotExist(err); removed != (i == 0) {
+			t.Fatalf("unexpected removal of %s: %v", name, removed)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// ErrUnknownPayment describes an error where a queued payment could not be
+// found, either because it was never queued, it was canceled, or it has
+// already been sent.
+var ErrUnknownPayment = errors.New("queued payment not found")
+
+// Keys of the batch namespace.  The namespace holds the ID of the next
+// queued payment and a bucket of queued payments keyed by their big endian
+// encoded ID.
+var (
+	nextPaymentIDKey  = []byte("nextpaymentid")
+	queuedPaymentsKey = []byte("payments")
+)
+
+// QueuedPayment is a payment which is waiting to be batched with other queued
+// payments of the same account into a single transaction.
+type QueuedPayment struct {
+	ID      uint64
+	Account uint32
+	Output  *wire.TxOut
+	Queued  time.Time
+}
+
+// The serialized value of a queued payment is:
+//
+//	[0:4]   Account (4 bytes)
+//	[4:12]  Time queued, unix seconds (8 bytes)
+//	[12:20] Output value (8 bytes)
+//	[20:]   Output script
+func serializeQueuedPayment(p *QueuedPayment) []byte {
+	v := make([]byte, 20+len(p.Output.PkScript))
+	binary.LittleEndian.PutUint32(v, p.Account)
+	binary.LittleEndian.PutUint64(v[4:], uint64(p.Queued.Unix()))
+	binary.LittleEndian.PutUint64(v[12:], uint64(p.Output.Value))
+	copy(v[20:], p.Output.PkScript)
+	return v
+}
+
+func deserializeQueuedPayment(k, v []byte) (*QueuedPayment, error) {
+	if len(k) != 8 || len(v) < 20 {
+		return nil, fmt.Errorf("queued payment %x: short read", k)
+	}
+	pkScript := make([]byte, len(v)-20)
+	copy(pkScript, v[20:])
+	return &QueuedPayment{
+		ID:      binary.BigEndian.Uint64(k),
+		Account: binary.LittleEndian.Uint32(v),
+		Output: wire.NewTxOut(
+			int64(binary.LittleEndian.Uint64(v[12:])), pkScript,
+		),
+		Queued: time.Unix(int64(binary.LittleEndian.Uint64(v[4:])), 0),
+	}, nil
+}
+
+func paymentIDKey(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
+
+func putQueuedPayment(ns walletdb.ReadWriteBucket, p *QueuedPayment) error {
+	payments, err := ns.CreateBucketIfNotExists(queuedPaymentsKey)
+	if err != nil {
+		return err
+	}
+	return payments.Put(paymentIDKey(p.ID), serializeQueuedPayment(p))
+}
+
+func forEachQueuedPayment(ns walletdb.ReadBucket,
+	f func(*QueuedPayment) error) error {
+
+	payments := ns.NestedReadBucket(queuedPaymentsKey)
+	if payments == nil {
+		return nil
+	}
+	return payments.ForEach(func(k, v []byte) error {
+		p, err := deserializeQueuedPayment(k, v)
+		if err != nil {
+			return err
+		}
+		return f(p)
+	})
+}
+
+// nextPaymentID returns the ID of a new queued payment and increments the
+// stored ID.
+func nextPaymentID(ns walletdb.ReadWriteBucket) (uint64, error) {
+	var id uint64
+	if v := ns.Get(nextPaymentIDKey); len(v) == 8 {
+		id = binary.BigEndian.Uint64(v)
+	}
+	if err := ns.Put(nextPaymentIDKey, paymentIDKey(id+1)); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// QueuePayment queues output to be paid from account the next time queued
+// payments are flushed, either explicitly with FlushPayments or periodically
+// by StartPaymentBatcher.  Queued payments are persisted in the wallet
+// database until they are sent or canceled.  The ID of the queued payment is
+// returned.
+func (w *Wallet) QueuePayment(output *wire.TxOut, account uint32) (uint64, error) {
+	err := txrules.CheckOutput(output, txrules.DefaultRelayFeePerKb)
+	if err != nil {
+		return 0, err
+	}
+
+	w.batchMtx.Lock()
+	defer w.batchMtx.Unlock()
+
+	var id uint64
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(batchNamespaceKey)
+		var err error
+		id, err = nextPaymentID(ns)
+		if err != nil {
+			return err
+		}
+		return putQueuedPayment(ns, &QueuedPayment{
+			ID:      id,
+			Account: account,
+			Output:  output,
+			Queued:  time.Now(),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// QueuedPayments returns every payment which is queued but not yet sent, in
+// the order they were queued.
+func (w *Wallet) QueuedPayments() ([]QueuedPayment, error) {
+	var queued []QueuedPayment
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(batchNamespaceKey)
+		return forEachQueuedPayment(ns, func(p *QueuedPayment) error {
+			queued = append(queued, *p)
+			return nil
+		})
+	})
+	return queued, err
+}
+
+// CancelPayment removes a queued payment so it is not sent.  If no payment
+// with the ID is queued, ErrUnknownPayment is returned.
+func (w *Wallet) CancelPayment(id uint64) error {
+	w.batchMtx.Lock()
+	defer w.batchMtx.Unlock()
+
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(batchNamespaceKey)
+		payments := ns.NestedReadWriteBucket(queuedPaymentsKey)
+		k := paymentIDKey(id)
+		if payments == nil || payments.Get(k) == nil {
+			return ErrUnknownPayment
+		}
+		return payments.Delete(k)
+	})
+}
+
+// FlushPayments sends every queued payment.  The payments of each account are
+// batched into a single transaction with at most one change output, funded by
+// outputs with at least minconf confirmations using the default coin
+// selection strategy, and paying the fee rate feeSatPerKb.  Payments are
+// removed from the queue once their transaction is published, and remain
+// queued if it can not be published.  The hashes of the published
+// transactions are returned.  If the payments of an account can not be sent,
+// the payments of the remaining accounts are still attempted and the first
+// error is returned.
+func (w *Wallet) FlushPayments(minconf int32,
+	feeSatPerKb btcutil.Amount) ([]*chainhash.Hash, error) {
+
+	w.batchMtx.Lock()
+	defer w.batchMtx.Unlock()
+
+	queued, err := w.QueuedPayments()
+	if err != nil {
+		return nil, err
+	}
+	byAccount := make(map[uint32][]QueuedPayment)
+	var accounts []uint32
+	for _, p := range queued {
+		if _, ok := byAccount[p.Account]; !ok {
+			accounts = append(accounts, p.Account)
+		}
+		byAccount[p.Account] = append(byAccount[p.Account], p)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i] < accounts[j]
+	})
+
+	var (
+		hashes   []*chainhash.Hash
+		firstErr error
+	)
+	for _, account := range accounts {
+		payments := byAccount[account]
+		hash, err := w.sendQueuedPayments(account, payments, minconf,
+			feeSatPerKb)
+		if err != nil {
+			log.Errorf("Unable to send %d queued payments of account "+
+				"%d: %v", len(payments), account, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		log.Infof("Sent %d queued payments of account %d in "+
+			"transaction %v", len(payments), account, hash)
+		hashes = append(hashes, hash)
+	}
+	return hashes, firstErr
+}
+
+// sendQueuedPayments sends the queued payments of account in a single
+// transaction.  The payments are dequeued once the transaction is published.
+// This must be called with the batch mutex held.
+func (w *Wallet) sendQueuedPayments(account uint32, payments []QueuedPayment,
+	minconf int32, feeSatPerKb btcutil.Amount) (*chainhash.Hash, error) {
+
+	outputs := make([]*wire.TxOut, 0, len(payments))
+	for i := range payments {
+		outputs = append(outputs, payments[i].Output)
+	}
+	createdTx, err := w.CreateSimpleTx(account, outputs, minconf,
+		feeSatPerKb, w.DefaultCoinSelectionStrategy())
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := w.publishTransaction(createdTx.Tx)
+	if err != nil {
+		// The payments remain queued and are sent again by the next
+		// flush, so a transaction which is kept for rebroadcasting must
+		// not be sent as well.
+		txRec, recErr := wtxmgr.NewTxRecordFromMsgTx(
+			createdTx.Tx, time.Now(),
+		)
+		if recErr != nil {
+			return nil, err
+		}
+		dbErr := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			txmgrNs := dbTx.ReadWriteBucket(wtxmgrNamespaceKey)
+			return w.TxStore.RemoveUnminedTx(txmgrNs, txRec)
+		})
+		if dbErr != nil {
+			return nil, fmt.Errorf("unable to publish transaction "+
+				"%v: %v, unable to remove it: %v",
+				createdTx.Tx.TxHash(), err, dbErr)
+		}
+		return nil, err
+	}
+
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(batchNamespaceKey)
+		bucket := ns.NestedReadWriteBucket(queuedPaymentsKey)
+		for i := range payments {
+			err := bucket.Delete(paymentIDKey(payments[i].ID))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("published transaction %v but unable "+
+			"to dequeue its payments: %v", hash, err)
+	}
+	return hash, nil
+}
+
+// StartPaymentBatcher flushes queued payments every interval, as described by
+// FlushPayments, until the wallet is stopped.  It must be called after the
+// wallet is started.
+func (w *Wallet) StartPaymentBatcher(interval time.Duration, minconf int32,
+	feeSatPerKb btcutil.Amount) {
+
+	w.wg.Add(1)
+	go w.paymentBatcher(interval, minconf, feeSatPerKb)
+}
+
+func (w *Wallet) paymentBatcher(interval time.Duration, minconf int32,
+	feeSatPerKb btcutil.Amount) {
+
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	quit := w.quitChan()
+	for {
+		select {
+		case <-ticker.C:
+			queued, err := w.QueuedPayments()
+			if err != nil {
+				log.Errorf("Unable to fetch queued payments: %v", err)
+				continue
+			}
+			if len(queued) == 0 {
+				continue
+			}
+			// Errors are logged by FlushPayments.
+			w.FlushPayments(minconf, feeSatPerKb)
+
+		case <-quit:
+			return
+		}
+	}
+}
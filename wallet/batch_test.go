@@ -0,0 +1,40 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestQueuedPaymentSerialization(t *testing.T) {
+	p := &QueuedPayment{
+		ID:      1 << 40,
+		Account: 3,
+		Output:  wire.NewTxOut(1e6, p2wpkhScript()),
+		Queued:  time.Unix(1530000000, 0),
+	}
+
+	got, err := deserializeQueuedPayment(
+		paymentIDKey(p.ID), serializeQueuedPayment(p),
+	)
+	if err != nil {
+		t.Fatalf("unable to deserialize queued payment: %v", err)
+	}
+	if got.ID != p.ID || got.Account != p.Account ||
+		!got.Queued.Equal(p.Queued) ||
+		got.Output.Value != p.Output.Value ||
+		!bytes.Equal(got.Output.PkScript, p.Output.PkScript) {
+
+		t.Fatalf("expected queued payment %v, got %v", p, got)
+	}
+
+	if _, err := deserializeQueuedPayment(paymentIDKey(p.ID), nil); err == nil {
+		t.Fatal("expected error deserializing short value")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// bip322Tag is the tag of the tagged hash a message is committed to by a
+// BIP0322 signature.
+const bip322Tag = "BIP0322-signed-message"
+
+// maxBIP322WitnessItemSize is the maximum size of a witness item of a
+// serialized BIP0322 signature.
+const maxBIP322WitnessItemSize = txscript.MaxScriptSize
+
+// bip322MessageHash returns the BIP0340 tagged hash of message with the
+// BIP0322 tag.
+func bip322MessageHash(message []byte) chainhash.Hash {
+	tagHash := sha256.Sum256([]byte(bip322Tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(message)
+	var hash chainhash.Hash
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
+
+// bip322ToSpend returns the virtual transaction committing to the message
+// hash, whose only output pays to pkScript.
+func bip322ToSpend(pkScript []byte, messageHash chainhash.Hash) *wire.MsgTx {
+	sigScript, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(messageHash[:]).Script()
+	prevOut := wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex)
+	txIn := wire.NewTxIn(prevOut, sigScript, nil)
+	txIn.Sequence = 0
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+	return tx
+}
+
+// bip322ToSign returns the virtual transaction spending the output of
+// toSpend, whose witness is the BIP0322 signature.
+func bip322ToSign(toSpend *wire.MsgTx, witness wire.TxWitness) *wire.MsgTx {
+	toSpendHash := toSpend.TxHash()
+	txIn := wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, witness)
+	txIn.Sequence = 0
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return tx
+}
+
+// bip322PkScript returns the output script of an address supported by BIP0322
+// message signing.  Only P2WPKH addresses are supported.
+func bip322PkScript(addr btcutil.Address) ([]byte, error) {
+	if _, ok := addr.(*btcutil.AddressWitnessPubKeyHash); !ok {
+		return nil, fmt.Errorf("BIP0322 signatures are not supported "+
+			"for address %v", addr)
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// signBIP322 returns the serialized witness of a BIP0322 simple signature of
+// message by the P2WPKH output script pkScript of privKey.
+func signBIP322(privKey *btcec.PrivateKey, pkScript []byte,
+	message string) ([]byte, error) {
+
+	toSpend := bip322ToSpend(pkScript, bip322MessageHash([]byte(message)))
+	toSign := bip322ToSign(toSpend, nil)
+	witness, err := txscript.WitnessSignature(toSign,
+		txscript.NewTxSigHashes(toSign), 0, 0, pkScript,
+		txscript.SigHashAll, privKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = wire.WriteVarInt(&buf, 0, uint64(len(witness)))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// SignMessageBIP322 returns a BIP0322 simple signature of message by the key
+// of the wallet address addr.  The signature is the serialized witness
+// spending the BIP0322 virtual transaction.  Only P2WPKH addresses are
+// supported; messages of P2PKH addresses are signed using the legacy
+// signmessage format instead.  The wallet must be unlocked.
+func (w *Wallet) SignMessageBIP322(addr btcutil.Address, message string) ([]byte, error) {
+	pkScript, err := bip322PkScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := w.PrivKeyForAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return signBIP322(privKey, pkScript, message)
+}
+
+// VerifyMessageBIP322 returns whether sig is a valid BIP0322 simple signature
+// of message by addr, as created by SignMessageBIP322.  Only P2WPKH addresses
+// are supported.  An error is returned if the signature is malformed.
+func VerifyMessageBIP322(addr btcutil.Address, message string,
+	sig []byte) (bool, error) {
+
+	pkScript, err := bip322PkScript(addr)
+	if err != nil {
+		return false, err
+	}
+
+	r := bytes.NewReader(sig)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return false, err
+	}
+	if count > uint64(len(sig)) {
+		return false, fmt.Errorf("signature has too many witness items")
+	}
+	witness := make(wire.TxWitness, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(r, 0, maxBIP322WitnessItemSize,
+			"witness item")
+		if err != nil {
+			return false, err
+		}
+		witness = append(witness, item)
+	}
+	if r.Len() != 0 {
+		return false, fmt.Errorf("signature has %d trailing bytes",
+			r.Len())
+	}
+
+	toSpend := bip322ToSpend(pkScript, bip322MessageHash([]byte(message)))
+	toSign := bip322ToSign(toSpend, witness)
+	vm, err := txscript.NewEngine(pkScript, toSign, 0,
+		txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(toSign),
+		0)
+	if err != nil {
+		return false, err
+	}
+	return vm.Execute() == nil, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+// TestBIP322VirtualTransactions checks the message hashes and virtual
+// transactions against the test vectors of BIP0322.
+func TestBIP322VirtualTransactions(t *testing.T) {
+	addr, err := btcutil.DecodeAddress(
+		"bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l",
+		&chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := bip322PkScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message     string
+		messageHash string
+		toSpend     string
+		toSign      string
+	}{
+		{
+			message:     "",
+			messageHash: "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+			toSpend:     "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+			toSign:      "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6",
+		},
+		{
+			message:     "Hello World",
+			messageHash: "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+			toSpend:     "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+			toSign:      "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf",
+		},
+	}
+	for _, test := range tests {
+		// The tagged hash is not a transaction hash, so it is
+		// compared in its natural byte order.
+		messageHash := bip322MessageHash([]byte(test.message))
+		if got := hex.EncodeToString(messageHash[:]); got != test.messageHash {
+			t.Errorf("message %q: expected hash %v, got %v",
+				test.message, test.messageHash, got)
+		}
+
+		toSpend := bip322ToSpend(pkScript, messageHash)
+		if got := toSpend.TxHash().String(); got != test.toSpend {
+			t.Errorf("message %q: expected to_spend %v, got %v",
+				test.message, test.toSpend, got)
+		}
+		toSign := bip322ToSign(toSpend, nil)
+		if got := toSign.TxHash().String(); got != test.toSign {
+			t.Errorf("message %q: expected to_sign %v, got %v",
+				test.message, test.toSign, got)
+		}
+	}
+}
+
+func TestBIP322SignVerify(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(privKey.PubKey().SerializeCompressed()),
+		&chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := signBIP322(privKey, pkScript, "Hello World")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := VerifyMessageBIP322(addr, "Hello World", sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Fatal("expected valid signature")
+	}
+
+	valid, err = VerifyMessageBIP322(addr, "Hello World!", sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid {
+		t.Fatal("expected invalid signature of a different message")
+	}
+
+	if _, err := VerifyMessageBIP322(addr, "Hello World", sig[1:]); err == nil {
+		t.Fatal("expected error verifying malformed signature")
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package bip39 implements the mnemonic sentences of BIP0039 using the English
+// wordlist, so that wallets created by other software can be restored from
+// their mnemonic and optional passphrase.
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/golangcrypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinEntropyBits and MaxEntropyBits are the bounds of the entropy
+	// encoded by a mnemonic.  The entropy must also be a multiple of
+	// EntropyBitsStep bits.
+	MinEntropyBits  = 128
+	MaxEntropyBits  = 256
+	EntropyBitsStep = 32
+
+	// RecommendedEntropyBits is the entropy of the 24 word mnemonics
+	// created for new wallets.
+	RecommendedEntropyBits = 256
+
+	// SeedLen is the length in bytes of the seed returned by NewSeed.
+	SeedLen = 64
+
+	seedIterations = 2048
+	bitsPerWord    = 11
+)
+
+var (
+	// ErrInvalidEntropyLength describes an error where the length of the
+	// entropy of a mnemonic is unsupported.
+	ErrInvalidEntropyLength = fmt.Errorf("entropy must be between %d and "+
+		"%d bits in multiples of %d bits", MinEntropyBits,
+		MaxEntropyBits, EntropyBitsStep)
+
+	// ErrInvalidChecksum describes an error where the checksum of a
+	// mnemonic does not match its entropy, which is usually caused by a
+	// mistyped, missing or reordered word.
+	ErrInvalidChecksum = errors.New("invalid mnemonic checksum")
+)
+
+var (
+	wordList  []string
+	wordIndex map[string]int
+)
+
+func init() {
+	wordList = strings.Split(englishWords, "\n")
+	wordIndex = make(map[string]int, len(wordList))
+	for i, word := range wordList {
+		wordIndex[word] = i
+	}
+}
+
+func checkEntropyBits(bits int) error {
+	if bits < MinEntropyBits || bits > MaxEntropyBits ||
+		bits%EntropyBitsStep != 0 {
+
+		return ErrInvalidEntropyLength
+	}
+	return nil
+}
+
+// NewEntropy returns bits of random entropy for a new mnemonic.
+func NewEntropy(bits int) ([]byte, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic returns the mnemonic sentence encoding entropy and its
+// checksum.
+func NewMnemonic(entropy []byte) (string, error) {
+	entropyBits := len(entropy) * 8
+	if err := checkEntropyBits(entropyBits); err != nil {
+		return "", err
+	}
+	checksumBits := uint(entropyBits / 32)
+
+	// Append the leading bits of the hash of the entropy and split the
+	// result into the word indexes, most significant first.
+	hash := sha256.Sum256(entropy)
+	n := new(big.Int).SetBytes(entropy)
+	n.Lsh(n, checksumBits)
+	n.Or(n, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	numWords := (entropyBits + int(checksumBits)) / bitsPerWord
+	words := make([]string, numWords)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	index := new(big.Int)
+	for i := numWords - 1; i >= 0; i-- {
+		index.And(n, mask)
+		words[i] = wordList[index.Int64()]
+		n.Rsh(n, bitsPerWord)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// normalizeMnemonic returns the words of a mnemonic in lowercase and in
+// Unicode NFKD form, as required by BIP0039.  Any amount of whitespace may
+// separate the words.
+func normalizeMnemonic(mnemonic string) []string {
+	return strings.Fields(norm.NFKD.String(strings.ToLower(mnemonic)))
+}
+
+// EntropyFromMnemonic returns the entropy encoded by a mnemonic sentence.  An
+// error is returned if the mnemonic contains a word not in the wordlist, has an
+// unsupported number of words or an invalid checksum.
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	words := normalizeMnemonic(mnemonic)
+	totalBits := len(words) * bitsPerWord
+	checksumBits := uint(totalBits / 33)
+	entropyBits := totalBits - int(checksumBits)
+	if totalBits%33 != 0 || checkEntropyBits(entropyBits) != nil {
+		return nil, fmt.Errorf("invalid number of mnemonic words %d",
+			len(words))
+	}
+
+	n := new(big.Int)
+	for _, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word %q", word)
+		}
+		n.Lsh(n, bitsPerWord)
+		n.Or(n, big.NewInt(int64(index)))
+	}
+
+	checksum := byte(new(big.Int).And(n,
+		big.NewInt(1<<checksumBits-1)).Int64())
+	n.Rsh(n, checksumBits)
+
+	// Left pad the entropy, which may have leading zero bytes.
+	entropy := make([]byte, entropyBits/8)
+	b := n.Bytes()
+	copy(entropy[len(entropy)-len(b):], b)
+
+	hash := sha256.Sum256(entropy)
+	if hash[0]>>(8-checksumBits) != checksum {
+		return nil, ErrInvalidChecksum
+	}
+	return entropy, nil
+}
+
+// IsValid returns whether a mnemonic sentence is valid.
+func IsValid(mnemonic string) bool {
+	_, err := EntropyFromMnemonic(mnemonic)
+	return err == nil
+}
+
+// NewSeed validates a mnemonic sentence and returns the SeedLen byte seed
+// derived from it and the optional passphrase, which is used as the seed of
+// the HD wallet.  Wallets created with a passphrase can only be restored with
+// the same passphrase, as a different passphrase derives an unrelated seed.
+// The mnemonic and passphrase are converted to Unicode NFKD form before the
+// seed is derived, so passphrases with composed and decomposed characters
+// derive the same seed as other BIP0039 implementations.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := EntropyFromMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(normalizeMnemonic(mnemonic), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(normalized), []byte(salt), seedIterations,
+		SeedLen, sha512.New), nil
+}
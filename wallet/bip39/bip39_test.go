@@ -0,0 +1,156 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip39_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/wallet/bip39"
+)
+
+// TestVectors checks the mnemonics and seeds of a subset of the BIP0039 test
+// vectors, which all use the passphrase TREZOR.
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+			seed:     "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			entropy:  "80808080808080808080808080808080",
+			mnemonic: "letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+			seed:     "d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+		},
+		{
+			entropy:  "ffffffffffffffffffffffffffffffff",
+			mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			seed:     "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+		},
+		{
+			entropy:  "000000000000000000000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent",
+			seed:     "035895f2f481b1b0f01fcf8c289c794660b289981a78f8106447707fdd9666ca06da5a9a565181599b79f53b844d8a71dd9f439c52a3d7b3e8a79c906ac845fa",
+		},
+		{
+			entropy:  "9e885d952ad362caeb4efe34a8e91bd2",
+			mnemonic: "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+			seed:     "274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+		},
+		{
+			entropy:  "f585c11aec520db57dd353c69554b21a89b20fb0650966fa0a9d6f74fd989d8f",
+			mnemonic: "void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold",
+			seed:     "01f5bced59dec48e362f2c45b5de68b9fd6c92c6634f44d6d40aab69056506f0e35524a518034ddc1192e1dacd32c1ed3eaa3c3b131c88ed8e7e54c49a5d0998",
+		},
+	}
+	for _, test := range tests {
+		entropy, _ := hex.DecodeString(test.entropy)
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != test.mnemonic {
+			t.Errorf("entropy %v: expected mnemonic %q, got %q",
+				test.entropy, test.mnemonic, mnemonic)
+		}
+
+		decoded, err := bip39.EntropyFromMnemonic(test.mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, entropy) {
+			t.Errorf("mnemonic %q: expected entropy %v, got %x",
+				test.mnemonic, test.entropy, decoded)
+		}
+
+		seed, err := bip39.NewSeed(test.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(seed); got != test.seed {
+			t.Errorf("mnemonic %q: expected seed %v, got %v",
+				test.mnemonic, test.seed, got)
+		}
+	}
+}
+
+func TestInvalidMnemonics(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+	}{
+		{
+			name:     "bad checksum",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		},
+		{
+			name:     "unknown word",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon btcwallet",
+		},
+		{
+			name:     "too few words",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		},
+		{
+			name:     "empty",
+			mnemonic: "",
+		},
+	}
+	for _, test := range tests {
+		if bip39.IsValid(test.mnemonic) {
+			t.Errorf("%v: expected mnemonic to be invalid", test.name)
+		}
+		if _, err := bip39.NewSeed(test.mnemonic, ""); err == nil {
+			t.Errorf("%v: expected error deriving seed", test.name)
+		}
+	}
+
+	// Case and extra whitespace do not change the seed.
+	seed, err := bip39.NewSeed("abandon abandon abandon abandon abandon "+
+		"abandon abandon abandon abandon abandon abandon about", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := bip39.NewSeed(" Abandon abandon abandon abandon abandon "+
+		"abandon abandon abandon abandon abandon abandon  ABOUT\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(seed, other) {
+		t.Fatal("expected normalized mnemonics to derive the same seed")
+	}
+}
+
+// TestNormalizedPassphrase ensures that passphrases are converted to NFKD form,
+// so composed and decomposed forms of the same characters derive the same
+// seed.
+func TestNormalizedPassphrase(t *testing.T) {
+	const mnemonic = "abandon abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon about"
+
+	composed, err := bip39.NewSeed(mnemonic, "caf\u00e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomposed, err := bip39.NewSeed(mnemonic, "cafe\u0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(composed, decomposed) {
+		t.Fatal("expected composed and decomposed passphrases to " +
+			"derive the same seed")
+	}
+}
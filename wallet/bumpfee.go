@@ -0,0 +1,221 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	h "github.com/btcsuite/btcwallet/internal/helpers"
+	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// replaceableSequence is the largest input sequence number which signals that
+// a transaction may be replaced, as defined by BIP0125.
+const replaceableSequence = wire.MaxTxInSequenceNum - 2
+
+// signalReplaceable sets the sequence number of every input of tx to signal
+// that it may be replaced.  This must be done before signing.
+func signalReplaceable(tx *wire.MsgTx) {
+	for _, txIn := range tx.TxIn {
+		txIn.Sequence = replaceableSequence
+	}
+}
+
+// signalsReplaceable returns whether any input of tx signals that it may be
+// replaced.
+func signalsReplaceable(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence <= replaceableSequence {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpFee replaces the unmined transaction txHash with a transaction paying
+// the fee rate feeSatPerKb and publishes it.  The replacement spends the same
+// inputs and pays the same outputs as the original, with the increased fee
+// deducted from its change output.  The original must signal replaceability,
+// for example by being created with WithReplaceable, and every input must be
+// controlled by the wallet.  The original is removed from the transaction
+// store, so only the change of the replacement is counted, and is recorded as
+// replaced.  The wallet must be unlocked.  The hash of the replacement is
+// returned.
+func (w *Wallet) BumpFee(txHash chainhash.Hash,
+	feeSatPerKb btcutil.Amount) (*chainhash.Hash, error) {
+
+	heldUnlock, err := w.holdUnlock()
+	if err != nil {
+		return nil, err
+	}
+	defer heldUnlock.release()
+
+	var tx *txauthor.AuthoredTx
+	err = walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		var err error
+		tx, err = w.replacementTx(dbtx, &txHash, feeSatPerKb)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateMsgTx(tx.Tx, tx.PrevScripts, tx.PrevInputValues)
+	if err != nil {
+		return nil, err
+	}
+
+	replacementHash, err := w.publishTransaction(tx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.InsertReplacement(
+			txmgrNs, &txHash, replacementHash,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Replaced transaction %v with %v", txHash, replacementHash)
+	return replacementHash, nil
+}
+
+// replacementTx creates a signed transaction replacing the unmined transaction
+// txHash which pays the fee rate feeSatPerKb.  The lock time of the original
+// is kept.
+func (w *Wallet) replacementTx(dbtx walletdb.ReadTx, txHash *chainhash.Hash,
+	feeSatPerKb btcutil.Amount) (*txauthor.AuthoredTx, error) {
+
+	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+	replacement, err := w.TxStore.Replacement(txmgrNs, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if replacement != nil {
+		return nil, fmt.Errorf("transaction %v is already replaced "+
+			"by %v", txHash, replacement)
+	}
+	details, err := w.TxStore.TxDetails(txmgrNs, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("transaction %v not found", txHash)
+	}
+	if details.Block.Height != -1 {
+		return nil, fmt.Errorf("transaction %v is already mined",
+			txHash)
+	}
+
+	orig := &details.MsgTx
+	if !signalsReplaceable(orig) {
+		return nil, fmt.Errorf("transaction %v does not signal "+
+			"replaceability", txHash)
+	}
+
+	// Every input must spend a wallet credit so its value and previous
+	// output script are known, and so it can be signed again.
+	prevScripts, err := w.TxStore.PreviousPkScripts(
+		txmgrNs, &details.TxRecord, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(details.Debits) != len(orig.TxIn) ||
+		len(prevScripts) != len(orig.TxIn) {
+
+		return nil, fmt.Errorf("transaction %v spends outputs not "+
+			"controlled by the wallet", txHash)
+	}
+	inputValues := make([]btcutil.Amount, len(orig.TxIn))
+	for _, debit := range details.Debits {
+		inputValues[debit.Index] = debit.Amount
+	}
+
+	// The increased fee is paid from the change output, so the original
+	// must have one.
+	changeIndex := -1
+	for _, credit := range details.Credits {
+		if credit.Change {
+			changeIndex = int(credit.Index)
+			break
+		}
+	}
+	if changeIndex == -1 {
+		return nil, fmt.Errorf("transaction %v has no change output "+
+			"to pay the increased fee", txHash)
+	}
+	changeScript := orig.TxOut[changeIndex].PkScript
+
+	outputs := make([]*wire.TxOut, 0, len(orig.TxOut)-1)
+	for i, txOut := range orig.TxOut {
+		if i == changeIndex {
+			continue
+		}
+		outputs = append(outputs, wire.NewTxOut(txOut.Value, txOut.PkScript))
+	}
+
+	var totalInput btcutil.Amount
+	inputs := make([]*wire.TxIn, 0, len(orig.TxIn))
+	for i, txIn := range orig.TxIn {
+		inputs = append(inputs, wire.NewTxIn(&txIn.PreviousOutPoint, nil, nil))
+		totalInput += inputValues[i]
+	}
+	inputSource := func(btcutil.Amount) (btcutil.Amount, []*wire.TxIn,
+		[]btcutil.Amount, [][]byte, error) {
+
+		return totalInput, inputs, inputValues, prevScripts, nil
+	}
+	changeSource := func() ([]byte, error) {
+		return changeScript, nil
+	}
+
+	tx, err := txauthor.NewUnsignedTransaction(outputs, feeSatPerKb,
+		inputSource, changeSource)
+	if err != nil {
+		return nil, err
+	}
+	if tx.ChangeIndex >= 0 {
+		tx.RandomizeChangePosition()
+	}
+	tx.Tx.LockTime = orig.LockTime
+	signalReplaceable(tx.Tx)
+
+	err = tx.AddAllInputScripts(w.secretsSource(addrmgrNs))
+	if err != nil {
+		return nil, err
+	}
+
+	// BIP0125 requires the replacement to pay a higher absolute fee which
+	// also covers relaying the replacement itself at the minimum relay
+	// fee.
+	oldFee := totalInput - h.SumOutputValues(orig.TxOut)
+	newFee := totalInput - h.SumOutputValues(tx.Tx.TxOut)
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx.Tx))
+	vsize := int((weight + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor)
+	minFee := oldFee + txrules.FeeForSerializeSize(
+		txrules.DefaultRelayFeePerKb, vsize,
+	)
+	if newFee < minFee {
+		return nil, fmt.Errorf("replacement fee %v is below the "+
+			"minimum of %v required to replace transaction %v",
+			newFee, minFee, txHash)
+	}
+
+	return tx, nil
+}
@@ -8,13 +8,130 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/chain"
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/walletdb"
 	"github.com/btcsuite/btcwallet/wtxmgr"
 )
 
+const (
+	// maxBatchedBlocks is the number of connected blocks whose database
+	// updates are committed in a single transaction while notifications
+	// are queued, such as during a rescan.
+	maxBatchedBlocks = 100
+
+	// maxBatchedNotifications bounds the number of notifications whose
+	// database updates are committed in a single transaction, for
+	// backends notifying many relevant transactions between blocks.
+	maxBatchedNotifications = 1000
+)
+
+// chainNtfnUpdate is the database update of a chain notification, and the
+// notification of clients of the update, if any, once it was committed.
+type chainNtfnUpdate struct {
+	name   string
+	update func(walletdb.ReadWriteTx) error
+	notify func(walletdb.ReadTx)
+}
+
+// chainNtfnBatch accumulates the database updates of consecutive chain
+// notifications so that they are committed in a single database transaction
+// rather than syncing the database for every block and transaction.
+type chainNtfnBatch struct {
+	updates []chainNtfnUpdate
+	blocks  int
+}
+
+// add appends the update of the notification name to the batch.  Clients are
+// notified of the update with notify, unless nil, once it was committed.
+func (b *chainNtfnBatch) add(name string, update func(walletdb.ReadWriteTx) error,
+	notify func(walletdb.ReadTx)) {
+
+	b.updates = append(b.updates, chainNtfnUpdate{name, update, notify})
+}
+
+// full returns whether the batch must be committed before adding further
+// updates.
+func (b *chainNtfnBatch) full() bool {
+	return b.blocks >= maxBatchedBlocks ||
+		len(b.updates) >= maxBatchedNotifications
+}
+
+// commit applies the updates of the batch in a single database transaction
+// and empties the batch.  When the transaction fails, each update is applied
+// in its own transaction, as before batching, so that a failing update, such
+// as a block connected out of order, does not discard the others.  Clients are
+// only notified of the updates which were committed, once they were.
+func (b *chainNtfnBatch) commit(w *Wallet) {
+	if len(b.updates) == 0 {
+		return
+	}
+	updates := b.updates
+	b.updates = nil
+	b.blocks = 0
+
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		for _, u := range updates {
+			if err := u.update(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		notifyChainNtfnUpdates(w, updates)
+		return
+	}
+	if len(updates) == 1 {
+		logChainNtfnErr(updates[0].name, err)
+		return
+	}
+	for i, u := range updates {
+		err := walletdb.Update(w.db, u.update)
+		logChainNtfnErr(u.name, err)
+		if err == nil {
+			notifyChainNtfnUpdates(w, updates[i:i+1])
+		}
+	}
+}
+
+// notifyChainNtfnUpdates notifies clients of the committed updates, in order.
+func notifyChainNtfnUpdates(w *Wallet, updates []chainNtfnUpdate) {
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		for _, u := range updates {
+			if u.notify != nil {
+				u.notify(tx)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Cannot notify chain notification updates: %v", err)
+	}
+}
+
+// logChainNtfnErr logs the error, if any, of processing the chain notification
+// name.
+func logChainNtfnErr(name string, err error) {
+	if err == nil {
+		return
+	}
+
+	// On out-of-sync blockconnected notifications, only send a debug
+	// message.
+	errStr := "Failed to process consensus server notification " +
+		"(name: `%s`, detail: `%v`)"
+	if name == "blockconnected" && strings.Contains(err.Error(),
+		"couldn't get hash from database") {
+		log.Debugf(errStr, name, err)
+	} else {
+		log.Errorf(errStr, name, err)
+	}
+}
+
 func (w *Wallet) handleChainNotifications() {
 	defer w.wg.Done()
 
@@ -85,117 +202,158 @@ func (w *Wallet) handleChainNotifications() {
 		return err
 	}
 
+	// The updates of connected blocks and relevant transactions are
+	// batched while further notifications are queued, and committed once
+	// the queue is drained, the batch is full, or a notification which
+	// depends on them is received.
+	var batch chainNtfnBatch
+	defer batch.commit(w)
+
 	for {
+		var n interface{}
+		var ok bool
 		select {
-		case n, ok := <-chainClient.Notifications():
-			if !ok {
+		case n, ok = <-chainClient.Notifications():
+		case <-w.quit:
+			return
+		default:
+			batch.commit(w)
+			select {
+			case n, ok = <-chainClient.Notifications():
+			case <-w.quit:
 				return
 			}
+		}
+		if !ok {
+			return
+		}
 
-			var notificationName string
-			var err error
-			switch n := n.(type) {
-			case chain.ClientConnected:
-				go sync(w)
-			case chain.BlockConnected:
-				err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
-					return w.connectBlock(tx, wtxmgr.BlockMeta(n))
-				})
-				notificationName = "blockconnected"
-			case chain.BlockDisconnected:
-				err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
-					return w.disconnectBlock(tx, wtxmgr.BlockMeta(n))
-				})
-				notificationName = "blockdisconnected"
-			case chain.RelevantTx:
-				err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
-					return w.addRelevantTx(tx, n.TxRecord, n.Block)
-				})
-				notificationName = "recvtx/redeemingtx"
-			case chain.FilteredBlockConnected:
-				// Atomically update for the whole block.
-				if len(n.RelevantTxs) > 0 {
-					err = walletdb.Update(w.db, func(
-						tx walletdb.ReadWriteTx) error {
-						var err error
-						for _, rec := range n.RelevantTxs {
-							err = w.addRelevantTx(tx, rec,
-								n.Block)
-							if err != nil {
-								return err
-							}
+		var notificationName string
+		var err error
+		switch n := n.(type) {
+		case chain.ClientConnected:
+			batch.commit(w)
+			go sync(w)
+		case chain.BlockConnected:
+			batch.add("blockconnected", func(tx walletdb.ReadWriteTx) error {
+				return w.connectBlock(tx, n.BlockMeta)
+			}, func(tx walletdb.ReadTx) {
+				w.notifyConnectedBlock(tx, n.BlockMeta, n.Header)
+			})
+			batch.blocks++
+		case chain.BlockDisconnected:
+			batch.commit(w)
+			err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+				return w.disconnectBlock(tx, n.BlockMeta, n.Header)
+			})
+			notificationName = "blockdisconnected"
+		case chain.RelevantTx:
+			batch.add("recvtx/redeemingtx", func(tx walletdb.ReadWriteTx) error {
+				return w.addRelevantTx(tx, n.TxRecord, n.Block)
+			}, func(tx walletdb.ReadTx) {
+				w.notifyRelevantTx(tx, n.TxRecord, n.Block)
+			})
+		case chain.FilteredBlockConnected:
+			// Atomically update for the whole block.
+			if len(n.RelevantTxs) > 0 {
+				batch.add("filteredblockconnected", func(
+					tx walletdb.ReadWriteTx) error {
+					var err error
+					for _, rec := range n.RelevantTxs {
+						err = w.addRelevantTx(tx, rec,
+							n.Block)
+						if err != nil {
+							return err
 						}
-						return nil
-					})
-				}
-				notificationName = "filteredblockconnected"
-
-			// The following require some database maintenance, but also
-			// need to be reported to the wallet's rescan goroutine.
-			case *chain.RescanProgress:
-				err = catchUpHashes(w, chainClient, n.Height)
-				notificationName = "rescanprogress"
-				select {
-				case w.rescanNotifications <- n:
-				case <-w.quitChan():
-					return
-				}
-			case *chain.RescanFinished:
-				err = catchUpHashes(w, chainClient, n.Height)
-				notificationName = "rescanprogress"
-				w.SetChainSynced(true)
-				select {
-				case w.rescanNotifications <- n:
-				case <-w.quitChan():
-					return
-				}
+					}
+					return nil
+				}, func(tx walletdb.ReadTx) {
+					for _, rec := range n.RelevantTxs {
+						w.notifyRelevantTx(tx, rec, n.Block)
+					}
+				})
 			}
-			if err != nil {
-				// On out-of-sync blockconnected notifications, only
-				// send a debug message.
-				errStr := "Failed to process consensus server " +
-					"notification (name: `%s`, detail: `%v`)"
-				if notificationName == "blockconnected" &&
-					strings.Contains(err.Error(),
-						"couldn't get hash from database") {
-					log.Debugf(errStr, notificationName, err)
-				} else {
-					log.Errorf(errStr, notificationName, err)
-				}
+
+		// The following require some database maintenance, but also
+		// need to be reported to the wallet's rescan goroutine.
+		case *chain.RescanProgress:
+			batch.commit(w)
+			err = catchUpHashes(w, chainClient, n.Height)
+			notificationName = "rescanprogress"
+			select {
+			case w.rescanNotifications <- n:
+			case <-w.quitChan():
+				return
 			}
-		case <-w.quit:
-			return
+		case *chain.RescanFinished:
+			batch.commit(w)
+			err = catchUpHashes(w, chainClient, n.Height)
+			notificationName = "rescanprogress"
+			w.SetChainSynced(true)
+			select {
+			case w.rescanNotifications <- n:
+			case <-w.quitChan():
+				return
+			}
+		}
+		if batch.full() {
+			batch.commit(w)
 		}
+		logChainNtfnErr(notificationName, err)
 	}
 }
 
 // connectBlock handles a chain server notification by marking a wallet
 // that's currently in-sync with the chain server as being synced up to
-// the passed block.
+// the passed block.  The block returned by SyncedTo is only updated by
+// notifyConnectedBlock, once the database transaction was committed.
 func (w *Wallet) connectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta) error {
 	addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
+	txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
 
 	bs := waddrmgr.BlockStamp{
 		Height:    b.Height,
 		Hash:      b.Hash,
 		Timestamp: b.Time,
 	}
-	err := w.Manager.SetSyncedTo(addrmgrNs, &bs)
+	err := w.Manager.PutSyncedTo(addrmgrNs, &bs)
 	if err != nil {
 		return err
 	}
 
-	// Notify interested clients of the connected block.
-	//
-	// TODO: move all notifications outside of the database transaction.
+	// Output leases are only checked against the current time, so expired
+	// ones are pruned as blocks are connected.
+	return w.TxStore.DeleteExpiredLockedOutputs(txmgrNs)
+}
+
+// notifyConnectedBlock marks the wallet as synced to the block recorded by
+// connectBlock, and notifies interested clients of it, once the database
+// transaction recording it was committed.  The header, if not nil, is the one
+// notified by the chain server.
+func (w *Wallet) notifyConnectedBlock(dbtx walletdb.ReadTx, b wtxmgr.BlockMeta,
+	header *wire.BlockHeader) {
+
+	w.Manager.MarkSyncedTo(&waddrmgr.BlockStamp{
+		Height:    b.Height,
+		Hash:      b.Hash,
+		Timestamp: b.Time,
+	})
 	w.NtfnServer.notifyAttachedBlock(dbtx, &b)
-	return nil
+	w.NtfnServer.notifyBlockEpoch(&BlockEpochNotification{
+		Connected: true,
+		Hash:      b.Hash,
+		Height:    b.Height,
+		Header:    header,
+	})
 }
 
 // disconnectBlock handles a chain server reorganize by rolling back all
 // block history from the reorged block for a wallet in-sync with the chain
-// server.
-func (w *Wallet) disconnectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta) error {
+// server.  The header, if not nil, is the one notified by the chain server for
+// the reorged block.
+func (w *Wallet) disconnectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta,
+	header *wire.BlockHeader) error {
+
 	addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
 
@@ -205,28 +363,58 @@ func (w *Wallet) disconnectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta)
 
 	// Disconnect the removed block and all blocks after it if we know about
 	// the disconnected block. Otherwise, the block is in the future.
-	if b.Height <= w.Manager.SyncedTo().Height {
+	syncedHeight := w.Manager.SyncedTo().Height
+	if b.Height <= syncedHeight {
 		hash, err := w.Manager.BlockHash(addrmgrNs, b.Height)
 		if err != nil {
 			return err
 		}
 		if bytes.Equal(hash[:], b.Hash[:]) {
-			bs := waddrmgr.BlockStamp{
-				Height: b.Height - 1,
+			// Record the transactions mined in the detached
+			// blocks to notify their status after the rollback.
+			var reorged []chainhash.Hash
+			record := func(details []wtxmgr.TxDetails) (bool, error) {
+				for i := range details {
+					reorged = append(reorged, details[i].Hash)
+				}
+				return false, nil
 			}
-			hash, err = w.Manager.BlockHash(addrmgrNs, bs.Height)
+			err = w.TxStore.RangeTransactions(
+				txmgrNs, b.Height, syncedHeight, record,
+			)
 			if err != nil {
 				return err
 			}
-			b.Hash = *hash
+
+			// Record the hashes of the detached blocks, from the
+			// tip down, before they are removed.
+			detached := make([]chainhash.Hash, 0, syncedHeight-b.Height+1)
+			for height := syncedHeight; height >= b.Height; height-- {
+				hash, err := w.Manager.BlockHash(addrmgrNs, height)
+				if err != nil {
+					return err
+				}
+				detached = append(detached, *hash)
+			}
+
+			// The wallet is synced to the fork point, while the
+			// detached block is the one notified below.
+			forkHash, err := w.Manager.BlockHash(addrmgrNs, b.Height-1)
+			if err != nil {
+				return err
+			}
+			bs := waddrmgr.BlockStamp{
+				Hash:   *forkHash,
+				Height: b.Height - 1,
+			}
 
 			client := w.ChainClient()
-			header, err := client.GetBlockHeader(hash)
+			forkHeader, err := client.GetBlockHeader(forkHash)
 			if err != nil {
 				return err
 			}
 
-			bs.Timestamp = header.Timestamp
+			bs.Timestamp = forkHeader.Timestamp
 			err = w.Manager.SetSyncedTo(addrmgrNs, &bs)
 			if err != nil {
 				return err
@@ -236,6 +424,35 @@ func (w *Wallet) disconnectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta)
 			if err != nil {
 				return err
 			}
+
+			// Transactions which were not moved back to unmined,
+			// such as coinbases, have been removed.
+			for i := range reorged {
+				hash := &reorged[i]
+				details, err := w.TxStore.TxDetails(txmgrNs, hash)
+				if err != nil {
+					return err
+				}
+				status := ReorgUnmined
+				if details == nil {
+					status = ReorgRemoved
+				}
+				w.NtfnServer.notifyReorgedTransaction(hash, status)
+			}
+
+			// Only the header of the reorged block is known,
+			// which is the last one detached.  The epochs are
+			// sent regardless.
+			for i := range detached {
+				n := &BlockEpochNotification{
+					Hash:   detached[i],
+					Height: syncedHeight - int32(i),
+				}
+				if i == len(detached)-1 {
+					n.Header = header
+				}
+				w.NtfnServer.notifyBlockEpoch(n)
+			}
 		}
 	}
 
@@ -245,7 +462,28 @@ func (w *Wallet) disconnectBlock(dbtx walletdb.ReadWriteTx, b wtxmgr.BlockMeta)
 	return nil
 }
 
+// addRelevantTx records the relevant transaction notified by the chain server.
+// Clients are notified of it by notifyRelevantTx once the database transaction
+// was committed.
 func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord, block *wtxmgr.BlockMeta) error {
+	if err := w.insertRelevantTx(dbtx, rec, block); err != nil {
+		return err
+	}
+
+	// Unmined transactions notified by the backend have been relayed by
+	// it, so the unmined transactions they double spend are conflicted.
+	if block == nil {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+		if _, err := w.TxStore.InsertConflicts(txmgrNs, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertRelevantTx records the relevant transaction, its credits and the
+// wallet addresses it pays to as used, without notifying clients of it.
+func (w *Wallet) insertRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord, block *wtxmgr.BlockMeta) error {
 	addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
 
@@ -270,11 +508,8 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord,
 		for _, addr := range addrs {
 			ma, err := w.Manager.Address(addrmgrNs, addr)
 			if err == nil {
-				// TODO: Credits should be added with the
-				// account they belong to, so wtxmgr is able to
-				// track per-account balances.
-				err = w.TxStore.AddCredit(txmgrNs, rec, block, uint32(i),
-					ma.Internal())
+				err = w.TxStore.AddAccountCredit(txmgrNs, rec, block,
+					uint32(i), ma.Internal(), ma.Account())
 				if err != nil {
 					return err
 				}
@@ -294,9 +529,17 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord,
 		}
 	}
 
-	// Send notification of mined or unmined transaction to any interested
-	// clients.
-	//
+	// Outputs paying to and spending deposits of multisig accounts are
+	// recorded separately, since they are not controlled by keys of the
+	// address manager.
+	return addMultisigDeposits(dbtx, rec)
+}
+
+// notifyRelevantTx sends notification of the mined or unmined relevant
+// transaction recorded by insertRelevantTx to any interested clients.
+func (w *Wallet) notifyRelevantTx(dbtx walletdb.ReadTx, rec *wtxmgr.TxRecord, block *wtxmgr.BlockMeta) {
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
 	// TODO: Avoid the extra db hits.
 	if block == nil {
 		details, err := w.TxStore.UniqueTxDetails(txmgrNs, &rec.Hash, nil)
@@ -326,6 +569,4 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord,
 			w.NtfnServer.notifyMinedTransaction(dbtx, details, block)
 		}
 	}
-
-	return nil
 }
@@ -0,0 +1,183 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestChainNtfnBatch ensures that batched notification updates are committed
+// together, that the updates of a batch containing a failing update are still
+// committed, and that clients are notified of each committed update once.
+func TestChainNtfnBatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "chainntfns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := walletdb.Create("bdb", filepath.Join(dir, "wallet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	nsKey := []byte("ns")
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		_, err := tx.CreateTopLevelBucket(nsKey)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Wallet{db: db}
+
+	put := func(key string) func(walletdb.ReadWriteTx) error {
+		return func(tx walletdb.ReadWriteTx) error {
+			return tx.ReadWriteBucket(nsKey).Put([]byte(key), []byte{1})
+		}
+	}
+	has := func(key string) bool {
+		var found bool
+		err := walletdb.View(db, func(tx walletdb.ReadTx) error {
+			found = tx.ReadBucket(nsKey).Get([]byte(key)) != nil
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+
+	var notified []string
+	notify := func(key string) func(walletdb.ReadTx) {
+		return func(tx walletdb.ReadTx) {
+			if tx.ReadBucket(nsKey).Get([]byte(key)) == nil {
+				t.Errorf("notified of %s before it was committed",
+					key)
+			}
+			notified = append(notified, key)
+		}
+	}
+
+	var batch chainNtfnBatch
+	for i := 0; i < maxBatchedBlocks; i++ {
+		if batch.full() {
+			t.Fatalf("batch full after %d blocks", i)
+		}
+		batch.add("blockconnected", put("a"), nil)
+		batch.blocks++
+	}
+	if !batch.full() {
+		t.Fatal("batch not full")
+	}
+	if has("a") {
+		t.Fatal("update committed before the batch")
+	}
+	batch.commit(w)
+	if !has("a") || batch.full() || len(batch.updates) != 0 {
+		t.Fatal("batch not committed")
+	}
+
+	batch.add("recvtx/redeemingtx", put("b"), notify("b"))
+	batch.add("recvtx/redeemingtx", func(walletdb.ReadWriteTx) error {
+		return errors.New("update failed")
+	}, func(walletdb.ReadTx) {
+		t.Error("notified of a failed update")
+	})
+	batch.add("recvtx/redeemingtx", put("c"), notify("c"))
+	batch.commit(w)
+	if !has("b") || !has("c") {
+		t.Fatal("updates of a failed batch not committed")
+	}
+	if !reflect.DeepEqual(notified, []string{"b", "c"}) {
+		t.Fatalf("expected notifications of b and c, got %v", notified)
+	}
+}
+
+// TestChainNtfnBatchConnectBlock ensures that the wallet is only marked synced
+// to batched connected blocks, and clients only notified of them, once they
+// were committed.
+func TestChainNtfnBatchConnectBlock(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	client := w.NtfnServer.TransactionNotifications()
+	defer client.Done()
+
+	// The block at height 1 can be connected, but not the block at height
+	// 3, which fails the batch.
+	block := func(height int32) wtxmgr.BlockMeta {
+		return wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   chainhash.Hash{byte(height)},
+				Height: height,
+			},
+			Time: time.Unix(1500000000+int64(height), 0),
+		}
+	}
+	var batch chainNtfnBatch
+	var committed int32
+	for _, b := range []wtxmgr.BlockMeta{block(1), block(3)} {
+		b := b
+		batch.add("blockconnected", func(tx walletdb.ReadWriteTx) error {
+			synced := w.Manager.SyncedTo()
+			if synced.Height != committed {
+				t.Errorf("synced to height %d while height %d "+
+					"was committed", synced.Height, committed)
+			}
+			return w.connectBlock(tx, b)
+		}, func(tx walletdb.ReadTx) {
+			w.notifyConnectedBlock(tx, b, nil)
+			committed = b.Height
+		})
+		batch.blocks++
+	}
+
+	done := make(chan struct{})
+	go func() {
+		batch.commit(w)
+		close(done)
+	}()
+
+	select {
+	case n := <-client.C:
+		if len(n.AttachedBlocks) != 1 || n.AttachedBlocks[0].Height != 1 {
+			t.Fatalf("expected notification of block 1, got %v",
+				n.AttachedBlocks)
+		}
+		if synced := w.Manager.SyncedTo(); synced.Height != 1 {
+			t.Fatalf("notified while synced to height %d",
+				synced.Height)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification of the connected block")
+	}
+
+	// Neither the block connected in the failed batch nor the failed
+	// block are notified again.
+	select {
+	case n := <-client.C:
+		t.Fatalf("unexpected notification of blocks %v",
+			n.AttachedBlocks)
+	case <-done:
+	}
+	if synced := w.Manager.SyncedTo(); synced.Height != 1 {
+		t.Fatalf("expected wallet synced to height 1, got %d",
+			synced.Height)
+	}
+}
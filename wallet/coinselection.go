@@ -0,0 +1,484 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	h "github.com/btcsuite/btcwallet/internal/helpers"
+	"github.com/btcsuite/btcwallet/wallet/internal/txsizes"
+	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+const (
+	// bnbMaxTries is the maximum number of branches the branch-and-bound
+	// search will visit before giving up.  This is the same limit used by
+	// Bitcoin Core.
+	bnbMaxTries = 100000
+
+	// p2wpkhWitnessVirtualSize is the worst case witness size of a P2WPKH
+	// or nested P2WPKH input in virtual bytes, rounded up.
+	p2wpkhWitnessVirtualSize = (txsizes.RedeemP2WPKHInputWitnessWeight +
+		blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+
+	// p2wpkhInputVirtualSize is the worst case virtual size of an input
+	// spending a P2WPKH output.
+	p2wpkhInputVirtualSize = txsizes.RedeemP2WPKHInputSize +
+		p2wpkhWitnessVirtualSize
+
+	// p2trInputVirtualSize is the worst case virtual size of an input
+	// spending a P2TR output through the key path, rounded up.
+	p2trInputVirtualSize = txsizes.RedeemP2TRInputSize +
+		(txsizes.RedeemP2TRInputWitnessWeight+
+			blockchain.WitnessScaleFactor-1)/
+			blockchain.WitnessScaleFactor
+)
+
+// inputVirtualSize returns the worst case virtual size an input redeeming
+// pkScript adds to a transaction.  Scripts are classified the same way
+// txauthor does when estimating the size of a signed transaction.
+func inputVirtualSize(pkScript []byte) int {
+	switch {
+	case txscript.IsPayToScriptHash(pkScript):
+		return txsizes.RedeemNestedP2WPKHInputSize +
+			p2wpkhWitnessVirtualSize
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		return p2wpkhInputVirtualSize
+	case txsizes.IsP2TRPkScript(pkScript):
+		return p2trInputVirtualSize
+	default:
+		return txsizes.RedeemP2PKHInputSize
+	}
+}
+
+// feeForVirtualSize returns the fee required for vsize bytes at the given fee
+// rate.  Unlike txrules.FeeForSerializeSize, no minimum fee is applied, which
+// makes the result suitable for summing the costs of individual inputs.
+func feeForVirtualSize(feeSatPerKb btcutil.Amount, vsize int) btcutil.Amount {
+	return feeSatPerKb * btcutil.Amount(vsize) / 1000
+}
+
+// effectiveValue returns the value of a credit after subtracting the fee
+// required to spend it at the given fee rate.
+func effectiveValue(credit *wtxmgr.Credit, feeSatPerKb btcutil.Amount) btcutil.Amount {
+	return credit.Amount - feeForVirtualSize(feeSatPerKb,
+		inputVirtualSize(credit.PkScript))
+}
+
+// makePreselectedInputSource creates an input source which always returns
+// every preselected credit, followed by as many additional credits as are
+// needed to reach the requested target.  Additional credits are picked
+// largest first.
+func makePreselectedInputSource(preselected,
+	additional []wtxmgr.Credit) txauthor.InputSource {
+
+	// Pick largest outputs first.  This is only done for compatibility with
+	// previous tx creation code, not because it's a good idea.
+	sort.Sort(sort.Reverse(byAmount(additional)))
+
+	return makeOrderedInputSource(preselected, additional)
+}
+
+// makeOrderedInputSource creates an input source which always returns every
+// preselected credit, followed by as many additional credits as are needed to
+// reach the requested target.  Additional credits are picked in the order they
+// are passed.
+func makeOrderedInputSource(preselected,
+	additional []wtxmgr.Credit) txauthor.InputSource {
+
+	// Current inputs and their total value.  These are closed over by the
+	// returned input source and reused across multiple calls.
+	numInputs := len(preselected) + len(additional)
+	currentTotal := btcutil.Amount(0)
+	currentInputs := make([]*wire.TxIn, 0, numInputs)
+	currentScripts := make([][]byte, 0, numInputs)
+	currentInputValues := make([]btcutil.Amount, 0, numInputs)
+
+	addCredit := func(credit *wtxmgr.Credit) {
+		nextInput := wire.NewTxIn(&credit.OutPoint, nil, nil)
+		currentTotal += credit.Amount
+		currentInputs = append(currentInputs, nextInput)
+		currentScripts = append(currentScripts, credit.PkScript)
+		currentInputValues = append(currentInputValues, credit.Amount)
+	}
+	for i := range preselected {
+		addCredit(&preselected[i])
+	}
+
+	return func(target btcutil.Amount) (btcutil.Amount, []*wire.TxIn,
+		[]btcutil.Amount, [][]byte, error) {
+
+		for currentTotal < target && len(additional) != 0 {
+			addCredit(&additional[0])
+			additional = additional[1:]
+		}
+		return currentTotal, currentInputs, currentInputValues, currentScripts, nil
+	}
+}
+
+// byConfirmations implements sort.Interface to sort credits from the most to
+// the least confirmed.  Unmined credits are sorted last.  Credits mined in the
+// same block are sorted largest first.
+type byConfirmations []wtxmgr.Credit
+
+func (s byConfirmations) Len() int      { return len(s) }
+func (s byConfirmations) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byConfirmations) Less(i, j int) bool {
+	hi, hj := s[i].Height, s[j].Height
+	switch {
+	case hi == hj:
+		return s[i].Amount > s[j].Amount
+	case hi == -1:
+		return false
+	case hj == -1:
+		return true
+	default:
+		return hi < hj
+	}
+}
+
+// makeOldestInputSource creates an input source which picks the most
+// confirmed eligible credits first.  This avoids spending recently received
+// outputs which are more likely to be affected by a reorg.
+func makeOldestInputSource(eligible []wtxmgr.Credit) txauthor.InputSource {
+	sort.Sort(byConfirmations(eligible))
+	return makeOrderedInputSource(nil, eligible)
+}
+
+// makeBnBInputSource creates an input source which, if possible, selects a set
+// of eligible credits that pays for all outputs and fees without the need for
+// a change output.  Such a set is searched for with the branch-and-bound
+// algorithm.  When no changeless solution is found, credits are selected
+// largest first.
+func makeBnBInputSource(eligible []wtxmgr.Credit, outputs []*wire.TxOut,
+	feeSatPerKb btcutil.Amount) txauthor.InputSource {
+
+	// txauthor always budgets for a P2WPKH change output when estimating
+	// the fee of the transaction, so the selection target includes it as
+	// well.  A witness marker and flag are accounted for to keep the
+	// target an upper bound of the fee actually required.
+	baseSize := txsizes.EstimateVirtualSize(0, 0, 0, 0, outputs, true) + 1
+	target := h.SumOutputValues(outputs) + feeForVirtualSize(feeSatPerKb,
+		baseSize)
+
+	// Any excess value beyond the target is paid to the miners instead of
+	// being returned as change.  This is only worthwhile when it costs us
+	// less than spending the change output later, and is only safe when
+	// txauthor will consider the excess dust and not add change anyway.
+	costOfChange := feeForVirtualSize(feeSatPerKb, p2wpkhInputVirtualSize)
+	dustThreshold := txrules.GetDustThreshold(
+		txsizes.P2WPKHPkScriptSize, feeSatPerKb,
+	)
+	if dustThreshold <= costOfChange {
+		return makeInputSource(eligible)
+	}
+
+	selected, ok := selectBnB(eligible, target, costOfChange, feeSatPerKb)
+	if !ok {
+		return makeInputSource(eligible)
+	}
+
+	// Keep the remaining credits around in case the final fee computed by
+	// txauthor exceeds our estimate.
+	chosen := make(map[wire.OutPoint]struct{}, len(selected))
+	for _, credit := range selected {
+		chosen[credit.OutPoint] = struct{}{}
+	}
+	remaining := make([]wtxmgr.Credit, 0, len(eligible)-len(selected))
+	for _, credit := range eligible {
+		if _, ok := chosen[credit.OutPoint]; !ok {
+			remaining = append(remaining, credit)
+		}
+	}
+
+	return makePreselectedInputSource(selected, remaining)
+}
+
+// selectBnB performs a depth-first branch-and-bound search over the eligible
+// credits for a set whose total effective value lies within
+// [target, target+costOfChange].  Of all sets found, the one with the
+// smallest excess over the target is returned.  The boolean return is false
+// if no such set exists or the search was exhausted before finding one.
+func selectBnB(eligible []wtxmgr.Credit, target, costOfChange,
+	feeSatPerKb btcutil.Amount) ([]wtxmgr.Credit, bool) {
+
+	// Only credits that add value after paying for their own inclusion
+	// are worth considering.  They are explored from the largest effective
+	// value to the smallest so that overshooting branches are cut early.
+	type candidate struct {
+		credit *wtxmgr.Credit
+		value  btcutil.Amount
+	}
+	var (
+		pool      []candidate
+		available btcutil.Amount
+	)
+	for i := range eligible {
+		value := effectiveValue(&eligible[i], feeSatPerKb)
+		if value <= 0 {
+			continue
+		}
+		pool = append(pool, candidate{&eligible[i], value})
+		available += value
+	}
+	if available < target {
+		return nil, false
+	}
+	sort.SliceStable(pool, func(i, j int) bool {
+		return pool[i].value > pool[j].value
+	})
+
+	var (
+		currValue  btcutil.Amount
+		currSel    []int
+		best       []int
+		bestExcess btcutil.Amount
+	)
+	index := 0
+	for try := 0; try < bnbMaxTries; try++ {
+		backtrack := false
+		switch {
+		// Cannot reach the target with what is left, or already
+		// overshot it by more than the change would cost.
+		case currValue+available < target,
+			currValue > target+costOfChange:
+			backtrack = true
+
+		// Found a solution, record it if it's an improvement.
+		case currValue >= target:
+			excess := currValue - target
+			if best == nil || excess < bestExcess {
+				best = append(best[:0], currSel...)
+				bestExcess = excess
+				if excess == 0 {
+					try = bnbMaxTries
+				}
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			if len(currSel) == 0 {
+				break
+			}
+
+			// Add omitted candidates back before traversing the
+			// omission branch of the last included candidate.
+			last := currSel[len(currSel)-1]
+			for index--; index > last; index-- {
+				available += pool[index].value
+			}
+
+			// The last included candidate is now excluded.
+			currValue -= pool[index].value
+			currSel = currSel[:len(currSel)-1]
+		} else {
+			// Continue down the inclusion branch, unless the
+			// previous candidate has an equal value and was
+			// omitted, since that branch was already explored.
+			value := pool[index].value
+			available -= value
+			if len(currSel) == 0 || index-1 == currSel[len(currSel)-1] ||
+				value != pool[index-1].value {
+
+				currSel = append(currSel, index)
+				currValue += value
+			}
+		}
+		index++
+	}
+	if best == nil {
+		return nil, false
+	}
+
+	selected := make([]wtxmgr.Credit, 0, len(best))
+	for _, i := range best {
+		selected = append(selected, *pool[i].credit)
+	}
+	return selected, true
+}
+
+const (
+	// DefaultKnapsackChangeTarget is the default ideal size of the change
+	// output produced by the knapsack coin selection strategy.  This is
+	// the same value used by Bitcoin Core.
+	DefaultKnapsackChangeTarget btcutil.Amount = 1e6
+
+	// knapsackIterations is the number of random subsets explored when
+	// approximating the best subset of inputs.
+	knapsackIterations = 1000
+)
+
+// newCoinSelectionRand returns a math/rand source seeded from the system's
+// cryptographically secure random number generator.
+func newCoinSelectionRand() (*rand.Rand, error) {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	return rand.New(rand.NewSource(
+		int64(binary.LittleEndian.Uint64(seed[:])),
+	)), nil
+}
+
+// makeKnapsackInputSource creates an input source which selects credits with
+// the knapsack algorithm.  Each call performs a fresh selection for the
+// requested target, aiming to leave a change output close to changeTarget.
+func makeKnapsackInputSource(eligible []wtxmgr.Credit,
+	changeTarget btcutil.Amount, rng *rand.Rand) txauthor.InputSource {
+
+	return func(target btcutil.Amount) (btcutil.Amount, []*wire.TxIn,
+		[]btcutil.Amount, [][]byte, error) {
+
+		selected := selectKnapsack(eligible, target, changeTarget, rng)
+
+		var total btcutil.Amount
+		inputs := make([]*wire.TxIn, 0, len(selected))
+		inputValues := make([]btcutil.Amount, 0, len(selected))
+		scripts := make([][]byte, 0, len(selected))
+		for i := range selected {
+			credit := &selected[i]
+			total += credit.Amount
+			inputs = append(inputs, wire.NewTxIn(&credit.OutPoint, nil, nil))
+			inputValues = append(inputValues, credit.Amount)
+			scripts = append(scripts, credit.PkScript)
+		}
+		return total, inputs, inputValues, scripts, nil
+	}
+}
+
+// selectKnapsack selects credits worth at least target, preferring sets that
+// either match target exactly or leave at least changeTarget as change.  The
+// algorithm follows the knapsack solver used by Bitcoin Core:
+//
+//   - A single credit matching the target exactly is used on its own.
+//   - Otherwise random subsets of the credits smaller than target+changeTarget
+//     are explored for the one closest to the target.
+//   - The smallest credit larger than target+changeTarget is used instead if
+//     no subset does better.
+//
+// If the eligible credits are not worth the target, all of them are returned.
+func selectKnapsack(eligible []wtxmgr.Credit, target,
+	changeTarget btcutil.Amount, rng *rand.Rand) []wtxmgr.Credit {
+
+	var (
+		lower        []wtxmgr.Credit
+		lowerTotal   btcutil.Amount
+		lowestLarger *wtxmgr.Credit
+	)
+	for i := range eligible {
+		credit := &eligible[i]
+		switch {
+		case credit.Amount == target:
+			return []wtxmgr.Credit{*credit}
+
+		case credit.Amount < target+changeTarget:
+			lower = append(lower, *credit)
+			lowerTotal += credit.Amount
+
+		case lowestLarger == nil || credit.Amount < lowestLarger.Amount:
+			lowestLarger = credit
+		}
+	}
+
+	if lowerTotal == target {
+		return lower
+	}
+	if lowerTotal < target {
+		if lowestLarger == nil {
+			return lower
+		}
+		return []wtxmgr.Credit{*lowestLarger}
+	}
+
+	// Exploring larger credits first makes the random subsets reach the
+	// target with fewer inputs.
+	sort.Sort(sort.Reverse(byAmount(lower)))
+
+	included, best := approximateBestSubset(lower, lowerTotal, target, rng)
+	if best != target && lowerTotal >= target+changeTarget {
+		included, best = approximateBestSubset(
+			lower, lowerTotal, target+changeTarget, rng,
+		)
+	}
+
+	// Prefer the single larger credit if the best subset neither matches
+	// the target nor leaves enough change, or is worth more than it.
+	if lowestLarger != nil && ((best != target &&
+		best < target+changeTarget) || lowestLarger.Amount <= best) {
+
+		return []wtxmgr.Credit{*lowestLarger}
+	}
+
+	selected := make([]wtxmgr.Credit, 0, len(lower))
+	for i := range lower {
+		if included[i] {
+			selected = append(selected, lower[i])
+		}
+	}
+	return selected
+}
+
+// approximateBestSubset randomly explores subsets of credits, returning the
+// inclusion flags and total of the smallest subset worth at least target.  If
+// no better subset is found, every credit is included.
+func approximateBestSubset(credits []wtxmgr.Credit, total,
+	target btcutil.Amount, rng *rand.Rand) ([]bool, btcutil.Amount) {
+
+	best := make([]bool, len(credits))
+	for i := range best {
+		best[i] = true
+	}
+	bestTotal := total
+
+	included := make([]bool, len(credits))
+	for rep := 0; rep < knapsackIterations && bestTotal != target; rep++ {
+		for i := range included {
+			included[i] = false
+		}
+		var current btcutil.Amount
+		reachedTarget := false
+
+		// The first pass includes credits at random, while the second
+		// pass includes every credit left out by the first one.
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i := range credits {
+				var include bool
+				if pass == 0 {
+					include = rng.Intn(2) == 1
+				} else {
+					include = !included[i]
+				}
+				if !include {
+					continue
+				}
+
+				current += credits[i].Amount
+				included[i] = true
+				if current < target {
+					continue
+				}
+
+				reachedTarget = true
+				if current < bestTotal {
+					bestTotal = current
+					copy(best, included)
+				}
+				current -= credits[i].Amount
+				included[i] = false
+			}
+		}
+	}
+	return best, bestTotal
+}
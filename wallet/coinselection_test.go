@@ -0,0 +1,615 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/internal/txsizes"
+	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// testFeeRate is the fee rate used by the coin selection tests.  At this rate
+// spending a P2WPKH output costs one satoshi per virtual byte.
+const testFeeRate btcutil.Amount = 1000
+
+// p2wpkhScript returns a dummy P2WPKH output script.
+func p2wpkhScript() []byte {
+	script := make([]byte, txsizes.P2WPKHPkScriptSize)
+	script[1] = 0x14
+	return script
+}
+
+// makeCredits creates P2WPKH credits with unique outpoints whose effective
+// values at testFeeRate equal the passed values.
+func makeCredits(effValues ...btcutil.Amount) []wtxmgr.Credit {
+	spendFee := feeForVirtualSize(testFeeRate, inputVirtualSize(p2wpkhScript()))
+	credits := make([]wtxmgr.Credit, 0, len(effValues))
+	for i, v := range effValues {
+		credits = append(credits, wtxmgr.Credit{
+			OutPoint: wire.OutPoint{
+				Hash:  chainhash.Hash{byte(i + 1)},
+				Index: uint32(i),
+			},
+			Amount:   v + spendFee,
+			PkScript: p2wpkhScript(),
+		})
+	}
+	return credits
+}
+
+func TestSelectBnB(t *testing.T) {
+	tests := []struct {
+		name         string
+		effValues    []btcutil.Amount
+		target       btcutil.Amount
+		costOfChange btcutil.Amount
+		expected     []btcutil.Amount
+	}{
+		{
+			name:      "exact match",
+			effValues: []btcutil.Amount{150000, 60000, 40000, 30000},
+			target:    100000,
+			expected:  []btcutil.Amount{60000, 40000},
+		},
+		{
+			name:         "match within cost of change",
+			effValues:    []btcutil.Amount{150000, 60000, 40050},
+			target:       100000,
+			costOfChange: 100,
+			expected:     []btcutil.Amount{60000, 40050},
+		},
+		{
+			name:         "overshoot beyond cost of change",
+			effValues:    []btcutil.Amount{150000, 60000, 40500},
+			target:       100000,
+			costOfChange: 100,
+		},
+		{
+			name:      "insufficient funds",
+			effValues: []btcutil.Amount{60000, 30000},
+			target:    100000,
+		},
+		{
+			name:         "smallest excess preferred",
+			effValues:    []btcutil.Amount{60000, 45000, 41000, 40010},
+			target:       100000,
+			costOfChange: 2000,
+			expected:     []btcutil.Amount{60000, 40010},
+		},
+	}
+
+	for _, test := range tests {
+		credits := makeCredits(test.effValues...)
+		selected, ok := selectBnB(
+			credits, test.target, test.costOfChange, testFeeRate,
+		)
+		if ok != (test.expected != nil) {
+			t.Fatalf("%s: expected solution %v, got %v", test.name,
+				test.expected != nil, ok)
+		}
+		if !ok {
+			continue
+		}
+
+		var total, expectedTotal btcutil.Amount
+		for _, credit := range selected {
+			total += effectiveValue(&credit, testFeeRate)
+		}
+		for _, v := range test.expected {
+			expectedTotal += v
+		}
+		if len(selected) != len(test.expected) || total != expectedTotal {
+			t.Fatalf("%s: expected %d inputs worth %v, got %d "+
+				"inputs worth %v", test.name, len(test.expected),
+				expectedTotal, len(selected), total)
+		}
+	}
+}
+
+// TestBnBInputSourceChangeless ensures that a transaction funded by a
+// branch-and-bound selection is authored without a change output.
+func TestBnBInputSourceChangeless(t *testing.T) {
+	outputs := []*wire.TxOut{wire.NewTxOut(100000, p2wpkhScript())}
+
+	baseSize := txsizes.EstimateVirtualSize(0, 0, 0, 0, outputs, true) + 1
+	target := btcutil.Amount(100000) + feeForVirtualSize(testFeeRate, baseSize)
+	half := target / 2
+	credits := makeCredits(10*target, half, target-half)
+
+	inputSource := makeBnBInputSource(credits, outputs, testFeeRate)
+	changeSource := func() ([]byte, error) {
+		return p2wpkhScript(), nil
+	}
+	tx, err := txauthor.NewUnsignedTransaction(
+		outputs, testFeeRate, inputSource, changeSource,
+	)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if tx.ChangeIndex != -1 {
+		t.Fatalf("expected no change output, got change at index %d",
+			tx.ChangeIndex)
+	}
+	if len(tx.Tx.TxIn) != 2 {
+		t.Fatalf("expected 2 inputs, got %d", len(tx.Tx.TxIn))
+	}
+}
+
+// creditsWithAmounts creates P2WPKH credits with unique outpoints for each of
+// the passed amounts.
+func creditsWithAmounts(amounts ...btcutil.Amount) []wtxmgr.Credit {
+	credits := make([]wtxmgr.Credit, 0, len(amounts))
+	for i, amt := range amounts {
+		credits = append(credits, wtxmgr.Credit{
+			OutPoint: wire.OutPoint{
+				Hash:  chainhash.Hash{byte(i + 1)},
+				Index: uint32(i),
+			},
+			Amount:   amt,
+			PkScript: p2wpkhScript(),
+		})
+	}
+	return credits
+}
+
+func TestSelectKnapsack(t *testing.T) {
+	tests := []struct {
+		name          string
+		amounts       []btcutil.Amount
+		target        btcutil.Amount
+		changeTarget  btcutil.Amount
+		expectedTotal btcutil.Amount
+		expectedCount int
+	}{
+		{
+			name:          "single exact match",
+			amounts:       []btcutil.Amount{5e5, 1e6, 3e6},
+			target:        1e6,
+			changeTarget:  1e6,
+			expectedTotal: 1e6,
+			expectedCount: 1,
+		},
+		{
+			name:          "lowest larger when smaller insufficient",
+			amounts:       []btcutil.Amount{1e5, 2e5, 9e6, 5e6},
+			target:        1e6,
+			changeTarget:  1e6,
+			expectedTotal: 5e6,
+			expectedCount: 1,
+		},
+		{
+			name:          "exact subset",
+			amounts:       []btcutil.Amount{4e5, 3e5, 3e5, 2e5, 5e5},
+			target:        1e6,
+			changeTarget:  1e6,
+			expectedTotal: 1e6,
+		},
+		{
+			name:          "subset leaving change target",
+			amounts:       []btcutil.Amount{6e5, 6e5, 6e5, 6e5},
+			target:        1e6,
+			changeTarget:  1e5,
+			expectedTotal: 12e5,
+			expectedCount: 2,
+		},
+		{
+			name:          "insufficient funds",
+			amounts:       []btcutil.Amount{1e5},
+			target:        1e6,
+			changeTarget:  1e6,
+			expectedTotal: 1e5,
+			expectedCount: 1,
+		},
+	}
+
+	for _, test := range tests {
+		rng := rand.New(rand.NewSource(1))
+		credits := creditsWithAmounts(test.amounts...)
+		selected := selectKnapsack(
+			credits, test.target, test.changeTarget, rng,
+		)
+
+		var total btcutil.Amount
+		for _, credit := range selected {
+			total += credit.Amount
+		}
+		if total != test.expectedTotal {
+			t.Fatalf("%s: expected selection worth %v, got %v",
+				test.name, test.expectedTotal, total)
+		}
+		if test.expectedCount != 0 && len(selected) != test.expectedCount {
+			t.Fatalf("%s: expected %d inputs, got %d", test.name,
+				test.expectedCount, len(selected))
+		}
+	}
+}
+
+// TestSelectKnapsackDeterministic ensures that knapsack selections depend only
+// on the state of the random number generator.
+func TestSelectKnapsackDeterministic(t *testing.T) {
+	amounts := []btcutil.Amount{
+		7e5, 3e5, 6e5, 2e5, 9e5, 4e5, 1e5, 8e5, 5e5, 11e5,
+	}
+	selection := func() []wtxmgr.Credit {
+		rng := rand.New(rand.NewSource(42))
+		return selectKnapsack(creditsWithAmounts(amounts...), 23e5,
+			DefaultKnapsackChangeTarget, rng)
+	}
+
+	first, second := selection(), selection()
+	if len(first) != len(second) {
+		t.Fatalf("expected identical selections, got %d and %d inputs",
+			len(first), len(second))
+	}
+	for i := range first {
+		if first[i].OutPoint != second[i].OutPoint {
+			t.Fatalf("selections differ at input %d: %v vs %v", i,
+				first[i].OutPoint, second[i].OutPoint)
+		}
+	}
+}
+
+// TestOldestInputSource ensures that the most confirmed credits are picked
+// first, with unmined credits picked last.
+func TestOldestInputSource(t *testing.T) {
+	credits := creditsWithAmounts(1e5, 2e5, 3e5, 4e5, 5e5)
+	credits[0].Height = -1
+	credits[1].Height = 300
+	credits[2].Height = 100
+	credits[3].Height = 200
+	credits[4].Height = 100
+
+	inputSource := makeOldestInputSource(credits)
+	total, inputs, _, _, err := inputSource(6e5)
+	if err != nil {
+		t.Fatalf("unable to select inputs: %v", err)
+	}
+	if total != 8e5 || len(inputs) != 2 {
+		t.Fatalf("expected 2 inputs worth %v, got %d inputs worth %v",
+			btcutil.Amount(8e5), len(inputs), total)
+	}
+
+	// Requesting the full balance must pick the unmined credit last.
+	_, inputs, _, _, err = inputSource(15e5)
+	if err != nil {
+		t.Fatalf("unable to select inputs: %v", err)
+	}
+	expected := []btcutil.Amount{5e5, 3e5, 4e5, 2e5, 1e5}
+	if len(inputs) != len(expected) {
+		t.Fatalf("expected %d inputs, got %d", len(expected), len(inputs))
+	}
+	for i, input := range inputs {
+		if input.PreviousOutPoint != credits[i].OutPoint ||
+			credits[i].Amount != expected[i] {
+
+			t.Fatalf("unexpected input %d: got %v worth %v", i,
+				input.PreviousOutPoint, credits[i].Amount)
+		}
+	}
+}
+
+// TestParseCoinSelectionStrategy ensures that every strategy supplied by this
+// package can be parsed from its name.
+func TestParseCoinSelectionStrategy(t *testing.T) {
+	strategies := []CoinSelectionStrategy{
+		CoinSelectionLargest,
+		CoinSelectionBnB,
+		CoinSelectionKnapsack,
+		CoinSelectionOldest,
+		CoinSelectionAll,
+	}
+	for _, strategy := range strategies {
+		name := fmt.Sprint(strategy)
+		parsed, err := ParseCoinSelectionStrategy(name)
+		if err != nil {
+			t.Fatalf("unable to parse %q: %v", name, err)
+		}
+		if parsed != strategy {
+			t.Fatalf("parsed %q as %v", name, parsed)
+		}
+	}
+
+	if _, err := ParseCoinSelectionStrategy("random"); err == nil {
+		t.Fatal("expected error parsing unknown strategy")
+	}
+}
+
+func TestManualCoinSelection(t *testing.T) {
+	credits := creditsWithAmounts(1e5, 2e5, 3e5, 4e5)
+	missing := wire.OutPoint{Hash: chainhash.Hash{0xff}}
+
+	tests := []struct {
+		name          string
+		inputs        []wire.OutPoint
+		exclusive     bool
+		target        btcutil.Amount
+		expectedTotal btcutil.Amount
+		expectErr     bool
+	}{
+		{
+			name:          "selected inputs exceed target",
+			inputs:        []wire.OutPoint{credits[0].OutPoint, credits[1].OutPoint},
+			target:        1e5,
+			expectedTotal: 3e5,
+		},
+		{
+			name:          "selected inputs keep their order",
+			inputs:        []wire.OutPoint{credits[2].OutPoint, credits[0].OutPoint},
+			target:        1e5,
+			expectedTotal: 4e5,
+		},
+		{
+			name:          "additional inputs largest first",
+			inputs:        []wire.OutPoint{credits[0].OutPoint},
+			target:        3e5,
+			expectedTotal: 5e5,
+		},
+		{
+			name:          "exclusive inputs insufficient",
+			inputs:        []wire.OutPoint{credits[0].OutPoint},
+			exclusive:     true,
+			target:        3e5,
+			expectedTotal: 1e5,
+		},
+		{
+			name:      "input selected twice",
+			inputs:    []wire.OutPoint{credits[0].OutPoint, credits[0].OutPoint},
+			target:    1e5,
+			expectErr: true,
+		},
+		{
+			name:      "ineligible input",
+			inputs:    []wire.OutPoint{credits[0].OutPoint, missing},
+			target:    1e5,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		strategy := ManualCoinSelection{
+			Inputs:    test.inputs,
+			Exclusive: test.exclusive,
+		}
+		eligible := append([]wtxmgr.Credit(nil), credits...)
+		inputSource, err := strategy.InputSource(eligible, nil, testFeeRate)
+		if test.expectErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+
+		total, inputs, _, _, err := inputSource(test.target)
+		if err != nil {
+			t.Fatalf("%s: unable to select inputs: %v", test.name, err)
+		}
+		if total != test.expectedTotal {
+			t.Fatalf("%s: expected selection worth %v, got %v",
+				test.name, test.expectedTotal, total)
+		}
+		for i, op := range test.inputs {
+			if inputs[i].PreviousOutPoint != op {
+				t.Fatalf("%s: expected input %d to spend %v, "+
+					"got %v", test.name, i, op,
+					inputs[i].PreviousOutPoint)
+			}
+		}
+	}
+}
+
+func TestCPFPFeeRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		parentFee   btcutil.Amount
+		parentSize  int
+		childSize   int
+		feeRate     btcutil.Amount
+		expectedFee btcutil.Amount
+	}{
+		{
+			name:        "unknown parent fee",
+			parentSize:  200,
+			childSize:   110,
+			feeRate:     1000,
+			expectedFee: 310,
+		},
+		{
+			name:        "parent pays part of package",
+			parentFee:   100,
+			parentSize:  200,
+			childSize:   110,
+			feeRate:     1000,
+			expectedFee: 210,
+		},
+		{
+			name:        "parent already pays package",
+			parentFee:   1000,
+			parentSize:  200,
+			childSize:   110,
+			feeRate:     1000,
+			expectedFee: 110,
+		},
+		{
+			name:        "rate rounded up",
+			parentFee:   225,
+			parentSize:  225,
+			childSize:   141,
+			feeRate:     10000,
+			expectedFee: 3435,
+		},
+	}
+
+	for _, test := range tests {
+		rate := cpfpFeeRate(test.parentFee, test.parentSize,
+			test.childSize, test.feeRate)
+		fee := txrules.FeeForSerializeSize(rate, test.childSize)
+		if fee != test.expectedFee {
+			t.Fatalf("%s: expected child fee %v, got %v", test.name,
+				test.expectedFee, fee)
+		}
+	}
+}
+
+func TestAntiFeeSnipingLockTime(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	const height = 500000
+	var atHeight, belowHeight int
+	for i := 0; i < 1000; i++ {
+		lockTime := antiFeeSnipingLockTime(height, rng)
+		switch {
+		case lockTime == height:
+			atHeight++
+		case lockTime < height && lockTime > height-100:
+			belowHeight++
+		default:
+			t.Fatalf("lock time %d out of range", lockTime)
+		}
+	}
+	if atHeight == 0 || belowHeight == 0 || belowHeight > atHeight {
+		t.Fatalf("unexpected lock time distribution: %d at height, "+
+			"%d below", atHeight, belowHeight)
+	}
+
+	// Lock times never precede the genesis block.
+	for i := 0; i < 1000; i++ {
+		if lockTime := antiFeeSnipingLockTime(5, rng); lockTime > 5 {
+			t.Fatalf("lock time %d out of range", lockTime)
+		}
+	}
+}
+
+func TestSetLockTime(t *testing.T) {
+	tests := []struct {
+		name             string
+		lockTime         uint32
+		sequence         uint32
+		expectedSequence uint32
+	}{
+		{
+			name:             "final input enables lock time",
+			lockTime:         500000,
+			sequence:         wire.MaxTxInSequenceNum,
+			expectedSequence: wire.MaxTxInSequenceNum - 1,
+		},
+		{
+			name:             "replaceable input unchanged",
+			lockTime:         500000,
+			sequence:         replaceableSequence,
+			expectedSequence: replaceableSequence,
+		},
+		{
+			name:             "no lock time",
+			lockTime:         0,
+			sequence:         wire.MaxTxInSequenceNum,
+			expectedSequence: wire.MaxTxInSequenceNum,
+		},
+	}
+
+	for _, test := range tests {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{Sequence: test.sequence})
+		setLockTime(tx, test.lockTime)
+		if tx.LockTime != test.lockTime {
+			t.Fatalf("%s: expected lock time %d, got %d", test.name,
+				test.lockTime, tx.LockTime)
+		}
+		if tx.TxIn[0].Sequence != test.expectedSequence {
+			t.Fatalf("%s: expected sequence %d, got %d", test.name,
+				test.expectedSequence, tx.TxIn[0].Sequence)
+		}
+	}
+}
+
+func TestCheckFeeLimit(t *testing.T) {
+	// The transaction sends 5e5 with 4e5 change, paying a fee of 1e5.
+	tx := &txauthor.AuthoredTx{
+		Tx: &wire.MsgTx{
+			TxOut: []*wire.TxOut{
+				wire.NewTxOut(5e5, p2wpkhScript()),
+				wire.NewTxOut(4e5, p2wpkhScript()),
+			},
+		},
+		TotalInput:  1e6,
+		ChangeIndex: 1,
+	}
+
+	tests := []struct {
+		name      string
+		opts      []TxCreateOption
+		expectErr bool
+	}{
+		{
+			name: "no limit",
+		},
+		{
+			name: "below absolute cap",
+			opts: []TxCreateOption{WithFeeLimit(0, 2e5)},
+		},
+		{
+			name:      "above absolute cap",
+			opts:      []TxCreateOption{WithFeeLimit(0, 5e4)},
+			expectErr: true,
+		},
+		{
+			name: "below ratio",
+			opts: []TxCreateOption{WithFeeLimit(0.5, 0)},
+		},
+		{
+			name:      "above ratio",
+			opts:      []TxCreateOption{WithFeeLimit(0.1, 0)},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := checkFeeLimit(tx, applyTxCreateOptions(test.opts))
+		if !test.expectErr {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		feeErr, ok := err.(*FeeLimitError)
+		if !ok {
+			t.Fatalf("%s: expected FeeLimitError, got %v", test.name,
+				err)
+		}
+		if feeErr.Fee != 1e5 || feeErr.Sent != 5e5 {
+			t.Fatalf("%s: unexpected fee %v sending %v", test.name,
+				feeErr.Fee, feeErr.Sent)
+		}
+	}
+}
+
+func TestAllCoinSelection(t *testing.T) {
+	// The smallest credit costs more to spend than it is worth.
+	credits := creditsWithAmounts(1e5, 2e5, 100)
+
+	inputSource, err := CoinSelectionAll.InputSource(credits, nil,
+		testFeeRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, inputs, _, _, err := inputSource(1)
+	if err != nil {
+		t.Fatalf("unable to select inputs: %v", err)
+	}
+	if total != 300100 || len(inputs) != len(credits) {
+		t.Fatalf("expected every credit to be selected, got %d "+
+			"inputs worth %v", len(inputs), total)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+var (
+	// errNotImplemented is returned by the methods of mockChainClient
+	// which the tests do not use.
+	errNotImplemented = errors.New("not implemented")
+
+	testPubPass  = []byte("public")
+	testPrivPass = []byte("private")
+)
+
+func init() {
+	// Use cheap scrypt parameters to keep the tests creating wallets
+	// fast.
+	waddrmgr.SetSecretKeyGen(func(passphrase *[]byte,
+		_ *waddrmgr.ScryptOptions) (*snacl.SecretKey, error) {
+
+		return snacl.NewSecretKey(passphrase, 16, 8, 1)
+	})
+}
+
+// testWallet creates a wallet in a temporary directory which is attached to a
+// mockChainClient.  The returned function closes the wallet and removes its
+// directory.
+func testWallet(t *testing.T) (*Wallet, *mockChainClient, func()) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := walletdb.Create("bdb", filepath.Join(dir, "wallet.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	err = Create(
+		db, testPubPass, testPrivPass, seed,
+		&chaincfg.TestNet3Params, time.Now(),
+	)
+	if err != nil {
+		cleanup()
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	w, err := Open(
+		db, testPubPass, nil, &chaincfg.TestNet3Params, 0, nil,
+	)
+	if err != nil {
+		cleanup()
+		t.Fatalf("unable to open wallet: %v", err)
+	}
+
+	chainClient := newMockChainClient()
+	w.chainClient = chainClient
+	return w, chainClient, func() {
+		w.Stop()
+		w.WaitForShutdown()
+		cleanup()
+	}
+}
+
+// mockChainClient is a chain.Interface which records the transactions sent to
+// it.
+type mockChainClient struct {
+	mu         sync.Mutex
+	sent       []*wire.MsgTx
+	sendErr    error
+	bestHeight int32
+
+	notifications chan interface{}
+}
+
+var _ chain.Interface = (*mockChainClient)(nil)
+
+func newMockChainClient() *mockChainClient {
+	return &mockChainClient{notifications: make(chan interface{})}
+}
+
+// setSendErr sets the error returned when transactions are sent.
+func (c *mockChainClient) setSendErr(err error) {
+	c.mu.Lock()
+	c.sendErr = err
+	c.mu.Unlock()
+}
+
+// sentTxs returns the transactions sent to the client.
+func (c *mockChainClient) sentTxs() []*wire.MsgTx {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*wire.MsgTx(nil), c.sent...)
+}
+
+func (c *mockChainClient) Start() error {
+	return nil
+}
+
+func (c *mockChainClient) Stop() {
+}
+
+func (c *mockChainClient) WaitForShutdown() {
+}
+
+func (c *mockChainClient) GetBestBlock() (*chainhash.Hash, int32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &chainhash.Hash{}, c.bestHeight, nil
+}
+
+func (c *mockChainClient) GetBlock(*chainhash.Hash) (*wire.MsgBlock, error) {
+	return nil, errNotImplemented
+}
+
+func (c *mockChainClient) GetBlockHash(int64) (*chainhash.Hash, error) {
+	return nil, errNotImplemented
+}
+
+func (c *mockChainClient) GetBlockHeader(*chainhash.Hash) (*wire.BlockHeader,
+	error) {
+
+	return nil, errNotImplemented
+}
+
+func (c *mockChainClient) FilterBlocks(*chain.FilterBlocksRequest) (
+	*chain.FilterBlocksResponse, error) {
+
+	return nil, nil
+}
+
+func (c *mockChainClient) BlockStamp() (*waddrmgr.BlockStamp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &waddrmgr.BlockStamp{Height: c.bestHeight}, nil
+}
+
+func (c *mockChainClient) SendRawTransaction(tx *wire.MsgTx,
+	_ bool) (*chainhash.Hash, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, tx)
+	if c.sendErr != nil {
+		return nil, c.sendErr
+	}
+	txHash := tx.TxHash()
+	return &txHash, nil
+}
+
+func (c *mockChainClient) EstimateFeePerKb(uint32) (btcutil.Amount, error) {
+	return 1000, nil
+}
+
+func (c *mockChainClient) Rescan(*chainhash.Hash, []btcutil.Address,
+	map[wire.OutPoint]btcutil.Address) error {
+
+	return nil
+}
+
+func (c *mockChainClient) NotifyReceived([]btcutil.Address) error {
+	return nil
+}
+
+func (c *mockChainClient) NotifyBlocks() error {
+	return nil
+}
+
+func (c *mockChainClient) Notifications() <-chan interface{} {
+	return c.notifications
+}
+
+func (c *mockChainClient) BackEnd() string {
+	return "mock"
+}
+
+// testTx returns a transaction spending the provided outpoint to a single
+// output of the provided value.
+func testTx(prevOut wire.OutPoint, value int64) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&prevOut, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(value, []byte{0x51}))
+	return tx
+}
+
+// insertUnminedTx records tx as an unmined transaction of the wallet.
+func insertUnminedTx(t *testing.T, w *Wallet, tx *wire.MsgTx) *wtxmgr.TxRecord {
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+		ns := dbTx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.InsertTx(ns, rec, nil)
+	})
+	if err != nil {
+		t.Fatalf("unable to insert transaction: %v", err)
+	}
+	return rec
+}
+
+// unminedTxHashes returns the hashes of the unmined transactions of the wallet.
+func unminedTxHashes(t *testing.T, w *Wallet) []chainhash.Hash {
+	var hashes []chainhash.Hash
+	err := walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+		ns := dbTx.ReadBucket(wtxmgrNamespaceKey)
+		txs, err := w.TxStore.UnminedTxs(ns)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			hashes = append(hashes, tx.TxHash())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hashes
+}
+
+// waitFor polls cond until it returns true, failing the test when it does not
+// within a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet/internal/txsizes"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// ChildPaysForParent accelerates the unmined transaction creating the wallet
+// output op by publishing a child transaction which spends op back to the
+// wallet.  The child pays a fee such that the combined fee rate of the parent
+// and child transactions reaches feeSatPerKb, but never less than feeSatPerKb
+// for the child alone.  This works for both incoming and outgoing parent
+// transactions.  If the parent spends outputs not controlled by the wallet,
+// its fee is unknown and assumed to be zero, so the child pays for the entire
+// package.  The hash of the child transaction is returned.
+func (w *Wallet) ChildPaysForParent(op wire.OutPoint,
+	feeSatPerKb btcutil.Amount) (*chainhash.Hash, error) {
+
+	var (
+		account   uint32
+		outputs   []*wire.TxOut
+		childRate btcutil.Amount
+	)
+	err := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+		parent, err := w.TxStore.TxDetails(txmgrNs, &op.Hash)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("transaction %v not found", op.Hash)
+		}
+		if parent.Block.Height != -1 {
+			return fmt.Errorf("transaction %v is already mined",
+				op.Hash)
+		}
+		unspent := false
+		for _, credit := range parent.Credits {
+			if credit.Index == op.Index && !credit.Spent {
+				unspent = true
+				break
+			}
+		}
+		if !unspent {
+			return fmt.Errorf("output %v is not an unspent wallet "+
+				"output", op)
+		}
+		pkScript := parent.MsgTx.TxOut[op.Index].PkScript
+
+		// The fee already paid by the parent is zero unless it is known
+		// by the transaction store.
+		parentFee := parent.Fee
+		parentWeight := blockchain.GetTransactionWeight(
+			btcutil.NewTx(&parent.MsgTx),
+		)
+		parentSize := int((parentWeight +
+			blockchain.WitnessScaleFactor - 1) /
+			blockchain.WitnessScaleFactor)
+
+		// Spend the output back to a new change address of the
+		// account it belongs to.  As when creating other transactions,
+		// outputs of the imported account are moved into account 0.
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			pkScript, w.chainParams,
+		)
+		if err != nil {
+			return err
+		}
+		if len(addrs) != 1 {
+			return fmt.Errorf("output %v is not spendable by a "+
+				"single address", op)
+		}
+		_, account, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
+		if err != nil {
+			return err
+		}
+		changeAccount := account
+		if account == waddrmgr.ImportedAddrAccount {
+			changeAccount = 0
+		}
+		changeAddr, err := w.newChangeAddress(addrmgrNs, changeAccount)
+		if err != nil {
+			return err
+		}
+		changeScript, err := txscript.PayToAddrScript(changeAddr)
+		if err != nil {
+			return err
+		}
+		outputs = []*wire.TxOut{wire.NewTxOut(0, changeScript)}
+
+		var p2pkh, p2tr, p2wpkh, nested int
+		switch {
+		case txscript.IsPayToScriptHash(pkScript):
+			nested++
+		case txscript.IsPayToWitnessPubKeyHash(pkScript):
+			p2wpkh++
+		case txsizes.IsP2TRPkScript(pkScript):
+			p2tr++
+		default:
+			p2pkh++
+		}
+		childSize := txsizes.EstimateVirtualSize(
+			p2pkh, p2tr, p2wpkh, nested, outputs, false,
+		)
+
+		childRate = cpfpFeeRate(
+			parentFee, parentSize, childSize, feeSatPerKb,
+		)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := ManualCoinSelection{
+		Inputs:    []wire.OutPoint{op},
+		Exclusive: true,
+	}
+	return w.SendOutputs(
+		outputs, account, 0, childRate, strategy, WithSendAll(),
+	)
+}
+
+// cpfpFeeRate returns the fee rate a child transaction of childSize virtual
+// bytes must be authored with so that, together with a parent of parentSize
+// virtual bytes paying parentFee, the package pays feeSatPerKb.  The child
+// always pays at least feeSatPerKb for itself.  The rate is rounded up so the
+// required fee is not undershot.
+func cpfpFeeRate(parentFee btcutil.Amount, parentSize, childSize int,
+	feeSatPerKb btcutil.Amount) btcutil.Amount {
+
+	packageFee := txrules.FeeForSerializeSize(
+		feeSatPerKb, parentSize+childSize,
+	)
+	childFee := packageFee - parentFee
+	minFee := txrules.FeeForSerializeSize(feeSatPerKb, childSize)
+	if childFee < minFee {
+		childFee = minFee
+	}
+
+	size := btcutil.Amount(childSize)
+	return (childFee*1000 + size - 1) / size
+}
@@ -7,7 +7,8 @@ package wallet
 
 import (
 	"fmt"
-	"sort"
+	"math/rand"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/txscript"
@@ -28,30 +29,191 @@ func (s byAmount) Less(i, j int) bool { return s[i].Amount < s[j].Amount }
 func (s byAmount) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 func makeInputSource(eligible []wtxmgr.Credit) txauthor.InputSource {
-	// Pick largest outputs first.  This is only done for compatibility with
-	// previous tx creation code, not because it's a good idea.
-	sort.Sort(sort.Reverse(byAmount(eligible)))
+	return makePreselectedInputSource(nil, eligible)
+}
+
+// CoinSelectionStrategy decides which of the eligible unspent outputs fund a
+// transaction.  Library consumers may provide their own implementation to
+// replace the strategies supplied by this package.
+type CoinSelectionStrategy interface {
+	// InputSource returns the input source used to fund a transaction
+	// paying to outputs at the fee rate feeSatPerKb.  Every credit
+	// returned by the input source must be taken from eligible, which the
+	// strategy may reorder.
+	InputSource(eligible []wtxmgr.Credit, outputs []*wire.TxOut,
+		feeSatPerKb btcutil.Amount) (txauthor.InputSource, error)
+}
+
+var (
+	// CoinSelectionLargest always picks the largest available output to
+	// add to the transaction next.
+	CoinSelectionLargest CoinSelectionStrategy = largestCoinSelection{}
+
+	// CoinSelectionBnB searches for a set of outputs which pays for the
+	// transaction without requiring a change output, using the
+	// branch-and-bound algorithm.  If no such set exists, outputs are
+	// picked the same way as CoinSelectionLargest.
+	CoinSelectionBnB CoinSelectionStrategy = bnbCoinSelection{}
+
+	// CoinSelectionKnapsack randomly explores subsets of the eligible
+	// outputs for one that either pays for the transaction exactly or
+	// leaves a change output close to DefaultKnapsackChangeTarget.
+	CoinSelectionKnapsack CoinSelectionStrategy = KnapsackCoinSelection{
+		ChangeTarget: DefaultKnapsackChangeTarget,
+	}
+
+	// CoinSelectionOldest picks the most confirmed outputs first.  This
+	// avoids spending recently received outputs which are more likely to
+	// be affected by a reorg.
+	CoinSelectionOldest CoinSelectionStrategy = oldestCoinSelection{}
+
+	// CoinSelectionAll spends every eligible output, including outputs
+	// worth less than the fee paid to spend them.  This consolidates the
+	// outputs of an account, such as dust, into the change output or, with
+	// WithSendAll, into the single output of the transaction.  It is best
+	// used when fee rates are low.
+	CoinSelectionAll CoinSelectionStrategy = allCoinSelection{}
+)
+
+// largestCoinSelection implements CoinSelectionLargest.
+type largestCoinSelection struct{}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (largestCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	_ []*wire.TxOut, _ btcutil.Amount) (txauthor.InputSource, error) {
+
+	return makeInputSource(eligible), nil
+}
 
-	// Current inputs and their total value.  These are closed over by the
-	// returned input source and reused across multiple calls.
-	currentTotal := btcutil.Amount(0)
-	currentInputs := make([]*wire.TxIn, 0, len(eligible))
-	currentScripts := make([][]byte, 0, len(eligible))
-	currentInputValues := make([]btcutil.Amount, 0, len(eligible))
+// String returns the name of the strategy.
+func (largestCoinSelection) String() string { return "largest" }
+
+// bnbCoinSelection implements CoinSelectionBnB.
+type bnbCoinSelection struct{}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (bnbCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	outputs []*wire.TxOut, feeSatPerKb btcutil.Amount) (txauthor.InputSource, error) {
+
+	return makeBnBInputSource(eligible, outputs, feeSatPerKb), nil
+}
+
+// String returns the name of the strategy.
+func (bnbCoinSelection) String() string { return "bnb" }
+
+// KnapsackCoinSelection is a coin selection strategy which randomly explores
+// subsets of the eligible outputs for one that either pays for the
+// transaction exactly or leaves a change output close to ChangeTarget.
+type KnapsackCoinSelection struct {
+	// ChangeTarget is the ideal change amount aimed for.
+	ChangeTarget btcutil.Amount
+}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (k KnapsackCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	_ []*wire.TxOut, _ btcutil.Amount) (txauthor.InputSource, error) {
+
+	rng, err := newCoinSelectionRand()
+	if err != nil {
+		return nil, err
+	}
+	return makeKnapsackInputSource(eligible, k.ChangeTarget, rng), nil
+}
+
+// String returns the name of the strategy.
+func (KnapsackCoinSelection) String() string { return "knapsack" }
+
+// oldestCoinSelection implements CoinSelectionOldest.
+type oldestCoinSelection struct{}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (oldestCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	_ []*wire.TxOut, _ btcutil.Amount) (txauthor.InputSource, error) {
+
+	return makeOldestInputSource(eligible), nil
+}
+
+// String returns the name of the strategy.
+func (oldestCoinSelection) String() string { return "oldest" }
+
+// allCoinSelection implements CoinSelectionAll.
+type allCoinSelection struct{}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (allCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	_ []*wire.TxOut, _ btcutil.Amount) (txauthor.InputSource, error) {
+
+	return makeOrderedInputSource(eligible, nil), nil
+}
+
+// String returns the name of the strategy.
+func (allCoinSelection) String() string { return "all" }
+
+// ManualCoinSelection is a coin selection strategy which spends a list of
+// outputs chosen by the caller.  Each output must belong to the account being
+// spent from and meet the confirmation requirements of the transaction.
+type ManualCoinSelection struct {
+	// Inputs are the outpoints which must be spent by the transaction.
+	Inputs []wire.OutPoint
+
+	// Exclusive, if true, funds the transaction with Inputs only.
+	// Otherwise, additional eligible outputs are picked largest first if
+	// Inputs do not pay for the transaction.
+	Exclusive bool
+}
+
+// InputSource implements the CoinSelectionStrategy interface.
+func (m ManualCoinSelection) InputSource(eligible []wtxmgr.Credit,
+	_ []*wire.TxOut, _ btcutil.Amount) (txauthor.InputSource, error) {
+
+	unselected := make(map[wire.OutPoint]wtxmgr.Credit, len(eligible))
+	for _, credit := range eligible {
+		unselected[credit.OutPoint] = credit
+	}
 
-	return func(target btcutil.Amount) (btcutil.Amount, []*wire.TxIn,
-		[]btcutil.Amount, [][]byte, error) {
+	// The selected inputs are spent in the order the caller passed them.
+	selected := make([]wtxmgr.Credit, 0, len(m.Inputs))
+	for _, op := range m.Inputs {
+		credit, ok := unselected[op]
+		if !ok {
+			return nil, fmt.Errorf("selected input %v is not "+
+				"eligible for spending or is selected twice", op)
+		}
+		selected = append(selected, credit)
+		delete(unselected, op)
+	}
 
-		for currentTotal < target && len(eligible) != 0 {
-			nextCredit := &eligible[0]
-			eligible = eligible[1:]
-			nextInput := wire.NewTxIn(&nextCredit.OutPoint, nil, nil)
-			currentTotal += nextCredit.Amount
-			currentInputs = append(currentInputs, nextInput)
-			currentScripts = append(currentScripts, nextCredit.PkScript)
-			currentInputValues = append(currentInputValues, nextCredit.Amount)
+	var remaining []wtxmgr.Credit
+	if !m.Exclusive {
+		for _, credit := range eligible {
+			if _, ok := unselected[credit.OutPoint]; ok {
+				remaining = append(remaining, credit)
+			}
 		}
-		return currentTotal, currentInputs, currentInputValues, currentScripts, nil
+	}
+	return makePreselectedInputSource(selected, remaining), nil
+}
+
+// String returns the name of the strategy.
+func (ManualCoinSelection) String() string { return "manual" }
+
+// ParseCoinSelectionStrategy returns the coin selection strategy supplied by
+// this package which is named by s.  Valid names are largest, bnb, knapsack,
+// oldest and all.
+func ParseCoinSelectionStrategy(s string) (CoinSelectionStrategy, error) {
+	switch s {
+	case "largest":
+		return CoinSelectionLargest, nil
+	case "bnb":
+		return CoinSelectionBnB, nil
+	case "knapsack":
+		return CoinSelectionKnapsack, nil
+	case "oldest":
+		return CoinSelectionOldest, nil
+	case "all":
+		return CoinSelectionAll, nil
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy %q", s)
 	}
 }
 
@@ -96,13 +258,272 @@ func (s secretSource) GetScript(addr btcutil.Address) ([]byte, error) {
 	return msa.Script()
 }
 
+// TxOrdering describes how the inputs and outputs of a created transaction are
+// ordered.
+type TxOrdering uint8
+
+const (
+	// TxOrderingRandomChange keeps inputs in the order chosen by the coin
+	// selection strategy and outputs in the order passed by the caller,
+	// and moves any change output to a random position.  This is the
+	// default.
+	TxOrderingRandomChange TxOrdering = iota
+
+	// TxOrderingBIP69 sorts inputs and outputs as described by BIP0069.
+	TxOrderingBIP69
+
+	// TxOrderingRandom shuffles inputs and outputs using the
+	// cryptographically secure random number generator.
+	TxOrderingRandom
+)
+
+// TxCreateOption is a functional option modifying how transactions are created
+// by CreateSimpleTx and SendOutputs.
+type TxCreateOption func(*txCreateOptions)
+
+// txCreateOptions holds the transaction creation settings modified by
+// TxCreateOptions.
+type txCreateOptions struct {
+	sendAll     bool
+	replaceable bool
+	lockTime    uint32
+	lockTimeSet bool
+
+	changeKeyScope   *waddrmgr.KeyScope
+	changeAddress    btcutil.Address
+	changeAccount    uint32
+	changeAccountSet bool
+
+	ordering TxOrdering
+
+	maxFeeRatio float64
+	maxFee      btcutil.Amount
+
+	confTarget uint32
+
+	maxConf                  int32
+	maxConfSet               bool
+	excludeUnconfirmedChange bool
+
+	// unsigned creates the transaction without signing it, which does not
+	// require the wallet to be unlocked.  It is set by FundPsbt.
+	unsigned bool
+
+	// leaseDuration, if non-zero, leases the inputs of the transaction
+	// under leaseID while the transaction creation lock is held, so no
+	// other transaction can select them.  It is set by FundPsbt.
+	leaseID       wtxmgr.LockID
+	leaseDuration time.Duration
+}
+
+// applyTxCreateOptions returns the transaction creation settings resulting from
+// applying each option to the defaults.
+func applyTxCreateOptions(opts []TxCreateOption) *txCreateOptions {
+	o := &txCreateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSendAll creates a transaction with a single output which is paid the
+// total value of every input provided by the coin selection strategy, minus
+// the transaction fee.  No change output is created.  Exactly one output must
+// be passed and its value is ignored.  With CoinSelectionLargest, this sweeps
+// every eligible output of the account.
+func WithSendAll() TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.sendAll = true
+	}
+}
+
+// WithReplaceable signals that the created transaction may be replaced by a
+// transaction paying a higher fee, as defined by BIP0125.  Such a replacement
+// can later be created with BumpFee.
+func WithReplaceable() TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.replaceable = true
+	}
+}
+
+// WithLockTime sets the lock time of the created transaction, overriding the
+// anti-fee-sniping lock time chosen by default.  Callers which require a
+// transaction without a lock time should pass zero.
+func WithLockTime(lockTime uint32) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.lockTime = lockTime
+		o.lockTimeSet = true
+	}
+}
+
+// WithChangeKeyScope derives the change output of the created transaction from
+// the passed key scope, rather than from the P2WPKH scope used by default.
+// This allows, for example, returning change to a new address type while
+// spending outputs of an older one.  The change account must also exist in the
+// passed scope.
+func WithChangeKeyScope(scope waddrmgr.KeyScope) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.changeKeyScope = &scope
+	}
+}
+
+// WithChangeAccount derives the change output of the created transaction from
+// the passed account rather than the account funding the transaction.  This
+// allows moving the remaining value of the spent outputs to another account.
+func WithChangeAccount(account uint32) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.changeAccount = account
+		o.changeAccountSet = true
+	}
+}
+
+// WithChangeAddress pays the change output of the created transaction to the
+// passed address, which need not belong to the wallet, for example to return
+// change to cold storage.  It takes precedence over WithChangeAccount and
+// WithChangeKeyScope.  Change paid to an address outside of the wallet is not
+// tracked by the wallet.
+func WithChangeAddress(addr btcutil.Address) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.changeAddress = addr
+	}
+}
+
+// WithTxOrdering orders the inputs and outputs of the created transaction as
+// described by ordering, instead of only randomizing the change position.
+func WithTxOrdering(ordering TxOrdering) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.ordering = ordering
+	}
+}
+
+// WithFeeLimit rejects the created transaction with a FeeLimitError if its fee
+// exceeds maxFeeRatio times the amount sent, or the absolute cap maxFee.  The
+// amount sent excludes change.  A zero ratio or cap disables that check.  This
+// protects against mistakenly passing a much higher fee rate than intended.
+func WithFeeLimit(maxFeeRatio float64, maxFee btcutil.Amount) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.maxFeeRatio = maxFeeRatio
+		o.maxFee = maxFee
+	}
+}
+
+// WithMaxConf only spends outputs with at most maxconf confirmations, in
+// addition to the minimum number of confirmations passed when creating the
+// transaction.  Immature coinbase outputs are never spent.
+func WithMaxConf(maxconf int32) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.maxConf = maxconf
+		o.maxConfSet = true
+	}
+}
+
+// WithoutUnconfirmedChange does not spend unconfirmed change outputs, even if
+// the minimum number of confirmations passed when creating the transaction is
+// zero.  Unconfirmed outputs received from others may still be spent.
+func WithoutUnconfirmedChange() TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.excludeUnconfirmedChange = true
+	}
+}
+
+// WithConfTarget pays the fee rate estimated to confirm the created transaction
+// within confTarget blocks, as returned by Wallet.EstimateFeePerKb, instead of
+// the fee rate passed by the caller.
+func WithConfTarget(confTarget uint32) TxCreateOption {
+	return func(o *txCreateOptions) {
+		o.confTarget = confTarget
+	}
+}
+
+// FeeLimitError describes a created transaction whose fee exceeds the limit
+// set with WithFeeLimit.
+type FeeLimitError struct {
+	Fee   btcutil.Amount
+	Sent  btcutil.Amount
+	Limit btcutil.Amount
+}
+
+// Error satisifies the error interface.
+func (e *FeeLimitError) Error() string {
+	return fmt.Sprintf("transaction fee %v sending %v exceeds the limit "+
+		"of %v", e.Fee, e.Sent, e.Limit)
+}
+
+// checkFeeLimit returns a FeeLimitError if the fee of tx exceeds the limits of
+// opts.
+func checkFeeLimit(tx *txauthor.AuthoredTx, opts *txCreateOptions) error {
+	var sent, totalOutput btcutil.Amount
+	for i, txOut := range tx.Tx.TxOut {
+		totalOutput += btcutil.Amount(txOut.Value)
+		if i != tx.ChangeIndex {
+			sent += btcutil.Amount(txOut.Value)
+		}
+	}
+	fee := tx.TotalInput - totalOutput
+
+	if opts.maxFee > 0 && fee > opts.maxFee {
+		return &FeeLimitError{Fee: fee, Sent: sent, Limit: opts.maxFee}
+	}
+	if opts.maxFeeRatio > 0 {
+		limit := btcutil.Amount(opts.maxFeeRatio * float64(sent))
+		if fee > limit {
+			return &FeeLimitError{Fee: fee, Sent: sent, Limit: limit}
+		}
+	}
+	return nil
+}
+
+// antiFeeSnipingLockTime returns the lock time of a transaction created at the
+// best block height.  Like Bitcoin Core, this is usually the height itself,
+// which discourages miners from reorganizing the chain to collect the fees of
+// recent blocks.  One in ten times the lock time is moved back by a random
+// number of blocks, so transactions which are delayed before broadcast do not
+// stand out.
+func antiFeeSnipingLockTime(height int32, rng *rand.Rand) uint32 {
+	lockTime := height
+	if rng.Intn(10) == 0 {
+		lockTime -= rng.Int31n(100)
+	}
+	if lockTime < 0 {
+		lockTime = 0
+	}
+	return uint32(lockTime)
+}
+
+// setLockTime sets the lock time of tx.  Lock times are only enforced when not
+// every input has the final sequence number, so any final input sequence
+// numbers are decremented when the lock time is non-zero.  This must be done
+// before signing.
+func setLockTime(tx *wire.MsgTx, lockTime uint32) {
+	tx.LockTime = lockTime
+	if lockTime == 0 {
+		return
+	}
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence == wire.MaxTxInSequenceNum {
+			txIn.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+	}
+}
+
 // txToOutputs creates a signed transaction which includes each output from
 // outputs.  Previous outputs to reedeem are chosen from the passed account's
 // UTXO set and minconf policy. An additional output may be added to return
 // change to the wallet.  An appropriate fee is included based on the wallet's
-// current relay fee.  The wallet must be unlocked to create the transaction.
+// current relay fee.  The passed coin selection strategy decides which of the
+// eligible outputs are spent, defaulting to CoinSelectionLargest if nil.
+// Unless a lock time is set with WithLockTime, the transaction is given an
+// anti-fee-sniping lock time.  The wallet must be unlocked to create the
+// transaction, unless it is created unsigned.
 func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
-	minconf int32, feeSatPerKb btcutil.Amount) (tx *txauthor.AuthoredTx, err error) {
+	minconf int32, feeSatPerKb btcutil.Amount,
+	coinSelectionStrategy CoinSelectionStrategy,
+	opts *txCreateOptions) (tx *txauthor.AuthoredTx, err error) {
+
+	if opts.sendAll && len(outputs) != 1 {
+		return nil, fmt.Errorf("send-all transactions require exactly "+
+			"one output, got %d", len(outputs))
+	}
 
 	chainClient, err := w.requireChainClient()
 	if err != nil {
@@ -118,53 +539,133 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 			return err
 		}
 
-		eligible, err := w.findEligibleOutputs(dbtx, account, minconf, bs)
+		eligible, err := w.findEligibleOutputs(
+			dbtx, account, minconf, bs, opts,
+		)
+		if err != nil {
+			return err
+		}
+
+		if coinSelectionStrategy == nil {
+			coinSelectionStrategy = CoinSelectionLargest
+		}
+		inputSource, err := coinSelectionStrategy.InputSource(
+			eligible, outputs, feeSatPerKb,
+		)
 		if err != nil {
 			return err
 		}
 
-		inputSource := makeInputSource(eligible)
 		changeSource := func() ([]byte, error) {
+			if opts.changeAddress != nil {
+				return txscript.PayToAddrScript(opts.changeAddress)
+			}
+
 			// Derive the change output script.  As a hack to allow
 			// spending from the imported account, change addresses
-			// are created from account 0.
+			// are created from account 0 unless another change
+			// account is requested.
+			changeAccount := account
+			switch {
+			case opts.changeAccountSet:
+				changeAccount = opts.changeAccount
+			case account == waddrmgr.ImportedAddrAccount:
+				changeAccount = 0
+			}
 			var changeAddr btcutil.Address
 			var err error
-			if account == waddrmgr.ImportedAddrAccount {
-				changeAddr, err = w.newChangeAddress(addrmgrNs, 0)
+			if opts.changeKeyScope != nil {
+				changeAddr, err = w.newScopedChangeAddress(
+					addrmgrNs, *opts.changeKeyScope,
+					changeAccount,
+				)
 			} else {
-				changeAddr, err = w.newChangeAddress(addrmgrNs, account)
+				changeAddr, err = w.newChangeAddress(
+					addrmgrNs, changeAccount,
+				)
 			}
 			if err != nil {
 				return nil, err
 			}
 			return txscript.PayToAddrScript(changeAddr)
 		}
-		tx, err = txauthor.NewUnsignedTransaction(outputs, feeSatPerKb,
-			inputSource, changeSource)
+		if opts.sendAll {
+			tx, err = txauthor.NewUnsignedSendAllTransaction(
+				outputs[0].PkScript, feeSatPerKb, inputSource,
+			)
+		} else {
+			tx, err = txauthor.NewUnsignedTransaction(outputs,
+				feeSatPerKb, inputSource, changeSource)
+		}
 		if err != nil {
 			return err
 		}
+		if err := checkFeeLimit(tx, opts); err != nil {
+			return err
+		}
 
-		// Randomize change position, if change exists, before signing.
-		// This doesn't affect the serialize size, so the change amount
-		// will still be valid.
-		if tx.ChangeIndex >= 0 {
-			tx.RandomizeChangePosition()
+		// Order inputs and outputs, or randomize the change position if
+		// change exists, before signing.  This doesn't affect the
+		// serialize size, so the change amount will still be valid.
+		switch opts.ordering {
+		case TxOrderingBIP69:
+			tx.SortBIP69()
+		case TxOrderingRandom:
+			if err := tx.Shuffle(); err != nil {
+				return err
+			}
+		default:
+			if tx.ChangeIndex >= 0 {
+				tx.RandomizeChangePosition()
+			}
+		}
+
+		lockTime := opts.lockTime
+		if !opts.lockTimeSet {
+			rng, err := newCoinSelectionRand()
+			if err != nil {
+				return err
+			}
+			lockTime = antiFeeSnipingLockTime(bs.Height, rng)
+		}
+		setLockTime(tx.Tx, lockTime)
+
+		if opts.replaceable {
+			signalReplaceable(tx.Tx)
+		}
+
+		if opts.leaseDuration > 0 {
+			txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+			for _, txIn := range tx.Tx.TxIn {
+				_, err := w.TxStore.LockOutput(
+					txmgrNs, opts.leaseID,
+					txIn.PreviousOutPoint, opts.leaseDuration,
+				)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
-		return tx.AddAllInputScripts(secretSource{w.Manager, addrmgrNs})
+		if opts.unsigned {
+			return nil
+		}
+		return tx.AddAllInputScripts(w.secretsSource(addrmgrNs))
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = validateMsgTx(tx.Tx, tx.PrevScripts, tx.PrevInputValues)
-	if err != nil {
-		return nil, err
+	if !opts.unsigned {
+		err = validateMsgTx(tx.Tx, tx.PrevScripts, tx.PrevInputValues)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if tx.ChangeIndex >= 0 && account == waddrmgr.ImportedAddrAccount {
+	if tx.ChangeIndex >= 0 && account == waddrmgr.ImportedAddrAccount &&
+		opts.changeAddress == nil && !opts.changeAccountSet {
+
 		changeAmount := btcutil.Amount(tx.Tx.TxOut[tx.ChangeIndex].Value)
 		log.Warnf("Spend from imported account produced change: moving"+
 			" %v from imported account into default account.", changeAmount)
@@ -173,7 +674,10 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 	return tx, nil
 }
 
-func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minconf int32, bs *waddrmgr.BlockStamp) ([]wtxmgr.Credit, error) {
+func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32,
+	minconf int32, bs *waddrmgr.BlockStamp,
+	opts *txCreateOptions) ([]wtxmgr.Credit, error) {
+
 	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
 
@@ -181,6 +685,14 @@ func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minco
 	if err != nil {
 		return nil, err
 	}
+	leases, err := w.TxStore.LockedOutputs(txmgrNs)
+	if err != nil {
+		return nil, err
+	}
+	leased := make(map[wire.OutPoint]struct{}, len(leases))
+	for _, lease := range leases {
+		leased[lease.OutPoint] = struct{}{}
+	}
 
 	// TODO: Eventually all of these filters (except perhaps output locking)
 	// should be handled by the call to UnspentOutputs (or similar).
@@ -197,6 +709,11 @@ func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minco
 		if !confirmed(minconf, output.Height, bs.Height) {
 			continue
 		}
+		if opts.maxConfSet &&
+			confirms(output.Height, bs.Height) > opts.maxConf {
+
+			continue
+		}
 		if output.FromCoinBase {
 			target := int32(w.chainParams.CoinbaseMaturity)
 			if !confirmed(target, output.Height, bs.Height) {
@@ -204,10 +721,13 @@ func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minco
 			}
 		}
 
-		// Locked unspent outputs are skipped.
+		// Locked and leased unspent outputs are skipped.
 		if w.LockedOutpoint(output.OutPoint) {
 			continue
 		}
+		if _, ok := leased[output.OutPoint]; ok {
+			continue
+		}
 
 		// Only include the output if it is associated with the passed
 		// account.
@@ -219,10 +739,18 @@ func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minco
 		if err != nil || len(addrs) != 1 {
 			continue
 		}
-		_, addrAcct, err := w.Manager.AddrAccount(addrmgrNs, addrs[0])
-		if err != nil || addrAcct != account {
+		addrAcct, ok := w.creditAccount(addrmgrNs, output)
+		if !ok || addrAcct != account {
 			continue
 		}
+
+		// Change outputs are paid to internal addresses.
+		if opts.excludeUnconfirmedChange && output.Height == -1 {
+			addr, err := w.Manager.Address(addrmgrNs, addrs[0])
+			if err != nil || addr.Internal() {
+				continue
+			}
+		}
 		eligible = append(eligible, *output)
 	}
 	return eligible, nil
@@ -0,0 +1,320 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+)
+
+// Character sets and generator of the output descriptor checksum.
+const (
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var descriptorGenerator = [5]uint64{
+	0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd,
+}
+
+// descriptorChecksum returns the eight character checksum of an output
+// descriptor without its checksum.
+func descriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	polymod := func(value uint64) {
+		top := c >> 35
+		c = (c&0x7ffffffff)<<5 ^ value
+		for i := uint(0); i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				c ^= descriptorGenerator[i]
+			}
+		}
+	}
+
+	var groups []uint64
+	for _, r := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, r)
+		if pos == -1 {
+			return "", fmt.Errorf("invalid descriptor character %q", r)
+		}
+		polymod(uint64(pos & 31))
+		groups = append(groups, uint64(pos>>5))
+		if len(groups) == 3 {
+			polymod(groups[0]*9 + groups[1]*3 + groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		polymod(groups[0])
+	case 2:
+		polymod(groups[0]*3 + groups[1])
+	}
+	for i := 0; i < 8; i++ {
+		polymod(0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-uint(i))))&31]
+	}
+	return string(checksum), nil
+}
+
+// splitDescriptor splits a descriptor expression of the form name(args) into
+// its name and top level arguments.
+func splitDescriptor(expr string) (string, []string, error) {
+	open := strings.IndexByte(expr, '(')
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return "", nil, fmt.Errorf("malformed descriptor expression %q",
+			expr)
+	}
+	name, inner := expr[:open], expr[open+1:len(expr)-1]
+
+	var (
+		args  []string
+		depth int
+		start int
+	)
+	for i, r := range inner {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				return "", nil, fmt.Errorf("unbalanced descriptor "+
+					"expression %q", expr)
+			}
+		case ',':
+			if depth == 0 {
+				args = append(args, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return "", nil, fmt.Errorf("unbalanced descriptor expression %q",
+			expr)
+	}
+	args = append(args, inner[start:])
+	return name, args, nil
+}
+
+// descriptorKey is a key expression of an output descriptor.  Exactly one of
+// pubKey, for a single key, or accountKey, for the ranged keys of both
+// branches of an account, is set.
+type descriptorKey struct {
+	pubKey     *btcec.PublicKey
+	accountKey *hdkeychain.ExtendedKey
+}
+
+// parseDescriptorKey parses a key expression.  Key origin information is
+// ignored.  Extended keys must be account keys followed by the external,
+// internal or both branches and a wildcard, such as xpub.../0/* or
+// xpub.../<0;1>/*.
+func parseDescriptorKey(expr string) (*descriptorKey, error) {
+	if strings.HasPrefix(expr, "[") {
+		end := strings.IndexByte(expr, ']')
+		if end == -1 {
+			return nil, fmt.Errorf("malformed key origin in %q", expr)
+		}
+		expr = expr[end+1:]
+	}
+
+	parts := strings.Split(expr, "/")
+	if len(parts) == 1 {
+		serialized, err := hex.DecodeString(expr)
+		if err != nil {
+			return nil, fmt.Errorf("malformed key %q", expr)
+		}
+		pubKey, err := btcec.ParsePubKey(serialized, btcec.S256())
+		if err != nil {
+			return nil, err
+		}
+		return &descriptorKey{pubKey: pubKey}, nil
+	}
+
+	accountKey, err := hdkeychain.NewKeyFromString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if accountKey.IsPrivate() {
+		return nil, errors.New("private keys can not be imported")
+	}
+	branch := parts[1]
+	externalBranch := fmt.Sprint(waddrmgr.ExternalBranch)
+	internalBranch := fmt.Sprint(waddrmgr.InternalBranch)
+	both := "<" + externalBranch + ";" + internalBranch + ">"
+	if len(parts) != 3 || parts[2] != "*" ||
+		(branch != externalBranch && branch != internalBranch &&
+			branch != both) {
+
+		return nil, fmt.Errorf("key %q is not an account key "+
+			"followed by a branch and wildcard", expr)
+	}
+	return &descriptorKey{accountKey: accountKey}, nil
+}
+
+// ImportedDescriptor describes what an output descriptor was imported as by
+// ImportDescriptor.
+type ImportedDescriptor struct {
+	// Account is the properties of the watching-only account created in
+	// Scope for a ranged descriptor.  It is nil otherwise.
+	Account *waddrmgr.AccountProperties
+	Scope   waddrmgr.KeyScope
+
+	// Address is the address of the key or script imported for a
+	// descriptor which is not ranged.  It is nil otherwise.
+	Address btcutil.Address
+}
+
+// ImportDescriptor imports a Bitcoin Core output descriptor, such as one
+// exported from a watching-only Core wallet.  If the descriptor has a
+// checksum, it is verified.  The supported descriptors are:
+//
+//   - pkh(KEY), sh(wpkh(KEY)) and wpkh(KEY) with an account extended public
+//     key, which are imported as a watching-only account named name into the
+//     BIP0044, BIP0049 and BIP0084 key scopes.  Both branches of the account
+//     are derived, so only one of the receive and change descriptors of an
+//     account is imported.  Change of the BIP0049 scope is paid to P2WPKH
+//     addresses rather than the nested addresses Core uses.
+//   - pkh(KEY), sh(wpkh(KEY)) and wpkh(KEY) with a public key, which are
+//     imported as a watched address of the imported account of the same
+//     scopes.
+//   - sh(multi(k,KEY,...)) with public keys, which is imported as a P2SH
+//     redeem script.
+//
+// Taproot descriptors and any other descriptor the wallet can not represent
+// are rejected with an error.
+func (w *Wallet) ImportDescriptor(desc, name string) (*ImportedDescriptor, error) {
+	if i := strings.IndexByte(desc, '#'); i != -1 {
+		checksum, err := descriptorChecksum(desc[:i])
+		if err != nil {
+			return nil, err
+		}
+		if desc[i+1:] != checksum {
+			return nil, fmt.Errorf("invalid descriptor checksum %q, "+
+				"expected %q", desc[i+1:], checksum)
+		}
+		desc = desc[:i]
+	}
+
+	fn, args, err := splitDescriptor(desc)
+	if err != nil {
+		return nil, err
+	}
+	var scope waddrmgr.KeyScope
+	switch {
+	case fn == "pkh" && len(args) == 1:
+		scope = waddrmgr.KeyScopeBIP0044
+	case fn == "wpkh" && len(args) == 1:
+		scope = waddrmgr.KeyScopeBIP0084
+	case fn == "sh" && len(args) == 1:
+		innerFn, innerArgs, err := splitDescriptor(args[0])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case innerFn == "wpkh" && len(innerArgs) == 1:
+			scope = waddrmgr.KeyScopeBIP0049Plus
+			args = innerArgs
+		case innerFn == "multi" || innerFn == "sortedmulti":
+			return w.importMultisigDescriptor(innerFn, innerArgs)
+		default:
+			return nil, fmt.Errorf("unsupported descriptor %q", desc)
+		}
+	case fn == "tr":
+		return nil, errors.New("taproot descriptors are not supported")
+	default:
+		return nil, fmt.Errorf("unsupported descriptor %q", desc)
+	}
+
+	key, err := parseDescriptorKey(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if key.pubKey != nil {
+		addrType := waddrmgr.ScopeAddrMap[scope].ExternalAddrType
+		addrStr, err := w.ImportPublicKey(key.pubKey, addrType, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := btcutil.DecodeAddress(addrStr, w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportedDescriptor{Address: addr, Scope: scope}, nil
+	}
+	props, err := w.ImportAccount(name, key.accountKey, scope, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportedDescriptor{Account: props, Scope: scope}, nil
+}
+
+// importMultisigDescriptor imports the P2SH redeem script of the arguments of
+// a multi or sortedmulti descriptor.
+func (w *Wallet) importMultisigDescriptor(fn string,
+	args []string) (*ImportedDescriptor, error) {
+
+	if len(args) < 2 {
+		return nil, fmt.Errorf("malformed %v descriptor", fn)
+	}
+	nRequired, err := strconv.Atoi(args[0])
+	if err != nil || nRequired < 1 || nRequired > len(args)-1 {
+		return nil, fmt.Errorf("invalid number of required signatures "+
+			"%q", args[0])
+	}
+
+	pubKeys := make([]*btcutil.AddressPubKey, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		key, err := parseDescriptorKey(arg)
+		if err != nil {
+			return nil, err
+		}
+		if key.pubKey == nil {
+			return nil, errors.New("ranged multisig descriptors are " +
+				"not supported")
+		}
+		pubKey, err := btcutil.NewAddressPubKey(
+			key.pubKey.SerializeCompressed(), w.chainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if fn == "sortedmulti" {
+		sort.Slice(pubKeys, func(i, j int) bool {
+			return bytes.Compare(pubKeys[i].ScriptAddress(),
+				pubKeys[j].ScriptAddress()) < 0
+		})
+	}
+
+	script, err := txscript.MultiSigScript(pubKeys, nRequired)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := w.ImportP2SHRedeemScript(script)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportedDescriptor{Address: addr}, nil
+}
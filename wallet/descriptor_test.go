@@ -0,0 +1,108 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	tests := []struct {
+		desc     string
+		checksum string
+	}{
+		{
+			desc:     "raw(deadbeef)",
+			checksum: "89f8spxm",
+		},
+		{
+			desc:     "wpkh(xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8/0/*)",
+			checksum: "wvk84d79",
+		},
+	}
+	for _, test := range tests {
+		checksum, err := descriptorChecksum(test.desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checksum != test.checksum {
+			t.Errorf("descriptor %v: expected checksum %v, got %v",
+				test.desc, test.checksum, checksum)
+		}
+	}
+
+	if _, err := descriptorChecksum("raw(é)"); err == nil {
+		t.Fatal("expected error for invalid descriptor character")
+	}
+}
+
+func TestSplitDescriptor(t *testing.T) {
+	name, args, err := splitDescriptor("sh(multi(1,[d34db33f/49'/0'/0']02aa,03bb))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "sh" || !reflect.DeepEqual(args,
+		[]string{"multi(1,[d34db33f/49'/0'/0']02aa,03bb)"}) {
+
+		t.Fatalf("unexpected split %v %v", name, args)
+	}
+
+	name, args, err = splitDescriptor(args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1", "[d34db33f/49'/0'/0']02aa", "03bb"}
+	if name != "multi" || !reflect.DeepEqual(args, expected) {
+		t.Fatalf("unexpected split %v %v", name, args)
+	}
+
+	for _, desc := range []string{"wpkh", "(02aa)", "sh(wpkh(02aa)", "pkh(02aa))"} {
+		if _, _, err := splitDescriptor(desc); err == nil {
+			t.Errorf("expected error splitting %q", desc)
+		}
+	}
+}
+
+func TestParseDescriptorKey(t *testing.T) {
+	const xpub = "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+
+	for _, expr := range []string{
+		xpub + "/0/*",
+		xpub + "/1/*",
+		"[d34db33f/84'/0'/0']" + xpub + "/<0;1>/*",
+	} {
+		key, err := parseDescriptorKey(expr)
+		if err != nil {
+			t.Fatalf("key %v: %v", expr, err)
+		}
+		if key.accountKey == nil || key.pubKey != nil {
+			t.Fatalf("key %v: expected account key", expr)
+		}
+	}
+
+	key, err := parseDescriptorKey("[d34db33f]" +
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.pubKey == nil || key.accountKey != nil {
+		t.Fatal("expected public key")
+	}
+
+	for _, expr := range []string{
+		xpub,
+		xpub + "/0",
+		xpub + "/2/*",
+		xpub + "/0'/*",
+		xpub + "/0/0/*",
+		"02aa",
+		"[d34db33f" + xpub + "/0/*",
+	} {
+		if _, err := parseDescriptorKey(expr); err == nil {
+			t.Errorf("expected error parsing key %v", expr)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+)
+
+// ErrNoFeeEstimator describes an error where a fee rate is estimated for a
+// confirmation target, but no fee estimator is set and the chain backend is
+// unable to estimate fees.
+var ErrNoFeeEstimator = errors.New("no fee estimator available")
+
+// DefaultConfTarget is the confirmation target of the fee rate estimated for
+// transactions created without a fee rate or confirmation target.
+const DefaultConfTarget = 6
+
+// FeeEstimator estimates the fee rate required for a transaction to confirm
+// within a number of blocks.  Every chain.Interface implements FeeEstimator,
+// although not every chain backend is able to estimate fees.
+type FeeEstimator interface {
+	// EstimateFeePerKb returns the fee rate, in satoshis per kilobyte,
+	// estimated to be required for a transaction to confirm within
+	// confTarget blocks.
+	EstimateFeePerKb(confTarget uint32) (btcutil.Amount, error)
+}
+
+// StaticFeeEstimator is a FeeEstimator which returns the same fee rate for
+// every confirmation target.
+type StaticFeeEstimator btcutil.Amount
+
+// EstimateFeePerKb returns the static fee rate.
+func (e StaticFeeEstimator) EstimateFeePerKb(uint32) (btcutil.Amount, error) {
+	return btcutil.Amount(e), nil
+}
+
+// SetFeeEstimator sets the fee estimator used to estimate fee rates for
+// confirmation targets.  If no fee estimator is set, or it is set to nil, the
+// chain backend is used.
+func (w *Wallet) SetFeeEstimator(estimator FeeEstimator) {
+	w.feeEstimatorMtx.Lock()
+	w.feeEstimator = estimator
+	w.feeEstimatorMtx.Unlock()
+}
+
+// EstimateFeePerKb returns the fee rate, in satoshis per kilobyte, estimated
+// to be required for a transaction to confirm within confTarget blocks.  The
+// estimate is never below the default relay fee.
+func (w *Wallet) EstimateFeePerKb(confTarget uint32) (btcutil.Amount, error) {
+	w.feeEstimatorMtx.Lock()
+	estimator := w.feeEstimator
+	w.feeEstimatorMtx.Unlock()
+
+	if estimator == nil {
+		chainClient := w.ChainClient()
+		if chainClient == nil {
+			return 0, ErrNoFeeEstimator
+		}
+		estimator = chainClient
+	}
+
+	feeSatPerKb, err := estimator.EstimateFeePerKb(confTarget)
+	if err == chain.ErrFeeEstimationUnsupported {
+		return 0, ErrNoFeeEstimator
+	}
+	if err != nil {
+		return 0, err
+	}
+	if feeSatPerKb < txrules.DefaultRelayFeePerKb {
+		feeSatPerKb = txrules.DefaultRelayFeePerKb
+	}
+	return feeSatPerKb, nil
+}
+
+// txFeeRate returns the fee rate of a created transaction.  This is the rate
+// estimated for the confirmation target set with WithConfTarget, or otherwise
+// the passed fee rate.  When neither is given, the rate estimated for the
+// DefaultConfTarget is used, or the default relay fee if fees can not be
+// estimated.
+func (w *Wallet) txFeeRate(satPerKb btcutil.Amount,
+	opts *txCreateOptions) (btcutil.Amount, error) {
+
+	switch {
+	case opts.confTarget != 0:
+		return w.EstimateFeePerKb(opts.confTarget)
+	case satPerKb != 0:
+		return satPerKb, nil
+	}
+
+	feeSatPerKb, err := w.EstimateFeePerKb(DefaultConfTarget)
+	if err != nil {
+		log.Debugf("Unable to estimate fee rate, paying the relay "+
+			"fee: %v", err)
+		return txrules.DefaultRelayFeePerKb, nil
+	}
+	return feeSatPerKb, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+)
+
+func TestEstimateFeePerKb(t *testing.T) {
+	w := &Wallet{}
+	if _, err := w.EstimateFeePerKb(6); err != ErrNoFeeEstimator {
+		t.Fatalf("expected ErrNoFeeEstimator, got %v", err)
+	}
+
+	// Estimates below the relay fee are raised to it.
+	w.SetFeeEstimator(StaticFeeEstimator(1))
+	feeRate, err := w.EstimateFeePerKb(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feeRate != txrules.DefaultRelayFeePerKb {
+		t.Fatalf("expected fee rate %v, got %v",
+			txrules.DefaultRelayFeePerKb, feeRate)
+	}
+
+	// A confirmation target overrides the fee rate of the caller.
+	w.SetFeeEstimator(StaticFeeEstimator(5e4))
+	opts := applyTxCreateOptions(nil)
+	if feeRate, _ := w.txFeeRate(2e4, opts); feeRate != 2e4 {
+		t.Fatalf("expected fee rate 2e4, got %v", feeRate)
+	}
+	opts = applyTxCreateOptions([]TxCreateOption{WithConfTarget(6)})
+	if feeRate, _ := w.txFeeRate(2e4, opts); feeRate != 5e4 {
+		t.Fatalf("expected fee rate 5e4, got %v", feeRate)
+	}
+
+	// Without a fee rate, the estimated rate is paid, or the relay fee
+	// when fees can not be estimated.
+	opts = applyTxCreateOptions(nil)
+	if feeRate, _ := w.txFeeRate(0, opts); feeRate != 5e4 {
+		t.Fatalf("expected fee rate 5e4, got %v", feeRate)
+	}
+	w.SetFeeEstimator(nil)
+	feeRate, err = w.txFeeRate(0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feeRate != txrules.DefaultRelayFeePerKb {
+		t.Fatalf("expected fee rate %v, got %v",
+			txrules.DefaultRelayFeePerKb, feeRate)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// CheckIntegrity walks the address records of the address manager and the
+// credits, unspent outputs and balance of the transaction store, and returns
+// the inconsistencies between them.  Credits must pay to an address of the
+// address manager, and must match the unspent outputs and mined balance of
+// the transaction store.
+//
+// When repair is set, the inconsistencies are repaired in the same database
+// transaction, and the Repaired field of each repaired inconsistency is set.
+// Credits which do not pay to an address of the wallet are only reported.
+func (w *Wallet) CheckIntegrity(repair bool) ([]wtxmgr.Inconsistency, error) {
+	var incs []wtxmgr.Inconsistency
+	check := func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+
+		addrs := make(map[string]struct{})
+		err := w.Manager.ForEachActiveAddress(addrmgrNs,
+			func(addr btcutil.Address) error {
+				addrs[addr.EncodeAddress()] = struct{}{}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		isRelevant := func(pkScript []byte) bool {
+			_, scriptAddrs, _, err := txscript.ExtractPkScriptAddrs(
+				pkScript, w.chainParams,
+			)
+			if err != nil {
+				return false
+			}
+			for _, addr := range scriptAddrs {
+				if _, ok := addrs[addr.EncodeAddress()]; ok {
+					return true
+				}
+			}
+			return false
+		}
+
+		incs, err = w.TxStore.CheckIntegrity(txmgrNs, isRelevant)
+		return err
+	}
+
+	var err error
+	if repair {
+		err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+			if err := check(tx); err != nil {
+				return err
+			}
+			txmgrNs := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+			return w.TxStore.Repair(txmgrNs, incs)
+		})
+	} else {
+		err = walletdb.View(w.db, check)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range incs {
+		if incs[i].Repaired {
+			log.Infof("Repaired wallet inconsistency: %v", &incs[i])
+		} else {
+			log.Warnf("Wallet inconsistency: %v", &incs[i])
+		}
+	}
+	return incs, nil
+}
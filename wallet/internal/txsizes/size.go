@@ -6,6 +6,7 @@ package txsizes
 
 import (
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 
 	h "github.com/btcsuite/btcwallet/internal/helpers"
@@ -116,8 +117,123 @@ const (
 	//   - 1 wu compact int encoding value 33
 	//   - 33 wu serialized compressed pubkey
 	RedeemP2WPKHInputWitnessWeight = 1 + 1 + 73 + 1 + 33
+
+	// P2TRPkScriptSize is the size of a transaction output script that
+	// pays to a taproot output key. It is calculated as:
+	//
+	//   - OP_1
+	//   - OP_DATA_32
+	//   - 32 bytes output key
+	P2TRPkScriptSize = 1 + 1 + 32
+
+	// P2TROutputSize is the serialize size of a transaction output with a
+	// P2TR output script. It is calculated as:
+	//
+	//   - 8 bytes output value
+	//   - 1 byte compact int encoding value 34
+	//   - 34 bytes P2TR output script
+	P2TROutputSize = 8 + 1 + P2TRPkScriptSize
+
+	// RedeemP2TRInputSize is the worst case size of a transaction input
+	// redeeming a P2TR output through the key path. It is calculated as:
+	//
+	//   - 32 bytes previous tx
+	//   - 4 bytes output index
+	//   - 1 byte compact int encoding value 0
+	//   - 0 bytes signature script
+	//   - 4 bytes sequence
+	RedeemP2TRInputSize = 32 + 4 + 1 + 4
+
+	// RedeemP2TRInputWitnessWeight is the worst case weight of a witness
+	// for spending a P2TR output through the key path. It is calculated
+	// as:
+	//
+	//   - 1 wu compact int encoding value 1 (number of items)
+	//   - 1 wu compact int encoding value 65
+	//   - 64 wu schnorr signature + 1 wu non-default sighash
+	RedeemP2TRInputWitnessWeight = 1 + 1 + 65
+
+	// P2WSHPkScriptSize is the size of a transaction output script that
+	// pays to a witness script hash. It is calculated as:
+	//
+	//   - OP_0
+	//   - OP_DATA_32
+	//   - 32 bytes script hash
+	P2WSHPkScriptSize = 1 + 1 + 32
+
+	// P2WSHOutputSize is the serialize size of a transaction output with a
+	// P2WSH output script. It is calculated as:
+	//
+	//   - 8 bytes output value
+	//   - 1 byte compact int encoding value 34
+	//   - 34 bytes P2WSH output script
+	P2WSHOutputSize = 8 + 1 + P2WSHPkScriptSize
+
+	// RedeemP2WSHInputSize is the size of a transaction input redeeming a
+	// P2WSH output. It is calculated as:
+	//
+	//   - 32 bytes previous tx
+	//   - 4 bytes output index
+	//   - 1 byte compact int encoding value 0
+	//   - 0 bytes signature script
+	//   - 4 bytes sequence
+	RedeemP2WSHInputSize = 32 + 4 + 1 + 4
 )
 
+// RedeemP2WSHMultisigWitnessWeight returns the worst case weight of a witness
+// spending a P2WSH output with a multisig witness script of scriptSize bytes
+// requiring nRequired signatures. It is calculated as:
+//
+//   - compact int encoding the number of items
+//   - 1 wu empty item consumed by OP_CHECKMULTISIG
+//   - nRequired times 1 wu compact int encoding value 73 and 72 wu DER
+//     signature + 1 wu sighash
+//   - compact int encoding the script size
+//   - the witness script
+func RedeemP2WSHMultisigWitnessWeight(nRequired, scriptSize int) int {
+	return wire.VarIntSerializeSize(uint64(nRequired+2)) + 1 +
+		nRequired*(1+73) +
+		wire.VarIntSerializeSize(uint64(scriptSize)) + scriptSize
+}
+
+// EstimateP2WSHMultisigVirtualSize returns a worst case virtual size estimate
+// for a signed transaction that spends numIns P2WSH multisig outputs, each
+// with a witness of inputWitnessWeight weight units, and contains each
+// transaction output from txOuts. The estimate is incremented for an
+// additional P2WSH change output if addChangeOutput is true.
+func EstimateP2WSHMultisigVirtualSize(numIns, inputWitnessWeight int,
+	txOuts []*wire.TxOut, addChangeOutput bool) int {
+
+	changeSize := 0
+	outputCount := len(txOuts)
+	if addChangeOutput {
+		changeSize = P2WSHOutputSize
+		outputCount++
+	}
+
+	baseSize := 8 +
+		wire.VarIntSerializeSize(uint64(numIns)) +
+		wire.VarIntSerializeSize(uint64(outputCount)) +
+		numIns*RedeemP2WSHInputSize +
+		h.SumOutputSerializeSizes(txOuts) +
+		changeSize
+
+	// Additional 2 weight units for segwit marker + flag.
+	witnessWeight := 2 + numIns*inputWitnessWeight
+
+	// We add 3 to the witness weight to make sure the result is always
+	// rounded up.
+	return baseSize + (witnessWeight+3)/blockchain.WitnessScaleFactor
+}
+
+// IsP2TRPkScript returns whether pkScript pays to a taproot output key, which
+// is a version 1 witness program of 32 bytes.
+func IsP2TRPkScript(pkScript []byte) bool {
+	return len(pkScript) == P2TRPkScriptSize &&
+		pkScript[0] == txscript.OP_1 &&
+		pkScript[1] == txscript.OP_DATA_32
+}
+
 // EstimateSerializeSize returns a worst case serialize size estimate for a
 // signed transaction that spends inputCount number of compressed P2PKH outputs
 // and contains each transaction output from txOuts.  The estimated size is
@@ -139,12 +255,12 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 }
 
 // EstimateVirtualSize returns a worst case virtual size estimate for a
-// signed transaction that spends the given number of P2PKH, P2WPKH and
-// (nested) P2SH-P2WPKH outputs, and contains each transaction output
-// from txOuts. The estimate is incremented for an additional P2PKH
+// signed transaction that spends the given number of P2PKH, P2TR (key path),
+// P2WPKH and (nested) P2SH-P2WPKH outputs, and contains each transaction
+// output from txOuts. The estimate is incremented for an additional P2WPKH
 // change output if addChangeOutput is true.
-func EstimateVirtualSize(numP2PKHIns, numP2WPKHIns, numNestedP2WPKHIns int,
-	txOuts []*wire.TxOut, addChangeOutput bool) int {
+func EstimateVirtualSize(numP2PKHIns, numP2TRIns, numP2WPKHIns,
+	numNestedP2WPKHIns int, txOuts []*wire.TxOut, addChangeOutput bool) int {
 	changeSize := 0
 	outputCount := len(txOuts)
 	if addChangeOutput {
@@ -158,9 +274,11 @@ func EstimateVirtualSize(numP2PKHIns, numP2WPKHIns, numNestedP2WPKHIns int,
 	// the size out the serialized outputs and change.
 	baseSize := 8 +
 		wire.VarIntSerializeSize(
-			uint64(numP2PKHIns+numP2WPKHIns+numNestedP2WPKHIns)) +
+			uint64(numP2PKHIns+numP2TRIns+numP2WPKHIns+
+				numNestedP2WPKHIns)) +
 		wire.VarIntSerializeSize(uint64(len(txOuts))) +
 		numP2PKHIns*RedeemP2PKHInputSize +
+		numP2TRIns*RedeemP2TRInputSize +
 		numP2WPKHIns*RedeemP2WPKHInputSize +
 		numNestedP2WPKHIns*RedeemNestedP2WPKHInputSize +
 		h.SumOutputSerializeSizes(txOuts) +
@@ -169,11 +287,13 @@ func EstimateVirtualSize(numP2PKHIns, numP2WPKHIns, numNestedP2WPKHIns int,
 	// If this transaction has any witness inputs, we must count the
 	// witness data.
 	witnessWeight := 0
-	if numP2WPKHIns+numNestedP2WPKHIns > 0 {
+	if numP2TRIns+numP2WPKHIns+numNestedP2WPKHIns > 0 {
 		// Additional 2 weight units for segwit marker + flag.
 		witnessWeight = 2 +
 			wire.VarIntSerializeSize(
-				uint64(numP2WPKHIns+numNestedP2WPKHIns)) +
+				uint64(numP2TRIns+numP2WPKHIns+
+					numNestedP2WPKHIns)) +
+			numP2TRIns*RedeemP2TRInputWitnessWeight +
 			numP2WPKHIns*RedeemP2WPKHInputWitnessWeight +
 			numNestedP2WPKHIns*RedeemP2WPKHInputWitnessWeight
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	. "github.com/btcsuite/btcwallet/wallet/internal/txsizes"
 )
@@ -70,6 +71,7 @@ func TestEstimateVirtualSize(t *testing.T) {
 		p2wpkhIns       int
 		nestedp2wpkhIns int
 		p2pkhIns        int
+		p2trIns         int
 		change          bool
 		result          int
 	}
@@ -156,6 +158,22 @@ func TestEstimateVirtualSize(t *testing.T) {
 			p2pkhIns: 1,
 			result:   227,
 		},
+		{
+			// Spending one P2TR output through the key path to one
+			// P2TR output.  A 64 byte signature results in 111
+			// vbytes, the estimate allows for a sighash flag.
+			tx: func() (*wire.MsgTx, error) {
+				pkScript := make([]byte, P2TRPkScriptSize)
+				pkScript[0] = txscript.OP_1
+				pkScript[1] = txscript.OP_DATA_32
+
+				tx := wire.NewMsgTx(wire.TxVersion)
+				tx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+				return tx, nil
+			},
+			p2trIns: 1,
+			result:  112,
+		},
 	}
 
 	for _, test := range tests {
@@ -164,8 +182,9 @@ func TestEstimateVirtualSize(t *testing.T) {
 			t.Fatalf("unable to get test tx: %v", err)
 		}
 
-		est := EstimateVirtualSize(test.p2pkhIns, test.p2wpkhIns,
-			test.nestedp2wpkhIns, tx.TxOut, test.change)
+		est := EstimateVirtualSize(test.p2pkhIns, test.p2trIns,
+			test.p2wpkhIns, test.nestedp2wpkhIns, tx.TxOut,
+			test.change)
 
 		if est != test.result {
 			t.Fatalf("expected estimated vsize to be %d, "+
@@ -173,3 +192,20 @@ func TestEstimateVirtualSize(t *testing.T) {
 		}
 	}
 }
+
+func TestEstimateP2WSHMultisigVirtualSize(t *testing.T) {
+	// A 2-of-3 multisig witness script of compressed keys is 105 bytes.
+	witnessWeight := RedeemP2WSHMultisigWitnessWeight(2, 105)
+	if witnessWeight != 256 {
+		t.Fatalf("expected witness weight 256, got %d", witnessWeight)
+	}
+
+	// Spending one output to a P2WPKH output and a P2WSH change output.
+	pkScript := make([]byte, P2WPKHPkScriptSize)
+	txOuts := []*wire.TxOut{wire.NewTxOut(1e8, pkScript)}
+	est := EstimateP2WSHMultisigVirtualSize(1, witnessWeight, txOuts, true)
+	if est != 190 {
+		t.Fatalf("expected estimated vsize to be 190, instead got %d",
+			est)
+	}
+}
@@ -12,15 +12,31 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/btcsuite/btcwallet/internal/prompt"
 	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet/bip39"
 	"github.com/btcsuite/btcwallet/walletdb"
 )
 
 const (
 	walletDbName = "wallet.db"
+
+	// DefaultDatabaseDriver is the walletdb driver of the databases
+	// created and opened by a Loader unless another driver is set with
+	// SetDatabaseDriver.
+	DefaultDatabaseDriver = "bdb"
 )
 
+// DatabaseFileName returns the name of the wallet database file in the
+// database directory of a Loader using the walletdb driver dbType.
+func DatabaseFileName(dbType string) string {
+	if dbType == DefaultDatabaseDriver {
+		return walletDbName
+	}
+	return "wallet." + dbType
+}
+
 var (
 	// ErrLoaded describes the error condition of attempting to load or
 	// create a wallet when the loader has already done so.
@@ -47,6 +63,10 @@ type Loader struct {
 	chainParams    *chaincfg.Params
 	dbDirPath      string
 	recoveryWindow uint32
+	keyScopes      map[waddrmgr.KeyScope]waddrmgr.ScopeAddrSchema
+	dbDriver       string
+	dbDriverArgs   []interface{}
+	dbObserver     walletdb.TxObserver
 	wallet         *Wallet
 	db             walletdb.DB
 	mu             sync.Mutex
@@ -62,7 +82,90 @@ func NewLoader(chainParams *chaincfg.Params, dbDirPath string,
 		chainParams:    chainParams,
 		dbDirPath:      dbDirPath,
 		recoveryWindow: recoveryWindow,
+		dbDriver:       DefaultDatabaseDriver,
+	}
+}
+
+// SetDatabaseDriver sets the walletdb driver of the databases created and
+// opened by the loader, such as "sqlite" for the walletdb/sqlite package.  The
+// driver must be registered with walletdb, and set before the wallet is
+// loaded.
+//
+// The driver is passed the path of the database file in the database directory
+// of the loader, unless args are given.  Drivers of databases not stored in a
+// file, such as "postgres", are passed args instead, and must fail to open
+// missing databases with walletdb.ErrDbDoesNotExist.
+func (l *Loader) SetDatabaseDriver(dbType string, args ...interface{}) {
+	l.mu.Lock()
+	l.dbDriver = dbType
+	l.dbDriverArgs = args
+	l.mu.Unlock()
+}
+
+// SetDatabaseObserver sets a function called with the duration of every
+// transaction of the wallet database, as described by walletdb.Observe.  The
+// observer must be set before the wallet is loaded.
+func (l *Loader) SetDatabaseObserver(observe walletdb.TxObserver) {
+	l.mu.Lock()
+	l.dbObserver = observe
+	l.mu.Unlock()
+}
+
+// observeDB returns the database observed by the observer of the loader, if
+// any.  Requires mutex to be locked.
+func (l *Loader) observeDB(db walletdb.DB) walletdb.DB {
+	if l.dbObserver == nil {
+		return db
+	}
+	return walletdb.Observe(db, l.dbObserver)
+}
+
+// dbPath returns the path of the wallet database.
+func (l *Loader) dbPath() string {
+	return filepath.Join(l.dbDirPath, DatabaseFileName(l.dbDriver))
+}
+
+// dbArgs returns the arguments of the driver creating and opening the wallet
+// database.
+func (l *Loader) dbArgs() []interface{} {
+	if len(l.dbDriverArgs) != 0 {
+		return l.dbDriverArgs
+	}
+	return []interface{}{l.dbPath()}
+}
+
+// walletExists returns whether the wallet database exists.  Requires mutex to
+// be locked.
+func (l *Loader) walletExists() (bool, error) {
+	if len(l.dbDriverArgs) == 0 {
+		return fileExists(l.dbPath())
+	}
+
+	db, err := walletdb.Open(l.dbDriver, l.dbDriverArgs...)
+	switch {
+	case err == walletdb.ErrDbDoesNotExist:
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return true, db.Close()
+}
+
+// RegisterKeyScope registers a custom key scope, whose addresses use the
+// address schema addrSchema, in addition to the default key scopes of the
+// wallet created or opened by the loader.  The scope is created when the
+// wallet is first unlocked.  Scopes must be registered before the wallet is
+// loaded.
+func (l *Loader) RegisterKeyScope(scope waddrmgr.KeyScope,
+	addrSchema waddrmgr.ScopeAddrSchema) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.keyScopes == nil {
+		l.keyScopes = make(map[waddrmgr.KeyScope]waddrmgr.ScopeAddrSchema)
 	}
+	l.keyScopes[scope] = addrSchema
 }
 
 // onLoaded executes each added callback and prevents loader from loading any
@@ -94,9 +197,56 @@ func (l *Loader) RunAfterLoad(fn func(*Wallet)) {
 
 // CreateNewWallet creates a new wallet using the provided public and private
 // passphrases.  The seed is optional.  If non-nil, addresses are derived from
-// this seed.  If nil, a secure random seed is generated.
+// this seed.  If nil, a secure random seed is generated.  The options are
+// passed to Create.
 func (l *Loader) CreateNewWallet(pubPassphrase, privPassphrase, seed []byte,
-	bday time.Time) (*Wallet, error) {
+	bday time.Time, opts ...CreateOption) (*Wallet, error) {
+
+	return l.createNewWallet(pubPassphrase, func(db walletdb.DB) error {
+		return Create(
+			db, pubPassphrase, privPassphrase, seed, l.chainParams,
+			bday, opts...,
+		)
+	})
+}
+
+// CreateNewWalletFromMnemonic creates a new wallet in the same way as
+// CreateNewWallet, deriving the seed from a BIP0039 mnemonic sentence and its
+// optional passphrase.  This restores the HD keys of wallets created by other
+// software which back up their seed as a mnemonic.
+func (l *Loader) CreateNewWalletFromMnemonic(pubPassphrase,
+	privPassphrase []byte, mnemonic, mnemonicPassphrase string,
+	bday time.Time, opts ...CreateOption) (*Wallet, error) {
+
+	seed, err := bip39.NewSeed(mnemonic, mnemonicPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	return l.CreateNewWallet(
+		pubPassphrase, privPassphrase, seed, bday, opts...,
+	)
+}
+
+// CreateNewWatchingOnlyWallet creates a new watching-only wallet using the
+// provided public passphrase and account extended public keys, as described
+// by CreateWatchingOnly.  The wallet is loaded and started in the same way as
+// by CreateNewWallet.
+func (l *Loader) CreateNewWatchingOnlyWallet(pubPassphrase []byte,
+	accountPubKeys map[waddrmgr.KeyScope]*hdkeychain.ExtendedKey,
+	bday time.Time, opts ...CreateOption) (*Wallet, error) {
+
+	return l.createNewWallet(pubPassphrase, func(db walletdb.DB) error {
+		return CreateWatchingOnly(
+			db, pubPassphrase, accountPubKeys, l.chainParams, bday,
+			opts...,
+		)
+	})
+}
+
+// createNewWallet creates a new wallet database, initializes it with create,
+// and loads and starts the created wallet.
+func (l *Loader) createNewWallet(pubPassphrase []byte,
+	create func(walletdb.DB) error) (*Wallet, error) {
 
 	defer l.mu.Unlock()
 	l.mu.Lock()
@@ -105,8 +255,7 @@ func (l *Loader) CreateNewWallet(pubPassphrase, privPassphrase, seed []byte,
 		return nil, ErrLoaded
 	}
 
-	dbPath := filepath.Join(l.dbDirPath, walletDbName)
-	exists, err := fileExists(dbPath)
+	exists, err := l.walletExists()
 	if err != nil {
 		return nil, err
 	}
@@ -114,26 +263,28 @@ func (l *Loader) CreateNewWallet(pubPassphrase, privPassphrase, seed []byte,
 		return nil, ErrExists
 	}
 
-	// Create the wallet database backed by bolt db.
+	// Create the wallet database of the loader's driver.
 	err = os.MkdirAll(l.dbDirPath, 0700)
 	if err != nil {
 		return nil, err
 	}
-	db, err := walletdb.Create("bdb", dbPath)
+	db, err := walletdb.Create(l.dbDriver, l.dbArgs()...)
 	if err != nil {
 		return nil, err
 	}
+	db = l.observeDB(db)
 
 	// Initialize the newly created database for the wallet before opening.
-	err = Create(
-		db, pubPassphrase, privPassphrase, seed, l.chainParams, bday,
-	)
+	err = create(db)
 	if err != nil {
 		return nil, err
 	}
 
 	// Open the newly-created wallet.
-	w, err := Open(db, pubPassphrase, nil, l.chainParams, l.recoveryWindow)
+	w, err := Open(
+		db, pubPassphrase, nil, l.chainParams, l.recoveryWindow,
+		l.keyScopes,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -154,6 +305,23 @@ func noConsole() ([]byte, error) {
 // standard input prompts may be used during wallet upgrades, setting
 // canConsolePrompt will enables these prompts.
 func (l *Loader) OpenExistingWallet(pubPassphrase []byte, canConsolePrompt bool) (*Wallet, error) {
+	return l.openExistingWallet(pubPassphrase, canConsolePrompt, false)
+}
+
+// OpenExistingWalletReadOnly opens the wallet from the loader's wallet database
+// in read-only mode, so that the database is not locked against another
+// process writing to it if the database driver supports it, and wallet
+// upgrades and all changes to the wallet fail.  This is intended for
+// monitoring tools querying the balances and history of a running wallet.
+func (l *Loader) OpenExistingWalletReadOnly(pubPassphrase []byte) (*Wallet, error) {
+	return l.openExistingWallet(pubPassphrase, false, true)
+}
+
+// openExistingWallet opens the wallet from the loader's wallet database,
+// optionally in read-only mode.
+func (l *Loader) openExistingWallet(pubPassphrase []byte, canConsolePrompt,
+	readOnly bool) (*Wallet, error) {
+
 	defer l.mu.Unlock()
 	l.mu.Lock()
 
@@ -166,13 +334,18 @@ func (l *Loader) OpenExistingWallet(pubPassphrase []byte, canConsolePrompt bool)
 		return nil, err
 	}
 
-	// Open the database using the boltdb backend.
-	dbPath := filepath.Join(l.dbDirPath, walletDbName)
-	db, err := walletdb.Open("bdb", dbPath)
+	// Open the database using the loader's driver.
+	args := l.dbArgs()
+	if readOnly {
+		args = append(args[:len(args):len(args)],
+			walletdb.OpenOptions{ReadOnly: true})
+	}
+	db, err := walletdb.Open(l.dbDriver, args...)
 	if err != nil {
 		log.Errorf("Failed to open database: %v", err)
 		return nil, err
 	}
+	db = l.observeDB(db)
 
 	var cbs *waddrmgr.OpenCallbacks
 	if canConsolePrompt {
@@ -186,7 +359,10 @@ func (l *Loader) OpenExistingWallet(pubPassphrase []byte, canConsolePrompt bool)
 			ObtainPrivatePass: noConsole,
 		}
 	}
-	w, err := Open(db, pubPassphrase, cbs, l.chainParams, l.recoveryWindow)
+	w, err := Open(
+		db, pubPassphrase, cbs, l.chainParams, l.recoveryWindow,
+		l.keyScopes,
+	)
 	if err != nil {
 		// If opening the wallet fails (e.g. because of wrong
 		// passphrase), we must close the backing database to
@@ -203,11 +379,13 @@ func (l *Loader) OpenExistingWallet(pubPassphrase []byte, canConsolePrompt bool)
 	return w, nil
 }
 
-// WalletExists returns whether a file exists at the loader's database path.
+// WalletExists returns whether a file exists at the loader's database path, or
+// whether the database exists for drivers given arguments other than the path.
 // This may return an error for unexpected I/O failures.
 func (l *Loader) WalletExists() (bool, error) {
-	dbPath := filepath.Join(l.dbDirPath, walletDbName)
-	return fileExists(dbPath)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.walletExists()
 }
 
 // LoadedWallet returns the loaded wallet, if any, and a bool for whether the
@@ -0,0 +1,154 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/chain"
+)
+
+// MempoolAcceptTester is implemented by chain backends able to test whether a
+// transaction would be accepted to their mempool without broadcasting it.  The
+// bitcoind chain backend implements MempoolAcceptTester.
+type MempoolAcceptTester interface {
+	TestMempoolAccept(*wire.MsgTx) (*chain.MempoolAcceptResult, error)
+}
+
+// TxRejectCode describes the mempool policy a transaction was rejected by.
+type TxRejectCode uint8
+
+// These constants describe why a transaction was rejected.
+const (
+	// TxRejectOther indicates a reject reason not described by any other
+	// code.  The reason reported by the chain backend describes it.
+	TxRejectOther TxRejectCode = iota
+
+	// TxRejectFeeTooLow indicates the fee of the transaction is below the
+	// minimum relay or mempool fee, or too low to replace a transaction.
+	TxRejectFeeTooLow
+
+	// TxRejectNonStandard indicates the transaction, or one of its
+	// scripts, is not standard.
+	TxRejectNonStandard
+
+	// TxRejectTooLongMempoolChain indicates the transaction has too many
+	// unconfirmed ancestors or descendants.
+	TxRejectTooLongMempoolChain
+
+	// TxRejectConflict indicates the transaction double spends an output
+	// spent by a mempool transaction.
+	TxRejectConflict
+
+	// TxRejectMissingInputs indicates the outputs spent by the
+	// transaction are unknown or already spent in the block chain.
+	TxRejectMissingInputs
+)
+
+var txRejectCodeStrings = [...]string{
+	TxRejectOther:               "other",
+	TxRejectFeeTooLow:           "fee too low",
+	TxRejectNonStandard:         "non-standard",
+	TxRejectTooLongMempoolChain: "too-long mempool chain",
+	TxRejectConflict:            "mempool conflict",
+	TxRejectMissingInputs:       "missing inputs",
+}
+
+// String returns the TxRejectCode as a human-readable string.
+func (c TxRejectCode) String() string {
+	if int(c) < len(txRejectCodeStrings) {
+		return txRejectCodeStrings[c]
+	}
+	return fmt.Sprintf("TxRejectCode(%d)", c)
+}
+
+// TxRejectedError describes a transaction which was not published as it would
+// be rejected by the mempool policy of the chain backend.
+type TxRejectedError struct {
+	Code   TxRejectCode
+	Reason string // reported by the chain backend
+}
+
+// Error satisifies the error interface.
+func (e *TxRejectedError) Error() string {
+	return fmt.Sprintf("transaction rejected by mempool policy (%v): %s",
+		e.Code, e.Reason)
+}
+
+// txRejectCode returns the TxRejectCode describing a bitcoind reject reason.
+func txRejectCode(reason string) TxRejectCode {
+	switch {
+	case strings.Contains(reason, "fee not met"),
+		strings.Contains(reason, "insufficient fee"),
+		strings.Contains(reason, "min relay fee"):
+		return TxRejectFeeTooLow
+	case strings.Contains(reason, "too-long-mempool-chain"):
+		return TxRejectTooLongMempoolChain
+	case strings.Contains(reason, "txn-mempool-conflict"):
+		return TxRejectConflict
+	case strings.Contains(reason, "missing-inputs"),
+		strings.Contains(reason, "Missing inputs"),
+		strings.Contains(reason, "inputs-missingorspent"):
+		return TxRejectMissingInputs
+	case strings.Contains(reason, "nonstandard"),
+		strings.Contains(reason, "non-mandatory-script-verify-flag"),
+		strings.Contains(reason, "scriptpubkey"),
+		strings.Contains(reason, "scriptsig"),
+		strings.Contains(reason, "dust"),
+		strings.Contains(reason, "tx-size"),
+		strings.Contains(reason, "multi-op-return"),
+		strings.Contains(reason, "bare-multisig"),
+		reason == "version":
+		return TxRejectNonStandard
+	default:
+		return TxRejectOther
+	}
+}
+
+// SetTestMempoolAccept sets whether transactions are tested against the
+// mempool policy of the chain backend before they are published, so that
+// policy rejections are returned as a TxRejectedError.  Transactions are only
+// tested with chain backends implementing MempoolAcceptTester.
+func (w *Wallet) SetTestMempoolAccept(test bool) {
+	w.testMempoolAcceptMtx.Lock()
+	w.testMempoolAccept = test
+	w.testMempoolAcceptMtx.Unlock()
+}
+
+// checkMempoolAccept tests whether tx would be accepted to the mempool of
+// chainClient, when enabled with SetTestMempoolAccept, and returns a
+// TxRejectedError if it would be rejected.  Transactions are published without
+// being tested when the test itself fails, such as with backends which do not
+// support testing transactions.
+func (w *Wallet) checkMempoolAccept(chainClient chain.Interface,
+	tx *wire.MsgTx) error {
+
+	w.testMempoolAcceptMtx.Lock()
+	test := w.testMempoolAccept
+	w.testMempoolAcceptMtx.Unlock()
+	if !test {
+		return nil
+	}
+	tester, ok := chainClient.(MempoolAcceptTester)
+	if !ok {
+		return nil
+	}
+
+	result, err := tester.TestMempoolAccept(tx)
+	if err != nil {
+		log.Debugf("Unable to test mempool acceptance of transaction "+
+			"%v, publishing it untested: %v", tx.TxHash(), err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	return &TxRejectedError{
+		Code:   txRejectCode(result.RejectReason),
+		Reason: result.RejectReason,
+	}
+}
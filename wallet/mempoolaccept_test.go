@@ -0,0 +1,131 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/chain"
+)
+
+// mockMempoolAcceptClient is a mockChainClient testing transactions against
+// its mempool policy.
+type mockMempoolAcceptClient struct {
+	*mockChainClient
+	result *chain.MempoolAcceptResult
+	err    error
+	tested int
+}
+
+func (c *mockMempoolAcceptClient) TestMempoolAccept(
+	*wire.MsgTx) (*chain.MempoolAcceptResult, error) {
+
+	c.tested++
+	return c.result, c.err
+}
+
+// TestCheckMempoolAccept ensures that transactions which would be rejected by
+// the mempool policy of the backend are not published, and that transactions
+// are published untested when the test fails.
+func TestCheckMempoolAccept(t *testing.T) {
+	tests := []struct {
+		name      string
+		disabled  bool
+		result    *chain.MempoolAcceptResult
+		err       error
+		published bool
+		code      TxRejectCode
+	}{
+		{
+			name:      "allowed",
+			result:    &chain.MempoolAcceptResult{Allowed: true},
+			published: true,
+		},
+		{
+			name: "rejected",
+			result: &chain.MempoolAcceptResult{
+				RejectReason: "min relay fee not met",
+			},
+			code: TxRejectFeeTooLow,
+		},
+		{
+			name:      "unsupported",
+			err:       errors.New("Method not found"),
+			published: true,
+		},
+		{
+			name:     "disabled",
+			disabled: true,
+			result: &chain.MempoolAcceptResult{
+				RejectReason: "min relay fee not met",
+			},
+			published: true,
+		},
+	}
+	for i, test := range tests {
+		w, chainClient, cleanup := testWallet(t)
+		tester := &mockMempoolAcceptClient{
+			mockChainClient: chainClient,
+			result:          test.result,
+			err:             test.err,
+		}
+		w.chainClient = tester
+		w.SetTestMempoolAccept(!test.disabled)
+
+		tx := testTx(wire.OutPoint{Hash: chainhash.Hash{byte(i)}}, 1e6)
+		txid, err := w.publishTransaction(tx)
+		published := len(chainClient.sentTxs()) == 1
+		recorded := len(unminedTxHashes(t, w)) == 1
+		cleanup()
+
+		if test.disabled != (tester.tested == 0) {
+			t.Errorf("%s: transaction tested %d times", test.name,
+				tester.tested)
+		}
+		if published != test.published || recorded != test.published {
+			t.Errorf("%s: expected published %v, got published %v "+
+				"and recorded %v", test.name, test.published,
+				published, recorded)
+		}
+		if test.published {
+			if err != nil || txid == nil || *txid != tx.TxHash() {
+				t.Errorf("%s: unexpected publish result %v, %v",
+					test.name, txid, err)
+			}
+			continue
+		}
+		rejected, ok := err.(*TxRejectedError)
+		if !ok || rejected.Code != test.code {
+			t.Errorf("%s: expected rejection %v, got %v", test.name,
+				test.code, err)
+		}
+	}
+}
+
+func TestTxRejectCode(t *testing.T) {
+	tests := []struct {
+		reason string
+		code   TxRejectCode
+	}{
+		{"min relay fee not met, 100 < 141", TxRejectFeeTooLow},
+		{"insufficient fee, rejecting replacement", TxRejectFeeTooLow},
+		{"too-long-mempool-chain, too many descendants",
+			TxRejectTooLongMempoolChain},
+		{"txn-mempool-conflict", TxRejectConflict},
+		{"missing-inputs", TxRejectMissingInputs},
+		{"dust", TxRejectNonStandard},
+		{"version", TxRejectNonStandard},
+		{"bad-txns-inputs-duplicate", TxRejectOther},
+	}
+	for _, test := range tests {
+		if code := txRejectCode(test.reason); code != test.code {
+			t.Errorf("reason %q: expected %v, got %v", test.reason,
+				test.code, code)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+var (
+	// ErrInvalidWalletName describes the error condition of loading a
+	// named wallet with a name which is empty or is not a single path
+	// element of letters, digits, and the characters "-", "_", and ".".
+	ErrInvalidWalletName = errors.New("invalid wallet name")
+
+	// ErrNamedWalletsUnsupported describes the error condition of loading
+	// a named wallet when the database driver of the default wallet does
+	// not store databases in files, so there is no directory to store the
+	// named wallets in.
+	ErrNamedWalletsUnsupported = errors.New("named wallets require a " +
+		"database driver storing wallets in files")
+)
+
+// MultiLoader hosts named wallets in addition to the wallet of a default
+// Loader, so that a single process and its RPC servers may serve multiple
+// wallets.  Each named wallet is stored in the subdirectory of the wallets
+// directory with its name, and is created and opened with the chain parameters,
+// recovery window, database driver and observer, and key scopes of the default
+// loader.  The default wallet continues to be loaded with the default loader
+// and is identified by the empty name.
+//
+// MultiLoader is safe for concurrent access.
+type MultiLoader struct {
+	defaultLoader *Loader
+	walletsDir    string
+	callbacks     []func(string, *Wallet)
+	loaders       map[string]*Loader
+	mu            sync.Mutex
+}
+
+// NewMultiLoader constructs a MultiLoader storing named wallets in walletsDir,
+// which should differ from the database directory of defaultLoader.
+func NewMultiLoader(defaultLoader *Loader, walletsDir string) *MultiLoader {
+	return &MultiLoader{
+		defaultLoader: defaultLoader,
+		walletsDir:    walletsDir,
+		loaders:       make(map[string]*Loader),
+	}
+}
+
+// checkWalletName returns ErrInvalidWalletName unless name may name a wallet
+// directory.
+func checkWalletName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return ErrInvalidWalletName
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z',
+			c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return ErrInvalidWalletName
+		}
+	}
+	return nil
+}
+
+// newLoader returns a loader of the named wallet, configured like the default
+// loader.
+func (m *MultiLoader) newLoader(name string) (*Loader, error) {
+	if err := checkWalletName(name); err != nil {
+		return nil, err
+	}
+
+	d := m.defaultLoader
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.dbDriverArgs) != 0 {
+		return nil, ErrNamedWalletsUnsupported
+	}
+	l := NewLoader(d.chainParams, filepath.Join(m.walletsDir, name),
+		d.recoveryWindow)
+	l.dbDriver = d.dbDriver
+	l.dbObserver = d.dbObserver
+	if len(d.keyScopes) != 0 {
+		l.keyScopes = make(map[waddrmgr.KeyScope]waddrmgr.ScopeAddrSchema,
+			len(d.keyScopes))
+		for scope, addrSchema := range d.keyScopes {
+			l.keyScopes[scope] = addrSchema
+		}
+	}
+	return l, nil
+}
+
+// onLoaded executes each added callback for the named wallet and records its
+// loader.  Requires mutex to be locked.
+func (m *MultiLoader) onLoaded(name string, l *Loader, w *Wallet) {
+	for _, fn := range m.callbacks {
+		fn(name, w)
+	}
+	m.loaders[name] = l
+}
+
+// RunAfterLoad adds a function to be executed when the multiloader creates or
+// opens a named wallet, and executes it for each named wallet which is already
+// loaded.  The default wallet is not passed to the function, and callbacks
+// should instead be added to the default loader.  Functions are executed in the
+// order they are added, and must not call methods of the multiloader.
+func (m *MultiLoader) RunAfterLoad(fn func(name string, w *Wallet)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.callbacks = append(m.callbacks, fn)
+	for _, name := range m.names() {
+		w, _ := m.loaders[name].LoadedWallet()
+		fn(name, w)
+	}
+}
+
+// CreateWallet creates and loads a new named wallet in the same way as the
+// CreateNewWallet method of Loader.  ErrLoaded is returned if a wallet of the
+// name is already loaded, and ErrExists if it was created before.
+func (m *MultiLoader) CreateWallet(name string, pubPassphrase, privPassphrase,
+	seed []byte, bday time.Time, opts ...CreateOption) (*Wallet, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.loaders[name]; ok {
+		return nil, ErrLoaded
+	}
+	l, err := m.newLoader(name)
+	if err != nil {
+		return nil, err
+	}
+	w, err := l.CreateNewWallet(pubPassphrase, privPassphrase, seed,
+		bday, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.onLoaded(name, l, w)
+	return w, nil
+}
+
+// OpenWallet opens and loads an existing named wallet with its public
+// passphrase.  ErrLoaded is returned if a wallet of the name is already loaded,
+// and walletdb.ErrDbDoesNotExist if no wallet of the name was created.
+func (m *MultiLoader) OpenWallet(name string, pubPassphrase []byte) (*Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.loaders[name]; ok {
+		return nil, ErrLoaded
+	}
+	l, err := m.newLoader(name)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := l.WalletExists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+	w, err := l.OpenExistingWallet(pubPassphrase, false)
+	if err != nil {
+		return nil, err
+	}
+	m.onLoaded(name, l, w)
+	return w, nil
+}
+
+// WalletExists returns whether a named wallet was created, or whether the
+// default wallet exists when name is empty.
+func (m *MultiLoader) WalletExists(name string) (bool, error) {
+	if name == "" {
+		return m.defaultLoader.WalletExists()
+	}
+	l, err := m.newLoader(name)
+	if err != nil {
+		return false, err
+	}
+	return l.WalletExists()
+}
+
+// UnloadWallet stops the loaded named wallet and closes its database.  This
+// returns ErrNotLoaded if no wallet of the name is loaded.  The default wallet
+// must be unloaded with the default loader.
+func (m *MultiLoader) UnloadWallet(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.loaders[name]
+	if !ok {
+		return ErrNotLoaded
+	}
+	if err := l.UnloadWallet(); err != nil {
+		return err
+	}
+	delete(m.loaders, name)
+	return nil
+}
+
+// UnloadWallets unloads every named wallet, returning the first error
+// encountered.
+func (m *MultiLoader) UnloadWallets() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, name := range m.names() {
+		err := m.loaders[name].UnloadWallet()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		delete(m.loaders, name)
+	}
+	return firstErr
+}
+
+// LoadedWallet returns the loaded wallet of the name, or the wallet of the
+// default loader when name is empty, and whether the wallet is loaded.
+func (m *MultiLoader) LoadedWallet(name string) (*Wallet, bool) {
+	if name == "" {
+		return m.defaultLoader.LoadedWallet()
+	}
+
+	m.mu.Lock()
+	l, ok := m.loaders[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return l.LoadedWallet()
+}
+
+// LoadedWallets returns the sorted names of the loaded wallets, which begin
+// with the empty name of the default wallet when it is loaded.
+func (m *MultiLoader) LoadedWallets() []string {
+	names := make([]string, 0, 1)
+	if _, ok := m.defaultLoader.LoadedWallet(); ok {
+		names = append(names, "")
+	}
+	m.mu.Lock()
+	names = append(names, m.names()...)
+	m.mu.Unlock()
+	return names
+}
+
+// names returns the sorted names of the loaded named wallets.  Requires mutex
+// to be locked.
+func (m *MultiLoader) names() []string {
+	names := make([]string, 0, len(m.loaders))
+	for name := range m.loaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+func TestCheckWalletName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"savings", true},
+		{"cold-storage_2.old", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../default", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"with space", false},
+	}
+	for _, test := range tests {
+		err := checkWalletName(test.name)
+		if test.valid && err != nil {
+			t.Errorf("wallet name %q rejected: %v", test.name, err)
+		}
+		if !test.valid && err != ErrInvalidWalletName {
+			t.Errorf("wallet name %q: expected ErrInvalidWalletName, "+
+				"got %v", test.name, err)
+		}
+	}
+}
+
+func TestMultiLoaderUnloaded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multiloader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	loader := NewLoader(&chaincfg.TestNet3Params, dir, 0)
+	m := NewMultiLoader(loader, filepath.Join(dir, "wallets"))
+
+	if names := m.LoadedWallets(); len(names) != 0 {
+		t.Errorf("expected no loaded wallets, got %v", names)
+	}
+	if _, ok := m.LoadedWallet("savings"); ok {
+		t.Error("unloaded wallet reported as loaded")
+	}
+	exists, err := m.WalletExists("savings")
+	if err != nil || exists {
+		t.Errorf("expected missing wallet, got exists=%v err=%v", exists, err)
+	}
+	_, err = m.OpenWallet("savings", []byte(InsecurePubPassphrase))
+	if err != walletdb.ErrDbDoesNotExist {
+		t.Errorf("expected ErrDbDoesNotExist, got %v", err)
+	}
+	_, err = m.OpenWallet("../savings", []byte(InsecurePubPassphrase))
+	if err != ErrInvalidWalletName {
+		t.Errorf("expected ErrInvalidWalletName, got %v", err)
+	}
+	if err := m.UnloadWallet("savings"); err != ErrNotLoaded {
+		t.Errorf("expected ErrNotLoaded, got %v", err)
+	}
+
+	// Named wallets have no directory when the default wallet database is
+	// not stored in a file.
+	loader.SetDatabaseDriver("postgres", "postgres://localhost/wallet")
+	_, err = m.OpenWallet("savings", []byte(InsecurePubPassphrase))
+	if err != ErrNamedWalletsUnsupported {
+		t.Errorf("expected ErrNamedWalletsUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,1048 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcutil/psbt"
+	h "github.com/btcsuite/btcwallet/internal/helpers"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet/internal/txsizes"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+const (
+	// bip48Purpose is the BIP0043 purpose of BIP0048 multisig keys.
+	bip48Purpose = 48
+
+	// bip48ScriptTypeP2WSH is the BIP0048 script type of native segwit
+	// multisig scripts.
+	bip48ScriptTypeP2WSH = 2
+)
+
+// ErrUnknownMultisigAccount describes an error where a multisig account could
+// not be found.
+var ErrUnknownMultisigAccount = errors.New("multisig account not found")
+
+// Keys of the multisig namespace.  The namespace holds a bucket of multisig
+// accounts keyed by their names, a bucket of the output scripts of the
+// addresses of the accounts, and a bucket of the outputs paying to these
+// scripts keyed by their outpoints.
+var (
+	multisigAccountsKey = []byte("accounts")
+	multisigScriptsKey  = []byte("scripts")
+	multisigDepositsKey = []byte("deposits")
+)
+
+// MultisigAccount is an m-of-n multisig account of BIP0048.  The account
+// combines the wallet's account key, derived at m/48'/coin'/account'/2', with
+// the account keys of its cosigners.  The address at a branch and index of
+// the account pays to a P2WSH sorted multisig script of the keys derived at
+// that branch and index from each of the account keys.
+type MultisigAccount struct {
+	// Name uniquely identifies the multisig account in the wallet.
+	Name string
+
+	// Account is the BIP0048 account number of the wallet's key.
+	Account uint32
+
+	// RequiredSigs is the number of signatures required to spend outputs
+	// of the account.
+	RequiredSigs int
+
+	// AccountKey is the wallet's extended public key of the account and
+	// Cosigners are the extended public keys of the other cosigners.
+	AccountKey *hdkeychain.ExtendedKey
+	Cosigners  []*hdkeychain.ExtendedKey
+
+	// NextExternalIndex and NextInternalIndex are the indexes of the next
+	// receive and change addresses.
+	NextExternalIndex uint32
+	NextInternalIndex uint32
+}
+
+// bip48AccountPath returns the hardened path m/48'/coin'/account'/2' of the
+// wallet's P2WSH multisig account key.
+func bip48AccountPath(params *chaincfg.Params, account uint32) []uint32 {
+	return []uint32{
+		bip48Purpose + hdkeychain.HardenedKeyStart,
+		params.HDCoinType + hdkeychain.HardenedKeyStart,
+		account + hdkeychain.HardenedKeyStart,
+		bip48ScriptTypeP2WSH + hdkeychain.HardenedKeyStart,
+	}
+}
+
+// WitnessScript returns the sorted multisig witness script of the address at
+// an index of a branch of the account.
+func (a *MultisigAccount) WitnessScript(branch, index uint32,
+	params *chaincfg.Params) ([]byte, error) {
+
+	accountKeys := append(
+		[]*hdkeychain.ExtendedKey{a.AccountKey}, a.Cosigners...,
+	)
+	pubKeys := make([]*btcutil.AddressPubKey, 0, len(accountKeys))
+	for _, accountKey := range accountKeys {
+		branchKey, err := accountKey.Child(branch)
+		if err != nil {
+			return nil, err
+		}
+		key, err := branchKey.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		addr, err := btcutil.NewAddressPubKey(
+			pubKey.SerializeCompressed(), params,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, addr)
+	}
+	sort.Slice(pubKeys, func(i, j int) bool {
+		return bytes.Compare(pubKeys[i].ScriptAddress(),
+			pubKeys[j].ScriptAddress()) < 0
+	})
+
+	return txscript.MultiSigScript(pubKeys, a.RequiredSigs)
+}
+
+// Address returns the P2WSH address at an index of a branch of the account.
+func (a *MultisigAccount) Address(branch, index uint32,
+	params *chaincfg.Params) (btcutil.Address, error) {
+
+	script, err := a.WitnessScript(branch, index, params)
+	if err != nil {
+		return nil, err
+	}
+	scriptHash := sha256.Sum256(script)
+	return btcutil.NewAddressWitnessScriptHash(scriptHash[:], params)
+}
+
+// The serialized value of a multisig account, keyed by its name, is:
+//
+//	[0:4]   BIP0048 account number (4 bytes)
+//	[4:8]   Required signatures (4 bytes)
+//	[8:12]  Next external index (4 bytes)
+//	[12:16] Next internal index (4 bytes)
+//	[16:]   Space separated account keys, the wallet's key first, encrypted
+//	        with the public crypto key
+func (w *Wallet) serializeMultisigAccount(a *MultisigAccount) ([]byte, error) {
+	keys := make([]string, 0, len(a.Cosigners)+1)
+	keys = append(keys, a.AccountKey.String())
+	for _, cosigner := range a.Cosigners {
+		keys = append(keys, cosigner.String())
+	}
+	keysEnc, err := w.Manager.Encrypt(
+		waddrmgr.CKTPublic, []byte(strings.Join(keys, " ")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]byte, 16+len(keysEnc))
+	binary.LittleEndian.PutUint32(v, a.Account)
+	binary.LittleEndian.PutUint32(v[4:], uint32(a.RequiredSigs))
+	binary.LittleEndian.PutUint32(v[8:], a.NextExternalIndex)
+	binary.LittleEndian.PutUint32(v[12:], a.NextInternalIndex)
+	copy(v[16:], keysEnc)
+	return v, nil
+}
+
+func (w *Wallet) deserializeMultisigAccount(k, v []byte) (*MultisigAccount, error) {
+	if len(v) < 16 {
+		return nil, fmt.Errorf("multisig account %s: short read", k)
+	}
+	keys, err := w.Manager.Decrypt(waddrmgr.CKTPublic, v[16:])
+	if err != nil {
+		return nil, err
+	}
+	accountKeys := make([]*hdkeychain.ExtendedKey, 0, 2)
+	for _, key := range strings.Split(string(keys), " ") {
+		accountKey, err := hdkeychain.NewKeyFromString(key)
+		if err != nil {
+			return nil, err
+		}
+		accountKeys = append(accountKeys, accountKey)
+	}
+
+	return &MultisigAccount{
+		Name:              string(k),
+		Account:           binary.LittleEndian.Uint32(v),
+		RequiredSigs:      int(binary.LittleEndian.Uint32(v[4:])),
+		AccountKey:        accountKeys[0],
+		Cosigners:         accountKeys[1:],
+		NextExternalIndex: binary.LittleEndian.Uint32(v[8:]),
+		NextInternalIndex: binary.LittleEndian.Uint32(v[12:]),
+	}, nil
+}
+
+func (w *Wallet) putMultisigAccount(ns walletdb.ReadWriteBucket,
+	a *MultisigAccount) error {
+
+	accounts, err := ns.CreateBucketIfNotExists(multisigAccountsKey)
+	if err != nil {
+		return err
+	}
+	v, err := w.serializeMultisigAccount(a)
+	if err != nil {
+		return err
+	}
+	return accounts.Put([]byte(a.Name), v)
+}
+
+func (w *Wallet) fetchMultisigAccount(ns walletdb.ReadBucket,
+	name string) (*MultisigAccount, error) {
+
+	accounts := ns.NestedReadBucket(multisigAccountsKey)
+	if accounts == nil {
+		return nil, ErrUnknownMultisigAccount
+	}
+	v := accounts.Get([]byte(name))
+	if v == nil {
+		return nil, ErrUnknownMultisigAccount
+	}
+	return w.deserializeMultisigAccount([]byte(name), v)
+}
+
+func (w *Wallet) forEachMultisigAccount(ns walletdb.ReadBucket,
+	f func(*MultisigAccount) error) error {
+
+	accounts := ns.NestedReadBucket(multisigAccountsKey)
+	if accounts == nil {
+		return nil
+	}
+	return accounts.ForEach(func(k, v []byte) error {
+		a, err := w.deserializeMultisigAccount(k, v)
+		if err != nil {
+			return err
+		}
+		return f(a)
+	})
+}
+
+// reencryptMultisigAccounts re-encrypts the account keys of all multisig
+// accounts when the public crypto key of the address manager is rotated.
+func reencryptMultisigAccounts(ns walletdb.ReadWriteBucket,
+	reencrypt waddrmgr.ReencryptFunc) error {
+
+	accounts := ns.NestedReadWriteBucket(multisigAccountsKey)
+	if accounts == nil {
+		return nil
+	}
+	return accounts.ForEach(func(k, v []byte) error {
+		if len(v) < 16 {
+			return fmt.Errorf("multisig account %s: short read", k)
+		}
+		keysEnc, err := reencrypt(v[16:])
+		if err != nil {
+			return err
+		}
+		newV := make([]byte, 16+len(keysEnc))
+		copy(newV, v[:16])
+		copy(newV[16:], keysEnc)
+		return accounts.Put(k, newV)
+	})
+}
+
+// multisigScript describes the address of a multisig account an output
+// script pays to.
+type multisigScript struct {
+	name   string
+	branch uint32
+	index  uint32
+}
+
+// The serialized value of a multisig script, keyed by the output script, is:
+//
+//	[0:4] Branch (4 bytes)
+//	[4:8] Index (4 bytes)
+//	[8:]  Name of the multisig account
+func putMultisigScript(ns walletdb.ReadWriteBucket, pkScript []byte,
+	script *multisigScript) error {
+
+	scripts, err := ns.CreateBucketIfNotExists(multisigScriptsKey)
+	if err != nil {
+		return err
+	}
+	v := make([]byte, 8+len(script.name))
+	binary.LittleEndian.PutUint32(v, script.branch)
+	binary.LittleEndian.PutUint32(v[4:], script.index)
+	copy(v[8:], script.name)
+	return scripts.Put(pkScript, v)
+}
+
+func fetchMultisigScript(ns walletdb.ReadBucket, pkScript []byte) *multisigScript {
+	scripts := ns.NestedReadBucket(multisigScriptsKey)
+	if scripts == nil {
+		return nil
+	}
+	v := scripts.Get(pkScript)
+	if len(v) < 8 {
+		return nil
+	}
+	return &multisigScript{
+		name:   string(v[8:]),
+		branch: binary.LittleEndian.Uint32(v),
+		index:  binary.LittleEndian.Uint32(v[4:]),
+	}
+}
+
+func forEachMultisigScript(ns walletdb.ReadBucket, f func(pkScript []byte) error) error {
+	scripts := ns.NestedReadBucket(multisigScriptsKey)
+	if scripts == nil {
+		return nil
+	}
+	return scripts.ForEach(func(k, _ []byte) error {
+		return f(k)
+	})
+}
+
+// MultisigDeposit is an output paying to an address of a multisig account.
+type MultisigDeposit struct {
+	OutPoint      wire.OutPoint
+	Amount        btcutil.Amount
+	PkScript      []byte
+	AccountName   string
+	Branch        uint32
+	Index         uint32
+	Confirmations int32
+}
+
+func multisigOutPointKey(op *wire.OutPoint) []byte {
+	k := make([]byte, 36)
+	copy(k, op.Hash[:])
+	binary.LittleEndian.PutUint32(k[32:], op.Index)
+	return k
+}
+
+// The serialized value of a deposit, keyed by the outpoint, is:
+//
+//	[0:8]   Amount (8 bytes)
+//	[8:40]  Hash of the spending transaction, zero while unspent (32 bytes)
+//	[40:]   Output script
+//
+// The spending transaction is recorded rather than removing the deposit, so
+// the deposit becomes spendable again if the spending transaction is removed
+// from the wallet, such as by a double spend.
+func putMultisigDeposit(ns walletdb.ReadWriteBucket, op *wire.OutPoint,
+	output *wire.TxOut) error {
+
+	deposits, err := ns.CreateBucketIfNotExists(multisigDepositsKey)
+	if err != nil {
+		return err
+	}
+	k := multisigOutPointKey(op)
+	if deposits.Get(k) != nil {
+		return nil
+	}
+	v := make([]byte, 40+len(output.PkScript))
+	binary.LittleEndian.PutUint64(v, uint64(output.Value))
+	copy(v[40:], output.PkScript)
+	return deposits.Put(k, v)
+}
+
+// spendMultisigDeposit records the transaction spending the deposit op, if op
+// is a deposit.
+func spendMultisigDeposit(ns walletdb.ReadWriteBucket, op *wire.OutPoint,
+	spender *chainhash.Hash) error {
+
+	deposits := ns.NestedReadWriteBucket(multisigDepositsKey)
+	if deposits == nil {
+		return nil
+	}
+	k := multisigOutPointKey(op)
+	v := deposits.Get(k)
+	if len(v) < 40 {
+		return nil
+	}
+	spent := make([]byte, len(v))
+	copy(spent, v)
+	copy(spent[8:40], spender[:])
+	return deposits.Put(k, spent)
+}
+
+// forEachMultisigDeposit calls f with each deposit and the hash of its
+// spending transaction, if any.
+func forEachMultisigDeposit(ns walletdb.ReadBucket,
+	f func(*MultisigDeposit, *chainhash.Hash) error) error {
+
+	deposits := ns.NestedReadBucket(multisigDepositsKey)
+	if deposits == nil {
+		return nil
+	}
+	return deposits.ForEach(func(k, v []byte) error {
+		if len(k) != 36 || len(v) < 40 {
+			return fmt.Errorf("multisig deposit %x: short read", k)
+		}
+		d := &MultisigDeposit{
+			Amount:   btcutil.Amount(binary.LittleEndian.Uint64(v)),
+			PkScript: append([]byte(nil), v[40:]...),
+		}
+		copy(d.OutPoint.Hash[:], k)
+		d.OutPoint.Index = binary.LittleEndian.Uint32(k[32:])
+		if script := fetchMultisigScript(ns, d.PkScript); script != nil {
+			d.AccountName = script.name
+			d.Branch = script.branch
+			d.Index = script.index
+		}
+
+		var spender chainhash.Hash
+		copy(spender[:], v[8:40])
+		if spender == (chainhash.Hash{}) {
+			return f(d, nil)
+		}
+		return f(d, &spender)
+	})
+}
+
+// MultisigAccountKey returns the wallet's extended public key of a BIP0048
+// P2WSH multisig account, which is shared with the cosigners of the account.
+// The wallet must be unlocked.
+func (w *Wallet) MultisigAccountKey(account uint32) (*hdkeychain.ExtendedKey, error) {
+	if account > waddrmgr.MaxAccountNum {
+		return nil, fmt.Errorf("account number %d is too high", account)
+	}
+
+	var accountKey *hdkeychain.ExtendedKey
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		privKey, err := w.Manager.DeriveFromRoot(
+			addrmgrNs, bip48AccountPath(w.chainParams, account)...,
+		)
+		if err != nil {
+			return err
+		}
+		defer privKey.Zero()
+
+		accountKey, err = privKey.Neuter()
+		return err
+	})
+	return accountKey, err
+}
+
+// CreateMultisigAccount creates a requiredSigs-of-n BIP0048 P2WSH multisig
+// account of the wallet's key of the BIP0048 account number and the account
+// keys of the cosigners, which are extended public keys such as those
+// returned by MultisigAccountKey of the cosigners' wallets.  The wallet must
+// be unlocked.
+func (w *Wallet) CreateMultisigAccount(name string, account uint32,
+	requiredSigs int,
+	cosigners []*hdkeychain.ExtendedKey) (*MultisigAccount, error) {
+
+	if name == "" {
+		return nil, errors.New("multisig account name is empty")
+	}
+	n := len(cosigners) + 1
+	if n > txscript.MaxPubKeysPerMultiSig {
+		return nil, fmt.Errorf("multisig accounts may have at most %d "+
+			"keys", txscript.MaxPubKeysPerMultiSig)
+	}
+	if requiredSigs < 1 || requiredSigs > n {
+		return nil, fmt.Errorf("invalid number of required signatures %d "+
+			"of %d keys", requiredSigs, n)
+	}
+	for _, cosigner := range cosigners {
+		if cosigner.IsPrivate() {
+			return nil, errors.New("cosigner keys must be extended " +
+				"public keys")
+		}
+		if !cosigner.IsForNet(w.chainParams) {
+			return nil, fmt.Errorf("cosigner key is not for network %v",
+				w.chainParams.Name)
+		}
+	}
+
+	accountKey, err := w.MultisigAccountKey(account)
+	if err != nil {
+		return nil, err
+	}
+	a := &MultisigAccount{
+		Name:         name,
+		Account:      account,
+		RequiredSigs: requiredSigs,
+		AccountKey:   accountKey,
+		Cosigners:    cosigners,
+	}
+
+	// Deriving the first address checks that the keys are usable.
+	if _, err := a.Address(waddrmgr.ExternalBranch, 0, w.chainParams); err != nil {
+		return nil, err
+	}
+
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(multisigNamespaceKey)
+		_, err := w.fetchMultisigAccount(ns, name)
+		switch {
+		case err == nil:
+			return fmt.Errorf("multisig account %q already exists",
+				name)
+		case err != ErrUnknownMultisigAccount:
+			return err
+		}
+		return w.putMultisigAccount(ns, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// MultisigAccount returns the multisig account with the name.
+// ErrUnknownMultisigAccount is returned if there is no such account.
+func (w *Wallet) MultisigAccount(name string) (*MultisigAccount, error) {
+	var a *MultisigAccount
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(multisigNamespaceKey)
+		var err error
+		a, err = w.fetchMultisigAccount(ns, name)
+		return err
+	})
+	return a, err
+}
+
+// MultisigAccounts returns all multisig accounts of the wallet, sorted by
+// name.
+func (w *Wallet) MultisigAccounts() ([]*MultisigAccount, error) {
+	var accounts []*MultisigAccount
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(multisigNamespaceKey)
+		return w.forEachMultisigAccount(ns, func(a *MultisigAccount) error {
+			accounts = append(accounts, a)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+// NewMultisigAddress returns the next receive address, or the next change
+// address if change is true, of a multisig account.  Outputs paying to the
+// address are recorded as deposits of the account.
+func (w *Wallet) NewMultisigAddress(name string, change bool) (btcutil.Address, error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var addr btcutil.Address
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(multisigNamespaceKey)
+		a, err := w.fetchMultisigAccount(ns, name)
+		if err != nil {
+			return err
+		}
+
+		branch, index := waddrmgr.ExternalBranch, &a.NextExternalIndex
+		if change {
+			branch, index = waddrmgr.InternalBranch, &a.NextInternalIndex
+		}
+		if *index > waddrmgr.MaxAddressesPerAccount {
+			return fmt.Errorf("multisig account %q has no more "+
+				"addresses", name)
+		}
+		addr, err = a.Address(branch, *index, w.chainParams)
+		if err != nil {
+			return err
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return err
+		}
+		err = putMultisigScript(ns, pkScript, &multisigScript{
+			name:   name,
+			branch: branch,
+			index:  *index,
+		})
+		if err != nil {
+			return err
+		}
+		*index++
+		return w.putMultisigAccount(ns, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = chainClient.NotifyReceived([]btcutil.Address{addr})
+	if err != nil {
+		return nil, err
+	}
+	return addr, nil
+}
+
+// addMultisigDeposits records the outputs of rec paying to addresses of
+// multisig accounts as deposits, and the deposits spent by its inputs.
+func addMultisigDeposits(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord) error {
+	ns := dbtx.ReadWriteBucket(multisigNamespaceKey)
+	for _, txIn := range rec.MsgTx.TxIn {
+		err := spendMultisigDeposit(ns, &txIn.PreviousOutPoint, &rec.Hash)
+		if err != nil {
+			return err
+		}
+	}
+	for i, output := range rec.MsgTx.TxOut {
+		script := fetchMultisigScript(ns, output.PkScript)
+		if script == nil {
+			continue
+		}
+		op := wire.OutPoint{Hash: rec.Hash, Index: uint32(i)}
+		if err := putMultisigDeposit(ns, &op, output); err != nil {
+			return err
+		}
+		log.Debugf("Recorded deposit %v of multisig account %q", op,
+			script.name)
+	}
+	return nil
+}
+
+// multisigActiveData returns the addresses of all multisig accounts and
+// their unspent deposits, which are watched by the chain backend.
+func (w *Wallet) multisigActiveData(dbtx walletdb.ReadTx) ([]btcutil.Address,
+	[]wtxmgr.Credit, error) {
+
+	ns := dbtx.ReadBucket(multisigNamespaceKey)
+	var addrs []btcutil.Address
+	err := forEachMultisigScript(ns, func(pkScript []byte) error {
+		_, scriptAddrs, _, err := txscript.ExtractPkScriptAddrs(
+			pkScript, w.chainParams,
+		)
+		if err != nil {
+			return err
+		}
+		addrs = append(addrs, scriptAddrs...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deposits, err := w.multisigDeposits(dbtx, "", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	unspent := make([]wtxmgr.Credit, 0, len(deposits))
+	for _, d := range deposits {
+		unspent = append(unspent, wtxmgr.Credit{
+			OutPoint: d.OutPoint,
+			Amount:   d.Amount,
+			PkScript: d.PkScript,
+		})
+	}
+	return addrs, unspent, nil
+}
+
+// multisigDeposits returns the unspent deposits of the multisig account with
+// the name, or of all accounts if name is empty, with at least minconf
+// confirmations.  Deposits whose transaction is no longer recorded by the
+// wallet, and deposits spent by a recorded transaction, are skipped.
+func (w *Wallet) multisigDeposits(dbtx walletdb.ReadTx, name string,
+	minconf int32) ([]*MultisigDeposit, error) {
+
+	ns := dbtx.ReadBucket(multisigNamespaceKey)
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+	syncHeight := w.Manager.SyncedTo().Height
+
+	var deposits []*MultisigDeposit
+	err := forEachMultisigDeposit(ns, func(d *MultisigDeposit,
+		spender *chainhash.Hash) error {
+
+		if name != "" && d.AccountName != name {
+			return nil
+		}
+		if spender != nil {
+			spendTx, err := w.TxStore.TxDetails(txmgrNs, spender)
+			if err != nil {
+				return err
+			}
+			if spendTx != nil {
+				return nil
+			}
+		}
+		details, err := w.TxStore.TxDetails(txmgrNs, &d.OutPoint.Hash)
+		if err != nil {
+			return err
+		}
+		if details == nil {
+			return nil
+		}
+		d.Confirmations = confirms(details.Block.Height, syncHeight)
+		if d.Confirmations < minconf {
+			return nil
+		}
+		deposits = append(deposits, d)
+		return nil
+	})
+	return deposits, err
+}
+
+// MultisigDeposits returns the unspent deposits of the multisig account with
+// the name with at least minconf confirmations.
+func (w *Wallet) MultisigDeposits(name string, minconf int32) ([]*MultisigDeposit, error) {
+	var deposits []*MultisigDeposit
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(multisigNamespaceKey)
+		if _, err := w.fetchMultisigAccount(ns, name); err != nil {
+			return err
+		}
+		var err error
+		deposits, err = w.multisigDeposits(tx, name, minconf)
+		return err
+	})
+	return deposits, err
+}
+
+// FundMultisigPsbt creates a PSBT packet paying to outputs from the deposits
+// of the multisig account with the name with at least minconf confirmations,
+// paying the fee rate feeSatPerKb.  The largest deposits are spent first, and
+// a change output paying to a new change address of the account is added if
+// required.  Each input is populated with the previous output, the witness
+// script and the derivation of the wallet's key of the spent address.  The
+// packet is not signed, so the wallet does not need to be unlocked; it is
+// signed by SignPsbt of the wallets of the cosigners until it has enough
+// signatures to be finalized by FinalizePsbt.  The spent deposits are leased
+// under PsbtLockID for DefaultPsbtLeaseDuration, as FundPsbt leases its
+// inputs, so they are not spent by another packet.  The index of the change
+// output is returned, or -1 if no change output was added.
+func (w *Wallet) FundMultisigPsbt(name string, outputs []*wire.TxOut,
+	minconf int32, feeSatPerKb btcutil.Amount,
+	opts ...TxCreateOption) (*psbt.Packet, int32, error) {
+
+	if len(outputs) == 0 {
+		return nil, 0, ErrPsbtNoOutputs
+	}
+	feeSatPerKb, err := w.txFeeRate(feeSatPerKb, applyTxCreateOptions(opts))
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, output := range outputs {
+		if err := txrules.CheckOutput(output, feeSatPerKb); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var (
+		a        *MultisigAccount
+		deposits []*MultisigDeposit
+		leased   = make(map[wire.OutPoint]struct{})
+	)
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(multisigNamespaceKey)
+		var err error
+		a, err = w.fetchMultisigAccount(ns, name)
+		if err != nil {
+			return err
+		}
+		deposits, err = w.multisigDeposits(tx, name, minconf)
+		if err != nil {
+			return err
+		}
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		leases, err := w.TxStore.LockedOutputs(txmgrNs)
+		if err != nil {
+			return err
+		}
+		for _, lease := range leases {
+			leased[lease.OutPoint] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(deposits, func(i, j int) bool {
+		return deposits[i].Amount > deposits[j].Amount
+	})
+
+	// All addresses of the account have witness scripts of the same size.
+	script, err := a.WitnessScript(waddrmgr.ExternalBranch, 0, w.chainParams)
+	if err != nil {
+		return nil, 0, err
+	}
+	witnessWeight := txsizes.RedeemP2WSHMultisigWitnessWeight(
+		a.RequiredSigs, len(script),
+	)
+
+	var (
+		selected    []*MultisigDeposit
+		totalInput  btcutil.Amount
+		target      = h.SumOutputValues(outputs)
+		fee         btcutil.Amount
+		needsChange bool
+		funded      bool
+	)
+	for _, d := range deposits {
+		if _, ok := leased[d.OutPoint]; ok {
+			continue
+		}
+		if w.LockedOutpoint(d.OutPoint) {
+			continue
+		}
+		selected = append(selected, d)
+		totalInput += d.Amount
+
+		size := txsizes.EstimateP2WSHMultisigVirtualSize(
+			len(selected), witnessWeight, outputs, false,
+		)
+		fee = txrules.FeeForSerializeSize(feeSatPerKb, size)
+		if totalInput < target+fee {
+			continue
+		}
+		funded = true
+
+		size = txsizes.EstimateP2WSHMultisigVirtualSize(
+			len(selected), witnessWeight, outputs, true,
+		)
+		changeFee := txrules.FeeForSerializeSize(feeSatPerKb, size)
+		change := totalInput - target - changeFee
+		needsChange = change > 0 && !txrules.IsDustAmount(
+			change, txsizes.P2WSHPkScriptSize, feeSatPerKb,
+		)
+		if needsChange {
+			fee = changeFee
+		}
+		break
+	}
+	if !funded {
+		return nil, 0, fmt.Errorf("insufficient funds in multisig "+
+			"account %q", name)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, d := range selected {
+		tx.AddTxIn(wire.NewTxIn(&d.OutPoint, nil, nil))
+	}
+	for _, output := range outputs {
+		tx.AddTxOut(wire.NewTxOut(output.Value, output.PkScript))
+	}
+	changeIndex := int32(-1)
+	if needsChange {
+		changeAddr, err := w.NewMultisigAddress(name, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		changeScript, err := txscript.PayToAddrScript(changeAddr)
+		if err != nil {
+			return nil, 0, err
+		}
+		changeIndex = int32(len(tx.TxOut))
+		tx.AddTxOut(wire.NewTxOut(
+			int64(totalInput-target-fee), changeScript,
+		))
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The deposits were selected in a separate database transaction, so
+	// they are checked to still be unleased before leasing them.  Leasing
+	// under the same lock ID would otherwise silently extend a lease taken
+	// by a concurrent call in the meantime.
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+		leases, err := w.TxStore.LockedOutputs(txmgrNs)
+		if err != nil {
+			return err
+		}
+		for _, lease := range leases {
+			leased[lease.OutPoint] = struct{}{}
+		}
+		for i, d := range selected {
+			if _, ok := leased[d.OutPoint]; ok {
+				return fmt.Errorf("deposit %v is already "+
+					"leased", d.OutPoint)
+			}
+			_, err := w.TxStore.LockUntrackedOutput(
+				txmgrNs, PsbtLockID, d.OutPoint,
+				DefaultPsbtLeaseDuration,
+			)
+			if err != nil {
+				return err
+			}
+			prevTx, err := w.TxStore.TxDetails(txmgrNs, &d.OutPoint.Hash)
+			if err != nil {
+				return err
+			}
+			if prevTx == nil {
+				return fmt.Errorf("previous output %v not found",
+					d.OutPoint)
+			}
+			err = w.populateMultisigPsbtInput(
+				&packet.Inputs[i], a, d.Branch, d.Index,
+			)
+			if err != nil {
+				return err
+			}
+			packet.Inputs[i].NonWitnessUtxo = &prevTx.MsgTx
+			packet.Inputs[i].WitnessUtxo = wire.NewTxOut(
+				int64(d.Amount), d.PkScript,
+			)
+			packet.Inputs[i].SighashType = txscript.SigHashAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return packet, changeIndex, nil
+}
+
+// multisigKeyPath returns the derivation path of the wallet's key of the
+// address at an index of a branch of a multisig account.
+func (w *Wallet) multisigKeyPath(a *MultisigAccount, branch,
+	index uint32) []uint32 {
+
+	return append(bip48AccountPath(w.chainParams, a.Account), branch, index)
+}
+
+// pubKey returns the wallet's public key of the address at an index of a
+// branch of the account.
+func (a *MultisigAccount) pubKey(branch, index uint32) (*btcec.PublicKey, error) {
+	branchKey, err := a.AccountKey.Child(branch)
+	if err != nil {
+		return nil, err
+	}
+	key, err := branchKey.Child(index)
+	if err != nil {
+		return nil, err
+	}
+	return key.ECPubKey()
+}
+
+// populateMultisigPsbtInput sets the witness script and the derivation of
+// the wallet's key of an input spending the address at an index of a branch
+// of a multisig account.
+func (w *Wallet) populateMultisigPsbtInput(pInput *psbt.PInput,
+	a *MultisigAccount, branch, index uint32) error {
+
+	script, err := a.WitnessScript(branch, index, w.chainParams)
+	if err != nil {
+		return err
+	}
+	pInput.WitnessScript = script
+
+	pubKey, err := a.pubKey(branch, index)
+	if err != nil {
+		return err
+	}
+	pInput.Bip32Derivation = []*psbt.Bip32Derivation{{
+		PubKey:    pubKey.SerializeCompressed(),
+		Bip32Path: w.multisigKeyPath(a, branch, index),
+	}}
+	return nil
+}
+
+// signMultisigPsbtInput adds a signature by the wallet's key to input idx of
+// tx if it spends prevOut paying to an address of a multisig account.  It
+// returns false if the output is not a deposit of a multisig account.  The
+// wallet must be unlocked.
+func (w *Wallet) signMultisigPsbtInput(dbtx walletdb.ReadTx,
+	tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int,
+	pInput *psbt.PInput, prevOut *wire.TxOut) (bool, error) {
+
+	ns := dbtx.ReadBucket(multisigNamespaceKey)
+	script := fetchMultisigScript(ns, prevOut.PkScript)
+	if script == nil {
+		return false, nil
+	}
+	a, err := w.fetchMultisigAccount(ns, script.name)
+	if err != nil {
+		return false, err
+	}
+	if pInput.WitnessScript == nil {
+		err := w.populateMultisigPsbtInput(
+			pInput, a, script.branch, script.index,
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// A witness script set by the creator of the packet is what the
+	// wallet signs, so it must be the script of the spent output.
+	witnessScript, err := a.WitnessScript(
+		script.branch, script.index, w.chainParams,
+	)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(pInput.WitnessScript, witnessScript) {
+		return false, errors.New("witness script does not match " +
+			"the spent output")
+	}
+
+	hashType := pInput.SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+	keyPath := w.multisigKeyPath(a, script.branch, script.index)
+
+	if signer := w.remoteSigner(); signer != nil {
+		pubKey, err := a.pubKey(script.branch, script.index)
+		if err != nil {
+			return false, err
+		}
+		serializedPubKey := pubKey.SerializeCompressed()
+		sig, err := signer.SignOutputRaw(tx, &SignDescriptor{
+			KeyPath:    keyPath,
+			PubKey:     serializedPubKey,
+			SubScript:  pInput.WitnessScript,
+			Amount:     prevOut.Value,
+			HashType:   hashType,
+			InputIndex: idx,
+			Witness:    true,
+		})
+		if err != nil {
+			return false, err
+		}
+		setPsbtPartialSig(pInput, serializedPubKey, sig, hashType)
+		return true, nil
+	}
+
+	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+	key, err := w.Manager.DeriveFromRoot(addrmgrNs, keyPath...)
+	if waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer key.Zero()
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := txscript.RawTxInWitnessSignature(
+		tx, sigHashes, idx, prevOut.Value, pInput.WitnessScript,
+		hashType, privKey,
+	)
+	if err != nil {
+		return false, err
+	}
+	setPsbtPartialSig(
+		pInput, privKey.PubKey().SerializeCompressed(), sig, hashType,
+	)
+	return true, nil
+}
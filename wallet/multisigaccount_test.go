@@ -0,0 +1,74 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+func TestMultisigAccountWitnessScript(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	keys := make([]*hdkeychain.ExtendedKey, 3)
+	for i := range keys {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, hdkeychain.RecommendedSeedLen)
+		master, err := hdkeychain.NewMaster(seed, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], err = master.Neuter()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := &MultisigAccount{
+		RequiredSigs: 2,
+		AccountKey:   keys[0],
+		Cosigners:    keys[1:],
+	}
+	script, err := a.WitnessScript(0, 5, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	class, _, nRequired, err := txscript.ExtractPkScriptAddrs(script, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class != txscript.MultiSigTy || nRequired != 2 {
+		t.Fatalf("expected 2 of 3 multisig script, got %v requiring %d",
+			class, nRequired)
+	}
+
+	// The keys are sorted, so the script of each cosigner is the same.
+	other := &MultisigAccount{
+		RequiredSigs: 2,
+		AccountKey:   keys[2],
+		Cosigners:    []*hdkeychain.ExtendedKey{keys[1], keys[0]},
+	}
+	otherScript, err := other.WitnessScript(0, 5, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(script, otherScript) {
+		t.Fatal("expected cosigners to derive the same script")
+	}
+
+	// Other indexes and branches derive different scripts.
+	for _, path := range [][2]uint32{{0, 6}, {1, 5}} {
+		s, err := a.WitnessScript(path[0], path[1], params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(script, s) {
+			t.Fatalf("expected script of %d/%d to differ", path[0],
+				path[1])
+		}
+	}
+}
@@ -6,12 +6,15 @@ package wallet
 
 import (
 	"bytes"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/chain"
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/walletdb"
 	"github.com/btcsuite/btcwallet/wtxmgr"
@@ -36,8 +39,14 @@ type NotificationServer struct {
 	currentTxNtfn  *TransactionNotifications // coalesce this since wallet does not add mined txs together
 	spentness      map[uint32][]chan *SpentnessNotifications
 	accountClients []chan *AccountNotification
-	mu             sync.Mutex // Only protects registered client channels
-	wallet         *Wallet    // smells like hacks
+	chainHealth    []chan *ChainHealthNotification
+	rescanProgress []chan *RescanProgressNotification
+	syncState      []chan *SyncStateNotification
+	blockEpochs    []chan *BlockEpochNotification
+	balanceClients []chan *AccountBalanceNotification
+	balances       map[uint32]accountBalance // last notified, nil without balance clients
+	mu             sync.Mutex                // Only protects registered client channels
+	wallet         *Wallet                   // smells like hacks
 }
 
 func newNotificationServer(wallet *Wallet) *NotificationServer {
@@ -106,15 +115,6 @@ func makeTxSummary(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails) T
 		}
 		serializedTx = buf.Bytes()
 	}
-	var fee btcutil.Amount
-	if len(details.Debits) == len(details.MsgTx.TxIn) {
-		for _, deb := range details.Debits {
-			fee += deb.Amount
-		}
-		for _, txOut := range details.MsgTx.TxOut {
-			fee -= btcutil.Amount(txOut.Value)
-		}
-	}
 	var inputs []TransactionSummaryInput
 	if len(details.Debits) != 0 {
 		inputs = make([]TransactionSummaryInput, len(details.Debits))
@@ -138,6 +138,7 @@ func makeTxSummary(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails) T
 			Index:    uint32(i),
 			Account:  acct,
 			Internal: internal,
+			Amount:   details.Credits[credIndex].Amount,
 		}
 		outputs = append(outputs, output)
 	}
@@ -146,31 +147,24 @@ func makeTxSummary(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails) T
 		Transaction: serializedTx,
 		MyInputs:    inputs,
 		MyOutputs:   outputs,
-		Fee:         fee,
+		Fee:         details.Fee,
 		Timestamp:   details.Received.Unix(),
+		Label:       details.Label,
 	}
 }
 
+// totalBalances sets the total balance of every account of m, which are read
+// from the balances of the unspent outputs indexed by account.
 func totalBalances(dbtx walletdb.ReadTx, w *Wallet, m map[uint32]btcutil.Amount) error {
-	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
-	unspent, err := w.TxStore.UnspentOutputs(dbtx.ReadBucket(wtxmgrNamespaceKey))
-	if err != nil {
-		return err
-	}
-	for i := range unspent {
-		output := &unspent[i]
-		var outputAcct uint32
-		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
-			output.PkScript, w.chainParams)
-		if err == nil && len(addrs) > 0 {
-			_, outputAcct, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
-		}
-		if err == nil {
-			_, ok := m[outputAcct]
-			if ok {
-				m[outputAcct] += output.Amount
-			}
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+	syncHeight := w.Manager.SyncedTo().Height
+	for account := range m {
+		bals, err := w.TxStore.AccountBalances(txmgrNs, account, 1,
+			syncHeight)
+		if err != nil {
+			return err
 		}
+		m[account] = bals.Total
 	}
 	return nil
 }
@@ -202,6 +196,8 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 			details.Hash)
 	}
 
+	s.notifyAccountBalances(dbtx)
+
 	defer s.mu.Unlock()
 	s.mu.Lock()
 	clients := s.transactions
@@ -210,11 +206,28 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 	}
 
 	unminedTxs := []TransactionSummary{makeTxSummary(dbtx, s.wallet, details)}
-	unminedHashes, err := s.wallet.TxStore.UnminedTxHashes(dbtx.ReadBucket(wtxmgrNamespaceKey))
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+	unminedHashes, err := s.wallet.TxStore.UnminedTxHashes(txmgrNs)
 	if err != nil {
 		log.Errorf("Cannot fetch unmined transaction hashes: %v", err)
 		return
 	}
+
+	// Unmined transactions double spent by this one are only recorded as
+	// conflicted once it was relayed by the backend, so they are read from
+	// the store rather than recorded while inserting it.
+	conflictHashes, err := s.wallet.TxStore.Conflicts(txmgrNs, &details.TxRecord)
+	if err != nil {
+		log.Errorf("Cannot fetch conflicted transactions: %v", err)
+		return
+	}
+	var conflicts []ConflictedTransaction
+	for i := range conflictHashes {
+		conflicts = append(conflicts, ConflictedTransaction{
+			Hash:            &conflictHashes[i],
+			ConflictingHash: &details.Hash,
+		})
+	}
 	bals := make(map[uint32]btcutil.Amount)
 	relevantAccounts(s.wallet, bals, unminedTxs)
 	err = totalBalances(dbtx, s.wallet, bals)
@@ -225,6 +238,7 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 	n := &TransactionNotifications{
 		UnminedTransactions:      unminedTxs,
 		UnminedTransactionHashes: unminedHashes,
+		ConflictedTransactions:   conflicts,
 		NewBalances:              flattenBalanceMap(bals),
 	}
 	for _, c := range clients {
@@ -232,6 +246,92 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 	}
 }
 
+// notifyRemovedUnminedTransactions notifies clients of the removal of the
+// unmined transactions described by removed, with the hashes of the remaining
+// unmined transactions and the new balances of the accounts of the removed
+// transactions.
+func (s *NotificationServer) notifyRemovedUnminedTransactions(dbtx walletdb.ReadTx, removed []TransactionSummary) {
+	if s.currentTxNtfn != nil {
+		log.Errorf("Notifying removed unmined txs while creating notification for blocks")
+	}
+
+	s.notifyAccountBalances(dbtx)
+
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	clients := s.transactions
+	if len(clients) == 0 {
+		return
+	}
+
+	unminedHashes, err := s.wallet.TxStore.UnminedTxHashes(dbtx.ReadBucket(wtxmgrNamespaceKey))
+	if err != nil {
+		log.Errorf("Cannot fetch unmined transaction hashes: %v", err)
+		return
+	}
+	bals := make(map[uint32]btcutil.Amount)
+	relevantAccounts(s.wallet, bals, removed)
+	err = totalBalances(dbtx, s.wallet, bals)
+	if err != nil {
+		log.Errorf("Cannot determine balances for relevant accounts: %v", err)
+		return
+	}
+	n := &TransactionNotifications{
+		UnminedTransactionHashes: unminedHashes,
+		NewBalances:              flattenBalanceMap(bals),
+	}
+	for _, c := range clients {
+		c <- n
+	}
+}
+
+// notifyConflict records that the unmined transaction hash was removed because
+// the transaction conflictingHash double spending it was mined, which is
+// notified with the block it was mined in.  Conflicts with unmined
+// transactions are notified with the unmined double spend.
+func (s *NotificationServer) notifyConflict(hash, conflictingHash *chainhash.Hash) {
+	conflict := ConflictedTransaction{
+		Hash:            hash,
+		ConflictingHash: conflictingHash,
+		Mined:           true,
+	}
+	if i := s.reorgedTransaction(hash); i != -1 {
+		s.currentTxNtfn.ReorgedTransactions[i].Status = ReorgConflicted
+	}
+	if s.currentTxNtfn == nil {
+		s.currentTxNtfn = &TransactionNotifications{}
+	}
+	s.currentTxNtfn.ConflictedTransactions = append(
+		s.currentTxNtfn.ConflictedTransactions, conflict)
+}
+
+// notifyReorgedTransaction records the status of the transaction hash, which
+// was mined in a detached block, after the rollback of the block.
+func (s *NotificationServer) notifyReorgedTransaction(hash *chainhash.Hash,
+	status ReorgStatus) {
+
+	if s.currentTxNtfn == nil {
+		s.currentTxNtfn = &TransactionNotifications{}
+	}
+	s.currentTxNtfn.ReorgedTransactions = append(
+		s.currentTxNtfn.ReorgedTransactions,
+		ReorgedTransaction{Hash: hash, Status: status})
+}
+
+// reorgedTransaction returns the index of the reorged transaction hash of the
+// notification being created, or -1 if it is not a reorged transaction.
+func (s *NotificationServer) reorgedTransaction(hash *chainhash.Hash) int {
+	if s.currentTxNtfn == nil {
+		return -1
+	}
+	for i, tx := range s.currentTxNtfn.ReorgedTransactions {
+		if *tx.Hash == *hash {
+			return i
+		}
+	}
+	return -1
+}
+
 func (s *NotificationServer) notifyDetachedBlock(hash *chainhash.Hash) {
 	if s.currentTxNtfn == nil {
 		s.currentTxNtfn = &TransactionNotifications{}
@@ -240,6 +340,13 @@ func (s *NotificationServer) notifyDetachedBlock(hash *chainhash.Hash) {
 }
 
 func (s *NotificationServer) notifyMinedTransaction(dbtx walletdb.ReadTx, details *wtxmgr.TxDetails, block *wtxmgr.BlockMeta) {
+	// Reorged transactions mined again in the new chain are only reported
+	// with the attached block.
+	if i := s.reorgedTransaction(&details.Hash); i != -1 {
+		reorged := s.currentTxNtfn.ReorgedTransactions
+		s.currentTxNtfn.ReorgedTransactions = append(reorged[:i],
+			reorged[i+1:]...)
+	}
 	if s.currentTxNtfn == nil {
 		s.currentTxNtfn = &TransactionNotifications{}
 	}
@@ -281,6 +388,8 @@ func (s *NotificationServer) notifyAttachedBlock(dbtx walletdb.ReadTx, block *wt
 		}
 	}
 
+	s.notifyAccountBalances(dbtx)
+
 	defer s.mu.Unlock()
 	s.mu.Lock()
 	clients := s.transactions
@@ -334,6 +443,15 @@ func (s *NotificationServer) notifyAttachedBlock(dbtx walletdb.ReadTx, block *wt
 // transactions are not explicitly included.  Instead, the hashes of all
 // transactions still unmined are included.
 //
+// Unmined transactions double spent by a newly mined or unmined transaction
+// are included as conflicted transactions.  Transactions double spent by a
+// mined transaction are removed, and no longer included in the unmined
+// transaction hashes.
+//
+// The transactions mined in detached blocks and not mined again in an
+// attached block are included as reorged transactions, with their status in
+// the new best chain.
+//
 // If any transactions were involved, each affected account's new total balance
 // is included.
 //
@@ -344,9 +462,61 @@ type TransactionNotifications struct {
 	DetachedBlocks           []*chainhash.Hash
 	UnminedTransactions      []TransactionSummary
 	UnminedTransactionHashes []*chainhash.Hash
+	ConflictedTransactions   []ConflictedTransaction
+	ReorgedTransactions      []ReorgedTransaction
 	NewBalances              []AccountBalance
 }
 
+// ReorgStatus describes the status of a wallet transaction mined in a block
+// which was reorganized out of the main chain.
+type ReorgStatus uint8
+
+// These constants describe the status of reorged transactions.
+const (
+	// ReorgUnmined indicates the transaction was moved back to the
+	// unmined transactions, and may be mined again.
+	ReorgUnmined ReorgStatus = iota
+
+	// ReorgConflicted indicates the transaction was double spent by a
+	// transaction mined in the new chain, and removed from the wallet.
+	ReorgConflicted
+
+	// ReorgRemoved indicates the transaction can not exist outside of its
+	// block, such as a coinbase, and was removed from the wallet.
+	ReorgRemoved
+)
+
+var reorgStatusStrings = [...]string{
+	ReorgUnmined:    "unmined",
+	ReorgConflicted: "conflicted",
+	ReorgRemoved:    "removed",
+}
+
+// String returns the ReorgStatus as a human-readable string.
+func (s ReorgStatus) String() string {
+	if int(s) < len(reorgStatusStrings) {
+		return reorgStatusStrings[s]
+	}
+	return "unknown"
+}
+
+// ReorgedTransaction describes a wallet transaction which was mined in a
+// detached block, and its status in the new best chain.
+type ReorgedTransaction struct {
+	Hash   *chainhash.Hash
+	Status ReorgStatus
+}
+
+// ConflictedTransaction describes an unmined wallet transaction which was
+// double spent by the transaction ConflictingHash.  Mined is set when the
+// double spend was mined, in which case the conflicted transaction can never
+// be mined in the current chain.
+type ConflictedTransaction struct {
+	Hash            *chainhash.Hash
+	ConflictingHash *chainhash.Hash
+	Mined           bool
+}
+
 // Block contains the properties and all relevant transactions of an attached
 // block.
 type Block struct {
@@ -357,7 +527,8 @@ type Block struct {
 }
 
 // TransactionSummary contains a transaction relevant to the wallet and marks
-// which inputs and outputs were relevant.
+// which inputs and outputs were relevant.  Label is the label of the
+// transaction, if any.
 type TransactionSummary struct {
 	Hash        *chainhash.Hash
 	Transaction []byte
@@ -365,6 +536,7 @@ type TransactionSummary struct {
 	MyOutputs   []TransactionSummaryOutput
 	Fee         btcutil.Amount
 	Timestamp   int64
+	Label       string
 }
 
 // TransactionSummaryInput describes a transaction input that is relevant to the
@@ -379,11 +551,13 @@ type TransactionSummaryInput struct {
 
 // TransactionSummaryOutput describes wallet properties of a transaction output
 // controlled by the wallet.  The Index field marks the transaction output index
-// of the transaction (not included here).
+// of the transaction (not included here).  The Amount field is the output
+// value credited to the wallet account.
 type TransactionSummaryOutput struct {
 	Index    uint32
 	Account  uint32
 	Internal bool
+	Amount   btcutil.Amount
 }
 
 // AccountBalance associates a total (zero confirmation) balance with an
@@ -633,3 +807,457 @@ func (c *AccountNotificationsClient) Done() {
 		s.mu.Unlock()
 	}()
 }
+
+// ChainHealthNotification describes a change of the health of the consensus
+// RPC server the wallet synchronizes with.
+type ChainHealthNotification struct {
+	Health          chain.BackendHealth
+	BestHeight      int32
+	LastBlockChange time.Time
+	Err             error
+}
+
+func (s *NotificationServer) notifyChainHealth(status *chain.HealthStatus) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	clients := s.chainHealth
+	if len(clients) == 0 {
+		return
+	}
+	n := &ChainHealthNotification{
+		Health:          status.Health,
+		BestHeight:      status.BestHeight,
+		LastBlockChange: status.LastBlockChange,
+		Err:             status.Err,
+	}
+	for _, c := range clients {
+		c <- n
+	}
+}
+
+// ChainHealthNotificationsClient receives ChainHealthNotifications over the
+// channel C.
+type ChainHealthNotificationsClient struct {
+	C      chan *ChainHealthNotification
+	server *NotificationServer
+}
+
+// ChainHealthNotifications returns a client for receiving
+// ChainHealthNotifications over a channel, sent when the consensus RPC server
+// becomes degraded or healthy again.  The channel is unbuffered.  When
+// finished, the client's Done method should be called to disassociate the
+// client from the server.
+func (s *NotificationServer) ChainHealthNotifications() ChainHealthNotificationsClient {
+	c := make(chan *ChainHealthNotification)
+	s.mu.Lock()
+	s.chainHealth = append(s.chainHealth, c)
+	s.mu.Unlock()
+	return ChainHealthNotificationsClient{
+		C:      c,
+		server: s,
+	}
+}
+
+// Done deregisters the client from the server and drains any remaining
+// messages.  It must be called exactly once when the client is finished
+// receiving notifications.
+func (c *ChainHealthNotificationsClient) Done() {
+	go func() {
+		for range c.C {
+		}
+	}()
+	go func() {
+		s := c.server
+		s.mu.Lock()
+		clients := s.chainHealth
+		for i, ch := range clients {
+			if c.C == ch {
+				clients[i] = clients[len(clients)-1]
+				s.chainHealth = clients[:len(clients)-1]
+				close(ch)
+				break
+			}
+		}
+		s.mu.Unlock()
+	}()
+}
+
+// RescanProgressNotification describes the progress of a rescan.  A final
+// notification at 100 percent is sent when the rescan finishes.
+type RescanProgressNotification struct {
+	Hash         chainhash.Hash // block processed through
+	Height       int32
+	TargetHeight int32 // best block when the rescan started
+	Percent      float64
+
+	// Remaining is the estimated time until the rescan finishes, or zero
+	// when no estimate is available yet.
+	Remaining time.Duration
+}
+
+func (s *NotificationServer) notifyRescanProgress(n *RescanProgressNotification) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	for _, c := range s.rescanProgress {
+		c <- n
+	}
+}
+
+// RescanProgressNotificationsClient receives RescanProgressNotifications over
+// the channel C.
+type RescanProgressNotificationsClient struct {
+	C      chan *RescanProgressNotification
+	server *NotificationServer
+}
+
+// RescanProgressNotifications returns a client for receiving
+// RescanProgressNotifications over a channel, sent as rescans process blocks.
+// The channel is unbuffered.  When finished, the client's Done method should be
+// called to disassociate the client from the server.
+func (s *NotificationServer) RescanProgressNotifications() RescanProgressNotificationsClient {
+	c := make(chan *RescanProgressNotification)
+	s.mu.Lock()
+	s.rescanProgress = append(s.rescanProgress, c)
+	s.mu.Unlock()
+	return RescanProgressNotificationsClient{
+		C:      c,
+		server: s,
+	}
+}
+
+// Done deregisters the client from the server and drains any remaining
+// messages.  It must be called exactly once when the client is finished
+// receiving notifications.
+func (c *RescanProgressNotificationsClient) Done() {
+	go func() {
+		for range c.C {
+		}
+	}()
+	go func() {
+		s := c.server
+		s.mu.Lock()
+		clients := s.rescanProgress
+		for i, ch := range clients {
+			if c.C == ch {
+				clients[i] = clients[len(clients)-1]
+				s.rescanProgress = clients[:len(clients)-1]
+				close(ch)
+				break
+			}
+		}
+		s.mu.Unlock()
+	}()
+}
+
+// SyncStateNotification describes whether the wallet is synced to the chain
+// server, and the heights of the wallet and the chain server.
+type SyncStateNotification struct {
+	Synced        bool
+	Height        int32 // block the wallet is synced to
+	BackendHeight int32 // best block of the chain server, zero when unknown
+}
+
+func (s *NotificationServer) notifySyncState(n *SyncStateNotification) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	for _, c := range s.syncState {
+		c <- n
+	}
+}
+
+// SyncStateNotificationsClient receives SyncStateNotifications over the
+// channel C.
+type SyncStateNotificationsClient struct {
+	C      chan *SyncStateNotification
+	server *NotificationServer
+}
+
+// SyncStateNotifications returns a client for receiving SyncStateNotifications
+// over a channel, sent when the wallet becomes synced to the chain server or
+// stops being synced.  The channel is unbuffered.  When finished, the client's
+// Done method should be called to disassociate the client from the server.
+func (s *NotificationServer) SyncStateNotifications() SyncStateNotificationsClient {
+	c := make(chan *SyncStateNotification)
+	s.mu.Lock()
+	s.syncState = append(s.syncState, c)
+	s.mu.Unlock()
+	return SyncStateNotificationsClient{
+		C:      c,
+		server: s,
+	}
+}
+
+// Done deregisters the client from the server and drains any remaining
+// messages.  It must be called exactly once when the client is finished
+// receiving notifications.
+func (c *SyncStateNotificationsClient) Done() {
+	go func() {
+		for range c.C {
+		}
+	}()
+	go func() {
+		s := c.server
+		s.mu.Lock()
+		clients := s.syncState
+		for i, ch := range clients {
+			if c.C == ch {
+				clients[i] = clients[len(clients)-1]
+				s.syncState = clients[:len(clients)-1]
+				close(ch)
+				break
+			}
+		}
+		s.mu.Unlock()
+	}()
+}
+
+// BlockEpochNotification describes a block connected to or disconnected from
+// the chain the wallet is synced to.  Header is the header notified by the
+// chain server, and is nil when the chain server did not notify it, such as
+// for blocks disconnected by a neutrino backend or the blocks detached above
+// the disconnected block.
+type BlockEpochNotification struct {
+	Connected bool // false when the block was disconnected
+	Hash      chainhash.Hash
+	Height    int32
+	Header    *wire.BlockHeader
+}
+
+func (s *NotificationServer) notifyBlockEpoch(n *BlockEpochNotification) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	for _, c := range s.blockEpochs {
+		c <- n
+	}
+}
+
+// BlockEpochNotificationsClient receives BlockEpochNotifications over the
+// channel C.
+type BlockEpochNotificationsClient struct {
+	C      chan *BlockEpochNotification
+	server *NotificationServer
+}
+
+// BlockEpochNotifications returns a client for receiving
+// BlockEpochNotifications over a channel, sent as blocks are connected to and
+// disconnected from the chain the wallet is synced to.  During a chain switch,
+// the detached blocks are sent in the reverse order they were mined, before
+// the attached blocks.  The channel is unbuffered.  When finished, the
+// client's Done method should be called to disassociate the client from the
+// server.
+func (s *NotificationServer) BlockEpochNotifications() BlockEpochNotificationsClient {
+	c := make(chan *BlockEpochNotification)
+	s.mu.Lock()
+	s.blockEpochs = append(s.blockEpochs, c)
+	s.mu.Unlock()
+	return BlockEpochNotificationsClient{
+		C:      c,
+		server: s,
+	}
+}
+
+// Done deregisters the client from the server and drains any remaining
+// messages.  It must be called exactly once when the client is finished
+// receiving notifications.
+func (c *BlockEpochNotificationsClient) Done() {
+	go func() {
+		for range c.C {
+		}
+	}()
+	go func() {
+		s := c.server
+		s.mu.Lock()
+		clients := s.blockEpochs
+		for i, ch := range clients {
+			if c.C == ch {
+				clients[i] = clients[len(clients)-1]
+				s.blockEpochs = clients[:len(clients)-1]
+				close(ch)
+				break
+			}
+		}
+		s.mu.Unlock()
+	}()
+}
+
+// accountBalance is the confirmed and unconfirmed balance of an account.
+type accountBalance struct {
+	confirmed   btcutil.Amount
+	unconfirmed btcutil.Amount
+}
+
+// accountBalances returns the balances of every account with unspent outputs.
+// Outputs mined in the blocks the wallet is synced to are confirmed, and the
+// outputs of unmined transactions are unconfirmed.  The balances are read from
+// the unspent outputs indexed by account, so only the outputs of the most
+// recent blocks are read for each account of the wallet.
+func accountBalances(dbtx walletdb.ReadTx, w *Wallet) (map[uint32]accountBalance, error) {
+	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+	// Account numbers are shared by the key scopes, so the accounts
+	// through the last account of any scope, and the imported account,
+	// are read.
+	var lastAccount uint32
+	for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+		last, err := manager.LastAccount(addrmgrNs)
+		if err != nil {
+			return nil, err
+		}
+		if last > lastAccount {
+			lastAccount = last
+		}
+	}
+	accounts := make([]uint32, 0, lastAccount+2)
+	for account := uint32(0); account <= lastAccount; account++ {
+		accounts = append(accounts, account)
+	}
+	accounts = append(accounts, waddrmgr.ImportedAddrAccount)
+
+	syncHeight := w.Manager.SyncedTo().Height
+	m := make(map[uint32]accountBalance)
+	for _, account := range accounts {
+		bals, err := w.TxStore.AccountBalances(txmgrNs, account, 1,
+			syncHeight)
+		if err != nil {
+			return nil, err
+		}
+		if bals.Total == 0 {
+			continue
+		}
+		unconfirmed := bals.Total - bals.Spendable - bals.ImmatureReward
+		m[account] = accountBalance{
+			confirmed:   bals.Total - unconfirmed,
+			unconfirmed: unconfirmed,
+		}
+	}
+	return m, nil
+}
+
+// AccountBalanceNotification describes a change of the confirmed or
+// unconfirmed balance of an account.  The deltas are the changes since the
+// balances were previously notified, or since the client was registered.
+type AccountBalanceNotification struct {
+	Account          uint32
+	Confirmed        btcutil.Amount
+	Unconfirmed      btcutil.Amount
+	ConfirmedDelta   btcutil.Amount
+	UnconfirmedDelta btcutil.Amount
+}
+
+// notifyAccountBalances notifies clients of every account with balances that
+// changed since they were previously notified, in order of increasing account
+// number.
+func (s *NotificationServer) notifyAccountBalances(dbtx walletdb.ReadTx) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	if len(s.balanceClients) == 0 {
+		return
+	}
+
+	bals, err := accountBalances(dbtx, s.wallet)
+	if err != nil {
+		log.Errorf("Cannot determine account balances: %v", err)
+		return
+	}
+	accounts := make([]uint32, 0, len(bals))
+	for account := range bals {
+		accounts = append(accounts, account)
+	}
+	for account := range s.balances {
+		if _, ok := bals[account]; !ok {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i] < accounts[j]
+	})
+	for _, account := range accounts {
+		prev, bal := s.balances[account], bals[account]
+		if prev == bal {
+			continue
+		}
+		n := &AccountBalanceNotification{
+			Account:          account,
+			Confirmed:        bal.confirmed,
+			Unconfirmed:      bal.unconfirmed,
+			ConfirmedDelta:   bal.confirmed - prev.confirmed,
+			UnconfirmedDelta: bal.unconfirmed - prev.unconfirmed,
+		}
+		for _, c := range s.balanceClients {
+			c <- n
+		}
+	}
+	s.balances = bals
+}
+
+// AccountBalanceNotificationsClient receives AccountBalanceNotifications over
+// the channel C.
+type AccountBalanceNotificationsClient struct {
+	C      chan *AccountBalanceNotification
+	server *NotificationServer
+}
+
+// AccountBalanceNotifications returns a client for receiving
+// AccountBalanceNotifications over a channel, sent when transactions change
+// the confirmed or unconfirmed balance of an account.  The channel is
+// unbuffered.  When finished, the client's Done method should be called to
+// disassociate the client from the server.
+func (s *NotificationServer) AccountBalanceNotifications() AccountBalanceNotificationsClient {
+	c := make(chan *AccountBalanceNotification)
+	s.mu.Lock()
+	s.balanceClients = append(s.balanceClients, c)
+	seeded := s.balances != nil
+	s.mu.Unlock()
+
+	// The balances are only tracked while clients are registered, so the
+	// first client records the balances the deltas are relative to.
+	if !seeded {
+		var bals map[uint32]accountBalance
+		err := walletdb.View(s.wallet.db, func(dbtx walletdb.ReadTx) error {
+			var err error
+			bals, err = accountBalances(dbtx, s.wallet)
+			return err
+		})
+		if err != nil {
+			log.Errorf("Cannot determine account balances: %v", err)
+		}
+		s.mu.Lock()
+		if s.balances == nil && len(s.balanceClients) != 0 {
+			s.balances = bals
+		}
+		s.mu.Unlock()
+	}
+
+	return AccountBalanceNotificationsClient{
+		C:      c,
+		server: s,
+	}
+}
+
+// Done deregisters the client from the server and drains any remaining
+// messages.  It must be called exactly once when the client is finished
+// receiving notifications.
+func (c *AccountBalanceNotificationsClient) Done() {
+	go func() {
+		for range c.C {
+		}
+	}()
+	go func() {
+		s := c.server
+		s.mu.Lock()
+		clients := s.balanceClients
+		for i, ch := range clients {
+			if c.C == ch {
+				clients[i] = clients[len(clients)-1]
+				s.balanceClients = clients[:len(clients)-1]
+				close(ch)
+				break
+			}
+		}
+		if len(s.balanceClients) == 0 {
+			s.balances = nil
+		}
+		s.mu.Unlock()
+	}()
+}
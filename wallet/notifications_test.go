@@ -0,0 +1,338 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// headerChainClient is a mockChainClient returning an empty header for every
+// block.
+type headerChainClient struct {
+	*mockChainClient
+}
+
+func (c *headerChainClient) GetBlockHeader(*chainhash.Hash) (*wire.BlockHeader,
+	error) {
+
+	return &wire.BlockHeader{Timestamp: time.Unix(1500000000, 0)}, nil
+}
+
+// TestReorgedTransactions ensures that the transactions mined in a detached
+// block are notified with their status in the new best chain, unless they are
+// mined again in an attached block.
+func TestReorgedTransactions(t *testing.T) {
+	w, mock, cleanup := testWallet(t)
+	defer cleanup()
+	w.chainClient = &headerChainClient{mock}
+	w.SetChainSynced(true)
+
+	block := func(hash byte, height int32) wtxmgr.BlockMeta {
+		return wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   chainhash.Hash{hash},
+				Height: height,
+			},
+			Time: time.Unix(1500000000+int64(height), 0),
+		}
+	}
+	addTxs := func(b wtxmgr.BlockMeta, txs ...*wire.MsgTx) {
+		var recs []*wtxmgr.TxRecord
+		err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			for _, tx := range txs {
+				rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, b.Time)
+				if err != nil {
+					return err
+				}
+				if err := w.addRelevantTx(dbTx, rec, &b); err != nil {
+					return err
+				}
+				recs = append(recs, rec)
+			}
+			return w.connectBlock(dbTx, b)
+		})
+		if err != nil {
+			t.Fatalf("unable to connect block %v: %v", b.Hash, err)
+		}
+		err = walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+			for _, rec := range recs {
+				w.notifyRelevantTx(dbTx, rec, &b)
+			}
+			w.notifyConnectedBlock(dbTx, b, nil)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The detached block mines a transaction which is double spent in the
+	// new chain, a coinbase, and a transaction mined again in the new
+	// chain.
+	conflicted := testTx(wire.OutPoint{Hash: chainhash.Hash{0xa}}, 1e6)
+	coinbase := testTx(wire.OutPoint{Index: wire.MaxPrevOutIndex}, 5e9)
+	remined := testTx(wire.OutPoint{Hash: chainhash.Hash{0xb}}, 2e6)
+	doubleSpend := testTx(wire.OutPoint{Hash: chainhash.Hash{0xa}}, 9e5)
+	detached := block(1, 1)
+	addTxs(detached, conflicted, coinbase, remined)
+
+	client := w.NtfnServer.TransactionNotifications()
+	defer client.Done()
+
+	go func() {
+		err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			return w.disconnectBlock(dbTx, detached, nil)
+		})
+		if err != nil {
+			t.Errorf("unable to disconnect block: %v", err)
+			return
+		}
+		addTxs(block(11, 1), remined, doubleSpend)
+		addTxs(block(12, 2))
+	}()
+
+	var n *TransactionNotifications
+	select {
+	case n = <-client.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification of the reorg")
+	}
+
+	if len(n.DetachedBlocks) != 1 || *n.DetachedBlocks[0] != detached.Hash {
+		t.Fatalf("expected detached block %v, got %v", detached.Hash,
+			n.DetachedBlocks)
+	}
+	if len(n.AttachedBlocks) != 2 {
+		t.Fatalf("expected 2 attached blocks, got %d",
+			len(n.AttachedBlocks))
+	}
+	expected := map[chainhash.Hash]ReorgStatus{
+		conflicted.TxHash(): ReorgConflicted,
+		coinbase.TxHash():   ReorgRemoved,
+	}
+	if len(n.ReorgedTransactions) != len(expected) {
+		t.Fatalf("expected %d reorged transactions, got %v",
+			len(expected), n.ReorgedTransactions)
+	}
+	for _, tx := range n.ReorgedTransactions {
+		status, ok := expected[*tx.Hash]
+		if !ok || tx.Status != status {
+			t.Errorf("unexpected reorged transaction %v (%v)",
+				tx.Hash, tx.Status)
+		}
+	}
+	if synced := w.Manager.SyncedTo(); synced.Height != 2 {
+		t.Fatalf("expected wallet synced to height 2, got %d",
+			synced.Height)
+	}
+}
+
+// forkHeaderClient is a mockChainClient which only knows the header of the
+// fork point of a reorg.
+type forkHeaderClient struct {
+	*mockChainClient
+	fork chainhash.Hash
+}
+
+func (c *forkHeaderClient) GetBlockHeader(hash *chainhash.Hash) (
+	*wire.BlockHeader, error) {
+
+	if *hash != c.fork {
+		return nil, errNotImplemented
+	}
+	return &wire.BlockHeader{Timestamp: time.Unix(1500000000, 0)}, nil
+}
+
+// TestBlockEpochNotifications ensures that block epochs are notified with the
+// headers notified by the chain server, and that the epochs of detached blocks
+// are notified even when their headers are unknown.
+func TestBlockEpochNotifications(t *testing.T) {
+	w, mock, cleanup := testWallet(t)
+	defer cleanup()
+	w.SetChainSynced(true)
+
+	var fork *chainhash.Hash
+	err := walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+		var err error
+		addrmgrNs := dbTx.ReadBucket(waddrmgrNamespaceKey)
+		fork, err = w.Manager.BlockHash(addrmgrNs, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.chainClient = &forkHeaderClient{mockChainClient: mock, fork: *fork}
+
+	client := w.NtfnServer.BlockEpochNotifications()
+	defer client.Done()
+
+	headers := make([]*wire.BlockHeader, 3)
+	blocks := make([]wtxmgr.BlockMeta, 3)
+	for height := int32(1); height <= 2; height++ {
+		headers[height] = &wire.BlockHeader{
+			Nonce:     uint32(height),
+			Timestamp: time.Unix(1500000000+int64(height), 0),
+		}
+		blocks[height] = wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   headers[height].BlockHash(),
+				Height: height,
+			},
+			Time: headers[height].Timestamp,
+		}
+	}
+
+	go func() {
+		for height := 1; height <= 2; height++ {
+			b := blocks[height]
+			err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+				return w.connectBlock(dbTx, b)
+			})
+			if err != nil {
+				t.Errorf("unable to connect block %v: %v", b.Hash, err)
+				return
+			}
+			err = walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+				w.notifyConnectedBlock(dbTx, b, headers[height])
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		// Disconnecting the first block detaches both blocks, and
+		// only the header of the disconnected block is notified.
+		err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			return w.disconnectBlock(dbTx, blocks[1], headers[1])
+		})
+		if err != nil {
+			t.Errorf("unable to disconnect block: %v", err)
+		}
+	}()
+
+	expected := []BlockEpochNotification{
+		{Connected: true, Hash: blocks[1].Hash, Height: 1, Header: headers[1]},
+		{Connected: true, Hash: blocks[2].Hash, Height: 2, Header: headers[2]},
+		{Hash: blocks[2].Hash, Height: 2},
+		{Hash: blocks[1].Hash, Height: 1, Header: headers[1]},
+	}
+	for i, exp := range expected {
+		var n *BlockEpochNotification
+		select {
+		case n = <-client.C:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("epoch %d: no notification", i)
+		}
+		if *n != exp {
+			t.Fatalf("epoch %d: expected %+v, got %+v", i, exp, *n)
+		}
+	}
+}
+
+// TestAccountBalanceNotifications ensures that clients are notified of the
+// balances of the accounts paid by unmined transactions, and of the balances
+// confirmed once the block mining them is attached.
+func TestAccountBalanceNotifications(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.NewAddress(
+		waddrmgr.DefaultAccountNum, waddrmgr.KeyScopeBIP0084,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	tx.TxOut[0].PkScript = pkScript
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: chainhash.Hash{11}, Height: 1},
+		Time:  time.Unix(1500000001, 0),
+	}
+
+	client := w.NtfnServer.AccountBalanceNotifications()
+	defer client.Done()
+
+	go func() {
+		err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			return w.addRelevantTx(dbTx, rec, nil)
+		})
+		if err != nil {
+			t.Errorf("unable to add unmined transaction: %v", err)
+			return
+		}
+		walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+			w.notifyRelevantTx(dbTx, rec, nil)
+			return nil
+		})
+
+		err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			if err := w.addRelevantTx(dbTx, rec, &block); err != nil {
+				return err
+			}
+			return w.connectBlock(dbTx, block)
+		})
+		if err != nil {
+			t.Errorf("unable to connect block: %v", err)
+			return
+		}
+		walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+			w.notifyRelevantTx(dbTx, rec, &block)
+			w.notifyConnectedBlock(dbTx, block, nil)
+			return nil
+		})
+	}()
+
+	expected := []AccountBalanceNotification{
+		{
+			Account:          waddrmgr.DefaultAccountNum,
+			Unconfirmed:      1e6,
+			UnconfirmedDelta: 1e6,
+		},
+		{
+			Account:          waddrmgr.DefaultAccountNum,
+			Confirmed:        1e6,
+			ConfirmedDelta:   1e6,
+			UnconfirmedDelta: -1e6,
+		},
+	}
+	for i, exp := range expected {
+		var n *AccountBalanceNotification
+		select {
+		case n = <-client.C:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("balance %d: no notification", i)
+		}
+		if *n != exp {
+			t.Fatalf("balance %d: expected %+v, got %+v", i, exp, *n)
+		}
+	}
+
+	// The balances match those calculated for the account.
+	bals, err := w.CalculateAccountBalances(waddrmgr.DefaultAccountNum, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bals.Total != 1e6 || bals.Spendable != 1e6 {
+		t.Fatalf("unexpected account balances %+v", bals)
+	}
+}
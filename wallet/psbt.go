@@ -0,0 +1,651 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcutil/psbt"
+	h "github.com/btcsuite/btcwallet/internal/helpers"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet/internal/txsizes"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// DefaultPsbtLeaseDuration is the duration the inputs of a funded PSBT are
+// leased for by FundPsbt.
+const DefaultPsbtLeaseDuration = 10 * time.Minute
+
+// PsbtLockID is the lock ID the inputs of PSBTs funded by FundPsbt are leased
+// under.
+var PsbtLockID = wtxmgr.LockID{
+	0x70, 0x73, 0x62, 0x74, 0x66, 0x75, 0x6e, 0x64, // "psbtfund"
+}
+
+// ErrPsbtNoOutputs describes an error where a PSBT to be funded does not pay
+// to any outputs.
+var ErrPsbtNoOutputs = errors.New("PSBT has no outputs")
+
+// FundPsbt funds the outputs of the PSBT packet with outputs of account with
+// at least minconf confirmations, paying the fee rate feeSatPerKb.  If the
+// packet already has inputs, exactly these are spent and no further inputs
+// are selected.  Otherwise, inputs are selected with strategy.  A change
+// output is added if required.  The unsigned transaction, inputs and outputs
+// of the packet are replaced with those of the funded transaction, and each
+// input is populated with the previous output and key derivation information
+// required to sign it, as is the change output.  The packet is not signed,
+// so the wallet does not need to be unlocked.  Each input is leased under
+// PsbtLockID for DefaultPsbtLeaseDuration, as part of input selection, so it
+// is not spent by another transaction before the packet is signed and
+// published.  The leases are released if the packet can not be funded.  The
+// index of the change output is returned, or -1 if no change output was added.
+func (w *Wallet) FundPsbt(packet *psbt.Packet, account uint32, minconf int32,
+	feeSatPerKb btcutil.Amount, strategy CoinSelectionStrategy,
+	opts ...TxCreateOption) (int32, error) {
+
+	unsignedTx := packet.UnsignedTx
+	if len(unsignedTx.TxOut) == 0 {
+		return 0, ErrPsbtNoOutputs
+	}
+
+	if len(unsignedTx.TxIn) > 0 {
+		inputs := make([]wire.OutPoint, 0, len(unsignedTx.TxIn))
+		for _, txIn := range unsignedTx.TxIn {
+			inputs = append(inputs, txIn.PreviousOutPoint)
+		}
+		strategy = ManualCoinSelection{
+			Inputs:    inputs,
+			Exclusive: true,
+		}
+	}
+
+	o := applyTxCreateOptions(opts)
+	o.unsigned = true
+	o.leaseID = PsbtLockID
+	o.leaseDuration = DefaultPsbtLeaseDuration
+	feeSatPerKb, err := w.txFeeRate(feeSatPerKb, o)
+	if err != nil {
+		return 0, err
+	}
+	if !o.sendAll {
+		for _, output := range unsignedTx.TxOut {
+			err := txrules.CheckOutput(output, feeSatPerKb)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// Outputs keep their identity when the created transaction is
+	// ordered, which allows the output information of the caller to be
+	// carried over to the funded packet.
+	outputInfo := make(map[*wire.TxOut]psbt.POutput, len(unsignedTx.TxOut))
+	outputs := make([]*wire.TxOut, 0, len(unsignedTx.TxOut))
+	for i, txOut := range unsignedTx.TxOut {
+		output := wire.NewTxOut(txOut.Value, txOut.PkScript)
+		if i < len(packet.Outputs) {
+			outputInfo[output] = packet.Outputs[i]
+		}
+		outputs = append(outputs, output)
+	}
+
+	tx, err := w.createSimpleTx(
+		account, outputs, minconf, feeSatPerKb, strategy, o,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	pInputs := make([]psbt.PInput, len(tx.Tx.TxIn))
+	pOutputs := make([]psbt.POutput, len(tx.Tx.TxOut))
+	for i, txOut := range tx.Tx.TxOut {
+		pOutputs[i] = outputInfo[txOut]
+	}
+	err = walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+		for i, txIn := range tx.Tx.TxIn {
+			err := w.populatePsbtInput(
+				addrmgrNs, txmgrNs, &pInputs[i], txIn,
+				tx.PrevScripts[i],
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if tx.ChangeIndex >= 0 {
+			change := &pOutputs[tx.ChangeIndex]
+			pkScript := tx.Tx.TxOut[tx.ChangeIndex].PkScript
+			return w.populatePsbtOutput(addrmgrNs, change, pkScript)
+		}
+		return nil
+	})
+	if err != nil {
+		// The inputs were leased when the transaction was created, but
+		// the packet is not funded.
+		for _, txIn := range tx.Tx.TxIn {
+			relErr := w.ReleaseOutput(PsbtLockID, txIn.PreviousOutPoint)
+			if relErr != nil {
+				log.Errorf("Unable to release output %v: %v",
+					txIn.PreviousOutPoint, relErr)
+			}
+		}
+		return 0, err
+	}
+
+	packet.UnsignedTx = tx.Tx
+	packet.Inputs = pInputs
+	packet.Outputs = pOutputs
+	return int32(tx.ChangeIndex), nil
+}
+
+// SignPsbt signs every input of the PSBT packet spending an output controlled
+// by the wallet, adding a partial signature to the input.  Inputs spending
+// P2PKH, nested P2WPKH and P2WPKH outputs, and P2WSH outputs of multisig
+// accounts, are signed with the sighash type of the input, or SigHashAll if it
+// is unset.  Inputs which are already finalized, which spend outputs the
+// wallet does not control, only watches or of an unsupported type, or without
+// a previous output are left untouched.  An error is returned if the witness
+// script of an input spending a multisig account is not the script of the
+// spent output.  The wallet must be unlocked.  The indexes of the signed
+// inputs are returned.
+func (w *Wallet) SignPsbt(packet *psbt.Packet) ([]uint32, error) {
+	heldUnlock, err := w.holdUnlock()
+	if err != nil {
+		return nil, err
+	}
+	defer heldUnlock.release()
+
+	tx := packet.UnsignedTx
+	if len(packet.Inputs) != len(tx.TxIn) {
+		return nil, fmt.Errorf("PSBT has %d inputs but its transaction "+
+			"has %d", len(packet.Inputs), len(tx.TxIn))
+	}
+	sigHashes := txscript.NewTxSigHashes(tx)
+	signer := w.remoteSigner()
+
+	var signed []uint32
+	err = walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+
+		for i := range packet.Inputs {
+			pInput := &packet.Inputs[i]
+			if pInput.FinalScriptSig != nil ||
+				pInput.FinalScriptWitness != nil {
+
+				continue
+			}
+			prevOut := psbtPrevOutput(pInput, tx.TxIn[i])
+			if prevOut == nil {
+				continue
+			}
+			ok, err := w.signMultisigPsbtInput(
+				dbtx, tx, sigHashes, i, pInput, prevOut,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to sign input %d: %v",
+					i, err)
+			}
+			if ok {
+				signed = append(signed, uint32(i))
+				continue
+			}
+
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				prevOut.PkScript, w.chainParams,
+			)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+			managedAddr, err := w.Manager.Address(addrmgrNs, addrs[0])
+			switch {
+			case waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+				continue
+			case err != nil:
+				return err
+			}
+			addr, ok := managedAddr.(waddrmgr.ManagedPubKeyAddress)
+			if !ok {
+				continue
+			}
+
+			ok, err = signPsbtInput(
+				signer, tx, sigHashes, i, pInput, prevOut, addr,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to sign input %d: %v",
+					i, err)
+			}
+			if ok {
+				signed = append(signed, uint32(i))
+			}
+		}
+		return nil
+	})
+	return signed, err
+}
+
+// FinalizePsbt finalizes every input of the fully signed PSBT packet and
+// extracts the final transaction.  The signatures of the transaction are
+// validated against the previous outputs described by the packet.  If
+// publish is true, the transaction is also recorded by the wallet and
+// published to the network.
+func (w *Wallet) FinalizePsbt(packet *psbt.Packet, publish bool) (*wire.MsgTx, error) {
+	if len(packet.Inputs) != len(packet.UnsignedTx.TxIn) {
+		return nil, fmt.Errorf("PSBT has %d inputs but its transaction "+
+			"has %d", len(packet.Inputs), len(packet.UnsignedTx.TxIn))
+	}
+	prevScripts := make([][]byte, len(packet.Inputs))
+	inputValues := make([]btcutil.Amount, len(packet.Inputs))
+	for i := range packet.Inputs {
+		prevOut := psbtPrevOutput(&packet.Inputs[i],
+			packet.UnsignedTx.TxIn[i])
+		if prevOut == nil {
+			return nil, fmt.Errorf("previous output of input %d "+
+				"is unknown", i)
+		}
+		prevScripts[i] = prevOut.PkScript
+		inputValues[i] = btcutil.Amount(prevOut.Value)
+	}
+
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return nil, fmt.Errorf("unable to finalize PSBT: %v", err)
+	}
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, err
+	}
+	err = validateMsgTx(tx, prevScripts, inputValues)
+	if err != nil {
+		return nil, err
+	}
+
+	if publish {
+		if _, err := w.publishTransaction(tx); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// PsbtInputAnalysis describes an input of a PSBT analyzed by AnalyzePsbt.
+type PsbtInputAnalysis struct {
+	// PrevOutputKnown is whether the packet describes the output spent by
+	// the input.  The remaining fields are only set if it does.
+	PrevOutputKnown bool
+
+	// Mine is whether the spent output is controlled by the wallet, and
+	// Account is the account of its address.
+	Mine    bool
+	Account uint32
+
+	// Finalized is whether the input has a final signature script or
+	// witness, and Signed is whether it has any partial signatures.
+	Finalized bool
+	Signed    bool
+}
+
+// PsbtAnalysis describes a PSBT before it is signed, as reported by
+// AnalyzePsbt.
+type PsbtAnalysis struct {
+	Inputs []PsbtInputAnalysis
+
+	// MissingSignatures is the number of inputs which are neither
+	// finalized nor have any partial signatures.
+	MissingSignatures int
+
+	// EstimatedVSize is the estimated virtual size of the final
+	// transaction.
+	EstimatedVSize int
+
+	// Fee and FeeRate, in satoshis per kilobyte, are the fee paid by the
+	// final transaction.  They are only set if FeeKnown is true, which
+	// requires the previous output of every input to be known.
+	FeeKnown bool
+	Fee      btcutil.Amount
+	FeeRate  btcutil.Amount
+}
+
+// AnalyzePsbt reports which inputs of the PSBT packet spend outputs controlled
+// by the wallet, which inputs are missing signatures, and the estimated size
+// and fee rate of the final transaction, so the packet can be reviewed before
+// it is signed.  The packet is not modified.
+func (w *Wallet) AnalyzePsbt(packet *psbt.Packet) (*PsbtAnalysis, error) {
+	tx := packet.UnsignedTx
+	if len(packet.Inputs) != len(tx.TxIn) {
+		return nil, fmt.Errorf("PSBT has %d inputs but its transaction "+
+			"has %d", len(packet.Inputs), len(tx.TxIn))
+	}
+
+	analysis := &PsbtAnalysis{
+		Inputs:   make([]PsbtInputAnalysis, len(packet.Inputs)),
+		FeeKnown: true,
+	}
+	var (
+		p2pkh, p2tr, p2wpkh, nested int
+		totalInput                  btcutil.Amount
+	)
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+
+		for i := range packet.Inputs {
+			pInput := &packet.Inputs[i]
+			input := &analysis.Inputs[i]
+			input.Finalized = pInput.FinalScriptSig != nil ||
+				pInput.FinalScriptWitness != nil
+			input.Signed = len(pInput.PartialSigs) > 0
+			if !input.Finalized && !input.Signed {
+				analysis.MissingSignatures++
+			}
+
+			// Inputs of unknown type are estimated as P2PKH, which
+			// is the largest supported input.
+			prevOut := psbtPrevOutput(pInput, tx.TxIn[i])
+			if prevOut == nil {
+				analysis.FeeKnown = false
+				p2pkh++
+				continue
+			}
+			input.PrevOutputKnown = true
+			totalInput += btcutil.Amount(prevOut.Value)
+			switch {
+			case txscript.IsPayToScriptHash(prevOut.PkScript):
+				nested++
+			case txscript.IsPayToWitnessPubKeyHash(prevOut.PkScript):
+				p2wpkh++
+			case txsizes.IsP2TRPkScript(prevOut.PkScript):
+				p2tr++
+			default:
+				p2pkh++
+			}
+
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				prevOut.PkScript, w.chainParams,
+			)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+			_, account, err := w.Manager.AddrAccount(
+				addrmgrNs, addrs[0],
+			)
+			switch {
+			case waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+				continue
+			case err != nil:
+				return err
+			}
+			input.Mine = true
+			input.Account = account
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analysis.EstimatedVSize = txsizes.EstimateVirtualSize(
+		p2pkh, p2tr, p2wpkh, nested, tx.TxOut, false,
+	)
+	if analysis.FeeKnown {
+		analysis.Fee = totalInput - h.SumOutputValues(tx.TxOut)
+		analysis.FeeRate = analysis.Fee * 1000 /
+			btcutil.Amount(analysis.EstimatedVSize)
+	}
+	return analysis, nil
+}
+
+// psbtPrevOutput returns the output spent by txIn, as described by the PSBT
+// input pInput, or nil if the input does not describe it.
+func psbtPrevOutput(pInput *psbt.PInput, txIn *wire.TxIn) *wire.TxOut {
+	if pInput.WitnessUtxo != nil {
+		return pInput.WitnessUtxo
+	}
+	prevTx := pInput.NonWitnessUtxo
+	prevOut := &txIn.PreviousOutPoint
+	if prevTx == nil || prevTx.TxHash() != prevOut.Hash ||
+		int(prevOut.Index) >= len(prevTx.TxOut) {
+
+		return nil
+	}
+	return prevTx.TxOut[prevOut.Index]
+}
+
+// signPsbtInput adds a signature by the key of addr to input idx of tx, which
+// spends prevOut.  The signature is created by signer, or with the private key
+// of addr if signer is nil.  It returns false if the address type can not be
+// signed or the key of addr can not be signed for.
+func signPsbtInput(signer Signer, tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes, idx int, pInput *psbt.PInput,
+	prevOut *wire.TxOut, addr waddrmgr.ManagedPubKeyAddress) (bool, error) {
+
+	hashType := pInput.SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+
+	var redeemScript []byte
+	subScript, witness := prevOut.PkScript, true
+	switch addr.AddrType() {
+	case waddrmgr.PubKeyHash:
+		witness = false
+
+	case waddrmgr.WitnessPubKey:
+		// The previous output script is the signed witness program.
+
+	case waddrmgr.NestedWitnessPubKey:
+		var err error
+		redeemScript, err = nestedWitnessRedeemScript(addr)
+		if err != nil {
+			return false, err
+		}
+		subScript = redeemScript
+
+	default:
+		return false, nil
+	}
+
+	pubKey := addr.PubKey().SerializeUncompressed()
+	if addr.Compressed() {
+		pubKey = addr.PubKey().SerializeCompressed()
+	}
+
+	var sig []byte
+	if signer != nil {
+		derivation, ok := psbtDerivation(addr)
+		if !ok {
+			return false, nil
+		}
+		var err error
+		sig, err = signer.SignOutputRaw(tx, &SignDescriptor{
+			KeyPath:    derivation.Bip32Path,
+			PubKey:     pubKey,
+			SubScript:  subScript,
+			Amount:     prevOut.Value,
+			HashType:   hashType,
+			InputIndex: idx,
+			Witness:    witness,
+		})
+		if err != nil {
+			return false, err
+		}
+	} else {
+		privKey, err := addr.PrivKey()
+		if waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if witness {
+			sig, err = txscript.RawTxInWitnessSignature(
+				tx, sigHashes, idx, prevOut.Value, subScript,
+				hashType, privKey,
+			)
+		} else {
+			sig, err = txscript.RawTxInSignature(
+				tx, idx, subScript, hashType, privKey,
+			)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if redeemScript != nil {
+		pInput.RedeemScript = redeemScript
+	}
+	setPsbtPartialSig(pInput, pubKey, sig, hashType)
+	return true, nil
+}
+
+// setPsbtPartialSig adds the signature sig of pubKey to the partial
+// signatures of pInput, replacing any previous signature of the key.
+func setPsbtPartialSig(pInput *psbt.PInput, pubKey, sig []byte,
+	hashType txscript.SigHashType) {
+
+	pInput.SighashType = hashType
+	for _, partialSig := range pInput.PartialSigs {
+		if bytes.Equal(partialSig.PubKey, pubKey) {
+			partialSig.Signature = sig
+			return
+		}
+	}
+	pInput.PartialSigs = append(pInput.PartialSigs, &psbt.PartialSig{
+		PubKey:    pubKey,
+		Signature: sig,
+	})
+}
+
+// populatePsbtInput sets the previous output, redeem script and key
+// derivation information required to sign txIn, which spends the wallet
+// output with script prevScript.
+func (w *Wallet) populatePsbtInput(addrmgrNs walletdb.ReadBucket,
+	txmgrNs walletdb.ReadBucket, pInput *psbt.PInput, txIn *wire.TxIn,
+	prevScript []byte) error {
+
+	prevOut := &txIn.PreviousOutPoint
+	prevTx, err := w.TxStore.TxDetails(txmgrNs, &prevOut.Hash)
+	if err != nil {
+		return err
+	}
+	if prevTx == nil || int(prevOut.Index) >= len(prevTx.MsgTx.TxOut) {
+		return fmt.Errorf("previous output %v not found", prevOut)
+	}
+	pInput.NonWitnessUtxo = &prevTx.MsgTx
+	pInput.SighashType = txscript.SigHashAll
+
+	// The previous output is also included on its own for witness and
+	// script hash inputs, which do not require the previous transaction to
+	// be signed.
+	if txscript.IsWitnessProgram(prevScript) ||
+		txscript.IsPayToScriptHash(prevScript) {
+
+		pInput.WitnessUtxo = prevTx.MsgTx.TxOut[prevOut.Index]
+	}
+
+	addr, err := w.psbtPubKeyAddress(addrmgrNs, prevScript)
+	if err != nil {
+		return err
+	}
+	if addr.AddrType() == waddrmgr.NestedWitnessPubKey {
+		redeemScript, err := nestedWitnessRedeemScript(addr)
+		if err != nil {
+			return err
+		}
+		pInput.RedeemScript = redeemScript
+	}
+	if derivation, ok := psbtDerivation(addr); ok {
+		pInput.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
+	}
+	return nil
+}
+
+// populatePsbtOutput sets the redeem script and key derivation information of
+// the wallet output paying to pkScript.
+func (w *Wallet) populatePsbtOutput(addrmgrNs walletdb.ReadBucket,
+	pOutput *psbt.POutput, pkScript []byte) error {
+
+	addr, err := w.psbtPubKeyAddress(addrmgrNs, pkScript)
+	if err != nil {
+		return err
+	}
+	if addr.AddrType() == waddrmgr.NestedWitnessPubKey {
+		redeemScript, err := nestedWitnessRedeemScript(addr)
+		if err != nil {
+			return err
+		}
+		pOutput.RedeemScript = redeemScript
+	}
+	if derivation, ok := psbtDerivation(addr); ok {
+		pOutput.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
+	}
+	return nil
+}
+
+// psbtPubKeyAddress returns the wallet address pkScript pays to.
+func (w *Wallet) psbtPubKeyAddress(addrmgrNs walletdb.ReadBucket,
+	pkScript []byte) (waddrmgr.ManagedPubKeyAddress, error) {
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) != 1 {
+		return nil, fmt.Errorf("script %x does not pay to a single "+
+			"address", pkScript)
+	}
+	managedAddr, err := w.Manager.Address(addrmgrNs, addrs[0])
+	if err != nil {
+		return nil, err
+	}
+	addr, ok := managedAddr.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, fmt.Errorf("address %v is not a public key address",
+			addrs[0])
+	}
+	return addr, nil
+}
+
+// nestedWitnessRedeemScript returns the P2WPKH witness program a nested
+// witness address commits to.
+func nestedWitnessRedeemScript(addr waddrmgr.ManagedPubKeyAddress) ([]byte, error) {
+	pubKeyHash := btcutil.Hash160(addr.PubKey().SerializeCompressed())
+	return txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(pubKeyHash).Script()
+}
+
+// psbtDerivation returns the BIP0032 derivation of the key of addr.  Imported
+// addresses have no derivation.  The wallet does not store the fingerprint of
+// its master key, so it is left zero.
+func psbtDerivation(addr waddrmgr.ManagedPubKeyAddress) (*psbt.Bip32Derivation, bool) {
+	scope, path, ok := addr.DerivationInfo()
+	if !ok {
+		return nil, false
+	}
+	pubKey := addr.PubKey().SerializeUncompressed()
+	if addr.Compressed() {
+		pubKey = addr.PubKey().SerializeCompressed()
+	}
+	return &psbt.Bip32Derivation{
+		PubKey: pubKey,
+		Bip32Path: []uint32{
+			scope.Purpose + hdkeychain.HardenedKeyStart,
+			scope.Coin + hdkeychain.HardenedKeyStart,
+			path.Account + hdkeychain.HardenedKeyStart,
+			path.Branch,
+			path.Index,
+		},
+	}, true
+}
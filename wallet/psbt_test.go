@@ -0,0 +1,42 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/psbt"
+)
+
+func TestPsbtPrevOutput(t *testing.T) {
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxOut(wire.NewTxOut(1e8, []byte{0x51}))
+	txIn := wire.NewTxIn(&wire.OutPoint{Hash: prevTx.TxHash()}, nil, nil)
+
+	// Without any previous output information the output is unknown.
+	if prevOut := psbtPrevOutput(&psbt.PInput{}, txIn); prevOut != nil {
+		t.Fatalf("expected no previous output, got %v", prevOut)
+	}
+
+	pInput := &psbt.PInput{NonWitnessUtxo: prevTx}
+	if prevOut := psbtPrevOutput(pInput, txIn); prevOut != prevTx.TxOut[0] {
+		t.Fatalf("expected output of previous transaction, got %v",
+			prevOut)
+	}
+
+	// A previous transaction which is not spent by the input is ignored.
+	otherIn := wire.NewTxIn(&wire.OutPoint{Index: 0}, nil, nil)
+	if prevOut := psbtPrevOutput(pInput, otherIn); prevOut != nil {
+		t.Fatalf("expected no previous output, got %v", prevOut)
+	}
+
+	// The witness output takes precedence.
+	witnessUtxo := wire.NewTxOut(2e8, []byte{0x52})
+	pInput.WitnessUtxo = witnessUtxo
+	if prevOut := psbtPrevOutput(pInput, otherIn); prevOut != witnessUtxo {
+		t.Fatalf("expected witness output, got %v", prevOut)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import "time"
+
+// StartRebroadcaster resends the unmined transactions of the wallet to the
+// chain backend every interval until the wallet is stopped, so transactions
+// evicted from the mempools of the network are relayed again until they are
+// mined.  Transactions rejected by the backend as double spent or invalid are
+// abandoned and removed from the wallet, as when they are resent after the
+// wallet synchronizes.  It must be called after the wallet is started.
+func (w *Wallet) StartRebroadcaster(interval time.Duration) {
+	w.wg.Add(1)
+	go w.rebroadcaster(interval)
+}
+
+func (w *Wallet) rebroadcaster(interval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	quit := w.quitChan()
+	for {
+		select {
+		case <-ticker.C:
+			// Transactions are only resent by a synced wallet, as
+			// the backend rejects transactions spending outputs
+			// it has not yet seen.
+			if w.ChainClient() == nil || !w.ChainSynced() {
+				continue
+			}
+			log.Debugf("Rebroadcasting unmined transactions")
+			w.resendUnminedTxs()
+
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/chain"
+)
+
+// TestRebroadcaster ensures that the unmined transactions of the wallet are
+// only resent once it is synced, and that transactions the backend rejects as
+// double spent are removed.
+func TestRebroadcaster(t *testing.T) {
+	w, chainClient, cleanup := testWallet(t)
+	defer cleanup()
+
+	tx := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	rec := insertUnminedTx(t, w, tx)
+
+	w.StartRebroadcaster(time.Millisecond)
+
+	// Nothing is resent while the wallet is not synced.
+	time.Sleep(50 * time.Millisecond)
+	if sent := chainClient.sentTxs(); len(sent) != 0 {
+		t.Fatalf("unsynced wallet resent %d transactions", len(sent))
+	}
+
+	w.SetChainSynced(true)
+	waitFor(t, "resent transaction", func() bool {
+		return len(chainClient.sentTxs()) > 0
+	})
+	if hash := chainClient.sentTxs()[0].TxHash(); hash != rec.Hash {
+		t.Fatalf("expected %v to be resent, got %v", rec.Hash, hash)
+	}
+	if hashes := unminedTxHashes(t, w); len(hashes) != 1 {
+		t.Fatalf("expected relayed transaction to remain unmined, "+
+			"got %v", hashes)
+	}
+
+	// A transaction rejected as double spent is abandoned.
+	chainClient.setSendErr(chain.ErrDoubleSpend)
+	waitFor(t, "rejected transaction removal", func() bool {
+		return len(unminedTxHashes(t, w)) == 0
+	})
+}
+
+// TestResendUnminedTxsKeepsUnrejected ensures that transactions which fail to
+// be resent for reasons other than a rejection remain recorded.
+func TestResendUnminedTxsKeepsUnrejected(t *testing.T) {
+	w, chainClient, cleanup := testWallet(t)
+	defer cleanup()
+
+	tx := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	insertUnminedTx(t, w, tx)
+
+	chainClient.setSendErr(errNotImplemented)
+	w.resendUnminedTxs()
+	if len(chainClient.sentTxs()) != 1 {
+		t.Fatal("expected transaction to be resent")
+	}
+	if hashes := unminedTxHashes(t, w); len(hashes) != 1 {
+		t.Fatalf("expected transaction to remain unmined, got %v",
+			hashes)
+	}
+}
@@ -180,6 +180,52 @@ func (rm *RecoveryManager) State() *RecoveryState {
 	return rm.state
 }
 
+// RecoveryInfo describes the recovery of the addresses of the wallet's seed,
+// which the wallet performs when synchronizing with the chain backend using a
+// nonzero recovery window.
+type RecoveryInfo struct {
+	// Recovering is whether the wallet is recovering addresses, or will
+	// when it next synchronizes with the chain backend.
+	Recovering bool
+
+	// Finished is whether the recovery scanned through the best block of
+	// the chain backend.
+	Finished bool
+
+	// Window is the recovery window of the recovery.
+	Window uint32
+
+	// Progress is the fraction, between zero and one, of the blocks
+	// scanned by the recovery.
+	Progress float64
+}
+
+// recoveryProgress records the blocks scanned by a recovery, from the block
+// the synchronization started at through the best block of the chain backend.
+type recoveryProgress struct {
+	window      uint32
+	startHeight int32
+	height      int32 // last block scanned
+	bestHeight  int32
+	finished    bool
+}
+
+// fraction returns the fraction of the blocks scanned by the recovery.
+func (p *recoveryProgress) fraction() float64 {
+	if p.finished {
+		return 1
+	}
+	total := p.bestHeight - p.startHeight + 1
+	scanned := p.height - p.startHeight + 1
+	switch {
+	case total <= 0 || scanned <= 0:
+		return 0
+	case scanned >= total:
+		return 1
+	}
+	return float64(scanned) / float64(total)
+}
+
 // RecoveryState manages the initialization and lookup of ScopeRecoveryStates
 // for any actively used key scopes.
 //
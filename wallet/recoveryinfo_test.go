@@ -0,0 +1,137 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+)
+
+// TestRecoveryProgressFraction ensures that the fraction of a recovery is
+// bounded by zero and one, and only reaches one once all blocks through the
+// best block were scanned.
+func TestRecoveryProgressFraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress recoveryProgress
+		fraction float64
+	}{
+		{
+			name: "not started",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      99,
+				bestHeight:  199,
+			},
+			fraction: 0,
+		},
+		{
+			name: "halfway",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      149,
+				bestHeight:  199,
+			},
+			fraction: 0.5,
+		},
+		{
+			name: "best block scanned",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      199,
+				bestHeight:  199,
+			},
+			fraction: 1,
+		},
+		{
+			name: "past best block",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      250,
+				bestHeight:  199,
+			},
+			fraction: 1,
+		},
+		{
+			name: "best block below start",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      99,
+				bestHeight:  50,
+			},
+			fraction: 0,
+		},
+		{
+			name: "finished",
+			progress: recoveryProgress{
+				startHeight: 100,
+				height:      99,
+				bestHeight:  199,
+				finished:    true,
+			},
+			fraction: 1,
+		},
+	}
+	for _, test := range tests {
+		if fraction := test.progress.fraction(); fraction != test.fraction {
+			t.Errorf("%s: expected fraction %v, got %v", test.name,
+				test.fraction, fraction)
+		}
+	}
+}
+
+// TestRecoveryInfo ensures that the recovery window is reported before a
+// recovery begins, and that the progress of a running recovery is reported
+// until it finished.
+func TestRecoveryInfo(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	check := func(desc string, exp RecoveryInfo) {
+		t.Helper()
+		if info := w.RecoveryInfo(); *info != exp {
+			t.Fatalf("%s: expected %+v, got %+v", desc, exp, *info)
+		}
+	}
+
+	w.SetRecoveryWindow(0)
+	check("no recovery", RecoveryInfo{})
+
+	w.SetRecoveryWindow(250)
+	check("pending recovery", RecoveryInfo{Recovering: true, Window: 250})
+
+	w.setRecoveryProgress(recoveryProgress{
+		window:      250,
+		startHeight: 1,
+		height:      25,
+		bestHeight:  100,
+	})
+	check("running recovery", RecoveryInfo{
+		Recovering: true,
+		Window:     250,
+		Progress:   0.25,
+	})
+
+	// The window of the running recovery is reported, even once the
+	// window of the wallet changed.
+	w.SetRecoveryWindow(0)
+	check("running recovery after window change", RecoveryInfo{
+		Recovering: true,
+		Window:     250,
+		Progress:   0.25,
+	})
+
+	w.setRecoveryProgress(recoveryProgress{
+		window:      250,
+		startHeight: 1,
+		height:      100,
+		bestHeight:  100,
+		finished:    true,
+	})
+	check("finished recovery", RecoveryInfo{
+		Finished: true,
+		Window:   250,
+		Progress: 1,
+	})
+}
@@ -5,19 +5,50 @@
 package wallet
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcwallet/chain"
 	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
 	"github.com/btcsuite/btcwallet/wtxmgr"
 )
 
+// rescanCheckpointInterval is the minimum time between two checkpoints of the
+// progress of a running rescan.
+const rescanCheckpointInterval = 30 * time.Second
+
+// rescanCheckpointKey is the key of the rescan namespace holding the
+// checkpoint of the running rescans, if any.
+var rescanCheckpointKey = []byte("checkpoint")
+
+// ErrRescanCancelUnsupported is returned by CancelRescan when the chain
+// backend does not support canceling rescans.
+var ErrRescanCancelUnsupported = errors.New("chain backend does not " +
+	"support canceling rescans")
+
+// rescanCanceler is implemented by the chain backends which can cancel a
+// running rescan.
+type rescanCanceler interface {
+	CancelRescan() error
+}
+
 // RescanProgressMsg reports the current progress made by a rescan for a
 // set of wallet addresses.
 type RescanProgressMsg struct {
 	Addresses    []btcutil.Address
 	Notification *chain.RescanProgress
+
+	// StartHeight and StartTime describe where and when the rescan
+	// reporting the progress started.
+	StartHeight int32
+	StartTime   time.Time
 }
 
 // RescanFinishedMsg reports the addresses that were rescanned when a
@@ -48,6 +79,8 @@ type rescanBatch struct {
 	outpoints   map[wire.OutPoint]btcutil.Address
 	bs          waddrmgr.BlockStamp
 	errChans    []chan error
+	started     time.Time
+	doneOnce    sync.Once
 }
 
 // SubmitRescan submits a RescanJob to the RescanManager.  A channel is
@@ -80,6 +113,10 @@ func (b *rescanBatch) merge(job *RescanJob) {
 	}
 	b.addrs = append(b.addrs, job.Addrs...)
 
+	if b.outpoints == nil && len(job.OutPoints) != 0 {
+		b.outpoints = make(map[wire.OutPoint]btcutil.Address,
+			len(job.OutPoints))
+	}
 	for op, addr := range job.OutPoints {
 		b.outpoints[op] = addr
 	}
@@ -92,18 +129,132 @@ func (b *rescanBatch) merge(job *RescanJob) {
 
 // done iterates through all error channels, duplicating sending the error
 // to inform callers that the rescan finished (or could not complete due
-// to an error).
+// to an error).  Only the first call informs callers, as a running batch is
+// both finished by the rescan RPC and canceled by CancelRescan.
 func (b *rescanBatch) done(err error) {
-	for _, c := range b.errChans {
-		c <- err
+	b.doneOnce.Do(func() {
+		for _, c := range b.errChans {
+			c <- err
+		}
+	})
+}
+
+// rescanCheckpoint returns the block a rescan must resume from after a restart
+// to complete the work of the current batch, which has processed through
+// progress unless nil, and of the next batch unless nil.  Nil is returned when
+// there is no rescan to resume.
+func rescanCheckpoint(cur, next *rescanBatch,
+	progress *waddrmgr.BlockStamp) *waddrmgr.BlockStamp {
+
+	var checkpoint *waddrmgr.BlockStamp
+	if cur != nil {
+		checkpoint = &cur.bs
+		if progress != nil {
+			checkpoint = progress
+		}
+	}
+	if next != nil && (checkpoint == nil || next.bs.Height < checkpoint.Height) {
+		checkpoint = &next.bs
+	}
+	return checkpoint
+}
+
+// putRescanCheckpoint records the block to resume rescans from, or removes the
+// checkpoint when bs is nil.
+//
+// The serialized checkpoint is:
+//
+//	[0:32]  Block hash (32 bytes)
+//	[32:36] Block height (4 bytes)
+//	[36:44] Block timestamp, unix seconds (8 bytes)
+func putRescanCheckpoint(ns walletdb.ReadWriteBucket,
+	bs *waddrmgr.BlockStamp) error {
+
+	if bs == nil {
+		return ns.Delete(rescanCheckpointKey)
+	}
+	v := make([]byte, 44)
+	copy(v, bs.Hash[:])
+	binary.LittleEndian.PutUint32(v[32:], uint32(bs.Height))
+	binary.LittleEndian.PutUint64(v[36:], uint64(bs.Timestamp.Unix()))
+	return ns.Put(rescanCheckpointKey, v)
+}
+
+// fetchRescanCheckpoint returns the block to resume rescans from, or nil when
+// no rescan was interrupted.
+func fetchRescanCheckpoint(ns walletdb.ReadBucket) (*waddrmgr.BlockStamp, error) {
+	v := ns.Get(rescanCheckpointKey)
+	if v == nil {
+		return nil, nil
+	}
+	if len(v) != 44 {
+		return nil, fmt.Errorf("rescan checkpoint: short read (%d bytes)",
+			len(v))
+	}
+	bs := &waddrmgr.BlockStamp{
+		Height:    int32(binary.LittleEndian.Uint32(v[32:])),
+		Timestamp: time.Unix(int64(binary.LittleEndian.Uint64(v[36:])), 0),
+	}
+	copy(bs.Hash[:], v[:32])
+	return bs, nil
+}
+
+// saveRescanCheckpoint persists the block to resume rescans from, so that a
+// rescan interrupted by a restart continues where it left off rather than
+// being lost or starting over.
+func (w *Wallet) saveRescanCheckpoint(bs *waddrmgr.BlockStamp) {
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(rescanNamespaceKey)
+		return putRescanCheckpoint(ns, bs)
+	})
+	if err != nil {
+		log.Errorf("Unable to save rescan checkpoint: %v", err)
 	}
 }
 
+// rescanResumeBlock returns the block to resume an interrupted rescan from, or
+// nil when no rescan was interrupted.  The checkpoint block is replaced by the
+// main chain block at its height if it has been reorged out.
+func (w *Wallet) rescanResumeBlock(
+	chainClient chain.Interface) (*waddrmgr.BlockStamp, error) {
+
+	var checkpoint *waddrmgr.BlockStamp
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		var err error
+		checkpoint, err = fetchRescanCheckpoint(
+			tx.ReadBucket(rescanNamespaceKey),
+		)
+		return err
+	})
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+
+	hash, err := chainClient.GetBlockHash(int64(checkpoint.Height))
+	if err != nil {
+		return nil, err
+	}
+	if *hash != checkpoint.Hash {
+		header, err := chainClient.GetBlockHeader(hash)
+		if err != nil {
+			return nil, err
+		}
+		checkpoint = &waddrmgr.BlockStamp{
+			Hash:      *hash,
+			Height:    checkpoint.Height,
+			Timestamp: header.Timestamp,
+		}
+	}
+	return checkpoint, nil
+}
+
 // rescanBatchHandler handles incoming rescan request, serializing rescan
 // submissions, and possibly batching many waiting requests together so they
-// can be handled by a single rescan after the current one completes.
+// can be handled by a single rescan after the current one completes.  The
+// progress of the rescans is checkpointed in the database.
 func (w *Wallet) rescanBatchHandler() {
 	var curBatch, nextBatch *rescanBatch
+	var lastCheckpoint time.Time
 	quit := w.quitChan()
 
 out:
@@ -114,7 +265,14 @@ out:
 				// Set current batch as this job and send
 				// request.
 				curBatch = job.batch()
-				w.rescanBatch <- curBatch
+				curBatch.started = time.Now()
+				w.saveRescanCheckpoint(&curBatch.bs)
+				lastCheckpoint = time.Now()
+				select {
+				case w.rescanBatch <- curBatch:
+				case <-quit:
+					break out
+				}
 			} else {
 				// Create next batch if it doesn't exist, or
 				// merge the job.
@@ -123,8 +281,46 @@ out:
 				} else {
 					nextBatch.merge(job)
 				}
+				if job.BlockStamp.Height < curBatch.bs.Height {
+					w.saveRescanCheckpoint(&job.BlockStamp)
+					lastCheckpoint = time.Now()
+				}
 			}
 
+		case errChan := <-w.rescanCancel:
+			if curBatch == nil {
+				errChan <- chain.ErrNoRescan
+				continue
+			}
+			if err := w.cancelRescan(); err != nil {
+				errChan <- err
+				continue
+			}
+
+			// The checkpoint is left as is, so the canceled
+			// work resumes from the last committed checkpoint
+			// after a restart.  Jobs waiting for the next batch
+			// are canceled as well.
+			log.Infof("Canceled rescan from block %v (height %d)",
+				curBatch.bs.Hash, curBatch.bs.Height)
+			curBatch.done(chain.ErrRescanCanceled)
+			initialSync := curBatch.initialSync
+			if nextBatch != nil {
+				nextBatch.done(chain.ErrRescanCanceled)
+				initialSync = initialSync || nextBatch.initialSync
+			}
+			curBatch, nextBatch = nil, nil
+
+			// The blocks the canceled rescans did not reach were
+			// not scanned, so a wallet performing its initial sync
+			// is not marked synced until another rescan finishes.
+			if initialSync && !w.ChainSynced() {
+				log.Warnf("Wallet is not synced to the best block " +
+					"until a rescan finishes, such as the rescan " +
+					"resuming from the checkpoint on restart")
+			}
+			errChan <- nil
+
 		case n := <-w.rescanNotifications:
 			switch n := n.(type) {
 			case *chain.RescanProgress:
@@ -134,9 +330,27 @@ out:
 						"currently running")
 					continue
 				}
-				w.rescanProgress <- &RescanProgressMsg{
+				if time.Since(lastCheckpoint) >= rescanCheckpointInterval {
+					progress := &waddrmgr.BlockStamp{
+						Hash:      *n.Hash,
+						Height:    n.Height,
+						Timestamp: n.Time,
+					}
+					w.saveRescanCheckpoint(rescanCheckpoint(
+						curBatch, nextBatch, progress,
+					))
+					lastCheckpoint = time.Now()
+				}
+				msg := &RescanProgressMsg{
 					Addresses:    curBatch.addrs,
 					Notification: n,
+					StartHeight:  curBatch.bs.Height,
+					StartTime:    curBatch.started,
+				}
+				select {
+				case w.rescanProgress <- msg:
+				case <-quit:
+					break out
 				}
 
 			case *chain.RescanFinished:
@@ -146,15 +360,29 @@ out:
 						"currently running")
 					continue
 				}
-				w.rescanFinished <- &RescanFinishedMsg{
+				msg := &RescanFinishedMsg{
 					Addresses:    curBatch.addrs,
 					Notification: n,
 				}
+				select {
+				case w.rescanFinished <- msg:
+				case <-quit:
+					break out
+				}
 
 				curBatch, nextBatch = nextBatch, nil
+				w.saveRescanCheckpoint(rescanCheckpoint(
+					curBatch, nil, nil,
+				))
+				lastCheckpoint = time.Now()
 
 				if curBatch != nil {
-					w.rescanBatch <- curBatch
+					curBatch.started = time.Now()
+					select {
+					case w.rescanBatch <- curBatch:
+					case <-quit:
+						break out
+					}
 				}
 
 			default:
@@ -170,10 +398,76 @@ out:
 	w.wg.Done()
 }
 
+// cancelRescan cancels the rescan running on the chain backend.
+func (w *Wallet) cancelRescan() error {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return err
+	}
+	canceler, ok := chainClient.(rescanCanceler)
+	if !ok {
+		return ErrRescanCancelUnsupported
+	}
+	return canceler.CancelRescan()
+}
+
+// rescanTargetHeight returns the height a rescan which has processed through
+// height completes at: the best block height of the chain server.
+func (w *Wallet) rescanTargetHeight(height int32) int32 {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return height
+	}
+	_, bestHeight, err := chainClient.GetBestBlock()
+	if err != nil {
+		log.Warnf("Unable to query best block to estimate rescan "+
+			"progress: %v", err)
+		return height
+	}
+	if bestHeight < height {
+		return height
+	}
+	return bestHeight
+}
+
+// newRescanProgressNotification returns the progress of a rescan from
+// startHeight to targetHeight, started at startTime, which has processed
+// through the block of n at now.  The time remaining is estimated from the
+// rate blocks have been processed at so far.
+func newRescanProgressNotification(n *chain.RescanProgress, startHeight,
+	targetHeight int32, startTime, now time.Time) *RescanProgressNotification {
+
+	p := &RescanProgressNotification{
+		Hash:         *n.Hash,
+		Height:       n.Height,
+		TargetHeight: targetHeight,
+		Percent:      100,
+	}
+	total := targetHeight - startHeight
+	done := n.Height - startHeight
+	if total <= 0 || done >= total {
+		return p
+	}
+	if done <= 0 {
+		p.Percent = 0
+		return p
+	}
+	p.Percent = 100 * float64(done) / float64(total)
+	elapsed := now.Sub(startTime)
+	p.Remaining = time.Duration(float64(elapsed) *
+		float64(total-done) / float64(done))
+	return p
+}
+
 // rescanProgressHandler handles notifications for partially and fully completed
 // rescans by marking each rescanned address as partially or fully synced.
+// Progress is logged and sent to RescanProgressNotifications clients.
 func (w *Wallet) rescanProgressHandler() {
 	quit := w.quitChan()
+
+	// The target height of the running rescan, queried with its first
+	// progress notification.
+	var targetHeight int32
 out:
 	for {
 		// These can't be processed out of order since both chans are
@@ -182,8 +476,15 @@ out:
 		select {
 		case msg := <-w.rescanProgress:
 			n := msg.Notification
-			log.Infof("Rescanned through block %v (height %d)",
-				n.Hash, n.Height)
+			if targetHeight < n.Height {
+				targetHeight = w.rescanTargetHeight(n.Height)
+			}
+			p := newRescanProgressNotification(n, msg.StartHeight,
+				targetHeight, msg.StartTime, time.Now())
+			log.Infof("Rescanned through block %v (height %d, "+
+				"%.2f%%, %v remaining)", n.Hash, n.Height,
+				p.Percent, p.Remaining.Round(time.Second))
+			w.NtfnServer.notifyRescanProgress(p)
 
 		case msg := <-w.rescanFinished:
 			n := msg.Notification
@@ -193,6 +494,17 @@ out:
 				"%s, height %d)", len(addrs), noun, n.Hash,
 				n.Height)
 
+			if targetHeight < n.Height {
+				targetHeight = n.Height
+			}
+			w.NtfnServer.notifyRescanProgress(&RescanProgressNotification{
+				Hash:         *n.Hash,
+				Height:       n.Height,
+				TargetHeight: targetHeight,
+				Percent:      100,
+			})
+			targetHeight = 0
+
 			go w.resendUnminedTxs()
 
 		case <-quit:
@@ -248,6 +560,63 @@ func (w *Wallet) Rescan(addrs []btcutil.Address, unspent []wtxmgr.Credit) error
 	return w.rescanWithTarget(addrs, unspent, nil)
 }
 
+// RescanFor rescans the blocks from startHeight through the best block only for
+// transactions paying to addrs or spending outpoints, rather than for all
+// addresses and unspent outputs of the wallet, so that importing a few
+// addresses does not require rescanning the whole wallet.  Relevant
+// transactions are recorded as with any other rescan, so the addresses must
+// belong to the wallet.  RescanFor blocks until the rescan completes.
+func (w *Wallet) RescanFor(addrs []btcutil.Address,
+	outpoints map[wire.OutPoint]btcutil.Address, startHeight int32) error {
+
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 && len(outpoints) == 0 {
+		return nil
+	}
+
+	hash, err := chainClient.GetBlockHash(int64(startHeight))
+	if err != nil {
+		return err
+	}
+
+	// The outpoints of later jobs are merged into the map of the job, so
+	// it must not be the caller's.
+	ops := make(map[wire.OutPoint]btcutil.Address, len(outpoints))
+	for op, addr := range outpoints {
+		ops[op] = addr
+	}
+
+	job := &RescanJob{
+		Addrs:     addrs,
+		OutPoints: ops,
+		BlockStamp: waddrmgr.BlockStamp{
+			Hash:   *hash,
+			Height: startHeight,
+		},
+	}
+	return <-w.SubmitRescan(job)
+}
+
+// CancelRescan aborts the running rescan, and the rescans waiting for it to
+// finish, which return chain.ErrRescanCanceled.  The transactions found before
+// the rescan was canceled are kept.  The progress of the rescan is not lost:
+// its last checkpoint remains, so the rescan resumes from it when the wallet is
+// next restarted.  A wallet whose initial sync rescan is canceled remains
+// unsynced until a later rescan finishes.  chain.ErrNoRescan is returned when
+// no rescan is running.
+func (w *Wallet) CancelRescan() error {
+	errChan := make(chan error, 1)
+	select {
+	case w.rescanCancel <- errChan:
+	case <-w.quitChan():
+		return chain.ErrNoRescan
+	}
+	return <-errChan
+}
+
 // rescanWithTarget performs a rescan starting at the optional startStamp. If
 // none is provided, the rescan will begin from the manager's sync tip.
 func (w *Wallet) rescanWithTarget(addrs []btcutil.Address,
@@ -0,0 +1,355 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+)
+
+// rescanRequest describes a rescan performed by a rescanChainClient.
+type rescanRequest struct {
+	start     chainhash.Hash
+	addrs     []btcutil.Address
+	outpoints map[wire.OutPoint]btcutil.Address
+}
+
+// rescanChainClient is a mockChainClient recording the rescans it performs.
+// The hash of the block at each height is the hash whose first byte is the
+// height.
+type rescanChainClient struct {
+	*mockChainClient
+	rescans   chan rescanRequest
+	rescanErr error
+}
+
+func newRescanChainClient(c *mockChainClient) *rescanChainClient {
+	return &rescanChainClient{
+		mockChainClient: c,
+		rescans:         make(chan rescanRequest, 1),
+	}
+}
+
+// setRescanErr sets the error returned by rescans.
+func (c *rescanChainClient) setRescanErr(err error) {
+	c.mu.Lock()
+	c.rescanErr = err
+	c.mu.Unlock()
+}
+
+func (c *rescanChainClient) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	if height < 0 || height > 255 {
+		return nil, errors.New("block height out of range")
+	}
+	return &chainhash.Hash{byte(height)}, nil
+}
+
+func (c *rescanChainClient) Rescan(start *chainhash.Hash,
+	addrs []btcutil.Address,
+	outpoints map[wire.OutPoint]btcutil.Address) error {
+
+	c.rescans <- rescanRequest{
+		start:     *start,
+		addrs:     addrs,
+		outpoints: outpoints,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rescanErr
+}
+
+// startRescanHandlers attaches chainClient to the wallet and starts the
+// goroutines performing its rescans.
+func startRescanHandlers(w *Wallet, chainClient chain.Interface) {
+	w.chainClient = chainClient
+	w.wg.Add(3)
+	go w.rescanBatchHandler()
+	go w.rescanProgressHandler()
+	go w.rescanRPCHandler()
+}
+
+// nextRescan returns the next rescan performed by chainClient.
+func nextRescan(t *testing.T, chainClient *rescanChainClient) rescanRequest {
+	select {
+	case req := <-chainClient.rescans:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("no rescan was performed")
+		return rescanRequest{}
+	}
+}
+
+// finishRescan notifies the wallet that the running rescan finished at the
+// block at height, and waits until the wallet notified progress that the
+// rescan finished.
+func finishRescan(t *testing.T, w *Wallet,
+	progress RescanProgressNotificationsClient, height int32) {
+
+	w.rescanNotifications <- &chain.RescanFinished{
+		Hash:   &chainhash.Hash{byte(height)},
+		Height: height,
+	}
+	for {
+		select {
+		case n := <-progress.C:
+			if n.Percent == 100 && n.Height == height {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("rescan finish was not notified")
+		}
+	}
+}
+
+// TestRescanFor ensures that RescanFor rescans only for the provided addresses
+// and outpoints from the block at the start height, and returns the result of
+// the rescan.
+func TestRescanFor(t *testing.T) {
+	w, mock, cleanup := testWallet(t)
+	defer cleanup()
+	chainClient := newRescanChainClient(mock)
+	startRescanHandlers(w, chainClient)
+	progress := w.NtfnServer.RescanProgressNotifications()
+	defer progress.Done()
+
+	// There is nothing to rescan for without addresses and outpoints.
+	if err := w.RescanFor(nil, nil, 10); err != nil {
+		t.Fatalf("unable to rescan for nothing: %v", err)
+	}
+	select {
+	case req := <-chainClient.rescans:
+		t.Fatalf("unexpected rescan from block %v", req.start)
+	default:
+	}
+
+	addr, err := w.NewAddress(
+		waddrmgr.DefaultAccountNum, waddrmgr.KeyScopeBIP0084,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := wire.OutPoint{Hash: chainhash.Hash{1}}
+	outpoints := map[wire.OutPoint]btcutil.Address{op: addr}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- w.RescanFor([]btcutil.Address{addr}, outpoints, 10)
+	}()
+	req := nextRescan(t, chainClient)
+	if req.start != (chainhash.Hash{10}) {
+		t.Fatalf("expected rescan from block %v, got %v",
+			chainhash.Hash{10}, req.start)
+	}
+	if len(req.addrs) != 1 || req.addrs[0].String() != addr.String() {
+		t.Fatalf("expected rescan for address %v, got %v", addr,
+			req.addrs)
+	}
+	if len(req.outpoints) != 1 || req.outpoints[op] != addr {
+		t.Fatalf("expected rescan for outpoint %v, got %v", op,
+			req.outpoints)
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unable to rescan: %v", err)
+	}
+
+	// The rescan was passed a copy of the outpoints of the caller.
+	delete(req.outpoints, op)
+	if _, ok := outpoints[op]; !ok {
+		t.Fatal("outpoints of the caller modified by the rescan")
+	}
+	finishRescan(t, w, progress, 20)
+
+	// Errors of the rescan are returned.
+	rescanErr := errors.New("rescan failed")
+	chainClient.setRescanErr(rescanErr)
+	go func() {
+		errChan <- w.RescanFor([]btcutil.Address{addr}, nil, 5)
+	}()
+	if req := nextRescan(t, chainClient); req.start != (chainhash.Hash{5}) {
+		t.Fatalf("expected rescan from block %v, got %v",
+			chainhash.Hash{5}, req.start)
+	}
+	if err := <-errChan; err != rescanErr {
+		t.Fatalf("expected error %v, got %v", rescanErr, err)
+	}
+	finishRescan(t, w, progress, 20)
+
+	// No rescan is performed from an unknown block.
+	if err := w.RescanFor([]btcutil.Address{addr}, nil, -1); err == nil {
+		t.Fatal("expected error rescanning from an unknown block")
+	}
+	select {
+	case req := <-chainClient.rescans:
+		t.Fatalf("unexpected rescan from block %v", req.start)
+	default:
+	}
+}
+
+func TestRescanProgressNotification(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	tests := []struct {
+		height    int32
+		elapsed   time.Duration
+		percent   float64
+		remaining time.Duration
+	}{
+		// No blocks processed yet, so nothing is estimated.
+		{height: 100, elapsed: time.Minute, percent: 0},
+
+		// A quarter of the blocks in one minute leaves three minutes.
+		{height: 200, elapsed: time.Minute, percent: 25,
+			remaining: 3 * time.Minute},
+
+		{height: 500, elapsed: time.Hour, percent: 100},
+	}
+	for i, test := range tests {
+		n := newRescanProgressNotification(
+			&chain.RescanProgress{
+				Hash:   &chainhash.Hash{},
+				Height: test.height,
+			}, 100, 500,
+			start, start.Add(test.elapsed),
+		)
+		if n.Height != test.height || n.TargetHeight != 500 {
+			t.Errorf("test %d: unexpected heights %d/%d", i,
+				n.Height, n.TargetHeight)
+		}
+		if n.Percent != test.percent {
+			t.Errorf("test %d: expected %v%%, got %v%%", i,
+				test.percent, n.Percent)
+		}
+		if n.Remaining != test.remaining {
+			t.Errorf("test %d: expected %v remaining, got %v", i,
+				test.remaining, n.Remaining)
+		}
+	}
+}
+
+func TestRescanCheckpoint(t *testing.T) {
+	stamp := func(height int32) waddrmgr.BlockStamp {
+		return waddrmgr.BlockStamp{Height: height}
+	}
+	cur := &rescanBatch{bs: stamp(100)}
+	next := &rescanBatch{bs: stamp(150)}
+	progress := stamp(200)
+
+	tests := []struct {
+		cur, next *rescanBatch
+		progress  *waddrmgr.BlockStamp
+		height    int32 // -1 when no checkpoint is expected
+	}{
+		{height: -1},
+		{cur: cur, height: 100},
+		{cur: cur, progress: &progress, height: 200},
+
+		// The next batch is resumed from when it starts below the
+		// progress of the current batch.
+		{cur: cur, next: next, progress: &progress, height: 150},
+		{cur: cur, next: next, height: 100},
+	}
+	for i, test := range tests {
+		bs := rescanCheckpoint(test.cur, test.next, test.progress)
+		switch {
+		case bs == nil && test.height != -1:
+			t.Errorf("test %d: expected checkpoint at height %d, "+
+				"got none", i, test.height)
+		case bs != nil && bs.Height != test.height:
+			t.Errorf("test %d: expected checkpoint at height %d, "+
+				"got %d", i, test.height, bs.Height)
+		}
+	}
+}
+
+// cancelRescanChainClient is a rescanChainClient whose rescans run until they
+// are canceled.
+type cancelRescanChainClient struct {
+	*rescanChainClient
+	canceled chan struct{}
+}
+
+func (c *cancelRescanChainClient) Rescan(start *chainhash.Hash,
+	addrs []btcutil.Address,
+	outpoints map[wire.OutPoint]btcutil.Address) error {
+
+	c.rescans <- rescanRequest{
+		start:     *start,
+		addrs:     addrs,
+		outpoints: outpoints,
+	}
+	<-c.canceled
+	return chain.ErrRescanCanceled
+}
+
+func (c *cancelRescanChainClient) CancelRescan() error {
+	close(c.canceled)
+	return nil
+}
+
+// TestCancelRescan ensures that canceling a running rescan returns to the
+// callers waiting for it and for the rescans queued after it, and that the
+// wallet is not marked synced by a canceled initial sync rescan.
+func TestCancelRescan(t *testing.T) {
+	w, mock, cleanup := testWallet(t)
+	defer cleanup()
+	chainClient := &cancelRescanChainClient{
+		rescanChainClient: newRescanChainClient(mock),
+		canceled:          make(chan struct{}),
+	}
+	startRescanHandlers(w, chainClient)
+
+	if err := w.CancelRescan(); err != chain.ErrNoRescan {
+		t.Fatalf("expected %v without a rescan, got %v",
+			chain.ErrNoRescan, err)
+	}
+
+	addr, err := w.NewAddress(
+		waddrmgr.DefaultAccountNum, waddrmgr.KeyScopeBIP0084,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	running := w.SubmitRescan(&RescanJob{
+		InitialSync: true,
+		Addrs:       []btcutil.Address{addr},
+		BlockStamp:  waddrmgr.BlockStamp{Hash: chainhash.Hash{1}, Height: 1},
+	})
+	nextRescan(t, chainClient.rescanChainClient)
+	queued := w.SubmitRescan(&RescanJob{
+		Addrs:      []btcutil.Address{addr},
+		BlockStamp: waddrmgr.BlockStamp{Hash: chainhash.Hash{2}, Height: 2},
+	})
+
+	if err := w.CancelRescan(); err != nil {
+		t.Fatalf("unable to cancel rescan: %v", err)
+	}
+	for name, errChan := range map[string]<-chan error{
+		"running": running,
+		"queued":  queued,
+	} {
+		select {
+		case err := <-errChan:
+			if err != chain.ErrRescanCanceled {
+				t.Fatalf("%s rescan: expected %v, got %v", name,
+					chain.ErrRescanCanceled, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s rescan did not return", name)
+		}
+	}
+
+	if w.ChainSynced() {
+		t.Fatal("wallet synced by a canceled initial sync rescan")
+	}
+	if err := w.CancelRescan(); err != chain.ErrNoRescan {
+		t.Fatalf("expected %v after canceling, got %v",
+			chain.ErrNoRescan, err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcwallet/internal/zero"
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// encryptedRootKeyVersion is the version of the serialization of exported
+// root keys.
+const encryptedRootKeyVersion = 1
+
+// secretKeyParamsSize is the size of the marshalled scrypt parameters of a
+// snacl.SecretKey, which begin exported root keys and encrypted backups.
+const secretKeyParamsSize = snacl.KeySize + sha256.Size + 24
+
+var (
+	// ErrEmptyExportPassphrase is returned when exporting the root key with
+	// an empty passphrase.
+	ErrEmptyExportPassphrase = errors.New("export passphrase must not " +
+		"be empty")
+
+	// ErrMalformedRootKey is returned when decrypting an exported root key
+	// which was not serialized by ExportEncryptedRootKey.
+	ErrMalformedRootKey = errors.New("malformed encrypted root key")
+)
+
+// ExportEncryptedRootKey returns the HD root extended private key of the
+// wallet, from which all keys of the wallet are derived, encrypted with a key
+// derived from passphrase using scrypt.  The passphrase is independent of the
+// private passphrase of the wallet, so the backup can be stored without
+// revealing the wallet passphrase or plaintext key material.  The result is
+// hex encoded and can be decrypted with DecryptRootKey.  The wallet must be
+// unlocked.
+func (w *Wallet) ExportEncryptedRootKey(passphrase []byte) (string, error) {
+	var rootKey *hdkeychain.ExtendedKey
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err error
+		rootKey, err = w.Manager.DeriveFromRoot(ns)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rootKey.Zero()
+
+	opts := waddrmgr.DefaultScryptOptions
+	encrypted, err := encryptRootKey(
+		rootKey, passphrase, opts.N, opts.R, opts.P,
+	)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(encrypted), nil
+}
+
+// encryptRootKey serializes a root key encrypted with a key derived from
+// passphrase using the scrypt parameters N, r and p.
+func encryptRootKey(rootKey *hdkeychain.ExtendedKey, passphrase []byte,
+	N, r, p int) ([]byte, error) {
+
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyExportPassphrase
+	}
+
+	secretKey, err := snacl.NewSecretKey(&passphrase, N, r, p)
+	if err != nil {
+		return nil, err
+	}
+	defer secretKey.Zero()
+
+	plaintext := []byte(rootKey.String())
+	defer zero.Bytes(plaintext)
+	ciphertext, err := secretKey.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// The serialized format is:
+	//   <version><scrypt parameters><encrypted extended key>
+	//
+	// 1 byte version + marshalled snacl parameters + encrypted base58 key
+	buf := make([]byte, 0, 1+secretKeyParamsSize+len(ciphertext))
+	buf = append(buf, encryptedRootKeyVersion)
+	buf = append(buf, secretKey.Marshal()...)
+	return append(buf, ciphertext...), nil
+}
+
+// DecryptRootKey decrypts a root key exported by ExportEncryptedRootKey with
+// the passphrase it was exported with.  The returned key can be used as the
+// root of a restored wallet or to derive its keys directly.
+func DecryptRootKey(encrypted string,
+	passphrase []byte) (*hdkeychain.ExtendedKey, error) {
+
+	buf, err := hex.DecodeString(encrypted)
+	if err != nil {
+		return nil, ErrMalformedRootKey
+	}
+	return decryptRootKey(buf, passphrase)
+}
+
+// decryptRootKey decrypts a root key serialized by encryptRootKey.
+func decryptRootKey(buf, passphrase []byte) (*hdkeychain.ExtendedKey, error) {
+	if len(buf) < 1+secretKeyParamsSize ||
+		buf[0] != encryptedRootKeyVersion {
+
+		return nil, ErrMalformedRootKey
+	}
+	buf = buf[1:]
+
+	var secretKey snacl.SecretKey
+	err := secretKey.Unmarshal(buf[:secretKeyParamsSize])
+	if err != nil {
+		return nil, err
+	}
+	defer secretKey.Zero()
+	if err := secretKey.DeriveKey(&passphrase); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := secretKey.Decrypt(buf[secretKeyParamsSize:])
+	if err != nil {
+		return nil, err
+	}
+	defer zero.Bytes(plaintext)
+
+	rootKey, err := hdkeychain.NewKeyFromString(string(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	if !rootKey.IsPrivate() {
+		return nil, ErrMalformedRootKey
+	}
+	return rootKey, nil
+}
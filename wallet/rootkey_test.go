@@ -0,0 +1,53 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcwallet/snacl"
+)
+
+func TestEncryptRootKey(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	rootKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use cheap scrypt parameters to keep the test fast.
+	passphrase := []byte("backup passphrase")
+	encrypted, err := encryptRootKey(rootKey, passphrase, 16, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte(rootKey.String())) {
+		t.Fatal("expected root key to be encrypted")
+	}
+
+	decrypted, err := decryptRootKey(encrypted, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.String() != rootKey.String() {
+		t.Fatalf("expected root key %v, got %v", rootKey, decrypted)
+	}
+
+	_, err = decryptRootKey(encrypted, []byte("wrong passphrase"))
+	if err != snacl.ErrInvalidPassword {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	_, err = decryptRootKey(encrypted[:10], passphrase)
+	if err != ErrMalformedRootKey {
+		t.Fatalf("expected ErrMalformedRootKey, got %v", err)
+	}
+	_, err = encryptRootKey(rootKey, nil, 16, 8, 1)
+	if err != ErrEmptyExportPassphrase {
+		t.Fatalf("expected ErrEmptyExportPassphrase, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// ErrSignerKeyMismatch is returned by a Signer when the key derived from the
+// path of a SignDescriptor does not match the public key of the descriptor.
+var ErrSignerKeyMismatch = errors.New("derived key does not match the " +
+	"public key of the sign descriptor")
+
+// SignDescriptor describes a signature of a transaction input requested from
+// a Signer.
+type SignDescriptor struct {
+	// KeyPath is the BIP0032 derivation path of the signing key from the
+	// root key of the wallet.  Hardened indexes include
+	// hdkeychain.HardenedKeyStart.
+	KeyPath []uint32
+
+	// PubKey is the serialized public key of the signing key, which the
+	// signer checks against the key it derives from KeyPath.
+	PubKey []byte
+
+	// SubScript is the script committed to by the signature: the previous
+	// output script of P2PKH and P2WPKH inputs, and the witness program or
+	// witness script of nested and P2WSH inputs.
+	SubScript []byte
+
+	// Amount is the value of the output spent by the input.
+	Amount int64
+
+	// HashType is the signature hash type of the signature.
+	HashType txscript.SigHashType
+
+	// InputIndex is the index of the signed input.
+	InputIndex int
+
+	// Witness selects the BIP0143 signature hash of witness inputs.
+	Witness bool
+}
+
+// Signer performs the private key operations of a wallet.  By default a
+// wallet signs with the private keys of its address manager.  Setting a Signer
+// with SetSigner delegates all signing to it instead, such as to the remote
+// wallet of the rpc/remotesigner package, so that a watching-only wallet can
+// create and sign transactions while the seed is held elsewhere.
+type Signer interface {
+	// SignOutputRaw returns the signature of the input of tx described by
+	// desc, with the hash type appended.
+	SignOutputRaw(tx *wire.MsgTx, desc *SignDescriptor) ([]byte, error)
+}
+
+// localSigner is a Signer using the keys derived from the root key of a
+// wallet.
+type localSigner struct {
+	w *Wallet
+}
+
+// NewLocalSigner returns a Signer which signs with the keys derived from the
+// root key of w.  It is used to serve signing requests of a remote watching-only
+// wallet from the wallet holding the seed, which must be unlocked while
+// signing.
+func NewLocalSigner(w *Wallet) Signer {
+	return localSigner{w}
+}
+
+// SignOutputRaw implements the Signer interface.
+func (s localSigner) SignOutputRaw(tx *wire.MsgTx,
+	desc *SignDescriptor) ([]byte, error) {
+
+	if desc.InputIndex < 0 || desc.InputIndex >= len(tx.TxIn) {
+		return nil, fmt.Errorf("input index %d out of range",
+			desc.InputIndex)
+	}
+
+	var key *hdkeychain.ExtendedKey
+	err := walletdb.View(s.w.db, func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		var err error
+		key, err = s.w.Manager.DeriveFromRoot(ns, desc.KeyPath...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer key.Zero()
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := privKey.PubKey()
+	if !bytes.Equal(desc.PubKey, pubKey.SerializeCompressed()) &&
+		!bytes.Equal(desc.PubKey, pubKey.SerializeUncompressed()) {
+
+		return nil, ErrSignerKeyMismatch
+	}
+
+	if desc.Witness {
+		return txscript.RawTxInWitnessSignature(
+			tx, txscript.NewTxSigHashes(tx), desc.InputIndex,
+			desc.Amount, desc.SubScript, desc.HashType, privKey,
+		)
+	}
+	return txscript.RawTxInSignature(
+		tx, desc.InputIndex, desc.SubScript, desc.HashType, privKey,
+	)
+}
+
+// SetSigner delegates the signing of transactions created by the wallet and of
+// PSBT inputs to signer.  While a signer is set, the wallet does not need to
+// be unlocked to sign, and only keys derived from the root key can be signed
+// for.  A nil signer restores signing with the keys of the address
+// manager.
+func (w *Wallet) SetSigner(signer Signer) {
+	w.signerMtx.Lock()
+	w.signer = signer
+	w.signerMtx.Unlock()
+}
+
+// remoteSigner returns the signer set by SetSigner, or nil if the wallet signs
+// with its own keys.
+func (w *Wallet) remoteSigner() Signer {
+	w.signerMtx.Lock()
+	defer w.signerMtx.Unlock()
+	return w.signer
+}
+
+// secretsSource returns the txauthor.SecretsSource signing transactions
+// created by the wallet.
+func (w *Wallet) secretsSource(addrmgrNs walletdb.ReadBucket) txauthor.SecretsSource {
+	source := secretSource{w.Manager, addrmgrNs}
+	if signer := w.remoteSigner(); signer != nil {
+		return signerSecretSource{source, signer}
+	}
+	return source
+}
+
+// signerSecretSource is a txauthor.InputSigner signing the inputs of created
+// transactions with the wallet's Signer.
+type signerSecretSource struct {
+	secretSource
+	signer Signer
+}
+
+// pubKeyAddress returns the managed address of addr.
+func (s signerSecretSource) pubKeyAddress(
+	addr btcutil.Address) (waddrmgr.ManagedPubKeyAddress, error) {
+
+	ma, err := s.Address(s.addrmgrNs, addr)
+	if err != nil {
+		return nil, err
+	}
+	mpka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, fmt.Errorf("managed address type for %v is `%T` "+
+			"but want waddrmgr.ManagedPubKeyAddress", addr, ma)
+	}
+	return mpka, nil
+}
+
+// PubKey implements the txauthor.InputSigner interface.
+func (s signerSecretSource) PubKey(addr btcutil.Address) ([]byte, error) {
+	mpka, err := s.pubKeyAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	if mpka.Compressed() {
+		return mpka.PubKey().SerializeCompressed(), nil
+	}
+	return mpka.PubKey().SerializeUncompressed(), nil
+}
+
+// SignInput implements the txauthor.InputSigner interface.
+func (s signerSecretSource) SignInput(tx *wire.MsgTx, idx int,
+	addr btcutil.Address, subScript []byte, amount int64,
+	witness bool) ([]byte, error) {
+
+	mpka, err := s.pubKeyAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	derivation, ok := psbtDerivation(mpka)
+	if !ok {
+		return nil, fmt.Errorf("imported address %v can not be signed "+
+			"by the signer", addr)
+	}
+	return s.signer.SignOutputRaw(tx, &SignDescriptor{
+		KeyPath:    derivation.Bip32Path,
+		PubKey:     derivation.PubKey,
+		SubScript:  subScript,
+		Amount:     amount,
+		HashType:   txscript.SigHashAll,
+		InputIndex: idx,
+		Witness:    witness,
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// signedMessageHash returns the hash of message committed to by a compact
+// signature in the signmessage format.
+func signedMessageHash(message string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n")
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// SignMessage returns a signature of message by the key of the wallet address
+// addr.  Messages of P2WPKH addresses, which can not be signed with a
+// recoverable signature, are signed as described by BIP0322, and messages of
+// other addresses with a compact signature in the signmessage format.  The
+// wallet must be unlocked.
+func (w *Wallet) SignMessage(addr btcutil.Address, message string) ([]byte, error) {
+	if _, ok := addr.(*btcutil.AddressWitnessPubKeyHash); ok {
+		return w.SignMessageBIP322(addr, message)
+	}
+
+	privKey, err := w.PrivKeyForAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return btcec.SignCompact(btcec.S256(), privKey,
+		signedMessageHash(message), true)
+}
+
+// VerifyMessage returns whether sig is a valid signature of message by addr,
+// as created by SignMessage.  An error is returned if the signature is
+// malformed or the address type is not supported.
+func VerifyMessage(addr btcutil.Address, message string, sig []byte) (bool, error) {
+	if _, ok := addr.(*btcutil.AddressWitnessPubKeyHash); ok {
+		return VerifyMessageBIP322(addr, message, sig)
+	}
+
+	// Validate the signature - this just shows that it was valid at all.
+	// we will compare it with the key next.
+	pk, wasCompressed, err := btcec.RecoverCompact(btcec.S256(), sig,
+		signedMessageHash(message))
+	if err != nil {
+		return false, err
+	}
+
+	var serializedPubKey []byte
+	if wasCompressed {
+		serializedPubKey = pk.SerializeCompressed()
+	} else {
+		serializedPubKey = pk.SerializeUncompressed()
+	}
+	// Verify that the signed-by address matches the given address
+	switch checkAddr := addr.(type) {
+	case *btcutil.AddressPubKeyHash: // ok
+		return bytes.Equal(btcutil.Hash160(serializedPubKey), checkAddr.Hash160()[:]), nil
+	case *btcutil.AddressPubKey: // ok
+		return string(serializedPubKey) == checkAddr.String(), nil
+	default:
+		return false, errors.New("address type not supported")
+	}
+}
@@ -6,12 +6,16 @@
 package txauthor
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"math/big"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/txsort"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 
 	h "github.com/btcsuite/btcwallet/internal/helpers"
@@ -54,7 +58,7 @@ type AuthoredTx struct {
 	ChangeIndex     int // negative if no change
 }
 
-// ChangeSource provides P2PKH change output scripts for transaction creation.
+// ChangeSource provides change output scripts for transaction creation.
 type ChangeSource func() ([]byte, error)
 
 // NewUnsignedTransaction creates an unsigned transaction paying to one or more
@@ -81,7 +85,7 @@ func NewUnsignedTransaction(outputs []*wire.TxOut, relayFeePerKb btcutil.Amount,
 	fetchInputs InputSource, fetchChange ChangeSource) (*AuthoredTx, error) {
 
 	targetAmount := h.SumOutputValues(outputs)
-	estimatedSize := txsizes.EstimateVirtualSize(0, 1, 0, outputs, true)
+	estimatedSize := txsizes.EstimateVirtualSize(0, 0, 1, 0, outputs, true)
 	targetFee := txrules.FeeForSerializeSize(relayFeePerKb, estimatedSize)
 
 	for {
@@ -95,22 +99,10 @@ func NewUnsignedTransaction(outputs []*wire.TxOut, relayFeePerKb btcutil.Amount,
 
 		// We count the types of inputs, which we'll use to estimate
 		// the vsize of the transaction.
-		var nested, p2wpkh, p2pkh int
-		for _, pkScript := range scripts {
-			switch {
-			// If this is a p2sh output, we assume this is a
-			// nested P2WKH.
-			case txscript.IsPayToScriptHash(pkScript):
-				nested++
-			case txscript.IsPayToWitnessPubKeyHash(pkScript):
-				p2wpkh++
-			default:
-				p2pkh++
-			}
-		}
+		p2pkh, p2tr, p2wpkh, nested := countInputTypes(scripts)
 
-		maxSignedSize := txsizes.EstimateVirtualSize(p2pkh, p2wpkh,
-			nested, outputs, true)
+		maxSignedSize := txsizes.EstimateVirtualSize(p2pkh, p2tr,
+			p2wpkh, nested, outputs, true)
 		maxRequiredFee := txrules.FeeForSerializeSize(relayFeePerKb, maxSignedSize)
 		remainingAmount := inputAmount - targetAmount
 		if remainingAmount < maxRequiredFee {
@@ -132,14 +124,25 @@ func NewUnsignedTransaction(outputs []*wire.TxOut, relayFeePerKb btcutil.Amount,
 			if err != nil {
 				return nil, err
 			}
-			if len(changeScript) > txsizes.P2WPKHPkScriptSize {
-				return nil, errors.New("fee estimation requires change " +
-					"scripts no larger than P2WPKH output scripts")
+
+			// The fee estimate assumes a P2WPKH change output.  Larger
+			// change scripts pay for their additional size from the
+			// change amount, and the change output is dropped if it
+			// becomes dust.
+			extraSize := len(changeScript) - txsizes.P2WPKHPkScriptSize
+			if extraSize > 0 {
+				changeFee := txrules.FeeForSerializeSize(relayFeePerKb,
+					maxSignedSize+extraSize) - maxRequiredFee
+				changeAmount -= changeFee
+			}
+			if changeAmount > 0 && !txrules.IsDustAmount(changeAmount,
+				len(changeScript), relayFeePerKb) {
+
+				change := wire.NewTxOut(int64(changeAmount), changeScript)
+				l := len(outputs)
+				unsignedTransaction.TxOut = append(outputs[:l:l], change)
+				changeIndex = l
 			}
-			change := wire.NewTxOut(int64(changeAmount), changeScript)
-			l := len(outputs)
-			unsignedTransaction.TxOut = append(outputs[:l:l], change)
-			changeIndex = l
 		}
 
 		return &AuthoredTx{
@@ -152,6 +155,76 @@ func NewUnsignedTransaction(outputs []*wire.TxOut, relayFeePerKb btcutil.Amount,
 	}
 }
 
+// NewUnsignedSendAllTransaction creates an unsigned transaction with a single
+// output paying to pkScript.  Every input returned by fetchInputs is spent and
+// the output value is the total input value minus the transaction fee, so no
+// change output is created.  The input source is called exactly once with a
+// target of btcutil.MaxSatoshi, so input sources returning up to the target
+// amount provide every input available to them.
+//
+// If the input source provides no input value, an InputSourceError is
+// returned.  If the output value after subtracting the fee would be dust,
+// txrules.ErrOutputIsDust is returned.
+func NewUnsignedSendAllTransaction(pkScript []byte, relayFeePerKb btcutil.Amount,
+	fetchInputs InputSource) (*AuthoredTx, error) {
+
+	inputAmount, inputs, inputValues, scripts, err := fetchInputs(btcutil.MaxSatoshi)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 || inputAmount == 0 {
+		return nil, insufficientFundsError{}
+	}
+
+	output := wire.NewTxOut(0, pkScript)
+	p2pkh, p2tr, p2wpkh, nested := countInputTypes(scripts)
+	maxSignedSize := txsizes.EstimateVirtualSize(p2pkh, p2tr, p2wpkh,
+		nested, []*wire.TxOut{output}, false)
+	maxRequiredFee := txrules.FeeForSerializeSize(relayFeePerKb, maxSignedSize)
+
+	outputAmount := inputAmount - maxRequiredFee
+	if outputAmount <= 0 || txrules.IsDustAmount(outputAmount,
+		len(pkScript), relayFeePerKb) {
+
+		return nil, txrules.ErrOutputIsDust
+	}
+	output.Value = int64(outputAmount)
+
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{output},
+		LockTime: 0,
+	}
+	return &AuthoredTx{
+		Tx:              unsignedTransaction,
+		PrevScripts:     scripts,
+		PrevInputValues: inputValues,
+		TotalInput:      inputAmount,
+		ChangeIndex:     -1,
+	}, nil
+}
+
+// countInputTypes counts the number of P2PKH, P2TR, P2WPKH and nested P2WPKH
+// inputs redeeming the passed previous output scripts.  All P2SH outputs are
+// assumed to be nested P2WPKH, and all P2TR outputs are assumed to be spent
+// through the key path.
+func countInputTypes(scripts [][]byte) (p2pkh, p2tr, p2wpkh, nested int) {
+	for _, pkScript := range scripts {
+		switch {
+		case txscript.IsPayToScriptHash(pkScript):
+			nested++
+		case txscript.IsPayToWitnessPubKeyHash(pkScript):
+			p2wpkh++
+		case txsizes.IsP2TRPkScript(pkScript):
+			p2tr++
+		default:
+			p2pkh++
+		}
+	}
+	return p2pkh, p2tr, p2wpkh, nested
+}
+
 // RandomizeOutputPosition randomizes the position of a transaction's output by
 // swapping it with a random output.  The new index is returned.  This should be
 // done before signing.
@@ -167,6 +240,106 @@ func (tx *AuthoredTx) RandomizeChangePosition() {
 	tx.ChangeIndex = RandomizeOutputPosition(tx.Tx.TxOut, tx.ChangeIndex)
 }
 
+// SortBIP69 sorts the inputs and outputs of an authored transaction as
+// described by BIP0069, so that their order reveals nothing about which output
+// is change.  The previous output scripts, input values and change index are
+// updated to match the new order.  This should be done before signing.
+func (tx *AuthoredTx) SortBIP69() {
+	tx.reorder(func() error {
+		txsort.InPlaceSort(tx.Tx)
+		return nil
+	})
+}
+
+// Shuffle randomly permutes the inputs and outputs of an authored transaction
+// using the cryptographically secure random number generator.  The previous
+// output scripts, input values and change index are updated to match the new
+// order.  This should be done before signing.
+func (tx *AuthoredTx) Shuffle() error {
+	return tx.reorder(func() error {
+		txIns := tx.Tx.TxIn
+		err := shuffle(len(txIns), func(i, j int) {
+			txIns[i], txIns[j] = txIns[j], txIns[i]
+		})
+		if err != nil {
+			return err
+		}
+		txOuts := tx.Tx.TxOut
+		return shuffle(len(txOuts), func(i, j int) {
+			txOuts[i], txOuts[j] = txOuts[j], txOuts[i]
+		})
+	})
+}
+
+// reorder calls f to reorder the inputs and outputs of the transaction, and
+// then reorders the previous output scripts and input values and updates the
+// change index to match.
+func (tx *AuthoredTx) reorder(f func() error) error {
+	var change *wire.TxOut
+	if tx.ChangeIndex >= 0 {
+		change = tx.Tx.TxOut[tx.ChangeIndex]
+	}
+	prevIndex := make(map[wire.OutPoint]int, len(tx.Tx.TxIn))
+	for i, txIn := range tx.Tx.TxIn {
+		prevIndex[txIn.PreviousOutPoint] = i
+	}
+
+	if err := f(); err != nil {
+		return err
+	}
+
+	var (
+		prevScripts [][]byte
+		inputValues []btcutil.Amount
+	)
+	if tx.PrevScripts != nil {
+		prevScripts = make([][]byte, len(tx.Tx.TxIn))
+	}
+	if tx.PrevInputValues != nil {
+		inputValues = make([]btcutil.Amount, len(tx.Tx.TxIn))
+	}
+	for i, txIn := range tx.Tx.TxIn {
+		j := prevIndex[txIn.PreviousOutPoint]
+		if prevScripts != nil {
+			prevScripts[i] = tx.PrevScripts[j]
+		}
+		if inputValues != nil {
+			inputValues[i] = tx.PrevInputValues[j]
+		}
+	}
+	tx.PrevScripts = prevScripts
+	tx.PrevInputValues = inputValues
+	tx.ChangeIndex = outputIndex(tx.Tx.TxOut, change)
+	return nil
+}
+
+// outputIndex returns the index of output within outputs, or -1 if output is
+// nil or not found.
+func outputIndex(outputs []*wire.TxOut, output *wire.TxOut) int {
+	if output == nil {
+		return -1
+	}
+	for i, txOut := range outputs {
+		if txOut == output {
+			return i
+		}
+	}
+	return -1
+}
+
+// shuffle performs a Fisher-Yates shuffle of n elements using the
+// cryptographically secure random number generator.
+func shuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+		swap(i, int(j.Int64()))
+	}
+	return nil
+}
+
 // SecretsSource provides private keys and redeem scripts necessary for
 // constructing transaction input signatures.  Secrets are looked up by the
 // corresponding Address for the previous output script.  Addresses for lookup
@@ -184,11 +357,29 @@ type SecretsSource interface {
 	ChainParams() *chaincfg.Params
 }
 
+// InputSigner is implemented by a SecretsSource which creates input signatures
+// itself instead of providing private keys, such as a source whose keys are
+// held by an external signer.  When the SecretsSource passed to
+// AddAllInputScripts is an InputSigner, P2PKH, P2WPKH and nested P2WPKH inputs
+// are signed by SignInput and other inputs are not supported.
+type InputSigner interface {
+	// PubKey returns the serialized public key of addr.
+	PubKey(addr btcutil.Address) ([]byte, error)
+
+	// SignInput returns the SigHashAll signature, with the hash type
+	// appended, of input idx of tx by the key of addr.  The input spends
+	// an output of value amount and redeems subScript, which is signed
+	// with the BIP0143 signature hash if witness is true.
+	SignInput(tx *wire.MsgTx, idx int, addr btcutil.Address,
+		subScript []byte, amount int64, witness bool) ([]byte, error)
+}
+
 // AddAllInputScripts modifies transaction a transaction by adding inputs
 // scripts for each input.  Previous output scripts being redeemed by each input
 // are passed in prevPkScripts and the slice length must match the number of
 // inputs.  Private keys and redeem scripts are looked up using a SecretsSource
-// based on the previous output script.
+// based on the previous output script, unless the SecretsSource is an
+// InputSigner.
 func AddAllInputScripts(tx *wire.MsgTx, prevPkScripts [][]byte, inputValues []btcutil.Amount,
 	secrets SecretsSource) error {
 
@@ -201,6 +392,17 @@ func AddAllInputScripts(tx *wire.MsgTx, prevPkScripts [][]byte, inputValues []bt
 			"have equal length")
 	}
 
+	if signer, ok := secrets.(InputSigner); ok {
+		for i := range inputs {
+			err := signInput(tx, i, prevPkScripts[i],
+				int64(inputValues[i]), chainParams, signer)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	for i := range inputs {
 		pkScript := prevPkScripts[i]
 
@@ -353,6 +555,82 @@ func spendNestedWitnessPubKeyHash(txIn *wire.TxIn, pkScript []byte,
 	return nil
 }
 
+// signInput sets the sigScript and witness of input idx of tx, which spends
+// the P2PKH, P2WPKH or nested P2WPKH output pkScript of value inputValue, with
+// a signature created by signer.
+func signInput(tx *wire.MsgTx, idx int, pkScript []byte, inputValue int64,
+	chainParams *chaincfg.Params, signer InputSigner) error {
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
+		chainParams)
+	if err != nil {
+		return err
+	}
+	if len(addrs) != 1 {
+		return fmt.Errorf("unable to sign input %d: unsupported "+
+			"script %x", idx, pkScript)
+	}
+	pubKey, err := signer.PubKey(addrs[0])
+	if err != nil {
+		return err
+	}
+	txIn := tx.TxIn[idx]
+
+	switch {
+	// The sigScript of a nested P2WPKH input is a single push of the
+	// witness program, which is signed as the script of the witness.
+	case txscript.IsPayToScriptHash(pkScript):
+		p2wkhAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+			btcutil.Hash160(pubKey), chainParams)
+		if err != nil {
+			return err
+		}
+		witnessProgram, err := txscript.PayToAddrScript(p2wkhAddr)
+		if err != nil {
+			return err
+		}
+		sigScript, err := txscript.NewScriptBuilder().
+			AddData(witnessProgram).Script()
+		if err != nil {
+			return err
+		}
+		sig, err := signer.SignInput(tx, idx, addrs[0],
+			witnessProgram, inputValue, true)
+		if err != nil {
+			return err
+		}
+		txIn.SignatureScript = sigScript
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		sig, err := signer.SignInput(tx, idx, addrs[0], pkScript,
+			inputValue, true)
+		if err != nil {
+			return err
+		}
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		sig, err := signer.SignInput(tx, idx, addrs[0], pkScript,
+			inputValue, false)
+		if err != nil {
+			return err
+		}
+		sigScript, err := txscript.NewScriptBuilder().AddData(sig).
+			AddData(pubKey).Script()
+		if err != nil {
+			return err
+		}
+		txIn.SignatureScript = sigScript
+
+	default:
+		return fmt.Errorf("unable to sign input %d: unsupported "+
+			"script %x", idx, pkScript)
+	}
+
+	return nil
+}
+
 // AddAllInputScripts modifies an authored transaction by adding inputs scripts
 // for each input of an authored transaction.  Private keys and redeem scripts
 // are looked up using a SecretsSource based on the previous output script.
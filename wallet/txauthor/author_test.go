@@ -5,10 +5,15 @@
 package txauthor_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/txsort"
 	. "github.com/btcsuite/btcwallet/wallet/txauthor"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 
@@ -63,7 +68,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 			Outputs:        p2pkhOutputs(1e6),
 			RelayFee:       1e3,
 			ChangeAmount: 1e8 - 1e6 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(1e6), true)),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(1e6), true)),
 			InputCount: 1,
 		},
 		2: {
@@ -71,7 +76,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 			Outputs:        p2pkhOutputs(1e6),
 			RelayFee:       1e4,
 			ChangeAmount: 1e8 - 1e6 - txrules.FeeForSerializeSize(1e4,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(1e6), true)),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(1e6), true)),
 			InputCount: 1,
 		},
 		3: {
@@ -79,7 +84,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 			Outputs:        p2pkhOutputs(1e6, 1e6, 1e6),
 			RelayFee:       1e4,
 			ChangeAmount: 1e8 - 3e6 - txrules.FeeForSerializeSize(1e4,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(1e6, 1e6, 1e6), true)),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(1e6, 1e6, 1e6), true)),
 			InputCount: 1,
 		},
 		4: {
@@ -87,7 +92,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 			Outputs:        p2pkhOutputs(1e6, 1e6, 1e6),
 			RelayFee:       2.55e3,
 			ChangeAmount: 1e8 - 3e6 - txrules.FeeForSerializeSize(2.55e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(1e6, 1e6, 1e6), true)),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(1e6, 1e6, 1e6), true)),
 			InputCount: 1,
 		},
 
@@ -95,7 +100,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		5: {
 			UnspentOutputs: p2pkhOutputs(1e8),
 			Outputs: p2pkhOutputs(1e8 - 545 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     1e3,
 			ChangeAmount: 545,
 			InputCount:   1,
@@ -103,7 +108,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		6: {
 			UnspentOutputs: p2pkhOutputs(1e8),
 			Outputs: p2pkhOutputs(1e8 - 546 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     1e3,
 			ChangeAmount: 546,
 			InputCount:   1,
@@ -113,7 +118,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		7: {
 			UnspentOutputs: p2pkhOutputs(1e8),
 			Outputs: p2pkhOutputs(1e8 - 1392 - txrules.FeeForSerializeSize(2.55e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     2.55e3,
 			ChangeAmount: 1392,
 			InputCount:   1,
@@ -121,7 +126,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		8: {
 			UnspentOutputs: p2pkhOutputs(1e8),
 			Outputs: p2pkhOutputs(1e8 - 1393 - txrules.FeeForSerializeSize(2.55e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     2.55e3,
 			ChangeAmount: 1393,
 			InputCount:   1,
@@ -133,7 +138,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		9: {
 			UnspentOutputs: p2pkhOutputs(1e8, 1e8),
 			Outputs: p2pkhOutputs(1e8 - 546 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     1e3,
 			ChangeAmount: 546,
 			InputCount:   1,
@@ -147,7 +152,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		10: {
 			UnspentOutputs: p2pkhOutputs(1e8, 1e8),
 			Outputs: p2pkhOutputs(1e8 - 545 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(1, 0, 0, p2pkhOutputs(0), true))),
+				txsizes.EstimateVirtualSize(1, 0, 0, 0, p2pkhOutputs(0), true))),
 			RelayFee:     1e3,
 			ChangeAmount: 545,
 			InputCount:   1,
@@ -159,7 +164,7 @@ func TestNewUnsignedTransaction(t *testing.T) {
 			Outputs:        p2pkhOutputs(1e8),
 			RelayFee:       1e3,
 			ChangeAmount: 1e8 - txrules.FeeForSerializeSize(1e3,
-				txsizes.EstimateVirtualSize(2, 0, 0, p2pkhOutputs(1e8), true)),
+				txsizes.EstimateVirtualSize(2, 0, 0, 0, p2pkhOutputs(1e8), true)),
 			InputCount: 2,
 		},
 
@@ -219,3 +224,266 @@ func TestNewUnsignedTransaction(t *testing.T) {
 		}
 	}
 }
+
+// TestNewUnsignedTransactionLargeChange ensures that change scripts larger than
+// the P2WPKH script assumed by the fee estimate pay for their additional size.
+func TestNewUnsignedTransactionLargeChange(t *testing.T) {
+	const relayFee = 1e3
+	outputs := p2pkhOutputs(1e6)
+	changeScript := make([]byte, txsizes.P2PKHPkScriptSize)
+	changeSource := func() ([]byte, error) {
+		return changeScript, nil
+	}
+
+	inputSource := makeInputSource(p2pkhOutputs(1e8))
+	tx, err := NewUnsignedTransaction(outputs, relayFee, inputSource,
+		changeSource)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if tx.ChangeIndex < 0 {
+		t.Fatal("expected change output")
+	}
+
+	size := txsizes.EstimateVirtualSize(1, 0, 0, 0, outputs, true) +
+		txsizes.P2PKHPkScriptSize - txsizes.P2WPKHPkScriptSize
+	expected := 1e8 - 1e6 - txrules.FeeForSerializeSize(relayFee, size)
+	change := btcutil.Amount(tx.Tx.TxOut[tx.ChangeIndex].Value)
+	if change != expected {
+		t.Fatalf("expected change amount %v, got %v", expected, change)
+	}
+}
+
+func TestNewUnsignedSendAllTransaction(t *testing.T) {
+	outScript := make([]byte, txsizes.P2PKHOutputSize)
+
+	tests := []struct {
+		UnspentOutputs   []*wire.TxOut
+		RelayFee         btcutil.Amount
+		InputSourceError bool
+		DustError        bool
+	}{
+		0: {
+			UnspentOutputs:   p2pkhOutputs(),
+			RelayFee:         1e3,
+			InputSourceError: true,
+		},
+		1: {
+			UnspentOutputs: p2pkhOutputs(1e6, 2e6, 5e5),
+			RelayFee:       1e3,
+		},
+		2: {
+			UnspentOutputs: p2pkhOutputs(1e6),
+			RelayFee:       1e4,
+		},
+		// The output of 508 satoshis remaining after the fee of 192
+		// satoshis is below the dust threshold of 573 satoshis.
+		3: {
+			UnspentOutputs: p2pkhOutputs(700),
+			RelayFee:       1e3,
+			DustError:      true,
+		},
+	}
+
+	for i, test := range tests {
+		inputSource := makeInputSource(test.UnspentOutputs)
+		tx, err := NewUnsignedSendAllTransaction(outScript,
+			test.RelayFee, inputSource)
+		switch e := err.(type) {
+		case nil:
+		case InputSourceError:
+			if !test.InputSourceError {
+				t.Errorf("Test %d: Returned InputSourceError but "+
+					"expected success: %v", i, e)
+			}
+			continue
+		default:
+			if !test.DustError {
+				t.Errorf("Test %d: Unexpected error: %v", i, err)
+			} else if err != txrules.ErrOutputIsDust {
+				t.Errorf("Test %d: Expected error %v, got %v", i,
+					txrules.ErrOutputIsDust, err)
+			}
+			continue
+		}
+		if test.InputSourceError || test.DustError {
+			t.Errorf("Test %d: Expected error but succeeded", i)
+			continue
+		}
+
+		var total btcutil.Amount
+		for _, u := range test.UnspentOutputs {
+			total += btcutil.Amount(u.Value)
+		}
+		if len(tx.Tx.TxIn) != len(test.UnspentOutputs) {
+			t.Errorf("Test %d: Expected %d inputs, got %d", i,
+				len(test.UnspentOutputs), len(tx.Tx.TxIn))
+			continue
+		}
+		if len(tx.Tx.TxOut) != 1 || tx.ChangeIndex != -1 {
+			t.Errorf("Test %d: Expected a single non-change output", i)
+			continue
+		}
+		size := txsizes.EstimateVirtualSize(len(tx.Tx.TxIn), 0, 0, 0,
+			tx.Tx.TxOut, false)
+		fee := txrules.FeeForSerializeSize(test.RelayFee, size)
+		if btcutil.Amount(tx.Tx.TxOut[0].Value) != total-fee {
+			t.Errorf("Test %d: Expected output value %v, got %v", i,
+				total-fee, btcutil.Amount(tx.Tx.TxOut[0].Value))
+		}
+	}
+}
+
+// makeReorderTx creates an authored transaction whose inputs are identified by
+// the first byte of their previous transaction hash, previous output script
+// and input value, and whose change output pays a unique value.
+func makeReorderTx() (*AuthoredTx, *wire.TxOut) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var (
+		prevScripts [][]byte
+		inputValues []btcutil.Amount
+	)
+	for _, id := range []byte{3, 1, 4, 2} {
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: [32]byte{id}}, nil, nil))
+		prevScripts = append(prevScripts, []byte{id})
+		inputValues = append(inputValues, btcutil.Amount(id))
+	}
+	for _, v := range []int64{3e5, 1e5, 2e5} {
+		tx.AddTxOut(wire.NewTxOut(v, make([]byte, txsizes.P2WPKHPkScriptSize)))
+	}
+	return &AuthoredTx{
+		Tx:              tx,
+		PrevScripts:     prevScripts,
+		PrevInputValues: inputValues,
+		ChangeIndex:     2,
+	}, tx.TxOut[2]
+}
+
+// checkReordered ensures the previous output scripts, input values and change
+// index of a reordered transaction match its inputs and outputs.
+func checkReordered(t *testing.T, tx *AuthoredTx, change *wire.TxOut) {
+	for i, txIn := range tx.Tx.TxIn {
+		id := txIn.PreviousOutPoint.Hash[0]
+		if tx.PrevScripts[i][0] != id ||
+			tx.PrevInputValues[i] != btcutil.Amount(id) {
+
+			t.Fatalf("previous output %d does not match input %d",
+				tx.PrevScripts[i][0], id)
+		}
+	}
+	if tx.Tx.TxOut[tx.ChangeIndex] != change {
+		t.Fatalf("change index %d does not reference change output",
+			tx.ChangeIndex)
+	}
+}
+
+func TestSortBIP69(t *testing.T) {
+	tx, change := makeReorderTx()
+	tx.SortBIP69()
+	if !txsort.IsSorted(tx.Tx) {
+		t.Fatal("transaction is not sorted")
+	}
+	checkReordered(t, tx, change)
+}
+
+func TestShuffle(t *testing.T) {
+	tx, change := makeReorderTx()
+	for i := 0; i < 10; i++ {
+		if err := tx.Shuffle(); err != nil {
+			t.Fatalf("unable to shuffle transaction: %v", err)
+		}
+		checkReordered(t, tx, change)
+	}
+}
+
+// inputSigner is a SecretsSource signing inputs with a single key without
+// providing it.
+type inputSigner struct {
+	key *btcec.PrivateKey
+}
+
+func (s inputSigner) GetKey(btcutil.Address) (*btcec.PrivateKey, bool, error) {
+	return nil, false, errors.New("private keys are not provided")
+}
+
+func (s inputSigner) GetScript(btcutil.Address) ([]byte, error) {
+	return nil, errors.New("scripts are not provided")
+}
+
+func (s inputSigner) ChainParams() *chaincfg.Params {
+	return &chaincfg.MainNetParams
+}
+
+func (s inputSigner) PubKey(btcutil.Address) ([]byte, error) {
+	return s.key.PubKey().SerializeCompressed(), nil
+}
+
+func (s inputSigner) SignInput(tx *wire.MsgTx, idx int, addr btcutil.Address,
+	subScript []byte, amount int64, witness bool) ([]byte, error) {
+
+	if witness {
+		return txscript.RawTxInWitnessSignature(tx,
+			txscript.NewTxSigHashes(tx), idx, amount, subScript,
+			txscript.SigHashAll, s.key)
+	}
+	return txscript.RawTxInSignature(tx, idx, subScript,
+		txscript.SigHashAll, s.key)
+}
+
+func TestAddAllInputScriptsInputSigner(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := inputSigner{key}
+	params := signer.ChainParams()
+	pubKeyHash := btcutil.Hash160(key.PubKey().SerializeCompressed())
+
+	p2pkhAddr, err := btcutil.NewAddressPubKeyHash(pubKeyHash, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2wkhAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witnessProgram, err := txscript.PayToAddrScript(p2wkhAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nestedAddr, err := btcutil.NewAddressScriptHash(witnessProgram, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pkScripts [][]byte
+	var values []btcutil.Amount
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for i, addr := range []btcutil.Address{p2pkhAddr, p2wkhAddr, nestedAddr} {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScripts = append(pkScripts, pkScript)
+		values = append(values, btcutil.Amount(1e6*(i+1)))
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(i)}, nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(5e6, witnessProgram))
+
+	if err := AddAllInputScripts(tx, pkScripts, values, signer); err != nil {
+		t.Fatal(err)
+	}
+
+	hashCache := txscript.NewTxSigHashes(tx)
+	for i, pkScript := range pkScripts {
+		vm, err := txscript.NewEngine(pkScript, tx, i,
+			txscript.StandardVerifyFlags, nil, hashCache,
+			int64(values[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Errorf("input %d: invalid input script: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// ErrTransactionNotFound describes the error of a transaction which is not
+// recorded by the wallet.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// TransactionDetails describes a transaction recorded by the wallet, with the
+// block it is mined in, its inputs and outputs, and its fee.
+type TransactionDetails struct {
+	Hash         chainhash.Hash
+	MsgTx        wire.MsgTx
+	SerializedTx []byte
+	Label        string
+	Received     time.Time
+
+	// BlockHash is the hash of the block the transaction is mined in, and
+	// is nil for unmined transactions, whose BlockHeight is -1.
+	BlockHash     *chainhash.Hash
+	BlockHeight   int32
+	BlockTime     time.Time
+	Confirmations int32
+
+	Inputs  []TransactionInputDetails
+	Outputs []TransactionOutputDetails
+
+	// Fee is the fee paid by the transaction, and is only set when FeeKnown
+	// is true.
+	Fee      btcutil.Amount
+	FeeKnown bool
+}
+
+// TransactionInputDetails describes a transaction input.  The amount of the
+// previous output is only known when it is an output of a transaction
+// recorded by the wallet, and its account only when it is a wallet output.
+type TransactionInputDetails struct {
+	PreviousOutPoint wire.OutPoint
+	PreviousAmount   btcutil.Amount
+	AmountKnown      bool
+	Mine             bool
+	Account          uint32
+}
+
+// TransactionOutputDetails describes a transaction output, the addresses it
+// pays to, and whether it is controlled by the wallet.  The account and
+// branch of the output address are only set for wallet outputs.
+type TransactionOutputDetails struct {
+	Index     uint32
+	Amount    btcutil.Amount
+	PkScript  []byte
+	Addresses []btcutil.Address
+	Mine      bool
+	Account   uint32
+	Internal  bool
+}
+
+// GetTransaction returns the details of the transaction txHash recorded by the
+// wallet.  The amounts of inputs spending outputs of other recorded
+// transactions are resolved, which also allows reporting the fee of incoming
+// transactions whose previous outputs are all known.
+func (w *Wallet) GetTransaction(txHash *chainhash.Hash) (*TransactionDetails, error) {
+	var res *TransactionDetails
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+		details, err := w.TxStore.TxDetails(txmgrNs, txHash)
+		if err != nil {
+			return err
+		}
+		if details == nil {
+			return ErrTransactionNotFound
+		}
+
+		syncBlock := w.Manager.SyncedTo()
+		res = &TransactionDetails{
+			Hash:         details.Hash,
+			MsgTx:        details.MsgTx,
+			SerializedTx: details.SerializedTx,
+			Label:        details.Label,
+			Received:     details.Received,
+			BlockHeight:  details.Block.Height,
+			Fee:          details.Fee,
+			FeeKnown:     details.FeeKnown,
+		}
+		if details.Block.Height != -1 {
+			blockHash := details.Block.Hash
+			res.BlockHash = &blockHash
+			res.BlockTime = details.Block.Time
+			res.Confirmations = confirms(details.Block.Height,
+				syncBlock.Height)
+		}
+
+		debits := make(map[uint32]btcutil.Amount, len(details.Debits))
+		for _, deb := range details.Debits {
+			debits[deb.Index] = deb.Amount
+		}
+		inputTotal, inputsKnown := btcutil.Amount(0), true
+		res.Inputs = make([]TransactionInputDetails, len(details.MsgTx.TxIn))
+		for i, txIn := range details.MsgTx.TxIn {
+			input := &res.Inputs[i]
+			input.PreviousOutPoint = txIn.PreviousOutPoint
+
+			prevOut, err := w.prevOutput(txmgrNs, &txIn.PreviousOutPoint)
+			if err != nil {
+				return err
+			}
+			if amount, ok := debits[uint32(i)]; ok {
+				input.PreviousAmount = amount
+				input.AmountKnown = true
+				input.Mine = true
+			} else if prevOut != nil {
+				input.PreviousAmount = btcutil.Amount(prevOut.Value)
+				input.AmountKnown = true
+			}
+			if input.Mine && prevOut != nil {
+				input.Account, _ = w.outputAccount(addrmgrNs,
+					prevOut.PkScript)
+			}
+
+			inputTotal += input.PreviousAmount
+			inputsKnown = inputsKnown && input.AmountKnown
+		}
+
+		credits := make(map[uint32]struct{}, len(details.Credits))
+		for _, cred := range details.Credits {
+			credits[cred.Index] = struct{}{}
+		}
+		outputTotal := btcutil.Amount(0)
+		res.Outputs = make([]TransactionOutputDetails, len(details.MsgTx.TxOut))
+		for i, txOut := range details.MsgTx.TxOut {
+			output := &res.Outputs[i]
+			output.Index = uint32(i)
+			output.Amount = btcutil.Amount(txOut.Value)
+			output.PkScript = txOut.PkScript
+			outputTotal += output.Amount
+
+			// Non-standard scripts pay to no address.
+			_, addrs, _, _ := txscript.ExtractPkScriptAddrs(
+				txOut.PkScript, w.chainParams)
+			output.Addresses = addrs
+
+			if _, ok := credits[uint32(i)]; !ok || len(addrs) == 0 {
+				continue
+			}
+			output.Mine = true
+			ma, err := w.Manager.Address(addrmgrNs, addrs[0])
+			if err == nil {
+				output.Account = ma.Account()
+				output.Internal = ma.Internal()
+			}
+		}
+
+		// The fee of transactions spending outputs of other recorded
+		// transactions is known once every previous output is resolved.
+		if !res.FeeKnown && inputsKnown && inputTotal >= outputTotal &&
+			!blockchain.IsCoinBaseTx(&details.MsgTx) {
+
+			res.Fee = inputTotal - outputTotal
+			res.FeeKnown = true
+		}
+		return nil
+	})
+	return res, err
+}
+
+// prevOutput returns the output op of a transaction recorded by the wallet, or
+// nil if the transaction is not recorded.
+func (w *Wallet) prevOutput(txmgrNs walletdb.ReadBucket,
+	op *wire.OutPoint) (*wire.TxOut, error) {
+
+	prev, err := w.TxStore.TxDetails(txmgrNs, &op.Hash)
+	if err != nil || prev == nil {
+		return nil, err
+	}
+	if int(op.Index) >= len(prev.MsgTx.TxOut) {
+		return nil, nil
+	}
+	return prev.MsgTx.TxOut[op.Index], nil
+}
+
+// outputAccount returns the account of the wallet address paid to by
+// pkScript, and whether the script pays to a wallet address.
+func (w *Wallet) outputAccount(addrmgrNs walletdb.ReadBucket,
+	pkScript []byte) (uint32, bool) {
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
+		w.chainParams)
+	if err != nil || len(addrs) == 0 {
+		return 0, false
+	}
+	_, account, err := w.Manager.AddrAccount(addrmgrNs, addrs[0])
+	if err != nil {
+		return 0, false
+	}
+	return account, true
+}
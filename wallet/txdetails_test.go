@@ -0,0 +1,130 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestGetTransaction ensures that the inputs of transactions spending outputs
+// of other recorded transactions are resolved, along with their fee.
+func TestGetTransaction(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.NewAddress(
+		waddrmgr.DefaultAccountNum, waddrmgr.KeyScopeBIP0084,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parent pays the wallet and another script, and its child spends
+	// both outputs.
+	parent := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	parent.TxOut[0].PkScript = walletScript
+	parent.AddTxOut(wire.NewTxOut(5e5, []byte{0x51}))
+	parentHash := parent.TxHash()
+	child := testTx(wire.OutPoint{Hash: parentHash, Index: 0}, 14e5)
+	child.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: parentHash, Index: 1},
+		nil, nil))
+
+	block := wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: chainhash.Hash{10}, Height: 10},
+		Time:  time.Unix(1500000000, 0),
+	}
+	err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbTx.ReadWriteBucket(waddrmgrNamespaceKey)
+		err := w.Manager.FastForwardSyncedTo(addrmgrNs,
+			&waddrmgr.BlockStamp{Hash: chainhash.Hash{20}, Height: 20})
+		if err != nil {
+			return err
+		}
+		for _, tx := range []*wire.MsgTx{parent, child} {
+			rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := w.insertRelevantTx(dbTx, rec, &block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := w.GetTransaction(&parentHash)
+	if err != nil {
+		t.Fatalf("unable to get parent transaction: %v", err)
+	}
+	if details.BlockHash == nil || *details.BlockHash != block.Hash ||
+		details.Confirmations != 11 {
+
+		t.Fatalf("unexpected block %v with %d confirmations",
+			details.BlockHash, details.Confirmations)
+	}
+	if details.FeeKnown || details.Inputs[0].AmountKnown {
+		t.Fatal("expected unknown fee spending an unrecorded output")
+	}
+	out := details.Outputs[0]
+	if !out.Mine || out.Account != waddrmgr.DefaultAccountNum ||
+		out.Internal || len(out.Addresses) != 1 ||
+		out.Addresses[0].String() != addr.String() {
+
+		t.Fatalf("unexpected wallet output %+v", out)
+	}
+	if details.Outputs[1].Mine {
+		t.Fatal("output to another script reported as a wallet output")
+	}
+
+	childHash := child.TxHash()
+	details, err = w.GetTransaction(&childHash)
+	if err != nil {
+		t.Fatalf("unable to get child transaction: %v", err)
+	}
+	if !details.FeeKnown || details.Fee != 1e5 {
+		t.Fatalf("expected fee of %v, got %v (known %v)",
+			btcutil.Amount(1e5), details.Fee, details.FeeKnown)
+	}
+	inputs := []TransactionInputDetails{
+		{
+			PreviousOutPoint: child.TxIn[0].PreviousOutPoint,
+			PreviousAmount:   1e6,
+			AmountKnown:      true,
+			Mine:             true,
+			Account:          waddrmgr.DefaultAccountNum,
+		},
+		{
+			PreviousOutPoint: child.TxIn[1].PreviousOutPoint,
+			PreviousAmount:   5e5,
+			AmountKnown:      true,
+		},
+	}
+	for i, input := range inputs {
+		if details.Inputs[i] != input {
+			t.Errorf("input %d: expected %+v, got %+v", i, input,
+				details.Inputs[i])
+		}
+	}
+
+	if _, err := w.GetTransaction(&chainhash.Hash{2}); err == nil {
+		t.Fatal("expected error getting an unrecorded transaction")
+	}
+}
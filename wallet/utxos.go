@@ -6,9 +6,9 @@
 package wallet
 
 import (
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
 )
 
 // OutputSelectionPolicy describes the rules for selecting an output from the
@@ -22,6 +22,32 @@ func (p *OutputSelectionPolicy) meetsRequiredConfs(txHeight, curHeight int32) bo
 	return confirmed(p.RequiredConfirmations, txHeight, curHeight)
 }
 
+// creditAccount returns the account of an unspent output, and whether the
+// output pays to a wallet address.  The account recorded by the transaction
+// store is used when known, and is otherwise looked up with the address of the
+// output.
+func (w *Wallet) creditAccount(addrmgrNs walletdb.ReadBucket,
+	output *wtxmgr.Credit) (uint32, bool) {
+
+	if output.HasAccount {
+		return output.Account, true
+	}
+	return w.outputAccount(addrmgrNs, output.PkScript)
+}
+
+// UnspentOutputCount returns the number of unspent outputs recorded by the
+// wallet, including unconfirmed outputs and outputs of every account.
+func (w *Wallet) UnspentOutputCount() (int, error) {
+	var n int
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		outputs, err := w.TxStore.UnspentOutputs(txmgrNs)
+		n = len(outputs)
+		return err
+	})
+	return n, err
+}
+
 // UnspentOutputs fetches all unspent outputs from the wallet that match rules
 // described in the passed policy.
 func (w *Wallet) UnspentOutputs(policy OutputSelectionPolicy) ([]*TransactionOutput, error) {
@@ -47,20 +73,8 @@ func (w *Wallet) UnspentOutputs(policy OutputSelectionPolicy) ([]*TransactionOut
 			}
 
 			// Ignore outputs that are not controlled by the account.
-			_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.PkScript,
-				w.chainParams)
-			if err != nil || len(addrs) == 0 {
-				// Cannot determine which account this belongs
-				// to without a valid address.  TODO: Fix this
-				// by saving outputs per account, or accounts
-				// per output.
-				continue
-			}
-			_, outputAcct, err := w.Manager.AddrAccount(addrmgrNs, addrs[0])
-			if err != nil {
-				return err
-			}
-			if outputAcct != policy.Account {
+			outputAcct, ok := w.creditAccount(addrmgrNs, &output)
+			if !ok || outputAcct != policy.Account {
 				continue
 			}
 
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
@@ -32,6 +33,7 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/walletdb/encrypted"
 	"github.com/btcsuite/btcwallet/wtxmgr"
 )
 
@@ -63,12 +65,22 @@ var ErrNotSynced = errors.New("wallet is not synchronized with the chain server"
 var (
 	waddrmgrNamespaceKey = []byte("waddrmgr")
 	wtxmgrNamespaceKey   = []byte("wtxmgr")
+	batchNamespaceKey    = []byte("batch")
+	multisigNamespaceKey = []byte("multisig")
+	rescanNamespaceKey   = []byte("rescan")
 )
 
 // Wallet is a structure containing all the components for a
 // complete wallet.  It contains the Armory-style key store
 // addresses and keys),
 type Wallet struct {
+	// txsPublished and txsRejected count the transactions broadcast to
+	// the chain backend since the wallet was opened, and those which it
+	// rejected.  They must be accessed atomically, and are kept first for
+	// 64-bit alignment.
+	txsPublished uint64
+	txsRejected  uint64
+
 	publicPassphrase []byte
 
 	// Data stores
@@ -81,10 +93,53 @@ type Wallet struct {
 	chainClientSynced  bool
 	chainClientSyncMtx sync.Mutex
 
+	// syncStateChanged signals syncStateNotifier that the sync state
+	// changed, so that SetChainSynced does not wait for clients.
+	syncStateChanged chan struct{}
+
+	// chainHealth is the health of the consensus RPC server last recorded
+	// with SetChainHealth, and is reset when a new client is associated.
+	chainHealth    chain.HealthStatus
+	chainHealthMtx sync.Mutex
+
 	lockedOutpoints map[wire.OutPoint]struct{}
 
+	// defaultStrategy is the coin selection strategy used by the RPC
+	// servers when creating transactions.
+	defaultStrategy    CoinSelectionStrategy
+	defaultStrategyMtx sync.Mutex
+
+	// feeEstimator estimates fee rates for confirmation targets.  The
+	// chain backend is used when nil.
+	feeEstimator    FeeEstimator
+	feeEstimatorMtx sync.Mutex
+
+	// testMempoolAccept is set when published transactions are first
+	// tested against the mempool policy of the chain backend.
+	testMempoolAccept    bool
+	testMempoolAcceptMtx sync.Mutex
+
+	// signer signs transactions instead of the address manager when set.
+	signer    Signer
+	signerMtx sync.Mutex
+
+	// batchMtx serializes changes to the queue of batched payments.
+	batchMtx sync.Mutex
+
+	// recoveryWindow is the number of unused addresses past the last
+	// used address of each branch searched during recovery.  It must be
+	// accessed atomically.
 	recoveryWindow uint32
 
+	// recoveryProgress records the blocks scanned by the recovery of the
+	// last synchronization with the chain backend.
+	recoveryProgress    recoveryProgress
+	recoveryProgressMtx sync.Mutex
+
+	// keyScopes are the key scopes registered in addition to the default
+	// key scopes, mapped to the address schemas of their addresses.
+	keyScopes map[waddrmgr.KeyScope]waddrmgr.ScopeAddrSchema
+
 	// Channels for rescan processing.  Requests are added and merged with
 	// any waiting requests, before being sent to another goroutine to
 	// call the rescan RPC.
@@ -93,17 +148,19 @@ type Wallet struct {
 	rescanNotifications chan interface{} // From chain server
 	rescanProgress      chan *RescanProgressMsg
 	rescanFinished      chan *RescanFinishedMsg
+	rescanCancel        chan chan error
 
 	// Channel for transaction creation requests.
 	createTxRequests chan createTxRequest
 
 	// Channels for the manager locker.
-	unlockRequests     chan unlockRequest
-	lockRequests       chan struct{}
-	holdUnlockRequests chan chan heldUnlock
-	lockState          chan bool
-	changePassphrase   chan changePassphraseRequest
-	changePassphrases  chan changePassphrasesRequest
+	unlockRequests       chan unlockRequest
+	scopedUnlockRequests chan scopedUnlockRequest
+	lockRequests         chan struct{}
+	holdUnlockRequests   chan chan heldUnlock
+	lockState            chan bool
+	changePassphrase     chan changePassphraseRequest
+	changePassphrases    chan changePassphrasesRequest
 
 	// Information for reorganization handling.
 	reorganizingLock sync.Mutex
@@ -138,9 +195,10 @@ func (w *Wallet) Start() {
 	}
 	w.quitMu.Unlock()
 
-	w.wg.Add(2)
+	w.wg.Add(3)
 	go w.txCreator()
 	go w.walletLocker()
+	go w.syncStateNotifier()
 }
 
 // SynchronizeRPC associates the wallet with the consensus RPC client,
@@ -178,6 +236,9 @@ func (w *Wallet) SynchronizeRPC(chainClient chain.Interface) {
 	}
 	w.chainClientLock.Unlock()
 
+	// The recorded health described the previous client.
+	w.SetChainHealth(chain.HealthStatus{})
+
 	// TODO: It would be preferable to either run these goroutines
 	// separately from the wallet (use wallet mutator functions to
 	// make changes from the RPC client) and not have to stop and
@@ -215,6 +276,28 @@ func (w *Wallet) ChainClient() chain.Interface {
 	return chainClient
 }
 
+// SetChainHealth records the health of the consensus RPC server, as determined
+// by a chain.HealthMonitor, and notifies clients of chain health notifications
+// when it changed.
+func (w *Wallet) SetChainHealth(status chain.HealthStatus) {
+	w.chainHealthMtx.Lock()
+	changed := status.Health != w.chainHealth.Health
+	w.chainHealth = status
+	w.chainHealthMtx.Unlock()
+
+	if changed {
+		w.NtfnServer.notifyChainHealth(&status)
+	}
+}
+
+// ChainHealth returns the health of the consensus RPC server last recorded with
+// SetChainHealth.  Servers are considered healthy until recorded otherwise.
+func (w *Wallet) ChainHealth() chain.HealthStatus {
+	w.chainHealthMtx.Lock()
+	defer w.chainHealthMtx.Unlock()
+	return w.chainHealth
+}
+
 // quitChan atomically reads the quit channel.
 func (w *Wallet) quitChan() <-chan struct{} {
 	w.quitMu.Lock()
@@ -292,15 +375,67 @@ func (w *Wallet) ChainSynced() bool {
 // the client disconnected (and is attempting a reconnect).  This will be unknown
 // until the reconnect notification is received, at which point the wallet can be
 // marked out of sync again until after the next rescan completes.
+//
+// Clients of sync state notifications are notified when the state changes,
+// without waiting for them.
 func (w *Wallet) SetChainSynced(synced bool) {
 	w.chainClientSyncMtx.Lock()
+	changed := synced != w.chainClientSynced
 	w.chainClientSynced = synced
 	w.chainClientSyncMtx.Unlock()
+
+	if changed {
+		select {
+		case w.syncStateChanged <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// syncStateNotifier notifies clients of sync state notifications of the
+// changes recorded by SetChainSynced.  Changes made while clients are being
+// notified are coalesced, so clients are only notified of the latest state.
+func (w *Wallet) syncStateNotifier() {
+	defer w.wg.Done()
+
+	quit := w.quitChan()
+	var notified bool
+	for {
+		select {
+		case <-w.syncStateChanged:
+			state := w.SyncState()
+			if state.Synced == notified {
+				continue
+			}
+			notified = state.Synced
+			w.NtfnServer.notifySyncState(state)
+
+		case <-quit:
+			return
+		}
+	}
+}
+
+// SyncState returns whether the wallet is synced to the chain server, along
+// with the height of the block the wallet is synced to and the best block
+// height of the chain server, which is zero when it can not be queried.
+func (w *Wallet) SyncState() *SyncStateNotification {
+	state := &SyncStateNotification{
+		Synced: w.ChainSynced(),
+		Height: w.Manager.SyncedTo().Height,
+	}
+	if chainClient := w.ChainClient(); chainClient != nil {
+		_, bestHeight, err := chainClient.GetBestBlock()
+		if err == nil {
+			state.BackendHeight = bestHeight
+		}
+	}
+	return state
 }
 
 // activeData returns the currently-active receiving addresses and all unspent
-// outputs.  This is primarely intended to provide the parameters for a
-// rescan request.
+// outputs, including those of multisig accounts.  This is primarely intended
+// to provide the parameters for a rescan request.
 func (w *Wallet) activeData(dbtx walletdb.ReadTx) ([]btcutil.Address, []wtxmgr.Credit, error) {
 	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
@@ -314,7 +449,17 @@ func (w *Wallet) activeData(dbtx walletdb.ReadTx) ([]btcutil.Address, []wtxmgr.C
 		return nil, nil, err
 	}
 	unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
-	return addrs, unspent, err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	multisigAddrs, multisigUnspent, err := w.multisigActiveData(dbtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	addrs = append(addrs, multisigAddrs...)
+	unspent = append(unspent, multisigUnspent...)
+	return addrs, unspent, nil
 }
 
 // syncWithChain brings the wallet up to date with the current chain server
@@ -348,13 +493,16 @@ func (w *Wallet) syncWithChain() error {
 	// rescan at this height, and instead wait for the backend to catch up.
 	isInitialSync := len(unspent) == 0
 
-	isRecovery := w.recoveryWindow > 0
+	recoveryWindow := w.RecoveryWindow()
+	isRecovery := recoveryWindow > 0
 	birthday := w.Manager.Birthday()
 
 	// If an initial sync is attempted, we will try and find the block stamp
 	// of the first block past our birthday. This will be fed into the
 	// rescan to ensure we catch transactions that are sent while performing
-	// the initial sync.
+	// the initial sync. The block is stored once found, so that a restored
+	// wallet which is restarted before its rescan completes still rescans
+	// from its birthday.
 	var birthdayStamp *waddrmgr.BlockStamp
 
 	// TODO(jrick): How should this handle a synced height earlier than
@@ -393,6 +541,25 @@ func (w *Wallet) syncWithChain() error {
 		log.Infof("Catching up block hashes to height %d, this will "+
 			"take a while...", logHeight)
 
+		birthdayStamp, err = w.syncedBirthdayBlock(
+			chainClient, w.Manager.SyncedTo(),
+		)
+		if err != nil {
+			return err
+		}
+
+		// The blocks before the birthday can not contain transactions
+		// of the wallet, so a first sync skips them rather than
+		// walking the chain from the genesis block.
+		if birthdayStamp == nil {
+			startHeight, err = w.fastForwardToBirthday(
+				startHeight, bestHeight,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Initialize the first database transaction.
 		tx, err := w.db.BeginReadWriteTx()
 		if err != nil {
@@ -406,12 +573,12 @@ func (w *Wallet) syncWithChain() error {
 		if isRecovery {
 			log.Infof("RECOVERY MODE ENABLED -- rescanning for "+
 				"used addresses with recovery_window=%d",
-				w.recoveryWindow)
+				recoveryWindow)
 
 			// Initialize the recovery manager with a default batch
 			// size of 2000.
 			recoveryMgr = NewRecoveryManager(
-				w.recoveryWindow, recoveryBatchSize,
+				recoveryWindow, recoveryBatchSize,
 				w.chainParams,
 			)
 
@@ -434,6 +601,13 @@ func (w *Wallet) syncWithChain() error {
 			if err != nil {
 				return err
 			}
+
+			w.setRecoveryProgress(recoveryProgress{
+				window:      recoveryWindow,
+				startHeight: startHeight,
+				height:      startHeight - 1,
+				bestHeight:  bestHeight,
+			})
 		}
 
 		for height := startHeight; height <= bestHeight; height++ {
@@ -536,6 +710,14 @@ func (w *Wallet) syncWithChain() error {
 				// Clear the batch of all processed blocks.
 				recoveryMgr.ResetBlockBatch()
 			}
+			if isRecovery {
+				w.setRecoveryProgress(recoveryProgress{
+					window:      recoveryWindow,
+					startHeight: startHeight,
+					height:      height,
+					bestHeight:  bestHeight,
+				})
+			}
 
 			// Every 10K blocks, commit and start a new database TX.
 			if height%10000 == 0 {
@@ -569,6 +751,14 @@ func (w *Wallet) syncWithChain() error {
 			}
 		}
 
+		if birthdayStamp != nil {
+			err := w.Manager.SetBirthdayBlock(ns, *birthdayStamp)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
 		// Commit (or roll back) the final database transaction.
 		err = tx.Commit()
 		if err != nil {
@@ -577,6 +767,16 @@ func (w *Wallet) syncWithChain() error {
 		}
 		log.Info("Done catching up block hashes")
 
+		if isRecovery {
+			w.setRecoveryProgress(recoveryProgress{
+				window:      recoveryWindow,
+				startHeight: startHeight,
+				height:      bestHeight,
+				bestHeight:  bestHeight,
+				finished:    true,
+			})
+		}
+
 		// Since we've spent some time catching up block hashes, we
 		// might have new addresses waiting for us that were requested
 		// during initial sync. Make sure we have those before we
@@ -662,17 +862,133 @@ func (w *Wallet) syncWithChain() error {
 		return err
 	}
 
-	return w.rescanWithTarget(addrs, unspent, birthdayStamp)
+	// A rescan interrupted by a restart, such as a long initial rescan or
+	// the rescan of imported addresses, resumes from its checkpoint when it
+	// is below the block the rescan would begin at.
+	checkpoint, err := w.rescanResumeBlock(chainClient)
+	if err != nil {
+		return err
+	}
+	startStamp := birthdayStamp
+	if startStamp == nil {
+		syncedTo := w.Manager.SyncedTo()
+		startStamp = &syncedTo
+	}
+	if checkpoint != nil && checkpoint.Height < startStamp.Height {
+		log.Infof("Resuming interrupted rescan from block %v (height %d)",
+			checkpoint.Hash, checkpoint.Height)
+		startStamp = checkpoint
+	}
+
+	return w.rescanWithTarget(addrs, unspent, startStamp)
+}
+
+// fastForwardToBirthday marks the wallet as synced to the block before the
+// first block past its birthday, when it is synced to an earlier block, and
+// returns the height to catch up from.  The stored birthday already includes
+// a safety margin, so no further margin is applied.  The block is kept below
+// the best block so that catching up still waits for a backend which is
+// synchronizing.
+func (w *Wallet) fastForwardToBirthday(startHeight,
+	bestHeight int32) (int32, error) {
+
+	if bestHeight-1 <= startHeight {
+		return startHeight, nil
+	}
+	first, err := w.firstBlockStampAfter(w.Manager.Birthday())
+	if err != nil {
+		return 0, err
+	}
+	height := first.Height - 1
+	if height >= bestHeight {
+		height = bestHeight - 1
+	}
+	if height <= startHeight {
+		return startHeight, nil
+	}
+	bs, err := w.BlockStampAtHeight(height)
+	if err != nil {
+		return 0, err
+	}
+
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return w.Manager.FastForwardSyncedTo(ns, bs)
+	})
+	if err != nil {
+		return 0, err
+	}
+	log.Infof("Skipped %d blocks before the wallet birthday, catching up "+
+		"from block %v (height %d)", bs.Height-startHeight, bs.Hash,
+		bs.Height)
+	return bs.Height + 1, nil
+}
+
+// syncedBirthdayBlock returns the stored birthday block of the wallet if it is
+// still in the main chain.  When no valid birthday block is stored but blocks
+// past the birthday have already been synced, such as after a restart during
+// the initial sync, the block is searched for by its timestamp, as catching
+// up from the synced height would otherwise take the synced block as the
+// birthday block.  Nil is returned if the birthday has not been synced yet,
+// in which case the block is found while catching up.
+func (w *Wallet) syncedBirthdayBlock(chainClient chain.Interface,
+	syncedTo waddrmgr.BlockStamp) (*waddrmgr.BlockStamp, error) {
+
+	var block waddrmgr.BlockStamp
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err error
+		block, err = w.Manager.BirthdayBlock(ns)
+		return err
+	})
+	switch {
+	case err == nil:
+		hash, err := chainClient.GetBlockHash(int64(block.Height))
+		if err != nil {
+			return nil, err
+		}
+		if *hash == block.Hash {
+			return &block, nil
+		}
+		log.Infof("Birthday block %v (height %d) is no longer in the "+
+			"main chain", block.Hash, block.Height)
+
+	case !waddrmgr.IsError(err, waddrmgr.ErrBirthdayBlockNotSet):
+		return nil, err
+	}
+
+	if syncedTo.Height == 0 {
+		return nil, nil
+	}
+	bs, err := w.firstBlockStampAfter(w.Manager.Birthday())
+	if err != nil {
+		return nil, err
+	}
+	if bs.Height > syncedTo.Height {
+		return nil, nil
+	}
+	log.Infof("Found birthday block %v (height %d)", bs.Hash, bs.Height)
+	return bs, nil
 }
 
 // defaultScopeManagers fetches the ScopedKeyManagers from the wallet using the
-// default set of key scopes.
+// default set of key scopes and the registered key scopes.  Scopes the wallet
+// does not have, as is possible for watching-only wallets or registered
+// scopes of a wallet which was not unlocked yet, are skipped.
 func (w *Wallet) defaultScopeManagers() (
 	map[waddrmgr.KeyScope]*waddrmgr.ScopedKeyManager, error) {
 
+	scopes := append([]waddrmgr.KeyScope(nil), waddrmgr.DefaultKeyScopes...)
+	for scope := range w.keyScopes {
+		scopes = append(scopes, scope)
+	}
+
 	scopedMgrs := make(map[waddrmgr.KeyScope]*waddrmgr.ScopedKeyManager)
-	for _, scope := range waddrmgr.DefaultKeyScopes {
+	for _, scope := range scopes {
 		scopedMgr, err := w.Manager.FetchScopedKeyManager(scope)
+		if waddrmgr.IsError(err, waddrmgr.ErrScopeNotFound) {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -683,6 +999,37 @@ func (w *Wallet) defaultScopeManagers() (
 	return scopedMgrs, nil
 }
 
+// createKeyScopes creates the registered key scopes the wallet does not have
+// yet.  The address manager must be unlocked, as the coin type keys of the
+// scopes are derived from the root private key.
+func (w *Wallet) createKeyScopes() error {
+	if len(w.keyScopes) == 0 {
+		return nil
+	}
+
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		for scope, addrSchema := range w.keyScopes {
+			_, err := w.Manager.FetchScopedKeyManager(scope)
+			if err == nil {
+				continue
+			}
+			if !waddrmgr.IsError(err, waddrmgr.ErrScopeNotFound) {
+				return err
+			}
+
+			_, err = w.Manager.NewScopedKeyManager(
+				addrmgrNs, scope, addrSchema,
+			)
+			if err != nil {
+				return err
+			}
+			log.Infof("Created key scope %v", &scope)
+		}
+		return nil
+	})
+}
+
 // recoverDefaultScopes attempts to recover any addresses belonging to any
 // active scoped key managers known to the wallet. Recovery of each scope's
 // default account will be done iteratively against the same batch of blocks.
@@ -797,6 +1144,7 @@ expandHorizons:
 		if err != nil {
 			return err
 		}
+		w.notifyRelevantTx(tx, txRecord, &filterResp.BlockMeta)
 	}
 
 	// Update the batch to indicate that we've processed all block through
@@ -812,6 +1160,51 @@ expandHorizons:
 	return nil
 }
 
+// RecoveryWindow returns the address lookahead, or gap limit, used when
+// recovering the wallet.  A window of zero disables recovery.
+func (w *Wallet) RecoveryWindow() uint32 {
+	return atomic.LoadUint32(&w.recoveryWindow)
+}
+
+// SetRecoveryWindow sets the address lookahead, or gap limit, used when
+// recovering the wallet, overriding the window the wallet was opened with.  A
+// recovery which is already in progress continues using its current window;
+// the new window is used by the next synchronization with the chain backend.
+func (w *Wallet) SetRecoveryWindow(recoveryWindow uint32) {
+	atomic.StoreUint32(&w.recoveryWindow, recoveryWindow)
+}
+
+// setRecoveryProgress records the progress of a running recovery.
+func (w *Wallet) setRecoveryProgress(progress recoveryProgress) {
+	w.recoveryProgressMtx.Lock()
+	w.recoveryProgress = progress
+	w.recoveryProgressMtx.Unlock()
+}
+
+// RecoveryInfo returns whether the wallet is recovering the addresses of its
+// seed, along with the recovery window and progress of the recovery.
+func (w *Wallet) RecoveryInfo() *RecoveryInfo {
+	w.recoveryProgressMtx.Lock()
+	progress := w.recoveryProgress
+	w.recoveryProgressMtx.Unlock()
+
+	// Before a recovery begins, the window the wallet will recover with is
+	// reported.
+	if progress.window == 0 {
+		window := w.RecoveryWindow()
+		return &RecoveryInfo{
+			Recovering: window != 0,
+			Window:     window,
+		}
+	}
+	return &RecoveryInfo{
+		Recovering: !progress.finished,
+		Finished:   progress.finished,
+		Window:     progress.window,
+		Progress:   progress.fraction(),
+	}
+}
+
 // expandScopeHorizons ensures that the ScopeRecoveryState has an adequately
 // sized look ahead for both its internal and external branches. The keys
 // derived here are added to the scope's recovery state, but do not affect the
@@ -1075,6 +1468,8 @@ type (
 		outputs     []*wire.TxOut
 		minconf     int32
 		feeSatPerKB btcutil.Amount
+		strategy    CoinSelectionStrategy
+		opts        *txCreateOptions
 		resp        chan createTxResponse
 	}
 	createTxResponse struct {
@@ -1099,14 +1494,22 @@ out:
 	for {
 		select {
 		case txr := <-w.createTxRequests:
-			heldUnlock, err := w.holdUnlock()
-			if err != nil {
-				txr.resp <- createTxResponse{nil, err}
-				continue
+			// Unsigned transactions are created without holding
+			// the wallet unlocked.
+			var heldUnlock heldUnlock
+			if !txr.opts.unsigned {
+				var err error
+				heldUnlock, err = w.holdUnlock()
+				if err != nil {
+					txr.resp <- createTxResponse{nil, err}
+					continue
+				}
 			}
 			tx, err := w.txToOutputs(txr.outputs, txr.account,
-				txr.minconf, txr.feeSatPerKB)
-			heldUnlock.release()
+				txr.minconf, txr.feeSatPerKB, txr.strategy, txr.opts)
+			if heldUnlock != nil {
+				heldUnlock.release()
+			}
 			txr.resp <- createTxResponse{tx, err}
 		case <-quit:
 			break out
@@ -1118,17 +1521,36 @@ out:
 // CreateSimpleTx creates a new signed transaction spending unspent P2PKH
 // outputs with at laest minconf confirmations spending to any number of
 // address/amount pairs.  Change and an appropriate transaction fee are
-// automatically included, if necessary.  All transaction creation through this
-// function is serialized to prevent the creation of many transactions which
-// spend the same outputs.
+// automatically included, if necessary.  The coin selection strategy decides
+// which eligible outputs are spent, and the passed options further modify how
+// the transaction is created.  All transaction creation through this function
+// is serialized to prevent the creation of many transactions which spend the
+// same outputs.
 func (w *Wallet) CreateSimpleTx(account uint32, outputs []*wire.TxOut,
-	minconf int32, satPerKb btcutil.Amount) (*txauthor.AuthoredTx, error) {
+	minconf int32, satPerKb btcutil.Amount, strategy CoinSelectionStrategy,
+	opts ...TxCreateOption) (*txauthor.AuthoredTx, error) {
+
+	o := applyTxCreateOptions(opts)
+	satPerKb, err := w.txFeeRate(satPerKb, o)
+	if err != nil {
+		return nil, err
+	}
+	return w.createSimpleTx(account, outputs, minconf, satPerKb, strategy, o)
+}
+
+// createSimpleTx creates a transaction as described by CreateSimpleTx, paying
+// exactly the fee rate satPerKb.
+func (w *Wallet) createSimpleTx(account uint32, outputs []*wire.TxOut,
+	minconf int32, satPerKb btcutil.Amount, strategy CoinSelectionStrategy,
+	opts *txCreateOptions) (*txauthor.AuthoredTx, error) {
 
 	req := createTxRequest{
 		account:     account,
 		outputs:     outputs,
 		minconf:     minconf,
 		feeSatPerKB: satPerKb,
+		strategy:    strategy,
+		opts:        opts,
 		resp:        make(chan createTxResponse),
 	}
 	w.createTxRequests <- req
@@ -1136,6 +1558,23 @@ func (w *Wallet) CreateSimpleTx(account uint32, outputs []*wire.TxOut,
 	return resp.tx, resp.err
 }
 
+// DefaultCoinSelectionStrategy returns the coin selection strategy used when
+// creating transactions on behalf of RPC clients, which are unable to choose
+// a strategy themselves.
+func (w *Wallet) DefaultCoinSelectionStrategy() CoinSelectionStrategy {
+	w.defaultStrategyMtx.Lock()
+	defer w.defaultStrategyMtx.Unlock()
+	return w.defaultStrategy
+}
+
+// SetDefaultCoinSelectionStrategy sets the coin selection strategy used when
+// creating transactions on behalf of RPC clients.
+func (w *Wallet) SetDefaultCoinSelectionStrategy(strategy CoinSelectionStrategy) {
+	w.defaultStrategyMtx.Lock()
+	w.defaultStrategy = strategy
+	w.defaultStrategyMtx.Unlock()
+}
+
 type (
 	unlockRequest struct {
 		passphrase []byte
@@ -1143,6 +1582,15 @@ type (
 		err        chan error
 	}
 
+	// scopedUnlockRequest unlocks the wallet until done is closed, after
+	// which the wallet is locked and locked is closed.
+	scopedUnlockRequest struct {
+		passphrase []byte
+		err        chan error
+		done       chan struct{}
+		locked     chan struct{}
+	}
+
 	changePassphraseRequest struct {
 		old, new []byte
 		private  bool
@@ -1172,11 +1620,7 @@ out:
 	for {
 		select {
 		case req := <-w.unlockRequests:
-			err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
-				addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
-				return w.Manager.Unlock(addrmgrNs, req.passphrase)
-			})
-			if err != nil {
+			if err := w.unlockManager(req.passphrase); err != nil {
 				req.err <- err
 				continue
 			}
@@ -1189,33 +1633,59 @@ out:
 			req.err <- nil
 			continue
 
+		case req := <-w.scopedUnlockRequests:
+			if err := w.unlockManager(req.passphrase); err != nil {
+				req.err <- err
+				continue
+			}
+			log.Info("The wallet has been unlocked for a single operation")
+			req.err <- nil
+
+			// Other lock state changes wait until the operation
+			// is done, so the wallet can not be left unlocked.
+			w.holdScopedUnlock(holdChan, req.done, quit)
+			timeout = nil
+			w.lockManager()
+			close(req.locked)
+			continue
+
 		case req := <-w.changePassphrase:
 			err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+				if !req.private {
+					return w.rotatePublicPassphrase(
+						tx, req.old, req.new,
+					)
+				}
 				addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
 				return w.Manager.ChangePassphrase(
 					addrmgrNs, req.old, req.new, req.private,
-					&waddrmgr.DefaultScryptOptions,
+					nil,
 				)
 			})
+			if err == nil && !req.private {
+				w.publicPassphrase = req.new
+			}
 			req.err <- err
 			continue
 
 		case req := <-w.changePassphrases:
 			err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
-				addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
-				err := w.Manager.ChangePassphrase(
-					addrmgrNs, req.publicOld, req.publicNew,
-					false, &waddrmgr.DefaultScryptOptions,
+				err := w.changeDatabasePassphrase(
+					tx, req.publicOld, req.publicNew,
 				)
 				if err != nil {
 					return err
 				}
-
-				return w.Manager.ChangePassphrase(
-					addrmgrNs, req.privateOld, req.privateNew,
-					true, &waddrmgr.DefaultScryptOptions,
+				addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+				return w.Manager.ChangePassphrases(
+					addrmgrNs, req.publicOld, req.publicNew,
+					req.privateOld, req.privateNew, nil,
+					reencryptPublicData(tx),
 				)
 			})
+			if err == nil {
+				w.publicPassphrase = req.publicNew
+			}
 			req.err <- err
 			continue
 
@@ -1253,14 +1723,63 @@ out:
 		// Select statement fell through by an explicit lock or the
 		// timer expiring.  Lock the manager here.
 		timeout = nil
-		err := w.Manager.Lock()
-		if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+		w.lockManager()
+	}
+	w.wg.Done()
+}
+
+// unlockManager unlocks the address manager with the private passphrase and
+// creates any key scopes which require the unlocked manager.  The manager is
+// left locked if an error occurs.
+func (w *Wallet) unlockManager(passphrase []byte) error {
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		return w.Manager.Unlock(addrmgrNs, passphrase)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.createKeyScopes(); err != nil {
+		if err := w.Manager.Lock(); err != nil {
 			log.Errorf("Could not lock wallet: %v", err)
-		} else {
-			log.Info("The wallet has been locked")
+		}
+		return err
+	}
+	return nil
+}
+
+// lockManager locks the address manager, which zeroes its decrypted keys.
+func (w *Wallet) lockManager() {
+	err := w.Manager.Lock()
+	if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+		log.Errorf("Could not lock wallet: %v", err)
+	} else {
+		log.Info("The wallet has been locked")
+	}
+}
+
+// holdScopedUnlock serves the unlock holds and lock state queries of the
+// operation of a scoped unlock until done is closed or the wallet shuts down.
+// Requests to unlock, lock or change the passphrase of the wallet are not
+// served in the meantime.
+func (w *Wallet) holdScopedUnlock(holdChan heldUnlock, done,
+	quit <-chan struct{}) {
+
+	for {
+		select {
+		case req := <-w.holdUnlockRequests:
+			req <- holdChan
+			<-holdChan // Block until the lock is released.
+
+		case w.lockState <- false:
+
+		case <-done:
+			return
+
+		case <-quit:
+			return
 		}
 	}
-	w.wg.Done()
 }
 
 // Unlock unlocks the wallet's address manager and relocks it after timeout has
@@ -1278,6 +1797,36 @@ func (w *Wallet) Unlock(passphrase []byte, lock <-chan time.Time) error {
 	return <-err
 }
 
+// WithUnlock unlocks the wallet's address manager with the private passphrase,
+// runs fn, and locks the wallet again when fn returns, which zeroes the
+// decrypted keys.  Unlike Unlock, the keys are only decrypted for the duration
+// of the operation: other requests to unlock or lock the wallet wait until fn
+// returns, and the wallet is locked afterwards even if it was unlocked before
+// WithUnlock was called.  fn is not run and an error is returned if the
+// passphrase is incorrect.  The error of fn is returned.  fn must not itself
+// unlock, lock or change the passphrase of the wallet.
+//
+// Operations of other callers which require the unlocked wallet may still
+// succeed while fn runs, so fn should be short and the wallet should not be
+// unlocked with Unlock if keys must only be used by fn.
+func (w *Wallet) WithUnlock(passphrase []byte, fn func() error) error {
+	req := scopedUnlockRequest{
+		passphrase: passphrase,
+		err:        make(chan error, 1),
+		done:       make(chan struct{}),
+		locked:     make(chan struct{}),
+	}
+	w.scopedUnlockRequests <- req
+	if err := <-req.err; err != nil {
+		return err
+	}
+	defer func() {
+		close(req.done)
+		<-req.locked
+	}()
+	return fn()
+}
+
 // Lock locks the wallet's address manager.
 func (w *Wallet) Lock() {
 	w.lockRequests <- struct{}{}
@@ -1295,9 +1844,21 @@ func (w *Wallet) Locked() bool {
 // to the walletLocker goroutine and disallow callers from explicitly
 // handling the locking mechanism.
 func (w *Wallet) holdUnlock() (heldUnlock, error) {
+	// Signing with a signer does not require the private keys of the
+	// address manager, so the wallet may remain locked.
+	if w.remoteSigner() != nil {
+		return make(heldUnlock, 1), nil
+	}
+
 	req := make(chan heldUnlock)
 	w.holdUnlockRequests <- req
 	hl, ok := <-req
+	if !ok && w.Manager.WatchOnly() {
+		return nil, waddrmgr.ManagerError{
+			ErrorCode:   waddrmgr.ErrWatchingOnly,
+			Description: "wallet is watching-only",
+		}
+	}
 	if !ok {
 		// TODO(davec): This should be defined and exported from
 		// waddrmgr.
@@ -1331,7 +1892,49 @@ func (w *Wallet) ChangePrivatePassphrase(old, new []byte) error {
 	return <-err
 }
 
-// ChangePublicPassphrase modifies the public passphrase of the wallet.
+// rotatePublicPassphrase changes the public passphrase of the address manager
+// and replaces its public crypto key, re-encrypting the multisig accounts of
+// the wallet in the same transaction.  The passphrase of an encrypted database
+// is changed as well.
+func (w *Wallet) rotatePublicPassphrase(tx walletdb.ReadWriteTx, old,
+	new []byte) error {
+
+	if err := w.changeDatabasePassphrase(tx, old, new); err != nil {
+		return err
+	}
+	addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+	return w.Manager.RotatePublicPassphrase(
+		addrmgrNs, old, new, nil, reencryptPublicData(tx),
+	)
+}
+
+// changeDatabasePassphrase changes the passphrase of an encrypted database,
+// which is the public passphrase, from old to new.  It only changes the
+// database, so it is done before the address manager, which updates its
+// in-memory keys, so that neither is changed if the transaction fails.
+func (w *Wallet) changeDatabasePassphrase(tx walletdb.ReadWriteTx, old,
+	new []byte) error {
+
+	if encDB, ok := walletdb.Unwrap(w.db).(*encrypted.DB); ok {
+		err := encDB.ChangePassphrase(tx, old, new)
+		return convertDatabaseError(err)
+	}
+	return nil
+}
+
+// reencryptPublicData returns the function re-encrypting the data the wallet
+// stores outside of the address manager with the public crypto key, which are
+// the keys of its multisig accounts, when that key is rotated.
+func reencryptPublicData(tx walletdb.ReadWriteTx) func(waddrmgr.ReencryptFunc) error {
+	return func(reencrypt waddrmgr.ReencryptFunc) error {
+		multisigNs := tx.ReadWriteBucket(multisigNamespaceKey)
+		return reencryptMultisigAccounts(multisigNs, reencrypt)
+	}
+}
+
+// ChangePublicPassphrase modifies the public passphrase of the wallet.  The
+// public crypto key is replaced and all public data is re-encrypted, which
+// does not require the private passphrase or locking the wallet.
 func (w *Wallet) ChangePublicPassphrase(old, new []byte) error {
 	err := make(chan error, 1)
 	w.changePassphrase <- changePassphraseRequest{
@@ -1344,7 +1947,8 @@ func (w *Wallet) ChangePublicPassphrase(old, new []byte) error {
 }
 
 // ChangePassphrases modifies the public and private passphrase of the wallet
-// atomically.
+// atomically.  Neither passphrase is changed if either old passphrase is
+// incorrect.
 func (w *Wallet) ChangePassphrases(publicOld, publicNew, privateOld,
 	privateNew []byte) error {
 
@@ -1420,47 +2024,22 @@ type Balances struct {
 }
 
 // CalculateAccountBalances sums the amounts of all unspent transaction
-// outputs to the given account of a wallet and returns the balance.
-//
-// This function is much slower than it needs to be since transactions outputs
-// are not indexed by the accounts they credit to, and all unspent transaction
-// outputs must be iterated.
+// outputs to the given account of a wallet and returns the balance.  The
+// balances are calculated from the unspent outputs indexed by account, so only
+// the outputs of the most recent blocks are read.
 func (w *Wallet) CalculateAccountBalances(account uint32, confirms int32) (Balances, error) {
 	var bals Balances
 	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
-		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
 		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
 
 		// Get current block.  The block height used for calculating
 		// the number of tx confirmations.
 		syncBlock := w.Manager.SyncedTo()
 
-		unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
-		if err != nil {
-			return err
-		}
-		for i := range unspent {
-			output := &unspent[i]
-
-			var outputAcct uint32
-			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
-				output.PkScript, w.chainParams)
-			if err == nil && len(addrs) > 0 {
-				_, outputAcct, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
-			}
-			if err != nil || outputAcct != account {
-				continue
-			}
-
-			bals.Total += output.Amount
-			if output.FromCoinBase && !confirmed(int32(w.chainParams.CoinbaseMaturity),
-				output.Height, syncBlock.Height) {
-				bals.ImmatureReward += output.Amount
-			} else if confirmed(confirms, output.Height, syncBlock.Height) {
-				bals.Spendable += output.Amount
-			}
-		}
-		return nil
+		b, err := w.TxStore.AccountBalances(txmgrNs, account,
+			confirms, syncBlock.Height)
+		bals = Balances(b)
+		return err
 	})
 	return bals, err
 }
@@ -1720,6 +2299,48 @@ func (w *Wallet) NextAccount(scope waddrmgr.KeyScope, name string) (uint32, erro
 	return account, err
 }
 
+// ImportAccount imports a watching-only account from the account extended
+// public key accountPubKey into the key scope, for example an account of a
+// hardware wallet.  The external and internal branches of the account are
+// derived using the address schema of the scope.  If addrType is non-nil, it
+// must match the external address type of the scope, which ensures the
+// account is imported into the scope creating the addresses the key is used
+// for.  Funds received to addresses of the account are tracked, but can only
+// be spent by signing elsewhere, for example with a PSBT created by FundPsbt.
+// Funds received before the import are only found by a rescan.  The
+// properties of the new account are returned.
+func (w *Wallet) ImportAccount(name string, accountPubKey *hdkeychain.ExtendedKey,
+	scope waddrmgr.KeyScope,
+	addrType *waddrmgr.AddressType) (*waddrmgr.AccountProperties, error) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+	if addrType != nil && *addrType != manager.AddrSchema().ExternalAddrType {
+		return nil, fmt.Errorf("key scope %v does not create addresses "+
+			"of type %v", &scope, *addrType)
+	}
+
+	var props *waddrmgr.AccountProperties
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err := manager.NewAccountWatchingOnly(
+			addrmgrNs, name, accountPubKey,
+		)
+		if err != nil {
+			return err
+		}
+		props, err = manager.AccountProperties(addrmgrNs, account)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.NtfnServer.notifyAccountProperties(props)
+	return props, nil
+}
+
 // CreditCategory describes the type of wallet transaction output.  The category
 // of "sent transactions" (debits) is always "send", and is not expressed by
 // this type.
@@ -1795,22 +2416,9 @@ func listTransactions(tx walletdb.ReadTx, details *wtxmgr.TxDetails, addrMgr *wa
 
 	send := len(details.Debits) != 0
 
-	// Fee can only be determined if every input is a debit.
-	var feeF64 float64
-	if len(details.Debits) == len(details.MsgTx.TxIn) {
-		var debitTotal btcutil.Amount
-		for _, deb := range details.Debits {
-			debitTotal += deb.Amount
-		}
-		var outputTotal btcutil.Amount
-		for _, output := range details.MsgTx.TxOut {
-			outputTotal += btcutil.Amount(output.Value)
-		}
-		// Note: The actual fee is debitTotal - outputTotal.  However,
-		// this RPC reports negative numbers for fees, so the inverse
-		// is calculated.
-		feeF64 = (outputTotal - debitTotal).ToBTC()
-	}
+	// This RPC reports negative numbers for fees, so the inverse of the
+	// fee is reported.  Unknown fees are reported as zero.
+	feeF64 := (-details.Fee).ToBTC()
 
 outputs:
 	for i, output := range details.MsgTx.TxOut {
@@ -1857,6 +2465,7 @@ outputs:
 			//   Category
 			//   Amount
 			//   Fee
+			//   Comment (only for labeled transactions)
 			Address:         address,
 			Vout:            uint32(i),
 			Confirmations:   confirmations,
@@ -1868,6 +2477,7 @@ outputs:
 			Time:            received,
 			TimeReceived:    received,
 		}
+		result.Comment = details.Label
 
 		// Add a received/generated/immature result if this is a credit.
 		// If the output was spent, create a second result under the
@@ -1918,6 +2528,105 @@ func (w *Wallet) ListSinceBlock(start, end, syncHeight int32) ([]btcjson.ListTra
 	return txList, err
 }
 
+// ListSinceBlockHash returns the details of the transactions mined after the
+// block since, and of all unmined transactions, along with the hash of a block
+// to list transactions since in the next call.  If since is nil, all
+// transactions are included.  This is intended to be used for listsinceblock
+// RPC replies.
+//
+// The returned block is the newest block with a wallet transaction which has
+// at least targetConfs confirmations, or the genesis block if there is none.
+// Listing from it returns the same transactions as listing from the block with
+// targetConfs confirmations, which may be below the blocks the wallet synced.
+//
+// When since was reorganized out of the main chain, transactions are listed
+// from the block after the last block of the main chain since descends from,
+// so clients polling with the previously returned block hash receive every
+// transaction of the new chain.  Transactions of reorganized blocks which were
+// not mined again are included as unmined transactions, unless they were
+// double spent by the new chain.
+func (w *Wallet) ListSinceBlockHash(since *chainhash.Hash,
+	targetConfs int32) ([]btcjson.ListTransactionsResult, *chainhash.Hash, error) {
+
+	syncBlock := w.Manager.SyncedTo()
+
+	var start int32
+	if since != nil {
+		forkHeight, err := w.mainChainAncestor(since)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = forkHeight + 1
+	}
+
+	txs, err := w.ListSinceBlock(start, -1, syncBlock.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lastHeight := syncBlock.Height + 1 - targetConfs
+	if lastHeight > syncBlock.Height {
+		lastHeight = syncBlock.Height
+	}
+	if lastHeight < 0 {
+		lastHeight = 0
+	}
+	lastBlock := w.chainParams.GenesisHash
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		block, err := w.TxStore.LastBlock(txmgrNs, lastHeight)
+		if block != nil {
+			lastBlock = &block.Hash
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs, lastBlock, nil
+}
+
+// mainChainAncestor returns the height of the newest block of the main chain
+// of the chain backend which is hash or one of its ancestors.  This is the
+// height of hash itself unless it was reorganized out of the main chain.
+func (w *Wallet) mainChainAncestor(hash *chainhash.Hash) (int32, error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return 0, err
+	}
+	heightClient, ok := chainClient.(interface {
+		GetBlockHeight(*chainhash.Hash) (int32, error)
+	})
+	if !ok {
+		return 0, fmt.Errorf("%s backend does not look up block heights",
+			chainClient.BackEnd())
+	}
+
+	for {
+		height, err := heightClient.GetBlockHeight(hash)
+		if err != nil {
+			return 0, err
+		}
+		mainHash, err := chainClient.GetBlockHash(int64(height))
+		if err != nil {
+			return 0, err
+		}
+		if *mainHash == *hash {
+			return height, nil
+		}
+		if height == 0 {
+			return 0, fmt.Errorf("block %v is not part of the chain",
+				hash)
+		}
+
+		header, err := chainClient.GetBlockHeader(hash)
+		if err != nil {
+			return 0, err
+		}
+		hash = &header.PrevBlock
+	}
+}
+
 // ListTransactions returns a slice of objects with details about a recorded
 // transaction.  This is intended to be used for listtransactions RPC
 // replies.
@@ -1971,6 +2680,33 @@ func (w *Wallet) ListTransactions(from, count int) ([]btcjson.ListTransactionsRe
 	return txList, err
 }
 
+// ListTransactionsPaged returns a slice of objects with details about the page
+// of recorded transactions described by q.  Unlike ListTransactions, only the
+// transactions of the page are read from the database, so wallets with many
+// transactions can be listed a page at a time.
+func (w *Wallet) ListTransactionsPaged(q *wtxmgr.TxQuery) ([]btcjson.ListTransactionsResult, error) {
+	txList := []btcjson.ListTransactionsResult{}
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+
+		// Get current block.  The block height used for calculating
+		// the number of tx confirmations.
+		syncBlock := w.Manager.SyncedTo()
+
+		details, err := w.TxStore.QueryTransactions(txmgrNs, q)
+		if err != nil {
+			return err
+		}
+		for i := range details {
+			jsonResults := listTransactions(tx, &details[i],
+				w.Manager, syncBlock.Height, w.chainParams)
+			txList = append(txList, jsonResults...)
+		}
+		return nil
+	})
+	return txList, err
+}
+
 // ListAddressTransactions returns a slice of objects with details about
 // recorded transactions to or from any address belonging to a set.  This is
 // intended to be used for listaddresstransactions RPC replies.
@@ -2259,17 +2995,13 @@ func (w *Wallet) Accounts(scope waddrmgr.KeyScope) (*AccountsResult, error) {
 			m[a.AccountNumber] = &a.TotalBalance
 		}
 		for i := range unspent {
-			output := unspent[i]
-			var outputAcct uint32
-			_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.PkScript, w.chainParams)
-			if err == nil && len(addrs) > 0 {
-				_, outputAcct, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
+			output := &unspent[i]
+			outputAcct, ok := w.creditAccount(addrmgrNs, output)
+			if !ok {
+				continue
 			}
-			if err == nil {
-				amt, ok := m[outputAcct]
-				if ok {
-					*amt += output.Amount
-				}
+			if amt, ok := m[outputAcct]; ok {
+				*amt += output.Amount
 			}
 		}
 		return nil
@@ -2602,10 +3334,139 @@ func (w *Wallet) DumpWIFPrivateKey(addr btcutil.Address) (string, error) {
 }
 
 // ImportPrivateKey imports a private key to the wallet and writes the new
-// wallet to disk.
+// wallet to disk.  If rescan is true, the chain is rescanned for transactions
+// of the imported address starting at bs, such as the block stamp of a height
+// returned by BlockStampAtHeight, or the genesis block if bs is nil.
 func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *btcutil.WIF,
 	bs *waddrmgr.BlockStamp, rescan bool) (string, error) {
 
+	return w.importKey(scope, bs, rescan, func(manager *waddrmgr.ScopedKeyManager,
+		ns walletdb.ReadWriteBucket,
+		bs *waddrmgr.BlockStamp) (waddrmgr.ManagedAddress, error) {
+
+		return manager.ImportPrivateKey(ns, wif, bs)
+	})
+}
+
+// ImportPrivateKeyWithBirthday imports a private key which was created at
+// birthday, and rescans the chain for transactions of the imported address
+// only, starting at the first block which may contain them rather than the
+// genesis block.
+func (w *Wallet) ImportPrivateKeyWithBirthday(scope waddrmgr.KeyScope,
+	wif *btcutil.WIF, birthday time.Time) (string, error) {
+
+	bs, err := w.BlockStampAtTime(birthday)
+	if err != nil {
+		return "", err
+	}
+	return w.ImportPrivateKey(scope, wif, bs, true)
+}
+
+// importBirthdayMargin is subtracted from the birthday of an imported key when
+// locating the first block which may contain its transactions, to deal with
+// potentially inaccurate header timestamps.
+const importBirthdayMargin = 48 * time.Hour
+
+// BlockStampAtHeight returns the block stamp of the main chain block at
+// height.
+func (w *Wallet) BlockStampAtHeight(height int32) (*waddrmgr.BlockStamp, error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := chainClient.GetBlockHash(int64(height))
+	if err != nil {
+		return nil, err
+	}
+	header, err := chainClient.GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &waddrmgr.BlockStamp{
+		Height:    height,
+		Hash:      *hash,
+		Timestamp: header.Timestamp,
+	}, nil
+}
+
+// BlockStampAtTime returns the block stamp of the earliest main chain block
+// which may contain transactions created after t, allowing for inaccurate
+// header timestamps.  Since header timestamps are not strictly increasing,
+// the block is found with a binary search over the timestamps.  If t is after
+// the timestamp of the best block, the best block is returned.
+func (w *Wallet) BlockStampAtTime(t time.Time) (*waddrmgr.BlockStamp, error) {
+	return w.firstBlockStampAfter(t.Add(-importBirthdayMargin))
+}
+
+// firstBlockStampAfter returns the block stamp of the earliest main chain
+// block with a timestamp not before t, or the best block if there is none.
+// Unlike BlockStampAtTime, no margin is subtracted from t, so it is used for
+// the wallet birthday, which is already stored with a margin.
+func (w *Wallet) firstBlockStampAfter(t time.Time) (*waddrmgr.BlockStamp, error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+	_, bestHeight, err := chainClient.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	low, high := int32(0), bestHeight
+	for low < high {
+		mid := low + (high-low)/2
+		bs, err := w.BlockStampAtHeight(mid)
+		if err != nil {
+			return nil, err
+		}
+		if bs.Timestamp.Before(t) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return w.BlockStampAtHeight(low)
+}
+
+// ImportPublicKey imports a public key to the wallet as a watched address of
+// type addrType and writes the new wallet to disk.  P2PKH, nested P2WPKH and
+// P2WPKH addresses are imported into the BIP0044, BIP0049 and BIP0084 key
+// scopes.  Outputs paying to the address are detected, and inputs spending
+// them can be added to PSBTs to be signed elsewhere, but the wallet can not
+// sign for them.  Taproot addresses are not supported.
+func (w *Wallet) ImportPublicKey(pubKey *btcec.PublicKey,
+	addrType waddrmgr.AddressType, bs *waddrmgr.BlockStamp,
+	rescan bool) (string, error) {
+
+	var scope waddrmgr.KeyScope
+	switch addrType {
+	case waddrmgr.PubKeyHash:
+		scope = waddrmgr.KeyScopeBIP0044
+	case waddrmgr.NestedWitnessPubKey:
+		scope = waddrmgr.KeyScopeBIP0049Plus
+	case waddrmgr.WitnessPubKey:
+		scope = waddrmgr.KeyScopeBIP0084
+	default:
+		return "", fmt.Errorf("unsupported address type %v", addrType)
+	}
+
+	return w.importKey(scope, bs, rescan, func(manager *waddrmgr.ScopedKeyManager,
+		ns walletdb.ReadWriteBucket,
+		bs *waddrmgr.BlockStamp) (waddrmgr.ManagedAddress, error) {
+
+		return manager.ImportPublicKey(ns, pubKey, bs)
+	})
+}
+
+// importKey imports a key into the imported account of the scope using
+// importFn, and rescans for or subscribes to notifications of its address.
+// importFn is passed the starting block of the key, which defaults to the
+// genesis block when bs is nil.  The payment address of the key is returned.
+func (w *Wallet) importKey(scope waddrmgr.KeyScope, bs *waddrmgr.BlockStamp,
+	rescan bool, importFn func(*waddrmgr.ScopedKeyManager,
+		walletdb.ReadWriteBucket, *waddrmgr.BlockStamp) (waddrmgr.ManagedAddress,
+		error)) (string, error) {
+
 	manager, err := w.Manager.FetchScopedKeyManager(scope)
 	if err != nil {
 		return "", err
@@ -2628,12 +3489,12 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *btcutil.WIF,
 		}
 	}
 
-	// Attempt to import private key into wallet.
+	// Attempt to import the key into wallet.
 	var addr btcutil.Address
 	var props *waddrmgr.AccountProperties
 	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
 		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
-		maddr, err := manager.ImportPrivateKey(addrmgrNs, wif, bs)
+		maddr, err := importFn(manager, addrmgrNs, bs)
 		if err != nil {
 			return err
 		}
@@ -2644,6 +3505,13 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *btcutil.WIF,
 		if err != nil {
 			return err
 		}
+
+		// The wallet birthday is only moved back, as an imported key
+		// created after the wallet does not make earlier blocks of the
+		// wallet irrelevant.
+		if !newBirthday.Before(w.Manager.Birthday()) {
+			return nil
+		}
 		return w.Manager.SetBirthday(addrmgrNs, newBirthday)
 	})
 	if err != nil {
@@ -2677,10 +3545,33 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *btcutil.WIF,
 
 	w.NtfnServer.notifyAccountProperties(props)
 
-	// Return the payment address string of the imported private key.
+	// Return the payment address string of the imported key.
 	return addrStr, nil
 }
 
+// LabelTransaction sets the label of a wallet transaction, replacing any
+// existing label.  An empty label removes the label of the transaction.  The
+// label is returned as the comment of the transaction's listtransactions
+// results.
+func (w *Wallet) LabelTransaction(hash chainhash.Hash, label string) error {
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		txmgrNs := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.LabelTransaction(txmgrNs, hash, label)
+	})
+}
+
+// TxLabel returns the label of a wallet transaction, or an empty string if the
+// transaction is not labeled.
+func (w *Wallet) TxLabel(hash chainhash.Hash) (string, error) {
+	var label string
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		label = w.TxStore.FetchTxLabel(txmgrNs, hash)
+		return nil
+	})
+	return label, err
+}
+
 // LockedOutpoint returns whether an outpoint has been marked as locked and
 // should not be used as an input for created transactions.
 func (w *Wallet) LockedOutpoint(op wire.OutPoint) bool {
@@ -2722,6 +3613,46 @@ func (w *Wallet) LockedOutpoints() []btcjson.TransactionInput {
 	return locked
 }
 
+// LeaseOutput leases an unspent output for duration, preventing it from being
+// used as an input for created transactions until the lease expires.  Unlike
+// LockOutpoint, leases are persisted to the wallet database and survive
+// restarts.  Leasing an output already leased under the same lock ID extends
+// the lease.  The expiration time of the lease is returned.
+func (w *Wallet) LeaseOutput(id wtxmgr.LockID, op wire.OutPoint,
+	duration time.Duration) (time.Time, error) {
+
+	var expiry time.Time
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+		var err error
+		expiry, err = w.TxStore.LockOutput(ns, id, op, duration)
+		return err
+	})
+	return expiry, err
+}
+
+// ReleaseOutput releases the lease of an output previously leased with
+// LeaseOutput under the same lock ID, making it available to created
+// transactions again.
+func (w *Wallet) ReleaseOutput(id wtxmgr.LockID, op wire.OutPoint) error {
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.UnlockOutput(ns, id, op)
+	})
+}
+
+// LeasedOutputs returns all outputs with an unexpired lease.
+func (w *Wallet) LeasedOutputs() ([]wtxmgr.LockedOutput, error) {
+	var leases []wtxmgr.LockedOutput
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(wtxmgrNamespaceKey)
+		var err error
+		leases, err = w.TxStore.LockedOutputs(ns)
+		return err
+	})
+	return leases, err
+}
+
 // resendUnminedTxs iterates through all transactions that spend from wallet
 // credits that are not known to have been mined into a block, and attempts
 // to send each to the chain server for relay.
@@ -2735,9 +3666,26 @@ func (w *Wallet) resendUnminedTxs() {
 	var txs []*wire.MsgTx
 	err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
 		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
-		var err error
-		txs, err = w.TxStore.UnminedTxs(txmgrNs)
-		return err
+		unmined, err := w.TxStore.UnminedTxs(txmgrNs)
+		if err != nil {
+			return err
+		}
+
+		// Transactions replaced by a fee bump are no longer relayed,
+		// so only their replacements are resent.
+		for _, tx := range unmined {
+			txHash := tx.TxHash()
+			replacement, err := w.TxStore.Replacement(
+				txmgrNs, &txHash,
+			)
+			if err != nil {
+				return err
+			}
+			if replacement == nil {
+				txs = append(txs, tx)
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		log.Errorf("Cannot load unmined transactions for resending: %v", err)
@@ -2754,23 +3702,19 @@ func (w *Wallet) resendUnminedTxs() {
 			// detect that the output has already been fully spent,
 			// is an orphan, or is conflicting with another
 			// transaction.
-			//
-			// TODO(roasbeef): SendRawTransaction needs to return
-			// concrete error types, no need for string matching
 			switch {
+			case chain.IsPublishError(err, chain.ErrDoubleSpend):
+			case chain.IsPublishError(err, chain.ErrMissingInputs):
+			case chain.IsPublishError(err, chain.ErrMempoolMinFee):
+
 			// The following are errors returned from btcd's
-			// mempool.
-			case strings.Contains(err.Error(), "spent"):
-			case strings.Contains(err.Error(), "orphan"):
-			case strings.Contains(err.Error(), "conflict"):
+			// mempool which have no rejection error.
 			case strings.Contains(err.Error(), "already exists"):
 			case strings.Contains(err.Error(), "negative"):
 
 			// The following errors are returned from bitcoind's
-			// mempool.
-			case strings.Contains(err.Error(), "Missing inputs"):
+			// mempool which have no rejection error.
 			case strings.Contains(err.Error(), "already in block chain"):
-			case strings.Contains(err.Error(), "fee not met"):
 
 			default:
 				continue
@@ -2826,9 +3770,33 @@ func (w *Wallet) SortedActivePaymentAddresses() ([]string, error) {
 	return addrStrs, nil
 }
 
+// NewAddressOption is a functional option modifying the address returned by
+// NewAddress.
+type NewAddressOption func(*newAddressOptions)
+
+// newAddressOptions holds the settings modified by NewAddressOptions.
+type newAddressOptions struct {
+	label string
+}
+
+// WithAddressLabel sets the label of the new address.
+func WithAddressLabel(label string) NewAddressOption {
+	return func(o *newAddressOptions) {
+		o.label = label
+	}
+}
+
 // NewAddress returns the next external chained address for a wallet.
-func (w *Wallet) NewAddress(account uint32,
-	scope waddrmgr.KeyScope) (btcutil.Address, error) {
+func (w *Wallet) NewAddress(account uint32, scope waddrmgr.KeyScope,
+	opts ...NewAddressOption) (btcutil.Address, error) {
+
+	o := &newAddressOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := waddrmgr.ValidateAddressLabel(o.label); err != nil {
+		return nil, err
+	}
 
 	chainClient, err := w.requireChainClient()
 	if err != nil {
@@ -2843,7 +3811,10 @@ func (w *Wallet) NewAddress(account uint32,
 		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
 		var err error
 		addr, props, err = w.newAddress(addrmgrNs, account, scope)
-		return err
+		if err != nil || o.label == "" {
+			return err
+		}
+		return w.Manager.SetAddressLabel(addrmgrNs, addr, o.label)
 	})
 	if err != nil {
 		return nil, err
@@ -2884,6 +3855,104 @@ func (w *Wallet) newAddress(addrmgrNs walletdb.ReadWriteBucket, account uint32,
 	return addrs[0].Address(), props, nil
 }
 
+// SetAddressLabel sets the label of a wallet address, replacing any existing
+// label.  An empty label removes the label of the address.
+func (w *Wallet) SetAddressLabel(addr btcutil.Address, label string) error {
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return w.Manager.SetAddressLabel(addrmgrNs, addr, label)
+	})
+}
+
+// AddressLabel returns the label of a wallet address, or an empty string if
+// the address has no label.
+func (w *Wallet) AddressLabel(addr btcutil.Address) (string, error) {
+	var label string
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		var err error
+		label, err = w.Manager.AddressLabel(addrmgrNs, addr)
+		return err
+	})
+	return label, err
+}
+
+// AddressLabels returns the labels of every labeled wallet address, keyed by
+// the encoded address.
+func (w *Wallet) AddressLabels() (map[string]string, error) {
+	labels := make(map[string]string)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		for _, scopedMgr := range w.Manager.ActiveScopedKeyManagers() {
+			err := scopedMgr.ForEachAddressLabel(addrmgrNs,
+				func(addr btcutil.Address, label string) error {
+					labels[addr.EncodeAddress()] = label
+					return nil
+				})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return labels, err
+}
+
+// DeriveFromKeyPath derives the key at the path
+// m/purpose'/coin'/account'/branch/index of the key scope, where account is
+// an existing account of the scope.  The returned address describes the
+// public key and derivation of the key.  If persist is true, the address is
+// also stored and watched by the wallet, otherwise the derivation does not
+// modify the wallet.
+func (w *Wallet) DeriveFromKeyPath(scope waddrmgr.KeyScope,
+	path waddrmgr.DerivationPath,
+	persist bool) (waddrmgr.ManagedPubKeyAddress, error) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var maddr waddrmgr.ManagedAddress
+	if !persist {
+		err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+			addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+			var err error
+			maddr, err = manager.DeriveFromKeyPath(addrmgrNs, path)
+			return err
+		})
+	} else {
+		var chainClient chain.Interface
+		chainClient, err = w.requireChainClient()
+		if err != nil {
+			return nil, err
+		}
+		err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+			addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+			var err error
+			maddr, err = manager.DeriveAndStoreFromKeyPath(
+				addrmgrNs, path,
+			)
+			return err
+		})
+		if err == nil {
+			err = chainClient.NotifyReceived(
+				[]btcutil.Address{maddr.Address()},
+			)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addr, ok := maddr.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, fmt.Errorf("address %v is not a key address",
+			maddr.Address())
+	}
+	return addr, nil
+}
+
 // NewChangeAddress returns a new change address for a wallet.
 func (w *Wallet) NewChangeAddress(account uint32,
 	scope waddrmgr.KeyScope) (btcutil.Address, error) {
@@ -2921,7 +3990,15 @@ func (w *Wallet) newChangeAddress(addrmgrNs walletdb.ReadWriteBucket,
 	scopes := w.Manager.ScopesForExternalAddrType(
 		waddrmgr.WitnessPubKey,
 	)
-	manager, err := w.Manager.FetchScopedKeyManager(scopes[0])
+	return w.newScopedChangeAddress(addrmgrNs, scopes[0], account)
+}
+
+// newScopedChangeAddress derives the next change address of account from the
+// key manager of the passed scope.
+func (w *Wallet) newScopedChangeAddress(addrmgrNs walletdb.ReadWriteBucket,
+	scope waddrmgr.KeyScope, account uint32) (btcutil.Address, error) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
 	if err != nil {
 		return nil, err
 	}
@@ -3031,9 +4108,10 @@ func (w *Wallet) TotalReceivedForAccounts(scope waddrmgr.KeyScope,
 	return results, err
 }
 
-// TotalReceivedForAddr iterates through a wallet's transaction history,
-// returning the total amount of bitcoins received for a single wallet
-// address.
+// TotalReceivedForAddr returns the total amount of bitcoins received for a
+// single wallet address.  The credits of the address are read from the index
+// of credits by address, and the transaction history is only iterated through
+// for stores which are not indexed.
 func (w *Wallet) TotalReceivedForAddr(addr btcutil.Address, minConf int32) (btcutil.Amount, error) {
 	var amount btcutil.Amount
 	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
@@ -3041,6 +4119,21 @@ func (w *Wallet) TotalReceivedForAddr(addr btcutil.Address, minConf int32) (btcu
 
 		syncBlock := w.Manager.SyncedTo()
 
+		if w.TxStore.AddressesIndexed(txmgrNs) {
+			credits, err := w.TxStore.AddressCredits(txmgrNs, addr)
+			if err != nil {
+				return err
+			}
+			for _, cred := range credits {
+				if confirmed(minConf, cred.Height,
+					syncBlock.Height) {
+
+					amount += cred.Amount
+				}
+			}
+			return nil
+		}
+
 		var (
 			addrStr    = addr.EncodeAddress()
 			stopHeight int32
@@ -3078,16 +4171,133 @@ func (w *Wallet) TotalReceivedForAddr(addr btcutil.Address, minConf int32) (btcu
 	return amount, err
 }
 
+// AddressReceived describes the credits received by an address.
+type AddressReceived struct {
+	Address string
+
+	// Amount is the total amount received by the address.
+	Amount btcutil.Amount
+
+	// Confirmations is the number of confirmations of the most recent
+	// transaction paying to the address.
+	Confirmations int32
+
+	// TxHashes are the hashes of the transactions paying to the address,
+	// once for each credit, ordered by block height.
+	TxHashes []chainhash.Hash
+}
+
+// ReceivedByAddresses returns the amounts received by every address with a
+// credit of at least minConf confirmations.  Addresses are returned in no
+// particular order.  This is intended to be used for listreceivedbyaddress
+// RPC replies.
+func (w *Wallet) ReceivedByAddresses(minConf int32) ([]AddressReceived, error) {
+	type addrCredit struct {
+		height int32
+		hash   chainhash.Hash
+	}
+	var (
+		received = make(map[string]*AddressReceived)
+		credits  = make(map[string][]addrCredit)
+	)
+	add := func(addr string, height int32, hash *chainhash.Hash,
+		amount btcutil.Amount) {
+
+		r, ok := received[addr]
+		if !ok {
+			r = &AddressReceived{Address: addr}
+			received[addr] = r
+		}
+		r.Amount += amount
+		credits[addr] = append(credits[addr], addrCredit{height, *hash})
+	}
+
+	syncBlock := w.Manager.SyncedTo()
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+
+		if w.TxStore.AddressesIndexed(txmgrNs) {
+			return w.TxStore.ForEachAddressCredit(txmgrNs,
+				func(addr string, cred *wtxmgr.AddressCredit) error {
+					if confirmed(minConf, cred.Height,
+						syncBlock.Height) {
+
+						add(addr, cred.Height,
+							&cred.OutPoint.Hash, cred.Amount)
+					}
+					return nil
+				})
+		}
+
+		stopHeight := int32(-1)
+		if minConf > 0 {
+			stopHeight = syncBlock.Height - minConf + 1
+		}
+		rangeFn := func(details []wtxmgr.TxDetails) (bool, error) {
+			for i := range details {
+				detail := &details[i]
+				for _, cred := range detail.Credits {
+					pkScript := detail.MsgTx.TxOut[cred.Index].PkScript
+					_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+						pkScript, w.chainParams)
+					// Non-standard scripts pay to no address.
+					if err != nil {
+						continue
+					}
+					for _, addr := range addrs {
+						add(addr.EncodeAddress(),
+							detail.Block.Height,
+							&detail.Hash, cred.Amount)
+					}
+				}
+			}
+			return false, nil
+		}
+		return w.TxStore.RangeTransactions(txmgrNs, 0, stopHeight, rangeFn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]AddressReceived, 0, len(received))
+	for addr, r := range received {
+		// Unmined credits are ordered after mined credits.
+		creds := credits[addr]
+		sort.SliceStable(creds, func(i, j int) bool {
+			return uint32(creds[i].height) < uint32(creds[j].height)
+		})
+		for _, cred := range creds {
+			r.TxHashes = append(r.TxHashes, cred.hash)
+		}
+		r.Confirmations = confirms(creds[len(creds)-1].height,
+			syncBlock.Height)
+		results = append(results, *r)
+	}
+	return results, nil
+}
+
 // SendOutputs creates and sends payment transactions. It returns the
-// transaction hash upon success.
+// transaction hash upon success. When satPerKb is zero and no confirmation
+// target is set, the fee rate is estimated by the chain backend.
 func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
-	minconf int32, satPerKb btcutil.Amount) (*chainhash.Hash, error) {
+	minconf int32, satPerKb btcutil.Amount, strategy CoinSelectionStrategy,
+	opts ...TxCreateOption) (*chainhash.Hash, error) {
+
+	o := applyTxCreateOptions(opts)
+	satPerKb, err := w.txFeeRate(satPerKb, o)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ensure the outputs to be created adhere to the network's consensus
-	// rules.
-	for _, output := range outputs {
-		if err := txrules.CheckOutput(output, satPerKb); err != nil {
-			return nil, err
+	// rules.  The value of a send-all output is only known once the
+	// transaction is created, which checks it instead.
+	if !o.sendAll {
+		for _, output := range outputs {
+			err := txrules.CheckOutput(output, satPerKb)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -3095,7 +4305,9 @@ func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
 	// transaction will be added to the database in order to ensure that we
 	// continue to re-broadcast the transaction upon restarts until it has
 	// been confirmed.
-	createdTx, err := w.CreateSimpleTx(account, outputs, minconf, satPerKb)
+	createdTx, err := w.createSimpleTx(
+		account, outputs, minconf, satPerKb, strategy, o,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -3261,39 +4473,78 @@ func (w *Wallet) publishTransaction(tx *wire.MsgTx) (*chainhash.Hash, error) {
 		return nil, err
 	}
 
+	// Transactions which would be rejected are not recorded, and the
+	// reason is returned rather than the error of the broadcast.
+	if err := w.checkMempoolAccept(server, tx); err != nil {
+		atomic.AddUint64(&w.txsRejected, 1)
+		return nil, err
+	}
+
 	// As we aim for this to be general reliable transaction broadcast API,
 	// we'll write this tx to disk as an unconfirmed transaction. This way,
 	// upon restarts, we'll always rebroadcast it, and also add it to our
-	// set of records.
+	// set of records.  Transactions published concurrently are inserted,
+	// and the addresses they pay to marked used, with a batched write, so
+	// their callers share a single commit.  Batch may run the update more
+	// than once, so clients are only notified of the transaction once it
+	// was committed and broadcast.
 	txRec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
 	if err != nil {
 		return nil, err
 	}
-	err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
-		return w.addRelevantTx(dbTx, txRec, nil)
+	err = w.db.Batch(func(dbTx walletdb.ReadWriteTx) error {
+		return w.insertRelevantTx(dbTx, txRec, nil)
 	})
 	if err != nil {
 		return nil, err
 	}
+	notify := func() {
+		err := walletdb.View(w.db, func(dbTx walletdb.ReadTx) error {
+			w.notifyRelevantTx(dbTx, txRec, nil)
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Cannot notify published transaction %v: %v",
+				txRec.Hash, err)
+		}
+	}
 
+	// Rejections are returned as the rejection errors of the chain
+	// package, such as chain.ErrDoubleSpend, so that callers can decide
+	// whether to replace the transaction.  A transaction already in the
+	// mempool is returned as published by the backend.
 	txid, err := server.SendRawTransaction(tx, false)
+	if err == nil {
+		atomic.AddUint64(&w.txsPublished, 1)
+	} else {
+		atomic.AddUint64(&w.txsRejected, 1)
+	}
 	switch {
 	case err == nil:
+		// The backend relayed the transaction, so the unmined
+		// transactions it double spends are conflicted.
+		err := walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+			txmgrNs := dbTx.ReadWriteBucket(wtxmgrNamespaceKey)
+			_, err := w.TxStore.InsertConflicts(txmgrNs, txRec)
+			return err
+		})
+		if err != nil {
+			log.Errorf("Cannot record transactions double spent by "+
+				"%v: %v", txid, err)
+		}
+		notify()
 		return txid, nil
 
-	// The following are errors returned from btcd's mempool.
-	case strings.Contains(err.Error(), "spent"):
+	case chain.IsPublishError(err, chain.ErrDoubleSpend):
 		fallthrough
-	case strings.Contains(err.Error(), "orphan"):
+	case chain.IsPublishError(err, chain.ErrMissingInputs):
 		fallthrough
-	case strings.Contains(err.Error(), "conflict"):
+	case chain.IsPublishError(err, chain.ErrMempoolMinFee):
 		fallthrough
-
-	// The following errors are returned from bitcoind's mempool.
-	case strings.Contains(err.Error(), "fee not met"):
-		fallthrough
-	case strings.Contains(err.Error(), "Missing inputs"):
+	case chain.IsPublishError(err, chain.ErrNonStandard):
 		fallthrough
+
+	// The following error is returned from bitcoind's mempool.
 	case strings.Contains(err.Error(), "already in block chain"):
 		// If the transaction was rejected, then we'll remove it from
 		// the txstore, as otherwise, we'll attempt to continually
@@ -3310,11 +4561,21 @@ func (w *Wallet) publishTransaction(tx *wire.MsgTx) (*chainhash.Hash, error) {
 
 		return nil, err
 
+	// Transactions which were not rejected remain recorded to be
+	// rebroadcast, so clients are notified of them.
 	default:
+		notify()
 		return nil, err
 	}
 }
 
+// PublishStats returns the number of transactions the wallet published to the
+// chain backend since it was opened, and the number of transactions which were
+// rejected by the backend or failed to be sent.
+func (w *Wallet) PublishStats() (published, rejected uint64) {
+	return atomic.LoadUint64(&w.txsPublished), atomic.LoadUint64(&w.txsRejected)
+}
+
 // ChainParams returns the network parameters for the blockchain the wallet
 // belongs to.
 func (w *Wallet) ChainParams() *chaincfg.Params {
@@ -3328,11 +4589,108 @@ func (w *Wallet) Database() walletdb.DB {
 	return w.db
 }
 
+// DatabaseEncrypted returns whether the wallet database is encrypted as a
+// whole, as created with WithDatabaseEncryption.  Copies of an encrypted
+// database remain encrypted, while its keys and values are read decrypted.
+func (w *Wallet) DatabaseEncrypted() bool {
+	_, ok := walletdb.Unwrap(w.db).(*encrypted.DB)
+	return ok
+}
+
+// CreateOption is a functional option that modifies how a wallet is created
+// by Create and CreateWatchingOnly.
+type CreateOption func(*createOptions)
+
+// createOptions holds the wallet creation settings modified by CreateOptions.
+type createOptions struct {
+	scryptOptions   *waddrmgr.ScryptOptions
+	encryptDatabase bool
+}
+
+// applyCreateOptions returns the creation settings of the options.
+func applyCreateOptions(opts []CreateOption) *createOptions {
+	o := &createOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithScryptOptions derives the keys encrypting the wallet from its
+// passphrases using the scrypt parameters opts instead of
+// waddrmgr.DefaultScryptOptions.  Lower parameters reduce the time and memory
+// needed to unlock the wallet on constrained devices, while higher parameters
+// make brute forcing the passphrases more expensive.  The parameters are
+// stored with the wallet and kept when its passphrases are changed.
+func WithScryptOptions(opts waddrmgr.ScryptOptions) CreateOption {
+	return func(o *createOptions) {
+		o.scryptOptions = &opts
+	}
+}
+
+// WithDatabaseEncryption encrypts the entire wallet database, including the
+// transaction history, addresses and all other metadata, with a key derived
+// from the public passphrase, as implemented by the walletdb/encrypted
+// package.  Open detects encrypted databases, so they are opened in the same
+// way as unencrypted ones.  The encryption only protects the database if the
+// public passphrase is not the default InsecurePubPassphrase.
+func WithDatabaseEncryption() CreateOption {
+	return func(o *createOptions) {
+		o.encryptDatabase = true
+	}
+}
+
+// createDatabase returns the database to create the wallet in, encrypting db
+// with pubPass if the database encryption option is set.
+func (o *createOptions) createDatabase(db walletdb.DB,
+	pubPass []byte) (walletdb.DB, error) {
+
+	if !o.encryptDatabase {
+		return db, nil
+	}
+	opts := &waddrmgr.DefaultScryptOptions
+	if o.scryptOptions != nil {
+		opts = o.scryptOptions
+	}
+	return encrypted.Create(db, pubPass, opts.N, opts.R, opts.P)
+}
+
+// openDatabase returns the database to open the wallet from, decrypting db
+// with pubPass if it was created with database encryption.
+func openDatabase(db walletdb.DB, pubPass []byte) (walletdb.DB, error) {
+	isEncrypted, err := encrypted.IsEncrypted(db)
+	if err != nil || !isEncrypted {
+		return db, err
+	}
+	encDB, err := encrypted.Open(db, pubPass)
+	if err != nil {
+		return nil, convertDatabaseError(err)
+	}
+	return encDB, nil
+}
+
+// convertDatabaseError returns an incorrect passphrase of an encrypted
+// database as the address manager error for incorrect passphrases, which
+// callers already handle.
+func convertDatabaseError(err error) error {
+	if err == encrypted.ErrWrongPassphrase {
+		str := "invalid passphrase for encrypted database"
+		return waddrmgr.ManagerError{
+			ErrorCode:   waddrmgr.ErrWrongPassphrase,
+			Description: str,
+			Err:         err,
+		}
+	}
+	return err
+}
+
 // Create creates an new wallet, writing it to an empty database.  If the passed
 // seed is non-nil, it is used.  Otherwise, a secure random seed of the
 // recommended length is generated.
 func Create(db walletdb.DB, pubPass, privPass, seed []byte, params *chaincfg.Params,
-	birthday time.Time) error {
+	birthday time.Time, opts ...CreateOption) error {
+
+	createOpts := applyCreateOptions(opts)
 
 	// If a seed was provided, ensure that it is of valid length. Otherwise,
 	// we generate a random seed for the wallet with the recommended seed
@@ -3350,6 +4708,11 @@ func Create(db walletdb.DB, pubPass, privPass, seed []byte, params *chaincfg.Par
 		return hdkeychain.ErrInvalidSeedLen
 	}
 
+	db, err := createOpts.createDatabase(db, pubPass)
+	if err != nil {
+		return err
+	}
+
 	return walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
 		addrmgrNs, err := tx.CreateTopLevelBucket(waddrmgrNamespaceKey)
 		if err != nil {
@@ -3359,10 +4722,22 @@ func Create(db walletdb.DB, pubPass, privPass, seed []byte, params *chaincfg.Par
 		if err != nil {
 			return err
 		}
+		_, err = tx.CreateTopLevelBucket(batchNamespaceKey)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateTopLevelBucket(multisigNamespaceKey)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateTopLevelBucket(rescanNamespaceKey)
+		if err != nil {
+			return err
+		}
 
 		err = waddrmgr.Create(
-			addrmgrNs, seed, pubPass, privPass, params, nil,
-			birthday,
+			addrmgrNs, seed, pubPass, privPass, params,
+			createOpts.scryptOptions, birthday,
 		)
 		if err != nil {
 			return err
@@ -3371,24 +4746,60 @@ func Create(db walletdb.DB, pubPass, privPass, seed []byte, params *chaincfg.Par
 	})
 }
 
-// Open loads an already-created wallet from the passed database and namespaces.
-func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
-	params *chaincfg.Params, recoveryWindow uint32) (*Wallet, error) {
+// CreateWatchingOnly creates a new watching-only wallet, writing it to an
+// empty database.  The wallet holds no private key material.  Its accounts
+// are created from the account extended public keys in accountPubKeys, each
+// of which becomes the default account of its key scope, as described by
+// waddrmgr.CreateWatchingOnly.  Transactions can be funded, for example with
+// FundPsbt, but signing returns an error with the code
+// waddrmgr.ErrWatchingOnly.
+func CreateWatchingOnly(db walletdb.DB, pubPass []byte,
+	accountPubKeys map[waddrmgr.KeyScope]*hdkeychain.ExtendedKey,
+	params *chaincfg.Params, birthday time.Time, opts ...CreateOption) error {
+
+	createOpts := applyCreateOptions(opts)
+	db, err := createOpts.createDatabase(db, pubPass)
+	if err != nil {
+		return err
+	}
 
-	err := walletdb.View(db, func(tx walletdb.ReadTx) error {
-		waddrmgrBucket := tx.ReadBucket(waddrmgrNamespaceKey)
-		if waddrmgrBucket == nil {
-			return errors.New("missing address manager namespace")
+	return walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs, err := tx.CreateTopLevelBucket(waddrmgrNamespaceKey)
+		if err != nil {
+			return err
 		}
-		wtxmgrBucket := tx.ReadBucket(wtxmgrNamespaceKey)
-		if wtxmgrBucket == nil {
-			return errors.New("missing transaction manager namespace")
+		txmgrNs, err := tx.CreateTopLevelBucket(wtxmgrNamespaceKey)
+		if err != nil {
+			return err
 		}
-		return nil
+		_, err = tx.CreateTopLevelBucket(batchNamespaceKey)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateTopLevelBucket(multisigNamespaceKey)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateTopLevelBucket(rescanNamespaceKey)
+		if err != nil {
+			return err
+		}
+
+		err = waddrmgr.CreateWatchingOnly(
+			addrmgrNs, accountPubKeys, pubPass, params,
+			createOpts.scryptOptions, birthday,
+		)
+		if err != nil {
+			return err
+		}
+		return wtxmgr.Create(txmgrNs)
 	})
-	if err != nil {
-		return nil, err
-	}
+}
+
+// upgradeDatabase performs the upgrades of the managers of the wallet database
+// and creates the namespaces missing from wallets created by older versions.
+func upgradeDatabase(db walletdb.DB, pubPass []byte, params *chaincfg.Params,
+	cbs *waddrmgr.OpenCallbacks) error {
 
 	// Perform upgrades as necessary.  Each upgrade is done under its own
 	// transaction, which is managed by each package itself, so the entire
@@ -3397,15 +4808,110 @@ func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
 	// This will need to change later when upgrades in one package depend on
 	// data in another (such as removing chain synchronization from address
 	// manager).
-	err = waddrmgr.DoUpgrades(db, waddrmgrNamespaceKey, pubPass, params, cbs)
+	err := waddrmgr.DoUpgrades(db, waddrmgrNamespaceKey, pubPass, params, cbs)
+	if err != nil {
+		return err
+	}
+
+	// Transaction stores upgraded to record the accounts of their credits
+	// look up the accounts with the address manager, which is only opened
+	// when a credit is looked up.
+	var (
+		addrMgr    *waddrmgr.Manager
+		addrMgrErr error
+	)
+	err = wtxmgr.DoAccountUpgrades(db, wtxmgrNamespaceKey,
+		func(tx walletdb.ReadTx, pkScript []byte) (uint32, bool) {
+			addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+			if addrMgr == nil && addrMgrErr == nil {
+				addrMgr, addrMgrErr = waddrmgr.Open(addrmgrNs,
+					pubPass, params)
+			}
+			if addrMgrErr != nil {
+				return 0, false
+			}
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				pkScript, params)
+			if err != nil || len(addrs) == 0 {
+				return 0, false
+			}
+			_, account, err := addrMgr.AddrAccount(addrmgrNs, addrs[0])
+			return account, err == nil
+		})
+	if addrMgr != nil {
+		addrMgr.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if addrMgrErr != nil {
+		return addrMgrErr
+	}
+
+	// Wallets created before payment batching, multisig accounts and
+	// rescan checkpoints were added lack their namespaces.
+	namespaces := [][]byte{
+		batchNamespaceKey, multisigNamespaceKey, rescanNamespaceKey,
+	}
+	return walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		for _, key := range namespaces {
+			if tx.ReadWriteBucket(key) != nil {
+				continue
+			}
+			if _, err := tx.CreateTopLevelBucket(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Open loads an already-created wallet from the passed database and namespaces.
+//
+// The optional keyScopes are registered in addition to the default key scopes
+// and map custom key scopes to the address schemas of their addresses.  A
+// registered scope the wallet already has must have been created with the same
+// address schema.  Registered scopes the wallet does not have yet are created
+// when the wallet is unlocked.
+//
+// Databases opened in read-only mode, with walletdb.OpenOptions, are not
+// upgraded, and every change to a wallet opened from them fails.  This allows
+// querying the balances and history of a wallet used by another process.
+func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
+	params *chaincfg.Params, recoveryWindow uint32,
+	keyScopes map[waddrmgr.KeyScope]waddrmgr.ScopeAddrSchema) (*Wallet, error) {
+
+	readOnly := walletdb.IsReadOnly(db)
+	db, err := openDatabase(db, pubPass)
 	if err != nil {
 		return nil, err
 	}
-	err = wtxmgr.DoUpgrades(db, wtxmgrNamespaceKey)
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		waddrmgrBucket := tx.ReadBucket(waddrmgrNamespaceKey)
+		if waddrmgrBucket == nil {
+			return errors.New("missing address manager namespace")
+		}
+		wtxmgrBucket := tx.ReadBucket(wtxmgrNamespaceKey)
+		if wtxmgrBucket == nil {
+			return errors.New("missing transaction manager namespace")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Read-only databases are opened as they are.  Their transaction
+	// stores are read without being upgraded, while opening their address
+	// managers fails if they need an upgrade.
+	if !readOnly {
+		err := upgradeDatabase(db, pubPass, params, cbs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Open database abstraction instances
 	var (
 		addrMgr *waddrmgr.Manager
@@ -3419,39 +4925,84 @@ func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
 		if err != nil {
 			return err
 		}
-		txMgr, err = wtxmgr.Open(txmgrNs, params)
+		if readOnly {
+			txMgr, err = wtxmgr.OpenReadOnly(txmgrNs, params)
+		} else {
+			txMgr, err = wtxmgr.Open(txmgrNs, params)
+		}
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Index the received credits of stores created before credits were
+	// indexed by address.  Read-only databases keep iterating over the
+	// transaction history instead.
+	if !readOnly {
+		err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+			txmgrNs := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+			if txMgr.AddressesIndexed(txmgrNs) {
+				return nil
+			}
+			log.Infof("Indexing received outputs by address")
+			return txMgr.IndexAddressCredits(txmgrNs)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for scope, addrSchema := range keyScopes {
+		scopedMgr, err := addrMgr.FetchScopedKeyManager(scope)
+		switch {
+		case waddrmgr.IsError(err, waddrmgr.ErrScopeNotFound):
+			if addrMgr.WatchOnly() {
+				return nil, fmt.Errorf("key scope %v can not be "+
+					"created by a watching-only wallet", &scope)
+			}
+		case err != nil:
+			return nil, err
+		case scopedMgr.AddrSchema() != addrSchema:
+			return nil, fmt.Errorf("key scope %v was created with a "+
+				"different address schema", &scope)
+		}
+	}
+
 	log.Infof("Opened wallet") // TODO: log balance? last sync height?
 	w := &Wallet{
-		publicPassphrase:    pubPass,
-		db:                  db,
-		Manager:             addrMgr,
-		TxStore:             txMgr,
-		lockedOutpoints:     map[wire.OutPoint]struct{}{},
-		recoveryWindow:      recoveryWindow,
-		rescanAddJob:        make(chan *RescanJob),
-		rescanBatch:         make(chan *rescanBatch),
-		rescanNotifications: make(chan interface{}),
-		rescanProgress:      make(chan *RescanProgressMsg),
-		rescanFinished:      make(chan *RescanFinishedMsg),
-		createTxRequests:    make(chan createTxRequest),
-		unlockRequests:      make(chan unlockRequest),
-		lockRequests:        make(chan struct{}),
-		holdUnlockRequests:  make(chan chan heldUnlock),
-		lockState:           make(chan bool),
-		changePassphrase:    make(chan changePassphraseRequest),
-		changePassphrases:   make(chan changePassphrasesRequest),
-		chainParams:         params,
-		quit:                make(chan struct{}),
+		publicPassphrase:     pubPass,
+		db:                   db,
+		Manager:              addrMgr,
+		TxStore:              txMgr,
+		lockedOutpoints:      map[wire.OutPoint]struct{}{},
+		defaultStrategy:      CoinSelectionLargest,
+		recoveryWindow:       recoveryWindow,
+		keyScopes:            keyScopes,
+		rescanAddJob:         make(chan *RescanJob),
+		rescanBatch:          make(chan *rescanBatch),
+		rescanNotifications:  make(chan interface{}),
+		rescanProgress:       make(chan *RescanProgressMsg),
+		rescanFinished:       make(chan *RescanFinishedMsg),
+		rescanCancel:         make(chan chan error),
+		syncStateChanged:     make(chan struct{}, 1),
+		createTxRequests:     make(chan createTxRequest),
+		unlockRequests:       make(chan unlockRequest),
+		scopedUnlockRequests: make(chan scopedUnlockRequest),
+		lockRequests:         make(chan struct{}),
+		holdUnlockRequests:   make(chan chan heldUnlock),
+		lockState:            make(chan bool),
+		changePassphrase:     make(chan changePassphraseRequest),
+		changePassphrases:    make(chan changePassphrasesRequest),
+		chainParams:          params,
+		quit:                 make(chan struct{}),
 	}
 	w.NtfnServer = newNotificationServer(w)
 	w.TxStore.NotifyUnspent = func(hash *chainhash.Hash, index uint32) {
 		w.NtfnServer.notifyUnspentOutput(0, hash, index)
 	}
+	w.TxStore.NotifyConflict = func(hash, conflictingHash *chainhash.Hash) {
+		w.NtfnServer.notifyConflict(hash, conflictingHash)
+	}
 	return w, nil
 }
@@ -0,0 +1,140 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// slowBestBlockClient is a mockChainClient whose best block is only returned
+// once released.
+type slowBestBlockClient struct {
+	*mockChainClient
+	release chan struct{}
+}
+
+func (c *slowBestBlockClient) GetBestBlock() (*chainhash.Hash, int32, error) {
+	<-c.release
+	return c.mockChainClient.GetBestBlock()
+}
+
+// TestSetChainSyncedNotification ensures that SetChainSynced neither waits for
+// the chain server nor for clients of sync state notifications, which are
+// notified of each change of the sync state.
+func TestSetChainSyncedNotification(t *testing.T) {
+	w, mock, cleanup := testWallet(t)
+	defer cleanup()
+	mock.bestHeight = 30
+	chainClient := &slowBestBlockClient{
+		mockChainClient: mock,
+		release:         make(chan struct{}),
+	}
+	w.chainClient = chainClient
+	w.Start()
+
+	client := w.NtfnServer.SyncStateNotifications()
+	defer client.Done()
+
+	done := make(chan struct{})
+	go func() {
+		w.SetChainSynced(true)
+		w.SetChainSynced(true)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetChainSynced waited for the sync state notification")
+	}
+	close(chainClient.release)
+
+	next := func() *SyncStateNotification {
+		select {
+		case n := <-client.C:
+			return n
+		case <-time.After(5 * time.Second):
+			t.Fatal("no sync state notification")
+			return nil
+		}
+	}
+	if n := next(); !n.Synced || n.BackendHeight != 30 {
+		t.Fatalf("unexpected sync state %+v", n)
+	}
+
+	w.SetChainSynced(false)
+	if n := next(); n.Synced {
+		t.Fatalf("unexpected sync state %+v", n)
+	}
+
+	// Setting an unchanged state is not notified.
+	w.SetChainSynced(false)
+	select {
+	case n := <-client.C:
+		t.Fatalf("unexpected sync state %+v", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestListSinceBlockHashLastBlock ensures that the block returned to list
+// transactions since is found for heights below the blocks the wallet synced.
+func TestListSinceBlockHashLastBlock(t *testing.T) {
+	w, _, cleanup := testWallet(t)
+	defer cleanup()
+
+	// The wallet only records the hash of the block it was fast forwarded
+	// to, while a wallet transaction was mined below it.
+	mined := wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: chainhash.Hash{5}, Height: 5},
+		Time:  time.Now(),
+	}
+	tx := testTx(wire.OutPoint{Hash: chainhash.Hash{1}}, 1e6)
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.Update(w.db, func(dbTx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbTx.ReadWriteBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbTx.ReadWriteBucket(wtxmgrNamespaceKey)
+		err := w.Manager.FastForwardSyncedTo(addrmgrNs, &waddrmgr.BlockStamp{
+			Hash:   chainhash.Hash{20},
+			Height: 20,
+		})
+		if err != nil {
+			return err
+		}
+		return w.TxStore.InsertTx(txmgrNs, rec, &mined)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		targetConfs int32
+		exp         *chainhash.Hash
+	}{
+		{targetConfs: 1, exp: &mined.Hash},
+		{targetConfs: 10, exp: &mined.Hash},
+		{targetConfs: 16, exp: &mined.Hash},
+		{targetConfs: 17, exp: w.chainParams.GenesisHash},
+		{targetConfs: 100, exp: w.chainParams.GenesisHash},
+	}
+	for _, test := range tests {
+		_, lastBlock, err := w.ListSinceBlockHash(nil, test.targetConfs)
+		if err != nil {
+			t.Fatalf("%d confirmations: %v", test.targetConfs, err)
+		}
+		if *lastBlock != *test.exp {
+			t.Errorf("%d confirmations: expected last block %v, "+
+				"got %v", test.targetConfs, test.exp, lastBlock)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bdb
+
+import (
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcwallet/internal/legacy/rename"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/coreos/bbolt"
+)
+
+const (
+	// compactTimeout is how long compacting a database waits for the file
+	// lock held by a process which has the database open.
+	compactTimeout = time.Second
+
+	// compactTxMaxSize is the size of the keys and values copied to the
+	// compacted database by each of its transactions, which limits the
+	// memory used to compact large databases.
+	compactTxMaxSize = 1 << 20
+)
+
+// Compact rewrites the database at the provided path into a new file holding
+// only the pages in use and replaces the database with it.  Bolt never shrinks
+// its database files, so the files of wallets which delete and overwrite many
+// keys keep growing; compacting returns the freed pages to the file system.
+//
+// The database must not be open, not even by this process, while compacting
+// it.  walletdb.ErrDbDoesNotExist is returned if the database doesn't exist.
+func Compact(dbPath string) error {
+	if !fileExists(dbPath) {
+		return walletdb.ErrDbDoesNotExist
+	}
+
+	// Opening the database for writing takes its exclusive file lock,
+	// which is held until the compacted database replaced it.
+	src, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: compactTimeout,
+	})
+	if err != nil {
+		return convertErr(err)
+	}
+	defer src.Close()
+
+	tmpPath := dbPath + ".compact"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	dst, err := bolt.Open(tmpPath, 0600, nil)
+	if err != nil {
+		return convertErr(err)
+	}
+	err = compact(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return convertErr(err)
+	}
+
+	return rename.Atomic(tmpPath, dbPath)
+}
+
+// compact copies all buckets and key/value pairs of src to dst.
+func compact(dst, src *bolt.DB) error {
+	c := &compactor{db: dst}
+	err := src.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return c.copyBucket(nil, name, b)
+		})
+	})
+	if c.tx == nil {
+		return err
+	}
+	if err != nil {
+		c.tx.Rollback()
+		return err
+	}
+	return c.tx.Commit()
+}
+
+// compactor copies buckets and key/value pairs to the compacted database,
+// committing its transactions whenever they copied compactTxMaxSize bytes.
+type compactor struct {
+	db   *bolt.DB
+	tx   *bolt.Tx
+	size int
+}
+
+// begin adds size bytes to the size copied by the current transaction of the
+// compacted database, first committing it and beginning a new one if this
+// exceeds compactTxMaxSize.
+func (c *compactor) begin(size int) error {
+	if c.tx != nil && c.size+size <= compactTxMaxSize {
+		c.size += size
+		return nil
+	}
+
+	if c.tx != nil {
+		err := c.tx.Commit()
+		c.tx = nil
+		if err != nil {
+			return err
+		}
+	}
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	c.tx, c.size = tx, size
+	return nil
+}
+
+// bucket returns the bucket at path in the current transaction of the
+// compacted database, where path holds the keys of the top-level bucket and
+// each nested bucket.
+func (c *compactor) bucket(path [][]byte) *bolt.Bucket {
+	b := c.tx.Bucket(path[0])
+	for _, key := range path[1:] {
+		b = b.Bucket(key)
+	}
+	return b
+}
+
+// copyBucket copies the bucket src with the key name, nested in the bucket at
+// path, and all of its contents.
+func (c *compactor) copyBucket(path [][]byte, name []byte, src *bolt.Bucket) error {
+	if err := c.begin(len(name)); err != nil {
+		return err
+	}
+	var b *bolt.Bucket
+	var err error
+	if len(path) == 0 {
+		b, err = c.tx.CreateBucket(name)
+	} else {
+		b, err = c.bucket(path).CreateBucket(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := b.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	path = append(path[:len(path):len(path)], name)
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return c.copyBucket(path, k, src.Bucket(k))
+		}
+		if err := c.begin(len(k) + len(v)); err != nil {
+			return err
+		}
+		return c.bucket(path).Put(k, v)
+	})
+}
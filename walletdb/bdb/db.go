@@ -22,7 +22,7 @@ func convertErr(err error) error {
 		return walletdb.ErrInvalid
 
 	// Transaction errors.
-	case bolt.ErrTxNotWritable:
+	case bolt.ErrTxNotWritable, bolt.ErrDatabaseReadOnly:
 		return walletdb.ErrTxNotWritable
 	case bolt.ErrTxClosed:
 		return walletdb.ErrTxClosed
@@ -59,6 +59,12 @@ func (tx *transaction) ReadBucket(key []byte) walletdb.ReadBucket {
 	return tx.ReadWriteBucket(key)
 }
 
+func (tx *transaction) ForEachBucket(fn func(key []byte) error) error {
+	return convertErr(tx.boltTx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		return fn(name)
+	}))
+}
+
 func (tx *transaction) ReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
 	boltBucket := tx.boltTx.Bucket(key)
 	if boltBucket == nil {
@@ -293,6 +299,17 @@ func (db *db) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
 	return db.beginTx(true)
 }
 
+// Batch executes f in a read-write transaction shared with concurrent calls to
+// Batch using the batching of bolt, which commits the transaction once enough
+// functions were added to it or after a short delay.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Batch(f func(tx walletdb.ReadWriteTx) error) error {
+	return convertErr((*bolt.DB)(db).Batch(func(boltTx *bolt.Tx) error {
+		return f(&transaction{boltTx: boltTx})
+	}))
+}
+
 // Copy writes a copy of the database to the provided writer.  This call will
 // start a read-only transaction to perform all operations.
 //
@@ -320,13 +337,14 @@ func fileExists(name string) bool {
 	return true
 }
 
-// openDB opens the database at the provided path.  walletdb.ErrDbDoesNotExist
-// is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, create bool) (walletdb.DB, error) {
+// openDB opens the database at the provided path with the bolt options.
+// walletdb.ErrDbDoesNotExist is returned if the database doesn't exist and the
+// create flag is not set.
+func openDB(dbPath string, create bool, options *bolt.Options) (walletdb.DB, error) {
 	if !create && !fileExists(dbPath) {
 		return nil, walletdb.ErrDbDoesNotExist
 	}
 
-	boltDB, err := bolt.Open(dbPath, 0600, nil)
+	boltDB, err := bolt.Open(dbPath, 0600, options)
 	return (*db)(boltDB), convertErr(err)
 }
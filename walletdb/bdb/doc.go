@@ -21,5 +21,25 @@ database path as a string:
 	if err != nil {
 		// Handle error
 	}
+
+Read-only mode
+
+Opening a database with walletdb.OpenOptions{ReadOnly: true} takes a shared
+file lock, which several read-only processes may hold at once.  Bolt holds an
+exclusive file lock while a process has the database open for writing, so a
+database open for writing is instead copied to a temporary snapshot, which is
+opened in read-only mode and removed when it is closed.  Transactions of a
+snapshot do not see writes committed after it was taken.
+
+Compaction
+
+Bolt reuses the pages freed by deleted data, but never shrinks the database
+file.  The Compact function rewrites a database which is not open into a new
+file without the freed pages:
+
+	err := bdb.Compact("path/to/database.db")
+	if err != nil {
+		// Handle error
+	}
 */
 package bdb
@@ -6,12 +6,19 @@ package bdb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/coreos/bbolt"
 )
 
 const (
 	dbType = "bdb"
+
+	// readOnlyTimeout is how long opening a database in read-only mode
+	// waits for the shared file lock before opening a snapshot of the
+	// database instead, as another process has it open for writing.
+	readOnlyTimeout = time.Second
 )
 
 // parseArgs parses the arguments from the walletdb Open/Create methods.
@@ -38,7 +45,32 @@ func openDBDriver(args ...interface{}) (walletdb.DB, error) {
 		return nil, err
 	}
 
-	return openDB(dbPath, false)
+	return openDB(dbPath, false, nil)
+}
+
+// openReadOnlyDBDriver is the callback provided during driver registration that
+// opens an existing database in read-only mode.  The database is opened with a
+// shared file lock, or from a snapshot of it when another process has it open
+// for writing.  Transactions of a snapshot do not see later writes of that
+// process.
+func openReadOnlyDBDriver(args ...interface{}) (walletdb.DB, error) {
+	dbPath, err := parseArgs("Open", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(dbPath, false, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  readOnlyTimeout,
+	})
+	switch err {
+	case nil:
+		return db, nil
+	case bolt.ErrTimeout:
+		return openSnapshot(dbPath)
+	default:
+		return nil, err
+	}
 }
 
 // createDBDriver is the callback provided during driver registration that
@@ -49,15 +81,16 @@ func createDBDriver(args ...interface{}) (walletdb.DB, error) {
 		return nil, err
 	}
 
-	return openDB(dbPath, true)
+	return openDB(dbPath, true, nil)
 }
 
 func init() {
 	// Register the driver.
 	driver := walletdb.Driver{
-		DbType: dbType,
-		Create: createDBDriver,
-		Open:   openDBDriver,
+		DbType:       dbType,
+		Create:       createDBDriver,
+		Open:         openDBDriver,
+		OpenReadOnly: openReadOnlyDBDriver,
 	}
 	if err := walletdb.RegisterDriver(driver); err != nil {
 		panic(fmt.Sprintf("Failed to regiser database driver '%s': %v",
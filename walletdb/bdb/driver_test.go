@@ -11,7 +11,7 @@ import (
 	"testing"
 
 	"github.com/btcsuite/btcwallet/walletdb"
-	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/walletdb/bdb"
 )
 
 // dbType is the database type name for this driver.
@@ -161,3 +161,181 @@ func TestPersistence(t *testing.T) {
 		return
 	}
 }
+
+// TestCompact ensures that compacting a database shrinks its file without
+// changing its contents.
+func TestCompact(t *testing.T) {
+	wantErr := walletdb.ErrDbDoesNotExist
+	if err := bdb.Compact("noexist.db"); err != wantErr {
+		t.Errorf("Compact: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	dbPath := "compacttest.db"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	// Store many values in a nested bucket and delete most of them again,
+	// leaving freed pages in the database file.
+	const numValues = 5000
+	value := make([]byte, 1000)
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns1, err := tx.CreateTopLevelBucket([]byte("ns1"))
+		if err != nil {
+			return err
+		}
+		nested, err := ns1.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < numValues; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if err := nested.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return ns1.Put([]byte("ns1key1"), []byte("foo1"))
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		nested := tx.ReadWriteBucket([]byte("ns1")).NestedReadWriteBucket(
+			[]byte("nested"),
+		)
+		for i := 10; i < numValues; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if err := nested.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+	wantSums, err := walletdb.Checksums(db)
+	if err != nil {
+		t.Errorf("Checksums: unexpected error: %v", err)
+		return
+	}
+	db.Close()
+
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		t.Errorf("Stat: unexpected error: %v", err)
+		return
+	}
+	if err := bdb.Compact(dbPath); err != nil {
+		t.Errorf("Compact: unexpected error: %v", err)
+		return
+	}
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		t.Errorf("Stat: unexpected error: %v", err)
+		return
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("Compact: database did not shrink - %d bytes before, "+
+			"%d bytes after", before.Size(), after.Size())
+		return
+	}
+
+	// Ensure the compacted database holds the same contents.
+	db, err = walletdb.Open(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to open test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+	gotSums, err := walletdb.Checksums(db)
+	if err != nil {
+		t.Errorf("Checksums: unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(gotSums, wantSums) {
+		t.Errorf("Compact: contents changed - got checksums %x, want %x",
+			gotSums, wantSums)
+		return
+	}
+}
+
+// TestOpenReadOnlyWhileOpen ensures that a database another process has open
+// for writing can be opened in read-only mode, and that the read-only database
+// holds the values committed before it was opened.
+func TestOpenReadOnlyWhileOpen(t *testing.T) {
+	dbPath := "readonlytest.db"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	ns1Key := []byte("ns1")
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns1, err := tx.CreateTopLevelBucket(ns1Key)
+		if err != nil {
+			return err
+		}
+		return ns1.Put([]byte("ns1key1"), []byte("foo1"))
+	})
+	if err != nil {
+		t.Errorf("ns1 Update: unexpected error: %v", err)
+		return
+	}
+
+	// The database is still open for writing, which holds its exclusive
+	// file lock for every other open file of it.
+	readOnlyDB, err := walletdb.Open(dbType, dbPath,
+		walletdb.OpenOptions{ReadOnly: true})
+	if err != nil {
+		t.Errorf("Open: unexpected error opening read-only: %v", err)
+		return
+	}
+	defer readOnlyDB.Close()
+
+	err = walletdb.View(readOnlyDB, func(tx walletdb.ReadTx) error {
+		ns1 := tx.ReadBucket(ns1Key)
+		if ns1 == nil {
+			return fmt.Errorf("ReadTx.ReadBucket: unexpected nil root bucket")
+		}
+		want := []byte("foo1")
+		if got := ns1.Get([]byte("ns1key1")); !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("Get: unexpected value - got %s, "+
+				"want %s", got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ns1 View: unexpected error: %v", err)
+		return
+	}
+
+	wantErr := walletdb.ErrTxNotWritable
+	if _, err := readOnlyDB.BeginReadWriteTx(); err != wantErr {
+		t.Errorf("BeginReadWriteTx: did not receive expected error - "+
+			"got %v, want %v", err, wantErr)
+		return
+	}
+
+	// Writes to the database must not be blocked by the read-only
+	// database.
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		return tx.ReadWriteBucket(ns1Key).Put([]byte("ns1key2"),
+			[]byte("foo2"))
+	})
+	if err != nil {
+		t.Errorf("ns1 Update: unexpected error: %v", err)
+		return
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/coreos/bbolt"
+)
+
+const (
+	// snapshotAttempts is how many times a database which another process
+	// has open for writing is copied until no transaction was committed
+	// while copying it.
+	snapshotAttempts = 10
+
+	// metaPageSizeOffset is the offset of the page size in the first meta
+	// page of a bolt database, following the page header and the magic and
+	// version numbers of the meta page.
+	metaPageSizeOffset = 24
+)
+
+// errSnapshotChanged is returned when every attempt to copy a database which
+// another process has open for writing was interrupted by a commit.
+var errSnapshotChanged = errors.New("database was modified while " +
+	"copying a snapshot of it")
+
+// snapshotDB is a database opened from a snapshot of a database which another
+// process has open for writing.  The snapshot is removed when the database is
+// closed.
+type snapshotDB struct {
+	*db
+	path string
+}
+
+// Close closes the database and removes its snapshot.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (s *snapshotDB) Close() error {
+	err := s.db.Close()
+	if e := os.Remove(s.path); err == nil {
+		err = e
+	}
+	return err
+}
+
+// openSnapshot opens a snapshot of the database at the provided path in
+// read-only mode without taking the file lock of the database.  Bolt holds an
+// exclusive file lock while a process has the database open for writing, and
+// opening it in read-only mode waits for that lock.
+//
+// A commit writes the pages of its transaction to pages which are not used by
+// the last committed transaction before writing a meta page, so a copy of the
+// database taken while its meta pages did not change holds the last committed
+// transaction.
+func openSnapshot(dbPath string) (walletdb.DB, error) {
+	for i := 0; i < snapshotAttempts; i++ {
+		path, err := copySnapshot(dbPath)
+		if err == errSnapshotChanged {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		boltDB, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+		if err != nil {
+			os.Remove(path)
+			return nil, convertErr(err)
+		}
+		return &snapshotDB{db: (*db)(boltDB), path: path}, nil
+	}
+	return nil, errSnapshotChanged
+}
+
+// copySnapshot copies the database at the provided path to a temporary file
+// and returns its path.  errSnapshotChanged is returned if the meta pages of
+// the database changed while copying it.
+func copySnapshot(dbPath string) (string, error) {
+	f, err := os.Open(dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	before, err := readMetaPages(f)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := ioutil.TempFile("", "btcwallet-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, f)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		var after []byte
+		after, err = readMetaPages(f)
+		if err == nil && !bytes.Equal(before, after) {
+			err = errSnapshotChanged
+		}
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// readMetaPages reads the two meta pages at the start of a bolt database.  The
+// page size is stored in the byte order of the machine which created the
+// database, and the page size of this machine is used when it is not
+// readable.
+func readMetaPages(f *os.File) ([]byte, error) {
+	var header [metaPageSizeOffset + 4]byte
+	if _, err := f.ReadAt(header[:], 0); err != nil {
+		return nil, err
+	}
+	pageSize := os.Getpagesize()
+	size := binary.LittleEndian.Uint32(header[metaPageSizeOffset:])
+	if size >= 512 && size <= 1<<16 {
+		pageSize = int(size)
+	}
+
+	meta := make([]byte, 2*pageSize)
+	if _, err := f.ReadAt(meta, 0); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
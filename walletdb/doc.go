@@ -43,6 +43,11 @@ and Open functions which take a database type string that identifies the
 specific database driver (backend) to use as well as arguments specific to the
 specified driver.
 
+Passing OpenOptions with ReadOnly set after the driver arguments of Open opens
+the database in read-only mode, where read-write transactions can not be
+started.  Drivers supporting it open the database without taking the locks of
+writers, so tools can read a database another process keeps writing to.
+
 Namespaces
 
 The Namespace interface is an abstraction that provides facilities for obtaining
@@ -75,6 +80,10 @@ call Commit or Rollback when they are finished with it.  Leaving transactions
 open for long periods of time can have several adverse effects, so it is
 recommended that managed transactions are used instead.
 
+The Batch method of DB provides a managed read-write transaction which may be
+shared with concurrent callers, coalescing many small writes into a single
+commit on drivers which support it.
+
 Buckets
 
 The Bucket interface provides the ability to manipulate key/value pairs and
@@ -0,0 +1,708 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package encrypted
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/btcsuite/btcwallet/internal/zero"
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+var (
+	// ErrNotEncrypted is returned when opening a database which was not
+	// encrypted with Create.
+	ErrNotEncrypted = errors.New("database is not encrypted")
+
+	// ErrAlreadyEncrypted is returned when encrypting a database which is
+	// already encrypted.
+	ErrAlreadyEncrypted = errors.New("database is already encrypted")
+
+	// ErrWrongPassphrase is returned when opening a database or changing
+	// its passphrase with an incorrect passphrase.
+	ErrWrongPassphrase = errors.New("invalid passphrase for database")
+
+	// ErrMalformed is returned when the encryption parameters stored in a
+	// database are invalid.
+	ErrMalformed = errors.New("malformed database encryption parameters")
+
+	// errForeignTx is returned when a transaction of another database is
+	// passed to a DB.
+	errForeignTx = errors.New("transaction does not belong to the database")
+)
+
+var (
+	// paramsBucketName is the name of the unencrypted top-level bucket
+	// holding the encryption parameters.  It can not collide with the
+	// names of encrypted buckets, which are all hashSize bytes long.
+	paramsBucketName = []byte("encryption")
+
+	// masterParamsName is the key of the scrypt parameters of the master
+	// key derived from the passphrase.
+	masterParamsName = []byte("masterparams")
+
+	// dataKeysName is the key of the encryption and HMAC keys of the data,
+	// encrypted with the master key.
+	dataKeysName = []byte("datakeys")
+
+	// bucketNameKey is the key in each bucket holding the encrypted name
+	// of the bucket.  It can not collide with the keys of values,
+	// which are all hashSize bytes long.
+	bucketNameKey = []byte{0}
+)
+
+// hashSize is the size of the HMAC-SHA256 replacing keys and bucket names.
+const hashSize = sha256.Size
+
+// DB is a walletdb.DB encrypting all buckets, keys and values written to an
+// underlying walletdb.DB.
+type DB struct {
+	db     walletdb.DB
+	encKey *snacl.CryptoKey
+	macKey *snacl.CryptoKey
+}
+
+// Enforce DB implements the walletdb.DB interface.
+var _ walletdb.DB = (*DB)(nil)
+
+// IsEncrypted returns whether db was encrypted with Create.
+func IsEncrypted(db walletdb.DB) (bool, error) {
+	var encrypted bool
+	err := walletdb.View(db, func(tx walletdb.ReadTx) error {
+		encrypted = tx.ReadBucket(paramsBucketName) != nil
+		return nil
+	})
+	return encrypted, err
+}
+
+// Create encrypts a new database with keys protected by passphrase, using the
+// scrypt parameters N, r and p to derive the key encrypting the keys from the
+// passphrase.  No data may have been written to db yet.  The returned DB must
+// be used to access the data of db.
+func Create(db walletdb.DB, passphrase []byte, N, r, p int) (*DB, error) {
+	masterKey, err := snacl.NewSecretKey(&passphrase, N, r, p)
+	if err != nil {
+		return nil, err
+	}
+	defer masterKey.Zero()
+
+	encKey, err := snacl.GenerateCryptoKey()
+	if err != nil {
+		return nil, err
+	}
+	macKey, err := snacl.GenerateCryptoKey()
+	if err != nil {
+		return nil, err
+	}
+	dataKeys := make([]byte, 0, 2*snacl.KeySize)
+	dataKeys = append(dataKeys, encKey[:]...)
+	dataKeys = append(dataKeys, macKey[:]...)
+	dataKeysEnc, err := masterKey.Encrypt(dataKeys)
+	zero.Bytes(dataKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		if tx.ReadBucket(paramsBucketName) != nil {
+			return ErrAlreadyEncrypted
+		}
+		params, err := tx.CreateTopLevelBucket(paramsBucketName)
+		if err != nil {
+			return err
+		}
+		err = params.Put(masterParamsName, masterKey.Marshal())
+		if err != nil {
+			return err
+		}
+		return params.Put(dataKeysName, dataKeysEnc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{db: db, encKey: encKey, macKey: macKey}, nil
+}
+
+// Open returns the DB accessing the data of a database encrypted with Create,
+// decrypting its keys with passphrase.
+func Open(db walletdb.DB, passphrase []byte) (*DB, error) {
+	var masterParams, dataKeysEnc []byte
+	err := walletdb.View(db, func(tx walletdb.ReadTx) error {
+		params := tx.ReadBucket(paramsBucketName)
+		if params == nil {
+			return ErrNotEncrypted
+		}
+		masterParams = append([]byte(nil), params.Get(masterParamsName)...)
+		dataKeysEnc = append([]byte(nil), params.Get(dataKeysName)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	masterKey, err := deriveMasterKey(masterParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer masterKey.Zero()
+
+	dataKeys, err := masterKey.Decrypt(dataKeysEnc)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	defer zero.Bytes(dataKeys)
+	if len(dataKeys) != 2*snacl.KeySize {
+		return nil, ErrMalformed
+	}
+
+	var encKey, macKey snacl.CryptoKey
+	copy(encKey[:], dataKeys[:snacl.KeySize])
+	copy(macKey[:], dataKeys[snacl.KeySize:])
+	return &DB{db: db, encKey: &encKey, macKey: &macKey}, nil
+}
+
+// deriveMasterKey derives the master key with the marshalled scrypt
+// parameters params from passphrase.
+func deriveMasterKey(params, passphrase []byte) (*snacl.SecretKey, error) {
+	var masterKey snacl.SecretKey
+	if err := masterKey.Unmarshal(params); err != nil {
+		return nil, ErrMalformed
+	}
+	if err := masterKey.DeriveKey(&passphrase); err != nil {
+		if err == snacl.ErrInvalidPassword {
+			return nil, ErrWrongPassphrase
+		}
+		return nil, err
+	}
+	return &masterKey, nil
+}
+
+// ChangePassphrase changes the passphrase protecting the keys of the database
+// from old to new, keeping the scrypt parameters.  The data does not need to be
+// re-encrypted.  tx must be a transaction of db, so that the passphrase can be
+// changed atomically with other changes.
+func (db *DB) ChangePassphrase(tx walletdb.ReadWriteTx, old, new []byte) error {
+	rwtx, ok := tx.(*readWriteTx)
+	if !ok || rwtx.db != db {
+		return errForeignTx
+	}
+
+	params := rwtx.tx.ReadWriteBucket(paramsBucketName)
+	oldKey, err := deriveMasterKey(params.Get(masterParamsName), old)
+	if err != nil {
+		return err
+	}
+	defer oldKey.Zero()
+
+	dataKeys, err := oldKey.Decrypt(params.Get(dataKeysName))
+	if err != nil {
+		return ErrMalformed
+	}
+	defer zero.Bytes(dataKeys)
+
+	p := &oldKey.Parameters
+	newKey, err := snacl.NewSecretKey(&new, p.N, p.R, p.P)
+	if err != nil {
+		return err
+	}
+	defer newKey.Zero()
+	dataKeysEnc, err := newKey.Encrypt(dataKeys)
+	if err != nil {
+		return err
+	}
+
+	err = params.Put(masterParamsName, newKey.Marshal())
+	if err != nil {
+		return err
+	}
+	return params.Put(dataKeysName, dataKeysEnc)
+}
+
+// hash returns the name in the underlying database of key in the bucket with
+// the id parent.
+func (db *DB) hash(parent, key []byte) []byte {
+	mac := hmac.New(sha256.New, db.macKey[:])
+	mac.Write(parent)
+	mac.Write(key)
+	return mac.Sum(nil)
+}
+
+// seal encrypts a key and its value.
+func (db *DB) seal(key, value []byte) ([]byte, error) {
+	// The serialized format is:
+	//   <key length><key><value>
+	//
+	// 4 bytes key length + key + value
+	buf := make([]byte, 4+len(key)+len(value))
+	binary.LittleEndian.PutUint32(buf, uint32(len(key)))
+	copy(buf[4:], key)
+	copy(buf[4+len(key):], value)
+	defer zero.Bytes(buf)
+	return db.encKey.Encrypt(buf)
+}
+
+// open decrypts a key and its value encrypted by seal.
+func (db *DB) open(sealed []byte) (key, value []byte, err error) {
+	buf, err := db.encKey.Decrypt(sealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(buf) < 4 {
+		return nil, nil, snacl.ErrMalformed
+	}
+	keyLen := binary.LittleEndian.Uint32(buf)
+	if uint32(len(buf)-4) < keyLen {
+		return nil, nil, snacl.ErrMalformed
+	}
+	return buf[4 : 4+keyLen], buf[4+keyLen:], nil
+}
+
+// BeginReadTx implements the walletdb.DB interface.
+func (db *DB) BeginReadTx() (walletdb.ReadTx, error) {
+	tx, err := db.db.BeginReadTx()
+	if err != nil {
+		return nil, err
+	}
+	return &readTx{tx: tx, db: db, cache: make(entryCache)}, nil
+}
+
+// BeginReadWriteTx implements the walletdb.DB interface.
+func (db *DB) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
+	tx, err := db.db.BeginReadWriteTx()
+	if err != nil {
+		return nil, err
+	}
+	return &readWriteTx{readTx{tx: tx, db: db, cache: make(entryCache)}, tx}, nil
+}
+
+// Batch executes f in a batched read-write transaction of the underlying
+// database.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (db *DB) Batch(f func(tx walletdb.ReadWriteTx) error) error {
+	return db.db.Batch(func(tx walletdb.ReadWriteTx) error {
+		return f(&readWriteTx{
+			readTx{tx: tx, db: db, cache: make(entryCache)}, tx,
+		})
+	})
+}
+
+// Copy writes a copy of the encrypted underlying database to w.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (db *DB) Copy(w io.Writer) error {
+	return db.db.Copy(w)
+}
+
+// Close clears the keys of the database and closes the underlying database.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (db *DB) Close() error {
+	db.encKey.Zero()
+	db.macKey.Zero()
+	return db.db.Close()
+}
+
+// entryCache holds the decrypted and sorted entries of the buckets iterated
+// by a transaction, keyed by bucket id, so that each bucket is only decrypted
+// and sorted once per transaction unless it is modified.
+type entryCache map[string][]entry
+
+// readTx is a read-only transaction of a DB.
+type readTx struct {
+	tx    walletdb.ReadTx
+	db    *DB
+	cache entryCache
+}
+
+// ReadBucket implements the walletdb.ReadTx interface.
+func (tx *readTx) ReadBucket(key []byte) walletdb.ReadBucket {
+	id := tx.db.hash(nil, key)
+	b := tx.tx.ReadBucket(id)
+	if b == nil {
+		return nil
+	}
+	return &readBucket{b: b, db: tx.db, id: id, cache: tx.cache}
+}
+
+// ForEachBucket implements the walletdb.ReadTx interface.
+func (tx *readTx) ForEachBucket(fn func(key []byte) error) error {
+	return tx.tx.ForEachBucket(func(id []byte) error {
+		if len(id) != hashSize {
+			return nil
+		}
+		name, _, err := tx.db.open(tx.tx.ReadBucket(id).Get(bucketNameKey))
+		if err != nil {
+			return err
+		}
+		return fn(name)
+	})
+}
+
+// Rollback implements the walletdb.ReadTx interface.
+func (tx *readTx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// readWriteTx is a read-write transaction of a DB.
+type readWriteTx struct {
+	readTx
+	tx walletdb.ReadWriteTx
+}
+
+// ReadWriteBucket implements the walletdb.ReadWriteTx interface.
+func (tx *readWriteTx) ReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	id := tx.db.hash(nil, key)
+	b := tx.tx.ReadWriteBucket(id)
+	if b == nil {
+		return nil
+	}
+	return &readWriteBucket{
+		readBucket{b: b, db: tx.db, id: id, cache: tx.cache}, b,
+	}
+}
+
+// CreateTopLevelBucket implements the walletdb.ReadWriteTx interface.
+func (tx *readWriteTx) CreateTopLevelBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	id := tx.db.hash(nil, key)
+	b, err := tx.tx.CreateTopLevelBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	delete(tx.cache, string(id))
+	return tx.db.initBucket(b, id, key, tx.cache)
+}
+
+// DeleteTopLevelBucket implements the walletdb.ReadWriteTx interface.
+func (tx *readWriteTx) DeleteTopLevelBucket(key []byte) error {
+	id := tx.db.hash(nil, key)
+	delete(tx.cache, string(id))
+	return tx.tx.DeleteTopLevelBucket(id)
+}
+
+// Commit implements the walletdb.ReadWriteTx interface.
+func (tx *readWriteTx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// readBucket is a bucket of a DB.  The id of a bucket is its name in the
+// underlying database, which is also hashed with the keys of its values to
+// derive their names.
+type readBucket struct {
+	b     walletdb.ReadBucket
+	db    *DB
+	id    []byte
+	cache entryCache
+}
+
+// NestedReadBucket implements the walletdb.ReadBucket interface.
+func (b *readBucket) NestedReadBucket(key []byte) walletdb.ReadBucket {
+	id := b.db.hash(b.id, key)
+	nested := b.b.NestedReadBucket(id)
+	if nested == nil {
+		return nil
+	}
+	return &readBucket{b: nested, db: b.db, id: id, cache: b.cache}
+}
+
+// ForEach implements the walletdb.ReadBucket interface.  Buckets and values are
+// iterated in the order of their keys.
+func (b *readBucket) ForEach(f func(k, v []byte) error) error {
+	entries, err := b.entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := f(e.key, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get implements the walletdb.ReadBucket interface.  Nil is returned if the
+// value can not be decrypted.
+func (b *readBucket) Get(key []byte) []byte {
+	sealed := b.b.Get(b.db.hash(b.id, key))
+	if sealed == nil {
+		return nil
+	}
+	k, v, err := b.db.open(sealed)
+	if err != nil || !bytes.Equal(k, key) {
+		return nil
+	}
+	return v
+}
+
+// ReadCursor implements the walletdb.ReadBucket interface.
+func (b *readBucket) ReadCursor() walletdb.ReadCursor {
+	return b.cursor()
+}
+
+// entry is a decrypted bucket or value of a bucket.
+type entry struct {
+	key   []byte
+	value []byte
+	name  []byte
+}
+
+// entries returns all decrypted buckets and values of the bucket sorted by
+// their keys.  The value of buckets is nil.  The entries are cached by the
+// transaction until the bucket is modified, so the returned slice must not be
+// modified.
+func (b *readBucket) entries() ([]entry, error) {
+	if entries, ok := b.cache[string(b.id)]; ok {
+		return entries, nil
+	}
+
+	var entries []entry
+	err := b.b.ForEach(func(k, v []byte) error {
+		if len(k) != hashSize {
+			return nil
+		}
+
+		e := entry{name: append([]byte(nil), k...)}
+		var err error
+		if v == nil {
+			nested := b.b.NestedReadBucket(k)
+			e.key, _, err = b.db.open(nested.Get(bucketNameKey))
+		} else {
+			e.key, e.value, err = b.db.open(v)
+		}
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	b.cache[string(b.id)] = entries
+	return entries, nil
+}
+
+// invalidate removes the cached entries of the bucket after it is modified.
+func (b *readBucket) invalidate() {
+	delete(b.cache, string(b.id))
+}
+
+// cursor returns a cursor over the entries of the bucket.  Errors decrypting
+// the bucket result in an empty cursor.
+func (b *readBucket) cursor() *cursor {
+	entries, _ := b.entries()
+	return &cursor{bucket: b, entries: entries, pos: -1}
+}
+
+// readWriteBucket is a writable bucket of a DB.
+type readWriteBucket struct {
+	readBucket
+	b walletdb.ReadWriteBucket
+}
+
+// Enforce readWriteBucket implements the walletdb.ReadWriteBucket interface.
+var _ walletdb.ReadWriteBucket = (*readWriteBucket)(nil)
+
+// NestedReadWriteBucket implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) NestedReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	id := b.db.hash(b.id, key)
+	nested := b.b.NestedReadWriteBucket(id)
+	if nested == nil {
+		return nil
+	}
+	return &readWriteBucket{
+		readBucket{b: nested, db: b.db, id: id, cache: b.cache}, nested,
+	}
+}
+
+// CreateBucket implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) CreateBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	id := b.db.hash(b.id, key)
+	nested, err := b.b.CreateBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	b.invalidate()
+	delete(b.cache, string(id))
+	return b.db.initBucket(nested, id, key, b.cache)
+}
+
+// CreateBucketIfNotExists implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) CreateBucketIfNotExists(key []byte) (walletdb.ReadWriteBucket, error) {
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	id := b.db.hash(b.id, key)
+	nested, err := b.b.CreateBucketIfNotExists(id)
+	if err != nil {
+		return nil, err
+	}
+	if nested.Get(bucketNameKey) != nil {
+		return &readWriteBucket{
+			readBucket{b: nested, db: b.db, id: id, cache: b.cache},
+			nested,
+		}, nil
+	}
+	b.invalidate()
+	delete(b.cache, string(id))
+	return b.db.initBucket(nested, id, key, b.cache)
+}
+
+// initBucket stores the encrypted name of a created bucket.
+func (db *DB) initBucket(b walletdb.ReadWriteBucket, id, key []byte,
+	cache entryCache) (walletdb.ReadWriteBucket, error) {
+
+	name, err := db.seal(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(bucketNameKey, name); err != nil {
+		return nil, err
+	}
+	return &readWriteBucket{
+		readBucket{b: b, db: db, id: id, cache: cache}, b,
+	}, nil
+}
+
+// DeleteNestedBucket implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) DeleteNestedBucket(key []byte) error {
+	if len(key) == 0 {
+		return walletdb.ErrIncompatibleValue
+	}
+	b.invalidate()
+	return b.b.DeleteNestedBucket(b.db.hash(b.id, key))
+}
+
+// Put implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return walletdb.ErrKeyRequired
+	}
+	sealed, err := b.db.seal(key, value)
+	if err != nil {
+		return err
+	}
+	b.invalidate()
+	return b.b.Put(b.db.hash(b.id, key), sealed)
+}
+
+// Delete implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) Delete(key []byte) error {
+	b.invalidate()
+	return b.b.Delete(b.db.hash(b.id, key))
+}
+
+// ReadWriteCursor implements the walletdb.ReadWriteBucket interface.
+func (b *readWriteBucket) ReadWriteCursor() walletdb.ReadWriteCursor {
+	c := b.readBucket.cursor()
+	c.rwBucket = b.b
+	return c
+}
+
+// cursor iterates the entries of a bucket, which are decrypted and sorted when
+// the cursor is created.  Values written to the bucket afterwards are not
+// iterated.  The entries are shared with the transaction's cache until the
+// cursor deletes an entry, when the cursor takes a copy of them.
+type cursor struct {
+	bucket   *readBucket
+	rwBucket walletdb.ReadWriteBucket
+	entries  []entry
+	pos      int
+	owned    bool
+
+	// deleted is set after deleting the entry at pos, which now holds
+	// the entry following it.
+	deleted bool
+}
+
+// at moves the cursor to the entry at pos and returns it.  Nil is returned
+// when moving before the first or after the last entry.
+func (c *cursor) at(pos int) (key, value []byte) {
+	c.deleted = false
+	switch {
+	case pos < 0:
+		c.pos = -1
+		return nil, nil
+	case pos >= len(c.entries):
+		c.pos = len(c.entries)
+		return nil, nil
+	}
+	c.pos = pos
+	return c.entries[pos].key, c.entries[pos].value
+}
+
+// First implements the walletdb.ReadCursor interface.
+func (c *cursor) First() (key, value []byte) {
+	return c.at(0)
+}
+
+// Last implements the walletdb.ReadCursor interface.
+func (c *cursor) Last() (key, value []byte) {
+	return c.at(len(c.entries) - 1)
+}
+
+// Next implements the walletdb.ReadCursor interface.
+func (c *cursor) Next() (key, value []byte) {
+	if c.deleted {
+		return c.at(c.pos)
+	}
+	return c.at(c.pos + 1)
+}
+
+// Prev implements the walletdb.ReadCursor interface.
+func (c *cursor) Prev() (key, value []byte) {
+	return c.at(c.pos - 1)
+}
+
+// Seek implements the walletdb.ReadCursor interface.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	return c.at(sort.Search(len(c.entries), func(i int) bool {
+		return bytes.Compare(c.entries[i].key, seek) >= 0
+	}))
+}
+
+// Delete implements the walletdb.ReadWriteCursor interface.  Buckets can not be
+// deleted with a cursor.
+func (c *cursor) Delete() error {
+	if c.rwBucket == nil {
+		return walletdb.ErrTxNotWritable
+	}
+	if c.deleted || c.pos < 0 || c.pos >= len(c.entries) {
+		return nil
+	}
+	e := c.entries[c.pos]
+	if e.value == nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	if err := c.rwBucket.Delete(e.name); err != nil {
+		return err
+	}
+	c.bucket.invalidate()
+	if !c.owned {
+		c.entries = append([]entry(nil), c.entries...)
+		c.owned = true
+	}
+	c.entries = append(c.entries[:c.pos], c.entries[c.pos+1:]...)
+	c.deleted = true
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package encrypted_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/walletdb/encrypted"
+)
+
+// setupDB creates an encrypted bdb database in a temporary directory.
+func setupDB(t *testing.T) (db walletdb.DB, encDB *encrypted.DB, teardown func()) {
+	dirName, err := ioutil.TempDir("", "encrypteddb")
+	if err != nil {
+		t.Fatalf("Failed to create db temp dir: %v", err)
+	}
+	db, err = walletdb.Create("bdb", filepath.Join(dirName, "test.db"))
+	if err != nil {
+		os.RemoveAll(dirName)
+		t.Fatal(err)
+	}
+	encDB, err = encrypted.Create(db, passphrase, 16, 8, 1)
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dirName)
+		t.Fatal(err)
+	}
+	teardown = func() {
+		db.Close()
+		os.RemoveAll(dirName)
+	}
+	return db, encDB, teardown
+}
+
+// TestOpen tests opening encrypted databases and changing their passphrase.
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	db, encDB, teardown := setupDB(t)
+	defer teardown()
+
+	bucketKey := []byte("bucket")
+	secret := []byte("secret value")
+	err := walletdb.Update(encDB, func(tx walletdb.ReadWriteTx) error {
+		b, err := tx.CreateTopLevelBucket(bucketKey)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), secret)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither the bucket nor the value are stored in plaintext.
+	var buf bytes.Buffer
+	if err := db.Copy(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), bucketKey) ||
+		bytes.Contains(buf.Bytes(), secret) {
+
+		t.Fatal("database contains plaintext data")
+	}
+
+	if _, err := encrypted.Create(db, passphrase, 16, 8, 1); err != encrypted.ErrAlreadyEncrypted {
+		t.Fatalf("expected ErrAlreadyEncrypted, got %v", err)
+	}
+	if _, err := encrypted.Open(db, []byte("wrong")); err != encrypted.ErrWrongPassphrase {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	newPassphrase := []byte("new passphrase")
+	err = walletdb.Update(encDB, func(tx walletdb.ReadWriteTx) error {
+		err := encDB.ChangePassphrase(tx, []byte("wrong"), newPassphrase)
+		if err != encrypted.ErrWrongPassphrase {
+			return fmt.Errorf("expected ErrWrongPassphrase, got %v",
+				err)
+		}
+		return encDB.ChangePassphrase(tx, passphrase, newPassphrase)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encrypted.Open(db, passphrase); err != encrypted.ErrWrongPassphrase {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	reopened, err := encrypted.Open(db, newPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.View(reopened, func(tx walletdb.ReadTx) error {
+		b := tx.ReadBucket(bucketKey)
+		if b == nil {
+			return fmt.Errorf("missing bucket %s", bucketKey)
+		}
+		if v := b.Get([]byte("key")); !bytes.Equal(v, secret) {
+			return fmt.Errorf("expected value %s, got %s", secret, v)
+		}
+
+		var names []string
+		err := tx.ForEachBucket(func(key []byte) error {
+			names = append(names, string(key))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(names, []string{string(bucketKey)}) {
+			return fmt.Errorf("expected top-level buckets %s, got %v",
+				bucketKey, names)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCursor tests that cursors iterate buckets and values in the order of
+// their keys.
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	_, encDB, teardown := setupDB(t)
+	defer teardown()
+
+	err := walletdb.Update(encDB, func(tx walletdb.ReadWriteTx) error {
+		b, err := tx.CreateTopLevelBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"c", "a", "d"} {
+			if err := b.Put([]byte(k), []byte("v"+k)); err != nil {
+				return err
+			}
+		}
+		if _, err := b.CreateBucket([]byte("b")); err != nil {
+			return err
+		}
+
+		// Collect the keys iterated by fn.
+		keys := func(fn func(walletdb.ReadCursor) ([]byte, []byte),
+			first func(walletdb.ReadCursor) ([]byte, []byte)) []string {
+
+			var keys []string
+			c := b.ReadCursor()
+			for k, _ := first(c); k != nil; k, _ = fn(c) {
+				keys = append(keys, string(k))
+			}
+			return keys
+		}
+		next := walletdb.ReadCursor.Next
+		prev := walletdb.ReadCursor.Prev
+		first := walletdb.ReadCursor.First
+		last := walletdb.ReadCursor.Last
+		seek := func(c walletdb.ReadCursor) ([]byte, []byte) {
+			return c.Seek([]byte("bb"))
+		}
+
+		tests := []struct {
+			name string
+			got  []string
+			want []string
+		}{
+			{"forward", keys(next, first), []string{"a", "b", "c", "d"}},
+			{"backward", keys(prev, last), []string{"d", "c", "b", "a"}},
+			{"seek", keys(next, seek), []string{"c", "d"}},
+		}
+		for _, test := range tests {
+			if !reflect.DeepEqual(test.got, test.want) {
+				return fmt.Errorf("%s: expected keys %v, got %v",
+					test.name, test.want, test.got)
+			}
+		}
+
+		// A cursor created before the delete below shares the cached
+		// entries of the bucket and must not be affected by it.
+		before := b.ReadCursor()
+
+		c := b.ReadWriteCursor()
+		c.First()
+		if err := c.Delete(); err != nil {
+			return err
+		}
+		if k, _ := c.Next(); string(k) != "b" {
+			return fmt.Errorf("expected key b after delete, got %s", k)
+		}
+		if err := c.Delete(); err != walletdb.ErrIncompatibleValue {
+			return fmt.Errorf("expected ErrIncompatibleValue "+
+				"deleting bucket, got %v", err)
+		}
+		if v := b.Get([]byte("a")); v != nil {
+			return fmt.Errorf("expected deleted value, got %s", v)
+		}
+		got := keys(next, first)
+		if want := []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("expected keys %v after delete, got %v",
+				want, got)
+		}
+		if k, _ := before.First(); string(k) != "a" {
+			return fmt.Errorf("expected earlier cursor to start at a, "+
+				"got %s", k)
+		}
+
+		// Writes must invalidate the cached entries.
+		if err := b.Put([]byte("e"), []byte("ve")); err != nil {
+			return err
+		}
+		if k, _ := b.ReadCursor().Last(); string(k) != "e" {
+			return fmt.Errorf("expected last key e after put, got %s", k)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package encrypted implements a walletdb.DB encrypting all data written to
+another walletdb.DB, so that a stolen database file does not reveal the
+transaction history, addresses or any other metadata of the wallet.
+
+The keys used to encrypt the database are derived from a passphrase using
+scrypt.  They are stored in the database encrypted with the passphrase, so the
+passphrase can be changed without re-encrypting the data.
+
+Every key of a bucket is replaced by its HMAC-SHA256, which depends on the
+bucket, and values are stored together with their key encrypted with
+NaCl secretbox.  Bucket names are encrypted in the same way.  The database
+therefore only reveals the number of buckets and values and their approximate
+sizes.
+
+Since the stored keys are not ordered, cursors and ForEach decrypt all values
+of a bucket and sort them in memory, which costs time and memory proportional
+to the size of the bucket.  The sorted entries are cached by the transaction
+until the bucket is modified, so creating several cursors over an unmodified
+bucket, as is common when seeking to different keys, decrypts the bucket only
+once.  Iterating a bucket which is written to between cursors still decrypts it
+each time, which makes such loops over large buckets considerably more
+expensive than with an unencrypted database.
+
+Usage
+
+A new database is encrypted with Create, which returns the encrypting
+database.  It must be called before anything is written to the database:
+
+	db, err := walletdb.Create("bdb", "wallet.db")
+	if err != nil {
+		// Handle error
+	}
+	encDB, err := encrypted.Create(db, passphrase, snacl.DefaultN,
+		snacl.DefaultR, snacl.DefaultP)
+
+An existing encrypted database is opened with Open:
+
+	db, err := walletdb.Open("bdb", "wallet.db")
+	if err != nil {
+		// Handle error
+	}
+	encDB, err := encrypted.Open(db, passphrase)
+*/
+package encrypted
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package encrypted_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/walletdb/encrypted"
+	"github.com/btcsuite/btcwallet/walletdb/walletdbtest"
+)
+
+// dbType is the type of the test driver creating encrypted bdb databases.
+const dbType = "encryptedbdb"
+
+var passphrase = []byte("passphrase")
+
+// createEncryptedDB creates a bdb database at the path in args and encrypts
+// it with cheap scrypt parameters.
+func createEncryptedDB(args ...interface{}) (walletdb.DB, error) {
+	db, err := walletdb.Create("bdb", args...)
+	if err != nil {
+		return nil, err
+	}
+	encDB, err := encrypted.Create(db, passphrase, 16, 8, 1)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return encDB, nil
+}
+
+func init() {
+	driver := walletdb.Driver{
+		DbType: dbType,
+		Create: createEncryptedDB,
+		Open: func(args ...interface{}) (walletdb.DB, error) {
+			return nil, fmt.Errorf("%s.Open is not supported", dbType)
+		},
+	}
+	if err := walletdb.RegisterDriver(driver); err != nil {
+		panic(fmt.Sprintf("Failed to regiser database driver '%s': %v",
+			dbType, err))
+	}
+}
+
+// TestInterface performs all interfaces tests for the encrypted database.
+func TestInterface(t *testing.T) {
+	dbPath := "interfacetest.db"
+	defer os.RemoveAll(dbPath)
+	walletdbtest.TestInterface(t, dbType, dbPath)
+}
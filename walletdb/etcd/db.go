@@ -0,0 +1,757 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/coreos/etcd/clientv3"
+)
+
+const (
+	// rootBucket is the id of the bucket holding the top-level buckets.
+	rootBucket = 0
+
+	// metaBucket is the id of the bucket holding the metadata of the
+	// database.
+	metaBucket = math.MaxUint64
+
+	// requestTimeout is the timeout of requests to the etcd cluster.
+	requestTimeout = 30 * time.Second
+)
+
+// Stored values are prefixed with a tag telling values and nested buckets
+// apart.  The tag of a nested bucket is followed by the id of the bucket.
+const (
+	valueTag  byte = 0
+	bucketTag byte = 1
+)
+
+// seqKey is the key of the metadata bucket holding the id of the last created
+// bucket.  It is written by every commit, so its modification revision is the
+// revision of the last commit.
+var seqKey = []byte("seq")
+
+var (
+	// ErrConflict is returned when committing a read-write transaction
+	// after the database was modified by another process.
+	ErrConflict = errors.New("the database was modified by another " +
+		"writer since the transaction started")
+
+	// errCopyUnsupported is returned by Copy, as etcd databases are backed
+	// up with the tools of the etcd cluster.
+	errCopyUnsupported = errors.New("copying an etcd database is not " +
+		"supported, use etcdctl snapshot save instead")
+)
+
+// encodeID serializes a bucket id.
+func encodeID(id uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], id)
+	return b[:]
+}
+
+// decode returns the value or the nested bucket id of a stored value.
+func decode(stored []byte) (value []byte, child uint64, isBucket bool) {
+	if len(stored) == 9 && stored[0] == bucketTag {
+		return nil, binary.BigEndian.Uint64(stored[1:]), true
+	}
+	return stored[1:], 0, false
+}
+
+// transaction represents a database transaction.  It can either by read-only or
+// read-write and implements the walletdb Tx interfaces.  The transaction
+// provides a root bucket against which all read and writes occur.
+type transaction struct {
+	db       *db
+	writable bool
+	closed   bool
+
+	// rev is the revision of the snapshot read by the transaction.
+	rev int64
+
+	// seq is the id of the last created bucket.
+	seq uint64
+
+	// writes holds the pending writes of a read-write transaction by etcd
+	// key.  Deleted keys map to nil.
+	writes map[string][]byte
+
+	// deleted holds the key prefixes of the deleted buckets.
+	deleted []string
+
+	// err is the first error of an operation which can not return it,
+	// such as Get.  Committing the transaction fails with it.
+	err error
+}
+
+// setErr records the first error of an operation which can not return it.
+func (tx *transaction) setErr(err error) {
+	if tx.err == nil {
+		tx.err = err
+	}
+}
+
+// get returns the stored value of key, or nil if the key does not exist.
+func (tx *transaction) get(key string) ([]byte, error) {
+	if stored, ok := tx.writes[key]; ok {
+		return stored, nil
+	}
+
+	ctx, cancel := tx.db.context()
+	defer cancel()
+	resp, err := tx.db.client.Get(ctx, key, clientv3.WithRev(tx.rev))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// entry is a key and stored value of a bucket.
+type entry struct {
+	key    []byte
+	stored []byte
+}
+
+// entries returns the keys and stored values of the bucket with the given id
+// sorted by their keys.
+func (tx *transaction) entries(id uint64) ([]entry, error) {
+	prefix := tx.db.bucketPrefix(id)
+
+	ctx, cancel := tx.db.context()
+	defer cancel()
+	resp, err := tx.db.client.Get(
+		ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(tx.rev),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		stored[string(kv.Key)] = kv.Value
+	}
+	for key, value := range tx.writes {
+		switch {
+		case !strings.HasPrefix(key, prefix):
+		case value == nil:
+			delete(stored, key)
+		default:
+			stored[key] = value
+		}
+	}
+
+	entries := make([]entry, 0, len(stored))
+	for key, value := range stored {
+		entries = append(entries, entry{
+			key:    []byte(key[len(prefix):]),
+			stored: value,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	return entries, nil
+}
+
+// deleteBucket deletes all key/value pairs of the bucket with the given id.
+// It does not delete nested buckets.
+func (tx *transaction) deleteBucket(id uint64) {
+	prefix := tx.db.bucketPrefix(id)
+	for key := range tx.writes {
+		if strings.HasPrefix(key, prefix) {
+			delete(tx.writes, key)
+		}
+	}
+	tx.deleted = append(tx.deleted, prefix)
+}
+
+func (tx *transaction) root() *bucket {
+	return &bucket{tx: tx, id: rootBucket}
+}
+
+func (tx *transaction) ReadBucket(key []byte) walletdb.ReadBucket {
+	return tx.ReadWriteBucket(key)
+}
+
+func (tx *transaction) ForEachBucket(fn func(key []byte) error) error {
+	return tx.root().ForEach(func(k, _ []byte) error {
+		return fn(k)
+	})
+}
+
+func (tx *transaction) ReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	b := tx.root().nested(key)
+	if b == nil {
+		return nil
+	}
+	return b
+}
+
+func (tx *transaction) CreateTopLevelBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	return tx.root().CreateBucket(key)
+}
+
+func (tx *transaction) DeleteTopLevelBucket(key []byte) error {
+	return tx.root().DeleteNestedBucket(key)
+}
+
+// Commit commits all changes that have been made through the root bucket and
+// all of its sub-buckets to persistent storage.  ErrConflict is returned, and
+// no changes are made, if another process committed changes since the
+// transaction started.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Commit() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	if !tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	defer tx.close()
+
+	if tx.err != nil {
+		return tx.err
+	}
+	if len(tx.writes) == 0 && len(tx.deleted) == 0 {
+		return nil
+	}
+
+	ops := make([]clientv3.Op, 0, len(tx.deleted)+len(tx.writes)+1)
+	for _, prefix := range tx.deleted {
+		ops = append(ops, clientv3.OpDelete(prefix, clientv3.WithPrefix()))
+	}
+	for key, value := range tx.writes {
+		if value == nil {
+			ops = append(ops, clientv3.OpDelete(key))
+			continue
+		}
+		ops = append(ops, clientv3.OpPut(key, string(value)))
+	}
+
+	// Apply the changes only if no other commit happened since the
+	// snapshot of the transaction.
+	seq := tx.db.bucketKey(metaBucket, seqKey)
+	ops = append(ops, clientv3.OpPut(seq, string(encodeID(tx.seq))))
+	ctx, cancel := tx.db.context()
+	defer cancel()
+	resp, err := tx.db.client.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(seq), "<", tx.rev+1),
+	).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Rollback undoes all changes that have been made to the root bucket and all of
+// its sub-buckets.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Rollback() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	tx.close()
+	return nil
+}
+
+// close discards the pending writes and ends the transaction.
+func (tx *transaction) close() {
+	tx.closed = true
+	tx.writes = nil
+	tx.deleted = nil
+	if tx.writable {
+		tx.db.writeMtx.Unlock()
+	}
+}
+
+// bucket is an internal type used to represent a collection of key/value pairs
+// and implements the walletdb Bucket interfaces.
+type bucket struct {
+	tx *transaction
+	id uint64
+}
+
+// Enforce bucket implements the walletdb Bucket interfaces.
+var _ walletdb.ReadWriteBucket = (*bucket)(nil)
+
+// lookup returns the value or nested bucket id of key.  ok is false if the key
+// does not exist.
+func (b *bucket) lookup(key []byte) (value []byte, child uint64, isBucket,
+	ok bool, err error) {
+
+	stored, err := b.tx.get(b.tx.db.bucketKey(b.id, key))
+	if err != nil || stored == nil {
+		return nil, 0, false, false, err
+	}
+	value, child, isBucket = decode(stored)
+	return value, child, isBucket, true, nil
+}
+
+// nested returns the nested bucket with the given key, or nil if it does not
+// exist.
+func (b *bucket) nested(key []byte) *bucket {
+	_, child, isBucket, _, err := b.lookup(key)
+	if err != nil {
+		b.tx.setErr(err)
+		return nil
+	}
+	if !isBucket {
+		return nil
+	}
+	return &bucket{tx: b.tx, id: child}
+}
+
+// NestedReadWriteBucket retrieves a nested bucket with the given key.  Returns
+// nil if the bucket does not exist.
+//
+// This function is part of the walletdb.ReadWriteBucket interface implementation.
+func (b *bucket) NestedReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	nested := b.nested(key)
+	// Don't return a non-nil interface to a nil pointer.
+	if nested == nil {
+		return nil
+	}
+	return nested
+}
+
+func (b *bucket) NestedReadBucket(key []byte) walletdb.ReadBucket {
+	return b.NestedReadWriteBucket(key)
+}
+
+// CreateBucket creates and returns a new nested bucket with the given key.
+// Returns ErrBucketExists if the bucket already exists, ErrBucketNameRequired
+// if the key is empty, or ErrIncompatibleValue if the key value is otherwise
+// invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	if !b.tx.writable {
+		return nil, walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	_, _, isBucket, ok, err := b.lookup(key)
+	switch {
+	case err != nil:
+		return nil, err
+	case isBucket:
+		return nil, walletdb.ErrBucketExists
+	case ok:
+		return nil, walletdb.ErrIncompatibleValue
+	}
+
+	b.tx.seq++
+	id := b.tx.seq
+	stored := append([]byte{bucketTag}, encodeID(id)...)
+	b.tx.writes[b.tx.db.bucketKey(b.id, key)] = stored
+	return &bucket{tx: b.tx, id: id}, nil
+}
+
+// CreateBucketIfNotExists creates and returns a new nested bucket with the
+// given key if it does not already exist.  Returns ErrBucketNameRequired if the
+// key is empty or ErrIncompatibleValue if the key value is otherwise invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucketIfNotExists(key []byte) (walletdb.ReadWriteBucket, error) {
+	if !b.tx.writable {
+		return nil, walletdb.ErrTxNotWritable
+	}
+	_, child, isBucket, _, err := b.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if isBucket {
+		return &bucket{tx: b.tx, id: child}, nil
+	}
+	return b.CreateBucket(key)
+}
+
+// DeleteNestedBucket removes a nested bucket with the given key.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction and
+// ErrBucketNotFound if the specified bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) DeleteNestedBucket(key []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	_, child, isBucket, ok, err := b.lookup(key)
+	switch {
+	case err != nil:
+		return err
+	case !ok && len(key) == 0:
+		return walletdb.ErrIncompatibleValue
+	case !ok:
+		return walletdb.ErrBucketNotFound
+	case !isBucket:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	// Delete the bucket and all buckets nested in it.
+	ids := []uint64{child}
+	for len(ids) != 0 {
+		id := ids[len(ids)-1]
+		ids = ids[:len(ids)-1]
+
+		entries, err := b.tx.entries(id)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if _, nested, isBucket := decode(e.stored); isBucket {
+				ids = append(ids, nested)
+			}
+		}
+		b.tx.deleteBucket(id)
+	}
+
+	b.tx.writes[b.tx.db.bucketKey(b.id, key)] = nil
+	return nil
+}
+
+// ForEach invokes the passed function with every key/value pair in the bucket.
+// This includes nested buckets, in which case the value is nil, but it does not
+// include the key/value pairs within those nested buckets.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ForEach(fn func(k, v []byte) error) error {
+	entries, err := b.tx.entries(b.id)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		value, _, _ := decode(e.stored)
+		if err := fn(e.key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put saves the specified key/value pair to the bucket.  Keys that do not
+// already exist are added and keys that already exist are overwritten.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Put(key, value []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return walletdb.ErrKeyRequired
+	}
+	_, _, isBucket, _, err := b.lookup(key)
+	if err != nil {
+		return err
+	}
+	if isBucket {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	stored := append([]byte{valueTag}, value...)
+	b.tx.writes[b.tx.db.bucketKey(b.id, key)] = stored
+	return nil
+}
+
+// Get returns the value for the given key.  Returns nil if the key does
+// not exist in this bucket (or nested buckets).
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Get(key []byte) []byte {
+	value, _, _, _, err := b.lookup(key)
+	if err != nil {
+		b.tx.setErr(err)
+		return nil
+	}
+	return value
+}
+
+// Delete removes the specified key from the bucket.  Deleting a key that does
+// not exist does not return an error.  Returns ErrTxNotWritable if attempted
+// against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	_, _, isBucket, _, err := b.lookup(key)
+	if err != nil {
+		return err
+	}
+	if isBucket {
+		return walletdb.ErrIncompatibleValue
+	}
+	b.tx.writes[b.tx.db.bucketKey(b.id, key)] = nil
+	return nil
+}
+
+func (b *bucket) ReadCursor() walletdb.ReadCursor {
+	return b.ReadWriteCursor()
+}
+
+// ReadWriteCursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs and nested buckets in forward or backward order.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ReadWriteCursor() walletdb.ReadWriteCursor {
+	entries, err := b.tx.entries(b.id)
+	if err != nil {
+		b.tx.setErr(err)
+	}
+	return &cursor{bucket: b, entries: entries, pos: -1}
+}
+
+// cursor iterates the key/value pairs and nested buckets of a bucket, which are
+// read when the cursor is created.  Values written to the bucket afterwards are
+// not iterated.
+type cursor struct {
+	bucket  *bucket
+	entries []entry
+	pos     int
+
+	// deleted is set after deleting the entry at pos, which now holds
+	// the entry following it.
+	deleted bool
+}
+
+// at moves the cursor to the entry at pos and returns it.  Nil is returned
+// when moving before the first or after the last entry.
+func (c *cursor) at(pos int) (key, value []byte) {
+	c.deleted = false
+	switch {
+	case pos < 0:
+		c.pos = -1
+		return nil, nil
+	case pos >= len(c.entries):
+		c.pos = len(c.entries)
+		return nil, nil
+	}
+	c.pos = pos
+	value, _, _ = decode(c.entries[pos].stored)
+	return c.entries[pos].key, value
+}
+
+// Delete removes the current key/value pair the cursor is at without
+// invalidating the cursor. Returns ErrTxNotWritable if attempted on a read-only
+// transaction, or ErrIncompatibleValue if attempted when the cursor points to a
+// nested bucket.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Delete() error {
+	if c.deleted || c.pos < 0 || c.pos >= len(c.entries) {
+		return nil
+	}
+	if err := c.bucket.Delete(c.entries[c.pos].key); err != nil {
+		return err
+	}
+	c.entries = append(c.entries[:c.pos], c.entries[c.pos+1:]...)
+	c.deleted = true
+	return nil
+}
+
+// First positions the cursor at the first key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) First() (key, value []byte) {
+	return c.at(0)
+}
+
+// Last positions the cursor at the last key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Last() (key, value []byte) {
+	return c.at(len(c.entries) - 1)
+}
+
+// Next moves the cursor one key/value pair forward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Next() (key, value []byte) {
+	if c.deleted {
+		return c.at(c.pos)
+	}
+	return c.at(c.pos + 1)
+}
+
+// Prev moves the cursor one key/value pair backward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Prev() (key, value []byte) {
+	return c.at(c.pos - 1)
+}
+
+// Seek positions the cursor at the passed seek key. If the key does not exist,
+// the cursor is moved to the next key after seek. Returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	return c.at(sort.Search(len(c.entries), func(i int) bool {
+		return bytes.Compare(c.entries[i].key, seek) >= 0
+	}))
+}
+
+// db represents a collection of namespaces which are persisted and implements
+// the walletdb.Db interface.  All database access is performed through
+// transactions which are obtained through the specific Namespace.
+type db struct {
+	client *clientv3.Client
+	prefix string
+
+	// writeMtx serializes the read-write transactions of the process.
+	writeMtx sync.Mutex
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+// Enforce db implements the walletdb.Db interface.
+var _ walletdb.DB = (*db)(nil)
+
+// context returns the context of a request to the etcd cluster.
+func (db *db) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
+
+// bucketPrefix returns the key prefix of the pairs of the bucket with the
+// given id.
+func (db *db) bucketPrefix(id uint64) string {
+	return db.prefix + string(encodeID(id))
+}
+
+// bucketKey returns the etcd key of key in the bucket with the given id.
+func (db *db) bucketKey(id uint64, key []byte) string {
+	return db.bucketPrefix(id) + string(key)
+}
+
+func (db *db) beginTx(writable bool) (*transaction, error) {
+	db.mtx.Lock()
+	closed := db.closed
+	db.mtx.Unlock()
+	if closed {
+		return nil, walletdb.ErrDbNotOpen
+	}
+
+	if writable {
+		db.writeMtx.Lock()
+	}
+
+	// The revision of the response is the revision of the snapshot the
+	// transaction reads.
+	ctx, cancel := db.context()
+	defer cancel()
+	resp, err := db.client.Get(ctx, db.bucketKey(metaBucket, seqKey))
+	if err == nil && len(resp.Kvs) == 0 {
+		err = walletdb.ErrInvalid
+	}
+	if err != nil {
+		if writable {
+			db.writeMtx.Unlock()
+		}
+		return nil, err
+	}
+
+	tx := &transaction{
+		db:       db,
+		writable: writable,
+		rev:      resp.Header.Revision,
+		seq:      binary.BigEndian.Uint64(resp.Kvs[0].Value),
+	}
+	if writable {
+		tx.writes = make(map[string][]byte)
+	}
+	return tx, nil
+}
+
+func (db *db) BeginReadTx() (walletdb.ReadTx, error) {
+	return db.beginTx(false)
+}
+
+func (db *db) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
+	return db.beginTx(true)
+}
+
+// Batch executes f in its own read-write transaction, as write transactions of
+// this driver are not coalesced.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Batch(f func(tx walletdb.ReadWriteTx) error) error {
+	return walletdb.Update(db, f)
+}
+
+// Copy is not supported, as etcd databases are backed up with snapshots of the
+// etcd cluster.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Copy(w io.Writer) error {
+	return errCopyUnsupported
+}
+
+// Close cleanly shuts down the database and syncs all data.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Close() error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	if db.closed {
+		return walletdb.ErrDbNotOpen
+	}
+	db.closed = true
+	return db.client.Close()
+}
+
+// openDB opens the database with the provided key prefix.
+// walletdb.ErrDbDoesNotExist is returned if the database doesn't exist and the
+// create flag is not set.
+func openDB(cfg clientv3.Config, prefix string, create bool) (walletdb.DB, error) {
+	client, err := clientv3.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := &db{client: client, prefix: prefix}
+
+	ctx, cancel := db.context()
+	defer cancel()
+	seq := db.bucketKey(metaBucket, seqKey)
+	if create {
+		_, err = client.Txn(ctx).If(
+			clientv3.Compare(clientv3.CreateRevision(seq), "=", 0),
+		).Then(
+			clientv3.OpPut(seq, string(encodeID(rootBucket))),
+		).Commit()
+	} else {
+		var resp *clientv3.GetResponse
+		resp, err = client.Get(ctx, seq)
+		if err == nil && len(resp.Kvs) == 0 {
+			err = walletdb.ErrDbDoesNotExist
+		}
+	}
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return db, nil
+}
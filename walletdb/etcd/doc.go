@@ -0,0 +1,43 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package etcd implements an instance of walletdb that uses etcd for the backing
+datastore.  This allows active/standby wallet deployments to fail over to
+another host without copying the database file.
+
+The key/value pairs of a bucket are stored under a key prefix of the bucket,
+where the value of a nested bucket is replaced by the id of the bucket.
+Transactions read a snapshot of the database at the revision of their start.
+Read-write transactions buffer their writes and apply them atomically on commit,
+unless the database was modified by another process since the transaction
+started, in which case committing fails with ErrConflict.  A wallet which lost
+its lease to a standby therefore can not commit transactions based on stale
+state, such as spending outputs spent by the standby.
+
+As every commit is a single etcd transaction, the etcd cluster must allow
+transactions of as many operations as a wallet commits at once, which is set
+with the --max-txn-ops and --max-request-bytes options of etcd.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "etcd".  The Open and Create functions take the configuration of the
+etcd client and the key prefix of the database:
+
+	cfg := clientv3.Config{Endpoints: []string{"localhost:2379"}}
+	db, err := walletdb.Open("etcd", cfg, "btcwallet/mainnet/")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Create("etcd", cfg, "btcwallet/mainnet/")
+	if err != nil {
+		// Handle error
+	}
+
+Open returns walletdb.ErrDbDoesNotExist if no database was created with the
+prefix.
+*/
+package etcd
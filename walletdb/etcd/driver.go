@@ -0,0 +1,76 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/coreos/etcd/clientv3"
+)
+
+const (
+	dbType = "etcd"
+)
+
+// parseArgs parses the arguments from the walletdb Open/Create methods.
+func parseArgs(funcName string, args ...interface{}) (clientv3.Config, string, error) {
+	if len(args) != 2 {
+		return clientv3.Config{}, "", fmt.Errorf("invalid arguments to "+
+			"%s.%s -- expected etcd client config and key prefix",
+			dbType, funcName)
+	}
+
+	cfg, ok := args[0].(clientv3.Config)
+	if !ok {
+		return clientv3.Config{}, "", fmt.Errorf("first argument to "+
+			"%s.%s is invalid -- expected etcd client config",
+			dbType, funcName)
+	}
+
+	prefix, ok := args[1].(string)
+	if !ok {
+		return clientv3.Config{}, "", fmt.Errorf("second argument to "+
+			"%s.%s is invalid -- expected key prefix string",
+			dbType, funcName)
+	}
+
+	return cfg, prefix, nil
+}
+
+// openDBDriver is the callback provided during driver registration that opens
+// an existing database for use.
+func openDBDriver(args ...interface{}) (walletdb.DB, error) {
+	cfg, prefix, err := parseArgs("Open", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return openDB(cfg, prefix, false)
+}
+
+// createDBDriver is the callback provided during driver registration that
+// creates, initializes, and opens a database for use.
+func createDBDriver(args ...interface{}) (walletdb.DB, error) {
+	cfg, prefix, err := parseArgs("Create", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return openDB(cfg, prefix, true)
+}
+
+func init() {
+	// Register the driver.
+	driver := walletdb.Driver{
+		DbType: dbType,
+		Create: createDBDriver,
+		Open:   openDBDriver,
+	}
+	if err := walletdb.RegisterDriver(driver); err != nil {
+		panic(fmt.Sprintf("Failed to regiser database driver '%s': %v",
+			dbType, err))
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package etcd_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/walletdb/etcd"
+	"github.com/coreos/etcd/clientv3"
+)
+
+// dbType is the database type name for this driver.
+const dbType = "etcd"
+
+// testConfig returns the configuration of the etcd client connecting to the
+// cluster the tests run against, whose endpoints are set with the
+// BTCWALLET_ETCD_TEST_ENDPOINTS environment variable, and a key prefix unique
+// to the test.  The test is skipped if the endpoints are not set.
+func testConfig(t *testing.T) (clientv3.Config, string) {
+	endpoints := os.Getenv("BTCWALLET_ETCD_TEST_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("BTCWALLET_ETCD_TEST_ENDPOINTS is not set")
+	}
+	cfg := clientv3.Config{
+		Endpoints:   strings.Split(endpoints, ","),
+		DialTimeout: 5 * time.Second,
+	}
+	prefix := fmt.Sprintf("btcwallet-test/%s/%d/", t.Name(),
+		time.Now().UnixNano())
+	return cfg, prefix
+}
+
+// deletePrefix deletes all keys with the prefix.
+func deletePrefix(t *testing.T, cfg clientv3.Config, prefix string) {
+	client, err := clientv3.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	_, err = client.Delete(context.Background(), prefix, clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCreateOpenFail ensures that errors related to creating and opening a
+// database are handled properly.
+func TestCreateOpenFail(t *testing.T) {
+	// Ensure that attempting to open a database with the wrong number of
+	// parameters returns the expected error.
+	wantErr := fmt.Errorf("invalid arguments to %s.Open -- expected "+
+		"etcd client config and key prefix", dbType)
+	if _, err := walletdb.Open(dbType, 1, 2, 3); err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database with an invalid type for
+	// the first parameter returns the expected error.
+	wantErr = fmt.Errorf("first argument to %s.Open is invalid -- "+
+		"expected etcd client config", dbType)
+	if _, err := walletdb.Open(dbType, 1, ""); err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database with the wrong number of
+	// parameters returns the expected error.
+	wantErr = fmt.Errorf("invalid arguments to %s.Create -- expected "+
+		"etcd client config and key prefix", dbType)
+	if _, err := walletdb.Create(dbType, 1, 2, 3); err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database with an invalid type for
+	// the first parameter returns the expected error.
+	wantErr = fmt.Errorf("second argument to %s.Create is invalid -- "+
+		"expected key prefix string", dbType)
+	_, err := walletdb.Create(dbType, clientv3.Config{}, 1)
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database that doesn't exist returns
+	// the expected error.
+	cfg, prefix := testConfig(t)
+	defer deletePrefix(t, cfg, prefix)
+	wantErr = walletdb.ErrDbDoesNotExist
+	if _, err := walletdb.Open(dbType, cfg, prefix); err != wantErr {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure operations against a closed database return the expected
+	// error.
+	db, err := walletdb.Create(dbType, cfg, prefix)
+	if err != nil {
+		t.Errorf("Create: unexpected error: %v", err)
+		return
+	}
+	db.Close()
+
+	wantErr = walletdb.ErrDbNotOpen
+	if _, err := db.BeginReadTx(); err != wantErr {
+		t.Errorf("Namespace: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+}
+
+// TestPersistence ensures that values stored are still valid after closing and
+// reopening the database.
+func TestPersistence(t *testing.T) {
+	// Create a new database to run tests against.
+	cfg, prefix := testConfig(t)
+	defer deletePrefix(t, cfg, prefix)
+	db, err := walletdb.Create(dbType, cfg, prefix)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+
+	// Create a namespace and put some values into it so they can be tested
+	// for existence on re-open.
+	storeValues := map[string]string{
+		"ns1key1": "foo1",
+		"ns1key2": "foo2",
+		"ns1key3": "foo3",
+	}
+	ns1Key := []byte("ns1")
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns1, err := tx.CreateTopLevelBucket(ns1Key)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range storeValues {
+			if err := ns1.Put([]byte(k), []byte(v)); err != nil {
+				return fmt.Errorf("Put: unexpected error: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ns1 Update: unexpected error: %v", err)
+		return
+	}
+
+	// Close and reopen the database to ensure the values persist.
+	db.Close()
+	db, err = walletdb.Open(dbType, cfg, prefix)
+	if err != nil {
+		t.Errorf("Failed to open test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+
+	// Ensure the values previously stored in the 3rd namespace still exist
+	// and are correct.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns1 := tx.ReadBucket(ns1Key)
+		if ns1 == nil {
+			return fmt.Errorf("ReadTx.ReadBucket: unexpected nil root bucket")
+		}
+
+		for k, v := range storeValues {
+			gotVal := ns1.Get([]byte(k))
+			if !reflect.DeepEqual(gotVal, []byte(v)) {
+				return fmt.Errorf("Get: key '%s' does not "+
+					"match expected value - got %s, want %s",
+					k, gotVal, v)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ns1 View: unexpected error: %v", err)
+		return
+	}
+}
+
+// TestConflict ensures that read-write transactions fail to commit after
+// another process committed changes since they started.
+func TestConflict(t *testing.T) {
+	cfg, prefix := testConfig(t)
+	defer deletePrefix(t, cfg, prefix)
+	db, err := walletdb.Create(dbType, cfg, prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	standby, err := walletdb.Open(dbType, cfg, prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer standby.Close()
+
+	tx, err := db.BeginReadWriteTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.CreateTopLevelBucket([]byte("active")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = walletdb.Update(standby, func(tx walletdb.ReadWriteTx) error {
+		_, err := tx.CreateTopLevelBucket([]byte("standby"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != etcd.ErrConflict {
+		t.Fatalf("Commit: did not receive expected error - got %v, "+
+			"want %v", err, etcd.ErrConflict)
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		if tx.ReadBucket([]byte("active")) != nil {
+			return fmt.Errorf("conflicting commit created bucket")
+		}
+		if tx.ReadBucket([]byte("standby")) == nil {
+			return fmt.Errorf("missing bucket of the standby")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
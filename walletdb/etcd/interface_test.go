@@ -0,0 +1,55 @@
+// Copyright (c) 2014 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file intended to be copied into each backend driver directory.  Each
+// driver should have their own driver_test.go file which creates a database and
+// invokes the testInterface function in this file to ensure the driver properly
+// implements the interface.  See the bdb backend driver for a working example.
+//
+// NOTE: When copying this file into the backend driver folder, the package name
+// will need to be changed accordingly.
+
+package etcd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/walletdb/walletdbtest"
+	"github.com/coreos/etcd/clientv3"
+)
+
+// testDBType is the type of the test driver creating etcd databases with the
+// client config and key prefix of TestInterface, as the interface tests only
+// pass a database path to the driver.
+const testDBType = "etcdtest"
+
+var (
+	testCfg    clientv3.Config
+	testPrefix string
+)
+
+func init() {
+	driver := walletdb.Driver{
+		DbType: testDBType,
+		Create: func(args ...interface{}) (walletdb.DB, error) {
+			return walletdb.Create(dbType, testCfg, testPrefix)
+		},
+		Open: func(args ...interface{}) (walletdb.DB, error) {
+			return walletdb.Open(dbType, testCfg, testPrefix)
+		},
+	}
+	if err := walletdb.RegisterDriver(driver); err != nil {
+		panic(fmt.Sprintf("Failed to regiser database driver '%s': %v",
+			testDBType, err))
+	}
+}
+
+// TestInterface performs all interfaces tests for this database driver.
+func TestInterface(t *testing.T) {
+	testCfg, testPrefix = testConfig(t)
+	defer deletePrefix(t, testCfg, testPrefix)
+	walletdbtest.TestInterface(t, testDBType, "")
+}
@@ -16,6 +16,10 @@ type ReadTx interface {
 	// described by the key does not exist, nil is returned.
 	ReadBucket(key []byte) ReadBucket
 
+	// ForEachBucket invokes the passed function with the key of every top
+	// level bucket of the database.
+	ForEachBucket(func(key []byte) error) error
+
 	// Rollback closes the transaction, discarding changes (if any) if the
 	// database was modified by a write transaction.
 	Rollback() error
@@ -176,6 +180,16 @@ type DB interface {
 	// BeginReadWriteTx opens a database read+write transaction.
 	BeginReadWriteTx() (ReadWriteTx, error)
 
+	// Batch executes the function f in a read+write transaction which may
+	// be shared with the functions of concurrent calls to Batch, so many
+	// small writes share the cost of a single commit.  Batch returns once
+	// the transaction is committed, or the error of f.  Unlike Update, f
+	// may be executed more than once if the function of another caller
+	// fails, so it should not have effects outside of the transaction.
+	// Drivers which do not coalesce writes execute f in its own
+	// transaction.
+	Batch(f func(tx ReadWriteTx) error) error
+
 	// Copy writes a copy of the database to the provided writer.  This
 	// call will start a read-only transaction to perform all operations.
 	Copy(w io.Writer) error
@@ -241,6 +255,11 @@ type Driver struct {
 	// arguments to open the database.  This function must return
 	// ErrDbDoesNotExist if the database has not already been created.
 	Open func(args ...interface{}) (DB, error)
+
+	// OpenReadOnly is the optional function invoked instead of Open to
+	// open the database in read-only mode, such as without taking the
+	// locks of writers.  Drivers without it are opened with Open.
+	OpenReadOnly func(args ...interface{}) (DB, error)
 }
 
 // driverList holds all of the registered database backends.
@@ -283,8 +302,8 @@ func Create(dbType string, args ...interface{}) (DB, error) {
 }
 
 // Open opens an existing database for the specified type.  The arguments are
-// specific to the database type driver.  See the documentation for the database
-// driver for further details.
+// specific to the database type driver, and may be followed by OpenOptions.
+// See the documentation for the database driver for further details.
 //
 // ErrDbUnknownType will be returned if the the database type is not registered.
 func Open(dbType string, args ...interface{}) (DB, error) {
@@ -293,5 +312,18 @@ func Open(dbType string, args ...interface{}) (DB, error) {
 		return nil, ErrDbUnknownType
 	}
 
-	return drv.Open(args...)
+	args, opts := splitOpenOptions(args)
+	if !opts.ReadOnly {
+		return drv.Open(args...)
+	}
+
+	open := drv.Open
+	if drv.OpenReadOnly != nil {
+		open = drv.OpenReadOnly
+	}
+	db, err := open(args...)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyDB{db}, nil
 }
@@ -0,0 +1,361 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// dumpMagic begins every dump written by Dump, followed by the version of the
+// dump format.
+var dumpMagic = []byte("walletdb dump\n")
+
+// dumpVersion is the version of the dump format.
+const dumpVersion = 1
+
+// Records of a dump.  The contents of a bucket are a sequence of value and
+// bucket records, where every bucket record is followed by the contents of the
+// nested bucket and an end record.  A dump is a sequence of bucket records of
+// the top-level buckets, each followed by the checksum of the bucket, and ends
+// with an end record.
+const (
+	// recordValue is followed by a key and a value.
+	recordValue byte = 0
+
+	// recordBucket is followed by the key of a nested bucket.
+	recordBucket byte = 1
+
+	// recordEnd ends the contents of a bucket or the dump.
+	recordEnd byte = 2
+)
+
+// maxRecordSize is the maximum size of a key or value read from a dump.
+const maxRecordSize = 1 << 30
+
+var (
+	// ErrMalformedDump is returned when restoring a dump which was not
+	// written by Dump.
+	ErrMalformedDump = errors.New("malformed database dump")
+
+	// ErrChecksumMismatch is returned when the checksum of a copied bucket
+	// does not match the checksum of the original bucket.
+	ErrChecksumMismatch = errors.New("bucket checksum mismatch")
+)
+
+// Checksum is the SHA-256 checksum of the contents of a bucket, including all
+// nested buckets.  The checksums of equal buckets of different databases
+// match, regardless of the backend storing them.
+type Checksum [sha256.Size]byte
+
+// dumpWriter writes the records of a dump.
+type dumpWriter struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (d *dumpWriter) write(b []byte) {
+	if d.err == nil {
+		_, d.err = d.w.Write(b)
+	}
+}
+
+func (d *dumpWriter) writeRecord(record byte) {
+	d.write([]byte{record})
+}
+
+func (d *dumpWriter) writeBytes(b []byte) {
+	d.write(d.buf[:binary.PutUvarint(d.buf[:], uint64(len(b)))])
+	d.write(b)
+}
+
+// writeContents writes the contents of the bucket.
+func (d *dumpWriter) writeContents(b ReadBucket) error {
+	err := b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if nested := b.NestedReadBucket(k); nested != nil {
+				d.writeRecord(recordBucket)
+				d.writeBytes(k)
+				if err := d.writeContents(nested); err != nil {
+					return err
+				}
+				d.writeRecord(recordEnd)
+				return d.err
+			}
+		}
+		d.writeRecord(recordValue)
+		d.writeBytes(k)
+		d.writeBytes(v)
+		return d.err
+	})
+	if err != nil {
+		return err
+	}
+	return d.err
+}
+
+// checksum returns the checksum of the contents of the bucket.
+func checksum(b ReadBucket) (Checksum, error) {
+	var sum Checksum
+	h := sha256.New()
+	if err := (&dumpWriter{w: h}).writeContents(b); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// Checksums returns the checksums of all top-level buckets of the database by
+// the keys of the buckets.
+func Checksums(db DB) (map[string]Checksum, error) {
+	sums := make(map[string]Checksum)
+	err := View(db, func(tx ReadTx) error {
+		return tx.ForEachBucket(func(key []byte) error {
+			sum, err := checksum(tx.ReadBucket(key))
+			if err != nil {
+				return err
+			}
+			sums[string(key)] = sum
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// Dump writes all buckets and key/value pairs of the database to w, in a format
+// independent of the backend of the database which is read by Restore.
+func Dump(db DB, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	d := &dumpWriter{w: bw}
+	d.write(dumpMagic)
+	d.writeRecord(dumpVersion)
+
+	err := View(db, func(tx ReadTx) error {
+		return tx.ForEachBucket(func(key []byte) error {
+			d.writeRecord(recordBucket)
+			d.writeBytes(key)
+
+			// Write the contents of the bucket both to the dump
+			// and its checksum.
+			h := sha256.New()
+			d.w = io.MultiWriter(bw, h)
+			err := d.writeContents(tx.ReadBucket(key))
+			d.w = bw
+			if err != nil {
+				return err
+			}
+			d.writeRecord(recordEnd)
+			d.write(h.Sum(nil))
+			return d.err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	d.writeRecord(recordEnd)
+	if d.err != nil {
+		return d.err
+	}
+	return bw.Flush()
+}
+
+// dumpReader reads the records of a dump.
+type dumpReader struct {
+	r *bufio.Reader
+}
+
+func (d *dumpReader) readFull(b []byte) error {
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *dumpReader) readRecord() (byte, error) {
+	var record [1]byte
+	err := d.readFull(record[:])
+	return record[0], err
+}
+
+func (d *dumpReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(d.r)
+	switch {
+	case err == io.EOF:
+		return nil, io.ErrUnexpectedEOF
+	case err != nil:
+		return nil, err
+	}
+	if n > maxRecordSize {
+		return nil, ErrMalformedDump
+	}
+	b := make([]byte, n)
+	return b, d.readFull(b)
+}
+
+// readContents reads the contents of a bucket and writes them to b.
+func (d *dumpReader) readContents(b ReadWriteBucket) error {
+	for {
+		record, err := d.readRecord()
+		if err != nil {
+			return err
+		}
+
+		switch record {
+		case recordValue:
+			k, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			if err := b.Put(k, v); err != nil {
+				return err
+			}
+
+		case recordBucket:
+			k, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			nested, err := b.CreateBucket(k)
+			if err != nil {
+				return err
+			}
+			if err := d.readContents(nested); err != nil {
+				return err
+			}
+
+		case recordEnd:
+			return nil
+
+		default:
+			return ErrMalformedDump
+		}
+	}
+}
+
+// Restore writes the buckets and key/value pairs dumped by Dump to the
+// database, which must not contain any of the dumped top-level buckets.  The
+// checksums of the restored buckets are verified against the checksums in the
+// dump, and nothing is restored if any of them do not match.
+func Restore(db DB, r io.Reader) error {
+	d := &dumpReader{r: bufio.NewReader(r)}
+	header := make([]byte, len(dumpMagic)+1)
+	if err := d.readFull(header); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:len(dumpMagic)], dumpMagic) ||
+		header[len(dumpMagic)] != dumpVersion {
+
+		return ErrMalformedDump
+	}
+
+	return Update(db, func(tx ReadWriteTx) error {
+		for {
+			record, err := d.readRecord()
+			if err != nil {
+				return err
+			}
+			switch record {
+			case recordBucket:
+			case recordEnd:
+				return nil
+			default:
+				return ErrMalformedDump
+			}
+
+			key, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			b, err := tx.CreateTopLevelBucket(key)
+			if err != nil {
+				return err
+			}
+
+			// The restored bucket must match the checksum of the
+			// dumped bucket.
+			if err := d.readContents(b); err != nil {
+				return err
+			}
+			var want Checksum
+			if err := d.readFull(want[:]); err != nil {
+				return err
+			}
+			sum, err := checksum(b)
+			if err != nil {
+				return err
+			}
+			if sum != want {
+				return ErrChecksumMismatch
+			}
+		}
+	})
+}
+
+// Migrate copies all buckets and key/value pairs of src to dst, which must not
+// contain any of the top-level buckets of src.  After the copy is committed,
+// the checksums of the copied buckets are compared with the buckets of src,
+// and ErrChecksumMismatch is returned if any differ.
+func Migrate(dst, src DB) error {
+	err := View(src, func(srcTx ReadTx) error {
+		return Update(dst, func(dstTx ReadWriteTx) error {
+			return srcTx.ForEachBucket(func(key []byte) error {
+				b, err := dstTx.CreateTopLevelBucket(key)
+				if err != nil {
+					return err
+				}
+				return copyBucket(b, srcTx.ReadBucket(key))
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	srcSums, err := Checksums(src)
+	if err != nil {
+		return err
+	}
+	dstSums, err := Checksums(dst)
+	if err != nil {
+		return err
+	}
+	for key, sum := range srcSums {
+		if dstSums[key] != sum {
+			return ErrChecksumMismatch
+		}
+	}
+	return nil
+}
+
+// copyBucket copies the contents of src to dst.
+func copyBucket(dst ReadWriteBucket, src ReadBucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if nested := src.NestedReadBucket(k); nested != nil {
+				b, err := dst.CreateBucket(k)
+				if err != nil {
+					return err
+				}
+				return copyBucket(b, nested)
+			}
+		}
+		return dst.Put(k, v)
+	})
+}
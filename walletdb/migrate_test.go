@@ -0,0 +1,152 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+)
+
+// createMigrateDBs creates n empty bdb databases in a temporary directory.
+func createMigrateDBs(t *testing.T, n int) ([]walletdb.DB, func()) {
+	dirName, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("Failed to create db temp dir: %v", err)
+	}
+	var dbs []walletdb.DB
+	teardown := func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+		os.RemoveAll(dirName)
+	}
+	for i := 0; i < n; i++ {
+		dbPath := filepath.Join(dirName, fmt.Sprintf("%d.db", i))
+		db, err := walletdb.Create("bdb", dbPath)
+		if err != nil {
+			teardown()
+			t.Fatal(err)
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, teardown
+}
+
+// TestMigrate ensures databases are copied by Dump and Restore and by Migrate,
+// and that the checksums of the copies match the original.
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	dbs, teardown := createMigrateDBs(t, 3)
+	defer teardown()
+	src, restored, migrated := dbs[0], dbs[1], dbs[2]
+
+	err := walletdb.Update(src, func(tx walletdb.ReadWriteTx) error {
+		for _, ns := range []string{"ns1", "ns2"} {
+			b, err := tx.CreateTopLevelBucket([]byte(ns))
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte("key"), []byte(ns)); err != nil {
+				return err
+			}
+			if err := b.Put([]byte("empty"), nil); err != nil {
+				return err
+			}
+			nested, err := b.CreateBucket([]byte("nested"))
+			if err != nil {
+				return err
+			}
+			err = nested.Put([]byte("nestedkey"), []byte("nestedvalue"))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := walletdb.Checksums(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 2 || want["ns1"] == want["ns2"] {
+		t.Fatalf("unexpected checksums %x", want)
+	}
+
+	var dump bytes.Buffer
+	if err := walletdb.Dump(src, &dump); err != nil {
+		t.Fatalf("Dump: unexpected error: %v", err)
+	}
+	dumped := dump.Bytes()
+	if err := walletdb.Restore(restored, bytes.NewReader(dumped)); err != nil {
+		t.Fatalf("Restore: unexpected error: %v", err)
+	}
+	if err := walletdb.Migrate(migrated, src); err != nil {
+		t.Fatalf("Migrate: unexpected error: %v", err)
+	}
+
+	for _, db := range []walletdb.DB{restored, migrated} {
+		got, err := walletdb.Checksums(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("checksums of copy do not match - got %x, "+
+				"want %x", got, want)
+		}
+	}
+	err = walletdb.View(restored, func(tx walletdb.ReadTx) error {
+		nested := tx.ReadBucket([]byte("ns2")).NestedReadBucket(
+			[]byte("nested"),
+		)
+		if nested == nil {
+			return fmt.Errorf("nested bucket was not restored")
+		}
+		v := nested.Get([]byte("nestedkey"))
+		if !bytes.Equal(v, []byte("nestedvalue")) {
+			return fmt.Errorf("unexpected nested value %s", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring buckets which already exist fails, and so does restoring
+	// a corrupted dump, without restoring any buckets.
+	err = walletdb.Restore(restored, bytes.NewReader(dumped))
+	if err != walletdb.ErrBucketExists {
+		t.Fatalf("Restore: did not receive expected error - got %v, "+
+			"want %v", err, walletdb.ErrBucketExists)
+	}
+	dbs, teardown = createMigrateDBs(t, 1)
+	defer teardown()
+	corrupted := append([]byte(nil), dumped...)
+	i := bytes.Index(corrupted, []byte("nestedvalue"))
+	corrupted[i] ^= 1
+	err = walletdb.Restore(dbs[0], bytes.NewReader(corrupted))
+	if err != walletdb.ErrChecksumMismatch {
+		t.Fatalf("Restore: did not receive expected error - got %v, "+
+			"want %v", err, walletdb.ErrChecksumMismatch)
+	}
+	if sums, _ := walletdb.Checksums(dbs[0]); len(sums) != 0 {
+		t.Fatalf("corrupted dump restored buckets %x", sums)
+	}
+	err = walletdb.Restore(dbs[0], bytes.NewReader(dumped[1:]))
+	if err != walletdb.ErrMalformedDump {
+		t.Fatalf("Restore: did not receive expected error - got %v, "+
+			"want %v", err, walletdb.ErrMalformedDump)
+	}
+}
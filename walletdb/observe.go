@@ -0,0 +1,111 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb
+
+import "time"
+
+// TxObserver is called with the duration of each transaction of an observed
+// database, from when it began until it was committed or rolled back, and
+// whether it was a read-write transaction.
+type TxObserver func(write bool, d time.Duration)
+
+// observedDB is a database reporting the duration of its transactions.
+type observedDB struct {
+	DB
+	observe TxObserver
+}
+
+// Observe returns a database calling observe with the duration of every
+// transaction of db, including those of View, Update and Batch.  The function
+// is called after a transaction ends, and must be safe for concurrent use.
+func Observe(db DB, observe TxObserver) DB {
+	return &observedDB{DB: db, observe: observe}
+}
+
+// Unwrap returns the database wrapped by Observe, or db itself if it is not
+// observed.  This allows checking the type of the underlying database.
+func Unwrap(db DB) DB {
+	if o, ok := db.(*observedDB); ok {
+		return o.DB
+	}
+	return db
+}
+
+// BeginReadTx opens a database read transaction.
+//
+// This function is part of the DB interface implementation.
+func (db *observedDB) BeginReadTx() (ReadTx, error) {
+	start := time.Now()
+	tx, err := db.DB.BeginReadTx()
+	if err != nil {
+		return nil, err
+	}
+	return &observedReadTx{ReadTx: tx, db: db, start: start}, nil
+}
+
+// BeginReadWriteTx opens a database read+write transaction.
+//
+// This function is part of the DB interface implementation.
+func (db *observedDB) BeginReadWriteTx() (ReadWriteTx, error) {
+	start := time.Now()
+	tx, err := db.DB.BeginReadWriteTx()
+	if err != nil {
+		return nil, err
+	}
+	return &observedReadWriteTx{ReadWriteTx: tx, db: db, start: start}, nil
+}
+
+// Batch executes f in a read+write transaction shared with concurrent calls,
+// and observes the time until the shared transaction is committed.
+//
+// This function is part of the DB interface implementation.
+func (db *observedDB) Batch(f func(tx ReadWriteTx) error) error {
+	start := time.Now()
+	err := db.DB.Batch(f)
+	db.observe(true, time.Since(start))
+	return err
+}
+
+// observedReadTx is a read transaction of an observed database.
+type observedReadTx struct {
+	ReadTx
+	db    *observedDB
+	start time.Time
+}
+
+// Rollback closes the transaction and observes its duration.
+//
+// This function is part of the ReadTx interface implementation.
+func (tx *observedReadTx) Rollback() error {
+	err := tx.ReadTx.Rollback()
+	tx.db.observe(false, time.Since(tx.start))
+	return err
+}
+
+// observedReadWriteTx is a read+write transaction of an observed database.
+type observedReadWriteTx struct {
+	ReadWriteTx
+	db    *observedDB
+	start time.Time
+}
+
+// Rollback closes the transaction, discarding its changes, and observes its
+// duration.
+//
+// This function is part of the ReadTx interface implementation.
+func (tx *observedReadWriteTx) Rollback() error {
+	err := tx.ReadWriteTx.Rollback()
+	tx.db.observe(true, time.Since(tx.start))
+	return err
+}
+
+// Commit commits the changes of the transaction and observes its duration.
+//
+// This function is part of the ReadWriteTx interface implementation.
+func (tx *observedReadWriteTx) Commit() error {
+	err := tx.ReadWriteTx.Commit()
+	tx.db.observe(true, time.Since(tx.start))
+	return err
+}
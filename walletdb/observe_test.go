@@ -0,0 +1,82 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+)
+
+// TestObserve ensures the transactions of observed databases are reported.
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	dirName, err := ioutil.TempDir("", "observe")
+	if err != nil {
+		t.Fatalf("Failed to create db temp dir: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+
+	db, err := walletdb.Create("bdb", filepath.Join(dirName, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mu sync.Mutex
+	var reads, writes int
+	observed := walletdb.Observe(db, func(write bool, d time.Duration) {
+		mu.Lock()
+		if write {
+			writes++
+		} else {
+			reads++
+		}
+		mu.Unlock()
+	})
+	if walletdb.Unwrap(observed) != db {
+		t.Fatal("Unwrap did not return the observed database")
+	}
+	if walletdb.IsReadOnly(observed) {
+		t.Fatal("observed database is read-only")
+	}
+
+	err = walletdb.Update(observed, func(tx walletdb.ReadWriteTx) error {
+		_, err := tx.CreateTopLevelBucket([]byte("ns"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = observed.Batch(func(tx walletdb.ReadWriteTx) error {
+		return tx.ReadWriteBucket([]byte("ns")).Put([]byte("k"), []byte("v"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.View(observed, func(tx walletdb.ReadTx) error {
+		if tx.ReadBucket([]byte("ns")).Get([]byte("k")) == nil {
+			t.Error("missing value written by Batch")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if reads != 1 || writes != 2 {
+		t.Errorf("observed %d reads and %d writes, want 1 and 2", reads,
+			writes)
+	}
+}
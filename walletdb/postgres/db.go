@@ -0,0 +1,656 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/lib/pq" // Register the postgres sql driver.
+)
+
+// rootBucket is the id of the bucket holding the top-level buckets.
+const rootBucket = 0
+
+// writeLock is the key of the advisory lock held by read-write transactions.
+const writeLock = 0x77616c6c6574 // "wallet"
+
+// errCopyUnsupported is returned by Copy, as PostgreSQL databases are backed
+// up with the tools of the database server.
+var errCopyUnsupported = errors.New("copying a postgres database is not " +
+	"supported, use pg_dump instead")
+
+// schema creates the tables of a new database.  Every key/value pair belongs to
+// a bucket.  Nested buckets are stored as a key with a NULL value and the id
+// of the nested bucket.
+const schema = `
+CREATE TABLE IF NOT EXISTS buckets (
+	id BIGSERIAL PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS kv (
+	bucket BIGINT NOT NULL,
+	key BYTEA NOT NULL,
+	value BYTEA,
+	child BIGINT,
+	PRIMARY KEY (bucket, key)
+);
+`
+
+// convertErr converts some sql errors to the equivalent walletdb error.
+func convertErr(err error) error {
+	switch {
+	case err == sql.ErrTxDone:
+		return walletdb.ErrTxClosed
+	case err != nil && strings.Contains(err.Error(), "database is closed"):
+		return walletdb.ErrDbNotOpen
+	}
+
+	// Return the original error if none of the above applies.
+	return err
+}
+
+// transaction represents a database transaction.  It can either by read-only or
+// read-write and implements the walletdb Tx interfaces.  The transaction
+// provides a root bucket against which all read and writes occur.
+type transaction struct {
+	db       *db
+	sqlTx    *sql.Tx
+	writable bool
+
+	// err is the first error of an operation which can not return it,
+	// such as Get.  Committing the transaction fails with it.
+	err error
+}
+
+// setErr records the first error of an operation which can not return it.
+func (tx *transaction) setErr(err error) {
+	if tx.err == nil {
+		tx.err = err
+	}
+}
+
+func (tx *transaction) root() *bucket {
+	return &bucket{tx: tx, id: rootBucket}
+}
+
+func (tx *transaction) ReadBucket(key []byte) walletdb.ReadBucket {
+	return tx.ReadWriteBucket(key)
+}
+
+func (tx *transaction) ForEachBucket(fn func(key []byte) error) error {
+	return tx.root().ForEach(func(k, _ []byte) error {
+		return fn(k)
+	})
+}
+
+func (tx *transaction) ReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	b := tx.root().nested(key)
+	if b == nil {
+		return nil
+	}
+	return b
+}
+
+func (tx *transaction) CreateTopLevelBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	return tx.root().CreateBucket(key)
+}
+
+func (tx *transaction) DeleteTopLevelBucket(key []byte) error {
+	return tx.root().DeleteNestedBucket(key)
+}
+
+// Commit commits all changes that have been made through the root bucket and
+// all of its sub-buckets to persistent storage.  The changes are rolled back
+// if an operation of the transaction failed.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Commit() error {
+	if !tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	if tx.err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return tx.err
+	}
+	err := convertErr(tx.sqlTx.Commit())
+	if err != walletdb.ErrTxClosed {
+		tx.db.writeMtx.Unlock()
+	}
+	return err
+}
+
+// Rollback undoes all changes that have been made to the root bucket and all of
+// its sub-buckets.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Rollback() error {
+	err := convertErr(tx.sqlTx.Rollback())
+	if tx.writable && err != walletdb.ErrTxClosed {
+		tx.db.writeMtx.Unlock()
+	}
+	return err
+}
+
+// bucket is an internal type used to represent a collection of key/value pairs
+// and implements the walletdb Bucket interfaces.
+type bucket struct {
+	tx *transaction
+	id int64
+}
+
+// Enforce bucket implements the walletdb Bucket interfaces.
+var _ walletdb.ReadWriteBucket = (*bucket)(nil)
+
+// row returns the value or nested bucket id of key.  ok is false if the key
+// does not exist.
+func (b *bucket) row(key []byte) (value []byte, child sql.NullInt64, ok bool, err error) {
+	err = b.tx.sqlTx.QueryRow(
+		"SELECT value, child FROM kv WHERE bucket = $1 AND key = $2",
+		b.id, key,
+	).Scan(&value, &child)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, child, false, nil
+	case err != nil:
+		return nil, child, false, err
+	}
+	if value == nil && !child.Valid {
+		value = []byte{}
+	}
+	return value, child, true, nil
+}
+
+// nested returns the nested bucket with the given key, or nil if it does not
+// exist.
+func (b *bucket) nested(key []byte) *bucket {
+	_, child, _, err := b.row(key)
+	if err != nil {
+		b.tx.setErr(err)
+		return nil
+	}
+	if !child.Valid {
+		return nil
+	}
+	return &bucket{tx: b.tx, id: child.Int64}
+}
+
+// NestedReadWriteBucket retrieves a nested bucket with the given key.  Returns
+// nil if the bucket does not exist.
+//
+// This function is part of the walletdb.ReadWriteBucket interface implementation.
+func (b *bucket) NestedReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	nested := b.nested(key)
+	// Don't return a non-nil interface to a nil pointer.
+	if nested == nil {
+		return nil
+	}
+	return nested
+}
+
+func (b *bucket) NestedReadBucket(key []byte) walletdb.ReadBucket {
+	return b.NestedReadWriteBucket(key)
+}
+
+// CreateBucket creates and returns a new nested bucket with the given key.
+// Returns ErrBucketExists if the bucket already exists, ErrBucketNameRequired
+// if the key is empty, or ErrIncompatibleValue if the key value is otherwise
+// invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	if !b.tx.writable {
+		return nil, walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	_, child, ok, err := b.row(key)
+	switch {
+	case err != nil:
+		return nil, err
+	case child.Valid:
+		return nil, walletdb.ErrBucketExists
+	case ok:
+		return nil, walletdb.ErrIncompatibleValue
+	}
+
+	var id int64
+	err = b.tx.sqlTx.QueryRow(
+		"INSERT INTO buckets DEFAULT VALUES RETURNING id",
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = b.tx.sqlTx.Exec(
+		"INSERT INTO kv (bucket, key, child) VALUES ($1, $2, $3)",
+		b.id, key, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket{tx: b.tx, id: id}, nil
+}
+
+// CreateBucketIfNotExists creates and returns a new nested bucket with the
+// given key if it does not already exist.  Returns ErrBucketNameRequired if the
+// key is empty or ErrIncompatibleValue if the key value is otherwise invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucketIfNotExists(key []byte) (walletdb.ReadWriteBucket, error) {
+	if !b.tx.writable {
+		return nil, walletdb.ErrTxNotWritable
+	}
+	_, child, _, err := b.row(key)
+	if err != nil {
+		return nil, err
+	}
+	if child.Valid {
+		return &bucket{tx: b.tx, id: child.Int64}, nil
+	}
+	return b.CreateBucket(key)
+}
+
+// DeleteNestedBucket removes a nested bucket with the given key.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction and
+// ErrBucketNotFound if the specified bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) DeleteNestedBucket(key []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	_, child, ok, err := b.row(key)
+	switch {
+	case err != nil:
+		return err
+	case !ok && len(key) == 0:
+		return walletdb.ErrIncompatibleValue
+	case !ok:
+		return walletdb.ErrBucketNotFound
+	case !child.Valid:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	// Delete the bucket and all buckets nested in it.
+	ids := []int64{child.Int64}
+	for len(ids) != 0 {
+		id := ids[len(ids)-1]
+		ids = ids[:len(ids)-1]
+
+		rows, err := b.tx.sqlTx.Query(
+			"SELECT child FROM kv WHERE bucket = $1 AND "+
+				"child IS NOT NULL", id,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var nested int64
+			if err := rows.Scan(&nested); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, nested)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		_, err = b.tx.sqlTx.Exec("DELETE FROM kv WHERE bucket = $1", id)
+		if err != nil {
+			return err
+		}
+		_, err = b.tx.sqlTx.Exec("DELETE FROM buckets WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = b.tx.sqlTx.Exec(
+		"DELETE FROM kv WHERE bucket = $1 AND key = $2", b.id, key,
+	)
+	return err
+}
+
+// ForEach invokes the passed function with every key/value pair in the bucket.
+// This includes nested buckets, in which case the value is nil, but it does not
+// include the key/value pairs within those nested buckets.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ForEach(fn func(k, v []byte) error) error {
+	// All pairs are read before calling fn, which may query the
+	// transaction itself.
+	rows, err := b.tx.sqlTx.Query(
+		"SELECT key, value, child FROM kv WHERE bucket = $1 ORDER BY key",
+		b.id,
+	)
+	if err != nil {
+		return err
+	}
+	var keys, values [][]byte
+	for rows.Next() {
+		var k, v []byte
+		var child sql.NullInt64
+		if err := rows.Scan(&k, &v, &child); err != nil {
+			rows.Close()
+			return err
+		}
+		if v == nil && !child.Valid {
+			v = []byte{}
+		}
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for i := range keys {
+		if err := fn(keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put saves the specified key/value pair to the bucket.  Keys that do not
+// already exist are added and keys that already exist are overwritten.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Put(key, value []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return walletdb.ErrKeyRequired
+	}
+	_, child, _, err := b.row(key)
+	if err != nil {
+		return err
+	}
+	if child.Valid {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	// A nil value is stored as an empty byte string, as NULL is reserved
+	// for nested buckets.
+	if value == nil {
+		value = []byte{}
+	}
+	_, err = b.tx.sqlTx.Exec(
+		"INSERT INTO kv (bucket, key, value) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (bucket, key) DO UPDATE SET value = $3",
+		b.id, key, value,
+	)
+	return err
+}
+
+// Get returns the value for the given key.  Returns nil if the key does
+// not exist in this bucket (or nested buckets).
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Get(key []byte) []byte {
+	value, _, _, err := b.row(key)
+	if err != nil {
+		b.tx.setErr(err)
+		return nil
+	}
+	return value
+}
+
+// Delete removes the specified key from the bucket.  Deleting a key that does
+// not exist does not return an error.  Returns ErrTxNotWritable if attempted
+// against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	_, child, _, err := b.row(key)
+	if err != nil {
+		return err
+	}
+	if child.Valid {
+		return walletdb.ErrIncompatibleValue
+	}
+	_, err = b.tx.sqlTx.Exec(
+		"DELETE FROM kv WHERE bucket = $1 AND key = $2", b.id, key,
+	)
+	return err
+}
+
+func (b *bucket) ReadCursor() walletdb.ReadCursor {
+	return b.ReadWriteCursor()
+}
+
+// ReadWriteCursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs and nested buckets in forward or backward order.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ReadWriteCursor() walletdb.ReadWriteCursor {
+	return &cursor{bucket: b}
+}
+
+// cursor represents a cursor over key/value pairs and nested buckets of a
+// bucket.  Every move of the cursor queries the pair following or preceding
+// the current key, so modifications of the bucket do not invalidate the
+// cursor.
+type cursor struct {
+	bucket *bucket
+
+	// key is the key of the current pair, or nil if the cursor is not
+	// positioned at a pair.
+	key []byte
+
+	// pastLast is set when a seek found no key at or after the seek key,
+	// which positions the cursor after the last pair as it does in bolt.
+	pastLast bool
+}
+
+// move positions the cursor at the first pair of the bucket matching the
+// condition on the key, in the order of keys given by order, and returns it.
+// The arguments of the condition are numbered from $2.
+func (c *cursor) move(cond, order string, args ...interface{}) (key, value []byte) {
+	query := "SELECT key, value, child FROM kv WHERE bucket = $1"
+	if cond != "" {
+		query += " AND " + cond
+	}
+	query += " ORDER BY key " + order + " LIMIT 1"
+
+	c.pastLast = false
+	var child sql.NullInt64
+	args = append([]interface{}{c.bucket.id}, args...)
+	err := c.bucket.tx.sqlTx.QueryRow(query, args...).Scan(
+		&key, &value, &child,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			c.bucket.tx.setErr(err)
+		}
+		c.key = nil
+		return nil, nil
+	}
+	if value == nil && !child.Valid {
+		value = []byte{}
+	}
+	c.key = key
+	return key, value
+}
+
+// Delete removes the current key/value pair the cursor is at without
+// invalidating the cursor. Returns ErrTxNotWritable if attempted on a read-only
+// transaction, or ErrIncompatibleValue if attempted when the cursor points to a
+// nested bucket.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Delete() error {
+	if c.key == nil {
+		return nil
+	}
+	return c.bucket.Delete(c.key)
+}
+
+// First positions the cursor at the first key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) First() (key, value []byte) {
+	return c.move("", "ASC")
+}
+
+// Last positions the cursor at the last key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Last() (key, value []byte) {
+	return c.move("", "DESC")
+}
+
+// Next moves the cursor one key/value pair forward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Next() (key, value []byte) {
+	if c.key == nil {
+		return nil, nil
+	}
+	return c.move("key > $2", "ASC", c.key)
+}
+
+// Prev moves the cursor one key/value pair backward and returns the new pair.
+// After a seek past the last key, this returns the last pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Prev() (key, value []byte) {
+	if c.pastLast {
+		return c.Last()
+	}
+	if c.key == nil {
+		return nil, nil
+	}
+	return c.move("key < $2", "DESC", c.key)
+}
+
+// Seek positions the cursor at the passed seek key. If the key does not exist,
+// the cursor is moved to the next key after seek. Returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	// A nil key would be bound as NULL, which compares to no key.
+	if len(seek) == 0 {
+		return c.First()
+	}
+	key, value = c.move("key >= $2", "ASC", seek)
+	c.pastLast = key == nil && c.bucket.tx.err == nil
+	return key, value
+}
+
+// db represents a collection of namespaces which are persisted and implements
+// the walletdb.Db interface.  All database access is performed through
+// transactions which are obtained through the specific Namespace.
+type db struct {
+	sqlDB *sql.DB
+
+	// writeMtx serializes the read-write transactions of the process,
+	// which would otherwise wait for the advisory lock while holding a
+	// connection.
+	writeMtx sync.Mutex
+}
+
+// Enforce db implements the walletdb.Db interface.
+var _ walletdb.DB = (*db)(nil)
+
+func (db *db) beginTx(writable bool) (*transaction, error) {
+	// Read-only transactions see a snapshot of the database as of their
+	// first query.
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	if writable {
+		db.writeMtx.Lock()
+		opts = nil
+	}
+	sqlTx, err := db.sqlDB.BeginTx(context.Background(), opts)
+	if err != nil {
+		if writable {
+			db.writeMtx.Unlock()
+		}
+		return nil, convertErr(err)
+	}
+
+	// Wait for read-write transactions of other processes, which the
+	// transaction would otherwise interleave with.  The lock is released
+	// when the transaction ends.
+	if writable {
+		_, err := sqlTx.Exec("SELECT pg_advisory_xact_lock($1)", writeLock)
+		if err != nil {
+			sqlTx.Rollback()
+			db.writeMtx.Unlock()
+			return nil, convertErr(err)
+		}
+	}
+	return &transaction{db: db, sqlTx: sqlTx, writable: writable}, nil
+}
+
+func (db *db) BeginReadTx() (walletdb.ReadTx, error) {
+	return db.beginTx(false)
+}
+
+func (db *db) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
+	return db.beginTx(true)
+}
+
+// Batch executes f in its own read-write transaction, as write transactions of
+// this driver are not coalesced.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Batch(f func(tx walletdb.ReadWriteTx) error) error {
+	return walletdb.Update(db, f)
+}
+
+// Copy is not supported, as PostgreSQL databases are backed up with pg_dump or
+// the replication of the database server.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Copy(w io.Writer) error {
+	return errCopyUnsupported
+}
+
+// Close cleanly shuts down the database and syncs all data.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Close() error {
+	return convertErr(db.sqlDB.Close())
+}
+
+// openDB opens the database of the provided connection string.
+// walletdb.ErrDbDoesNotExist is returned if the tables of the database don't
+// exist and the create flag is not set.
+func openDB(dsn string, create bool) (walletdb.DB, error) {
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		_, err = sqlDB.Exec(schema)
+	} else {
+		var exists bool
+		err = sqlDB.QueryRow(
+			"SELECT to_regclass('kv') IS NOT NULL",
+		).Scan(&exists)
+		if err == nil && !exists {
+			err = walletdb.ErrDbDoesNotExist
+		}
+	}
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return &db{sqlDB: sqlDB}, nil
+}
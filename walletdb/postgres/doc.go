@@ -0,0 +1,41 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package postgres implements an instance of walletdb that uses PostgreSQL for
+the backing datastore.  This allows keeping the wallet state in a managed,
+replicated database instead of a single file on the host running the wallet.
+
+Buckets are emulated with a table of key/value pairs, each belonging to a
+bucket, where the value of a nested bucket is replaced by the id of the bucket.
+Read-only transactions see a consistent snapshot of the database.  Read-write
+transactions are serialized with an advisory lock, so several processes may
+share a database, for example during a failover, without interleaving their
+writes.
+
+The tables are created in the current schema of the connection, so every
+wallet must use its own database or schema, which can be selected with the
+search_path parameter of the connection string.  PostgreSQL 9.5 or later is
+required.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "postgres".  The only parameter the Open and Create functions take is
+the connection string of the database, consisting of either key=value pairs or
+a URL:
+
+	db, err := walletdb.Open("postgres", "postgres://user@host/wallet")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Create("postgres", "host=host dbname=wallet")
+	if err != nil {
+		// Handle error
+	}
+
+Open returns walletdb.ErrDbDoesNotExist if the wallet tables were not created.
+*/
+package postgres
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb
+
+// OpenOptions are options of a database opened with Open.  They are passed to
+// Open after the arguments of the driver.
+type OpenOptions struct {
+	// ReadOnly opens the database in read-only mode.  Beginning a
+	// read-write transaction of a read-only database fails with
+	// ErrTxNotWritable, so the database can not be modified.  Drivers
+	// supporting it open the database without taking the locks of
+	// writers, allowing another process to keep writing to it.
+	ReadOnly bool
+}
+
+// splitOpenOptions splits the arguments of Open into the arguments of the
+// driver and the options of the database.
+func splitOpenOptions(args []interface{}) ([]interface{}, OpenOptions) {
+	if len(args) != 0 {
+		if opts, ok := args[len(args)-1].(OpenOptions); ok {
+			return args[:len(args)-1], opts
+		}
+	}
+	return args, OpenOptions{}
+}
+
+// readOnlyDB is a database opened in read-only mode.
+type readOnlyDB struct {
+	DB
+}
+
+// BeginReadWriteTx fails with ErrTxNotWritable, as the database is read-only.
+//
+// This function is part of the DB interface implementation.
+func (db *readOnlyDB) BeginReadWriteTx() (ReadWriteTx, error) {
+	return nil, ErrTxNotWritable
+}
+
+// Batch fails with ErrTxNotWritable, as the database is read-only.
+//
+// This function is part of the DB interface implementation.
+func (db *readOnlyDB) Batch(f func(tx ReadWriteTx) error) error {
+	return ErrTxNotWritable
+}
+
+// IsReadOnly returns whether the database was opened in read-only mode.
+func IsReadOnly(db DB) bool {
+	_, ok := Unwrap(db).(*readOnlyDB)
+	return ok
+}
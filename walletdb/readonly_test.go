@@ -0,0 +1,73 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdb_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+)
+
+// TestOpenReadOnly ensures databases opened in read-only mode can be read but
+// not modified.
+func TestOpenReadOnly(t *testing.T) {
+	t.Parallel()
+
+	dirName, err := ioutil.TempDir("", "readonly")
+	if err != nil {
+		t.Fatalf("Failed to create db temp dir: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	dbPath := filepath.Join(dirName, "test.db")
+
+	db, err := walletdb.Create("bdb", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		b, err := tx.CreateTopLevelBucket([]byte("ns"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), []byte("value"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walletdb.IsReadOnly(db) {
+		t.Fatal("database is read-only")
+	}
+	db.Close()
+
+	db, err = walletdb.Open("bdb", dbPath, walletdb.OpenOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: unexpected error: %v", err)
+	}
+	defer db.Close()
+	if !walletdb.IsReadOnly(db) {
+		t.Fatal("database is not read-only")
+	}
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		v := tx.ReadBucket([]byte("ns")).Get([]byte("key"))
+		if !bytes.Equal(v, []byte("value")) {
+			return fmt.Errorf("unexpected value %s", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.BeginReadWriteTx(); err != walletdb.ErrTxNotWritable {
+		t.Fatalf("BeginReadWriteTx: did not receive expected error - "+
+			"got %v, want %v", err, walletdb.ErrTxNotWritable)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package sqlite implements an instance of walletdb that uses SQLite for the
+backing datastore.  SQLite databases are easy to back up, inspect and replicate
+with standard tools, and are available on platforms bolt does not support.
+
+Buckets are emulated with a single table of key/value pairs, each belonging to
+a bucket, where the value of a nested bucket is replaced by the id of the
+bucket.  Keys are compared as blobs, so cursors iterate in the same order as
+with bolt.  Read-write transactions are serialized, while read-only
+transactions run concurrently using the write-ahead log of SQLite.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "sqlite".  The only parameter the Open and Create functions take is the
+database path as a string:
+
+	db, err := walletdb.Open("sqlite", "path/to/database.sqlite")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Create("sqlite", "path/to/database.sqlite")
+	if err != nil {
+		// Handle error
+	}
+*/
+package sqlite
@@ -676,6 +676,136 @@ func testAdditionalErrors(tc *testContext) bool {
 	return true
 }
 
+// testBatch ensures the writes of concurrent calls to Batch are all committed
+// and the writes of a failed call are rolled back.
+func testBatch(tc *testContext) bool {
+	batchKey := []byte("batch")
+	err := walletdb.Update(tc.db, func(tx walletdb.ReadWriteTx) error {
+		_, err := tx.CreateTopLevelBucket(batchKey)
+		return err
+	})
+	if err != nil {
+		tc.t.Errorf("CreateTopLevelBucket: unexpected error: %v", err)
+		return false
+	}
+
+	const numBatches = 10
+	errs := make(chan error, numBatches)
+	for i := 0; i < numBatches; i++ {
+		go func(i int) {
+			errs <- tc.db.Batch(func(tx walletdb.ReadWriteTx) error {
+				bucket := tx.ReadWriteBucket(batchKey)
+				key := []byte(fmt.Sprintf("batchkey%d", i))
+				return bucket.Put(key, []byte(fmt.Sprintf("foo%d", i)))
+			})
+		}(i)
+	}
+	for i := 0; i < numBatches; i++ {
+		if err := <-errs; err != nil {
+			tc.t.Errorf("Batch: unexpected error: %v", err)
+			return false
+		}
+	}
+
+	wantErr := fmt.Errorf("batch failure")
+	err = tc.db.Batch(func(tx walletdb.ReadWriteTx) error {
+		bucket := tx.ReadWriteBucket(batchKey)
+		if err := bucket.Put([]byte("batchfail"), []byte("foo")); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if err != wantErr {
+		tc.t.Errorf("Batch: unexpected error - got %v, want %v", err,
+			wantErr)
+		return false
+	}
+
+	err = walletdb.View(tc.db, func(tx walletdb.ReadTx) error {
+		bucket := tx.ReadBucket(batchKey)
+		for i := 0; i < numBatches; i++ {
+			key := []byte(fmt.Sprintf("batchkey%d", i))
+			want := []byte(fmt.Sprintf("foo%d", i))
+			if got := bucket.Get(key); !reflect.DeepEqual(got, want) {
+				return fmt.Errorf("Get: key '%s' does not match "+
+					"expected value - got %s, want %s", key,
+					got, want)
+			}
+		}
+		if v := bucket.Get([]byte("batchfail")); v != nil {
+			return fmt.Errorf("Get: writes of failed Batch were " +
+				"not rolled back")
+		}
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	return true
+}
+
+// testCursorSeek ensures cursors move backward from the key found by Seek, and
+// from after the last key when Seek finds no key at or after the seek key.
+func testCursorSeek(tc *testContext) bool {
+	seekKey := []byte("seek")
+	err := walletdb.Update(tc.db, func(tx walletdb.ReadWriteTx) error {
+		bucket, err := tx.CreateTopLevelBucket(seekKey)
+		if err != nil {
+			return fmt.Errorf("CreateTopLevelBucket: unexpected "+
+				"error: %v", err)
+		}
+		for _, k := range []string{"a", "b", "d"} {
+			if err := bucket.Put([]byte(k), []byte(k)); err != nil {
+				return fmt.Errorf("Put: unexpected error: %v",
+					err)
+			}
+		}
+
+		tests := []struct {
+			seek     string
+			wantSeek string
+			wantPrev string
+		}{
+			{seek: "b", wantSeek: "b", wantPrev: "a"},
+			{seek: "c", wantSeek: "d", wantPrev: "b"},
+			{seek: "e", wantSeek: "", wantPrev: "d"},
+		}
+		for _, test := range tests {
+			c := bucket.ReadCursor()
+			k, _ := c.Seek([]byte(test.seek))
+			if string(k) != test.wantSeek {
+				return fmt.Errorf("Seek(%s): unexpected key - "+
+					"got %s, want %s", test.seek, k,
+					test.wantSeek)
+			}
+			k, v := c.Prev()
+			if string(k) != test.wantPrev ||
+				string(v) != test.wantPrev {
+
+				return fmt.Errorf("Prev after Seek(%s): "+
+					"unexpected pair - got %s/%s, want %s",
+					test.seek, k, v, test.wantPrev)
+			}
+		}
+
+		c := bucket.ReadCursor()
+		c.Seek([]byte("e"))
+		if k, _ := c.Next(); k != nil {
+			return fmt.Errorf("Next after Seek past the last key: "+
+				"unexpected key %s", k)
+		}
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	return true
+}
+
 // TestInterface performs all interfaces tests for this database driver.
 func TestInterface(t Tester, dbType, dbPath string) {
 	db, err := walletdb.Create(dbType, dbPath)
@@ -704,4 +834,14 @@ func TestInterface(t Tester, dbType, dbPath string) {
 	if !testAdditionalErrors(&context) {
 		return
 	}
+
+	// Test concurrent batched writes.
+	if !testBatch(&context) {
+		return
+	}
+
+	// Test moving cursors backward after a seek.
+	if !testCursorSeek(&context) {
+		return
+	}
 }
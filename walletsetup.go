@@ -6,9 +6,12 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -20,7 +23,11 @@ import (
 	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/wallet"
 	"github.com/btcsuite/btcwallet/walletdb"
-	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/walletdb/bdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/etcd"
+	_ "github.com/btcsuite/btcwallet/walletdb/postgres"
+	_ "github.com/btcsuite/btcwallet/walletdb/sqlite"
+	"github.com/coreos/etcd/clientv3"
 )
 
 // networkDir returns the directory name of a network directory to hold wallet
@@ -102,7 +109,7 @@ func convertLegacyKeystore(legacyKeyStore *keystore.Store, w *wallet.Wallet) err
 // provided path.
 func createWallet(cfg *config) error {
 	dbDir := networkDir(cfg.AppDataDir.Value, activeNet.Params)
-	loader := wallet.NewLoader(activeNet.Params, dbDir, 250)
+	loader := newLoader(cfg, dbDir)
 
 	// When there is a legacy keystore, open it now to ensure any errors
 	// don't end up exiting the process after the user has spent time
@@ -192,8 +199,21 @@ func createWallet(cfg *config) error {
 		return err
 	}
 
+	// Encrypting the database with the default public passphrase would
+	// not protect it.
+	var opts []wallet.CreateOption
+	if cfg.EncryptDB {
+		if bytes.Equal(pubPass, []byte(wallet.InsecurePubPassphrase)) {
+			return errors.New("--encryptdb requires a public " +
+				"wallet password")
+		}
+		opts = append(opts, wallet.WithDatabaseEncryption())
+	}
+
 	fmt.Println("Creating the wallet...")
-	w, err := loader.CreateNewWallet(pubPass, privPass, seed, time.Now())
+	w, err := loader.CreateNewWallet(
+		pubPass, privPass, seed, time.Now(), opts...,
+	)
 	if err != nil {
 		return err
 	}
@@ -215,11 +235,15 @@ func createSimulationWallet(cfg *config) error {
 	netDir := networkDir(cfg.AppDataDir.Value, activeNet.Params)
 
 	// Create the wallet.
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbArgs := databaseArgs(cfg)
+	if dbArgs == nil {
+		dbPath := filepath.Join(netDir, wallet.DatabaseFileName(cfg.DBBackend))
+		dbArgs = []interface{}{dbPath}
+	}
 	fmt.Println("Creating the wallet...")
 
-	// Create the wallet database backed by bolt db.
-	db, err := walletdb.Create("bdb", dbPath)
+	// Create the wallet database of the configured backend.
+	db, err := walletdb.Create(cfg.DBBackend, dbArgs...)
 	if err != nil {
 		return err
 	}
@@ -235,6 +259,58 @@ func createSimulationWallet(cfg *config) error {
 	return nil
 }
 
+// databaseArgs returns the driver arguments of the configured database
+// backend, or nil if the backend stores the database in a file of the network
+// directory.
+func databaseArgs(cfg *config) []interface{} {
+	switch cfg.DBBackend {
+	case "postgres":
+		return []interface{}{cfg.PostgresDSN}
+
+	case "etcd":
+		etcdCfg := clientv3.Config{
+			Endpoints:   strings.Split(cfg.EtcdEndpoints, ","),
+			DialTimeout: 10 * time.Second,
+			Username:    cfg.EtcdUser,
+			Password:    cfg.EtcdPass,
+		}
+		prefix := cfg.EtcdPrefix
+		if prefix == "" {
+			prefix = "btcwallet/" + activeNet.Params.Name + "/"
+		}
+		return []interface{}{etcdCfg, prefix}
+	}
+	return nil
+}
+
+// newLoader returns a loader of the wallet in dbDir using the configured
+// database backend.
+func newLoader(cfg *config, dbDir string) *wallet.Loader {
+	loader := wallet.NewLoader(activeNet.Params, dbDir, cfg.GapLimit)
+	loader.SetDatabaseDriver(cfg.DBBackend, databaseArgs(cfg)...)
+	return loader
+}
+
+// compactDatabase compacts the bdb database of the wallet in dbDir.
+func compactDatabase(dbDir string) error {
+	dbPath := filepath.Join(dbDir, wallet.DatabaseFileName("bdb"))
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("Compacting wallet database %s", dbPath)
+	if err := bdb.Compact(dbPath); err != nil {
+		return fmt.Errorf("unable to compact wallet database: %v", err)
+	}
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("Compacted wallet database from %d to %d bytes",
+		before.Size(), after.Size())
+	return nil
+}
+
 // checkCreateDir checks that the path exists and is a directory.
 // If path does not exist, it is created.
 func checkCreateDir(path string) error {
@@ -0,0 +1,263 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"bytes"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// Balances describes the balances of the unspent outputs of an account.
+type Balances struct {
+	Total          btcutil.Amount
+	Spendable      btcutil.Amount
+	ImmatureReward btcutil.Amount
+}
+
+// AddAccountCredit marks a transaction record as containing a transaction
+// output spendable by wallet, like AddCredit, and records the account of the
+// output.  The unspent credits of an account are indexed by block height, so
+// AccountBalances does not need to read every unspent output.
+func (s *Store) AddAccountCredit(ns walletdb.ReadWriteBucket, rec *TxRecord,
+	block *BlockMeta, index uint32, change bool, account uint32) error {
+
+	if int(index) >= len(rec.MsgTx.TxOut) {
+		str := "transaction output does not exist"
+		return storeError(ErrInput, str, nil)
+	}
+
+	k := canonicalOutPoint(&rec.Hash, index)
+	err := putCreditAccount(ns, k, &creditAccount{
+		account:  account,
+		amount:   btcutil.Amount(rec.MsgTx.TxOut[index].Value),
+		coinbase: blockchain.IsCoinBaseTx(&rec.MsgTx),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.AddCredit(ns, rec, block, index, change); err != nil {
+		return err
+	}
+
+	// The credit may have been added before without an account, in which
+	// case it is only indexed now.
+	return indexUnspentCredit(ns, k)
+}
+
+// indexUnspentCredit indexes the credit with the outpoint key k by its
+// account if it is unspent.
+func indexUnspentCredit(ns walletdb.ReadWriteBucket, k []byte) error {
+	if v := ns.NestedReadBucket(bucketUnspent).Get(k); v != nil {
+		var block Block
+		if err := readUnspentBlock(v, &block); err != nil {
+			return err
+		}
+		return indexAcctUnspent(ns, k, block.Height)
+	}
+	if existsRawUnminedCredit(ns, k) != nil {
+		return indexAcctUnspent(ns, k, -1)
+	}
+	return nil
+}
+
+// AccountsIndexed returns whether the unspent credits of the store are indexed
+// by account.  This is the case for stores created with or upgraded to version
+// 2, whose credits are added with AddAccountCredit.
+func (s *Store) AccountsIndexed(ns walletdb.ReadBucket) bool {
+	return acctsIndexed(ns)
+}
+
+// IndexAccountCredits indexes the unspent credits which were added without
+// their account, such as with AddCredit.  The account of each credit is looked
+// up with the output script of the credit, and credits for which account
+// returns false are not indexed.  Credits already recorded with their account
+// keep it.  The credits of stores of version 1 are indexed when the store is
+// upgraded with DoAccountUpgrades.
+func (s *Store) IndexAccountCredits(ns walletdb.ReadWriteBucket,
+	account func(pkScript []byte) (uint32, bool)) error {
+
+	return indexAccountCredits(ns, account)
+}
+
+// indexAccountCredits is the implementation of IndexAccountCredits.
+func indexAccountCredits(ns walletdb.ReadWriteBucket,
+	account func(pkScript []byte) (uint32, bool)) error {
+
+	// Collect the unspent credits first, as the buckets must not be
+	// modified while iterating over them.
+	var minedKeys, unminedKeys [][]byte
+	collect := func(keys *[][]byte) func(k, v []byte) error {
+		return func(k, v []byte) error {
+			*keys = append(*keys, append([]byte(nil), k...))
+			return nil
+		}
+	}
+	err := ns.NestedReadBucket(bucketUnspent).ForEach(collect(&minedKeys))
+	if err != nil {
+		str := "failed iterating unspent bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	err = ns.NestedReadBucket(bucketUnminedCredits).ForEach(collect(&unminedKeys))
+	if err != nil {
+		str := "failed iterating unmined credits bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	index := func(k []byte, op *wire.OutPoint, rec *TxRecord) error {
+		if ca, err := fetchCreditAccount(ns, k); err != nil || ca != nil {
+			return err
+		}
+		if int(op.Index) >= len(rec.MsgTx.TxOut) {
+			str := "saved credit index exceeds number of outputs"
+			return storeError(ErrData, str, nil)
+		}
+
+		txOut := rec.MsgTx.TxOut[op.Index]
+		acct, ok := account(txOut.PkScript)
+		if !ok {
+			return nil
+		}
+		err := putCreditAccount(ns, k, &creditAccount{
+			account:  acct,
+			amount:   btcutil.Amount(txOut.Value),
+			coinbase: blockchain.IsCoinBaseTx(&rec.MsgTx),
+		})
+		if err != nil {
+			return err
+		}
+		return indexUnspentCredit(ns, k)
+	}
+
+	var op wire.OutPoint
+	var block Block
+	for _, k := range minedKeys {
+		if err := readCanonicalOutPoint(k, &op); err != nil {
+			return err
+		}
+		v := ns.NestedReadBucket(bucketUnspent).Get(k)
+		if err := readUnspentBlock(v, &block); err != nil {
+			return err
+		}
+		rec, err := fetchTxRecord(ns, &op.Hash, &block)
+		if err != nil {
+			return err
+		}
+		if err := index(k, &op, rec); err != nil {
+			return err
+		}
+	}
+	for _, k := range unminedKeys {
+		if err := readCanonicalOutPoint(k, &op); err != nil {
+			return err
+		}
+		var rec TxRecord
+		v := existsRawUnmined(ns, op.Hash[:])
+		if err := readRawTxRecord(&op.Hash, v, &rec); err != nil {
+			return err
+		}
+		if err := index(k, &op, &rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AccountBalances returns the balances of the unspent outputs of an account
+// given a minimum of minConf confirmations, calculated at a current chain
+// height of syncHeight.  Only unspent outputs with at least minConf
+// confirmations are spendable, and coinbase outputs are immature until
+// maturity has been reached.  Outputs spent by unmined transactions are not
+// included.
+//
+// The balances are calculated from the unspent outputs recorded with
+// AddAccountCredit or indexed by IndexAccountCredits, and only the outputs of
+// the most recent blocks and unmined transactions are read.
+func (s *Store) AccountBalances(ns walletdb.ReadBucket, account uint32,
+	minConf int32, syncHeight int32) (Balances, error) {
+
+	var bals Balances
+	total, err := fetchAcctBalance(ns, account)
+	if err != nil {
+		return bals, err
+	}
+	bals.Total = total
+
+	// Subtract the credits of the account which are spent by unmined
+	// transactions.
+	err = ns.NestedReadBucket(bucketUnminedInputs).ForEach(func(k, v []byte) error {
+		ca, err := fetchCreditAccount(ns, k)
+		if err != nil || ca == nil || ca.account != account {
+			return err
+		}
+		if existsRawUnspent(ns, k) != nil ||
+			existsRawUnminedCredit(ns, k) != nil {
+
+			bals.Total -= ca.amount
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(Error); ok {
+			return bals, err
+		}
+		str := "failed iterating unmined inputs bucket"
+		return bals, storeError(ErrDatabase, str, err)
+	}
+
+	// Only the credits of blocks after lastHeight, and unmined credits,
+	// may have less than minConf confirmations or be immature coinbase
+	// outputs.
+	coinbaseMaturity := int32(s.chainParams.CoinbaseMaturity)
+	stopConf := minConf
+	if coinbaseMaturity > stopConf {
+		stopConf = coinbaseMaturity
+	}
+	lastHeight := syncHeight - stopConf
+	if lastHeight < 0 {
+		lastHeight = 0
+	}
+
+	var unconfirmed btcutil.Amount
+	b := ns.NestedReadBucket(bucketAcctUnspent)
+	if b != nil {
+		seek := keyAcctUnspent(account, lastHeight, nil)[:8]
+		c := b.ReadCursor()
+		k, _ := c.Seek(seek)
+		for ; bytes.HasPrefix(k, seek[:4]); k, _ = c.Next() {
+			opKey := k[8:44]
+			if existsRawUnminedInput(ns, opKey) != nil {
+				continue
+			}
+			ca, err := fetchCreditAccount(ns, opKey)
+			if err != nil {
+				return bals, err
+			}
+			if ca == nil {
+				str := "indexed credit has no recorded account"
+				return bals, storeError(ErrData, str, nil)
+			}
+
+			height := int32(byteOrder.Uint32(k[4:8]))
+			confs := int32(0)
+			if height != -1 && height <= syncHeight {
+				confs = syncHeight - height + 1
+			}
+			switch {
+			case ca.coinbase && confs < coinbaseMaturity:
+				bals.ImmatureReward += ca.amount
+			case confs < minConf:
+				unconfirmed += ca.amount
+			}
+		}
+	}
+
+	bals.Spendable = bals.Total - bals.ImmatureReward - unconfirmed
+	return bals, nil
+}
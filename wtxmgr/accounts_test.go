@@ -0,0 +1,297 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestAccountBalances ensures that the balances of accounts calculated from
+// the credits indexed by account match the unspent outputs of the accounts.
+func TestAccountBalances(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	coinbaseMaturity := int32(chaincfg.TestNet3Params.CoinbaseMaturity)
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	b101 := &BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	cbRec, err := NewTxRecordFromMsgTx(newCoinBase(1e8), b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recvRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&chainhash.Hash{1}, 0, 2e7, 3e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&recvRec.Hash, 0, 1e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&chainhash.Hash{2}, 0, 4e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkBalances := func(ns walletdb.ReadBucket, account uint32,
+		minConf, syncHeight int32, want Balances) {
+
+		bals, err := store.AccountBalances(ns, account, minConf,
+			syncHeight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bals != want {
+			t.Fatalf("expected balances %+v of account %d at height "+
+				"%d, got %+v", want, account, syncHeight, bals)
+		}
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if !store.AccountsIndexed(ns) {
+			t.Fatal("new store is not indexed by account")
+		}
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddAccountCredit(ns, cbRec, b100, 0, false, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, recvRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		err = store.AddAccountCredit(ns, recvRec, nil, 0, false, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.AddAccountCredit(ns, recvRec, nil, 1, false, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The coinbase output is immature and the unmined output is
+		// unconfirmed.
+		checkBalances(ns, 1, 1, 100, Balances{
+			Total:          12e7,
+			ImmatureReward: 1e8,
+		})
+		checkBalances(ns, 1, 0, 100, Balances{
+			Total:          12e7,
+			Spendable:      2e7,
+			ImmatureReward: 1e8,
+		})
+		checkBalances(ns, 2, 1, 100, Balances{Total: 3e7})
+	})
+
+	// Mined credits move from the unmined index to the index of their
+	// block.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, recvRec, b101); err != nil {
+			t.Fatal(err)
+		}
+		checkBalances(ns, 1, 1, 101, Balances{
+			Total:          12e7,
+			Spendable:      2e7,
+			ImmatureReward: 1e8,
+		})
+		checkBalances(ns, 1, 2, 101, Balances{
+			Total:          12e7,
+			ImmatureReward: 1e8,
+		})
+		checkBalances(ns, 2, 1, 101, Balances{
+			Total:     3e7,
+			Spendable: 3e7,
+		})
+
+		matureHeight := 100 + coinbaseMaturity - 1
+		checkBalances(ns, 1, 1, matureHeight, Balances{
+			Total:     12e7,
+			Spendable: 12e7,
+		})
+	})
+
+	// Outputs spent by unmined transactions are no longer included.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, spendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		checkBalances(ns, 1, 1, 101, Balances{
+			Total:          1e8,
+			ImmatureReward: 1e8,
+		})
+	})
+
+	// Credits added without an account are only included once the
+	// store's credits are indexed.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, otherRec, b101); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, otherRec, b101, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBalances(ns, 3, 1, 101, Balances{})
+
+		err = store.IndexAccountCredits(ns,
+			func(pkScript []byte) (uint32, bool) {
+				return 3, true
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBalances(ns, 3, 1, 101, Balances{
+			Total:     4e7,
+			Spendable: 4e7,
+		})
+		checkBalances(ns, 2, 1, 101, Balances{
+			Total:     3e7,
+			Spendable: 3e7,
+		})
+
+		// Unspent outputs are returned with their recorded account.
+		unspent, err := store.UnspentOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accounts := map[chainhash.Hash]uint32{
+			cbRec.Hash:    1,
+			recvRec.Hash:  2,
+			otherRec.Hash: 3,
+		}
+		for _, cred := range unspent {
+			acct, ok := accounts[cred.Hash]
+			if !ok || !cred.HasAccount || cred.Account != acct {
+				t.Fatalf("unexpected account %d (known %v) of "+
+					"unspent output %v", cred.Account,
+					cred.HasAccount, cred.OutPoint)
+			}
+		}
+	})
+}
+
+// TestUpgradeAccountCredits ensures that stores of version 1, which do not
+// record the accounts of their credits, are upgraded to index their unspent
+// credits by account.
+func TestUpgradeAccountCredits(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	// Version 1 stores only record credits without their account.
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	rec, err := NewTxRecordFromMsgTx(
+		spendOutput(&chainhash.Hash{1}, 0, 2e7), b100.Time,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, rec, b100); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddCredit(ns, rec, b100, 0, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := ns.Put([]byte("vers"), []byte{0, 0, 0, 1}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	needsUpgrade := func(err error) bool {
+		serr, ok := err.(Error)
+		return ok && serr.Code == ErrNeedsUpgrade
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		_, err := Open(tx.ReadBucket(namespaceKey),
+			&chaincfg.TestNet3Params)
+		return err
+	})
+	if !needsUpgrade(err) {
+		t.Fatalf("expected ErrNeedsUpgrade opening version 1 store, "+
+			"got %v", err)
+	}
+	if err := DoUpgrades(db, namespaceKey); !needsUpgrade(err) {
+		t.Fatalf("expected ErrNeedsUpgrade upgrading without "+
+			"accounts, got %v", err)
+	}
+
+	lookups := 0
+	account := func(tx walletdb.ReadTx, pkScript []byte) (uint32, bool) {
+		lookups++
+		return 7, true
+	}
+	if err := DoAccountUpgrades(db, namespaceKey, account); err != nil {
+		t.Fatalf("unable to upgrade store: %v", err)
+	}
+	if lookups != 1 {
+		t.Fatalf("expected one account lookup, got %d", lookups)
+	}
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(namespaceKey)
+		store, err := Open(ns, &chaincfg.TestNet3Params)
+		if err != nil {
+			return err
+		}
+		if !store.AccountsIndexed(ns) {
+			t.Fatal("upgraded store is not indexed by account")
+		}
+		bals, err := store.AccountBalances(ns, 7, 1, 100)
+		if err != nil {
+			return err
+		}
+		want := Balances{Total: 2e7, Spendable: 2e7}
+		if bals != want {
+			t.Fatalf("expected balances %+v, got %+v", want, bals)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upgraded stores are not upgraded again.
+	if err := DoAccountUpgrades(db, namespaceKey, account); err != nil {
+		t.Fatal(err)
+	}
+	if err := DoUpgrades(db, namespaceKey); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 1 {
+		t.Fatalf("expected no further account lookups, got %d",
+			lookups-1)
+	}
+}
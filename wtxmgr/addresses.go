@@ -0,0 +1,172 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"bytes"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// AddressCredit describes a credit received by an address.
+type AddressCredit struct {
+	OutPoint wire.OutPoint
+	Height   int32 // -1 if unmined
+	Amount   btcutil.Amount
+}
+
+// indexAddrCredit indexes the credit txOut, with the outpoint key k, by the
+// addresses of its output script.  Credits with non-standard scripts are not
+// indexed.
+func (s *Store) indexAddrCredit(ns walletdb.ReadWriteBucket, k []byte,
+	txOut *wire.TxOut, height int32) error {
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript,
+		s.chainParams)
+	if err != nil || len(addrs) == 0 {
+		return nil
+	}
+	encoded := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		encoded = append(encoded, addr.EncodeAddress())
+	}
+	return putAddrCredit(ns, k, encoded, height,
+		btcutil.Amount(txOut.Value))
+}
+
+// AddressesIndexed returns whether the credits of the store are indexed by
+// address.  This is the case for stores whose credits were indexed with
+// IndexAddressCredits, or which were created after credits were indexed by
+// address.
+func (s *Store) AddressesIndexed(ns walletdb.ReadBucket) bool {
+	return addrsIndexed(ns)
+}
+
+// IndexAddressCredits indexes the mined and unmined credits of a store
+// created before credits were indexed by address.
+func (s *Store) IndexAddressCredits(ns walletdb.ReadWriteBucket) error {
+	// Collect the credits first, as the buckets must not be modified while
+	// iterating over them.
+	var minedKeys, unminedKeys [][]byte
+	collect := func(keys *[][]byte) func(k, v []byte) error {
+		return func(k, v []byte) error {
+			*keys = append(*keys, append([]byte(nil), k...))
+			return nil
+		}
+	}
+	err := ns.NestedReadBucket(bucketCredits).ForEach(collect(&minedKeys))
+	if err != nil {
+		str := "failed iterating credits bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	err = ns.NestedReadBucket(bucketUnminedCredits).ForEach(collect(&unminedKeys))
+	if err != nil {
+		str := "failed iterating unmined credits bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	var op wire.OutPoint
+	for _, k := range minedKeys {
+		if len(k) < 72 {
+			str := "short credit key"
+			return storeError(ErrData, str, nil)
+		}
+		var block Block
+		err := readRawTxRecordBlock(extractRawCreditTxRecordKey(k), &block)
+		if err != nil {
+			return err
+		}
+		copy(op.Hash[:], k[:32])
+		op.Index = extractRawCreditIndex(k)
+		rec, err := fetchTxRecord(ns, &op.Hash, &block)
+		if err != nil {
+			return err
+		}
+		if int(op.Index) >= len(rec.MsgTx.TxOut) {
+			str := "saved credit index exceeds number of outputs"
+			return storeError(ErrData, str, nil)
+		}
+		err = s.indexAddrCredit(ns, canonicalOutPoint(&op.Hash, op.Index),
+			rec.MsgTx.TxOut[op.Index], block.Height)
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range unminedKeys {
+		if err := readCanonicalOutPoint(k, &op); err != nil {
+			return err
+		}
+		var rec TxRecord
+		v := existsRawUnmined(ns, op.Hash[:])
+		if err := readRawTxRecord(&op.Hash, v, &rec); err != nil {
+			return err
+		}
+		if int(op.Index) >= len(rec.MsgTx.TxOut) {
+			str := "saved credit index exceeds number of outputs"
+			return storeError(ErrData, str, nil)
+		}
+		err := s.indexAddrCredit(ns, k, rec.MsgTx.TxOut[op.Index], -1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return putAddrsIndexed(ns)
+}
+
+// AddressCredits returns the mined and unmined credits received by addr,
+// whether or not they are spent.  Only the credits of the address are read.
+func (s *Store) AddressCredits(ns walletdb.ReadBucket,
+	addr btcutil.Address) ([]AddressCredit, error) {
+
+	b := ns.NestedReadBucket(bucketAddrCredits)
+	if b == nil {
+		return nil, nil
+	}
+	encoded := addr.EncodeAddress()
+	if len(encoded) > 0xff {
+		return nil, nil
+	}
+	prefix := keyAddrCredit(encoded, nil)[:1+len(encoded)]
+
+	var credits []AddressCredit
+	c := b.ReadCursor()
+	for k, v := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var cred AddressCredit
+		_, height, amount, err := readRawAddrCredit(k, v, &cred.OutPoint)
+		if err != nil {
+			return nil, err
+		}
+		cred.Height = height
+		cred.Amount = amount
+		credits = append(credits, cred)
+	}
+	return credits, nil
+}
+
+// ForEachAddressCredit calls f with the encoded address and each credit of
+// every indexed address, ordered by address.  Iteration stops when f returns
+// an error, which is returned.
+func (s *Store) ForEachAddressCredit(ns walletdb.ReadBucket,
+	f func(addr string, cred *AddressCredit) error) error {
+
+	b := ns.NestedReadBucket(bucketAddrCredits)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		var cred AddressCredit
+		addr, height, amount, err := readRawAddrCredit(k, v, &cred.OutPoint)
+		if err != nil {
+			return err
+		}
+		cred.Height = height
+		cred.Amount = amount
+		return f(addr, &cred)
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestAddressCredits ensures that the credits indexed by address follow the
+// credits of the store as transactions are mined, reorganized and removed.
+func TestAddressCredits(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	newAddr := func(b byte) (btcutil.Address, []byte) {
+		addr, err := btcutil.NewAddressPubKeyHash(
+			[]byte{19: b}, &chaincfg.TestNet3Params,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr, pkScript
+	}
+	addr1, pkScript1 := newAddr(1)
+	addr2, pkScript2 := newAddr(2)
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	b101 := &BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	cbTx := newCoinBase(1e8)
+	cbTx.TxOut[0].PkScript = pkScript1
+	cbRec, err := NewTxRecordFromMsgTx(cbTx, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recvTx := spendOutput(&chainhash.Hash{1}, 0, 2e7, 3e7)
+	recvTx.TxOut[0].PkScript = pkScript1
+	recvTx.TxOut[1].PkScript = pkScript2
+	recvRec, err := NewTxRecordFromMsgTx(recvTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&recvRec.Hash, 0, 1e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkCredits := func(ns walletdb.ReadBucket, addr btcutil.Address,
+		want []AddressCredit) {
+
+		credits, err := store.AddressCredits(ns, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The order of the credits is not compared.
+		sort.Slice(want, func(i, j int) bool {
+			return want[i].OutPoint.String() < want[j].OutPoint.String()
+		})
+		sort.Slice(credits, func(i, j int) bool {
+			return credits[i].OutPoint.String() < credits[j].OutPoint.String()
+		})
+		if !reflect.DeepEqual(credits, want) {
+			t.Fatalf("expected credits %v of %v, got %v", want,
+				addr, credits)
+		}
+	}
+	cbCredit := AddressCredit{
+		OutPoint: wire.OutPoint{Hash: cbRec.Hash},
+		Height:   100,
+		Amount:   1e8,
+	}
+	recvCredit := func(index uint32, height int32) AddressCredit {
+		return AddressCredit{
+			OutPoint: wire.OutPoint{Hash: recvRec.Hash, Index: index},
+			Height:   height,
+			Amount:   btcutil.Amount(recvTx.TxOut[index].Value),
+		}
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if !store.AddressesIndexed(ns) {
+			t.Fatal("new store is not indexed by address")
+		}
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, cbRec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, recvRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		for i := uint32(0); i < 2; i++ {
+			err := store.AddCredit(ns, recvRec, nil, i, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		checkCredits(ns, addr1, []AddressCredit{
+			cbCredit, recvCredit(0, -1),
+		})
+		checkCredits(ns, addr2, []AddressCredit{recvCredit(1, -1)})
+	})
+
+	// Mined credits are recorded at the height of their block, and remain
+	// indexed when they are spent.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, recvRec, b101); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, spendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		checkCredits(ns, addr1, []AddressCredit{
+			cbCredit, recvCredit(0, 101),
+		})
+		checkCredits(ns, addr2, []AddressCredit{recvCredit(1, 101)})
+	})
+
+	// Reorganized credits are unmined again, and reorganized coinbase
+	// credits are removed.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.Rollback(ns, 100); err != nil {
+			t.Fatal(err)
+		}
+		checkCredits(ns, addr1, []AddressCredit{recvCredit(0, -1)})
+		checkCredits(ns, addr2, []AddressCredit{recvCredit(1, -1)})
+
+		// The credits of removed unmined transactions are removed.
+		if err := store.RemoveUnminedTx(ns, recvRec); err != nil {
+			t.Fatal(err)
+		}
+		checkCredits(ns, addr1, nil)
+		checkCredits(ns, addr2, nil)
+
+		var n int
+		err := store.ForEachAddressCredit(ns,
+			func(string, *AddressCredit) error {
+				n++
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Fatalf("expected no indexed credits, got %d", n)
+		}
+	})
+}
@@ -56,7 +56,11 @@ var byteOrder = binary.BigEndian
 // change.
 const (
 	// LatestVersion is the most recent store version.
-	LatestVersion = 1
+	LatestVersion = 2
+
+	// accountCreditsVersion is the first version recording the account of
+	// every unspent credit.
+	accountCreditsVersion = 2
 )
 
 // This package makes assumptions that the width of a chainhash.Hash is always
@@ -75,6 +79,16 @@ var (
 	bucketUnmined        = []byte("m")
 	bucketUnminedCredits = []byte("mc")
 	bucketUnminedInputs  = []byte("mi")
+	bucketLockedOutputs  = []byte("lo")
+	bucketReplacements   = []byte("rp")
+	bucketConflicts      = []byte("cf")
+	bucketTxLabels       = []byte("l")
+	bucketTxFees         = []byte("fee")
+	bucketCreditAccounts = []byte("ca")
+	bucketAcctUnspent    = []byte("au")
+	bucketAcctBalances   = []byte("ab")
+	bucketAddrCredits    = []byte("ac")
+	bucketCreditAddrs    = []byte("cad")
 )
 
 // Root (namespace) bucket keys
@@ -82,6 +96,7 @@ var (
 	rootCreateDate   = []byte("date")
 	rootVersion      = []byte("vers")
 	rootMinedBalance = []byte("bal")
+	rootAddrsIndexed = []byte("addr")
 )
 
 // The root bucket's mined balance k/v pair records the total balance for all
@@ -569,7 +584,11 @@ func putRawCredit(ns walletdb.ReadWriteBucket, k, v []byte) error {
 func putUnspentCredit(ns walletdb.ReadWriteBucket, cred *credit) error {
 	k := keyCredit(&cred.outPoint.Hash, cred.outPoint.Index, &cred.block)
 	v := valueUnspentCredit(cred)
-	return putRawCredit(ns, k, v)
+	if err := putRawCredit(ns, k, v); err != nil {
+		return err
+	}
+	op := canonicalOutPoint(&cred.outPoint.Hash, cred.outPoint.Index)
+	return setAddrCreditHeight(ns, op, cred.block.Height)
 }
 
 func extractRawCreditTxRecordKey(k []byte) []byte {
@@ -784,12 +803,7 @@ func valueUnspent(block *Block) []byte {
 func putUnspent(ns walletdb.ReadWriteBucket, outPoint *wire.OutPoint, block *Block) error {
 	k := canonicalOutPoint(&outPoint.Hash, outPoint.Index)
 	v := valueUnspent(block)
-	err := ns.NestedReadWriteBucket(bucketUnspent).Put(k, v)
-	if err != nil {
-		str := "cannot put unspent"
-		return storeError(ErrDatabase, str, err)
-	}
-	return nil
+	return putRawUnspent(ns, k, v)
 }
 
 func putRawUnspent(ns walletdb.ReadWriteBucket, k, v []byte) error {
@@ -798,7 +812,7 @@ func putRawUnspent(ns walletdb.ReadWriteBucket, k, v []byte) error {
 		str := "cannot put unspent"
 		return storeError(ErrDatabase, str, err)
 	}
-	return nil
+	return indexAcctUnspent(ns, k, int32(byteOrder.Uint32(v)))
 }
 
 func readUnspentBlock(v []byte, block *Block) error {
@@ -838,12 +852,22 @@ func existsRawUnspent(ns walletdb.ReadBucket, k []byte) (credKey []byte) {
 }
 
 func deleteRawUnspent(ns walletdb.ReadWriteBucket, k []byte) error {
+	var block Block
+	v := ns.NestedReadBucket(bucketUnspent).Get(k)
+	if v != nil {
+		if err := readUnspentBlock(v, &block); err != nil {
+			return err
+		}
+	}
 	err := ns.NestedReadWriteBucket(bucketUnspent).Delete(k)
 	if err != nil {
 		str := "failed to delete unspent"
 		return storeError(ErrDatabase, str, err)
 	}
-	return nil
+	if v == nil {
+		return nil
+	}
+	return unindexAcctUnspent(ns, k, block.Height)
 }
 
 // All transaction debits (inputs which spend credits) are keyed as such:
@@ -1057,7 +1081,10 @@ func putRawUnminedCredit(ns walletdb.ReadWriteBucket, k, v []byte) error {
 		str := "cannot put unmined credit"
 		return storeError(ErrDatabase, str, err)
 	}
-	return nil
+	if err := setAddrCreditHeight(ns, k, -1); err != nil {
+		return err
+	}
+	return indexAcctUnspent(ns, k, -1)
 }
 
 func fetchRawUnminedCreditIndex(k []byte) (uint32, error) {
@@ -1091,12 +1118,16 @@ func existsRawUnminedCredit(ns walletdb.ReadBucket, k []byte) []byte {
 }
 
 func deleteRawUnminedCredit(ns walletdb.ReadWriteBucket, k []byte) error {
+	exists := existsRawUnminedCredit(ns, k) != nil
 	err := ns.NestedReadWriteBucket(bucketUnminedCredits).Delete(k)
 	if err != nil {
 		str := "failed to delete unmined credit"
 		return storeError(ErrDatabase, str, err)
 	}
-	return nil
+	if !exists {
+		return nil
+	}
+	return unindexAcctUnspent(ns, k, -1)
 }
 
 // unminedCreditIterator allows for cursor iteration over all credits, in order,
@@ -1262,8 +1293,727 @@ func deleteRawUnminedInput(ns walletdb.ReadWriteBucket, k []byte) error {
 	return nil
 }
 
+// deleteRawUnminedInputSpender removes spendTxHash from the list of unmined
+// transactions spending the serialized outpoint.  Hashes of transactions which
+// are no longer unmined are removed as well.  The unmined input is deleted
+// once no unmined spenders remain.
+func deleteRawUnminedInputSpender(ns walletdb.ReadWriteBucket, k []byte,
+	spendTxHash *chainhash.Hash) error {
+
+	var remaining []byte
+	for _, hash := range fetchUnminedInputSpendTxHashes(ns, k) {
+		if hash == *spendTxHash || existsRawUnmined(ns, hash[:]) == nil {
+			continue
+		}
+		remaining = append(remaining, hash[:]...)
+	}
+	if len(remaining) == 0 {
+		return deleteRawUnminedInput(ns, k)
+	}
+
+	err := ns.NestedReadWriteBucket(bucketUnminedInputs).Put(k, remaining)
+	if err != nil {
+		str := "failed to put unmined input"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// Outputs leased by a caller are saved in the locked outputs bucket.  Leased
+// outputs are not considered for coin selection until their lease expires.
+// Stores created before this bucket existed create it on the first lease.
+//
+// The key is serialized as such:
+//
+//   [0:32]   Transaction hash (32 bytes)
+//   [32:36]  Output index (4 bytes)
+//
+// The value is serialized as such:
+//
+//   [0:32]   Lock ID (32 bytes)
+//   [32:40]  Expiry time in nanoseconds since the Unix epoch (8 bytes)
+
+func valueLockedOutput(id LockID, expiry time.Time) []byte {
+	v := make([]byte, 40)
+	copy(v, id[:])
+	byteOrder.PutUint64(v[32:40], uint64(expiry.UnixNano()))
+	return v
+}
+
+func putLockedOutput(ns walletdb.ReadWriteBucket, op *wire.OutPoint,
+	id LockID, expiry time.Time) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucketLockedOutputs)
+	if err != nil {
+		str := "failed to create locked outputs bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	k := canonicalOutPoint(&op.Hash, op.Index)
+	err = b.Put(k, valueLockedOutput(id, expiry))
+	if err != nil {
+		str := "failed to put locked output"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+func readLockedOutput(v []byte, id *LockID, expiry *time.Time) error {
+	if len(v) != 40 {
+		str := fmt.Sprintf("%s: short read (expected %d bytes, read %d)",
+			bucketLockedOutputs, 40, len(v))
+		return storeError(ErrData, str, nil)
+	}
+	copy(id[:], v)
+	*expiry = time.Unix(0, int64(byteOrder.Uint64(v[32:40])))
+	return nil
+}
+
+// fetchLockedOutput returns the lock ID and expiry of a locked output.  The
+// returned bool is false if the output has never been locked or was unlocked.
+func fetchLockedOutput(ns walletdb.ReadBucket, op *wire.OutPoint) (LockID,
+	time.Time, bool, error) {
+
+	var (
+		id     LockID
+		expiry time.Time
+	)
+	b := ns.NestedReadBucket(bucketLockedOutputs)
+	if b == nil {
+		return id, expiry, false, nil
+	}
+	v := b.Get(canonicalOutPoint(&op.Hash, op.Index))
+	if v == nil {
+		return id, expiry, false, nil
+	}
+	err := readLockedOutput(v, &id, &expiry)
+	return id, expiry, err == nil, err
+}
+
+// forEachLockedOutput calls f for every locked output, including those whose
+// lease has already expired.
+func forEachLockedOutput(ns walletdb.ReadBucket,
+	f func(wire.OutPoint, LockID, time.Time) error) error {
+
+	b := ns.NestedReadBucket(bucketLockedOutputs)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		var (
+			op     wire.OutPoint
+			id     LockID
+			expiry time.Time
+		)
+		if err := readCanonicalOutPoint(k, &op); err != nil {
+			return err
+		}
+		if err := readLockedOutput(v, &id, &expiry); err != nil {
+			return err
+		}
+		return f(op, id, expiry)
+	})
+}
+
+func deleteLockedOutput(ns walletdb.ReadWriteBucket, op *wire.OutPoint) error {
+	b := ns.NestedReadWriteBucket(bucketLockedOutputs)
+	if b == nil {
+		return nil
+	}
+	err := b.Delete(canonicalOutPoint(&op.Hash, op.Index))
+	if err != nil {
+		str := "failed to delete locked output"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// Transactions replaced by a fee bump are recorded in the replacements bucket,
+// and unmined transactions which were double spent by a mined transaction, or
+// by an unmined transaction inserted after them, are recorded in the conflicts
+// bucket.  Both buckets map a transaction hash to the hash of another
+// transaction.  Stores created before these
+// buckets existed create them on the first write.
+//
+// The key is serialized as such:
+//
+//   [0:32]   Transaction hash (32 bytes)
+//
+// The value is serialized as such:
+//
+//   [0:32]   Replacing or conflicting transaction hash (32 bytes)
+
+func putTxHashMapping(ns walletdb.ReadWriteBucket, bucket []byte,
+	k, v *chainhash.Hash) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucket)
+	if err != nil {
+		str := fmt.Sprintf("failed to create bucket %s", bucket)
+		return storeError(ErrDatabase, str, err)
+	}
+	err = b.Put(k[:], v[:])
+	if err != nil {
+		str := fmt.Sprintf("failed to put %s for transaction %v",
+			bucket, k)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+func deleteTxHashMapping(ns walletdb.ReadWriteBucket, bucket []byte,
+	k *chainhash.Hash) error {
+
+	b := ns.NestedReadWriteBucket(bucket)
+	if b == nil {
+		return nil
+	}
+	err := b.Delete(k[:])
+	if err != nil {
+		str := fmt.Sprintf("failed to delete %s for transaction %v",
+			bucket, k)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+func fetchTxHashMapping(ns walletdb.ReadBucket, bucket []byte,
+	k *chainhash.Hash) (*chainhash.Hash, error) {
+
+	b := ns.NestedReadBucket(bucket)
+	if b == nil {
+		return nil, nil
+	}
+	v := b.Get(k[:])
+	if v == nil {
+		return nil, nil
+	}
+	if len(v) != chainhash.HashSize {
+		str := fmt.Sprintf("%s: short read (expected %d bytes, read %d)",
+			bucket, chainhash.HashSize, len(v))
+		return nil, storeError(ErrData, str, nil)
+	}
+	var hash chainhash.Hash
+	copy(hash[:], v)
+	return &hash, nil
+}
+
+// Transaction labels are saved in the transaction labels bucket, keyed by the
+// transaction hash.  Stores created before this bucket existed create it on
+// the first label.
+//
+// The key is serialized as such:
+//
+//   [0:32]   Transaction hash (32 bytes)
+//
+// The value is the UTF-8 label.
+
+func putTxLabel(ns walletdb.ReadWriteBucket, txHash *chainhash.Hash,
+	label string) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucketTxLabels)
+	if err != nil {
+		str := "failed to create transaction labels bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	err = b.Put(txHash[:], []byte(label))
+	if err != nil {
+		str := fmt.Sprintf("failed to put label for transaction %v",
+			txHash)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchTxLabel returns the label of a transaction, or an empty string if the
+// transaction is not labeled.
+func fetchTxLabel(ns walletdb.ReadBucket, txHash *chainhash.Hash) string {
+	b := ns.NestedReadBucket(bucketTxLabels)
+	if b == nil {
+		return ""
+	}
+	return string(b.Get(txHash[:]))
+}
+
+func deleteTxLabel(ns walletdb.ReadWriteBucket, txHash *chainhash.Hash) error {
+	b := ns.NestedReadWriteBucket(bucketTxLabels)
+	if b == nil {
+		return nil
+	}
+	err := b.Delete(txHash[:])
+	if err != nil {
+		str := fmt.Sprintf("failed to delete label for transaction %v",
+			txHash)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// Transaction fees are saved in the transaction fees bucket, keyed by the
+// transaction hash, when the previous outputs of every input of a transaction
+// are known.  Stores created before this bucket existed create it on the first
+// fee.
+//
+// The key is serialized as such:
+//
+//   [0:32]   Transaction hash (32 bytes)
+//
+// The value is serialized as such:
+//
+//   [0:8]    Fee (8 bytes)
+
+func putTxFee(ns walletdb.ReadWriteBucket, txHash *chainhash.Hash,
+	fee btcutil.Amount) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucketTxFees)
+	if err != nil {
+		str := "failed to create transaction fees bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	v := make([]byte, 8)
+	byteOrder.PutUint64(v, uint64(fee))
+	err = b.Put(txHash[:], v)
+	if err != nil {
+		str := fmt.Sprintf("failed to put fee for transaction %v",
+			txHash)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchTxFee returns the fee of a transaction, and whether the fee is recorded.
+func fetchTxFee(ns walletdb.ReadBucket, txHash *chainhash.Hash) (btcutil.Amount,
+	bool, error) {
+
+	b := ns.NestedReadBucket(bucketTxFees)
+	if b == nil {
+		return 0, false, nil
+	}
+	v := b.Get(txHash[:])
+	if v == nil {
+		return 0, false, nil
+	}
+	if len(v) != 8 {
+		str := fmt.Sprintf("%s: short read (expected 8 bytes, read %d)",
+			bucketTxFees, len(v))
+		return 0, false, storeError(ErrData, str, nil)
+	}
+	return btcutil.Amount(byteOrder.Uint64(v)), true, nil
+}
+
+func deleteTxFee(ns walletdb.ReadWriteBucket, txHash *chainhash.Hash) error {
+	b := ns.NestedReadWriteBucket(bucketTxFees)
+	if b == nil {
+		return nil
+	}
+	err := b.Delete(txHash[:])
+	if err != nil {
+		str := fmt.Sprintf("failed to delete fee for transaction %v",
+			txHash)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// Credits added with the account of their output are recorded in the credit
+// accounts bucket, keyed by the outpoint of the credit.  Unspent mined and
+// unmined credits with a recorded account are indexed by account and block
+// height in the account unspent bucket, and the total amount of the indexed
+// credits of each account is recorded in the account balances bucket.  The
+// balances of an account are therefore calculated from its total and the
+// indexed credits of the most recent blocks, without reading every unspent
+// output.  Stores of version 1 create these buckets when they are upgraded to
+// version 2, which indexes their unspent credits.
+//
+// The credit accounts key is serialized as such:
+//
+//   [0:32]   Transaction hash (32 bytes)
+//   [32:36]  Output index (4 bytes)
+//
+// The credit accounts value is serialized as such:
+//
+//   [0:4]    Account (4 bytes)
+//   [4:12]   Amount (8 bytes)
+//   [12]     Flags (1 byte)
+//              0x01: Coinbase
+//
+// The account unspent key is serialized as such, with an empty value:
+//
+//   [0:4]    Account (4 bytes)
+//   [4:8]    Block height, or 0xffffffff if unmined (4 bytes)
+//   [8:40]   Transaction hash (32 bytes)
+//   [40:44]  Output index (4 bytes)
+//
+// The account balances key is the account (4 bytes), and the value the total
+// amount of the indexed credits of the account (8 bytes).
+
+// creditAccount is the account and amount recorded for a credit.
+type creditAccount struct {
+	account  uint32
+	amount   btcutil.Amount
+	coinbase bool
+}
+
+func valueCreditAccount(ca *creditAccount) []byte {
+	v := make([]byte, 13)
+	byteOrder.PutUint32(v, ca.account)
+	byteOrder.PutUint64(v[4:12], uint64(ca.amount))
+	if ca.coinbase {
+		v[12] = 1 << 0
+	}
+	return v
+}
+
+func putCreditAccount(ns walletdb.ReadWriteBucket, k []byte,
+	ca *creditAccount) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucketCreditAccounts)
+	if err != nil {
+		str := "failed to create credit accounts bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	err = b.Put(k, valueCreditAccount(ca))
+	if err != nil {
+		str := "failed to put credit account"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchCreditAccount returns the account recorded for the credit with the
+// outpoint key k, or nil if no account is recorded.
+func fetchCreditAccount(ns walletdb.ReadBucket, k []byte) (*creditAccount, error) {
+	b := ns.NestedReadBucket(bucketCreditAccounts)
+	if b == nil {
+		return nil, nil
+	}
+	v := b.Get(k)
+	if v == nil {
+		return nil, nil
+	}
+	if len(v) < 13 {
+		str := fmt.Sprintf("%s: short read (expected 13 bytes, "+
+			"read %d)", bucketCreditAccounts, len(v))
+		return nil, storeError(ErrData, str, nil)
+	}
+	return &creditAccount{
+		account:  byteOrder.Uint32(v),
+		amount:   btcutil.Amount(byteOrder.Uint64(v[4:12])),
+		coinbase: v[12]&(1<<0) != 0,
+	}, nil
+}
+
+func keyAcctUnspent(account uint32, height int32, k []byte) []byte {
+	key := make([]byte, 44)
+	byteOrder.PutUint32(key, account)
+	byteOrder.PutUint32(key[4:8], uint32(height))
+	copy(key[8:44], k)
+	return key
+}
+
+// existsAcctUnspent returns whether the account unspent key is indexed.  The
+// keys are stored without a value, for which Get returns nil, so the key is
+// looked up with a cursor instead.
+func existsAcctUnspent(b walletdb.ReadBucket, key []byte) bool {
+	k, _ := b.ReadCursor().Seek(key)
+	return bytes.Equal(k, key)
+}
+
+func fetchAcctBalance(ns walletdb.ReadBucket, account uint32) (btcutil.Amount, error) {
+	b := ns.NestedReadBucket(bucketAcctBalances)
+	if b == nil {
+		return 0, nil
+	}
+	var k [4]byte
+	byteOrder.PutUint32(k[:], account)
+	v := b.Get(k[:])
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 8 {
+		str := fmt.Sprintf("%s: short read (expected 8 bytes, read %d)",
+			bucketAcctBalances, len(v))
+		return 0, storeError(ErrData, str, nil)
+	}
+	return btcutil.Amount(byteOrder.Uint64(v)), nil
+}
+
+func putAcctBalance(ns walletdb.ReadWriteBucket, account uint32,
+	amt btcutil.Amount) error {
+
+	b, err := ns.CreateBucketIfNotExists(bucketAcctBalances)
+	if err != nil {
+		str := "failed to create account balances bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	var k [4]byte
+	byteOrder.PutUint32(k[:], account)
+	v := make([]byte, 8)
+	byteOrder.PutUint64(v, uint64(amt))
+	err = b.Put(k[:], v)
+	if err != nil {
+		str := fmt.Sprintf("failed to put balance of account %d",
+			account)
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// indexAcctUnspent indexes the unspent credit with the outpoint key k, mined
+// at height or unmined if height is -1, by the account recorded for the
+// credit.  Credits without a recorded account, and credits which are already
+// indexed, are skipped.
+func indexAcctUnspent(ns walletdb.ReadWriteBucket, k []byte, height int32) error {
+	ca, err := fetchCreditAccount(ns, k)
+	if err != nil || ca == nil {
+		return err
+	}
+
+	b, err := ns.CreateBucketIfNotExists(bucketAcctUnspent)
+	if err != nil {
+		str := "failed to create account unspent bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	key := keyAcctUnspent(ca.account, height, k)
+	if existsAcctUnspent(b, key) {
+		return nil
+	}
+	if err := b.Put(key, nil); err != nil {
+		str := "failed to put account unspent"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	bal, err := fetchAcctBalance(ns, ca.account)
+	if err != nil {
+		return err
+	}
+	return putAcctBalance(ns, ca.account, bal+ca.amount)
+}
+
+// unindexAcctUnspent removes the credit with the outpoint key k, mined at
+// height or unmined if height is -1, from the index of the account recorded
+// for the credit.
+func unindexAcctUnspent(ns walletdb.ReadWriteBucket, k []byte, height int32) error {
+	ca, err := fetchCreditAccount(ns, k)
+	if err != nil || ca == nil {
+		return err
+	}
+
+	b := ns.NestedReadWriteBucket(bucketAcctUnspent)
+	if b == nil {
+		return nil
+	}
+	key := keyAcctUnspent(ca.account, height, k)
+	if !existsAcctUnspent(b, key) {
+		return nil
+	}
+	if err := b.Delete(key); err != nil {
+		str := "failed to delete account unspent"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	bal, err := fetchAcctBalance(ns, ca.account)
+	if err != nil {
+		return err
+	}
+	return putAcctBalance(ns, ca.account, bal-ca.amount)
+}
+
+// acctsIndexed returns whether the credits of all accounts are indexed, which
+// is the case for stores created or upgraded to record the accounts of their
+// credits.
+func acctsIndexed(ns walletdb.ReadBucket) bool {
+	v := ns.Get(rootVersion)
+	return len(v) == 4 && byteOrder.Uint32(v) >= accountCreditsVersion
+}
+
+// Mined and unmined credits paying to addresses are indexed by address in the
+// address credits bucket, whether or not they are spent, so the amounts
+// received by an address are read without iterating over every transaction.
+// The credit addresses bucket maps the outpoint of each indexed credit to its
+// addresses, so the index is updated when the credit is mined, moved to
+// unmined by a reorg, or removed.  Stores created before these buckets existed
+// create them when their credits are indexed by IndexAddressCredits.
+//
+// The address credits key is serialized as such, where n is the length of the
+// encoded address:
+//
+//   [0]          Address length (1 byte)
+//   [1:n+1]      Encoded address (n bytes)
+//   [n+1:n+33]   Transaction hash (32 bytes)
+//   [n+33:n+37]  Output index (4 bytes)
+//
+// The address credits value is serialized as such:
+//
+//   [0:4]    Block height, or 0xffffffff if unmined (4 bytes)
+//   [4:12]   Amount (8 bytes)
+//
+// The credit addresses key is the canonical outpoint of the credit, and the
+// value each address of the credit serialized as a length byte followed by the
+// encoded address.
+
+func keyAddrCredit(addr string, k []byte) []byte {
+	key := make([]byte, 1+len(addr)+36)
+	key[0] = byte(len(addr))
+	copy(key[1:], addr)
+	copy(key[1+len(addr):], k)
+	return key
+}
+
+func valueAddrCredit(height int32, amount btcutil.Amount) []byte {
+	v := make([]byte, 12)
+	byteOrder.PutUint32(v, uint32(height))
+	byteOrder.PutUint64(v[4:12], uint64(amount))
+	return v
+}
+
+// readRawAddrCredit reads the address, outpoint, height and amount of an
+// address credit.
+func readRawAddrCredit(k, v []byte, op *wire.OutPoint) (addr string,
+	height int32, amount btcutil.Amount, err error) {
+
+	if len(k) == 0 || len(k) != 1+int(k[0])+36 || len(v) != 12 {
+		str := fmt.Sprintf("%s: malformed address credit",
+			bucketAddrCredits)
+		return "", 0, 0, storeError(ErrData, str, nil)
+	}
+	n := 1 + int(k[0])
+	if err := readCanonicalOutPoint(k[n:], op); err != nil {
+		return "", 0, 0, err
+	}
+	return string(k[1:n]), int32(byteOrder.Uint32(v)),
+		btcutil.Amount(byteOrder.Uint64(v[4:12])), nil
+}
+
+// fetchCreditAddrs returns the addresses recorded for the credit with the
+// outpoint key k.
+func fetchCreditAddrs(ns walletdb.ReadBucket, k []byte) ([]string, error) {
+	b := ns.NestedReadBucket(bucketCreditAddrs)
+	if b == nil {
+		return nil, nil
+	}
+	v := b.Get(k)
+	var addrs []string
+	for len(v) != 0 {
+		n := 1 + int(v[0])
+		if len(v) < n {
+			str := fmt.Sprintf("%s: short read", bucketCreditAddrs)
+			return nil, storeError(ErrData, str, nil)
+		}
+		addrs = append(addrs, string(v[1:n]))
+		v = v[n:]
+	}
+	return addrs, nil
+}
+
+// putAddrCredit indexes the credit with the outpoint key k, mined at height or
+// unmined if height is -1, by each of its addresses.  Credits which are
+// already indexed are skipped.
+func putAddrCredit(ns walletdb.ReadWriteBucket, k []byte, addrs []string,
+	height int32, amount btcutil.Amount) error {
+
+	if len(addrs) == 0 {
+		return nil
+	}
+	addrsBucket, err := ns.CreateBucketIfNotExists(bucketCreditAddrs)
+	if err != nil {
+		str := "failed to create credit addresses bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	if addrsBucket.Get(k) != nil {
+		return nil
+	}
+	credits, err := ns.CreateBucketIfNotExists(bucketAddrCredits)
+	if err != nil {
+		str := "failed to create address credits bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	var addrsValue []byte
+	v := valueAddrCredit(height, amount)
+	for _, addr := range addrs {
+		if len(addr) > 0xff {
+			continue
+		}
+		addrsValue = append(addrsValue, byte(len(addr)))
+		addrsValue = append(addrsValue, addr...)
+		if err := credits.Put(keyAddrCredit(addr, k), v); err != nil {
+			str := "failed to put address credit"
+			return storeError(ErrDatabase, str, err)
+		}
+	}
+	if err := addrsBucket.Put(k, addrsValue); err != nil {
+		str := "failed to put credit addresses"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// setAddrCreditHeight records the credit with the outpoint key k as mined at
+// height, or unmined if height is -1.  It returns without error if the credit
+// is not indexed.
+func setAddrCreditHeight(ns walletdb.ReadWriteBucket, k []byte, height int32) error {
+	addrs, err := fetchCreditAddrs(ns, k)
+	if err != nil || len(addrs) == 0 {
+		return err
+	}
+	credits := ns.NestedReadWriteBucket(bucketAddrCredits)
+	for _, addr := range addrs {
+		key := keyAddrCredit(addr, k)
+		v := credits.Get(key)
+		if len(v) != 12 {
+			str := fmt.Sprintf("%s: missing credit of address %s",
+				bucketAddrCredits, addr)
+			return storeError(ErrData, str, nil)
+		}
+		newv := make([]byte, 12)
+		copy(newv, v)
+		byteOrder.PutUint32(newv, uint32(height))
+		if err := credits.Put(key, newv); err != nil {
+			str := "failed to put address credit"
+			return storeError(ErrDatabase, str, err)
+		}
+	}
+	return nil
+}
+
+// deleteAddrCredit removes the credit with the outpoint key k from the index
+// of each of its addresses.
+func deleteAddrCredit(ns walletdb.ReadWriteBucket, k []byte) error {
+	addrs, err := fetchCreditAddrs(ns, k)
+	if err != nil || len(addrs) == 0 {
+		return err
+	}
+	credits := ns.NestedReadWriteBucket(bucketAddrCredits)
+	for _, addr := range addrs {
+		if err := credits.Delete(keyAddrCredit(addr, k)); err != nil {
+			str := "failed to delete address credit"
+			return storeError(ErrDatabase, str, err)
+		}
+	}
+	err = ns.NestedReadWriteBucket(bucketCreditAddrs).Delete(k)
+	if err != nil {
+		str := "failed to delete credit addresses"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// addrsIndexed returns whether the credits of all addresses are indexed.
+func addrsIndexed(ns walletdb.ReadBucket) bool {
+	return ns.Get(rootAddrsIndexed) != nil
+}
+
+func putAddrsIndexed(ns walletdb.ReadWriteBucket) error {
+	err := ns.Put(rootAddrsIndexed, []byte{1})
+	if err != nil {
+		str := "failed to mark address credits indexed"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
 // openStore opens an existing transaction store from the passed namespace.
-func openStore(ns walletdb.ReadBucket) error {
+// Stores of older versions are only opened when allowOld is set.
+func openStore(ns walletdb.ReadBucket, allowOld bool) error {
 	v := ns.Get(rootVersion)
 	if len(v) != 4 {
 		str := "no transaction store exists in namespace"
@@ -1271,7 +2021,7 @@ func openStore(ns walletdb.ReadBucket) error {
 	}
 	version := byteOrder.Uint32(v)
 
-	if version < LatestVersion {
+	if version < LatestVersion && !allowOld {
 		str := fmt.Sprintf("a database upgrade is required to upgrade "+
 			"wtxmgr from recorded version %d to the latest version %d",
 			version, LatestVersion)
@@ -1284,19 +2034,76 @@ func openStore(ns walletdb.ReadBucket) error {
 		return storeError(ErrUnknownVersion, str, nil)
 	}
 
-	// Upgrade the tx store as needed, one version at a time, until
-	// LatestVersion is reached.  Versions are not skipped when performing
-	// database upgrades, and each upgrade is done in its own transaction.
-	//
-	// No upgrades yet.
-	//if version < LatestVersion {
-	//	err := scopedUpdate(namespace, func(ns walletdb.Bucket) error {
-	//	})
-	//	if err != nil {
-	//		// Handle err
-	//	}
-	//}
+	return nil
+}
+
+// upgradeStore upgrades the tx store in the namespace namespaceKey, one
+// version at a time, until LatestVersion is reached.  Versions are not skipped
+// when performing database upgrades, and each upgrade is done in its own
+// transaction.  The account of the credits of stores upgraded to record them
+// is returned by account, and ErrNeedsUpgrade is returned for them when
+// account is nil.
+func upgradeStore(db walletdb.DB, namespaceKey []byte,
+	account func(tx walletdb.ReadTx, pkScript []byte) (uint32, bool)) error {
+
+	var version uint32
+	err := scopedView(db, namespaceKey, func(ns walletdb.ReadBucket) error {
+		v := ns.Get(rootVersion)
+		if len(v) != 4 {
+			str := "no transaction store exists in namespace"
+			return storeError(ErrNoExists, str, nil)
+		}
+		version = byteOrder.Uint32(v)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if version < accountCreditsVersion && account == nil {
+		str := fmt.Sprintf("upgrading wtxmgr from recorded version %d "+
+			"to version %d requires the accounts of its credits",
+			version, accountCreditsVersion)
+		return storeError(ErrNeedsUpgrade, str, nil)
+	}
+	if version < accountCreditsVersion {
+		log.Infof("Upgrading transaction store to version %d",
+			accountCreditsVersion)
+		err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+			ns := tx.ReadWriteBucket(namespaceKey)
+			return upgradeToVersion2(ns, func(pkScript []byte) (uint32, bool) {
+				return account(tx, pkScript)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeToVersion2 upgrades the store from version 1 to version 2 by
+// recording the account of each unspent credit.  This is the only migration of
+// the account index, so credits recorded with their account before the upgrade
+// are not rewritten.
+func upgradeToVersion2(ns walletdb.ReadWriteBucket,
+	account func(pkScript []byte) (uint32, bool)) error {
 
+	if err := indexAccountCredits(ns, account); err != nil {
+		return err
+	}
+	return putVersion(ns, accountCreditsVersion)
+}
+
+func putVersion(ns walletdb.ReadWriteBucket, version uint32) error {
+	v := make([]byte, 4)
+	byteOrder.PutUint32(v, version)
+	err := ns.Put(rootVersion, v)
+	if err != nil {
+		str := "failed to store database version"
+		return storeError(ErrDatabase, str, err)
+	}
 	return nil
 }
 
@@ -1384,7 +2191,40 @@ func createStore(ns walletdb.ReadWriteBucket) error {
 		return storeError(ErrDatabase, str, err)
 	}
 
-	return nil
+	_, err = ns.CreateBucket(bucketLockedOutputs)
+	if err != nil {
+		str := "failed to create locked outputs bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	_, err = ns.CreateBucket(bucketReplacements)
+	if err != nil {
+		str := "failed to create replacements bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	_, err = ns.CreateBucket(bucketConflicts)
+	if err != nil {
+		str := "failed to create conflicts bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	_, err = ns.CreateBucket(bucketTxLabels)
+	if err != nil {
+		str := "failed to create transaction labels bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	_, err = ns.CreateBucket(bucketTxFees)
+	if err != nil {
+		str := "failed to create transaction fees bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	// A new store has no credits, so the credits of all addresses are
+	// indexed.  The credits of all accounts are indexed in stores of the
+	// latest version.
+	return putAddrsIndexed(ns)
 }
 
 func scopedUpdate(db walletdb.DB, namespaceKey []byte, f func(walletdb.ReadWriteBucket) error) error {
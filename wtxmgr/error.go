@@ -49,6 +49,23 @@ const (
 	// but the database version is newer than latest version known to this
 	// software.  This likely indicates an outdated binary.
 	ErrUnknownVersion
+
+	// ErrUnknownOutput describes an error where an output is not an
+	// unspent output known to the store.
+	ErrUnknownOutput
+
+	// ErrOutputAlreadyLocked describes an error where an output cannot be
+	// locked because it is currently leased under a different lock ID.
+	ErrOutputAlreadyLocked
+
+	// ErrOutputUnlockNotAllowed describes an error where an output cannot
+	// be unlocked because it is currently leased under a different lock
+	// ID.
+	ErrOutputUnlockNotAllowed
+
+	// ErrUnknownTransaction describes an error where a transaction is not
+	// recorded by the store.
+	ErrUnknownTransaction
 )
 
 var errStrs = [...]string{
@@ -59,6 +76,11 @@ var errStrs = [...]string{
 	ErrNoExists:       "ErrNoExists",
 	ErrNeedsUpgrade:   "ErrNeedsUpgrade",
 	ErrUnknownVersion: "ErrUnknownVersion",
+
+	ErrUnknownOutput:          "ErrUnknownOutput",
+	ErrOutputAlreadyLocked:    "ErrOutputAlreadyLocked",
+	ErrOutputUnlockNotAllowed: "ErrOutputUnlockNotAllowed",
+	ErrUnknownTransaction:     "ErrUnknownTransaction",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// recordTxFee saves the fee paid by a transaction inserted into the store when
+// the previous outputs of all of its inputs are outputs of transactions
+// recorded by the store.  This is always the case for transactions authored by
+// the wallet, and for incoming transactions spending outputs of other recorded
+// transactions.
+func (s *Store) recordTxFee(ns walletdb.ReadWriteBucket, rec *TxRecord) error {
+	if blockchain.IsCoinBaseTx(&rec.MsgTx) {
+		return nil
+	}
+	if _, known, err := fetchTxFee(ns, &rec.Hash); err != nil || known {
+		return err
+	}
+
+	var totalInput btcutil.Amount
+	for _, input := range rec.MsgTx.TxIn {
+		amount, known, err := fetchPrevOutputAmount(ns,
+			&input.PreviousOutPoint)
+		if err != nil || !known {
+			return err
+		}
+		totalInput += amount
+	}
+	fee := totalInput
+	for _, output := range rec.MsgTx.TxOut {
+		fee -= btcutil.Amount(output.Value)
+	}
+	// Invalid transactions spending more than their inputs are recorded
+	// without a fee.
+	if fee < 0 {
+		return nil
+	}
+	return putTxFee(ns, &rec.Hash, fee)
+}
+
+// fetchPrevOutputAmount returns the amount of the output op of a transaction
+// recorded by the store, and whether the output is known.
+func fetchPrevOutputAmount(ns walletdb.ReadBucket, op *wire.OutPoint) (btcutil.Amount,
+	bool, error) {
+
+	v := existsRawUnmined(ns, op.Hash[:])
+	if v == nil {
+		_, v = latestTxRecord(ns, &op.Hash)
+	}
+	if v == nil {
+		return 0, false, nil
+	}
+
+	var rec TxRecord
+	if err := readRawTxRecord(&op.Hash, v, &rec); err != nil {
+		return 0, false, err
+	}
+	if int(op.Index) >= len(rec.MsgTx.TxOut) {
+		return 0, false, nil
+	}
+	return btcutil.Amount(rec.MsgTx.TxOut[op.Index].Value), true, nil
+}
+
+// txDetailsFee returns the fee of the transaction described by details, and
+// whether the fee is known.  The fees of transactions recorded before fees were
+// saved are only known when every input is a debit.
+func txDetailsFee(ns walletdb.ReadBucket, details *TxDetails) (btcutil.Amount,
+	bool, error) {
+
+	fee, known, err := fetchTxFee(ns, &details.Hash)
+	if err != nil || known {
+		return fee, known, err
+	}
+	if len(details.Debits) != len(details.MsgTx.TxIn) {
+		return 0, false, nil
+	}
+
+	for _, debit := range details.Debits {
+		fee += debit.Amount
+	}
+	for _, output := range details.MsgTx.TxOut {
+		fee -= btcutil.Amount(output.Value)
+	}
+	return fee, true, nil
+}
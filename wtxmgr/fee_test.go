@@ -0,0 +1,118 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestTxFees ensures that the fees of transactions are recorded when the
+// previous outputs of their inputs are known, and returned with the
+// transaction details.
+func TestTxFees(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	b101 := &BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	cbRec, err := NewTxRecordFromMsgTx(newCoinBase(1e8), b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&cbRec.Hash, 0, 5e7, 4e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incomingRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&chainhash.Hash{1}, 0, 1e6), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkFee := func(ns walletdb.ReadBucket, rec *TxRecord,
+		fee btcutil.Amount, known bool) {
+
+		details, err := store.TxDetails(ns, &rec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details.FeeKnown != known || details.Fee != fee {
+			t.Fatalf("expected fee %v (known %v) of %v, got %v "+
+				"(known %v)", fee, known, rec.Hash, details.Fee,
+				details.FeeKnown)
+		}
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, cbRec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, spendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, incomingRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		err = store.AddCredit(ns, incomingRec, nil, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Coinbases pay no fee, and the fee of the incoming
+		// transaction is unknown as its previous output is not
+		// recorded.
+		checkFee(ns, cbRec, 0, false)
+		checkFee(ns, spendRec, 1e7, true)
+		checkFee(ns, incomingRec, 0, false)
+	})
+
+	// The fee remains known once the transaction is mined.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, spendRec, b101); err != nil {
+			t.Fatal(err)
+		}
+		checkFee(ns, spendRec, 1e7, true)
+	})
+
+	// Fees are also returned when ranging over mined transactions.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		err := store.RangeTransactions(ns, 101, 101,
+			func(details []TxDetails) (bool, error) {
+				if len(details) != 1 || details[0].Fee != 1e7 ||
+					!details[0].FeeKnown {
+
+					t.Fatalf("unexpected details %v", details)
+				}
+				return false, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
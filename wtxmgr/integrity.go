@@ -0,0 +1,314 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// InconsistencyKind identifies the kind of an inconsistency between the
+// records of the store.
+type InconsistencyKind uint8
+
+// These constants are the kinds of inconsistencies found by CheckIntegrity.
+const (
+	// UnindexedCredit is a credit of a mined transaction which is not
+	// spent by another mined transaction, but missing from the index of
+	// unspent outputs.  Repairing it adds the output to the index.
+	UnindexedCredit InconsistencyKind = iota
+
+	// StaleUnspent is an output of the index of unspent outputs without
+	// an unspent credit.  Repairing it removes the output from the index.
+	StaleUnspent
+
+	// OrphanedCredit is a credit of a transaction which is not recorded
+	// or does not have the credited output.  Repairing it removes the
+	// credit.
+	OrphanedCredit
+
+	// BalanceMismatch is a recorded mined balance which does not match
+	// the total amount of the unspent credits of mined transactions.
+	// Repairing it rewrites the balance.
+	BalanceMismatch
+
+	// IrrelevantCredit is a credit of an output which does not pay to a
+	// script of the wallet.  It is not repaired, since the store can not
+	// tell whether the credit or the records of the wallet are wrong.
+	IrrelevantCredit
+)
+
+var inconsistencyKindStrs = [...]string{
+	UnindexedCredit:  "UnindexedCredit",
+	StaleUnspent:     "StaleUnspent",
+	OrphanedCredit:   "OrphanedCredit",
+	BalanceMismatch:  "BalanceMismatch",
+	IrrelevantCredit: "IrrelevantCredit",
+}
+
+// String returns the InconsistencyKind as a human-readable name.
+func (k InconsistencyKind) String() string {
+	if k < InconsistencyKind(len(inconsistencyKindStrs)) {
+		return inconsistencyKindStrs[k]
+	}
+	return fmt.Sprintf("InconsistencyKind(%d)", k)
+}
+
+// Inconsistency describes an inconsistency between the records of the store
+// found by CheckIntegrity.
+type Inconsistency struct {
+	Kind InconsistencyKind
+
+	// OutPoint is the output of the inconsistent credit or unspent output.
+	// It is not set for a BalanceMismatch.
+	OutPoint wire.OutPoint
+
+	// Block is the block of the transaction of the credit or unspent
+	// output, or nil if the transaction is unmined.  It is not set for a
+	// BalanceMismatch.
+	Block *Block
+
+	// Balance is the recorded mined balance and ExpectedBalance is the
+	// total amount of the unspent mined credits of a BalanceMismatch.
+	Balance         btcutil.Amount
+	ExpectedBalance btcutil.Amount
+
+	// Repaired is set once Repair repaired the inconsistency.
+	Repaired bool
+
+	// key is the key of the inconsistent credit or unspent output.
+	key []byte
+}
+
+// String returns a human-readable description of the inconsistency.
+func (i *Inconsistency) String() string {
+	switch i.Kind {
+	case UnindexedCredit:
+		return fmt.Sprintf("unspent credit %v is missing from the "+
+			"unspent index", i.OutPoint)
+	case StaleUnspent:
+		return fmt.Sprintf("unspent index records %v without an "+
+			"unspent credit", i.OutPoint)
+	case OrphanedCredit:
+		return fmt.Sprintf("credit %v has no transaction output",
+			i.OutPoint)
+	case BalanceMismatch:
+		return fmt.Sprintf("mined balance %v does not match the unspent "+
+			"credits totaling %v", i.Balance, i.ExpectedBalance)
+	case IrrelevantCredit:
+		return fmt.Sprintf("credit %v does not pay to the wallet",
+			i.OutPoint)
+	}
+	return i.Kind.String()
+}
+
+// minedCreditOutput returns the output script of the mined credit with the
+// key k, or nil if the transaction of the credit is not recorded or does not
+// have the credited output.
+func minedCreditOutput(ns walletdb.ReadBucket, k []byte) []byte {
+	recKey := extractRawCreditTxRecordKey(k)
+	recVal := existsRawTxRecord(ns, recKey)
+	if recVal == nil {
+		return nil
+	}
+	pkScript, err := fetchRawTxRecordPkScript(recKey, recVal,
+		extractRawCreditIndex(k))
+	if err != nil {
+		return nil
+	}
+	return pkScript
+}
+
+// CheckIntegrity walks the credits, unspent outputs and mined balance of the
+// store and returns the inconsistencies between them, which may be repaired
+// with Repair.  When isRelevant is not nil, it is called with the output
+// script of each credit, and the credits of scripts for which it returns false
+// are reported as IrrelevantCredit.
+func (s *Store) CheckIntegrity(ns walletdb.ReadBucket,
+	isRelevant func(pkScript []byte) bool) ([]Inconsistency, error) {
+
+	var incs []Inconsistency
+	var expectedBalance btcutil.Amount
+
+	// Every unspent mined credit of a recorded transaction output must be
+	// indexed as unspent, and its amount is part of the mined balance.
+	err := ns.NestedReadBucket(bucketCredits).ForEach(func(k, v []byte) error {
+		if len(k) < 72 {
+			str := fmt.Sprintf("%s: short key (expected %d "+
+				"bytes, read %d)", bucketCredits, 72, len(k))
+			return storeError(ErrData, str, nil)
+		}
+		amt, spent, err := fetchRawCreditAmountSpent(v)
+		if err != nil {
+			return err
+		}
+
+		inc := Inconsistency{
+			Block: new(Block),
+			key:   append([]byte(nil), k...),
+		}
+		copy(inc.OutPoint.Hash[:], k[0:32])
+		inc.OutPoint.Index = extractRawCreditIndex(k)
+		err = readRawTxRecordBlock(extractRawCreditTxRecordKey(k),
+			inc.Block)
+		if err != nil {
+			return err
+		}
+
+		pkScript := minedCreditOutput(ns, k)
+		if pkScript == nil {
+			inc.Kind = OrphanedCredit
+			incs = append(incs, inc)
+			return nil
+		}
+		if isRelevant != nil && !isRelevant(pkScript) {
+			inc.Kind = IrrelevantCredit
+			incs = append(incs, inc)
+		}
+		if spent {
+			return nil
+		}
+
+		expectedBalance += amt
+		unspentKey := canonicalOutPoint(&inc.OutPoint.Hash,
+			inc.OutPoint.Index)
+		if !bytes.Equal(existsRawUnspent(ns, unspentKey), k) {
+			inc.Kind = UnindexedCredit
+			incs = append(incs, inc)
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(Error); ok {
+			return nil, err
+		}
+		str := "failed iterating credits"
+		return nil, storeError(ErrDatabase, str, err)
+	}
+
+	// Every indexed unspent output must be an unspent credit of a
+	// recorded transaction output.
+	err = ns.NestedReadBucket(bucketUnspent).ForEach(func(k, v []byte) error {
+		inc := Inconsistency{
+			Kind:  StaleUnspent,
+			Block: new(Block),
+			key:   append([]byte(nil), k...),
+		}
+		err := readCanonicalOutPoint(k, &inc.OutPoint)
+		if err != nil {
+			return err
+		}
+		err = readUnspentBlock(v, inc.Block)
+		if err != nil {
+			return err
+		}
+
+		credKey := existsRawUnspent(ns, k)
+		_, spent, err := fetchRawCreditAmountSpent(
+			existsRawCredit(ns, credKey),
+		)
+		if err != nil || spent || minedCreditOutput(ns, credKey) == nil {
+			incs = append(incs, inc)
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(Error); ok {
+			return nil, err
+		}
+		str := "failed iterating unspent outputs"
+		return nil, storeError(ErrDatabase, str, err)
+	}
+
+	// Every credit of an unmined transaction must be of a recorded
+	// transaction output.
+	err = ns.NestedReadBucket(bucketUnminedCredits).ForEach(func(k, v []byte) error {
+		inc := Inconsistency{key: append([]byte(nil), k...)}
+		err := readCanonicalOutPoint(k, &inc.OutPoint)
+		if err != nil {
+			return err
+		}
+
+		var rec TxRecord
+		recVal := existsRawUnmined(ns, inc.OutPoint.Hash[:])
+		err = readRawTxRecord(&inc.OutPoint.Hash, recVal, &rec)
+		if err != nil || int(inc.OutPoint.Index) >= len(rec.MsgTx.TxOut) {
+			inc.Kind = OrphanedCredit
+			incs = append(incs, inc)
+			return nil
+		}
+		pkScript := rec.MsgTx.TxOut[inc.OutPoint.Index].PkScript
+		if isRelevant != nil && !isRelevant(pkScript) {
+			inc.Kind = IrrelevantCredit
+			incs = append(incs, inc)
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(Error); ok {
+			return nil, err
+		}
+		str := "failed iterating unmined credits"
+		return nil, storeError(ErrDatabase, str, err)
+	}
+
+	balance, err := fetchMinedBalance(ns)
+	if err != nil {
+		return nil, err
+	}
+	if balance != expectedBalance {
+		incs = append(incs, Inconsistency{
+			Kind:            BalanceMismatch,
+			Balance:         balance,
+			ExpectedBalance: expectedBalance,
+		})
+	}
+
+	return incs, nil
+}
+
+// Repair repairs the inconsistencies returned by CheckIntegrity, which must
+// not have been modified since, and sets their Repaired field.  Inconsistencies
+// of kind IrrelevantCredit are not repaired.
+func (s *Store) Repair(ns walletdb.ReadWriteBucket, incs []Inconsistency) error {
+	for i := range incs {
+		inc := &incs[i]
+
+		var err error
+		switch inc.Kind {
+		case UnindexedCredit:
+			err = putUnspent(ns, &inc.OutPoint, inc.Block)
+
+		case StaleUnspent:
+			err = deleteRawUnspent(ns, inc.key)
+
+		case OrphanedCredit:
+			if inc.Block != nil {
+				err = deleteRawCredit(ns, inc.key)
+			} else {
+				err = deleteRawUnminedCredit(ns, inc.key)
+			}
+			if err == nil {
+				err = deleteAddrCredit(ns, canonicalOutPoint(
+					&inc.OutPoint.Hash, inc.OutPoint.Index))
+			}
+
+		case BalanceMismatch:
+			err = putMinedBalance(ns, inc.ExpectedBalance)
+
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		inc.Repaired = true
+	}
+	return nil
+}
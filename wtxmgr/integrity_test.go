@@ -0,0 +1,123 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestCheckIntegrity ensures that inconsistencies between the credits, unspent
+// outputs and mined balance of the store are found and repaired.
+func TestCheckIntegrity(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	tx := spendOutput(&chainhash.Hash{}, 0, 1e8)
+	rec, err := NewTxRecordFromMsgTx(tx, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, rec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, rec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	checkKinds := func(ns walletdb.ReadBucket,
+		isRelevant func([]byte) bool, kinds ...InconsistencyKind) []Inconsistency {
+
+		incs, err := store.CheckIntegrity(ns, isRelevant)
+		if err != nil {
+			t.Fatalf("unable to check integrity: %v", err)
+		}
+		if len(incs) != len(kinds) {
+			t.Fatalf("expected %d inconsistencies, got %v",
+				len(kinds), incs)
+		}
+		for i, inc := range incs {
+			if inc.Kind != kinds[i] {
+				t.Fatalf("expected inconsistency %v, got %v",
+					kinds[i], inc.Kind)
+			}
+		}
+		return incs
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		checkKinds(ns, nil)
+
+		// Credits are checked against the scripts of the wallet.
+		incs := checkKinds(ns, func([]byte) bool { return false },
+			IrrelevantCredit)
+		if incs[0].OutPoint.Hash != rec.Hash {
+			t.Fatalf("expected credit of %v, got %v", rec.Hash,
+				incs[0].OutPoint)
+		}
+
+		// Corrupt the store by removing the credit from the unspent
+		// index and overwriting the mined balance.
+		unspentKey := make([]byte, 36)
+		copy(unspentKey, rec.Hash[:])
+		err := ns.NestedReadWriteBucket([]byte("u")).Delete(unspentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var bal [8]byte
+		binary.BigEndian.PutUint64(bal[:], 5)
+		if err := ns.Put([]byte("bal"), bal[:]); err != nil {
+			t.Fatal(err)
+		}
+
+		incs = checkKinds(ns, nil, UnindexedCredit, BalanceMismatch)
+		if incs[1].Balance != 5 || incs[1].ExpectedBalance != 1e8 {
+			t.Fatalf("unexpected balance mismatch: %v", &incs[1])
+		}
+		if err := store.Repair(ns, incs); err != nil {
+			t.Fatalf("unable to repair store: %v", err)
+		}
+		for _, inc := range incs {
+			if !inc.Repaired {
+				t.Fatalf("inconsistency %v was not repaired", &inc)
+			}
+		}
+
+		// The repaired store is consistent and reports the credit.
+		checkKinds(ns, nil)
+		balance, err := store.Balance(ns, 1, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance != 1e8 {
+			t.Fatalf("expected balance of 1 BTC, got %v", balance)
+		}
+		unspent, err := store.UnspentOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unspent) != 1 {
+			t.Fatalf("expected 1 unspent output, got %d",
+				len(unspent))
+		}
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// TxLabelLimit is the maximum length in bytes of a transaction label.
+const TxLabelLimit = 500
+
+// LabelTransaction sets the label of a transaction recorded by the store,
+// replacing any existing label.  An empty label removes the label of the
+// transaction.  The label must be valid UTF-8 and no longer than TxLabelLimit
+// bytes.  ErrUnknownTransaction is returned if the transaction is not
+// recorded by the store.
+func (s *Store) LabelTransaction(ns walletdb.ReadWriteBucket,
+	txHash chainhash.Hash, label string) error {
+
+	if len(label) > TxLabelLimit || !utf8.ValidString(label) {
+		str := fmt.Sprintf("transaction labels must be valid UTF-8 of "+
+			"at most %d bytes", TxLabelLimit)
+		return storeError(ErrInput, str, nil)
+	}
+
+	if existsRawUnmined(ns, txHash[:]) == nil {
+		if _, v := latestTxRecord(ns, &txHash); v == nil {
+			str := fmt.Sprintf("transaction %v is not recorded",
+				txHash)
+			return storeError(ErrUnknownTransaction, str, nil)
+		}
+	}
+
+	if label == "" {
+		return deleteTxLabel(ns, &txHash)
+	}
+	return putTxLabel(ns, &txHash, label)
+}
+
+// FetchTxLabel returns the label of a transaction, or an empty string if the
+// transaction is not labeled.
+func (s *Store) FetchTxLabel(ns walletdb.ReadBucket,
+	txHash chainhash.Hash) string {
+
+	return fetchTxLabel(ns, &txHash)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestLabelTransaction ensures that labels can only be set for recorded
+// transactions and are returned with the transaction details.
+func TestLabelTransaction(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	isErrorCode := func(err error, code ErrorCode) bool {
+		serr, ok := err.(Error)
+		return ok && serr.Code == code
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		err := store.LabelTransaction(ns, chainhash.Hash{1}, "unknown")
+		if !isErrorCode(err, ErrUnknownTransaction) {
+			t.Fatalf("expected ErrUnknownTransaction, got %v", err)
+		}
+
+		longLabel := strings.Repeat("a", TxLabelLimit+1)
+		err = store.LabelTransaction(ns, cbRec.Hash, longLabel)
+		if !isErrorCode(err, ErrInput) {
+			t.Fatalf("expected ErrInput, got %v", err)
+		}
+
+		err = store.LabelTransaction(ns, cbRec.Hash, "payment 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if label := store.FetchTxLabel(ns, cbRec.Hash); label != "payment 1" {
+			t.Fatalf("expected label %q, got %q", "payment 1", label)
+		}
+		details, err := store.TxDetails(ns, &cbRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details.Label != "payment 1" {
+			t.Fatalf("expected details label %q, got %q",
+				"payment 1", details.Label)
+		}
+
+		// An empty label removes the label.
+		err = store.LabelTransaction(ns, cbRec.Hash, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if label := store.FetchTxLabel(ns, cbRec.Hash); label != "" {
+			t.Fatalf("expected removed label, got %q", label)
+		}
+	})
+}
+
+// TestLabelRangeTransactions ensures that the labels of both mined and unmined
+// transactions are included in the details passed to RangeTransactions.
+func TestLabelRangeTransactions(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend := spendOutput(&cbRec.Hash, 0, 5e7)
+	spendRec, err := NewTxRecordFromMsgTx(spend, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := map[chainhash.Hash]string{
+		cbRec.Hash:    "mined",
+		spendRec.Hash: "unmined",
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, spendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		for hash, label := range labels {
+			if err := store.LabelTransaction(ns, hash, label); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		found := 0
+		err := store.RangeTransactions(ns, 0, -1,
+			func(details []TxDetails) (bool, error) {
+				for _, detail := range details {
+					if detail.Label != labels[detail.Hash] {
+						t.Fatalf("expected label %q for %v, "+
+							"got %q", labels[detail.Hash],
+							detail.Hash, detail.Label)
+					}
+					found++
+				}
+				return false, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != len(labels) {
+			t.Fatalf("expected %d transactions, got %d",
+				len(labels), found)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// LockID identifies the holder of an output lease.  Only the holder of a lease
+// may extend or release it before it expires.
+type LockID [32]byte
+
+// LockedOutput describes an output which is leased and therefore unavailable
+// for coin selection until the lease expires.
+type LockedOutput struct {
+	OutPoint   wire.OutPoint
+	LockID     LockID
+	Expiration time.Time
+}
+
+// LockOutput leases an unspent output known to the store for duration.
+// Leasing an output which is already leased under the same lock ID extends
+// the lease.  If the output is leased under a different lock ID, and that
+// lease has not yet expired, ErrOutputAlreadyLocked is returned.  The
+// expiration time of the lease is returned on success.
+func (s *Store) LockOutput(ns walletdb.ReadWriteBucket, id LockID,
+	op wire.OutPoint, duration time.Duration) (time.Time, error) {
+
+	k := canonicalOutPoint(&op.Hash, op.Index)
+	if existsRawUnspent(ns, k) == nil && existsRawUnminedCredit(ns, k) == nil {
+		str := fmt.Sprintf("output %v is not an unspent output", op)
+		return time.Time{}, storeError(ErrUnknownOutput, str, nil)
+	}
+	return lockOutput(ns, id, op, duration)
+}
+
+// LockUntrackedOutput leases an output which the store does not track as a
+// credit, such as a deposit of a multisig account recorded by the caller.
+// The store cannot tell whether such an output exists or is spent by a mined
+// transaction, so this is left to the caller; only outputs spent by unmined
+// transactions are rejected.  Otherwise it behaves as LockOutput.
+func (s *Store) LockUntrackedOutput(ns walletdb.ReadWriteBucket, id LockID,
+	op wire.OutPoint, duration time.Duration) (time.Time, error) {
+
+	return lockOutput(ns, id, op, duration)
+}
+
+// lockOutput leases op under id for duration unless it is spent by an unmined
+// transaction or already leased under a different lock ID.
+func lockOutput(ns walletdb.ReadWriteBucket, id LockID, op wire.OutPoint,
+	duration time.Duration) (time.Time, error) {
+
+	if duration <= 0 {
+		str := fmt.Sprintf("invalid lease duration %v", duration)
+		return time.Time{}, storeError(ErrInput, str, nil)
+	}
+	k := canonicalOutPoint(&op.Hash, op.Index)
+	if existsRawUnminedInput(ns, k) != nil {
+		str := fmt.Sprintf("output %v is spent by an unmined "+
+			"transaction", op)
+		return time.Time{}, storeError(ErrUnknownOutput, str, nil)
+	}
+
+	now := time.Now()
+	lockedID, expiry, locked, err := fetchLockedOutput(ns, &op)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if locked && lockedID != id && now.Before(expiry) {
+		str := fmt.Sprintf("output %v is leased until %v", op, expiry)
+		return time.Time{}, storeError(ErrOutputAlreadyLocked, str, nil)
+	}
+
+	// Strip the monotonic clock reading so the returned expiry equals the
+	// persisted one.
+	expiry = now.Add(duration).Round(0)
+	if err := putLockedOutput(ns, &op, id, expiry); err != nil {
+		return time.Time{}, err
+	}
+	return expiry, nil
+}
+
+// UnlockOutput releases the lease of an output.  If the output is leased under
+// a different lock ID, and that lease has not yet expired,
+// ErrOutputUnlockNotAllowed is returned.  Releasing an output which is not
+// leased is not an error.
+func (s *Store) UnlockOutput(ns walletdb.ReadWriteBucket, id LockID,
+	op wire.OutPoint) error {
+
+	lockedID, expiry, locked, err := fetchLockedOutput(ns, &op)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	if lockedID != id && time.Now().Before(expiry) {
+		str := fmt.Sprintf("output %v is leased under a different "+
+			"lock ID", op)
+		return storeError(ErrOutputUnlockNotAllowed, str, nil)
+	}
+	return deleteLockedOutput(ns, &op)
+}
+
+// DeleteExpiredLockedOutputs removes the leases which have expired from the
+// store.  Expired leases no longer prevent an output from being spent, so this
+// only keeps the lock bucket from growing without bound.
+func (s *Store) DeleteExpiredLockedOutputs(ns walletdb.ReadWriteBucket) error {
+	var expired []wire.OutPoint
+	now := time.Now()
+	err := forEachLockedOutput(ns, func(op wire.OutPoint, _ LockID,
+		expiry time.Time) error {
+
+		if !now.Before(expiry) {
+			expired = append(expired, op)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keys may not be deleted while iterating over the bucket.
+	for i := range expired {
+		if err := deleteLockedOutput(ns, &expired[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockedOutputs returns all outputs with an unexpired lease.
+func (s *Store) LockedOutputs(ns walletdb.ReadBucket) ([]LockedOutput, error) {
+	var locked []LockedOutput
+	now := time.Now()
+	err := forEachLockedOutput(ns, func(op wire.OutPoint, id LockID,
+		expiry time.Time) error {
+
+		if now.Before(expiry) {
+			locked = append(locked, LockedOutput{
+				OutPoint:   op,
+				LockID:     id,
+				Expiration: expiry,
+			})
+		}
+		return nil
+	})
+	return locked, err
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wtxmgr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/walletdb"
+	. "github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// TestLockOutput ensures that outputs can only be leased and released by the
+// holder of an unexpired lease, and that leases are persisted.
+func TestLockOutput(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	// Insert a mined transaction with a single credit which will be
+	// leased throughout the test.
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, cbRec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	op := wire.OutPoint{Hash: cbRec.Hash, Index: 0}
+	id1, id2 := LockID{1}, LockID{2}
+
+	isErrorCode := func(err error, code ErrorCode) bool {
+		serr, ok := err.(Error)
+		return ok && serr.Code == code
+	}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		// Outputs unknown to the store cannot be leased.
+		unknown := wire.OutPoint{Hash: cbRec.Hash, Index: 1}
+		_, err := store.LockOutput(ns, id1, unknown, time.Hour)
+		if !isErrorCode(err, ErrUnknownOutput) {
+			t.Fatalf("expected ErrUnknownOutput, got %v", err)
+		}
+
+		expiry, err := store.LockOutput(ns, id1, op, time.Hour)
+		if err != nil {
+			t.Fatalf("unable to lease output: %v", err)
+		}
+
+		// The output may not be leased or released by another ID.
+		_, err = store.LockOutput(ns, id2, op, time.Hour)
+		if !isErrorCode(err, ErrOutputAlreadyLocked) {
+			t.Fatalf("expected ErrOutputAlreadyLocked, got %v", err)
+		}
+		err = store.UnlockOutput(ns, id2, op)
+		if !isErrorCode(err, ErrOutputUnlockNotAllowed) {
+			t.Fatalf("expected ErrOutputUnlockNotAllowed, got %v",
+				err)
+		}
+
+		locked, err := store.LockedOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 1 || locked[0].OutPoint != op ||
+			locked[0].LockID != id1 ||
+			!locked[0].Expiration.Equal(expiry) {
+
+			t.Fatalf("unexpected locked outputs %v", locked)
+		}
+
+		if err := store.UnlockOutput(ns, id1, op); err != nil {
+			t.Fatalf("unable to release output: %v", err)
+		}
+		locked, err = store.LockedOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 0 {
+			t.Fatalf("expected no locked outputs, got %v", locked)
+		}
+
+		// Lease the output for a short duration to check expiry.
+		_, err = store.LockOutput(ns, id1, op, 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("unable to lease output: %v", err)
+		}
+	})
+
+	time.Sleep(20 * time.Millisecond)
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		// Expired leases are not reported and may be taken over.
+		locked, err := store.LockedOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 0 {
+			t.Fatalf("expected no locked outputs, got %v", locked)
+		}
+		if _, err := store.LockOutput(ns, id2, op, time.Hour); err != nil {
+			t.Fatalf("unable to lease expired output: %v", err)
+		}
+	})
+}
+
+// TestDeleteExpiredLockedOutputs ensures that only expired leases are removed
+// from the store.
+func TestDeleteExpiredLockedOutputs(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8, 2e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiring := wire.OutPoint{Hash: cbRec.Hash, Index: 0}
+	leased := wire.OutPoint{Hash: cbRec.Hash, Index: 1}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		for i := uint32(0); i < 2; i++ {
+			err := store.AddCredit(ns, cbRec, b100, i, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := store.LockOutput(ns, LockID{1}, expiring,
+			10*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.LockOutput(ns, LockID{1}, leased, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	time.Sleep(20 * time.Millisecond)
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.DeleteExpiredLockedOutputs(ns); err != nil {
+			t.Fatal(err)
+		}
+
+		// Expired leases are never reported by LockedOutputs, so the
+		// lock bucket itself is checked for their removal.
+		var numLeases int
+		err := ns.NestedReadBucket([]byte("lo")).ForEach(
+			func(_, _ []byte) error {
+				numLeases++
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if numLeases != 1 {
+			t.Fatalf("expected 1 stored lease, got %d", numLeases)
+		}
+
+		locked, err := store.LockedOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 1 || locked[0].OutPoint != leased {
+			t.Fatalf("unexpected locked outputs %v", locked)
+		}
+	})
+}
+
+// TestLockUntrackedOutput ensures that outputs which are not credits of the
+// store can be leased unless they are spent by an unmined transaction.
+func TestLockUntrackedOutput(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	// Insert a mined transaction without credits, and an unmined
+	// transaction spending its second output.
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8, 2e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&cbRec.Hash, 1, 1e8), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untracked := wire.OutPoint{Hash: cbRec.Hash, Index: 0}
+	spent := wire.OutPoint{Hash: cbRec.Hash, Index: 1}
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, spendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := store.LockOutput(ns, LockID{1}, untracked, time.Hour)
+		if serr, ok := err.(Error); !ok || serr.Code != ErrUnknownOutput {
+			t.Fatalf("expected ErrUnknownOutput, got %v", err)
+		}
+		_, err = store.LockUntrackedOutput(
+			ns, LockID{1}, untracked, time.Hour,
+		)
+		if err != nil {
+			t.Fatalf("unable to lease untracked output: %v", err)
+		}
+		_, err = store.LockUntrackedOutput(
+			ns, LockID{2}, untracked, time.Hour,
+		)
+		if serr, ok := err.(Error); !ok ||
+			serr.Code != ErrOutputAlreadyLocked {
+
+			t.Fatalf("expected ErrOutputAlreadyLocked, got %v", err)
+		}
+		_, err = store.LockUntrackedOutput(ns, LockID{1}, spent, time.Hour)
+		if serr, ok := err.(Error); !ok || serr.Code != ErrUnknownOutput {
+			t.Fatalf("expected ErrUnknownOutput, got %v", err)
+		}
+
+		locked, err := store.LockedOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 1 || locked[0].OutPoint != untracked {
+			t.Fatalf("unexpected locked outputs %v", locked)
+		}
+	})
+}
@@ -33,12 +33,16 @@ type DebitRecord struct {
 
 // TxDetails is intended to provide callers with access to rich details
 // regarding a relevant transaction and which inputs and outputs are credit or
-// debits.
+// debits.  The fee paid by the transaction is only known, and FeeKnown set,
+// when the previous outputs of every input are known to the store.
 type TxDetails struct {
 	TxRecord
-	Block   BlockMeta
-	Credits []CreditRecord
-	Debits  []DebitRecord
+	Block    BlockMeta
+	Credits  []CreditRecord
+	Debits   []DebitRecord
+	Label    string
+	Fee      btcutil.Amount
+	FeeKnown bool
 }
 
 // minedTxDetails fetches the TxDetails for the mined transaction with hash
@@ -90,7 +94,15 @@ func (s *Store) minedTxDetails(ns walletdb.ReadBucket, txHash *chainhash.Hash, r
 
 		details.Debits = append(details.Debits, debIter.elem)
 	}
-	return &details, debIter.err
+	if debIter.err != nil {
+		return nil, debIter.err
+	}
+	details.Label = fetchTxLabel(ns, txHash)
+	details.Fee, details.FeeKnown, err = txDetailsFee(ns, &details)
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
 }
 
 // unminedTxDetails fetches the TxDetails for the unmined transaction with the
@@ -157,6 +169,11 @@ func (s *Store) unminedTxDetails(ns walletdb.ReadBucket, txHash *chainhash.Hash,
 			Index:  uint32(i),
 		})
 	}
+	details.Label = fetchTxLabel(ns, txHash)
+	details.Fee, details.FeeKnown, err = txDetailsFee(ns, &details)
+	if err != nil {
+		return nil, err
+	}
 
 	return &details, nil
 }
@@ -341,6 +358,12 @@ func (s *Store) rangeBlockTransactions(ns walletdb.ReadBucket, begin, end int32,
 				return false, debIter.err
 			}
 
+			detail.Label = fetchTxLabel(ns, &txHash)
+			detail.Fee, detail.FeeKnown, err = txDetailsFee(ns, &detail)
+			if err != nil {
+				return false, err
+			}
+
 			details = append(details, detail)
 		}
 
@@ -386,6 +409,173 @@ func (s *Store) RangeTransactions(ns walletdb.ReadBucket, begin, end int32,
 	return err
 }
 
+// LastBlock returns the newest block at or below the height maxHeight in which
+// a wallet transaction was mined, or nil if no wallet transactions were mined
+// at or below it.  Listing transactions from the returned block lists the same
+// transactions as listing them from any block between it and maxHeight.
+func (s *Store) LastBlock(ns walletdb.ReadBucket, maxHeight int32) (*Block, error) {
+	it := makeReadBlockIterator(ns, maxHeight)
+	if !it.prev() {
+		return nil, it.err
+	}
+	block := it.elem.Block
+	return &block, nil
+}
+
+// TxQuery describes a page of the transactions returned by QueryTransactions.
+// Mined transactions are ordered by the height of their block, and by the order
+// they were marked mined within a block.  Unmined transactions are ordered
+// after all mined transactions by their hash.
+type TxQuery struct {
+	// MinHeight and MaxHeight are the inclusive bounds of the heights of
+	// the blocks of the returned mined transactions.  A negative
+	// MaxHeight does not bound the heights.
+	MinHeight int32
+	MaxHeight int32
+
+	// IncludeUnmined includes unmined transactions in the query.
+	IncludeUnmined bool
+
+	// Reverse orders the transactions in reverse, returning the newest
+	// transactions first.
+	Reverse bool
+
+	// Offset is the number of transactions skipped before the page, and
+	// Limit the maximum number of transactions in the page.  A zero Limit
+	// does not limit the number of transactions.
+	Offset uint32
+	Limit  uint32
+}
+
+// QueryTransactions returns the page of transaction details described by q.
+// Only the details of the transactions in the page are read, so wallets with
+// many transactions can be paged through without reading all their details.
+func (s *Store) QueryTransactions(ns walletdb.ReadBucket, q *TxQuery) ([]TxDetails,
+	error) {
+
+	var details []TxDetails
+	skip := q.Offset
+	full := func() bool {
+		return q.Limit != 0 && uint32(len(details)) >= q.Limit
+	}
+
+	// Transactions before the offset are skipped by counting the
+	// transactions of each block record and unmined record, without
+	// reading their details.
+	queryUnmined := func() error {
+		var hashes []chainhash.Hash
+		err := ns.NestedReadBucket(bucketUnmined).ForEach(func(k, v []byte) error {
+			if len(k) < 32 {
+				str := fmt.Sprintf("%s: short key (expected %d "+
+					"bytes, read %d)", bucketUnmined, 32, len(k))
+				return storeError(ErrData, str, nil)
+			}
+
+			var txHash chainhash.Hash
+			copy(txHash[:], k)
+			hashes = append(hashes, txHash)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if skip >= uint32(len(hashes)) {
+			skip -= uint32(len(hashes))
+			return nil
+		}
+
+		for i := skip; i < uint32(len(hashes)) && !full(); i++ {
+			txHash := &hashes[i]
+			if q.Reverse {
+				txHash = &hashes[uint32(len(hashes))-1-i]
+			}
+			v := existsRawUnmined(ns, txHash[:])
+			detail, err := s.unminedTxDetails(ns, txHash, v)
+			if err != nil {
+				return err
+			}
+			details = append(details, *detail)
+		}
+		skip = 0
+		return nil
+	}
+
+	queryBlocks := func() error {
+		minHeight, maxHeight := q.MinHeight, q.MaxHeight
+		if minHeight < 0 {
+			minHeight = 0
+		}
+		if maxHeight < 0 {
+			maxHeight = int32(^uint32(0) >> 1)
+		}
+
+		var blockIter blockIterator
+		var advance func(*blockIterator) bool
+		if !q.Reverse {
+			blockIter = makeReadBlockIterator(ns, minHeight)
+			advance = func(it *blockIterator) bool {
+				if !it.next() {
+					return false
+				}
+				return it.elem.Height <= maxHeight
+			}
+		} else {
+			blockIter = makeReadBlockIterator(ns, maxHeight)
+			advance = func(it *blockIterator) bool {
+				if !it.prev() {
+					return false
+				}
+				return minHeight <= it.elem.Height
+			}
+		}
+
+		for !full() && advance(&blockIter) {
+			block := &blockIter.elem
+			n := uint32(len(block.transactions))
+			if skip >= n {
+				skip -= n
+				continue
+			}
+
+			for i := skip; i < n && !full(); i++ {
+				txHash := &block.transactions[i]
+				if q.Reverse {
+					txHash = &block.transactions[n-1-i]
+				}
+				k := keyTxRecord(txHash, &block.Block)
+				v := existsRawTxRecord(ns, k)
+				if v == nil {
+					str := fmt.Sprintf("missing transaction %v "+
+						"for block %v", txHash, block.Height)
+					return storeError(ErrData, str, nil)
+				}
+				detail, err := s.minedTxDetails(ns, txHash, k, v)
+				if err != nil {
+					return err
+				}
+				details = append(details, *detail)
+			}
+			skip = 0
+		}
+		return blockIter.err
+	}
+
+	if q.Reverse && q.IncludeUnmined {
+		if err := queryUnmined(); err != nil {
+			return nil, err
+		}
+	}
+	if err := queryBlocks(); err != nil {
+		return nil, err
+	}
+	if !q.Reverse && q.IncludeUnmined && !full() {
+		if err := queryUnmined(); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
 // PreviousPkScripts returns a slice of previous output scripts for each credit
 // output this transaction record debits from.
 func (s *Store) PreviousPkScripts(ns walletdb.ReadBucket, rec *TxRecord, block *Block) ([][]byte, error) {
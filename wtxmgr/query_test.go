@@ -737,3 +737,190 @@ func TestPreviousPkScripts(t *testing.T) {
 		t.Fatal("Failed after inserting tx D")
 	}
 }
+
+func TestQueryTransactions(t *testing.T) {
+	t.Parallel()
+
+	s, db, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b100 := makeBlockMeta(100)
+	b101 := makeBlockMeta(101)
+	newRec := func(tx *wire.MsgTx) *TxRecord {
+		rec, err := NewTxRecordFromMsgTx(tx, timeNow())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	recA := newRec(newCoinBase(1e8))
+	recB := newRec(newCoinBase(2e8))
+	recC := newRec(newCoinBase(3e8))
+	recU := newRec(spendOutput(&recA.Hash, 0, 5e7))
+
+	commitDBTx(t, s, db, func(ns walletdb.ReadWriteBucket) {
+		inserts := []struct {
+			rec   *TxRecord
+			block *BlockMeta
+		}{
+			{recA, &b100},
+			{recB, &b100},
+			{recC, &b101},
+			{recU, nil},
+		}
+		for _, ins := range inserts {
+			if err := s.InsertTx(ns, ins.rec, ins.block); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	tests := []struct {
+		name  string
+		query TxQuery
+		exp   []*TxRecord
+	}{
+		{
+			name:  "all",
+			query: TxQuery{MaxHeight: -1, IncludeUnmined: true},
+			exp:   []*TxRecord{recA, recB, recC, recU},
+		},
+		{
+			name:  "mined",
+			query: TxQuery{MaxHeight: -1},
+			exp:   []*TxRecord{recA, recB, recC},
+		},
+		{
+			name: "reverse",
+			query: TxQuery{
+				MaxHeight:      -1,
+				IncludeUnmined: true,
+				Reverse:        true,
+			},
+			exp: []*TxRecord{recU, recC, recB, recA},
+		},
+		{
+			name: "reverse page",
+			query: TxQuery{
+				MaxHeight:      -1,
+				IncludeUnmined: true,
+				Reverse:        true,
+				Offset:         1,
+				Limit:          2,
+			},
+			exp: []*TxRecord{recC, recB},
+		},
+		{
+			name:  "page within block",
+			query: TxQuery{MinHeight: 100, MaxHeight: 100, Offset: 1},
+			exp:   []*TxRecord{recB},
+		},
+		{
+			name:  "height range",
+			query: TxQuery{MinHeight: 101, MaxHeight: 101},
+			exp:   []*TxRecord{recC},
+		},
+		{
+			name: "unmined page",
+			query: TxQuery{
+				MaxHeight:      -1,
+				IncludeUnmined: true,
+				Offset:         3,
+				Limit:          5,
+			},
+			exp: []*TxRecord{recU},
+		},
+		{
+			name:  "past end",
+			query: TxQuery{MaxHeight: -1, Offset: 3},
+			exp:   nil,
+		},
+	}
+
+	dbtx, err := db.BeginReadTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbtx.Rollback()
+	ns := dbtx.ReadBucket(namespaceKey)
+
+	for _, test := range tests {
+		details, err := s.QueryTransactions(ns, &test.query)
+		if err != nil {
+			t.Fatalf("%s: unable to query transactions: %v",
+				test.name, err)
+		}
+		if len(details) != len(test.exp) {
+			t.Fatalf("%s: expected %d transactions, got %d",
+				test.name, len(test.exp), len(details))
+		}
+		for i, rec := range test.exp {
+			if details[i].Hash != rec.Hash {
+				t.Fatalf("%s: expected transaction %d to be %v, "+
+					"got %v", test.name, i, rec.Hash,
+					details[i].Hash)
+			}
+		}
+	}
+}
+
+func TestLastBlock(t *testing.T) {
+	t.Parallel()
+
+	s, db, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b100 := makeBlockMeta(100)
+	b105 := makeBlockMeta(105)
+	commitDBTx(t, s, db, func(ns walletdb.ReadWriteBucket) {
+		for _, block := range []*BlockMeta{&b100, &b105} {
+			rec, err := NewTxRecordFromMsgTx(
+				newCoinBase(int64(block.Height)), timeNow(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.InsertTx(ns, rec, block); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	dbtx, err := db.BeginReadTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbtx.Rollback()
+	ns := dbtx.ReadBucket(namespaceKey)
+
+	tests := []struct {
+		maxHeight int32
+		exp       *BlockMeta
+	}{
+		{maxHeight: 99},
+		{maxHeight: 100, exp: &b100},
+		{maxHeight: 104, exp: &b100},
+		{maxHeight: 105, exp: &b105},
+		{maxHeight: 1000, exp: &b105},
+	}
+	for _, test := range tests {
+		block, err := s.LastBlock(ns, test.maxHeight)
+		if err != nil {
+			t.Fatalf("height %d: %v", test.maxHeight, err)
+		}
+		switch {
+		case test.exp == nil && block != nil:
+			t.Errorf("height %d: expected no block, got %d",
+				test.maxHeight, block.Height)
+		case test.exp != nil && (block == nil || *block != test.exp.Block):
+			t.Errorf("height %d: expected block %d, got %v",
+				test.maxHeight, test.exp.Height, block)
+		}
+	}
+}
@@ -128,6 +128,13 @@ type Credit struct {
 	PkScript     []byte
 	Received     time.Time
 	FromCoinBase bool
+
+	// Account is the account of the wallet address paid to by the credit,
+	// and is only set when HasAccount is true.  Credits added with
+	// AddAccountCredit, and unspent credits of upgraded stores paying to
+	// wallet addresses, record their account.
+	Account    uint32
+	HasAccount bool
 }
 
 // Store implements a transaction store for storing and managing wallet
@@ -138,25 +145,55 @@ type Store struct {
 	// Event callbacks.  These execute in the same goroutine as the wtxmgr
 	// caller.
 	NotifyUnspent func(hash *chainhash.Hash, index uint32)
+
+	// NotifyConflict is called when the unmined transaction hash is
+	// removed from the store because the transaction conflictingHash
+	// double spending it was mined.  Conflicts with unmined transactions
+	// are queried with Conflicts instead.
+	NotifyConflict func(hash, conflictingHash *chainhash.Hash)
 }
 
 // DoUpgrades performs any necessary upgrades to the transaction history
 // contained in the wallet database, namespaced by the top level bucket key
-// namespaceKey.
+// namespaceKey.  Upgrading stores of version 1 requires the accounts of their
+// credits, so ErrNeedsUpgrade is returned for them, and they must be upgraded
+// with DoAccountUpgrades.
 func DoUpgrades(db walletdb.DB, namespaceKey []byte) error {
-	// No upgrades
-	return nil
+	return upgradeStore(db, namespaceKey, nil)
+}
+
+// DoAccountUpgrades performs the upgrades of DoUpgrades, including those of
+// stores of version 1.  Stores upgraded to record the account of their credits
+// look up the account of each unspent credit with account, which returns false
+// if the output script does not pay to a wallet address.
+func DoAccountUpgrades(db walletdb.DB, namespaceKey []byte,
+	account func(tx walletdb.ReadTx, pkScript []byte) (uint32, bool)) error {
+
+	return upgradeStore(db, namespaceKey, account)
 }
 
 // Open opens the wallet transaction store from a walletdb namespace.  If the
 // store does not exist, ErrNoExist is returned.
 func Open(ns walletdb.ReadBucket, chainParams *chaincfg.Params) (*Store, error) {
 	// Open the store.
-	err := openStore(ns)
+	err := openStore(ns, false)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{chainParams, nil, nil} // TODO: set callbacks
+	return s, nil
+}
+
+// OpenReadOnly opens the wallet transaction store from a walletdb namespace of
+// a database opened in read-only mode, which can not be upgraded.  Unlike
+// Open, stores of older versions are opened as they are, and their credits do
+// not record their accounts until the store is upgraded.
+func OpenReadOnly(ns walletdb.ReadBucket, chainParams *chaincfg.Params) (*Store, error) {
+	err := openStore(ns, true)
 	if err != nil {
 		return nil, err
 	}
-	s := &Store{chainParams, nil} // TODO: set callbacks
+	s := &Store{chainParams, nil, nil} // TODO: set callbacks
 	return s, nil
 }
 
@@ -301,10 +338,16 @@ func (s *Store) deleteUnminedTx(ns walletdb.ReadWriteBucket, rec *TxRecord) erro
 // history.  If block is nil, the transaction is considered unspent, and the
 // transaction's index must be unset.
 func (s *Store) InsertTx(ns walletdb.ReadWriteBucket, rec *TxRecord, block *BlockMeta) error {
+	var err error
 	if block == nil {
-		return s.insertMemPoolTx(ns, rec)
+		err = s.insertMemPoolTx(ns, rec)
+	} else {
+		err = s.insertMinedTx(ns, rec, block)
 	}
-	return s.insertMinedTx(ns, rec, block)
+	if err != nil {
+		return err
+	}
+	return s.recordTxFee(ns, rec)
 }
 
 // RemoveUnminedTx attempts to remove an unmined transaction from the
@@ -355,6 +398,13 @@ func (s *Store) insertMinedTx(ns walletdb.ReadWriteBucket, rec *TxRecord,
 		return err
 	}
 
+	// A mined transaction is no longer conflicted by any unmined double
+	// spend seen after it.
+	err = deleteTxHashMapping(ns, bucketConflicts, &rec.Hash)
+	if err != nil {
+		return err
+	}
+
 	// Determine if this transaction has affected our balance, and if so,
 	// update it.
 	if err := s.updateMinedBalance(ns, rec, block); err != nil {
@@ -416,7 +466,10 @@ func (s *Store) addCredit(ns walletdb.ReadWriteBucket, rec *TxRecord, block *Blo
 			return false, nil
 		}
 		v := valueUnminedCredit(btcutil.Amount(rec.MsgTx.TxOut[index].Value), change)
-		return true, putRawUnminedCredit(ns, k, v)
+		if err := putRawUnminedCredit(ns, k, v); err != nil {
+			return false, err
+		}
+		return true, s.indexAddrCredit(ns, k, rec.MsgTx.TxOut[index], -1)
 	}
 
 	k, v := existsCredit(ns, &rec.Hash, index, &block.Block)
@@ -443,6 +496,11 @@ func (s *Store) addCredit(ns walletdb.ReadWriteBucket, rec *TxRecord, block *Blo
 	if err != nil {
 		return false, err
 	}
+	err = s.indexAddrCredit(ns, canonicalOutPoint(&rec.Hash, index),
+		rec.MsgTx.TxOut[index], block.Height)
+	if err != nil {
+		return false, err
+	}
 
 	minedBalance, err := fetchMinedBalance(ns)
 	if err != nil {
@@ -534,6 +592,11 @@ func (s *Store) rollback(ns walletdb.ReadWriteBucket, height int32) error {
 					if err != nil {
 						return err
 					}
+					err = deleteAddrCredit(ns,
+						canonicalOutPoint(&op.Hash, op.Index))
+					if err != nil {
+						return err
+					}
 				}
 
 				continue
@@ -747,6 +810,14 @@ func (s *Store) UnspentOutputs(ns walletdb.ReadBucket) ([]Credit, error) {
 			Received:     rec.Received,
 			FromCoinBase: blockchain.IsCoinBaseTx(&rec.MsgTx),
 		}
+		ca, err := fetchCreditAccount(ns, k)
+		if err != nil {
+			return err
+		}
+		if ca != nil {
+			cred.Account = ca.account
+			cred.HasAccount = true
+		}
 		unspent = append(unspent, cred)
 		return nil
 	})
@@ -790,6 +861,14 @@ func (s *Store) UnspentOutputs(ns walletdb.ReadBucket) ([]Credit, error) {
 			Received:     rec.Received,
 			FromCoinBase: blockchain.IsCoinBaseTx(&rec.MsgTx),
 		}
+		ca, err := fetchCreditAccount(ns, k)
+		if err != nil {
+			return err
+		}
+		if ca != nil {
+			cred.Account = ca.account
+			cred.HasAccount = true
+		}
 		unspent = append(unspent, cred)
 		return nil
 	})
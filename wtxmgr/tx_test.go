@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -1945,3 +1946,286 @@ func TestAddDuplicateCreditAfterConfirm(t *testing.T) {
 		}
 	})
 }
+
+// TestReplacementConflict ensures that replaced transactions are tracked and
+// removed along with their change, that removing a rejected replacement leaves
+// the original spending its inputs, and that the original is recorded as
+// conflicted with its replacement.
+func TestReplacementConflict(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(1e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, cbRec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Create an unmined spend of the coinbase output along with two
+	// replacements paying higher fees.
+	newRec := func(changeAmount int64) *TxRecord {
+		rec, err := NewTxRecordFromMsgTx(
+			spendOutput(&cbRec.Hash, 0, 5e7, changeAmount),
+			time.Now(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	origRec, rejectedRec, replacementRec := newRec(4e7), newRec(3e7), newRec(2e7)
+
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		for _, rec := range []*TxRecord{origRec, rejectedRec} {
+			if err := store.InsertTx(ns, rec, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Removing a replacement, as is done when it is rejected by
+		// the backend, must not mark the original's inputs unspent.
+		if err := store.RemoveUnminedTx(ns, rejectedRec); err != nil {
+			t.Fatal(err)
+		}
+		unspent, err := store.UnspentOutputs(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unspent) != 0 {
+			t.Fatalf("expected no unspent outputs, got %v", unspent)
+		}
+
+		err = store.AddCredit(ns, origRec, nil, 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, replacementRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		err = store.AddCredit(ns, replacementRec, nil, 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.InsertReplacement(
+			ns, &origRec.Hash, &replacementRec.Hash,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replacement, err := store.Replacement(ns, &origRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replacement == nil || *replacement != replacementRec.Hash {
+			t.Fatalf("expected replacement %v, got %v",
+				replacementRec.Hash, replacement)
+		}
+
+		// Only the change of the replacement may be counted.
+		details, err := store.TxDetails(ns, &origRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details != nil {
+			t.Fatal("expected replaced transaction to be removed")
+		}
+		bal, err := store.Balance(ns, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bal != 2e7 {
+			t.Fatalf("expected balance %v, got %v",
+				btcutil.Amount(2e7), bal)
+		}
+	})
+
+	// Mine the replacement.  The original must remain removed and recorded
+	// as conflicting with the replacement.
+	b101 := &BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, replacementRec, b101); err != nil {
+			t.Fatal(err)
+		}
+
+		details, err := store.TxDetails(ns, &origRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details != nil {
+			t.Fatal("expected replaced transaction to be removed")
+		}
+		conflict, err := store.ConflictingTx(ns, &origRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conflict == nil || *conflict != replacementRec.Hash {
+			t.Fatalf("expected conflict with %v, got %v",
+				replacementRec.Hash, conflict)
+		}
+	})
+}
+
+// TestConflictNotifications ensures that unmined transactions double spent by
+// unmined transactions are only recorded as conflicted with InsertConflicts,
+// and that unmined transactions removed because a double spend of them was
+// mined are recorded as conflicted and notified.
+func TestConflictNotifications(t *testing.T) {
+	t.Parallel()
+
+	store, db, teardown, err := testStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	type conflict struct {
+		hash, conflictingHash chainhash.Hash
+	}
+	var conflicts []conflict
+	store.NotifyConflict = func(hash, conflictingHash *chainhash.Hash) {
+		conflicts = append(conflicts, conflict{*hash, *conflictingHash})
+	}
+
+	b100 := &BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cbRec, err := NewTxRecordFromMsgTx(newCoinBase(1e8), b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&cbRec.Hash, 0, 5e7, 4e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doubleSpendRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&cbRec.Hash, 0, 9e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejectedRec, err := NewTxRecordFromMsgTx(
+		spendOutput(&cbRec.Hash, 0, 8e7), time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkConflict := func(ns walletdb.ReadBucket, hash *chainhash.Hash,
+		want *chainhash.Hash) {
+
+		conflict, err := store.ConflictingTx(ns, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (conflict == nil) != (want == nil) ||
+			(want != nil && *conflict != *want) {
+
+			t.Fatalf("expected conflict of %v with %v, got %v",
+				hash, want, conflict)
+		}
+	}
+
+	// A double spend which is inserted and removed again, as when the
+	// backend rejects a published transaction, is never recorded as
+	// conflicting with the first unmined spend.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, cbRec, b100); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddCredit(ns, cbRec, b100, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, origRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertTx(ns, rejectedRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RemoveUnminedTx(ns, rejectedRec); err != nil {
+			t.Fatal(err)
+		}
+		checkConflict(ns, &origRec.Hash, nil)
+	})
+
+	// The first unmined spend is double spent by the second one once its
+	// conflicts are inserted, but remains unmined.
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, doubleSpendRec, nil); err != nil {
+			t.Fatal(err)
+		}
+		checkConflict(ns, &origRec.Hash, nil)
+
+		want := []chainhash.Hash{origRec.Hash}
+		inserted, err := store.InsertConflicts(ns, doubleSpendRec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(inserted, want) {
+			t.Fatalf("expected inserted conflicts %v, got %v",
+				want, inserted)
+		}
+		checkConflict(ns, &origRec.Hash, &doubleSpendRec.Hash)
+
+		got, err := store.Conflicts(ns, doubleSpendRec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected conflicts %v, got %v", want, got)
+		}
+	})
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected notified conflicts %v", conflicts)
+	}
+
+	// Mining the first spend removes the double spend, which is notified
+	// as conflicted by a mined transaction.
+	b101 := &BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	commitDBTx(t, store, db, func(ns walletdb.ReadWriteBucket) {
+		if err := store.InsertTx(ns, origRec, b101); err != nil {
+			t.Fatal(err)
+		}
+		checkConflict(ns, &origRec.Hash, nil)
+		checkConflict(ns, &doubleSpendRec.Hash, &origRec.Hash)
+
+		details, err := store.TxDetails(ns, &doubleSpendRec.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details != nil {
+			t.Fatal("expected double spend to be removed")
+		}
+	})
+	want := []conflict{{doubleSpendRec.Hash, origRec.Hash}}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Fatalf("expected conflicts %v, got %v", want, conflicts)
+	}
+}
@@ -6,6 +6,8 @@
 package wtxmgr
 
 import (
+	"fmt"
+
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/walletdb"
@@ -89,17 +91,102 @@ func (s *Store) removeDoubleSpends(ns walletdb.ReadWriteBucket, rec *TxRecord) e
 				return err
 			}
 
-			log.Debugf("Removing double spending transaction %v",
-				doubleSpend.Hash)
+			log.Infof("Removing transaction %v double spent by "+
+				"mined transaction %v", doubleSpend.Hash, rec.Hash)
 			if err := s.removeConflict(ns, &doubleSpend); err != nil {
 				return err
 			}
+
+			// Remember which transaction the removed one conflicted
+			// with, so replaced transactions can be reported as
+			// such once their replacement confirms.
+			err = putTxHashMapping(
+				ns, bucketConflicts, &doubleSpend.Hash, &rec.Hash,
+			)
+			if err != nil {
+				return err
+			}
+			if s.NotifyConflict != nil {
+				s.NotifyConflict(&doubleSpend.Hash, &rec.Hash)
+			}
 		}
 	}
 
 	return nil
 }
 
+// InsertConflicts records the unmined transactions spending any output spent by
+// the unmined transaction rec as double spent by rec, and returns their hashes.
+// They remain in the store until one of the double spends is mined.  Conflicts
+// are recorded once the backend relayed rec, which it would not have done
+// unless rec replaced them, so that a transaction rejected by the backend and
+// removed again is never recorded as double spending other transactions.
+func (s *Store) InsertConflicts(ns walletdb.ReadWriteBucket,
+	rec *TxRecord) ([]chainhash.Hash, error) {
+
+	if existsRawUnmined(ns, rec.Hash[:]) == nil {
+		return nil, nil
+	}
+	conflicts := unminedDoubleSpends(ns, rec)
+	for i := range conflicts {
+		conflict := &conflicts[i]
+		log.Infof("Unconfirmed transaction %v is double spent by %v",
+			conflict, &rec.Hash)
+		err := putTxHashMapping(ns, bucketConflicts, conflict, &rec.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return conflicts, nil
+}
+
+// Conflicts returns the hashes of the unmined transactions recorded as double
+// spent by the unmined transaction rec with InsertConflicts.
+func (s *Store) Conflicts(ns walletdb.ReadBucket,
+	rec *TxRecord) ([]chainhash.Hash, error) {
+
+	var conflicts []chainhash.Hash
+	for _, doubleSpend := range unminedDoubleSpends(ns, rec) {
+		hash, err := fetchTxHashMapping(ns, bucketConflicts, &doubleSpend)
+		if err != nil {
+			return nil, err
+		}
+		if hash != nil && *hash == rec.Hash {
+			conflicts = append(conflicts, doubleSpend)
+		}
+	}
+	return conflicts, nil
+}
+
+// unminedDoubleSpends returns the hashes of the unmined transactions other than
+// rec spending any output spent by rec.
+func unminedDoubleSpends(ns walletdb.ReadBucket, rec *TxRecord) []chainhash.Hash {
+	var hashes []chainhash.Hash
+	for _, input := range rec.MsgTx.TxIn {
+		prevOut := &input.PreviousOutPoint
+		k := canonicalOutPoint(&prevOut.Hash, prevOut.Index)
+		for _, hash := range fetchUnminedInputSpendTxHashes(ns, k) {
+			if hash == rec.Hash || seenHash(hashes, &hash) ||
+				existsRawUnmined(ns, hash[:]) == nil {
+
+				continue
+			}
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// seenHash returns whether hash is one of hashes.
+func seenHash(hashes []chainhash.Hash, hash *chainhash.Hash) bool {
+	for i := range hashes {
+		if hashes[i] == *hash {
+			return true
+		}
+	}
+	return false
+}
+
 // removeConflict removes an unmined transaction record and all spend chains
 // deriving from it from the store.  This is designed to remove transactions
 // that would otherwise result in double spend conflicts if left in the store,
@@ -139,22 +226,87 @@ func (s *Store) removeConflict(ns walletdb.ReadWriteBucket, rec *TxRecord) error
 		if err := deleteRawUnminedCredit(ns, k); err != nil {
 			return err
 		}
+		if err := deleteAddrCredit(ns, k); err != nil {
+			return err
+		}
 	}
 
 	// If this tx spends any previous credits (either mined or unmined), set
 	// each unspent.  Mined transactions are only marked spent by having the
-	// output in the unmined inputs bucket.
+	// output in the unmined inputs bucket.  Other unmined transactions, such
+	// as replacements of this one, may still spend the same outputs, so
+	// only this transaction is removed from the list of spenders.
 	for _, input := range rec.MsgTx.TxIn {
 		prevOut := &input.PreviousOutPoint
 		k := canonicalOutPoint(&prevOut.Hash, prevOut.Index)
-		if err := deleteRawUnminedInput(ns, k); err != nil {
+		err := deleteRawUnminedInputSpender(ns, k, &rec.Hash)
+		if err != nil {
 			return err
 		}
 	}
 
+	if err := deleteTxFee(ns, &rec.Hash); err != nil {
+		return err
+	}
 	return deleteRawUnmined(ns, rec.Hash[:])
 }
 
+// InsertReplacement records that the unmined transaction replaced has been
+// replaced by the unmined transaction replacement, for example by bumping its
+// fee.  The replaced transaction, and all transactions spending its outputs,
+// are removed from the store, as both transactions spend the same outputs and
+// the change of each would otherwise be counted as unspent.  The replaced
+// transaction is recorded as conflicting with its replacement, which is
+// reported by ConflictingTx.
+func (s *Store) InsertReplacement(ns walletdb.ReadWriteBucket, replaced,
+	replacement *chainhash.Hash) error {
+
+	v := existsRawUnmined(ns, replaced[:])
+	if v == nil {
+		str := fmt.Sprintf("transaction %v is not unmined", replaced)
+		return storeError(ErrInput, str, nil)
+	}
+	if existsRawUnmined(ns, replacement[:]) == nil {
+		str := fmt.Sprintf("replacement %v is not unmined", replacement)
+		return storeError(ErrInput, str, nil)
+	}
+
+	var rec TxRecord
+	rec.Hash = *replaced
+	if err := readRawTxRecord(&rec.Hash, v, &rec); err != nil {
+		return err
+	}
+	log.Infof("Removing transaction %v replaced by %v", replaced,
+		replacement)
+	if err := s.removeConflict(ns, &rec); err != nil {
+		return err
+	}
+	err := putTxHashMapping(ns, bucketConflicts, replaced, replacement)
+	if err != nil {
+		return err
+	}
+	return putTxHashMapping(ns, bucketReplacements, replaced, replacement)
+}
+
+// Replacement returns the hash of the transaction which replaced txHash, or
+// nil if no replacement was recorded.
+func (s *Store) Replacement(ns walletdb.ReadBucket,
+	txHash *chainhash.Hash) (*chainhash.Hash, error) {
+
+	return fetchTxHashMapping(ns, bucketReplacements, txHash)
+}
+
+// ConflictingTx returns the hash of the transaction which double spent the
+// transaction txHash, or nil if no such conflict was recorded.  Transactions
+// double spent by a mined transaction are removed from the store, while those
+// double spent by an unmined transaction remain unmined until either
+// transaction is mined.
+func (s *Store) ConflictingTx(ns walletdb.ReadBucket,
+	txHash *chainhash.Hash) (*chainhash.Hash, error) {
+
+	return fetchTxHashMapping(ns, bucketConflicts, txHash)
+}
+
 // UnminedTxs returns the underlying transactions for all unmined transactions
 // which are not known to have been mined in a block.  Transactions are
 // guaranteed to be sorted by their dependency order.